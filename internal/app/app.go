@@ -1,22 +1,486 @@
 package app
 
 import (
+	"cmp"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/blob"
+	"github.com/cloudingcity/todo/internal/entity"
 	"github.com/cloudingcity/todo/internal/handler/http"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/cloudingcity/todo/internal/idempotency"
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/cloudingcity/todo/internal/mail"
+	"github.com/cloudingcity/todo/internal/oidc"
+	"github.com/cloudingcity/todo/internal/ratelimit"
+	"github.com/cloudingcity/todo/internal/reminder"
+	"github.com/cloudingcity/todo/internal/repo"
 	"github.com/cloudingcity/todo/internal/repo/memory"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/apikey"
+	"github.com/cloudingcity/todo/internal/service/attachment"
+	"github.com/cloudingcity/todo/internal/service/comment"
+	"github.com/cloudingcity/todo/internal/service/project"
+	"github.com/cloudingcity/todo/internal/service/sso"
+	"github.com/cloudingcity/todo/internal/service/timeentry"
 	"github.com/cloudingcity/todo/internal/service/todo"
+	"github.com/cloudingcity/todo/internal/service/user"
+	"github.com/cloudingcity/todo/internal/service/workspace"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+const (
+	addr            = ":8080"
+	idempotencyTTL  = 24 * time.Hour
+	drainDelay      = 5 * time.Second
+	shutdownTimeout = 10 * time.Second
+	webhookTimeout  = 10 * time.Second
+	oidcTimeout     = 10 * time.Second
+	// sweepInterval is how often content nothing refers to anymore is
+	// removed.
+	sweepInterval = time.Hour
 )
 
 func Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	r := gin.Default()
+	// Without trusted proxies, X-Forwarded-For is ignored, so clients cannot
+	// pick the IP address they are rate limited by.
+	if err := r.SetTrustedProxies(splitList(os.Getenv("TRUSTED_PROXIES"))); err != nil {
+		return fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+	healthReg := health.NewRegistry()
 
 	todoRepo := memory.NewTodoRepo()
-	todoSrv := todo.NewService(todoRepo)
-	http.NewRouter(r, todoSrv)
+	if checker, ok := todoRepo.(health.Checker); ok {
+		healthReg.Register("todo-repo", checker, 0)
+	}
+	quota, err := newTodoQuota()
+	if err != nil {
+		return err
+	}
+	historyRepo := memory.NewHistoryRepo()
+	todoSrv := todo.NewService(todoRepo, todo.WithQuota(quota), todo.WithHistory(historyRepo))
+	tokenMethod, err := newTokenMethod()
+	if err != nil {
+		return err
+	}
+	userRepo := memory.NewUserRepo()
+	userSrv, err := user.NewService(userRepo, tokenMethod)
+	if err != nil {
+		return err
+	}
+	apiKeySrv := apikey.NewService(memory.NewAPIKeyRepo(), userRepo)
+	workspaceRepo := memory.NewWorkspaceRepo()
+	projectRepo := memory.NewProjectRepo()
+	projectSrv := project.NewService(projectRepo, todoSrv, userRepo, workspaceRepo)
+	workspaceSrv := workspace.NewService(workspaceRepo, userRepo, projectRepo)
+	timeEntrySrv := timeentry.NewService(memory.NewTimeEntryRepo(), todoSrv, projectSrv)
+	commentSrv := comment.NewService(memory.NewCommentRepo(), historyRepo, todoSrv, userRepo, workspaceSrv, newMentionNotifier(userRepo))
+	attachmentLimits, err := newAttachmentLimits()
+	if err != nil {
+		return err
+	}
+	attachmentSrv, blobs, err := newAttachmentService(todoSrv, attachmentLimits)
+	if err != nil {
+		return err
+	}
+	if checker, ok := blobs.(health.Checker); ok {
+		healthReg.Register("attachment-store", checker, 0)
+	}
+	idemStore := idempotency.NewMemoryStore(idempotencyTTL)
+	ssoSrv, err := newSSOService(userSrv)
+	if err != nil {
+		return err
+	}
+	limits, err := newRateLimits()
+	if err != nil {
+		return err
+	}
+	http.NewRouter(r, healthReg, idemStore, http.Options{
+		RateLimits:  limits,
+		Idempotency: middleware.IdempotencyOptions{MaxUploadBytes: maxUploadBytes(attachmentLimits)},
+		// Checking every response costs memory and time, so only dev
+		// setups ask for it.
+		ValidateResponses: os.Getenv("OPENAPI_VALIDATE_RESPONSES") == "true",
+	}, userSrv, ssoSrv, apiKeySrv, workspaceSrv, todoSrv, commentSrv, attachmentSrv, projectSrv, timeEntrySrv)
 
-	if err := r.Run(":8080"); err != nil {
+	reminderQueue, err := newReminderQueue()
+	if err != nil {
+		return err
+	}
+	reminderTodos := reminder.AcrossWorkspaces(func() ([]int, error) {
+		workspaces, err := workspaceRepo.List()
+		if err != nil {
+			return nil, err
+		}
+		return lo.Map(workspaces, func(w entity.Workspace, _ int) int { return w.ID }), nil
+	}, func(id int) reminder.TodoLister {
+		return todoSrv.ForWorkspace(id)
+	})
+	if checker, ok := reminderQueue.(health.Checker); ok {
+		healthReg.Register("reminder-queue", checker, 0)
+	}
+	notifier, err := newNotifier()
+	if err != nil {
 		return err
 	}
+	scheduler := reminder.NewScheduler(reminderTodos, reminderQueue, notifier)
+	healthReg.Register("reminder-scheduler", scheduler, 0)
+	reminderCtx, stopReminders := context.WithCancel(context.Background())
+	reminderDone := make(chan struct{})
+	go func() {
+		scheduler.Run(reminderCtx)
+		close(reminderDone)
+	}()
+	defer func() {
+		stopReminders()
+		<-reminderDone
+	}()
 
+	sweepCtx, stopSweeps := context.WithCancel(context.Background())
+	sweepDone := make(chan struct{})
+	sweeper := newAttachmentSweeper(attachmentSrv)
+	healthReg.Register("attachment-sweeper", sweeper, 0)
+	go func() {
+		sweeper.run(sweepCtx)
+		close(sweepDone)
+	}()
+	defer func() {
+		stopSweeps()
+		<-sweepDone
+	}()
+
+	srv := &nethttp.Server{
+		Addr:    addr,
+		Handler: r,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first and give load balancers time to notice before
+	// we stop accepting connections.
+	healthReg.Shutdown()
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	return <-errCh
+}
+
+// newTokenMethod signs access tokens as JWT_ALG says, HS256 by default, with
+// the key from JWT_SECRET or JWT_ED25519_SEED (base64). Without a key one is
+// generated, and tokens stop working when the process restarts.
+func newTokenMethod() (jwt.Method, error) {
+	switch alg := cmp.Or(os.Getenv("JWT_ALG"), "HS256"); alg {
+	case "HS256":
+		secret := []byte(os.Getenv("JWT_SECRET"))
+		if len(secret) == 0 {
+			log.Print("JWT_SECRET is not set, using a random secret")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		} else if len(secret) < 32 {
+			return nil, errors.New("JWT_SECRET must be at least 32 bytes")
+		}
+		return jwt.HS256(secret), nil
+	case "EdDSA":
+		encoded := os.Getenv("JWT_ED25519_SEED")
+		if encoded == "" {
+			log.Print("JWT_ED25519_SEED is not set, using a random key")
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return nil, err
+			}
+			return jwt.EdDSA(key), nil
+		}
+		seed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, errors.New("JWT_ED25519_SEED must be 32 bytes in base64")
+		}
+		return jwt.EdDSA(ed25519.NewKeyFromSeed(seed)), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALG %q", alg)
+	}
+}
+
+// newSSOService logs users in with the OpenID Connect provider at
+// OIDC_ISSUER, or returns nil when it is unset. The provider is only
+// contacted once the first user logs in.
+func newSSOService(users service.User) (service.SSO, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+	cfg := oidc.Config{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		HTTPClient:   &nethttp.Client{Timeout: oidcTimeout},
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set along with OIDC_ISSUER")
+	}
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		cfg.Scopes = strings.Fields(scopes)
+	}
+
+	claims := sso.DefaultClaims
+	claims.Email = cmp.Or(os.Getenv("OIDC_EMAIL_CLAIM"), claims.Email)
+	claims.Name = cmp.Or(os.Getenv("OIDC_NAME_CLAIM"), claims.Name)
+	opts := []sso.Option{
+		sso.WithClaims(claims),
+		sso.WithPostLogoutRedirect(os.Getenv("OIDC_POST_LOGOUT_REDIRECT_URL")),
+	}
+	if os.Getenv("OIDC_TRUST_EMAILS") == "true" {
+		opts = append(opts, sso.WithTrustedEmails())
+	}
+	return sso.NewService(oidc.NewClient(cfg), users, opts...), nil
+}
+
+// newRateLimits limits every client to RATE_LIMIT requests, logins and
+// sign ups to RATE_LIMIT_LOGIN by IP address, and creating todos to
+// RATE_LIMIT_CREATE_TODOS. Requests to authenticated routes are limited to
+// RATE_LIMIT_IP by IP address before their credentials are checked. Each
+// takes a limit like 600/1m, or off.
+func newRateLimits() (middleware.RateLimitOptions, error) {
+	limiter := func(env, fallback string) (ratelimit.Limiter, error) {
+		limit, err := ratelimit.ParseLimit(cmp.Or(os.Getenv(env), fallback))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+		return ratelimit.NewMemoryLimiter(limit), nil
+	}
+	def, err := limiter("RATE_LIMIT", "600/1m")
+	if err != nil {
+		return middleware.RateLimitOptions{}, err
+	}
+	login, err := limiter("RATE_LIMIT_LOGIN", "10/1m")
+	if err != nil {
+		return middleware.RateLimitOptions{}, err
+	}
+	createTodos, err := limiter("RATE_LIMIT_CREATE_TODOS", "60/1m")
+	if err != nil {
+		return middleware.RateLimitOptions{}, err
+	}
+	preAuth, err := limiter("RATE_LIMIT_IP", "1200/1m")
+	if err != nil {
+		return middleware.RateLimitOptions{}, err
+	}
+	return middleware.RateLimitOptions{
+		Default: def,
+		Routes: map[string]ratelimit.Limiter{
+			"POST /v1/sessions":           login,
+			"POST /v1/users":              login,
+			"POST /v1/todos":              createTodos,
+			"POST /v1/todos:action":       createTodos,
+			"POST /v1/projects/:id/todos": createTodos,
+		},
+		PreAuth: preAuth,
+	}, nil
+}
+
+// newTodoQuota caps every user at TODO_QUOTA_MAX_TODOS todos, 10000 by
+// default, and descriptions at TODO_QUOTA_MAX_DESCRIPTION bytes, 64 KiB by
+// default. Zero lifts a cap.
+func newTodoQuota() (todo.Quota, error) {
+	quota := todo.Quota{MaxTodos: 10000, MaxDescription: 64 << 10}
+	for env, field := range map[string]*int{
+		"TODO_QUOTA_MAX_TODOS":       &quota.MaxTodos,
+		"TODO_QUOTA_MAX_DESCRIPTION": &quota.MaxDescription,
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return todo.Quota{}, fmt.Errorf("%s must be a number of zero or more", env)
+		}
+		*field = n
+	}
+	return quota, nil
+}
+
+// newAttachmentService keeps the content of attachments in ATTACHMENT_DIR,
+// a directory under the temporary directory by default. The blob store is
+// returned as well for its health check.
+func newAttachmentService(todoSrv service.Todo, limits attachment.Limits) (service.Attachment, blob.Store, error) {
+	blobs, err := blob.NewFileStore(cmp.Or(os.Getenv("ATTACHMENT_DIR"), filepath.Join(os.TempDir(), "todo-attachments")))
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment.NewService(memory.NewAttachmentRepo(), blobs, todoSrv, attachment.WithLimits(limits)), blobs, nil
+}
+
+// newAttachmentLimits caps files at ATTACHMENT_MAX_SIZE bytes, 25 MiB by
+// default, and todos at ATTACHMENT_MAX_PER_TODO files, 100 by default. Zero
+// lifts a cap.
+func newAttachmentLimits() (attachment.Limits, error) {
+	limits := attachment.Limits{MaxSize: 25 << 20, MaxPerTodo: 100}
+	if value := os.Getenv("ATTACHMENT_MAX_SIZE"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return attachment.Limits{}, errors.New("ATTACHMENT_MAX_SIZE must be a number of zero or more")
+		}
+		limits.MaxSize = n
+	}
+	if value := os.Getenv("ATTACHMENT_MAX_PER_TODO"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return attachment.Limits{}, errors.New("ATTACHMENT_MAX_PER_TODO must be a number of zero or more")
+		}
+		limits.MaxPerTodo = n
+	}
+	return limits, nil
+}
+
+// maxUploadBytes is the largest upload body spooled for idempotency: the
+// largest file plus a MiB for the multipart headers and other fields.
+func maxUploadBytes(limits attachment.Limits) int64 {
+	if limits.MaxSize == 0 {
+		return 0
+	}
+	return limits.MaxSize + 1<<20
+}
+
+// attachmentSweeper removes the attachments of deleted todos and the content
+// nothing refers to anymore every sweepInterval.
+type attachmentSweeper struct {
+	srv service.Attachment
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	lastErr   error
+}
+
+func newAttachmentSweeper(srv service.Attachment) *attachmentSweeper {
+	return &attachmentSweeper{srv: srv, lastSweep: time.Now()}
+}
+
+// run sweeps until ctx is done.
+func (s *attachmentSweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		deleted, err := s.srv.Sweep()
+		s.mu.Lock()
+		s.lastSweep, s.lastErr = time.Now(), err
+		s.mu.Unlock()
+		if err != nil {
+			log.Printf("attachments: sweep: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("attachments: removed %d unused files", deleted)
+		}
+	}
+}
+
+// Check reports the sweeper as unhealthy when the last sweep failed, or
+// when none finished for two intervals, which means a sweep is stuck.
+func (s *attachmentSweeper) Check(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastErr != nil {
+		return fmt.Errorf("sweep: %w", s.lastErr)
+	}
+	if since := time.Since(s.lastSweep); since > 2*sweepInterval {
+		return fmt.Errorf("no sweep for %s", since.Round(time.Second))
+	}
 	return nil
 }
+
+// newReminderQueue keeps reminders in the file named by REMINDER_QUEUE_PATH,
+// or in memory when it is unset.
+func newReminderQueue() (reminder.Queue, error) {
+	if path := os.Getenv("REMINDER_QUEUE_PATH"); path != "" {
+		return reminder.OpenFileQueue(path)
+	}
+	return reminder.NewMemoryQueue(), nil
+}
+
+// newNotifier logs every reminder and also delivers it to a webhook and by
+// mail when those are configured. Both channels receive the reminders of
+// every workspace, titles included, so REMINDER_WEBHOOK_URL and SMTP_TO must
+// point at the operator, never at a tenant.
+func newNotifier() (reminder.Notifier, error) {
+	notifiers := []reminder.Notifier{reminder.NewLogNotifier(log.Default())}
+	if url := os.Getenv("REMINDER_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, reminder.NewWebhookNotifier(url, &nethttp.Client{Timeout: webhookTimeout}))
+	}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		to := splitList(os.Getenv("SMTP_TO"))
+		if len(to) == 0 {
+			return nil, errors.New("SMTP_TO must be set along with SMTP_ADDR")
+		}
+		notifiers = append(notifiers, reminder.NewSMTPNotifier(smtpConfig(addr), to))
+	}
+	return reminder.Multi(notifiers...), nil
+}
+
+// newMentionNotifier mails mentioned users when SMTP_ADDR is set, and only
+// logs mentions otherwise.
+func newMentionNotifier(users repo.User) comment.Notifier {
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		return comment.NewMailNotifier(users, smtpConfig(addr), log.Default())
+	}
+	return comment.NewLogNotifier(log.Default())
+}
+
+func smtpConfig(addr string) mail.Config {
+	return mail.Config{
+		Addr:     addr,
+		From:     os.Getenv("SMTP_FROM"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+}
+
+// splitList splits a comma-separated value, dropping blank entries.
+func splitList(value string) []string {
+	return lo.Compact(lo.Map(strings.Split(value, ","), func(s string, _ int) string {
+		return strings.TrimSpace(s)
+	}))
+}
@@ -0,0 +1,45 @@
+// Package blob stores file contents by their SHA-256, so the same content is
+// only ever stored once no matter how often it is uploaded.
+package blob
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+var timeNow = time.Now
+
+// Info describes a stored blob.
+type Info struct {
+	// Key is the lowercase hex SHA-256 of the content.
+	Key  string
+	Size int64
+	// ModTime is when the content was last stored, including when it was
+	// stored again and deduplicated.
+	ModTime time.Time
+}
+
+type Store interface {
+	// Put stores everything read from r and returns its key. Content that is
+	// already stored is not stored twice. Nothing is stored when reading r
+	// fails, and the error is returned as is.
+	Put(r io.Reader) (Info, error)
+	// Open returns the content stored under key, which the caller must
+	// close.
+	Open(key string) (io.ReadSeekCloser, Info, error)
+	// Delete fails with ErrNotFound when nothing is stored under key.
+	Delete(key string) error
+	// List returns every stored blob, in no particular order.
+	List() ([]Info, error)
+}
+
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidKey reports whether key could be the key of a blob.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
@@ -0,0 +1,146 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempPrefix marks uploads that are still being written.
+const tempPrefix = ".upload-"
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore keeps blobs in dir, creating it when missing. Blobs are spread
+// over subdirectories named after the first two characters of their key, so
+// no directory grows too large.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+// Check reports the store as unhealthy when a file cannot be created in its
+// directory, as when the disk is full or the directory is gone or read-only.
+func (s *fileStore) Check(context.Context) error {
+	f, err := os.CreateTemp(s.dir, tempPrefix+"check-*")
+	if err != nil {
+		return err
+	}
+	err = f.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+func (s *fileStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// Put writes the content to a temporary file while hashing it, then renames
+// the file to its key. Renaming is atomic, so readers never see a blob that
+// is only partly written.
+func (s *fileStore) Put(r io.Reader) (Info, error) {
+	tmp, err := os.CreateTemp(s.dir, tempPrefix+"*")
+	if err != nil {
+		return Info{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	key := hex.EncodeToString(h.Sum(nil))
+	path := s.path(key)
+	now := timeNow()
+	if _, err := os.Stat(path); err == nil {
+		// Already stored. Touching it keeps it from looking unused to
+		// cleanups until the caller had the chance to refer to it.
+		if err := os.Chtimes(path, now, now); err != nil {
+			return Info{}, err
+		}
+		return Info{Key: key, Size: size, ModTime: now}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return Info{}, err
+	}
+	if err := os.Chtimes(tmp.Name(), now, now); err != nil {
+		return Info{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Info{}, err
+	}
+	return Info{Key: key, Size: size, ModTime: now}, nil
+}
+
+func (s *fileStore) Open(key string) (io.ReadSeekCloser, Info, error) {
+	if !ValidKey(key) {
+		return nil, Info{}, ErrNotFound
+	}
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Info{}, ErrNotFound
+	} else if err != nil {
+		return nil, Info{}, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Info{}, err
+	}
+	return f, Info{Key: key, Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+func (s *fileStore) Delete(key string) error {
+	if !ValidKey(key) {
+		return ErrNotFound
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// List skips uploads still being written, and files that are not blobs.
+func (s *fileStore) List() ([]Info, error) {
+	var infos []Info
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() || strings.HasPrefix(name, tempPrefix) || !ValidKey(name) {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Deleted while walking.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		infos = append(infos, Info{Key: name, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
@@ -0,0 +1,158 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// helloKey is the SHA-256 of "hello".
+const helloKey = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+type fileStoreSuite struct {
+	suite.Suite
+	dir   string
+	now   time.Time
+	store Store
+}
+
+func (s *fileStoreSuite) SetupSubTest() {
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time {
+		return s.now
+	}
+	s.dir = s.T().TempDir()
+	var err error
+	s.store, err = NewFileStore(s.dir)
+	s.Require().NoError(err)
+}
+
+func (s *fileStoreSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestFileStoreSuite(t *testing.T) {
+	suite.Run(t, new(fileStoreSuite))
+}
+
+func (s *fileStoreSuite) TestPut() {
+	s.Run("stored by hash", func() {
+		info, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+		s.Equal(Info{Key: helloKey, Size: 5, ModTime: s.now}, info)
+
+		content, err := os.ReadFile(filepath.Join(s.dir, "2c", helloKey))
+		s.Require().NoError(err)
+		s.Equal("hello", string(content))
+	})
+	s.Run("same content is stored once", func() {
+		_, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+		s.now = s.now.Add(time.Hour)
+
+		info, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+		s.Equal(Info{Key: helloKey, Size: 5, ModTime: s.now}, info)
+
+		infos, err := s.store.List()
+		s.Require().NoError(err)
+		s.Require().Len(infos, 1)
+		s.True(s.now.Equal(infos[0].ModTime), "storing again counts as a use")
+	})
+	s.Run("failed read stores nothing", func() {
+		readErr := errors.New("connection reset")
+		_, err := s.store.Put(io.MultiReader(strings.NewReader("hel"), &failingReader{err: readErr}))
+		s.ErrorIs(err, readErr)
+
+		entries, err := os.ReadDir(s.dir)
+		s.Require().NoError(err)
+		s.Empty(entries, "the temporary file is removed")
+	})
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func (s *fileStoreSuite) TestOpen() {
+	s.Run("success", func() {
+		_, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+
+		f, info, err := s.store.Open(helloKey)
+		s.Require().NoError(err)
+		defer f.Close()
+		s.Equal(int64(5), info.Size)
+		_, err = f.Seek(1, io.SeekStart)
+		s.Require().NoError(err)
+		content, err := io.ReadAll(f)
+		s.Require().NoError(err)
+		s.Equal("ello", string(content))
+	})
+	s.Run("not found", func() {
+		_, _, err := s.store.Open(helloKey)
+		s.ErrorIs(err, ErrNotFound)
+	})
+	s.Run("invalid key", func() {
+		_, _, err := s.store.Open("../../etc/passwd")
+		s.ErrorIs(err, ErrNotFound)
+	})
+}
+
+func (s *fileStoreSuite) TestDelete() {
+	s.Run("success", func() {
+		_, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+
+		s.NoError(s.store.Delete(helloKey))
+		_, _, err = s.store.Open(helloKey)
+		s.ErrorIs(err, ErrNotFound)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.store.Delete(helloKey), ErrNotFound)
+	})
+}
+
+func (s *fileStoreSuite) TestList() {
+	s.Run("skips uploads in progress", func() {
+		_, err := s.store.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+		s.Require().NoError(os.WriteFile(filepath.Join(s.dir, tempPrefix+"1"), []byte("hel"), 0o644))
+
+		infos, err := s.store.List()
+		s.Require().NoError(err)
+		s.Require().Len(infos, 1)
+		s.Equal(helloKey, infos[0].Key)
+	})
+	s.Run("empty", func() {
+		infos, err := s.store.List()
+		s.Require().NoError(err)
+		s.Empty(infos)
+	})
+}
+
+func (s *fileStoreSuite) TestCheck() {
+	s.Run("healthy", func() {
+		s.NoError(s.store.(*fileStore).Check(context.Background()))
+
+		entries, err := os.ReadDir(s.dir)
+		s.Require().NoError(err)
+		s.Empty(entries, "the probe file is removed")
+	})
+	s.Run("directory gone", func() {
+		s.Require().NoError(os.RemoveAll(s.dir))
+
+		s.Error(s.store.(*fileStore).Check(context.Background()))
+	})
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldChange is a field of a todo going from one value to another. Values
+// are formatted as text, and a value that is not set is empty.
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// TodoChange is what a single update changed on a todo. ActorID is zero for
+// changes made by the system, like the next occurrence of a recurring
+// todo.
+type TodoChange struct {
+	ID          int
+	WorkspaceID int
+	TodoID      int
+	ActorID     int
+	Fields      []FieldChange
+	At          time.Time
+}
+
+// DiffTodo returns the fields users see that differ between before and
+// after, in a fixed order. Bookkeeping like Rank and UpdatedAt is left out.
+func DiffTodo(before, after Todo) []FieldChange {
+	var changes []FieldChange
+	diff := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	diff("title", before.Title, after.Title)
+	diff("description", before.Description, after.Description)
+	diff("isCompleted", strconv.FormatBool(before.IsCompleted), strconv.FormatBool(after.IsCompleted))
+	diff("priority", string(before.Priority), string(after.Priority))
+	diff("projectId", formatID(before.ProjectID), formatID(after.ProjectID))
+	diff("parentId", formatID(before.ParentID), formatID(after.ParentID))
+	diff("rollup", string(before.Rollup), string(after.Rollup))
+	diff("estimate", formatDuration(before.Estimate), formatDuration(after.Estimate))
+	diff("start", formatDateTime(before.Start), formatDateTime(after.Start))
+	diff("due", formatDateTime(before.Due), formatDateTime(after.Due))
+	diff("recurrence", formatRecurrence(before.Recurrence), formatRecurrence(after.Recurrence))
+	diff("tags", formatTags(before.Tags), formatTags(after.Tags))
+	diff("checklist", before.Checklist.String(), after.Checklist.String())
+	return changes
+}
+
+func formatID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func formatDateTime(d *DateTime) string {
+	switch {
+	case d == nil:
+		return ""
+	case d.AllDay:
+		return d.Time.Format(time.DateOnly)
+	case d.TimeZone != "":
+		return d.Time.Format(time.RFC3339) + " " + d.TimeZone
+	}
+	return d.Time.Format(time.RFC3339)
+}
+
+func formatRecurrence(r *Recurrence) string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
+
+func formatTags(tags []string) string {
+	return strings.Join(slices.Sorted(slices.Values(tags)), ",")
+}
+
+type ActivityKind string
+
+const (
+	ActivityComment ActivityKind = "comment"
+	ActivityChange  ActivityKind = "change"
+)
+
+// Activity is an entry in the activity feed of a todo, which is either a
+// comment or a change.
+type Activity struct {
+	Kind    ActivityKind
+	At      time.Time
+	Comment *Comment
+	Change  *TodoChange
+}
+
+func CommentActivity(comment Comment) Activity {
+	return Activity{Kind: ActivityComment, At: comment.CreatedAt, Comment: &comment}
+}
+
+func ChangeActivity(change TodoChange) Activity {
+	return Activity{Kind: ActivityChange, At: change.At, Change: &change}
+}
+
+// Key orders the feed by time, breaking ties by kind and ID. Keys compare
+// as strings, which is what cursors rely on.
+func (a Activity) Key() string {
+	id := 0
+	if a.Comment != nil {
+		id = a.Comment.ID
+	} else if a.Change != nil {
+		id = a.Change.ID
+	}
+	return fmt.Sprintf("%020d-%s-%010d", a.At.UnixNano(), a.Kind, id)
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidScope = errors.New("invalid scope")
+	ErrNoScopes     = errors.New("at least one scope is required")
+	ErrExpiryPassed = errors.New("expiry is in the past")
+	// ErrAPIKeyPrefixTaken is returned when a new key collides with the
+	// prefix of an existing one.
+	ErrAPIKeyPrefixTaken = errors.New("api key prefix is taken")
+)
+
+// Scope grants an API key access to a kind of resource. Write scopes imply
+// the matching read scope.
+type Scope string
+
+const (
+	ScopeTodosRead     Scope = "todos:read"
+	ScopeTodosWrite    Scope = "todos:write"
+	ScopeTagsRead      Scope = "tags:read"
+	ScopeTagsWrite     Scope = "tags:write"
+	ScopeProjectsRead  Scope = "projects:read"
+	ScopeProjectsWrite Scope = "projects:write"
+)
+
+var Scopes = []Scope{
+	ScopeTodosRead,
+	ScopeTodosWrite,
+	ScopeTagsRead,
+	ScopeTagsWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+}
+
+func (s Scope) Valid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// ParseScopes validates scopes and returns them sorted without duplicates.
+func ParseScopes(scopes []Scope) ([]Scope, error) {
+	if len(scopes) == 0 {
+		return nil, ErrNoScopes
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+// APIKeyPrefix starts every API key, telling it apart from an access token.
+const APIKeyPrefix = "tdk_"
+
+type APIKey struct {
+	ID     int
+	UserID int
+	Name   string
+	// Prefix is the start of the key, kept in the clear so the owner can
+	// recognize it and so the key can be looked up.
+	Prefix string
+	// Hash is the SHA-256 of the whole key.
+	Hash       string
+	Scopes     []Scope
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// HasScope reports whether the key grants scope, directly or through the
+// matching write scope.
+func (k *APIKey) HasScope(scope Scope) bool {
+	if slices.Contains(k.Scopes, scope) {
+		return true
+	}
+	resource, ok := strings.CutSuffix(string(scope), ":read")
+	return ok && slices.Contains(k.Scopes, Scope(resource+":write"))
+}
+
+// Active reports whether the key can be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type CreateAPIKeyInput struct {
+	UserID    int
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []Scope
+	ExpiresAt *time.Time
+}
+
+// IssueAPIKeyInput is what a user asks for a new API key with.
+type IssueAPIKeyInput struct {
+	UserID    int
+	Name      string
+	Scopes    []Scope
+	ExpiresAt *time.Time
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrTooManyAttachments = errors.New("todo has too many attachments")
+)
+
+// MaxAttachmentNameLength is counted in bytes.
+const MaxAttachmentNameLength = 255
+
+// Attachment is a file attached to a todo. Its content is stored once per
+// distinct content, under BlobKey, and shared by every attachment with the
+// same content.
+type Attachment struct {
+	ID          int
+	WorkspaceID int
+	TodoID      int
+	UploaderID  int
+	Name        string
+	// ContentType is sniffed from the content rather than taken from the
+	// client.
+	ContentType string
+	Size        int64
+	BlobKey     string
+	CreatedAt   time.Time
+}
+
+type CreateAttachmentInput struct {
+	TodoID      int
+	UploaderID  int
+	Name        string
+	ContentType string
+	Size        int64
+	BlobKey     string
+}
+
+// NormalizeFileName keeps the last element of a path a client sent as the
+// name of a file, without control characters. Names that are left empty
+// become "attachment", and long names are cut at MaxAttachmentNameLength.
+func NormalizeFileName(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, path.Base(name))
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return "attachment"
+	}
+	for len(name) > MaxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,21 @@
+package entity
+
+type BatchMode string
+
+const (
+	// BatchAtomic applies every item or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies each item independently and reports per item
+	// failures.
+	BatchBestEffort BatchMode = "bestEffort"
+)
+
+type BatchResult struct {
+	Todo *Todo
+	Err  error
+}
+
+type BatchUpdateTodoInput struct {
+	ID    int
+	Input UpdateTodoInput
+}
@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxChecklistItemLength is counted in bytes.
+	MaxChecklistItemLength = 500
+	MaxChecklistItems      = 100
+)
+
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrEmptyChecklistItem    = errors.New("checklist item must not be empty")
+	ErrChecklistItemTooLong  = fmt.Errorf("checklist item must be at most %d bytes", MaxChecklistItemLength)
+	ErrChecklistFull         = fmt.Errorf("checklist can have at most %d items", MaxChecklistItems)
+	ErrChecklistOrder        = errors.New("checklist order must list every item exactly once")
+)
+
+// Checklist is an ordered list of small steps kept on a todo, for steps
+// that do not warrant subtasks. Its methods return a changed copy and leave
+// the checklist they are called on as it was.
+type Checklist struct {
+	Items []ChecklistItem
+	// AutoComplete completes the todo once every item is checked and
+	// reopens it when an item is unchecked or added, like RollupAuto does
+	// for subtasks.
+	AutoComplete bool
+	// NextID is the ID the next item gets, so IDs of removed items are
+	// never handed out again.
+	NextID int
+}
+
+// ChecklistItem IDs are only unique within their checklist.
+type ChecklistItem struct {
+	ID      int
+	Text    string
+	Checked bool
+}
+
+type UpdateChecklistItemInput struct {
+	Text    *string
+	Checked *bool
+}
+
+// NormalizeChecklistItem trims text and checks it is neither empty nor too
+// long.
+func NormalizeChecklistItem(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmptyChecklistItem
+	}
+	if len(text) > MaxChecklistItemLength {
+		return "", ErrChecklistItemTooLong
+	}
+	return text, nil
+}
+
+// Progress returns how many items are checked out of how many there are.
+func (c Checklist) Progress() (checked, total int) {
+	for _, item := range c.Items {
+		if item.Checked {
+			checked++
+		}
+	}
+	return checked, len(c.Items)
+}
+
+// String formats the progress like 3/5, or is empty without items.
+func (c Checklist) String() string {
+	checked, total := c.Progress()
+	if total == 0 {
+		return ""
+	}
+	return strconv.Itoa(checked) + "/" + strconv.Itoa(total)
+}
+
+// Done reports whether there are items and all of them are checked.
+func (c Checklist) Done() bool {
+	checked, total := c.Progress()
+	return total > 0 && checked == total
+}
+
+// Add appends an unchecked item.
+func (c Checklist) Add(text string) (Checklist, ChecklistItem, error) {
+	text, err := NormalizeChecklistItem(text)
+	if err != nil {
+		return c, ChecklistItem{}, err
+	}
+	if len(c.Items) >= MaxChecklistItems {
+		return c, ChecklistItem{}, ErrChecklistFull
+	}
+	item := ChecklistItem{ID: max(c.NextID, 1), Text: text}
+	c.NextID = item.ID + 1
+	c.Items = append(slices.Clone(c.Items), item)
+	return c, item, nil
+}
+
+// Update changes the text or checks the item with the given ID.
+func (c Checklist) Update(id int, input UpdateChecklistItemInput) (Checklist, ChecklistItem, error) {
+	idx := c.indexOf(id)
+	if idx == -1 {
+		return c, ChecklistItem{}, ErrChecklistItemNotFound
+	}
+	item := c.Items[idx]
+	if input.Text != nil {
+		text, err := NormalizeChecklistItem(*input.Text)
+		if err != nil {
+			return c, ChecklistItem{}, err
+		}
+		item.Text = text
+	}
+	if input.Checked != nil {
+		item.Checked = *input.Checked
+	}
+	c.Items = slices.Clone(c.Items)
+	c.Items[idx] = item
+	return c, item, nil
+}
+
+// Reorder puts the items in the order of ids, which must list every item
+// exactly once.
+func (c Checklist) Reorder(ids []int) (Checklist, error) {
+	if len(ids) != len(c.Items) {
+		return c, ErrChecklistOrder
+	}
+	items := make([]ChecklistItem, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		idx := c.indexOf(id)
+		if idx == -1 || seen[id] {
+			return c, ErrChecklistOrder
+		}
+		seen[id] = true
+		items = append(items, c.Items[idx])
+	}
+	c.Items = items
+	return c, nil
+}
+
+func (c Checklist) Remove(id int) (Checklist, error) {
+	idx := c.indexOf(id)
+	if idx == -1 {
+		return c, ErrChecklistItemNotFound
+	}
+	c.Items = slices.Delete(slices.Clone(c.Items), idx, idx+1)
+	return c, nil
+}
+
+// Reset unchecks every item, for the next occurrence of a recurring todo.
+func (c Checklist) Reset() Checklist {
+	c.Items = slices.Clone(c.Items)
+	for i := range c.Items {
+		c.Items[i].Checked = false
+	}
+	return c
+}
+
+func (c Checklist) indexOf(id int) int {
+	return slices.IndexFunc(c.Items, func(item ChecklistItem) bool {
+		return item.ID == id
+	})
+}
+
+// SetChecklist replaces the checklist of the todo and, when the checklist
+// auto-completes, completes or reopens the todo to match it. A blocked todo
+// is left open.
+func (t *Todo) SetChecklist(checklist Checklist) {
+	t.Checklist = checklist
+	if !checklist.AutoComplete || len(checklist.Items) == 0 {
+		return
+	}
+	switch done := checklist.Done(); {
+	case done && !t.Blocked:
+		t.IsCompleted = true
+	case !done:
+		t.IsCompleted = false
+	}
+}
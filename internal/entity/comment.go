@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxCommentLength is counted in bytes.
+const MaxCommentLength = 10000
+
+var (
+	ErrEmptyComment   = errors.New("comment must not be empty")
+	ErrCommentTooLong = fmt.Errorf("comment must be at most %d bytes", MaxCommentLength)
+)
+
+// Comment is a note left on a todo.
+type Comment struct {
+	ID          int
+	WorkspaceID int
+	TodoID      int
+	AuthorID    int
+	Body        string
+	// Mentions are the IDs of the users mentioned in Body who may read the
+	// todo.
+	Mentions []int
+	// Edits are the bodies the comment had before, oldest first.
+	Edits     []CommentEdit
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Edited reports whether the body was ever changed.
+func (c Comment) Edited() bool {
+	return len(c.Edits) > 0
+}
+
+// CommentEdit is a body a comment had until it was edited at EditedAt.
+type CommentEdit struct {
+	Body     string
+	EditedAt time.Time
+}
+
+type CreateCommentInput struct {
+	TodoID   int
+	AuthorID int
+	Body     string
+	Mentions []int
+}
+
+type UpdateCommentInput struct {
+	Body     string
+	Mentions []int
+}
+
+// NormalizeComment trims the body of a comment, which must not be empty or
+// longer than MaxCommentLength.
+func NormalizeComment(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	switch {
+	case body == "":
+		return "", ErrEmptyComment
+	case len(body) > MaxCommentLength:
+		return "", ErrCommentTooLong
+	}
+	return body, nil
+}
+
+// mentionPattern matches an @ followed by an email address, like
+// @jane@example.com, unless the @ is part of a word or address itself.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.+@-])@([\w.+-]+@[\w-]+(?:\.[\w-]+)+)`)
+
+// ParseMentions returns the lowercased email addresses mentioned in body,
+// each once, in the order they are first mentioned.
+func ParseMentions(body string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// Mention tells a user that a comment mentions them.
+type Mention struct {
+	UserID  int
+	Comment Comment
+}
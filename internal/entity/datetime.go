@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrStartAfterDue = errors.New("start must not be after due")
+
+// DateTime is either an instant (timed) or a calendar date (all-day). All-day
+// values keep the date at midnight UTC and are interpreted in the caller's
+// time zone when compared.
+type DateTime struct {
+	Time     time.Time
+	AllDay   bool
+	TimeZone string
+}
+
+func NewDate(year int, month time.Month, day int) DateTime {
+	return DateTime{
+		Time:   time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
+		AllDay: true,
+	}
+}
+
+func NewDateTime(t time.Time, timeZone string) DateTime {
+	return DateTime{
+		Time:     t,
+		TimeZone: timeZone,
+	}
+}
+
+// Day returns the calendar day the value falls on in loc.
+func (d DateTime) Day(loc *time.Location) time.Time {
+	if d.AllDay {
+		y, m, day := d.Time.Date()
+		return time.Date(y, m, day, 0, 0, 0, 0, loc)
+	}
+	y, m, day := d.Time.In(loc).Date()
+	return time.Date(y, m, day, 0, 0, 0, 0, loc)
+}
+
+// Deadline returns the instant after which the value has passed in loc. An
+// all-day value lasts until the end of its day.
+func (d DateTime) Deadline(loc *time.Location) time.Time {
+	if d.AllDay {
+		return d.Day(loc).AddDate(0, 0, 1)
+	}
+	return d.Time
+}
+
+func (d DateTime) sortKey() time.Time {
+	if d.AllDay {
+		return d.Time
+	}
+	return d.Time.UTC()
+}
+
+func ValidateSchedule(start, due *DateTime) error {
+	if start == nil || due == nil {
+		return nil
+	}
+	if start.AllDay || due.AllDay {
+		if start.Day(time.UTC).After(due.Day(time.UTC)) {
+			return ErrStartAfterDue
+		}
+		return nil
+	}
+	if start.sortKey().After(due.sortKey()) {
+		return ErrStartAfterDue
+	}
+	return nil
+}
+
+type DueView string
+
+const (
+	DueOverdue  DueView = "overdue"
+	DueToday    DueView = "today"
+	DueUpcoming DueView = "upcoming"
+)
+
+// location is the zone the wall-clock time of the value is kept in.
+func (d DateTime) location() *time.Location {
+	if d.AllDay {
+		return time.UTC
+	}
+	if loc, err := time.LoadLocation(d.TimeZone); d.TimeZone != "" && err == nil {
+		return loc
+	}
+	return d.Time.Location()
+}
+
+// onDay moves the value to day, given as midnight UTC, keeping its
+// wall-clock time.
+func (d DateTime) onDay(day time.Time) DateTime {
+	if d.AllDay {
+		date := NewDate(day.Date())
+		date.TimeZone = d.TimeZone
+		return date
+	}
+	loc := d.location()
+	wall := d.Time.In(loc)
+	t := time.Date(day.Year(), day.Month(), day.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	if t.Hour() != wall.Hour() || t.Minute() != wall.Minute() {
+		// The time falls into a DST gap. Read it with the offset in force
+		// before the gap, which moves it forward by the gap's length.
+		_, offset := t.AddDate(0, 0, -1).Zone()
+		naive := time.Date(day.Year(), day.Month(), day.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+		t = naive.Add(-time.Duration(offset) * time.Second).In(loc)
+	}
+	return NewDateTime(t, d.TimeZone)
+}
+
+// shift moves the value by days calendar days, keeping its wall-clock time.
+func (d *DateTime) shift(days int) *DateTime {
+	if d == nil {
+		return nil
+	}
+	day := d.Day(d.location())
+	shifted := d.onDay(time.Date(day.Year(), day.Month(), day.Day()+days, 0, 0, 0, 0, time.UTC))
+	return &shifted
+}
+
+// before reports whether the value ends before other starts. An all-day
+// value covers its whole day.
+func (d DateTime) before(other DateTime) bool {
+	if d.AllDay || other.AllDay {
+		return d.Day(time.UTC).Before(other.Day(other.location()))
+	}
+	return d.Time.Before(other.Time)
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrBlockerNotFound  = errors.New("blocking todo not found")
+	ErrDependencySelf   = errors.New("todo cannot depend on itself")
+	ErrDependencyCycle  = errors.New("dependency would create a cycle")
+	ErrDependencyExists = errors.New("dependency already exists")
+	ErrBlocked          = errors.New("todo is blocked by open todos")
+)
+
+// OrderTopological lists every todo after the todos blocking it. It is
+// applied on top of creation order, so unrelated todos keep that order.
+const OrderTopological TodoOrder = "topological"
+
+// Dependency records that the todo cannot be completed before the blocker.
+type Dependency struct {
+	TodoID    int
+	BlockerID int
+	CreatedAt time.Time
+}
+
+// TopologicalOrder reorders todos so that each comes after its blockers,
+// otherwise keeping their order. Dependencies on todos that are not in the
+// slice are ignored.
+func TopologicalOrder(todos []Todo, deps []Dependency) []Todo {
+	blockers := blockersWithin(todos, deps)
+	done := make(map[int]bool, len(todos))
+	ordered := make([]Todo, 0, len(todos))
+	for len(ordered) < len(todos) {
+		// The graph is acyclic, so every pass places at least one todo.
+		for _, todo := range todos {
+			if done[todo.ID] || slices.ContainsFunc(blockers[todo.ID], func(id int) bool { return !done[id] }) {
+				continue
+			}
+			done[todo.ID] = true
+			ordered = append(ordered, todo)
+			break
+		}
+	}
+	return ordered
+}
+
+// CriticalPath returns the longest chain of todos that have to be done one
+// after another, starting with the first blocker. Ties go to the chain
+// ending earliest in topological order.
+func CriticalPath(todos []Todo, deps []Dependency) []Todo {
+	blockers := blockersWithin(todos, deps)
+	byID := make(map[int]Todo, len(todos))
+	length := make(map[int]int, len(todos))
+	prev := make(map[int]int, len(todos))
+	last := 0
+	for _, todo := range TopologicalOrder(todos, deps) {
+		byID[todo.ID] = todo
+		length[todo.ID] = 1
+		for _, id := range blockers[todo.ID] {
+			if length[id]+1 > length[todo.ID] {
+				length[todo.ID] = length[id] + 1
+				prev[todo.ID] = id
+			}
+		}
+		if length[todo.ID] > length[last] {
+			last = todo.ID
+		}
+	}
+
+	var path []Todo
+	for id := last; id != 0; id = prev[id] {
+		path = append(path, byID[id])
+	}
+	slices.Reverse(path)
+	return path
+}
+
+func blockersWithin(todos []Todo, deps []Dependency) map[int][]int {
+	in := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		in[todo.ID] = true
+	}
+	blockers := make(map[int][]int)
+	for _, dep := range deps {
+		if in[dep.TodoID] && in[dep.BlockerID] {
+			blockers[dep.TodoID] = append(blockers[dep.TodoID], dep.BlockerID)
+		}
+	}
+	return blockers
+}
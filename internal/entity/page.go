@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageQuery asks for up to Limit items after Cursor, or from the start when
+// Cursor is empty. A zero Limit means DefaultPageSize.
+type PageQuery struct {
+	Cursor string
+	Limit  int
+}
+
+// Page is a slice of a longer list. NextCursor continues the list, and is
+// empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Paginate returns the page of items that query asks for. Items must be
+// sorted by key, and keys must be unique and compare as strings. The
+// cursor remembers the key of the last item rather than its position, so
+// items added or removed meanwhile do not shift the pages that follow.
+func Paginate[T any](items []T, query PageQuery, key func(T) string) (*Page[T], error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	start := 0
+	if query.Cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		start, _ = slices.BinarySearchFunc(items, string(after), func(item T, target string) int {
+			if strings.Compare(key(item), target) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+
+	end := min(start+limit, len(items))
+	page := &Page[T]{Items: items[start:end]}
+	if end < len(items) {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(key(items[end-1])))
+	}
+	return page, nil
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrProjectArchived = errors.New("project is archived")
+	ErrProjectNotEmpty = errors.New("project still has todos")
+)
+
+// Inbox is the project ID of todos that belong to no project.
+const Inbox = 0
+
+type Project struct {
+	ID          int
+	WorkspaceID int
+	OwnerID     int
+	Name        string
+	Description string
+	ArchivedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (p Project) Archived() bool {
+	return p.ArchivedAt != nil
+}
+
+// ProjectShare gives a user a role in a project on top of their role in the
+// workspace.
+type ProjectShare struct {
+	ProjectID int
+	UserID    int
+	Role      Role
+	CreatedAt time.Time
+}
+
+type CreateProjectInput struct {
+	OwnerID     int
+	Name        string
+	Description string
+}
+
+type UpdateProjectInput struct {
+	Name        *string
+	Description *string
+}
+
+func (in UpdateProjectInput) Apply(project *Project) {
+	if in.Name != nil {
+		project.Name = *in.Name
+	}
+	if in.Description != nil {
+		project.Description = *in.Description
+	}
+}
+
+// ProjectDeleteMode decides what happens to the todos of a deleted project.
+type ProjectDeleteMode string
+
+const (
+	// ProjectDeleteBlock refuses to delete a project that still has todos.
+	ProjectDeleteBlock ProjectDeleteMode = "block"
+	// ProjectDeleteCascade deletes the todos along with the project.
+	ProjectDeleteCascade ProjectDeleteMode = "cascade"
+	// ProjectDeleteInbox moves the todos to the inbox.
+	ProjectDeleteInbox ProjectDeleteMode = "inbox"
+)
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+type TodoOrder string
+
+const (
+	OrderCreated  TodoOrder = "created"
+	OrderManual   TodoOrder = "manual"
+	OrderPriority TodoOrder = "priority"
+	OrderDue      TodoOrder = "due"
+)
+
+// TodoQuery selects and orders todos. The zero value matches every todo in
+// creation order.
+type TodoQuery struct {
+	// Project selects the todos of one project, or the inbox when it
+	// points to Inbox.
+	Project  *int
+	Tags     TagExpr
+	View     DueView
+	Days     int
+	Location *time.Location
+	Order    TodoOrder
+}
+
+func (q TodoQuery) location() *time.Location {
+	if q.Location == nil {
+		return time.UTC
+	}
+	return q.Location
+}
+
+// Match reports whether todo is selected by the query at now. Due views only
+// select open todos with a due date.
+func (q TodoQuery) Match(todo Todo, now time.Time) bool {
+	if q.Project != nil && todo.ProjectID != *q.Project {
+		return false
+	}
+	if q.Tags != nil && !q.Tags.Eval(func(tag string) bool { return slices.Contains(todo.Tags, tag) }) {
+		return false
+	}
+	if q.View == "" {
+		return true
+	}
+	if todo.IsCompleted || todo.Due == nil {
+		return false
+	}
+
+	loc := q.location()
+	now = now.In(loc)
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	day := todo.Due.Day(loc)
+
+	switch q.View {
+	case DueOverdue:
+		return !now.Before(todo.Due.Deadline(loc))
+	case DueToday:
+		return day.Equal(today)
+	case DueUpcoming:
+		return day.After(today) && !day.After(today.AddDate(0, 0, q.Days))
+	}
+	return false
+}
+
+// Compare orders two todos for the query. Due views default to due date
+// order, everything else to creation order.
+func (q TodoQuery) Compare(a, b Todo) int {
+	order := q.Order
+	if order == "" {
+		order = OrderCreated
+		if q.View != "" {
+			order = OrderDue
+		}
+	}
+
+	switch order {
+	case OrderManual:
+		return cmp.Compare(a.Rank, b.Rank)
+	case OrderPriority:
+		return cmp.Or(
+			cmp.Compare(b.Priority.Weight(), a.Priority.Weight()),
+			cmp.Compare(a.Rank, b.Rank),
+		)
+	case OrderDue:
+		return cmp.Or(
+			q.compareDue(a.Due, b.Due),
+			cmp.Compare(a.Rank, b.Rank),
+		)
+	}
+	return a.CreatedAt.Compare(b.CreatedAt)
+}
+
+// compareDue sorts by deadline with undated todos last.
+func (q TodoQuery) compareDue(a, b *DateTime) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Deadline(q.location()).Compare(b.Deadline(q.location()))
+}
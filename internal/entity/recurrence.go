@@ -0,0 +1,383 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRRule     = errors.New("invalid recurrence rule")
+	ErrRecurrenceAnchor = errors.New("recurring todo needs a start or due date")
+)
+
+// maxRecurrencePeriods bounds the search for occurrences, so rules that can
+// never match again (BYMONTHDAY=31 every 12 months from February) end.
+const maxRecurrencePeriods = 10000
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry. N picks the nth such weekday of the month,
+// counted from the end when negative. Zero picks every one.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayCodes[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayCodes[w.Weekday]
+}
+
+// Recurrence is the supported subset of an RFC 5545 RRULE: FREQ, INTERVAL,
+// BYDAY, BYMONTHDAY, COUNT and UNTIL. The series starts at the todo's due
+// date, or its start date when it has no due date, and COUNT includes that
+// first occurrence.
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *DateTime
+}
+
+// ParseRRule parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,TH". The
+// "RRULE:" prefix is optional.
+func ParseRRule(s string) (*Recurrence, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+
+	r := &Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+	for part := range strings.SplitSeq(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.ToUpper(key), strings.ToUpper(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRRule, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if !slices.Contains([]Frequency{FreqDaily, FreqWeekly, FreqMonthly, FreqYearly}, r.Freq) {
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(key, value)
+		case "COUNT":
+			r.Count, err = positive(key, value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = fmt.Errorf("unsupported part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRRule, err)
+		}
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRRule, err)
+	}
+	return r, nil
+}
+
+func (r Recurrence) validate() error {
+	switch {
+	case r.Freq == "":
+		return errors.New("FREQ is required")
+	case r.Count > 0 && r.Until != nil:
+		return errors.New("COUNT and UNTIL are exclusive")
+	case r.Freq == FreqWeekly && len(r.ByMonthDay) > 0:
+		return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	case r.Freq == FreqYearly && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0):
+		return errors.New("BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY")
+	case r.Freq != FreqMonthly && slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool { return w.N != 0 }):
+		return errors.New("numbered BYDAY is only supported with FREQ=MONTHLY")
+	}
+	return nil
+}
+
+func positive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (*DateTime, error) {
+	if t, err := time.Parse("20060102", value); err == nil {
+		date := NewDate(t.Date())
+		return &date, nil
+	}
+	t, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		return nil, fmt.Errorf("UNTIL %q is neither a date nor a UTC date-time", value)
+	}
+	until := NewDateTime(t, "")
+	return &until, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for item := range strings.SplitSeq(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		weekday := slices.Index(weekdayCodes, item[len(item)-2:])
+		if weekday == -1 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		var n int
+		if num := item[:len(item)-2]; num != "" {
+			var err error
+			if n, err = strconv.Atoi(num); err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY %q", item)
+			}
+		}
+		days = append(days, WeekdayNum{N: n, Weekday: time.Weekday(weekday)})
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for item := range strings.SplitSeq(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %q", item)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// String formats the rule in canonical part order, without the "RRULE:"
+// prefix.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.Until.AllDay {
+			parts = append(parts, "UNTIL="+r.Until.Time.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.Time.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// After returns up to n occurrences that follow anchor, the first occurrence
+// of the series. Timed occurrences keep the wall-clock time of the anchor in
+// its time zone across DST changes. A time skipped by a DST gap moves forward
+// by the length of the gap, and a repeated time resolves to its first
+// instance, as RFC 5545 prescribes.
+func (r Recurrence) After(anchor DateTime, n int) []DateTime {
+	if r.Count > 0 {
+		n = min(n, r.Count-1)
+	}
+	loc := anchor.location()
+	first := anchor.Day(loc)
+	first = time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+
+	var occurrences []DateTime
+	for period := 0; period < maxRecurrencePeriods && len(occurrences) < n; period++ {
+		for _, day := range r.candidates(first, period*max(r.Interval, 1)) {
+			if !day.After(first) {
+				continue
+			}
+			occurrence := anchor.onDay(day)
+			if r.Until != nil && r.Until.before(occurrence) {
+				return occurrences
+			}
+			occurrences = append(occurrences, occurrence)
+			if len(occurrences) == n {
+				break
+			}
+		}
+	}
+	return occurrences
+}
+
+// candidates returns the days of the period that starts offset frequency
+// units after first, in order. Days are midnight UTC.
+func (r Recurrence) candidates(first time.Time, offset int) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		day := first.AddDate(0, 0, offset)
+		if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+	case FreqWeekly:
+		monday := first.AddDate(0, 0, -((int(first.Weekday())+6)%7)+7*offset)
+		for i := range 7 {
+			day := monday.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() == first.Weekday() || len(r.ByDay) > 0 && r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case FreqMonthly:
+		month := time.Date(first.Year(), first.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		for i := range daysIn(month) {
+			day := month.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+				if day.Day() == first.Day() {
+					days = append(days, day)
+				}
+				continue
+			}
+			if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+	case FreqYearly:
+		day := time.Date(first.Year()+offset, first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+		// 29 February only recurs in leap years.
+		if day.Day() == first.Day() {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r Recurrence) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	fromStart := (day.Day()-1)/7 + 1
+	fromEnd := -((daysIn(day)-day.Day())/7 + 1)
+	return slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool {
+		return w.Weekday == day.Weekday() && (w.N == 0 || w.N == fromStart || w.N == fromEnd)
+	})
+}
+
+func (r Recurrence) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	fromEnd := day.Day() - daysIn(day) - 1
+	return slices.ContainsFunc(r.ByMonthDay, func(d int) bool {
+		return d == day.Day() || d == fromEnd
+	})
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// ValidateRecurrence checks that a recurring todo has a date to recur from.
+func ValidateRecurrence(r *Recurrence, start, due *DateTime) error {
+	if r != nil && start == nil && due == nil {
+		return ErrRecurrenceAnchor
+	}
+	return nil
+}
+
+// Occurrence is the schedule of one instance of a recurring todo.
+type Occurrence struct {
+	Start *DateTime
+	Due   *DateTime
+}
+
+// Occurrences returns up to n occurrences following the todo. The date the
+// series recurs from lands on each occurrence and the other one moves by the
+// same number of days, keeping its wall-clock time.
+func (t Todo) Occurrences(n int) []Occurrence {
+	anchor := t.Due
+	if anchor == nil {
+		anchor = t.Start
+	}
+	if t.Recurrence == nil || anchor == nil {
+		return nil
+	}
+
+	from := anchor.Day(anchor.location())
+	dates := t.Recurrence.After(*anchor, n)
+	occurrences := make([]Occurrence, len(dates))
+	for i, date := range dates {
+		to := date.Day(anchor.location())
+		days := int(time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC).
+			Sub(time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24)
+		occurrences[i] = Occurrence{Start: t.Start.shift(days), Due: t.Due.shift(days)}
+		if anchor == t.Due {
+			occurrences[i].Due = &date
+		} else {
+			occurrences[i].Start = &date
+		}
+	}
+	return occurrences
+}
+
+// NextOccurrence returns the todo to create when this one is completed, or
+// false once the series has ended.
+func (t Todo) NextOccurrence() (CreateTodoInput, bool) {
+	occurrences := t.Occurrences(1)
+	if len(occurrences) == 0 {
+		return CreateTodoInput{}, false
+	}
+	rule := *t.Recurrence
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	return CreateTodoInput{
+		OwnerID:     t.OwnerID,
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		ProjectID:   t.ProjectID,
+		ParentID:    t.ParentID,
+		Rollup:      t.Rollup,
+		Checklist:   t.Checklist.Reset(),
+		Estimate:    t.Estimate,
+		Start:       occurrences[0].Start,
+		Due:         occurrences[0].Due,
+		Recurrence:  &rule,
+	}, true
+}
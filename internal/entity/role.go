@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrInvalidRole = errors.New("invalid role")
+
+// Role is what a member may do in a workspace, or in a project shared with
+// them. Each role grants everything the roles before it in Roles grant.
+type Role string
+
+const (
+	// RoleGuest members only see the projects shared with them.
+	RoleGuest  Role = "guest"
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+// Roles are ordered from the least to the most privileged.
+var Roles = []Role{RoleGuest, RoleViewer, RoleEditor, RoleOwner}
+
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if !role.Valid() {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
+
+// AtLeast reports whether r grants everything other grants. The empty role
+// grants nothing.
+func (r Role) AtLeast(other Role) bool {
+	return slices.Index(Roles, r) >= slices.Index(Roles, other)
+}
+
+// Action is something a member may be allowed to do with a todo or project.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionShare changes who a project is shared with.
+	ActionShare Action = "share"
+)
+
+var Actions = []Action{ActionCreate, ActionRead, ActionUpdate, ActionDelete, ActionShare}
+
+// Actor is a user acting in a workspace, along with the roles the policy
+// decides on.
+type Actor struct {
+	UserID      int
+	WorkspaceID int
+	Role        Role
+	// ProjectRoles are the roles of the projects shared with the user, by
+	// project ID.
+	ProjectRoles map[int]Role
+}
+
+// RoleIn returns the role of the actor in a project, or in the workspace as
+// a whole for Inbox: the higher of their workspace role and the role the
+// project is shared with them at.
+func (a Actor) RoleIn(projectID int) Role {
+	if role, ok := a.ProjectRoles[projectID]; ok && projectID != Inbox && !a.Role.AtLeast(role) {
+		return role
+	}
+	return a.Role
+}
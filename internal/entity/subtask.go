@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+)
+
+var (
+	ErrParentNotFound = errors.New("parent todo not found")
+	ErrParentCycle    = errors.New("cannot move a todo under itself or its subtasks")
+	ErrHasSubtasks    = errors.New("todo has subtasks")
+)
+
+// Rollup decides how completing todos propagates through a tree. It is set
+// on the parent and governs its direct children.
+type Rollup string
+
+const (
+	RollupNone Rollup = "none"
+	// RollupAuto completes the todo once all of its children are complete
+	// and reopens it when one of them reopens.
+	RollupAuto Rollup = "auto"
+	// RollupCascade completes every subtask when the todo is completed.
+	RollupCascade Rollup = "cascade"
+	// RollupBoth combines RollupAuto and RollupCascade.
+	RollupBoth Rollup = "both"
+)
+
+func (r Rollup) Valid() bool {
+	switch r {
+	case RollupNone, RollupAuto, RollupCascade, RollupBoth:
+		return true
+	}
+	return false
+}
+
+func (r Rollup) AutoCompletes() bool {
+	return r == RollupAuto || r == RollupBoth
+}
+
+func (r Rollup) Cascades() bool {
+	return r == RollupCascade || r == RollupBoth
+}
+
+// SubtaskDeleteMode decides what happens to the subtasks of a deleted todo.
+type SubtaskDeleteMode string
+
+const (
+	// SubtaskDeleteCascade deletes the whole subtree.
+	SubtaskDeleteCascade SubtaskDeleteMode = "cascade"
+	// SubtaskDeletePromote hands the children to the parent of the deleted
+	// todo.
+	SubtaskDeletePromote SubtaskDeleteMode = "promote"
+	// SubtaskDeleteBlock refuses to delete a todo that has subtasks.
+	SubtaskDeleteBlock SubtaskDeleteMode = "block"
+)
@@ -0,0 +1,197 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrInvalidTagName = errors.New("invalid tag name")
+	ErrTagExists      = errors.New("tag already exists")
+	ErrMergeSelf      = errors.New("cannot merge a tag into itself")
+	ErrInvalidTagExpr = errors.New("invalid tag expression")
+)
+
+const maxTagNameLength = 64
+
+type Tag struct {
+	ID        int
+	Name      string
+	TodoCount int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeTagName lower-cases name and checks it only holds letters, digits
+// and the separators - _ . : /, so it can appear in a tag expression.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > maxTagNameLength {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTagName, name)
+	}
+	for _, r := range name {
+		if !isTagRune(r) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidTagName, name)
+		}
+	}
+	switch name {
+	case "and", "or", "not":
+		return "", fmt.Errorf("%w: %q is reserved", ErrInvalidTagName, name)
+	}
+	return name, nil
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.:/", r)
+}
+
+// TagExpr is a boolean expression over tag names, such as
+// "work and (urgent or not home)".
+type TagExpr interface {
+	// Eval reports whether a todo with the given tags matches.
+	Eval(has func(tag string) bool) bool
+	String() string
+}
+
+type (
+	TagName string
+	TagAnd  struct{ Left, Right TagExpr }
+	TagOr   struct{ Left, Right TagExpr }
+	TagNot  struct{ Expr TagExpr }
+)
+
+func (t TagName) Eval(has func(string) bool) bool { return has(string(t)) }
+func (e TagAnd) Eval(has func(string) bool) bool  { return e.Left.Eval(has) && e.Right.Eval(has) }
+func (e TagOr) Eval(has func(string) bool) bool   { return e.Left.Eval(has) || e.Right.Eval(has) }
+func (e TagNot) Eval(has func(string) bool) bool  { return !e.Expr.Eval(has) }
+
+func (t TagName) String() string { return string(t) }
+func (e TagAnd) String() string  { return "(" + e.Left.String() + " and " + e.Right.String() + ")" }
+func (e TagOr) String() string   { return "(" + e.Left.String() + " or " + e.Right.String() + ")" }
+func (e TagNot) String() string  { return "not " + e.Expr.String() }
+
+// ParseTagExpr parses a tag expression. "and", "or" and "not" may also be
+// written as "&", "|" and "!", a comma means "or", and "not" binds tighter
+// than "and", which binds tighter than "or".
+func ParseTagExpr(s string) (TagExpr, error) {
+	p := &tagParser{tokens: tokenizeTagExpr(s)}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidTagExpr, tok)
+	}
+	return expr, nil
+}
+
+func tokenizeTagExpr(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.IndexByte("()&|!,", c) >= 0:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(s) && strings.IndexByte(" \t()&|!,", s[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() (string, bool) {
+	if p.pos == len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *tagParser) accept(ops ...string) bool {
+	tok, ok := p.peek()
+	if ok && slices.ContainsFunc(ops, func(op string) bool { return strings.EqualFold(op, tok) }) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tagParser) or() (TagExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or", "|", ",") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = TagOr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) and() (TagExpr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and", "&") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = TagAnd{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) not() (TagExpr, error) {
+	if p.accept("not", "!") {
+		expr, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return TagNot{Expr: expr}, nil
+	}
+	return p.primary()
+}
+
+func (p *tagParser) primary() (TagExpr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected end", ErrInvalidTagExpr)
+	}
+	if p.accept("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidTagExpr)
+		}
+		return expr, nil
+	}
+
+	name, err := NormalizeTagName(tok)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidTagExpr, tok)
+	}
+	p.pos++
+	return TagName(name), nil
+}
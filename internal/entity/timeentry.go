@@ -0,0 +1,284 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxTimeEntryNoteLength is counted in bytes.
+	MaxTimeEntryNoteLength = 1000
+	// MaxTimeReportDays caps how many days a time report covers.
+	MaxTimeReportDays = 366
+)
+
+var (
+	ErrNegativeEstimate      = errors.New("estimate must not be negative")
+	ErrNoRunningTimer        = errors.New("no timer is running")
+	ErrTimeEntryRange        = errors.New("time entry must end after it starts")
+	ErrTimeEntryInFuture     = errors.New("time entry must not end in the future")
+	ErrTimeEntryNoteTooLong  = fmt.Errorf("time entry note must be at most %d bytes", MaxTimeEntryNoteLength)
+	ErrTimeReportRange       = fmt.Errorf("time report must cover 1 to %d days", MaxTimeReportDays)
+	ErrUnknownTimeReportUnit = errors.New("time report must be grouped by day or week")
+)
+
+// TimeEntry is time a user spent on a todo. An entry without an End is a
+// running timer, and a user has at most one of those across workspaces.
+type TimeEntry struct {
+	ID          int
+	WorkspaceID int
+	TodoID      int
+	UserID      int
+	Start       time.Time
+	End         *time.Time
+	Note        string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Running reports whether the entry is a timer that has not been stopped.
+func (e TimeEntry) Running() bool {
+	return e.End == nil
+}
+
+// Duration is how long the entry lasted, counting a running timer up to now.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	return e.Between(e.Start, e.end(now), now)
+}
+
+// Between is how much of the entry falls between from and to.
+func (e TimeEntry) Between(from, to, now time.Time) time.Duration {
+	start := e.Start
+	if from.After(start) {
+		start = from
+	}
+	end := e.end(now)
+	if to.Before(end) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func (e TimeEntry) end(now time.Time) time.Time {
+	if e.End == nil {
+		return now
+	}
+	return *e.End
+}
+
+// Key orders entries by when they started.
+func (e TimeEntry) Key() string {
+	return fmt.Sprintf("%020d-%010d", e.Start.UnixNano(), e.ID)
+}
+
+// CreateTimeEntryInput creates a stopped entry, or starts a timer when End
+// is nil.
+type CreateTimeEntryInput struct {
+	TodoID int
+	UserID int
+	Start  time.Time
+	End    *time.Time
+	Note   string
+}
+
+// UpdateTimeEntryInput changes the fields that are set. Setting the end of
+// a running timer stops it.
+type UpdateTimeEntryInput struct {
+	Start *time.Time
+	End   *time.Time
+	Note  *string
+}
+
+func (in UpdateTimeEntryInput) Apply(entry *TimeEntry) {
+	if in.Start != nil {
+		entry.Start = *in.Start
+	}
+	if in.End != nil {
+		entry.End = in.End
+	}
+	if in.Note != nil {
+		entry.Note = *in.Note
+	}
+}
+
+// NormalizeTimeEntry trims the note of an entry and checks that a stopped
+// entry ends after it starts and not after now.
+func NormalizeTimeEntry(entry TimeEntry, now time.Time) (TimeEntry, error) {
+	entry.Note = strings.TrimSpace(entry.Note)
+	if len(entry.Note) > MaxTimeEntryNoteLength {
+		return entry, ErrTimeEntryNoteTooLong
+	}
+	if entry.End != nil {
+		if !entry.End.After(entry.Start) {
+			return entry, ErrTimeEntryRange
+		}
+		if entry.End.After(now) {
+			return entry, ErrTimeEntryInFuture
+		}
+	} else if entry.Start.After(now) {
+		return entry, ErrTimeEntryInFuture
+	}
+	return entry, nil
+}
+
+// ValidateEstimate checks the estimate of a todo, where zero means none.
+func ValidateEstimate(estimate time.Duration) error {
+	if estimate < 0 {
+		return ErrNegativeEstimate
+	}
+	return nil
+}
+
+// TimeTotals sums the estimates of todos and the time tracked on them.
+type TimeTotals struct {
+	Estimate time.Duration
+	Tracked  time.Duration
+}
+
+// Add counts a todo and its entries in the totals.
+func (t TimeTotals) Add(todo Todo, entries []TimeEntry, now time.Time) TimeTotals {
+	t.Estimate += todo.Estimate
+	for _, entry := range entries {
+		if entry.TodoID == todo.ID {
+			t.Tracked += entry.Duration(now)
+		}
+	}
+	return t
+}
+
+// Remaining is what is left of the estimate, or zero once it is used up.
+func (t TimeTotals) Remaining() time.Duration {
+	return max(t.Estimate-t.Tracked, 0)
+}
+
+// TimeReportUnit is the length of the periods a time report is grouped by.
+type TimeReportUnit string
+
+const (
+	TimeReportDay TimeReportUnit = "day"
+	// TimeReportWeek groups by ISO week, which starts on Monday.
+	TimeReportWeek TimeReportUnit = "week"
+)
+
+func (u TimeReportUnit) Valid() bool {
+	return u == TimeReportDay || u == TimeReportWeek
+}
+
+// TimeReportQuery selects the entries of a report. Zero IDs select every
+// project, todo and user.
+type TimeReportQuery struct {
+	// From and To are the first and the last day of the report, inclusive.
+	From     time.Time
+	To       time.Time
+	GroupBy  TimeReportUnit
+	Location *time.Location
+	// Project selects the todos of one project, or the inbox when it
+	// points to Inbox.
+	Project *int
+	TodoID  int
+	UserID  int
+}
+
+func (q TimeReportQuery) location() *time.Location {
+	if q.Location == nil {
+		return time.UTC
+	}
+	return q.Location
+}
+
+// bounds returns the instants the report starts and ends at.
+func (q TimeReportQuery) bounds() (time.Time, time.Time) {
+	loc := q.location()
+	y, m, d := q.From.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	y, m, d = q.To.Date()
+	to := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	return from, to
+}
+
+func (q TimeReportQuery) Validate() error {
+	if !q.GroupBy.Valid() {
+		return ErrUnknownTimeReportUnit
+	}
+	from, to := q.bounds()
+	if !to.After(from) || to.After(from.AddDate(0, 0, MaxTimeReportDays)) {
+		return ErrTimeReportRange
+	}
+	return nil
+}
+
+// Match reports whether the entry is selected by the todo and user of the
+// query. Projects are matched on the todos of the entries.
+func (q TimeReportQuery) Match(entry TimeEntry) bool {
+	if q.TodoID != 0 && entry.TodoID != q.TodoID {
+		return false
+	}
+	return q.UserID == 0 || entry.UserID == q.UserID
+}
+
+// TimeReport is the time tracked in each period of a report, including the
+// periods nothing was tracked in.
+type TimeReport struct {
+	Rows    []TimeReportRow
+	Tracked time.Duration
+}
+
+// TimeReportRow is the time tracked in the period starting on Period. The
+// first and last weeks only count the days within the report.
+type TimeReportRow struct {
+	Period  time.Time
+	Tracked time.Duration
+	// Entries counts the entries with time in the period.
+	Entries int
+}
+
+// NewTimeReport groups the time of entries into the periods of query,
+// splitting entries that span several periods between them.
+func NewTimeReport(entries []TimeEntry, query TimeReportQuery, now time.Time) TimeReport {
+	from, to := query.bounds()
+	period := from
+	if query.GroupBy == TimeReportWeek {
+		// Go weeks start on Sunday, ISO weeks on Monday.
+		period = period.AddDate(0, 0, -(int(period.Weekday())+6)%7)
+	}
+
+	var report TimeReport
+	for period.Before(to) {
+		next := period.AddDate(0, 0, 1)
+		if query.GroupBy == TimeReportWeek {
+			next = period.AddDate(0, 0, 7)
+		}
+		start, end := maxTime(period, from), minTime(next, to)
+
+		row := TimeReportRow{Period: period}
+		for _, entry := range entries {
+			if d := entry.Between(start, end, now); d > 0 {
+				row.Tracked += d
+				row.Entries++
+			}
+		}
+		report.Rows = append(report.Rows, row)
+		report.Tracked += row.Tracked
+		period = next
+	}
+	return report
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
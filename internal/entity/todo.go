@@ -1,18 +1,169 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
 
+// MaxTodoID is the largest ID a client may give a todo it upserts, which
+// leaves room for the generated IDs that follow it.
+const MaxTodoID = math.MaxInt32
+
+var (
+	ErrMoveTarget     = errors.New("move target not found")
+	ErrMoveSelf       = errors.New("cannot move a todo relative to itself")
+	ErrTodoIDTooLarge = fmt.Errorf("todo ID must be at most %d", MaxTodoID)
+)
+
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+var priorityWeights = map[Priority]int{
+	PriorityNone:   0,
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+func (p Priority) Valid() bool {
+	_, ok := priorityWeights[p]
+	return ok
+}
+
+// Weight grows with urgency. Unknown and empty priorities weigh as none.
+func (p Priority) Weight() int {
+	return priorityWeights[p]
+}
+
+// Todo is the stored todo. Blocked is kept up to date by the repo and true
+// while any todo blocking this one is open; a blocked todo cannot be
+// completed unless the update clears Blocked, which is what Force does. A
+// todo with a Recurrence is followed by its next occurrence once completed.
+// Estimate is how long the todo is expected to take, or zero.
 type Todo struct {
 	ID          int
+	WorkspaceID int
+	OwnerID     int
 	Title       string
 	Description string
 	IsCompleted bool
+	Blocked     bool
+	Priority    Priority
+	ProjectID   int
+	ParentID    int
+	Rollup      Rollup
+	Rank        string
+	Tags        []string
+	Checklist   Checklist
+	Estimate    time.Duration
+	Start       *DateTime
+	Due         *DateTime
+	Recurrence  *Recurrence
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+type CreateTodoInput struct {
+	OwnerID     int
+	Title       string
+	Description string
+	Priority    Priority
+	ProjectID   int
+	ParentID    int
+	Rollup      Rollup
+	Checklist   Checklist
+	Estimate    time.Duration
+	Start       *DateTime
+	Due         *DateTime
+	Recurrence  *Recurrence
+}
+
+// UpdateTodoInput changes the fields that are set. A Recurrence without a
+// frequency stops the todo from recurring.
 type UpdateTodoInput struct {
 	Title       *string
 	Description *string
 	IsCompleted *bool
+	Priority    *Priority
+	ProjectID   *int
+	Rollup      *Rollup
+	Estimate    *time.Duration
+	Start       *DateTime
+	Due         *DateTime
+	Recurrence  *Recurrence
+	// Force completes the todo even when it is blocked.
+	Force bool
+}
+
+func (in UpdateTodoInput) Apply(todo *Todo) {
+	if in.Title != nil {
+		todo.Title = *in.Title
+	}
+	if in.Description != nil {
+		todo.Description = *in.Description
+	}
+	if in.IsCompleted != nil {
+		todo.IsCompleted = *in.IsCompleted
+	}
+	if in.Priority != nil {
+		todo.Priority = *in.Priority
+	}
+	if in.ProjectID != nil {
+		todo.ProjectID = *in.ProjectID
+	}
+	if in.Rollup != nil {
+		todo.Rollup = *in.Rollup
+	}
+	if in.Estimate != nil {
+		todo.Estimate = *in.Estimate
+	}
+	if in.Start != nil {
+		todo.Start = in.Start
+	}
+	if in.Due != nil {
+		todo.Due = in.Due
+	}
+	if in.Recurrence != nil {
+		todo.Recurrence = in.Recurrence
+		if in.Recurrence.Freq == "" {
+			todo.Recurrence = nil
+		}
+	}
+	if in.Force {
+		todo.Blocked = false
+	}
+}
+
+type ReplaceTodoInput struct {
+	Title       string
+	Description string
+	IsCompleted bool
+	Priority    Priority
+	Rollup      Rollup
+	Estimate    time.Duration
+	Start       *DateTime
+	Due         *DateTime
+	Recurrence  *Recurrence
+	// Force completes the todo even when it is blocked.
+	Force bool
+	// OwnerID is only used when Upsert creates the todo; replacing never
+	// changes the owner.
+	OwnerID int
+}
+
+// MoveTodoInput places a todo directly before or after another one. Exactly
+// one of the two is set.
+type MoveTodoInput struct {
+	Before *int
+	After  *int
 }
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidEmail     = errors.New("invalid email")
+	ErrEmailTaken       = errors.New("email is already registered")
+	ErrPasswordTooShort = errors.New("password is too short")
+	ErrIdentityLinked   = errors.New("identity is already linked to a user")
+)
+
+// MinPasswordLength is counted in bytes, like the KDF sees the password.
+const MinPasswordLength = 8
+
+type User struct {
+	ID    int
+	Email string
+	Name  string
+	// PasswordHash is the encoded hash of the password, never the password
+	// itself. It is empty for users who only log in with SSO.
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type CreateUserInput struct {
+	Email        string
+	Name         string
+	PasswordHash string
+}
+
+// RegisterInput is what a new user signs up with.
+type RegisterInput struct {
+	Email    string
+	Name     string
+	Password string
+}
+
+// ExternalUser is who an identity provider says is logging in.
+type ExternalUser struct {
+	// Issuer and Subject identify the user at the provider for good;
+	// Email may change.
+	Issuer  string
+	Subject string
+	Email   string
+	// EmailVerified is whether the provider vouches that the user owns
+	// Email. Only then may it log in as an existing user with that email.
+	EmailVerified bool
+	Name          string
+}
+
+// AccessToken is a bearer token handed out at login.
+type AccessToken struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// NormalizeEmail trims and lower-cases a bare address, so the same mailbox
+// cannot register twice in different case.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+	return email, nil
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPersonalWorkspace = errors.New("personal workspaces cannot have other members")
+	ErrAlreadyMember     = errors.New("user is already a member")
+)
+
+// Workspace is the tenant boundary: todos, tags and projects of different
+// workspaces never mix, and each workspace numbers them on its own.
+type Workspace struct {
+	ID      int
+	Name    string
+	OwnerID int
+	// Personal workspaces are created for every user on first use and have
+	// no other members.
+	Personal  bool
+	CreatedAt time.Time
+}
+
+// Membership is the role a user has in a workspace.
+type Membership struct {
+	WorkspaceID int
+	UserID      int
+	Role        Role
+}
+
+// Member is a user along with their role in a workspace or project.
+type Member struct {
+	User User
+	Role Role
+}
+
+type CreateWorkspaceInput struct {
+	Name     string
+	OwnerID  int
+	Personal bool
+}
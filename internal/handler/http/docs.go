@@ -0,0 +1,21 @@
+package http
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed static/docs.html
+var docsPage []byte
+
+func NewDocsRoutes(r gin.IRoutes, doc *openapi.Document) {
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	r.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	})
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type docsSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+func (s *docsSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewDocsRoutes(s.router, openapi.New("Todo API", "1.0.0"))
+}
+
+func TestDocsSuite(t *testing.T) {
+	suite.Run(t, new(docsSuite))
+}
+
+func (s *docsSuite) TestOpenAPI() {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	var doc openapi.Document
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &doc))
+	s.Equal(openapi.Version, doc.OpenAPI)
+	s.Equal("Todo API", doc.Info.Title)
+}
+
+func (s *docsSuite) TestDocsPage() {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	s.router.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Header().Get("Content-Type"), "text/html")
+	s.Contains(w.Body.String(), "/openapi.json")
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthRoutes(r gin.IRoutes, registry *health.Registry) {
+	h := &healthHandler{
+		registry: registry,
+	}
+	r.GET("/healthz", h.liveness)
+	r.GET("/readyz", h.readiness)
+}
+
+func (h *healthHandler) liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+func (h *healthHandler) readiness(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	code := http.StatusOK
+	if !report.OK() {
+		code = http.StatusServiceUnavailable
+	}
+	if _, verbose := c.GetQuery("verbose"); !verbose {
+		c.JSON(code, gin.H{"status": report.Status})
+		return
+	}
+	c.JSON(code, report)
+}
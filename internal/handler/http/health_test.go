@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type healthSuite struct {
+	suite.Suite
+	router   *gin.Engine
+	registry *health.Registry
+}
+
+func (s *healthSuite) SetupSubTest() {
+	s.registry = health.NewRegistry()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewHealthRoutes(s.router, s.registry)
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(healthSuite))
+}
+
+func (s *healthSuite) TestLiveness() {
+	s.Run("always ok", func() {
+		s.registry.Register("broken", health.CheckerFunc(func(ctx context.Context) error {
+			return errors.New("broken")
+		}), 0)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"status": "ok"}`, w.Body.String())
+	})
+}
+
+func (s *healthSuite) TestReadiness() {
+	tests := []struct {
+		desc     string
+		path     string
+		setup    func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "ready",
+			path: "/readyz",
+			setup: func() {
+				s.registry.Register("repo", health.CheckerFunc(func(ctx context.Context) error { return nil }), 0)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"status": "ok"}`,
+		},
+		{
+			desc: "check failing",
+			path: "/readyz",
+			setup: func() {
+				s.registry.Register("repo", health.CheckerFunc(func(ctx context.Context) error {
+					return errors.New("broken")
+				}), 0)
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantResp: `{"status": "fail"}`,
+		},
+		{
+			desc: "shutting down",
+			path: "/readyz",
+			setup: func() {
+				s.registry.Shutdown()
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantResp: `{"status": "fail"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *healthSuite) TestReadinessVerbose() {
+	s.Run("detail view", func() {
+		s.registry.Register("repo", health.CheckerFunc(func(ctx context.Context) error {
+			return errors.New("broken")
+		}), 0)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/readyz?verbose", nil)
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusServiceUnavailable, w.Code)
+		s.Contains(w.Body.String(), `"name":"repo"`)
+		s.Contains(w.Body.String(), `"error":"broken"`)
+	})
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	userContextKey   = "middleware.user"
+	apiKeyContextKey = "middleware.apiKey"
+	tokenContextKey  = "middleware.accessToken"
+)
+
+// Auth rejects requests without a valid bearer token, which is either an
+// access token or an API key. The authenticated user is available to later
+// handlers through CurrentUser, and the API key through CurrentAPIKey.
+func Auth(users service.User, keys service.APIKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer realm="todo"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		var (
+			user   *entity.User
+			apiKey *entity.APIKey
+			err    error
+		)
+		if strings.HasPrefix(token, entity.APIKeyPrefix) {
+			user, apiKey, err = keys.Authenticate(token)
+		} else {
+			user, err = users.Authenticate(token)
+		}
+		if errors.Is(err, service.ErrUnauthorized) {
+			c.Header("WWW-Authenticate", `Bearer realm="todo", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(userContextKey, user)
+		if apiKey != nil {
+			c.Set(apiKeyContextKey, apiKey)
+		} else {
+			c.Set(tokenContextKey, token)
+		}
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests made with an API key that lacks any of
+// scopes. Access tokens from a login have every scope.
+func RequireScope(scopes ...entity.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := CurrentAPIKey(c)
+		if apiKey == nil {
+			c.Next()
+			return
+		}
+		for _, scope := range scopes {
+			if !apiKey.HasScope(scope) {
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="todo", error="insufficient_scope", scope="%s"`, scope))
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("api key lacks the %s scope", scope)})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects requests made with an API key, so a leaked key
+// cannot be used to manage credentials.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentAPIKey(c) != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api keys cannot be used here, log in instead"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// CurrentUser returns the user Auth authenticated, or nil outside of it.
+func CurrentUser(c *gin.Context) *entity.User {
+	value, _ := c.Get(userContextKey)
+	user, _ := value.(*entity.User)
+	return user
+}
+
+// CurrentAPIKey returns the API key Auth authenticated with, or nil when
+// the request used an access token.
+func CurrentAPIKey(c *gin.Context) *entity.APIKey {
+	value, _ := c.Get(apiKeyContextKey)
+	apiKey, _ := value.(*entity.APIKey)
+	return apiKey
+}
+
+// AccessToken returns the access token Auth authenticated with, or an empty
+// string when the request used an API key.
+func AccessToken(c *gin.Context) string {
+	return c.GetString(tokenContextKey)
+}
+
+// UserID returns the ID of CurrentUser, or zero when there is none.
+func UserID(c *gin.Context) int {
+	if user := CurrentUser(c); user != nil {
+		return user.ID
+	}
+	return 0
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type authSuite struct {
+	suite.Suite
+	router     *gin.Engine
+	mockUser   *mocks.MockUser
+	mockAPIKey *mocks.MockAPIKey
+}
+
+func (s *authSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockUser = mocks.NewMockUser(ctrl)
+	s.mockAPIKey = mocks.NewMockAPIKey(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.router.Use(Auth(s.mockUser, s.mockAPIKey))
+	s.router.GET("/me", func(c *gin.Context) {
+		body := fmt.Sprintf("user %d", UserID(c))
+		if token := AccessToken(c); token != "" {
+			body += " with " + token
+		}
+		c.String(http.StatusOK, body)
+	})
+	s.router.GET("/todos", RequireScope(entity.ScopeTodosRead), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	s.router.POST("/todos", RequireScope(entity.ScopeTodosWrite), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	s.router.GET("/api-keys", RequireSession(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+func TestAuthSuite(t *testing.T) {
+	suite.Run(t, new(authSuite))
+}
+
+func (s *authSuite) TestAuth() {
+	tests := []struct {
+		desc          string
+		authorization string
+		setup         func()
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+	}{
+		{
+			desc:          "valid token",
+			authorization: "Bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 7}, nil).Times(1)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "user 7 with token-1",
+		},
+		{
+			desc:          "scheme is case-insensitive",
+			authorization: "bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 7}, nil).Times(1)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "user 7 with token-1",
+		},
+		{
+			desc:          "api key",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup: func() {
+				s.mockAPIKey.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(&entity.User{ID: 7}, &entity.APIKey{ID: 3, UserID: 7}, nil).Times(1)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "user 7",
+		},
+		{
+			desc:          "invalid api key",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup: func() {
+				s.mockAPIKey.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(nil, nil, fmt.Errorf("%w: api key is revoked or expired", service.ErrUnauthorized)).Times(1)
+			},
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      `{"error":"unauthorized: api key is revoked or expired"}`,
+			wantChallenge: `Bearer realm="todo", error="invalid_token"`,
+		},
+		{
+			desc:          "missing header",
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      `{"error":"missing bearer token"}`,
+			wantChallenge: `Bearer realm="todo"`,
+		},
+		{
+			desc:          "other scheme",
+			authorization: "Basic dXNlcjpwYXNz",
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      `{"error":"missing bearer token"}`,
+			wantChallenge: `Bearer realm="todo"`,
+		},
+		{
+			desc:          "empty token",
+			authorization: "Bearer ",
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      `{"error":"missing bearer token"}`,
+			wantChallenge: `Bearer realm="todo"`,
+		},
+		{
+			desc:          "invalid token",
+			authorization: "Bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(nil, fmt.Errorf("%w: token is expired", service.ErrUnauthorized)).Times(1)
+			},
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      `{"error":"unauthorized: token is expired"}`,
+			wantChallenge: `Bearer realm="todo", error="invalid_token"`,
+		},
+		{
+			desc:          "service error",
+			authorization: "Bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantStatus, w.Code)
+			s.Equal(tt.wantBody, w.Body.String())
+			s.Equal(tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func (s *authSuite) TestRequireScope() {
+	apiKey := func(scopes ...entity.Scope) func() {
+		return func() {
+			s.mockAPIKey.EXPECT().Authenticate(gomock.Any()).Return(&entity.User{ID: 7}, &entity.APIKey{ID: 3, Scopes: scopes}, nil).Times(1)
+		}
+	}
+
+	tests := []struct {
+		desc          string
+		method        string
+		path          string
+		authorization string
+		setup         func()
+		wantStatus    int
+		wantChallenge string
+	}{
+		{
+			desc:          "access token has every scope",
+			method:        http.MethodPost,
+			path:          "/todos",
+			authorization: "Bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 7}, nil).Times(1)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			desc:          "api key with scope",
+			method:        http.MethodGet,
+			path:          "/todos",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup:         apiKey(entity.ScopeTodosRead),
+			wantStatus:    http.StatusNoContent,
+		},
+		{
+			desc:          "write scope implies read",
+			method:        http.MethodGet,
+			path:          "/todos",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup:         apiKey(entity.ScopeTodosWrite),
+			wantStatus:    http.StatusNoContent,
+		},
+		{
+			desc:          "api key without scope",
+			method:        http.MethodPost,
+			path:          "/todos",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup:         apiKey(entity.ScopeTodosRead, entity.ScopeProjectsWrite),
+			wantStatus:    http.StatusForbidden,
+			wantChallenge: `Bearer realm="todo", error="insufficient_scope", scope="todos:write"`,
+		},
+		{
+			desc:          "session only",
+			method:        http.MethodGet,
+			path:          "/api-keys",
+			authorization: "Bearer tdk_AAAAAAAA_SECRET",
+			setup:         apiKey(entity.Scopes...),
+			wantStatus:    http.StatusForbidden,
+		},
+		{
+			desc:          "session",
+			method:        http.MethodGet,
+			path:          "/api-keys",
+			authorization: "Bearer token-1",
+			setup: func() {
+				s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 7}, nil).Times(1)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", tt.authorization)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantStatus, w.Code, w.Body.String())
+			s.Equal(tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/cloudingcity/todo/internal/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type IdempotencyOptions struct {
+	// MaxUploadBytes caps the multipart bodies spooled to disk for hashing,
+	// rejecting larger ones with a 413. The body carries the multipart
+	// headers along with the file, so it is set a little above the largest
+	// upload. Zero leaves uploads uncapped.
+	MaxUploadBytes int64
+}
+
+// Idempotency replays the stored response when a mutating request is retried
+// with the same Idempotency-Key header. Server errors are not stored so the
+// client can retry them. Behind Auth, keys are per user, so users cannot see
+// each other's responses by guessing keys, and behind Workspace they are also
+// per workspace.
+func Idempotency(store idempotency.Store, opts IdempotencyOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			return
+		}
+		if id := WorkspaceID(c); id != 0 {
+			key = "w" + strconv.Itoa(id) + ":" + key
+		}
+		if id := UserID(c); id != 0 {
+			key = strconv.Itoa(id) + ":" + key
+		}
+
+		// Uploads are spooled to a temporary file while they are hashed, so
+		// retries are told apart by their content without holding the file
+		// in memory, nor more of it on disk than an upload may take.
+		var body []byte
+		if isMultipart(c.ContentType()) {
+			upload := c.Request.Body
+			if opts.MaxUploadBytes > 0 {
+				upload = http.MaxBytesReader(c.Writer, upload, opts.MaxUploadBytes)
+			}
+			spooled, sum, err := spool(upload)
+			if err != nil {
+				c.AbortWithStatusJSON(bodyErrorStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+			defer spooled.Close()
+			c.Request.Body = spooled
+			body = sum
+		} else {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(bodyErrorStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		stored, err := store.Begin(key, fingerprint(c, body))
+		switch {
+		case errors.Is(err, idempotency.ErrKeyMismatch):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case stored != nil:
+			for name, values := range stored.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Writer.WriteHeader(stored.Status)
+			_, _ = c.Writer.Write(stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Release the key if the handler panics so the retry is not stuck
+		// behind an in-progress reservation until it expires.
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(key)
+			}
+		}()
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		completed = true
+
+		if w.status >= http.StatusInternalServerError {
+			store.Release(key)
+		} else {
+			store.Complete(key, idempotency.Response{
+				Status: w.status,
+				Header: w.Header().Clone(),
+				Body:   bytes.Clone(w.buf.Bytes()),
+			})
+		}
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.buf.Bytes())
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func fingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Request.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// spool copies body to a temporary file, which is removed on Close, and
+// returns it rewound along with the SHA-256 of its content.
+func spool(body io.Reader) (io.ReadCloser, []byte, error) {
+	f, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	file := &tempFile{File: f}
+	h := sha256.New()
+	if _, err := io.Copy(f, io.TeeReader(body, h)); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, h.Sum(nil), nil
+}
+
+// tempFile is a file that is removed once closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// bodyErrorStatus is the status for a request body that could not be read.
+func bodyErrorStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/idempotency"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type idempotencySuite struct {
+	suite.Suite
+	router  *gin.Engine
+	calls   atomic.Int32
+	status  int
+	release chan struct{}
+}
+
+func (s *idempotencySuite) SetupSubTest() {
+	s.calls.Store(0)
+	s.status = http.StatusCreated
+	s.release = nil
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.router.Use(Idempotency(idempotency.NewMemoryStore(time.Hour), IdempotencyOptions{MaxUploadBytes: 16}))
+	s.router.POST("/todos", func(c *gin.Context) {
+		n := s.calls.Add(1)
+		if s.release != nil {
+			<-s.release
+		}
+		c.JSON(s.status, gin.H{"call": n})
+	})
+}
+
+func TestIdempotencySuite(t *testing.T) {
+	suite.Run(t, new(idempotencySuite))
+}
+
+func (s *idempotencySuite) do(key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *idempotencySuite) TestReplay() {
+	s.Run("same key and body replays", func() {
+		first := s.do("key-1", `{"title": "a"}`)
+		second := s.do("key-1", `{"title": "a"}`)
+
+		s.Equal(http.StatusCreated, first.Code)
+		s.Equal(http.StatusCreated, second.Code)
+		s.JSONEq(`{"call": 1}`, second.Body.String())
+		s.Equal("true", second.Header().Get(IdempotentReplayedHeader))
+		s.Empty(first.Header().Get(IdempotentReplayedHeader))
+		s.EqualValues(1, s.calls.Load())
+	})
+	s.Run("different keys run twice", func() {
+		s.do("key-1", `{"title": "a"}`)
+		s.do("key-2", `{"title": "a"}`)
+		s.EqualValues(2, s.calls.Load())
+	})
+	s.Run("no key runs twice", func() {
+		s.do("", `{"title": "a"}`)
+		s.do("", `{"title": "a"}`)
+		s.EqualValues(2, s.calls.Load())
+	})
+}
+
+func (s *idempotencySuite) TestMismatch() {
+	s.Run("same key different body", func() {
+		s.do("key-1", `{"title": "a"}`)
+		w := s.do("key-1", `{"title": "b"}`)
+
+		s.Equal(http.StatusUnprocessableEntity, w.Code)
+		s.JSONEq(`{"error": "idempotency key was already used with a different request"}`, w.Body.String())
+		s.EqualValues(1, s.calls.Load())
+	})
+}
+
+func (s *idempotencySuite) TestMultipart() {
+	do := func(body string, contentLength int64) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body))
+		req.ContentLength = contentLength
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	s.Run("same content replays", func() {
+		do("--x--", 5)
+		w := do("--x--", 5)
+
+		s.Equal("true", w.Header().Get(IdempotentReplayedHeader))
+		s.EqualValues(1, s.calls.Load())
+	})
+	s.Run("same size different content mismatches", func() {
+		do("--x--", 5)
+		w := do("--y--", 5)
+
+		s.Equal(http.StatusUnprocessableEntity, w.Code)
+		s.EqualValues(1, s.calls.Load())
+	})
+	s.Run("chunked uploads with different content mismatch", func() {
+		do("--x--", -1)
+		w := do("--x--\r\n", -1)
+
+		s.Equal(http.StatusUnprocessableEntity, w.Code)
+		s.EqualValues(1, s.calls.Load())
+	})
+	s.Run("upload too large", func() {
+		w := do(strings.Repeat("x", 17), 17)
+
+		s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+		s.EqualValues(0, s.calls.Load())
+	})
+	s.Run("handler reads the upload", func() {
+		var got []byte
+		s.router.PUT("/upload", func(c *gin.Context) {
+			got, _ = io.ReadAll(c.Request.Body)
+			c.Status(http.StatusNoContent)
+		})
+		req := httptest.NewRequest(http.MethodPut, "/upload", strings.NewReader("--x--"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		s.router.ServeHTTP(httptest.NewRecorder(), req)
+
+		s.Equal("--x--", string(got))
+	})
+}
+
+func (s *idempotencySuite) TestServerErrorNotStored() {
+	s.Run("retry after 500 runs again", func() {
+		s.status = http.StatusInternalServerError
+		s.do("key-1", `{"title": "a"}`)
+		s.status = http.StatusCreated
+		w := s.do("key-1", `{"title": "a"}`)
+
+		s.Equal(http.StatusCreated, w.Code)
+		s.JSONEq(`{"call": 2}`, w.Body.String())
+	})
+}
+
+func (s *idempotencySuite) TestInFlight() {
+	s.Run("concurrent duplicate conflicts", func() {
+		s.release = make(chan struct{})
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- s.do("key-1", `{"title": "a"}`)
+		}()
+		s.Eventually(func() bool { return s.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+		w := s.do("key-1", `{"title": "a"}`)
+		s.Equal(http.StatusConflict, w.Code)
+		s.Equal("1", w.Header().Get("Retry-After"))
+
+		close(s.release)
+		s.Equal(http.StatusCreated, (<-done).Code)
+	})
+}
+
+func (s *idempotencySuite) TestKeyTooLong() {
+	s.Run("rejected", func() {
+		w := s.do(strings.Repeat("k", 256), `{"title": "a"}`)
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.EqualValues(0, s.calls.Load())
+	})
+}
+
+func (s *idempotencySuite) TestPerUser() {
+	s.Run("users do not share keys", func() {
+		s.router = gin.New()
+		s.router.Use(func(c *gin.Context) {
+			id, _ := strconv.Atoi(c.GetHeader("X-User"))
+			c.Set(userContextKey, &entity.User{ID: id})
+		}, Idempotency(idempotency.NewMemoryStore(time.Hour), IdempotencyOptions{MaxUploadBytes: 16}))
+		s.router.POST("/todos", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"call": s.calls.Add(1)})
+		})
+		do := func(user string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "a"}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			req.Header.Set("X-User", user)
+			s.router.ServeHTTP(w, req)
+			return w
+		}
+
+		s.JSONEq(`{"call": 1}`, do("1").Body.String())
+		s.JSONEq(`{"call": 2}`, do("2").Body.String())
+		s.JSONEq(`{"call": 1}`, do("1").Body.String())
+	})
+}
+
+func (s *idempotencySuite) TestPerWorkspace() {
+	s.Run("workspaces do not share keys", func() {
+		s.router = gin.New()
+		s.router.Use(func(c *gin.Context) {
+			id, _ := strconv.Atoi(c.GetHeader(WorkspaceHeader))
+			c.Set(userContextKey, &entity.User{ID: 1})
+			c.Set(workspaceContextKey, &entity.Actor{UserID: 1, WorkspaceID: id})
+		}, Idempotency(idempotency.NewMemoryStore(time.Hour), IdempotencyOptions{MaxUploadBytes: 16}))
+		s.router.POST("/todos", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"call": s.calls.Add(1)})
+		})
+		do := func(workspace string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "a"}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			req.Header.Set(WorkspaceHeader, workspace)
+			s.router.ServeHTTP(w, req)
+			return w
+		}
+
+		s.JSONEq(`{"call": 1}`, do("1").Body.String())
+		s.JSONEq(`{"call": 2}`, do("2").Body.String())
+		s.JSONEq(`{"call": 1}`, do("1").Body.String())
+	})
+}
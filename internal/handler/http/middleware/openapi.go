@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the largest JSON body OpenAPI buffers unless told
+// otherwise.
+const DefaultMaxBodyBytes = 1 << 20
+
+type OpenAPIOptions struct {
+	// ValidateResponses buffers JSON responses and replaces them with a 500
+	// when they do not match the document. Other responses, like downloads,
+	// are streamed unchecked. Meant for dev and test only.
+	ValidateResponses bool
+	// MaxBodyBytes caps the JSON bodies buffered for validation, rejecting
+	// larger ones with a 413. Zero means DefaultMaxBodyBytes. Uploads are
+	// streamed, so their size is left to the handler.
+	MaxBodyBytes int64
+}
+
+// OpenAPI rejects requests whose path parameters, query parameters or body do
+// not match the operation documented for the matched route.
+func OpenAPI(doc *openapi.Document, opts OpenAPIOptions) gin.HandlerFunc {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return func(c *gin.Context) {
+		op := doc.Operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			// Custom methods like /todos:batchCreate share one gin route, so
+			// they are documented under their literal path.
+			op = doc.Operation(c.Request.Method, c.Request.URL.Path)
+		}
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		if err := validateRequest(c, doc, op, opts.MaxBodyBytes); err != nil {
+			c.AbortWithStatusJSON(bodyErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		w := &jsonWriter{bufferedWriter: bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.streamed {
+			return
+		}
+
+		if err := validateResponse(doc, op, w); err != nil {
+			c.Writer.Header().Del("Content-Length")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "response violates OpenAPI contract: " + err.Error()})
+			return
+		}
+		c.Writer.WriteHeader(w.status)
+		_, _ = c.Writer.Write(w.buf.Bytes())
+	}
+}
+
+func validateRequest(c *gin.Context, doc *openapi.Document, op *openapi.Operation, maxBodyBytes int64) error {
+	for _, p := range op.Parameters {
+		var (
+			raw string
+			ok  bool
+		)
+		switch p.In {
+		case "path":
+			raw = c.Param(p.Name)
+			ok = raw != ""
+		case "query":
+			raw, ok = c.GetQuery(p.Name)
+		case "header":
+			raw = c.GetHeader(p.Name)
+			ok = raw != ""
+		default:
+			continue
+		}
+		if !ok {
+			if p.Required {
+				return fmt.Errorf("%s parameter %s is required", p.In, p.Name)
+			}
+			continue
+		}
+		if _, err := doc.ParseParam(p, raw); err != nil {
+			return fmt.Errorf("%s parameter %w", p.In, err)
+		}
+	}
+
+	if op.RequestBody == nil {
+		return nil
+	}
+	if isMultipart(c.ContentType()) {
+		// Uploads are streamed to the handler, so only the content type is
+		// checked.
+		if c.Request.ContentLength == 0 && op.RequestBody.Required {
+			return fmt.Errorf("request body is required")
+		}
+		mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+		if _, ok := op.RequestBody.Content[mediaType]; !ok {
+			return fmt.Errorf("unsupported content type %q", mediaType)
+		}
+		return nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return fmt.Errorf("request body is required")
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.ContentType())
+	if err != nil {
+		mediaType = "application/json"
+	}
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	v, err := openapi.DecodeJSON(body)
+	if err != nil {
+		return fmt.Errorf("request body: %w", err)
+	}
+	if err := doc.Validate(content.Schema, v); err != nil {
+		return fmt.Errorf("request body: %w", err)
+	}
+	return nil
+}
+
+func validateResponse(doc *openapi.Document, op *openapi.Operation, w *jsonWriter) error {
+	resp, ok := op.Responses[strconv.Itoa(w.status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("undocumented status %d", w.status)
+	}
+	if len(resp.Content) == 0 {
+		if w.buf.Len() > 0 {
+			return fmt.Errorf("status %d must not have a body", w.status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	content, ok := resp.Content[mediaType]
+	if !ok {
+		// Downloads are documented with ranges like */*.
+		content, ok = resp.Content[strings.Split(mediaType, "/")[0]+"/*"]
+	}
+	if !ok {
+		content, ok = resp.Content["*/*"]
+	}
+	if !ok {
+		return fmt.Errorf("undocumented content type %q for status %d", mediaType, w.status)
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+	v, err := openapi.DecodeJSON(w.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("status %d: %w", w.status, err)
+	}
+	if err := doc.Validate(content.Schema, v); err != nil {
+		return fmt.Errorf("status %d: %w", w.status, err)
+	}
+	return nil
+}
+
+// isMultipart reports whether contentType is a multipart body, which is
+// streamed rather than buffered.
+func isMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.HasPrefix(mediaType, "multipart/")
+}
+
+// jsonWriter buffers JSON responses for validation. It streams any other
+// response to the client, so downloads are not held in memory.
+type jsonWriter struct {
+	bufferedWriter
+	streamed bool
+}
+
+func (w *jsonWriter) Write(b []byte) (int, error) {
+	if w.stream() {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.bufferedWriter.Write(b)
+}
+
+func (w *jsonWriter) WriteString(s string) (int, error) {
+	if w.stream() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.bufferedWriter.WriteString(s)
+}
+
+func (w *jsonWriter) Size() int {
+	if w.streamed {
+		return w.ResponseWriter.Size()
+	}
+	return w.bufferedWriter.Size()
+}
+
+// stream reports whether the response goes to the client as it is written,
+// deciding on the first body write by the content type set by then.
+func (w *jsonWriter) stream() bool {
+	if w.streamed || w.buf.Len() > 0 {
+		return w.streamed
+	}
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mediaType != "application/json" {
+		w.streamed = true
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	return w.streamed
+}
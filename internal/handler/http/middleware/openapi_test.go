@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type itemReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type itemResp struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type openAPISuite struct {
+	suite.Suite
+	doc     *openapi.Document
+	router  *gin.Engine
+	handler gin.HandlerFunc
+}
+
+func (s *openAPISuite) SetupSubTest() {
+	doc := openapi.New("test", "1.0.0")
+	doc.Add(http.MethodPost, "/items/:id", &openapi.Operation{
+		OperationID: "createItem",
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "dryRun", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("ItemRequest", itemReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "Created", Content: openapi.JSON(doc.Ref("ItemResponse", itemResp{}, openapi.Output))},
+			"204": {Description: "No content"},
+		},
+	})
+
+	doc.Add(http.MethodPut, "/items/:id/file", &openapi.Operation{
+		OperationID: "uploadItemFile",
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"multipart/form-data": {Schema: &openapi.Schema{Type: "object"}},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "No content"},
+		},
+	})
+	doc.Add(http.MethodGet, "/items/:id/file", &openapi.Operation{
+		OperationID: "downloadItemFile",
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "OK", Content: map[string]openapi.MediaType{
+				"*/*": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+			}},
+		},
+	})
+
+	s.handler = func(c *gin.Context) {
+		c.JSON(http.StatusCreated, itemResp{ID: 1, Name: "x"})
+	}
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.doc = doc
+	s.router.Use(OpenAPI(doc, OpenAPIOptions{ValidateResponses: true}))
+	s.router.POST("/items/:id", func(c *gin.Context) { s.handler(c) })
+	s.router.PUT("/items/:id/file", func(c *gin.Context) {
+		form, err := c.MultipartForm()
+		if err != nil || len(form.File["file"]) != 1 {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	s.router.GET("/items/:id/file", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte("\x89PNG"))
+	})
+	s.router.GET("/undocumented", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+}
+
+func TestOpenAPISuite(t *testing.T) {
+	suite.Run(t, new(openAPISuite))
+}
+
+func (s *openAPISuite) TestRequest() {
+	tests := []struct {
+		desc        string
+		path        string
+		contentType string
+		body        string
+		wantCode    int
+		wantResp    string
+	}{
+		{
+			desc:        "valid",
+			path:        "/items/1?dryRun=true",
+			contentType: "application/json",
+			body:        `{"name": "x"}`,
+			wantCode:    http.StatusCreated,
+			wantResp:    `{"id": 1, "name": "x"}`,
+		},
+		{
+			desc:        "invalid path parameter",
+			path:        "/items/abc",
+			contentType: "application/json",
+			body:        `{"name": "x"}`,
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "path parameter id: invalid integer \"abc\""}`,
+		},
+		{
+			desc:        "invalid query parameter",
+			path:        "/items/1?dryRun=maybe",
+			contentType: "application/json",
+			body:        `{"name": "x"}`,
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "query parameter dryRun: invalid boolean \"maybe\""}`,
+		},
+		{
+			desc:        "missing body",
+			path:        "/items/1",
+			contentType: "application/json",
+			body:        ``,
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "request body is required"}`,
+		},
+		{
+			desc:        "invalid body",
+			path:        "/items/1",
+			contentType: "application/json",
+			body:        `{"name": 1}`,
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "request body: name: expected string, got integer"}`,
+		},
+		{
+			desc:        "unsupported content type",
+			path:        "/items/1",
+			contentType: "text/plain",
+			body:        `name=x`,
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "unsupported content type \"text/plain\""}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *openAPISuite) TestResponse() {
+	tests := []struct {
+		desc     string
+		handler  gin.HandlerFunc
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "no content",
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc: "undocumented status",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, itemResp{ID: 1, Name: "x"})
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "response violates OpenAPI contract: undocumented status 200"}`,
+		},
+		{
+			desc: "drifted body",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"id": "1", "name": "x"})
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "response violates OpenAPI contract: status 201: id: expected integer, got string"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.handler = tt.handler
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(`{"name": "x"}`))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp != "" {
+				s.JSONEq(tt.wantResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *openAPISuite) TestBodyTooLarge() {
+	s.Run("rejected", func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(`{"name": "`+strings.Repeat("x", DefaultMaxBodyBytes)+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+		s.JSONEq(`{"error": "http: request body too large"}`, w.Body.String())
+	})
+	s.Run("configured limit", func() {
+		router := gin.New()
+		router.Use(OpenAPI(s.doc, OpenAPIOptions{MaxBodyBytes: 8}))
+		router.POST("/items/:id", func(c *gin.Context) { s.handler(c) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(`{"name": "x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func (s *openAPISuite) TestMultipart() {
+	s.Run("streamed to the handler", func() {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, err := mw.CreateFormFile("file", "a.txt")
+		s.Require().NoError(err)
+		_, _ = part.Write([]byte("hello"))
+		s.Require().NoError(mw.Close())
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/items/1/file", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("unsupported content type", func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/items/1/file", strings.NewReader("hello"))
+		req.Header.Set("Content-Type", "multipart/mixed; boundary=x")
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.JSONEq(`{"error": "unsupported content type \"multipart/mixed\""}`, w.Body.String())
+	})
+	s.Run("download matches any content type", func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/items/1/file", nil)
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusOK, w.Code)
+		s.Equal("image/png", w.Header().Get("Content-Type"))
+	})
+	s.Run("download is not buffered", func() {
+		w := httptest.NewRecorder()
+		router := gin.New()
+		router.Use(OpenAPI(s.doc, OpenAPIOptions{ValidateResponses: true}))
+		router.GET("/items/:id/file", func(c *gin.Context) {
+			c.Data(http.StatusOK, "image/png", []byte("\x89PNG"))
+			s.Equal("\x89PNG", w.Body.String(), "written before the handler returns")
+		})
+
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1/file", nil))
+		s.Equal(http.StatusOK, w.Code)
+	})
+}
+
+func (s *openAPISuite) TestUndocumentedRoute() {
+	s.Run("passes through", func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/undocumented", nil)
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusOK, w.Code)
+		s.Equal("ok", w.Body.String())
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitOptions struct {
+	// Default limits every route without a limiter of its own. A nil
+	// Default leaves those routes unlimited.
+	Default ratelimit.Limiter
+	// Routes has the limiters of single routes, keyed by method and route
+	// pattern, like "POST /v1/todos". Requests to those routes do not count
+	// against Default, and a nil limiter leaves the route unlimited.
+	Routes map[string]ratelimit.Limiter
+	// PreAuth limits requests to authenticated routes by IP address before
+	// Auth checks their credentials, so guessing tokens and API keys is
+	// limited too. It counts every such request, so it is meant to be looser
+	// than Default. A nil PreAuth leaves them unlimited until Auth.
+	PreAuth ratelimit.Limiter
+}
+
+// RateLimit gives every client a token bucket per limiter and answers 429
+// once it is empty. Clients are told apart by API key, then by user, and by
+// IP address before Auth, so it runs after Auth wherever there is one. The
+// RateLimit-* headers of draft-ietf-httpapi-ratelimit-headers tell clients
+// how much is left.
+func RateLimit(opts RateLimitOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, ok := opts.Routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			limiter = opts.Default
+		}
+		limit(c, limiter, rateLimitKey(c))
+	}
+}
+
+// PreAuthRateLimit limits clients by IP address with opts.PreAuth. It runs
+// before Auth, which would otherwise answer any number of bad credentials.
+func PreAuthRateLimit(opts RateLimitOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit(c, opts.PreAuth, "ip:"+c.ClientIP())
+	}
+}
+
+// limit counts the request against key, answering 429 once its bucket is
+// empty. A nil limiter lets every request through.
+func limit(c *gin.Context, limiter ratelimit.Limiter, key string) {
+	if limiter == nil {
+		c.Next()
+		return
+	}
+	res := limiter.Allow(key)
+	if res.Limit.IsZero() {
+		c.Next()
+		return
+	}
+	c.Header("RateLimit-Policy", strconv.Itoa(res.Limit.Requests)+";w="+seconds(res.Limit.Per))
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit.Requests))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", seconds(res.Reset))
+	if !res.Allowed {
+		c.Header("Retry-After", seconds(res.RetryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, retry in " + seconds(res.RetryAfter) + "s"})
+		return
+	}
+	c.Next()
+}
+
+// rateLimitKey is who a request counts against.
+func rateLimitKey(c *gin.Context) string {
+	if apiKey := CurrentAPIKey(c); apiKey != nil {
+		return "apikey:" + strconv.Itoa(apiKey.ID)
+	}
+	if user := CurrentUser(c); user != nil {
+		return "user:" + strconv.Itoa(user.ID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds d up to whole seconds, so clients never retry too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type rateLimitSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+func (s *rateLimitSuite) SetupSubTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	// The user and API key come from headers instead of Auth.
+	s.router.Use(func(c *gin.Context) {
+		if id, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+			c.Set(userContextKey, &entity.User{ID: id})
+		}
+		if id, err := strconv.Atoi(c.GetHeader("X-Api-Key")); err == nil {
+			c.Set(apiKeyContextKey, &entity.APIKey{ID: id})
+		}
+	}, RateLimit(RateLimitOptions{
+		Default: ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 2, Per: time.Minute}),
+		Routes: map[string]ratelimit.Limiter{
+			"POST /todos":    ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 1, Per: time.Minute}),
+			"GET /todos/:id": nil,
+		},
+	}))
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		s.router.Handle(method, "/todos", func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+	}
+	s.router.GET("/todos/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, new(rateLimitSuite))
+}
+
+func (s *rateLimitSuite) do(method, path string, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *rateLimitSuite) TestRateLimit() {
+	s.Run("headers", func() {
+		w := s.do(http.MethodGet, "/todos", nil)
+		s.Equal(http.StatusNoContent, w.Code)
+		s.Equal(http.Header{
+			"Ratelimit-Policy":    {"2;w=60"},
+			"Ratelimit-Limit":     {"2"},
+			"Ratelimit-Remaining": {"1"},
+			"Ratelimit-Reset":     {"30"},
+		}, w.Header())
+
+		w = s.do(http.MethodGet, "/todos", nil)
+		s.Equal(http.StatusNoContent, w.Code)
+		s.Equal("0", w.Header().Get("RateLimit-Remaining"))
+		s.Equal("60", w.Header().Get("RateLimit-Reset"))
+	})
+	s.Run("exceeded", func() {
+		s.do(http.MethodGet, "/todos", nil)
+		s.do(http.MethodGet, "/todos", nil)
+
+		w := s.do(http.MethodGet, "/todos", nil)
+		s.Equal(http.StatusTooManyRequests, w.Code)
+		s.Equal("30", w.Header().Get("Retry-After"))
+		s.Equal("0", w.Header().Get("RateLimit-Remaining"))
+		s.JSONEq(`{"error": "rate limit exceeded, retry in 30s"}`, w.Body.String())
+	})
+	s.Run("clients have their own buckets", func() {
+		clients := []http.Header{
+			nil,
+			{"X-User": {"1"}},
+			{"X-User": {"2"}},
+			{"X-User": {"1"}, "X-Api-Key": {"1"}},
+		}
+		for _, header := range clients {
+			s.do(http.MethodGet, "/todos", header)
+			s.do(http.MethodGet, "/todos", header)
+		}
+		for _, header := range clients {
+			s.Equal(http.StatusTooManyRequests, s.do(http.MethodGet, "/todos", header).Code, header)
+		}
+
+		w := s.do(http.MethodGet, "/todos", http.Header{"X-User": {"1"}, "X-Api-Key": {"2"}})
+		s.Equal(http.StatusNoContent, w.Code, "every API key has a bucket of its own")
+	})
+	s.Run("route limits", func() {
+		w := s.do(http.MethodPost, "/todos", nil)
+		s.Equal(http.StatusNoContent, w.Code)
+		s.Equal("1", w.Header().Get("RateLimit-Limit"))
+		s.Equal(http.StatusTooManyRequests, s.do(http.MethodPost, "/todos", nil).Code)
+
+		w = s.do(http.MethodGet, "/todos", nil)
+		s.Equal(http.StatusNoContent, w.Code, "the route limit does not count against the default")
+		s.Equal("1", w.Header().Get("RateLimit-Remaining"))
+	})
+	s.Run("unlimited routes", func() {
+		for range 5 {
+			w := s.do(http.MethodGet, "/todos/1", nil)
+			s.Equal(http.StatusNoContent, w.Code)
+			s.Empty(w.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	WorkspaceHeader     = "X-Workspace-ID"
+	workspaceContextKey = "middleware.workspace"
+)
+
+// Workspace resolves the workspace a request runs in from the X-Workspace-ID
+// header, falling back to the user's personal workspace when it is absent.
+// It runs after Auth. Workspaces the user is not a member of are reported as
+// not found. The user acting in the workspace, along with their roles, is
+// available to later handlers through CurrentActor.
+func Workspace(workspaces service.Workspace) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var workspaceID int
+		if header := c.GetHeader(WorkspaceHeader); header != "" {
+			id, err := strconv.Atoi(header)
+			if err != nil || id < 1 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid workspace id"})
+				return
+			}
+			workspaceID = id
+		}
+
+		actor, err := workspaces.Resolve(UserID(c), workspaceID)
+		if errors.Is(err, service.ErrNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "workspace not found"})
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(workspaceContextKey, actor)
+		c.Next()
+	}
+}
+
+// CurrentActor returns the actor Workspace resolved. Outside of it the
+// actor has no role, so the policy denies them everything.
+func CurrentActor(c *gin.Context) entity.Actor {
+	value, _ := c.Get(workspaceContextKey)
+	if actor, ok := value.(*entity.Actor); ok {
+		return *actor
+	}
+	return entity.Actor{UserID: UserID(c)}
+}
+
+// WorkspaceID returns the ID of the workspace of CurrentActor, or zero when
+// there is none.
+func WorkspaceID(c *gin.Context) int {
+	return CurrentActor(c).WorkspaceID
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type workspaceSuite struct {
+	suite.Suite
+	router        *gin.Engine
+	mockWorkspace *mocks.MockWorkspace
+}
+
+func (s *workspaceSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockWorkspace = mocks.NewMockWorkspace(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.router.Use(func(c *gin.Context) {
+		c.Set(userContextKey, &entity.User{ID: 7})
+	}, Workspace(s.mockWorkspace))
+	s.router.GET("/todos", func(c *gin.Context) {
+		c.String(http.StatusOK, fmt.Sprintf("workspace %d as %s", WorkspaceID(c), CurrentActor(c).Role))
+	})
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(workspaceSuite))
+}
+
+func (s *workspaceSuite) TestWorkspace() {
+	tests := []struct {
+		desc       string
+		header     string
+		setup      func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc: "personal by default",
+			setup: func() {
+				s.mockWorkspace.EXPECT().Resolve(7, 0).Return(&entity.Actor{UserID: 7, WorkspaceID: 3, Role: entity.RoleOwner}, nil).Times(1)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "workspace 3 as owner",
+		},
+		{
+			desc:   "from header",
+			header: "2",
+			setup: func() {
+				s.mockWorkspace.EXPECT().Resolve(7, 2).Return(&entity.Actor{UserID: 7, WorkspaceID: 2, Role: entity.RoleViewer}, nil).Times(1)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "workspace 2 as viewer",
+		},
+		{
+			desc:       "not a number",
+			header:     "abc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid workspace id"}`,
+		},
+		{
+			desc:       "not positive",
+			header:     "0",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid workspace id"}`,
+		},
+		{
+			desc:   "not a member",
+			header: "2",
+			setup: func() {
+				s.mockWorkspace.EXPECT().Resolve(7, 2).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"workspace not found"}`,
+		},
+		{
+			desc:   "service error",
+			header: "2",
+			setup: func() {
+				s.mockWorkspace.EXPECT().Resolve(7, 2).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.header != "" {
+				req.Header.Set(WorkspaceHeader, tt.header)
+			}
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantStatus, w.Code)
+			s.Equal(tt.wantBody, w.Body.String())
+		})
+	}
+}
+
+func (s *workspaceSuite) TestCurrentActor() {
+	s.Run("outside of Workspace", func() {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(userContextKey, &entity.User{ID: 7})
+		})
+		router.GET("/todos", func(c *gin.Context) {
+			c.JSON(http.StatusOK, CurrentActor(c))
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos", nil))
+		s.JSONEq(`{"UserID": 7, "WorkspaceID": 0, "Role": "", "ProjectRoles": null}`, w.Body.String())
+	})
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/handler/http/v1"
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/cloudingcity/todo/internal/idempotency"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type openAPISuite struct {
+	suite.Suite
+}
+
+func TestOpenAPISuite(t *testing.T) {
+	suite.Run(t, new(openAPISuite))
+}
+
+// TestRoutesDocumented walks the routes NewRouter registers, so a route
+// added to any group is checked without listing it here.
+func (s *openAPISuite) TestRoutesDocumented() {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	ctrl := gomock.NewController(s.T())
+	NewRouter(r, health.NewRegistry(), idempotency.NewMemoryStore(time.Hour), Options{},
+		mocks.NewMockUser(ctrl), mocks.NewMockSSO(ctrl), mocks.NewMockAPIKey(ctrl), mocks.NewMockWorkspace(ctrl),
+		mocks.NewMockTodo(ctrl), mocks.NewMockComment(ctrl), mocks.NewMockAttachment(ctrl), mocks.NewMockProject(ctrl), mocks.NewMockTimeEntry(ctrl))
+	doc := v1.NewOpenAPI()
+
+	var routes []gin.RouteInfo
+	for _, route := range r.Routes() {
+		switch {
+		case !strings.HasPrefix(route.Path, "/v1/"):
+			// Health checks and the docs are not part of the API.
+		case strings.HasSuffix(route.Path, ":action"):
+			for _, method := range v1.TodoCustomMethods() {
+				routes = append(routes, gin.RouteInfo{
+					Method: route.Method,
+					Path:   strings.TrimSuffix(route.Path, "action") + method,
+				})
+			}
+		default:
+			routes = append(routes, route)
+		}
+	}
+	s.Require().NotEmpty(routes)
+
+	for _, route := range routes {
+		s.Run(route.Method+" "+route.Path, func() {
+			op := doc.Operation(route.Method, route.Path)
+			s.Require().NotNil(op, "route is missing from the OpenAPI document")
+			s.NotEmpty(op.OperationID)
+			s.NotEmpty(op.Responses)
+		})
+	}
+}
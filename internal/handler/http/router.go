@@ -1,15 +1,84 @@
 package http
 
 import (
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
 	"github.com/cloudingcity/todo/internal/handler/http/v1"
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/cloudingcity/todo/internal/idempotency"
 	"github.com/cloudingcity/todo/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(r *gin.Engine, todoSrv service.Todo) {
+// Options tunes the middleware NewRouter mounts.
+type Options struct {
+	RateLimits  middleware.RateLimitOptions
+	Idempotency middleware.IdempotencyOptions
+	// ValidateResponses checks JSON responses against the OpenAPI document.
+	// Tests and dev setups turn it on; production leaves it off.
+	ValidateResponses bool
+}
+
+// NewRouter registers every route. SSO is left out when ssoSrv is nil.
+func NewRouter(r *gin.Engine, healthReg *health.Registry, idemStore idempotency.Store, opts Options, userSrv service.User, ssoSrv service.SSO, apiKeySrv service.APIKey, workspaceSrv service.Workspace, todoSrv service.Todo, commentSrv service.Comment, attachmentSrv service.Attachment, projectSrv service.Project, timeEntrySrv service.TimeEntry) {
+	doc := v1.NewOpenAPI()
+
+	NewHealthRoutes(r, healthReg)
+	NewDocsRoutes(r, doc)
+
 	v1Group := r.Group("/v1")
+
+	// Requests are validated once they are past Auth and the rate limit, so
+	// anonymous callers are not told the schema of authenticated routes and
+	// throttled ones are not parsed.
+	validate := middleware.OpenAPI(doc, middleware.OpenAPIOptions{
+		ValidateResponses: opts.ValidateResponses,
+	})
+
+	// Public routes are limited by IP address, the rest by API key or user
+	// once Auth knows them.
+	rateLimit := middleware.RateLimit(opts.RateLimits)
+	publicGroup := v1Group.Group("", rateLimit, validate)
+	{
+		v1.NewPingRoutes(publicGroup)
+		v1.NewUserRoutes(publicGroup, userSrv)
+		if ssoSrv != nil {
+			v1.NewSSORoutes(publicGroup, ssoSrv)
+		}
+	}
+
+	// Credentials are checked only once the IP address is within its limit,
+	// so bad tokens and API keys cannot be guessed at any rate.
+	authGroup := v1Group.Group("",
+		middleware.PreAuthRateLimit(opts.RateLimits),
+		middleware.Auth(userSrv, apiKeySrv),
+		rateLimit,
+		validate,
+	)
+
+	// Idempotency runs after Auth so keys are scoped to the user.
+	accountGroup := authGroup.Group("", middleware.Idempotency(idemStore, opts.Idempotency))
+	{
+		v1.NewCurrentUserRoutes(accountGroup, userSrv)
+		if ssoSrv != nil {
+			v1.NewSSOSessionRoutes(accountGroup, ssoSrv)
+		}
+		v1.NewAPIKeyRoutes(accountGroup, apiKeySrv)
+		v1.NewWorkspaceRoutes(accountGroup, workspaceSrv)
+	}
+
+	// Todos, tags and projects live in a workspace, and their idempotency
+	// keys are scoped to it as well.
+	tenantGroup := authGroup.Group("",
+		middleware.Workspace(workspaceSrv),
+		middleware.Idempotency(idemStore, opts.Idempotency),
+	)
 	{
-		v1.NewPingRoutes(v1Group)
-		v1.NewTodoRoutes(v1Group, todoSrv)
+		v1.NewTodoRoutes(tenantGroup, todoSrv)
+		v1.NewCommentRoutes(tenantGroup, commentSrv)
+		v1.NewAttachmentRoutes(tenantGroup, attachmentSrv)
+		v1.NewChecklistRoutes(tenantGroup, todoSrv)
+		v1.NewTagRoutes(tenantGroup, todoSrv)
+		v1.NewProjectRoutes(tenantGroup, projectSrv)
+		v1.NewTimeEntryRoutes(tenantGroup, timeEntrySrv)
 	}
 }
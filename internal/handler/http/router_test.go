@@ -0,0 +1,1005 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/blob"
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/health"
+	"github.com/cloudingcity/todo/internal/idempotency"
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/cloudingcity/todo/internal/oidc"
+	"github.com/cloudingcity/todo/internal/oidc/oidctest"
+	"github.com/cloudingcity/todo/internal/password"
+	"github.com/cloudingcity/todo/internal/ratelimit"
+	"github.com/cloudingcity/todo/internal/repo/memory"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/apikey"
+	"github.com/cloudingcity/todo/internal/service/attachment"
+	"github.com/cloudingcity/todo/internal/service/comment"
+	"github.com/cloudingcity/todo/internal/service/project"
+	"github.com/cloudingcity/todo/internal/service/sso"
+	"github.com/cloudingcity/todo/internal/service/timeentry"
+	"github.com/cloudingcity/todo/internal/service/todo"
+	"github.com/cloudingcity/todo/internal/service/user"
+	"github.com/cloudingcity/todo/internal/service/workspace"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+// routerSuite drives the real service through NewRouter so the OpenAPI
+// middleware validates every response against the contract.
+type routerSuite struct {
+	suite.Suite
+	router *gin.Engine
+	users  service.User
+	// token belongs to the user every request is sent as by default.
+	token string
+	// mentions are the mentions comments notified about.
+	mentions []entity.Mention
+	// attachments is kept to sweep them.
+	attachments service.Attachment
+}
+
+func (s *routerSuite) SetupTest() {
+	s.setup(setupOptions{})
+}
+
+type setupOptions struct {
+	// provider is where SSO logs in, which is left out when nil.
+	provider *oidctest.Provider
+	limits   middleware.RateLimitOptions
+	quota    todo.Quota
+}
+
+// setup builds the router.
+func (s *routerSuite) setup(opts setupOptions) {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	var err error
+	// Cheap password hashing keeps the suite fast.
+	params := password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	userRepo := memory.NewUserRepo()
+	s.users, err = user.NewService(userRepo, jwt.HS256([]byte("0123456789abcdef0123456789abcdef")), user.WithPasswordParams(params))
+	s.Require().NoError(err)
+	var ssoSrv service.SSO
+	if opts.provider != nil {
+		ssoSrv = sso.NewService(oidc.NewClient(oidc.Config{
+			Issuer:       opts.provider.Issuer(),
+			ClientID:     opts.provider.ClientID,
+			ClientSecret: opts.provider.ClientSecret,
+			RedirectURL:  "https://todo.example.com/v1/sso/callback",
+			HTTPClient:   opts.provider.Client(),
+		}), s.users)
+	}
+	apiKeySrv := apikey.NewService(memory.NewAPIKeyRepo(), userRepo)
+	workspaceRepo := memory.NewWorkspaceRepo()
+	projectRepo := memory.NewProjectRepo()
+	workspaceSrv := workspace.NewService(workspaceRepo, userRepo, projectRepo)
+	historyRepo := memory.NewHistoryRepo()
+	todoSrv := todo.NewService(memory.NewTodoRepo(), todo.WithQuota(opts.quota), todo.WithHistory(historyRepo))
+	s.mentions = nil
+	commentSrv := comment.NewService(memory.NewCommentRepo(), historyRepo, todoSrv, userRepo, workspaceSrv, comment.NotifierFunc(func(mention entity.Mention) {
+		s.mentions = append(s.mentions, mention)
+	}))
+	blobs, err := blob.NewFileStore(s.T().TempDir())
+	s.Require().NoError(err)
+	s.attachments = attachment.NewService(memory.NewAttachmentRepo(), blobs, todoSrv, attachment.WithLimits(attachment.Limits{MaxSize: 1 << 10, MaxPerTodo: 3}))
+	projectSrv := project.NewService(projectRepo, todoSrv, userRepo, workspaceRepo)
+	NewRouter(s.router, health.NewRegistry(), idempotency.NewMemoryStore(time.Hour), Options{
+		RateLimits:        opts.limits,
+		Idempotency:       middleware.IdempotencyOptions{MaxUploadBytes: 4 << 10},
+		ValidateResponses: true,
+	}, s.users, ssoSrv, apiKeySrv, workspaceSrv, todoSrv, commentSrv, s.attachments, projectSrv, timeentry.NewService(memory.NewTimeEntryRepo(), todoSrv, projectSrv))
+	s.token = s.signUp("alice@example.com")
+}
+
+// signUp registers a user and returns an access token for them.
+func (s *routerSuite) signUp(email string) string {
+	_, err := s.users.Register(entity.RegisterInput{Email: email, Password: "correct horse"})
+	s.Require().NoError(err)
+	_, token, err := s.users.Login(email, "correct horse")
+	s.Require().NoError(err)
+	return token.Value
+}
+
+func (s *routerSuite) serve(token, method, path, body, contentType string) *httptest.ResponseRecorder {
+	return s.serveIn("", token, method, path, body, contentType)
+}
+
+// serveIn is serve in the workspace with the given ID, or the personal one
+// when it is empty.
+func (s *routerSuite) serveIn(workspaceID, token, method, path, body, contentType string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", lo.CoalesceOrEmpty(contentType, "application/json"))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if workspaceID != "" {
+		req.Header.Set(middleware.WorkspaceHeader, workspaceID)
+	}
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouterSuite(t *testing.T) {
+	suite.Run(t, new(routerSuite))
+}
+
+func (s *routerSuite) TestContract() {
+	steps := []struct {
+		desc        string
+		method      string
+		path        string
+		body        string
+		contentType string
+		anonymous   bool
+		wantCode    int
+	}{
+		{desc: "ping", method: http.MethodGet, path: "/v1/ping", anonymous: true, wantCode: http.StatusOK},
+		{desc: "register", method: http.MethodPost, path: "/v1/users", body: `{"email": "bob@example.com", "name": "Bob", "password": "correct horse"}`, anonymous: true, wantCode: http.StatusCreated},
+		{desc: "register taken email", method: http.MethodPost, path: "/v1/users", body: `{"email": "Bob@example.com", "password": "correct horse"}`, anonymous: true, wantCode: http.StatusConflict},
+		{desc: "register short password", method: http.MethodPost, path: "/v1/users", body: `{"email": "carol@example.com", "password": "short"}`, anonymous: true, wantCode: http.StatusBadRequest},
+		{desc: "login", method: http.MethodPost, path: "/v1/sessions", body: `{"email": "bob@example.com", "password": "correct horse"}`, anonymous: true, wantCode: http.StatusCreated},
+		{desc: "login wrong password", method: http.MethodPost, path: "/v1/sessions", body: `{"email": "bob@example.com", "password": "battery staple"}`, anonymous: true, wantCode: http.StatusUnauthorized},
+		{desc: "list without token", method: http.MethodGet, path: "/v1/todos", anonymous: true, wantCode: http.StatusUnauthorized},
+		{desc: "create invalid without token", method: http.MethodPost, path: "/v1/todos", body: `{"title": 1}`, anonymous: true, wantCode: http.StatusUnauthorized},
+		{desc: "current user", method: http.MethodGet, path: "/v1/users/me", wantCode: http.StatusOK},
+		{desc: "create", method: http.MethodPost, path: "/v1/todos", body: `{"title": "t", "description": "d"}`, wantCode: http.StatusCreated},
+		{desc: "create invalid", method: http.MethodPost, path: "/v1/todos", body: `{"title": 1}`, wantCode: http.StatusBadRequest},
+		{desc: "list", method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK},
+		{desc: "get", method: http.MethodGet, path: "/v1/todos/1", wantCode: http.StatusOK},
+		{desc: "get invalid id", method: http.MethodGet, path: "/v1/todos/abc", wantCode: http.StatusBadRequest},
+		{desc: "update", method: http.MethodPatch, path: "/v1/todos/1", body: `{"isCompleted": true}`, wantCode: http.StatusNoContent},
+		{desc: "merge patch", method: http.MethodPatch, path: "/v1/todos/1", body: `{"description": null}`, contentType: "application/merge-patch+json", wantCode: http.StatusNoContent},
+		{desc: "json patch", method: http.MethodPatch, path: "/v1/todos/1", body: `[{"op": "replace", "path": "/title", "value": "t2"}]`, contentType: "application/json-patch+json", wantCode: http.StatusNoContent},
+		{desc: "json patch test failed", method: http.MethodPatch, path: "/v1/todos/1", body: `[{"op": "test", "path": "/title", "value": "t"}]`, contentType: "application/json-patch+json", wantCode: http.StatusConflict},
+		{desc: "replace", method: http.MethodPut, path: "/v1/todos/1", body: `{"title": "t3", "description": "", "isCompleted": false}`, wantCode: http.StatusOK},
+		{desc: "replace missing", method: http.MethodPut, path: "/v1/todos/7", body: `{"title": "t", "description": "", "isCompleted": false}`, wantCode: http.StatusNotFound},
+		{desc: "replace incomplete", method: http.MethodPut, path: "/v1/todos/1", body: `{"title": "t"}`, wantCode: http.StatusBadRequest},
+		{desc: "upsert", method: http.MethodPut, path: "/v1/todos/7?upsert=true", body: `{"title": "t", "description": "", "isCompleted": false}`, wantCode: http.StatusCreated},
+		{desc: "batch create", method: http.MethodPost, path: "/v1/todos:batchCreate", body: `{"items": [{"title": "a"}, {"title": "b"}]}`, wantCode: http.StatusOK},
+		{desc: "batch update best effort", method: http.MethodPost, path: "/v1/todos:batchUpdate", body: `{"mode": "bestEffort", "items": [{"id": 8, "isCompleted": true}, {"id": 99, "isCompleted": true}]}`, wantCode: http.StatusMultiStatus},
+		{desc: "batch delete atomic not found", method: http.MethodPost, path: "/v1/todos:batchDelete", body: `{"items": [{"id": 8}, {"id": 99}]}`, wantCode: http.StatusNotFound},
+		{desc: "batch delete invalid mode", method: http.MethodPost, path: "/v1/todos:batchDelete", body: `{"mode": "x", "items": [{"id": 8}]}`, wantCode: http.StatusBadRequest},
+		{desc: "create with dates", method: http.MethodPost, path: "/v1/todos", body: `{"title": "t", "start": {"date": "2000-01-01"}, "due": {"dateTime": "2000-01-02T09:00:00", "timeZone": "Asia/Taipei"}}`, wantCode: http.StatusCreated},
+		{desc: "create start after due", method: http.MethodPost, path: "/v1/todos", body: `{"title": "t", "start": {"date": "2000-01-03"}, "due": {"date": "2000-01-02"}}`, wantCode: http.StatusBadRequest},
+		{desc: "list overdue", method: http.MethodGet, path: "/v1/todos?view=overdue&tz=Asia/Taipei", wantCode: http.StatusOK},
+		{desc: "list invalid view", method: http.MethodGet, path: "/v1/todos?view=someday", wantCode: http.StatusBadRequest},
+		{desc: "merge patch clears due", method: http.MethodPatch, path: "/v1/todos/10", body: `{"due": null}`, contentType: "application/merge-patch+json", wantCode: http.StatusNoContent},
+		{desc: "merge patch start after due", method: http.MethodPatch, path: "/v1/todos/10", body: `{"due": {"date": "1999-12-31"}}`, contentType: "application/merge-patch+json", wantCode: http.StatusUnprocessableEntity},
+		{desc: "create with priority", method: http.MethodPost, path: "/v1/todos", body: `{"title": "t", "priority": "high"}`, wantCode: http.StatusCreated},
+		{desc: "create unknown priority", method: http.MethodPost, path: "/v1/todos", body: `{"title": "t", "priority": "asap"}`, wantCode: http.StatusBadRequest},
+		{desc: "merge patch priority", method: http.MethodPatch, path: "/v1/todos/11", body: `{"priority": "urgent"}`, contentType: "application/merge-patch+json", wantCode: http.StatusNoContent},
+		{desc: "move", method: http.MethodPost, path: "/v1/todos/11/move", body: `{"before": 1}`, wantCode: http.StatusOK},
+		{desc: "move missing target", method: http.MethodPost, path: "/v1/todos/11/move", body: `{"before": 99}`, wantCode: http.StatusBadRequest},
+		{desc: "list by priority", method: http.MethodGet, path: "/v1/todos?order=priority", wantCode: http.StatusOK},
+		{desc: "list manual order", method: http.MethodGet, path: "/v1/todos?order=manual", wantCode: http.StatusOK},
+		{desc: "create tag", method: http.MethodPost, path: "/v1/tags", body: `{"name": "Work"}`, wantCode: http.StatusCreated},
+		{desc: "create existing tag", method: http.MethodPost, path: "/v1/tags", body: `{"name": "work"}`, wantCode: http.StatusConflict},
+		{desc: "attach tags", method: http.MethodPost, path: "/v1/todos/11/tags", body: `{"tags": ["work", "home"]}`, wantCode: http.StatusOK},
+		{desc: "attach invalid tag", method: http.MethodPost, path: "/v1/todos/11/tags", body: `{"tags": ["a b"]}`, wantCode: http.StatusBadRequest},
+		{desc: "list tags", method: http.MethodGet, path: "/v1/tags", wantCode: http.StatusOK},
+		{desc: "list by tags", method: http.MethodGet, path: "/v1/todos?tags=work+and+not+urgent", wantCode: http.StatusOK},
+		{desc: "list invalid tag expression", method: http.MethodGet, path: "/v1/todos?tags=work+and", wantCode: http.StatusBadRequest},
+		{desc: "rename tag", method: http.MethodPatch, path: "/v1/tags/2", body: `{"name": "house"}`, wantCode: http.StatusOK},
+		{desc: "merge tag", method: http.MethodPost, path: "/v1/tags/2/merge", body: `{"into": 1}`, wantCode: http.StatusOK},
+		{desc: "get merged tag", method: http.MethodGet, path: "/v1/tags/2", wantCode: http.StatusNotFound},
+		{desc: "detach tag", method: http.MethodDelete, path: "/v1/todos/11/tags/work", wantCode: http.StatusNoContent},
+		{desc: "delete tag", method: http.MethodDelete, path: "/v1/tags/1", wantCode: http.StatusNoContent},
+		{desc: "create project", method: http.MethodPost, path: "/v1/projects", body: `{"name": "home"}`, wantCode: http.StatusCreated},
+		{desc: "create project without name", method: http.MethodPost, path: "/v1/projects", body: `{}`, wantCode: http.StatusBadRequest},
+		{desc: "create project todo", method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "t"}`, wantCode: http.StatusCreated},
+		{desc: "move todo to project", method: http.MethodPut, path: "/v1/todos/11/project", body: `{"projectId": 1}`, wantCode: http.StatusOK},
+		{desc: "move todo to missing project", method: http.MethodPut, path: "/v1/todos/11/project", body: `{"projectId": 9}`, wantCode: http.StatusNotFound},
+		{desc: "list project todos", method: http.MethodGet, path: "/v1/projects/1/todos?order=manual", wantCode: http.StatusOK},
+		{desc: "list inbox", method: http.MethodGet, path: "/v1/todos?project=0", wantCode: http.StatusOK},
+		{desc: "update project", method: http.MethodPatch, path: "/v1/projects/1", body: `{"description": "chores"}`, wantCode: http.StatusOK},
+		{desc: "archive project", method: http.MethodPost, path: "/v1/projects/1/archive", wantCode: http.StatusOK},
+		{desc: "list projects", method: http.MethodGet, path: "/v1/projects?archived=true", wantCode: http.StatusOK},
+		{desc: "create todo in archived project", method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "t"}`, wantCode: http.StatusConflict},
+		{desc: "unarchive project", method: http.MethodPost, path: "/v1/projects/1/unarchive", wantCode: http.StatusOK},
+		{desc: "delete project with todos", method: http.MethodDelete, path: "/v1/projects/1", wantCode: http.StatusConflict},
+		{desc: "delete project into inbox", method: http.MethodDelete, path: "/v1/projects/1?todos=inbox", wantCode: http.StatusNoContent},
+		{desc: "get deleted project", method: http.MethodGet, path: "/v1/projects/1", wantCode: http.StatusNotFound},
+		{desc: "create subtask", method: http.MethodPost, path: "/v1/todos", body: `{"title": "s", "parentId": 11, "rollup": "auto"}`, wantCode: http.StatusCreated},
+		{desc: "create subtask of missing parent", method: http.MethodPost, path: "/v1/todos", body: `{"title": "s", "parentId": 99}`, wantCode: http.StatusBadRequest},
+		{desc: "set parent", method: http.MethodPut, path: "/v1/todos/12/parent", body: `{"parentId": 13}`, wantCode: http.StatusOK},
+		{desc: "set parent cycle", method: http.MethodPut, path: "/v1/todos/11/parent", body: `{"parentId": 12}`, wantCode: http.StatusBadRequest},
+		{desc: "get subtree", method: http.MethodGet, path: "/v1/todos/11/subtree", wantCode: http.StatusOK},
+		{desc: "delete with subtasks blocked", method: http.MethodDelete, path: "/v1/todos/11?subtasks=block", wantCode: http.StatusConflict},
+		{desc: "delete promoting subtasks", method: http.MethodDelete, path: "/v1/todos/13?subtasks=promote", wantCode: http.StatusNoContent},
+		{desc: "create dependent project", method: http.MethodPost, path: "/v1/projects", body: `{"name": "release"}`, wantCode: http.StatusCreated},
+		{desc: "create blocker", method: http.MethodPost, path: "/v1/projects/2/todos", body: `{"title": "build"}`, wantCode: http.StatusCreated},
+		{desc: "create blocked", method: http.MethodPost, path: "/v1/projects/2/todos", body: `{"title": "ship"}`, wantCode: http.StatusCreated},
+		{desc: "add dependency", method: http.MethodPost, path: "/v1/todos/15/dependencies", body: `{"blockerId": 14}`, wantCode: http.StatusCreated},
+		{desc: "add dependency cycle", method: http.MethodPost, path: "/v1/todos/14/dependencies", body: `{"blockerId": 15}`, wantCode: http.StatusBadRequest},
+		{desc: "add dependency twice", method: http.MethodPost, path: "/v1/todos/15/dependencies", body: `{"blockerId": 14}`, wantCode: http.StatusConflict},
+		{desc: "list dependencies", method: http.MethodGet, path: "/v1/todos/15/dependencies", wantCode: http.StatusOK},
+		{desc: "list topological", method: http.MethodGet, path: "/v1/todos?order=topological", wantCode: http.StatusOK},
+		{desc: "critical path", method: http.MethodGet, path: "/v1/projects/2/critical-path", wantCode: http.StatusOK},
+		{desc: "complete blocked", method: http.MethodPatch, path: "/v1/todos/15", body: `{"isCompleted": true}`, wantCode: http.StatusConflict},
+		{desc: "complete blocked with force", method: http.MethodPatch, path: "/v1/todos/15?force=true", body: `{"isCompleted": true}`, contentType: "application/merge-patch+json", wantCode: http.StatusNoContent},
+		{desc: "remove dependency", method: http.MethodDelete, path: "/v1/todos/15/dependencies/14", wantCode: http.StatusNoContent},
+		{desc: "remove missing dependency", method: http.MethodDelete, path: "/v1/todos/15/dependencies/14", wantCode: http.StatusNotFound},
+		{desc: "create recurring", method: http.MethodPost, path: "/v1/todos", body: `{"title": "standup", "due": {"date": "2026-10-19"}, "recurrence": "FREQ=WEEKLY;BYDAY=MO,WE"}`, wantCode: http.StatusCreated},
+		{desc: "create recurring without date", method: http.MethodPost, path: "/v1/todos", body: `{"title": "standup", "recurrence": "FREQ=DAILY"}`, wantCode: http.StatusBadRequest},
+		{desc: "create with invalid rule", method: http.MethodPost, path: "/v1/todos", body: `{"title": "standup", "recurrence": "FREQ=SOMETIMES"}`, wantCode: http.StatusBadRequest},
+		{desc: "preview occurrences", method: http.MethodGet, path: "/v1/todos/16/occurrences?count=3", wantCode: http.StatusOK},
+		{desc: "complete recurring", method: http.MethodPatch, path: "/v1/todos/16", body: `{"isCompleted": true}`, wantCode: http.StatusNoContent},
+		{desc: "get next occurrence", method: http.MethodGet, path: "/v1/todos/17", wantCode: http.StatusOK},
+		{desc: "stop recurring", method: http.MethodPatch, path: "/v1/todos/17", body: `{"recurrence": ""}`, wantCode: http.StatusNoContent},
+		{desc: "delete", method: http.MethodDelete, path: "/v1/todos/1", wantCode: http.StatusNoContent},
+		{desc: "get deleted", method: http.MethodGet, path: "/v1/todos/1", wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			token := lo.Ternary(step.anonymous, "", s.token)
+			w := s.serve(token, step.method, step.path, step.body, step.contentType)
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func (s *routerSuite) TestIdempotentCreate() {
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/todos", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		req.Header.Set("Idempotency-Key", "retry-1")
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := create(`{"title": "t", "description": "d"}`)
+	retry := create(`{"title": "t", "description": "d"}`)
+	reused := create(`{"title": "other", "description": "d"}`)
+
+	s.Equal(http.StatusCreated, first.Code)
+	s.Equal(http.StatusCreated, retry.Code)
+	s.JSONEq(first.Body.String(), retry.Body.String())
+	s.Equal(http.StatusUnprocessableEntity, reused.Code, reused.Body.String())
+
+	w := s.serve(s.token, http.MethodGet, "/v1/todos", "", "")
+	s.JSONEq(`[`+first.Body.String()+`]`, w.Body.String())
+}
+
+// TestIsolation checks that a user cannot reach another user's todos and
+// projects.
+func (s *routerSuite) TestIsolation() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	s.Equal(http.StatusCreated, s.serve(alice, http.MethodPost, "/v1/todos", `{"title": "mine"}`, "").Code)
+	s.Equal(http.StatusCreated, s.serve(alice, http.MethodPost, "/v1/projects", `{"name": "mine"}`, "").Code)
+
+	steps := []struct {
+		desc     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{desc: "list todos", method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK, wantBody: `[]`},
+		{desc: "get todo", method: http.MethodGet, path: "/v1/todos/1", wantCode: http.StatusNotFound},
+		{desc: "update todo", method: http.MethodPatch, path: "/v1/todos/1", body: `{"title": "theirs"}`, wantCode: http.StatusNotFound},
+		{desc: "replace todo", method: http.MethodPut, path: "/v1/todos/1", body: `{"title": "t", "description": "", "isCompleted": false}`, wantCode: http.StatusNotFound},
+		{desc: "delete todo", method: http.MethodDelete, path: "/v1/todos/1", wantCode: http.StatusNotFound},
+		{desc: "create subtask", method: http.MethodPost, path: "/v1/todos", body: `{"title": "s", "parentId": 1}`, wantCode: http.StatusBadRequest},
+		{desc: "list projects", method: http.MethodGet, path: "/v1/projects", wantCode: http.StatusOK, wantBody: `[]`},
+		{desc: "get project", method: http.MethodGet, path: "/v1/projects/1", wantCode: http.StatusNotFound},
+		{desc: "create project todo", method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "t"}`, wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serve(bob, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.JSONEq(step.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	s.Run("todo is untouched", func() {
+		w := s.serve(alice, http.MethodGet, "/v1/todos/1", "", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.Contains(w.Body.String(), `"title":"mine"`)
+	})
+}
+
+// TestWorkspace checks that todos stay in the workspace they were created in
+// and that only members can enter a workspace.
+func (s *routerSuite) TestWorkspace() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+
+	steps := []struct {
+		desc      string
+		workspace string
+		token     string
+		method    string
+		path      string
+		body      string
+		wantCode  int
+	}{
+		{desc: "create in personal", token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "personal"}`, wantCode: http.StatusCreated},
+		{desc: "create in team", workspace: teamID, token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "team"}`, wantCode: http.StatusCreated},
+		{desc: "team numbers its own todos", workspace: teamID, token: alice, method: http.MethodGet, path: "/v1/todos/1", wantCode: http.StatusOK},
+		{desc: "list personal", token: alice, method: http.MethodGet, path: "/v1/todos?order=manual", wantCode: http.StatusOK},
+		{desc: "create tag in team", workspace: teamID, token: alice, method: http.MethodPost, path: "/v1/tags", body: `{"name": "team"}`, wantCode: http.StatusCreated},
+		{desc: "team tag is not personal", token: alice, method: http.MethodGet, path: "/v1/tags/1", wantCode: http.StatusNotFound},
+		{desc: "invalid workspace id", workspace: "abc", token: alice, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusBadRequest},
+		{desc: "missing workspace", workspace: "99", token: alice, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusNotFound},
+		{desc: "non-member", workspace: teamID, token: bob, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusNotFound},
+		{desc: "non-member members", token: bob, method: http.MethodGet, path: "/v1/workspaces/" + teamID + "/members", wantCode: http.StatusNotFound},
+		{desc: "bob has his own todo 1", token: bob, method: http.MethodPut, path: "/v1/todos/1?upsert=true", body: `{"title": "bob", "description": "", "isCompleted": false}`, wantCode: http.StatusCreated},
+		{desc: "add unknown member", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "carol@example.com"}`, wantCode: http.StatusNotFound},
+		{desc: "add member", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "Bob@example.com"}`, wantCode: http.StatusCreated},
+		{desc: "add member twice", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "bob@example.com"}`, wantCode: http.StatusConflict},
+		{desc: "member creates in team", workspace: teamID, token: bob, method: http.MethodPost, path: "/v1/todos", body: `{"title": "bob team"}`, wantCode: http.StatusCreated},
+		{desc: "member lists members", token: bob, method: http.MethodGet, path: "/v1/workspaces/" + teamID + "/members", wantCode: http.StatusOK},
+		{desc: "list workspaces", token: bob, method: http.MethodGet, path: "/v1/workspaces", wantCode: http.StatusOK},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(step.workspace, step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+		})
+	}
+
+	s.Run("each workspace keeps its todos", func() {
+		titles := func(workspace, token string) []string {
+			w := s.serveIn(workspace, token, http.MethodGet, "/v1/todos", "", "")
+			s.Require().Equal(http.StatusOK, w.Code)
+			var todos []struct {
+				ID    int    `json:"id"`
+				Title string `json:"title"`
+			}
+			s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &todos))
+			return lo.Map(todos, func(todo struct {
+				ID    int    `json:"id"`
+				Title string `json:"title"`
+			}, _ int) string {
+				return strconv.Itoa(todo.ID) + " " + todo.Title
+			})
+		}
+
+		s.Equal([]string{"1 personal"}, titles("", alice))
+		s.Equal([]string{"1 team", "2 bob team"}, titles(teamID, alice))
+		s.Equal([]string{"1 bob"}, titles("", bob))
+		s.Equal([]string{"1 team", "2 bob team"}, titles(teamID, bob))
+	})
+}
+
+// TestRoles checks what viewers and guests of a shared project may do, and
+// that what they may not see looks missing while what they see but may not
+// change is forbidden.
+func (s *routerSuite) TestRoles() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	carol := s.signUp("carol@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+	members := "/v1/workspaces/" + teamID + "/members"
+
+	steps := []struct {
+		desc     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{desc: "add viewer", token: alice, method: http.MethodPost, path: members, body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "create project", token: alice, method: http.MethodPost, path: "/v1/projects", body: `{"name": "plan"}`, wantCode: http.StatusCreated},
+		{desc: "create hidden project", token: alice, method: http.MethodPost, path: "/v1/projects", body: `{"name": "secret"}`, wantCode: http.StatusCreated},
+		{desc: "create project todo", token: alice, method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "plan"}`, wantCode: http.StatusCreated},
+		{desc: "create inbox todo", token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "inbox"}`, wantCode: http.StatusCreated},
+
+		{desc: "viewer lists todos", token: bob, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK},
+		{desc: "viewer cannot update", token: bob, method: http.MethodPatch, path: "/v1/todos/1", body: `{"title": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "viewer cannot create", token: bob, method: http.MethodPost, path: "/v1/todos", body: `{"title": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "viewer cannot create projects", token: bob, method: http.MethodPost, path: "/v1/projects", body: `{"name": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "viewer cannot delete projects", token: bob, method: http.MethodDelete, path: "/v1/projects/1", wantCode: http.StatusForbidden},
+		{desc: "viewer cannot share", token: bob, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "carol@example.com", "role": "viewer"}`, wantCode: http.StatusForbidden},
+		{desc: "viewer cannot create tags", token: bob, method: http.MethodPost, path: "/v1/tags", body: `{"name": "work"}`, wantCode: http.StatusForbidden},
+		{desc: "viewer lists members", token: bob, method: http.MethodGet, path: members, wantCode: http.StatusOK},
+		{desc: "viewer cannot add members", token: bob, method: http.MethodPost, path: members, body: `{"email": "carol@example.com"}`, wantCode: http.StatusForbidden},
+
+		{desc: "share unknown user", token: alice, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "dave@example.com", "role": "editor"}`, wantCode: http.StatusNotFound},
+		{desc: "share", token: alice, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "carol@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "change share role", token: alice, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "carol@example.com", "role": "editor"}`, wantCode: http.StatusOK},
+		{desc: "guest lists shared todos", token: carol, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK, wantBody: `"title":"plan"`},
+		{desc: "guest lists shared projects", token: carol, method: http.MethodGet, path: "/v1/projects", wantCode: http.StatusOK, wantBody: `"name":"plan"`},
+		{desc: "guest cannot see inbox todo", token: carol, method: http.MethodGet, path: "/v1/todos/2", wantCode: http.StatusNotFound},
+		{desc: "guest cannot change inbox todo", token: carol, method: http.MethodDelete, path: "/v1/todos/2", wantCode: http.StatusNotFound},
+		{desc: "guest cannot see hidden project", token: carol, method: http.MethodGet, path: "/v1/projects/2", wantCode: http.StatusNotFound},
+		{desc: "guest cannot create in the inbox", token: carol, method: http.MethodPost, path: "/v1/todos", body: `{"title": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "guest cannot move into hidden project", token: carol, method: http.MethodPut, path: "/v1/todos/1/project", body: `{"projectId": 2}`, wantCode: http.StatusNotFound},
+		{desc: "guest updates shared todo", token: carol, method: http.MethodPatch, path: "/v1/todos/1", body: `{"title": "plan it"}`, wantCode: http.StatusNoContent},
+		{desc: "guest cannot delete todos of others", token: carol, method: http.MethodDelete, path: "/v1/todos/1", wantCode: http.StatusForbidden},
+		{desc: "guest creates in shared project", token: carol, method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "mine"}`, wantCode: http.StatusCreated},
+		{desc: "guest deletes own todo", token: carol, method: http.MethodDelete, path: "/v1/todos/3", wantCode: http.StatusNoContent},
+		{desc: "guest lists shares", token: carol, method: http.MethodGet, path: "/v1/projects/1/shares", wantCode: http.StatusOK, wantBody: `"role":"editor"`},
+		{desc: "guest cannot list members", token: carol, method: http.MethodGet, path: members, wantCode: http.StatusForbidden},
+		{desc: "guest cannot share", token: carol, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "bob@example.com", "role": "owner"}`, wantCode: http.StatusForbidden},
+
+		{desc: "unshare", token: alice, method: http.MethodDelete, path: "/v1/projects/1/shares/3", wantCode: http.StatusNoContent},
+		{desc: "unshare twice", token: alice, method: http.MethodDelete, path: "/v1/projects/1/shares/3", wantCode: http.StatusNotFound},
+		{desc: "unshared project is gone", token: carol, method: http.MethodGet, path: "/v1/projects/1", wantCode: http.StatusNotFound},
+		{desc: "unshared todo is gone", token: carol, method: http.MethodPatch, path: "/v1/todos/1", body: `{"title": "mine"}`, wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(teamID, step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.Contains(w.Body.String(), step.wantBody)
+			}
+		})
+	}
+
+	s.Run("personal projects cannot be shared", func() {
+		s.Require().Equal(http.StatusCreated, s.serve(alice, http.MethodPost, "/v1/projects", `{"name": "home"}`, "").Code)
+
+		w := s.serve(alice, http.MethodPost, "/v1/projects/1/shares", `{"email": "bob@example.com", "role": "viewer"}`, "")
+		s.Equal(http.StatusConflict, w.Code, w.Body.String())
+	})
+}
+
+func (s *routerSuite) TestComments() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	carol := s.signUp("carol@example.com")
+	s.signUp("dave@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+	members := "/v1/workspaces/" + teamID + "/members"
+
+	steps := []struct {
+		desc     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{desc: "add viewer", token: alice, method: http.MethodPost, path: members, body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "add editor", token: alice, method: http.MethodPost, path: members, body: `{"email": "carol@example.com", "role": "editor"}`, wantCode: http.StatusCreated},
+		{desc: "create todo", token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "plan"}`, wantCode: http.StatusCreated},
+		{desc: "comment", token: alice, method: http.MethodPost, path: "/v1/todos/1/comments", body: `{"body": "ping @Bob@example.com and @dave@example.com"}`, wantCode: http.StatusCreated, wantBody: `"mentions":[2]`},
+		{desc: "viewer comments", token: bob, method: http.MethodPost, path: "/v1/todos/1/comments", body: `{"body": "on it"}`, wantCode: http.StatusCreated},
+		{desc: "empty comment", token: alice, method: http.MethodPost, path: "/v1/todos/1/comments", body: `{"body": "  "}`, wantCode: http.StatusBadRequest},
+		{desc: "comment on missing todo", token: alice, method: http.MethodPost, path: "/v1/todos/9/comments", body: `{"body": "hi"}`, wantCode: http.StatusNotFound},
+		{desc: "rename todo", token: carol, method: http.MethodPatch, path: "/v1/todos/1", body: `{"title": "plan it"}`, wantCode: http.StatusNoContent},
+		{desc: "edit", token: alice, method: http.MethodPatch, path: "/v1/todos/1/comments/1", body: `{"body": "ping @bob@example.com and @carol@example.com"}`, wantCode: http.StatusOK, wantBody: `"edited":true`},
+		{desc: "edit by someone else", token: carol, method: http.MethodPatch, path: "/v1/todos/1/comments/1", body: `{"body": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "get with edits", token: bob, method: http.MethodGet, path: "/v1/todos/1/comments/1", wantCode: http.StatusOK, wantBody: `"edits":[{"body":"ping @Bob@example.com and @dave@example.com"`},
+		{desc: "get on another todo", token: alice, method: http.MethodGet, path: "/v1/todos/2/comments/1", wantCode: http.StatusNotFound},
+		{desc: "list first page", token: bob, method: http.MethodGet, path: "/v1/todos/1/comments?limit=1", wantCode: http.StatusOK, wantBody: `"nextCursor":"`},
+		{desc: "list invalid cursor", token: bob, method: http.MethodGet, path: "/v1/todos/1/comments?cursor=!", wantCode: http.StatusBadRequest},
+		{desc: "list invalid limit", token: bob, method: http.MethodGet, path: "/v1/todos/1/comments?limit=101", wantCode: http.StatusBadRequest},
+		{desc: "activity", token: bob, method: http.MethodGet, path: "/v1/todos/1/activity", wantCode: http.StatusOK, wantBody: `"change":{"actorId":3,"fields":[{"field":"title","from":"plan","to":"plan it"}]}`},
+		{desc: "editor cannot delete comments of others", token: carol, method: http.MethodDelete, path: "/v1/todos/1/comments/2", wantCode: http.StatusForbidden},
+		{desc: "author deletes", token: bob, method: http.MethodDelete, path: "/v1/todos/1/comments/2", wantCode: http.StatusNoContent},
+		{desc: "delete twice", token: bob, method: http.MethodDelete, path: "/v1/todos/1/comments/2", wantCode: http.StatusNotFound},
+		{desc: "owner deletes", token: alice, method: http.MethodDelete, path: "/v1/todos/1/comments/1", wantCode: http.StatusNoContent},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(teamID, step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.Contains(w.Body.String(), step.wantBody)
+			}
+		})
+	}
+
+	s.Run("mentioned members are notified once", func() {
+		s.Equal([]int{2, 3}, lo.Map(s.mentions, func(m entity.Mention, _ int) int { return m.UserID }))
+	})
+}
+
+// upload encodes content as the file of a multipart form, returning the
+// body and its content type.
+func upload(name, content string) (string, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	w, _ := mw.CreateFormFile("file", name)
+	_, _ = w.Write([]byte(content))
+	_ = mw.Close()
+	return body.String(), mw.FormDataContentType()
+}
+
+func (s *routerSuite) TestAttachments() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+
+	png, pngType := upload("shot.png", "\x89PNG\r\n\x1a\n0123456789")
+	text, textType := upload("notes.txt", "0123456789")
+	large, largeType := upload("large.txt", strings.Repeat("x", 1<<10+1))
+	steps := []struct {
+		desc        string
+		token       string
+		method      string
+		path        string
+		body        string
+		contentType string
+		wantCode    int
+		wantBody    string
+	}{
+		{desc: "add viewer", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "create todo", token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "plan"}`, wantCode: http.StatusCreated},
+		{desc: "upload", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: png, contentType: pngType, wantCode: http.StatusCreated, wantBody: `"contentType":"image/png"`},
+		{desc: "upload as viewer", token: bob, method: http.MethodPost, path: "/v1/todos/1/attachments", body: text, contentType: textType, wantCode: http.StatusForbidden},
+		{desc: "upload too large", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: large, contentType: largeType, wantCode: http.StatusRequestEntityTooLarge},
+		{desc: "upload without file", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: `{}`, wantCode: http.StatusBadRequest},
+		{desc: "upload to missing todo", token: alice, method: http.MethodPost, path: "/v1/todos/9/attachments", body: text, contentType: textType, wantCode: http.StatusNotFound},
+		{desc: "upload text", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: text, contentType: textType, wantCode: http.StatusCreated, wantBody: `"contentType":"text/plain; charset=utf-8"`},
+		{desc: "upload same content", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: text, contentType: textType, wantCode: http.StatusCreated, wantBody: `"sha256":"84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882"`},
+		{desc: "upload too many", token: alice, method: http.MethodPost, path: "/v1/todos/1/attachments", body: text, contentType: textType, wantCode: http.StatusForbidden},
+		{desc: "list", token: bob, method: http.MethodGet, path: "/v1/todos/1/attachments", wantCode: http.StatusOK, wantBody: `"name":"shot.png"`},
+		{desc: "get", token: bob, method: http.MethodGet, path: "/v1/todos/1/attachments/2", wantCode: http.StatusOK, wantBody: `"size":10`},
+		{desc: "get on another todo", token: bob, method: http.MethodGet, path: "/v1/todos/2/attachments/2", wantCode: http.StatusNotFound},
+		{desc: "download", token: bob, method: http.MethodGet, path: "/v1/todos/1/attachments/2/content", wantCode: http.StatusOK, wantBody: "0123456789"},
+		{desc: "viewer cannot delete", token: bob, method: http.MethodDelete, path: "/v1/todos/1/attachments/2", wantCode: http.StatusForbidden},
+		{desc: "delete", token: alice, method: http.MethodDelete, path: "/v1/todos/1/attachments/3", wantCode: http.StatusNoContent},
+		{desc: "content kept for the other attachment", token: bob, method: http.MethodGet, path: "/v1/todos/1/attachments/2/content", wantCode: http.StatusOK, wantBody: "0123456789"},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(teamID, step.token, step.method, step.path, step.body, step.contentType)
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.Contains(w.Body.String(), step.wantBody)
+			}
+		})
+	}
+
+	download := func(header map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/todos/1/attachments/2/content", nil)
+		req.Header.Set("Authorization", "Bearer "+bob)
+		req.Header.Set(middleware.WorkspaceHeader, teamID)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+	s.Run("upload too large to spool", func() {
+		body, contentType := upload("huge.txt", strings.Repeat("x", 8<<10))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/todos/1/attachments", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+alice)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(middleware.WorkspaceHeader, teamID)
+		req.Header.Set(middleware.IdempotencyKeyHeader, "huge")
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	})
+	s.Run("download range", func() {
+		w := download(map[string]string{"Range": "bytes=-3"})
+
+		s.Equal(http.StatusPartialContent, w.Code, w.Body.String())
+		s.Equal("789", w.Body.String())
+		s.Equal("bytes 7-9/10", w.Header().Get("Content-Range"))
+	})
+	s.Run("download range not satisfiable", func() {
+		w := download(map[string]string{"Range": "bytes=10-"})
+
+		s.Equal(http.StatusRequestedRangeNotSatisfiable, w.Code, w.Body.String())
+	})
+	s.Run("download not modified", func() {
+		etag := download(nil).Header().Get("ETag")
+		w := download(map[string]string{"If-None-Match": etag})
+
+		s.Equal(http.StatusNotModified, w.Code, w.Body.String())
+	})
+	s.Run("attachments of deleted todos are swept", func() {
+		w := s.serveIn(teamID, alice, http.MethodDelete, "/v1/todos/1", "", "")
+		s.Require().Equal(http.StatusNoContent, w.Code, w.Body.String())
+
+		_, err := s.attachments.Sweep()
+		s.Require().NoError(err)
+		_, err = s.attachments.ForWorkspace(team.ID).Get(1, 2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *routerSuite) TestChecklist() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+
+	steps := []struct {
+		desc     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{desc: "add viewer", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "create todo", token: alice, method: http.MethodPost, path: "/v1/todos", body: `{"title": "pack"}`, wantCode: http.StatusCreated},
+		{desc: "empty checklist", token: bob, method: http.MethodGet, path: "/v1/todos/1/checklist", wantCode: http.StatusOK, wantBody: `"items":[]`},
+		{desc: "add item", token: alice, method: http.MethodPost, path: "/v1/todos/1/checklist/items", body: `{"text": "passport"}`, wantCode: http.StatusCreated, wantBody: `"total":1`},
+		{desc: "add another item", token: alice, method: http.MethodPost, path: "/v1/todos/1/checklist/items", body: `{"text": "tickets"}`, wantCode: http.StatusCreated, wantBody: `"total":2`},
+		{desc: "add empty item", token: alice, method: http.MethodPost, path: "/v1/todos/1/checklist/items", body: `{"text": "  "}`, wantCode: http.StatusBadRequest},
+		{desc: "viewer cannot add", token: bob, method: http.MethodPost, path: "/v1/todos/1/checklist/items", body: `{"text": "socks"}`, wantCode: http.StatusForbidden},
+		{desc: "auto-complete", token: alice, method: http.MethodPatch, path: "/v1/todos/1/checklist", body: `{"autoComplete": true}`, wantCode: http.StatusOK, wantBody: `"autoComplete":true`},
+		{desc: "check", token: alice, method: http.MethodPatch, path: "/v1/todos/1/checklist/items/1", body: `{"checked": true}`, wantCode: http.StatusOK, wantBody: `"isCompleted":false`},
+		{desc: "check the last item", token: alice, method: http.MethodPatch, path: "/v1/todos/1/checklist/items/2", body: `{"checked": true}`, wantCode: http.StatusOK, wantBody: `"isCompleted":true`},
+		{desc: "check missing item", token: alice, method: http.MethodPatch, path: "/v1/todos/1/checklist/items/9", body: `{"checked": true}`, wantCode: http.StatusNotFound},
+		{desc: "progress in list", token: bob, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK, wantBody: `"checklist":"2/2"`},
+		{desc: "items in get", token: bob, method: http.MethodGet, path: "/v1/todos/1", wantCode: http.StatusOK, wantBody: `"checklistItems":[{"id":1,"text":"passport","checked":true}`},
+		{desc: "reorder", token: alice, method: http.MethodPut, path: "/v1/todos/1/checklist/order", body: `{"itemIds": [2, 1]}`, wantCode: http.StatusOK, wantBody: `"items":[{"id":2,"text":"tickets","checked":true}`},
+		{desc: "reorder without every item", token: alice, method: http.MethodPut, path: "/v1/todos/1/checklist/order", body: `{"itemIds": [2]}`, wantCode: http.StatusBadRequest},
+		{desc: "uncheck reopens", token: alice, method: http.MethodPatch, path: "/v1/todos/1/checklist/items/2", body: `{"checked": false}`, wantCode: http.StatusOK, wantBody: `"isCompleted":false`},
+		{desc: "viewer cannot remove", token: bob, method: http.MethodDelete, path: "/v1/todos/1/checklist/items/2", wantCode: http.StatusForbidden},
+		{desc: "remove", token: alice, method: http.MethodDelete, path: "/v1/todos/1/checklist/items/2", wantCode: http.StatusNoContent},
+		{desc: "removing the unchecked item completes", token: bob, method: http.MethodGet, path: "/v1/todos/1/checklist", wantCode: http.StatusOK, wantBody: `"isCompleted":true`},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(teamID, step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.Contains(w.Body.String(), step.wantBody)
+			}
+		})
+	}
+}
+
+func (s *routerSuite) TestTimeTracking() {
+	alice := s.token
+	bob := s.signUp("bob@example.com")
+	w := s.serve(alice, http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var team struct {
+		ID int `json:"id"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &team))
+	teamID := strconv.Itoa(team.ID)
+
+	steps := []struct {
+		desc     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{desc: "add viewer", token: alice, method: http.MethodPost, path: "/v1/workspaces/" + teamID + "/members", body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusCreated},
+		{desc: "create project", token: alice, method: http.MethodPost, path: "/v1/projects", body: `{"name": "client"}`, wantCode: http.StatusCreated},
+		{desc: "create todo with estimate", token: alice, method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "design", "estimateSeconds": 7200}`, wantCode: http.StatusCreated, wantBody: `"estimateSeconds":7200`},
+		{desc: "create subtask with estimate", token: alice, method: http.MethodPost, path: "/v1/projects/1/todos", body: `{"title": "mockups", "parentId": 1, "estimateSeconds": 3600}`, wantCode: http.StatusCreated},
+		{desc: "negative estimate", token: alice, method: http.MethodPatch, path: "/v1/todos/1", body: `{"estimateSeconds": -1}`, wantCode: http.StatusBadRequest},
+		{desc: "no timer running", token: alice, method: http.MethodGet, path: "/v1/timer", wantCode: http.StatusNotFound},
+		{desc: "start timer", token: alice, method: http.MethodPost, path: "/v1/todos/1/timer", body: `{"note": "kickoff"}`, wantCode: http.StatusCreated, wantBody: `"running":true`},
+		{desc: "running timer", token: alice, method: http.MethodGet, path: "/v1/timer", wantCode: http.StatusOK, wantBody: `"todoId":1`},
+		{desc: "start another timer", token: alice, method: http.MethodPost, path: "/v1/todos/2/timer", wantCode: http.StatusCreated, wantBody: `"stopped":{"id":1`},
+		{desc: "stop timer", token: alice, method: http.MethodPost, path: "/v1/timer/stop", wantCode: http.StatusOK, wantBody: `"running":false`},
+		{desc: "stop without timer", token: alice, method: http.MethodPost, path: "/v1/timer/stop", wantCode: http.StatusNotFound},
+		{desc: "viewer cannot start", token: bob, method: http.MethodPost, path: "/v1/todos/1/timer", wantCode: http.StatusForbidden},
+		{desc: "manual entry", token: alice, method: http.MethodPost, path: "/v1/todos/1/time-entries", body: `{"start": "2025-01-06T09:00:00Z", "end": "2025-01-06T10:30:00Z", "note": "design"}`, wantCode: http.StatusCreated, wantBody: `"seconds":5400`},
+		{desc: "entry ending before it starts", token: alice, method: http.MethodPost, path: "/v1/todos/1/time-entries", body: `{"start": "2025-01-06T10:00:00Z", "end": "2025-01-06T09:00:00Z"}`, wantCode: http.StatusBadRequest},
+		{desc: "entry in the future", token: alice, method: http.MethodPost, path: "/v1/todos/1/time-entries", body: `{"start": "2999-01-01T09:00:00Z", "end": "2999-01-01T10:00:00Z"}`, wantCode: http.StatusBadRequest},
+		{desc: "edit entry", token: alice, method: http.MethodPatch, path: "/v1/todos/1/time-entries/3", body: `{"end": "2025-01-06T11:00:00Z"}`, wantCode: http.StatusOK, wantBody: `"seconds":7200`},
+		{desc: "viewer cannot edit", token: bob, method: http.MethodPatch, path: "/v1/todos/1/time-entries/3", body: `{"note": "mine"}`, wantCode: http.StatusForbidden},
+		{desc: "entry of another todo", token: bob, method: http.MethodGet, path: "/v1/todos/2/time-entries/3", wantCode: http.StatusNotFound},
+		{desc: "list entries", token: bob, method: http.MethodGet, path: "/v1/todos/1/time-entries", wantCode: http.StatusOK, wantBody: `"note":"design"`},
+		{desc: "todo totals", token: bob, method: http.MethodGet, path: "/v1/todos/1/time", wantCode: http.StatusOK, wantBody: `"own":{"estimateSeconds":7200,"trackedSeconds":7200,"remainingSeconds":0},"withSubtasks":{"estimateSeconds":10800`},
+		{desc: "project totals", token: bob, method: http.MethodGet, path: "/v1/projects/1/time", wantCode: http.StatusOK, wantBody: `"total":{"estimateSeconds":10800,"trackedSeconds":7200`},
+		{desc: "report", token: bob, method: http.MethodGet, path: "/v1/time/report?from=2025-01-06&to=2025-01-12", wantCode: http.StatusOK, wantBody: `{"period":"2025-01-06","seconds":7200,"entries":1}`},
+		{desc: "csv report", token: bob, method: http.MethodGet, path: "/v1/time/report?from=2025-01-06&to=2025-01-12&groupBy=week&format=csv", wantCode: http.StatusOK, wantBody: "period,seconds,hours,entries\n2025-01-06,7200,2.00,1\n"},
+		{desc: "report too long", token: bob, method: http.MethodGet, path: "/v1/time/report?from=2024-01-01&to=2025-12-31", wantCode: http.StatusBadRequest},
+		{desc: "remove entry", token: alice, method: http.MethodDelete, path: "/v1/todos/1/time-entries/3", wantCode: http.StatusNoContent},
+		{desc: "removed entry", token: bob, method: http.MethodGet, path: "/v1/todos/1/time-entries/3", wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serveIn(teamID, step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+			if step.wantBody != "" {
+				s.Contains(w.Body.String(), step.wantBody)
+			}
+		})
+	}
+}
+
+func (s *routerSuite) TestAPIKey() {
+	w := s.serve(s.token, http.MethodPost, "/v1/api-keys", `{"name": "ci", "scopes": ["todos:read"]}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Key string `json:"key"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	w = s.serve(s.token, http.MethodPost, "/v1/api-keys", `{"name": "admin", "scopes": ["projects:write"]}`, "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var admin struct {
+		Key string `json:"key"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &admin))
+
+	steps := []struct {
+		desc     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{desc: "read with key", token: created.Key, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusOK},
+		{desc: "write without scope", token: created.Key, method: http.MethodPost, path: "/v1/todos", body: `{"title": "t"}`, wantCode: http.StatusForbidden},
+		{desc: "other resource without scope", token: created.Key, method: http.MethodGet, path: "/v1/projects", wantCode: http.StatusForbidden},
+		{desc: "current user with key", token: created.Key, method: http.MethodGet, path: "/v1/users/me", wantCode: http.StatusOK},
+		{desc: "manage keys with key", token: created.Key, method: http.MethodGet, path: "/v1/api-keys", wantCode: http.StatusForbidden},
+		{desc: "share a project with key", token: admin.Key, method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "bob@example.com", "role": "viewer"}`, wantCode: http.StatusForbidden},
+		{desc: "unshare a project with key", token: admin.Key, method: http.MethodDelete, path: "/v1/projects/1/shares/2", wantCode: http.StatusForbidden},
+		{desc: "create with unknown scope", token: s.token, method: http.MethodPost, path: "/v1/api-keys", body: `{"name": "ci", "scopes": ["admin"]}`, wantCode: http.StatusBadRequest},
+		{desc: "list keys", token: s.token, method: http.MethodGet, path: "/v1/api-keys", wantCode: http.StatusOK},
+		{desc: "revoke", token: s.token, method: http.MethodDelete, path: "/v1/api-keys/1", wantCode: http.StatusNoContent},
+		{desc: "revoked key", token: created.Key, method: http.MethodGet, path: "/v1/todos", wantCode: http.StatusUnauthorized},
+		{desc: "revoke other user's key", token: s.signUp("bob@example.com"), method: http.MethodDelete, path: "/v1/api-keys/1", wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		s.Run(step.desc, func() {
+			w := s.serve(step.token, step.method, step.path, step.body, "")
+
+			s.Equal(step.wantCode, w.Code, w.Body.String())
+		})
+	}
+
+	s.Run("last use is tracked", func() {
+		w := s.serve(s.token, http.MethodGet, "/v1/api-keys", "", "")
+		s.Contains(w.Body.String(), `"lastUsedAt"`)
+		s.Contains(w.Body.String(), `"revokedAt"`)
+		s.NotContains(w.Body.String(), created.Key)
+	})
+}
+
+// TestSSO logs in through a stand-in identity provider the way a browser
+// would: from the login redirect, through the provider, to the callback.
+func (s *routerSuite) TestSSO() {
+	provider := oidctest.NewProvider("todo", "secret")
+	defer provider.Close()
+	s.setup(setupOptions{provider: provider})
+
+	// login goes from /v1/sso/login to the callback and returns the
+	// callback request, cookie included.
+	login := func() *http.Request {
+		w := s.serve("", http.MethodGet, "/v1/sso/login", "", "")
+		s.Require().Equal(http.StatusFound, w.Code, w.Body.String())
+		s.Require().True(strings.HasPrefix(w.Header().Get("Location"), provider.URL+"/authorize?"))
+		cookies := w.Result().Cookies()
+		s.Require().Len(cookies, 1)
+		s.True(cookies[0].HttpOnly)
+		s.Equal(http.SameSiteLaxMode, cookies[0].SameSite)
+
+		back, err := provider.Login(w.Header().Get("Location"))
+		s.Require().NoError(err)
+		req := httptest.NewRequest(http.MethodGet, back.RequestURI(), nil)
+		req.AddCookie(cookies[0])
+		return req
+	}
+	callback := func(req *http.Request) (*httptest.ResponseRecorder, string) {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		var resp struct {
+			AccessToken string `json:"accessToken"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.AccessToken
+	}
+	me := func(token string) map[string]any {
+		w := s.serve(token, http.MethodGet, "/v1/users/me", "", "")
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var got map[string]any
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &got))
+		return got
+	}
+
+	var token string
+	s.Run("links the user with the verified email", func() {
+		provider.SetUser(map[string]any{"sub": "alice-1", "email": "Alice@example.com", "email_verified": true})
+		req := login()
+
+		w, got := callback(req)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		s.Equal("no-store", w.Header().Get("Cache-Control"))
+		s.Equal(1.0, me(got)["id"])
+		token = got
+
+		w, _ = callback(req)
+		s.Equal(http.StatusUnauthorized, w.Code, "the state is used up")
+	})
+	s.Run("creates a user without password", func() {
+		provider.SetUser(map[string]any{"sub": "bob-1", "email": "bob@example.com", "email_verified": "true", "name": "Bob"})
+
+		w, got := callback(login())
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		s.Equal(map[string]any{"id": 2.0, "email": "bob@example.com", "name": "Bob"}, lo.PickByKeys(me(got), []string{"id", "email", "name"}))
+
+		w = s.serve("", http.MethodPost, "/v1/sessions", `{"email": "bob@example.com", "password": "correct horse"}`, "")
+		s.Equal(http.StatusUnauthorized, w.Code, "bob has no password")
+	})
+	s.Run("subject stays linked when the email changes", func() {
+		provider.SetUser(map[string]any{"sub": "alice-1", "email": "alice@corp.example.com", "email_verified": true})
+
+		_, got := callback(login())
+		s.Equal(1.0, me(got)["id"])
+	})
+	s.Run("email not verified", func() {
+		provider.SetUser(map[string]any{"sub": "carol-1", "email": "carol@example.com"})
+
+		w, _ := callback(login())
+		s.Equal(http.StatusUnauthorized, w.Code, w.Body.String())
+	})
+	s.Run("callback in another browser", func() {
+		req := login()
+		req.Header.Del("Cookie")
+
+		w, _ := callback(req)
+		s.Equal(http.StatusUnauthorized, w.Code)
+		s.JSONEq(`{"error": "login was started in another browser"}`, w.Body.String())
+	})
+	s.Run("denied at the provider", func() {
+		provider.Deny("access_denied")
+		defer provider.Deny("")
+
+		w, _ := callback(login())
+		s.Equal(http.StatusUnauthorized, w.Code)
+		s.JSONEq(`{"error": "identity provider refused the login: access_denied"}`, w.Body.String())
+	})
+	s.Run("logout", func() {
+		w := s.serve(token, http.MethodPost, "/v1/sso/logout", "", "")
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			LogoutURL string `json:"logoutUrl"`
+		}
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+		s.True(strings.HasPrefix(resp.LogoutURL, provider.URL+"/logout?"), resp.LogoutURL)
+
+		w = s.serve(token, http.MethodGet, "/v1/users/me", "", "")
+		s.Equal(http.StatusUnauthorized, w.Code)
+	})
+	s.Run("password session logout", func() {
+		w := s.serve(s.token, http.MethodDelete, "/v1/sessions/current", "", "")
+		s.Require().Equal(http.StatusNoContent, w.Code, w.Body.String())
+
+		w = s.serve(s.token, http.MethodGet, "/v1/users/me", "", "")
+		s.Equal(http.StatusUnauthorized, w.Code)
+	})
+}
+
+func (s *routerSuite) TestRateLimit() {
+	s.Run("per user with a tighter limit on creating todos", func() {
+		s.setup(setupOptions{limits: middleware.RateLimitOptions{
+			Default: ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 3, Per: time.Minute}),
+			Routes: map[string]ratelimit.Limiter{
+				"POST /v1/todos": ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 1, Per: time.Minute}),
+			},
+		}})
+		bob := s.signUp("bob@example.com")
+
+		w := s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-1"}`, "")
+		s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+		s.Equal("0", w.Header().Get("RateLimit-Remaining"))
+		w = s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-2"}`, "")
+		s.Equal(http.StatusTooManyRequests, w.Code, w.Body.String())
+		s.Equal("60", w.Header().Get("Retry-After"))
+		s.Equal(http.StatusCreated, s.serve(bob, http.MethodPost, "/v1/todos", `{"title": "title-2"}`, "").Code)
+
+		for range 3 {
+			s.Equal(http.StatusOK, s.serve(s.token, http.MethodGet, "/v1/todos", "", "").Code)
+		}
+		s.Equal(http.StatusTooManyRequests, s.serve(s.token, http.MethodGet, "/v1/todos", "", "").Code)
+	})
+	s.Run("public routes by IP address", func() {
+		s.setup(setupOptions{limits: middleware.RateLimitOptions{
+			Routes: map[string]ratelimit.Limiter{
+				"POST /v1/sessions": ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 2, Per: time.Minute}),
+			},
+		}})
+		login := `{"email": "alice@example.com", "password": "wrong"}`
+
+		s.Equal(http.StatusUnauthorized, s.serve("", http.MethodPost, "/v1/sessions", login, "").Code)
+		s.Equal(http.StatusUnauthorized, s.serve("", http.MethodPost, "/v1/sessions", login, "").Code)
+		w := s.serve("", http.MethodPost, "/v1/sessions", `{"email": "alice@example.com", "password": "correct horse"}`, "")
+		s.Equal(http.StatusTooManyRequests, w.Code)
+		s.Equal(http.StatusOK, s.serve(s.token, http.MethodGet, "/v1/users/me", "", "").Code, "other routes are unlimited")
+	})
+	s.Run("bad credentials by IP address before Auth", func() {
+		s.setup(setupOptions{limits: middleware.RateLimitOptions{
+			PreAuth: ratelimit.NewMemoryLimiter(ratelimit.Limit{Requests: 2, Per: time.Minute}),
+		}})
+
+		s.Equal(http.StatusUnauthorized, s.serve("guess-1", http.MethodGet, "/v1/todos", "", "").Code)
+		s.Equal(http.StatusUnauthorized, s.serve("guess-2", http.MethodGet, "/v1/todos", "", "").Code)
+		s.Equal(http.StatusTooManyRequests, s.serve("guess-3", http.MethodGet, "/v1/todos", "", "").Code)
+		s.Equal(http.StatusOK, s.serve("", http.MethodGet, "/v1/ping", "", "").Code, "public routes are limited on their own")
+	})
+}
+
+func (s *routerSuite) TestQuota() {
+	s.Run("max todos", func() {
+		s.setup(setupOptions{quota: todo.Quota{MaxTodos: 2}})
+
+		w := s.serve(s.token, http.MethodPost, "/v1/todos:batchCreate", `{"mode": "atomic", "items": [{"title": "title-1"}, {"title": "title-2"}, {"title": "title-3"}]}`, "")
+		s.Equal(http.StatusForbidden, w.Code, w.Body.String())
+		s.Contains(w.Body.String(), "quota exceeded: a user may own at most 2 todos")
+
+		s.Equal(http.StatusCreated, s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-1"}`, "").Code)
+		s.Equal(http.StatusCreated, s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-2"}`, "").Code)
+		w = s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-3"}`, "")
+		s.Equal(http.StatusForbidden, w.Code)
+		s.JSONEq(`{"error": "forbidden: quota exceeded: a user may own at most 2 todos"}`, w.Body.String())
+
+		s.Equal(http.StatusNoContent, s.serve(s.token, http.MethodDelete, "/v1/todos/1", "", "").Code)
+		s.Equal(http.StatusCreated, s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-3"}`, "").Code)
+	})
+	s.Run("max description", func() {
+		s.setup(setupOptions{quota: todo.Quota{MaxDescription: 5}})
+		s.Equal(http.StatusCreated, s.serve(s.token, http.MethodPost, "/v1/todos", `{"title": "title-1", "description": "short"}`, "").Code)
+
+		w := s.serve(s.token, http.MethodPatch, "/v1/todos/1", `{"description": "too long"}`, "")
+		s.Equal(http.StatusForbidden, w.Code, w.Body.String())
+		s.JSONEq(`{"error": "forbidden: quota exceeded: descriptions may be at most 5 bytes"}`, w.Body.String())
+	})
+}
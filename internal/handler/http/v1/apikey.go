@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyHandler struct {
+	srv service.APIKey
+}
+
+// NewAPIKeyRoutes registers API key management, which needs Auth and is
+// closed to API keys themselves.
+func NewAPIKeyRoutes(rg *gin.RouterGroup, srv service.APIKey) {
+	h := &apiKeyHandler{
+		srv: srv,
+	}
+	session := middleware.RequireSession()
+	rg.POST("/api-keys", session, h.create)
+	rg.GET("/api-keys", session, h.list)
+	rg.DELETE("/api-keys/:id", session, h.revoke)
+}
+
+type apiKeyParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type createAPIKeyReq struct {
+	Name      string         `json:"name" binding:"required,max=100"`
+	Scopes    []entity.Scope `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time     `json:"expiresAt"`
+}
+
+type createAPIKeyResp struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Prefix    string         `json:"prefix"`
+	Scopes    []entity.Scope `json:"scopes"`
+	ExpiresAt *time.Time     `json:"expiresAt,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	// Key is only ever returned here.
+	Key string `json:"key"`
+}
+
+func (h *apiKeyHandler) create(c *gin.Context) {
+	var req createAPIKeyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	apiKey, key, err := h.srv.Issue(entity.IssueAPIKeyInput{
+		UserID:    middleware.UserID(c),
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, createAPIKeyResp{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		ExpiresAt: apiKey.ExpiresAt,
+		CreatedAt: apiKey.CreatedAt,
+		Key:       key,
+	})
+}
+
+type listAPIKeyResp struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`
+	Scopes     []entity.Scope `json:"scopes"`
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time     `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time     `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+func (h *apiKeyHandler) list(c *gin.Context) {
+	apiKeys, err := h.srv.List(middleware.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]listAPIKeyResp, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		resp = append(resp, listAPIKeyResp{
+			ID:         apiKey.ID,
+			Name:       apiKey.Name,
+			Prefix:     apiKey.Prefix,
+			Scopes:     apiKey.Scopes,
+			ExpiresAt:  apiKey.ExpiresAt,
+			LastUsedAt: apiKey.LastUsedAt,
+			RevokedAt:  apiKey.RevokedAt,
+			CreatedAt:  apiKey.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *apiKeyHandler) revoke(c *gin.Context) {
+	var params apiKeyParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err := h.srv.Revoke(middleware.UserID(c), params.ID)
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type apiKeySuite struct {
+	suite.Suite
+	router   *gin.Engine
+	mockSrv  *mocks.MockAPIKey
+	mockUser *mocks.MockUser
+}
+
+func (s *apiKeySuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockAPIKey(ctrl)
+	s.mockUser = mocks.NewMockUser(ctrl)
+	s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewAPIKeyRoutes(s.router.Group("v1", middleware.Auth(s.mockUser, s.mockSrv)), s.mockSrv)
+}
+
+func TestAPIKeySuite(t *testing.T) {
+	suite.Run(t, new(apiKeySuite))
+}
+
+func (s *apiKeySuite) serve(method, path, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *apiKeySuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"name": "ci", "scopes": ["todos:read"], "expiresAt": "2000-01-01T00:00:00Z"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Issue(entity.IssueAPIKeyInput{
+					UserID:    1,
+					Name:      "ci",
+					Scopes:    []entity.Scope{entity.ScopeTodosRead},
+					ExpiresAt: lo.ToPtr(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+				}).Return(&entity.APIKey{
+					ID:        1,
+					UserID:    1,
+					Name:      "ci",
+					Prefix:    "tdk_AAAAAAAA",
+					Hash:      "hash",
+					Scopes:    []entity.Scope{entity.ScopeTodosRead},
+					ExpiresAt: lo.ToPtr(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+					CreatedAt: time.Unix(123456789, 0),
+				}, "tdk_AAAAAAAA_SECRET", nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "id": 1,
+			  "name": "ci",
+			  "prefix": "tdk_AAAAAAAA",
+			  "scopes": ["todos:read"],
+			  "expiresAt": "2000-01-01T00:00:00Z",
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "key": "tdk_AAAAAAAA_SECRET"
+			}`,
+		},
+		{
+			desc:     "missing scopes",
+			body:     `{"name": "ci"}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'createAPIKeyReq.Scopes' Error:Field validation for 'Scopes' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "unknown scope",
+			body: `{"name": "ci", "scopes": ["admin"]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Issue(gomock.Any()).Return(nil, "", fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidScope)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: invalid scope"}`,
+		},
+		{
+			desc: "service issue failed",
+			body: `{"name": "ci", "scopes": ["todos:read"]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Issue(gomock.Any()).Return(nil, "", errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, "/v1/api-keys", tt.body, "token-1")
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+			if w.Code == http.StatusCreated {
+				s.Equal("no-store", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func (s *apiKeySuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(1).Return([]entity.APIKey{
+			{ID: 1, Name: "ci", Prefix: "tdk_AAAAAAAA", Hash: "hash", Scopes: []entity.Scope{entity.ScopeTodosRead}, CreatedAt: time.Unix(123456789, 0)},
+			{ID: 2, Name: "old", Prefix: "tdk_BBBBBBBB", Hash: "hash", Scopes: []entity.Scope{entity.ScopeTodosWrite}, LastUsedAt: lo.ToPtr(time.Unix(123456789, 0)), RevokedAt: lo.ToPtr(time.Unix(123456789, 0)), CreatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/api-keys", "", "token-1")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[
+		  {"id": 1, "name": "ci", "prefix": "tdk_AAAAAAAA", "scopes": ["todos:read"], "createdAt": "1973-11-30T05:33:09+08:00"},
+		  {"id": 2, "name": "old", "prefix": "tdk_BBBBBBBB", "scopes": ["todos:write"], "lastUsedAt": "1973-11-30T05:33:09+08:00", "revokedAt": "1973-11-30T05:33:09+08:00", "createdAt": "1973-11-30T05:33:09+08:00"}
+		]`, w.Body.String())
+	})
+	s.Run("api key cannot list keys", func() {
+		s.mockSrv.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(&entity.User{ID: 1}, &entity.APIKey{ID: 1, Scopes: entity.Scopes}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/api-keys", "", "tdk_AAAAAAAA_SECRET")
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+}
+
+func (s *apiKeySuite) TestRevoke() {
+	tests := []struct {
+		desc     string
+		path     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			path: "/v1/api-keys/1",
+			mock: func() {
+				s.mockSrv.EXPECT().Revoke(1, 1).Return(&entity.APIKey{ID: 1}, nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:     "invalid id",
+			path:     "/v1/api-keys/abc",
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "not found",
+			path: "/v1/api-keys/1",
+			mock: func() {
+				s.mockSrv.EXPECT().Revoke(1, 1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodDelete, tt.path, "", "token-1")
+			s.Equal(tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentField is the multipart field files are uploaded in.
+const attachmentField = "file"
+
+type attachmentHandler struct {
+	srv service.Attachment
+}
+
+// NewAttachmentRoutes registers the files attached to todos, which take the
+// scopes of todos.
+func NewAttachmentRoutes(rg *gin.RouterGroup, srv service.Attachment) {
+	h := &attachmentHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeTodosRead)
+	write := middleware.RequireScope(entity.ScopeTodosWrite)
+	rg.POST("/todos/:id/attachments", write, h.upload)
+	rg.GET("/todos/:id/attachments", read, h.list)
+	rg.GET("/todos/:id/attachments/:attachmentId", read, h.get)
+	rg.GET("/todos/:id/attachments/:attachmentId/content", read, h.download)
+	rg.DELETE("/todos/:id/attachments/:attachmentId", write, h.remove)
+}
+
+// attachments returns the attachment service as seen by the caller.
+func (h *attachmentHandler) attachments(c *gin.Context) service.Attachment {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// attachmentError writes the status for errors shared by every attachment
+// endpoint.
+func attachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type attachmentTodoParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type attachmentParams struct {
+	ID           int `uri:"id" binding:"required,min=1"`
+	AttachmentID int `uri:"attachmentId" binding:"required,min=1"`
+}
+
+type uploadAttachmentResp struct {
+	ID          int       `json:"id"`
+	TodoID      int       `json:"todoId"`
+	UploaderID  int       `json:"uploaderId"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// upload streams the file in the multipart field "file" to the blob store,
+// so it is never held in memory. Other fields are skipped.
+func (h *attachmentHandler) upload(c *gin.Context) {
+	var params attachmentTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field " + attachmentField + " is required"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != attachmentField {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.attachments(c).Upload(params.ID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			attachmentError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, uploadAttachmentResp{
+			ID:          attachment.ID,
+			TodoID:      attachment.TodoID,
+			UploaderID:  attachment.UploaderID,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			SHA256:      attachment.BlobKey,
+			CreatedAt:   attachment.CreatedAt,
+		})
+		return
+	}
+}
+
+type listAttachmentResp struct {
+	Items []listAttachmentItem `json:"items"`
+}
+
+type listAttachmentItem struct {
+	ID          int       `json:"id"`
+	UploaderID  int       `json:"uploaderId"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (h *attachmentHandler) list(c *gin.Context) {
+	var params attachmentTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachments, err := h.attachments(c).List(params.ID)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+
+	resp := listAttachmentResp{Items: make([]listAttachmentItem, len(attachments))}
+	for i, attachment := range attachments {
+		resp.Items[i] = listAttachmentItem{
+			ID:          attachment.ID,
+			UploaderID:  attachment.UploaderID,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			SHA256:      attachment.BlobKey,
+			CreatedAt:   attachment.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type getAttachmentResp struct {
+	ID          int       `json:"id"`
+	TodoID      int       `json:"todoId"`
+	UploaderID  int       `json:"uploaderId"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (h *attachmentHandler) get(c *gin.Context) {
+	var params attachmentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachment, err := h.attachments(c).Get(params.ID, params.AttachmentID)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAttachmentResp{
+		ID:          attachment.ID,
+		TodoID:      attachment.TodoID,
+		UploaderID:  attachment.UploaderID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		SHA256:      attachment.BlobKey,
+		CreatedAt:   attachment.CreatedAt,
+	})
+}
+
+// download serves the content of an attachment, answering Range,
+// If-Range and If-None-Match requests. The content never changes, so its
+// SHA-256 is a strong ETag. Only images are shown inline; everything else is
+// downloaded, and nosniff keeps browsers from guessing otherwise.
+func (h *attachmentHandler) download(c *gin.Context) {
+	var params attachmentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachment, content, err := h.attachments(c).Open(params.ID, params.AttachmentID)
+	if err != nil {
+		attachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	header := c.Writer.Header()
+	header.Set("Content-Type", attachment.ContentType)
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	header.Set("ETag", `"`+attachment.BlobKey+`"`)
+	header.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, attachment.Name, attachment.CreatedAt, content)
+}
+
+// remove deletes an attachment, which its uploader and those who may delete
+// todos of others can do. The content is removed by the next sweep once no
+// attachment refers to it.
+func (h *attachmentHandler) remove(c *gin.Context) {
+	var params attachmentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.attachments(c).Delete(params.ID, params.AttachmentID); err != nil {
+		attachmentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
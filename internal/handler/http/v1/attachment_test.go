@@ -0,0 +1,330 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type attachmentSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockAttachment
+}
+
+func (s *attachmentSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockAttachment(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewAttachmentRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestAttachmentSuite(t *testing.T) {
+	suite.Run(t, new(attachmentSuite))
+}
+
+// multipartBody encodes fields as a multipart form, with the values of
+// fields named like attachmentField as files.
+func multipartBody(fields map[string]string) (io.Reader, string) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		var w io.Writer
+		if name == attachmentField {
+			w, _ = mw.CreateFormFile(name, "notes.txt")
+		} else {
+			w, _ = mw.CreateFormField(name)
+		}
+		_, _ = w.Write([]byte(value))
+	}
+	_ = mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+// nopReadSeekCloser gives content the Close an opened attachment has.
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+func (s *attachmentSuite) TestUpload() {
+	attachment := &entity.Attachment{
+		ID:          1,
+		TodoID:      2,
+		UploaderID:  3,
+		Name:        "notes.txt",
+		ContentType: "text/plain; charset=utf-8",
+		Size:        5,
+		BlobKey:     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		CreatedAt:   time.Unix(123456789, 0),
+	}
+
+	tests := []struct {
+		desc     string
+		fields   map[string]string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "success",
+			fields: map[string]string{"comment": "skipped", attachmentField: "hello"},
+			mock: func() {
+				s.mockSrv.EXPECT().Upload(2, "notes.txt", gomock.Any()).DoAndReturn(func(_ int, _ string, r io.Reader) (*entity.Attachment, error) {
+					content, err := io.ReadAll(r)
+					s.Require().NoError(err)
+					s.Equal("hello", string(content))
+					return attachment, nil
+				}).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 1, "todoId": 2, "uploaderId": 3, "name": "notes.txt", "contentType": "text/plain; charset=utf-8", "size": 5, "sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "createdAt": "1973-11-30T05:33:09+08:00"}`,
+		},
+		{
+			desc:     "no file",
+			fields:   map[string]string{"comment": "skipped"},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "multipart field file is required"}`,
+		},
+		{
+			desc:   "too large",
+			fields: map[string]string{attachmentField: "hello"},
+			mock: func() {
+				s.mockSrv.EXPECT().Upload(2, "notes.txt", gomock.Any()).Return(nil, fmt.Errorf("%w: %w, at most 4 bytes are allowed", service.ErrInvalidInput, entity.ErrAttachmentTooLarge)).Times(1)
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantResp: `{"error": "invalid input: attachment is too large, at most 4 bytes are allowed"}`,
+		},
+		{
+			desc:   "too many",
+			fields: map[string]string{attachmentField: "hello"},
+			mock: func() {
+				s.mockSrv.EXPECT().Upload(2, "notes.txt", gomock.Any()).Return(nil, fmt.Errorf("%w: %w, at most 1 are allowed", service.ErrQuotaExceeded, entity.ErrTooManyAttachments)).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: `{"error": "forbidden: quota exceeded: todo has too many attachments, at most 1 are allowed"}`,
+		},
+		{
+			desc:   "todo not found",
+			fields: map[string]string{attachmentField: "hello"},
+			mock: func() {
+				s.mockSrv.EXPECT().Upload(2, "notes.txt", gomock.Any()).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			body, contentType := multipartBody(tt.fields)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/todos/2/attachments", body)
+			req.Header.Set("Content-Type", contentType)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+	s.Run("not multipart", func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/todos/2/attachments", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		s.router.ServeHTTP(w, req)
+
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
+
+func (s *attachmentSuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(2).Return([]entity.Attachment{
+			{ID: 1, TodoID: 2, UploaderID: 3, Name: "a.png", ContentType: "image/png", Size: 8, BlobKey: "k", CreatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/todos/2/attachments", nil))
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"items": [{"id": 1, "uploaderId": 3, "name": "a.png", "contentType": "image/png", "size": 8, "sha256": "k", "createdAt": "1973-11-30T05:33:09+08:00"}]}`, w.Body.String())
+	})
+	s.Run("empty", func() {
+		s.mockSrv.EXPECT().List(2).Return(nil, nil).Times(1)
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/todos/2/attachments", nil))
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"items": []}`, w.Body.String())
+	})
+}
+
+func (s *attachmentSuite) TestDownload() {
+	attachment := &entity.Attachment{
+		ID:          1,
+		TodoID:      2,
+		Name:        "résumé.pdf",
+		ContentType: "application/pdf",
+		Size:        10,
+		BlobKey:     "abc",
+		CreatedAt:   time.Unix(123456789, 0),
+	}
+	open := func() {
+		content := nopReadSeekCloser{strings.NewReader("0123456789")}
+		s.mockSrv.EXPECT().Open(2, 1).Return(attachment, content, nil).Times(1)
+	}
+
+	tests := []struct {
+		desc       string
+		header     map[string]string
+		wantCode   int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			desc:     "whole file",
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+			wantHeader: map[string]string{
+				"Content-Type":           "application/pdf",
+				"Content-Disposition":    "attachment; filename*=utf-8''r%C3%A9sum%C3%A9.pdf",
+				"Etag":                   `"abc"`,
+				"Accept-Ranges":          "bytes",
+				"X-Content-Type-Options": "nosniff",
+			},
+		},
+		{
+			desc:     "range",
+			header:   map[string]string{"Range": "bytes=2-4"},
+			wantCode: http.StatusPartialContent,
+			wantBody: "234",
+			wantHeader: map[string]string{
+				"Content-Range": "bytes 2-4/10",
+			},
+		},
+		{
+			desc:     "range not satisfiable",
+			header:   map[string]string{"Range": "bytes=20-"},
+			wantCode: http.StatusRequestedRangeNotSatisfiable,
+			wantHeader: map[string]string{
+				"Content-Range": "bytes */10",
+			},
+		},
+		{
+			desc:     "not modified",
+			header:   map[string]string{"If-None-Match": `"abc"`},
+			wantCode: http.StatusNotModified,
+		},
+		{
+			desc:     "range of another version",
+			header:   map[string]string{"Range": "bytes=2-4", "If-Range": `"def"`},
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			open()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/v1/todos/2/attachments/1/content", nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				s.Equal(tt.wantBody, w.Body.String())
+			}
+			for name, value := range tt.wantHeader {
+				s.Equal(value, w.Header().Get(name), name)
+			}
+		})
+	}
+	s.Run("images are shown inline", func() {
+		image := *attachment
+		image.Name = "a.png"
+		image.ContentType = "image/png"
+		s.mockSrv.EXPECT().Open(2, 1).Return(&image, nopReadSeekCloser{strings.NewReader("png")}, nil).Times(1)
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/todos/2/attachments/1/content", nil))
+
+		s.Equal(http.StatusOK, w.Code)
+		s.Equal("inline; filename=a.png", w.Header().Get("Content-Disposition"))
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Open(2, 1).Return(nil, nil, service.ErrNotFound).Times(1)
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/todos/2/attachments/1/content", nil))
+
+		s.Equal(http.StatusNotFound, w.Code)
+		s.JSONEq(`{"error": "not found"}`, w.Body.String())
+	})
+}
+
+func (s *attachmentSuite) TestDelete() {
+	tests := []struct {
+		desc     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(2, 1).Return(nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc: "forbidden",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(2, 1).Return(service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			desc:     "invalid id",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			path := "/v1/todos/2/attachments/1"
+			if tt.mock != nil {
+				tt.mock()
+			} else {
+				path = "/v1/todos/2/attachments/abc"
+			}
+
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type checklistHandler struct {
+	srv service.Todo
+}
+
+// NewChecklistRoutes registers the checklists embedded in todos, which take
+// the scopes of todos.
+func NewChecklistRoutes(rg *gin.RouterGroup, srv service.Todo) {
+	h := &checklistHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeTodosRead)
+	write := middleware.RequireScope(entity.ScopeTodosWrite)
+	rg.GET("/todos/:id/checklist", read, h.get)
+	rg.PATCH("/todos/:id/checklist", write, h.update)
+	rg.PUT("/todos/:id/checklist/order", write, h.reorder)
+	rg.POST("/todos/:id/checklist/items", write, h.addItem)
+	rg.PATCH("/todos/:id/checklist/items/:itemId", write, h.updateItem)
+	rg.DELETE("/todos/:id/checklist/items/:itemId", write, h.removeItem)
+}
+
+// todos returns the todo service as seen by the caller.
+func (h *checklistHandler) todos(c *gin.Context) service.Todo {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// checklistError writes the status for errors shared by every checklist
+// endpoint.
+func checklistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type checklistTodoParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type checklistItemParams struct {
+	ID     int `uri:"id" binding:"required,min=1"`
+	ItemID int `uri:"itemId" binding:"required,min=1"`
+}
+
+// checklistResp is what every checklist endpoint answers with. It carries
+// whether the todo is completed, since checking an item can complete or
+// reopen it.
+type checklistResp struct {
+	TodoID       int                `json:"todoId"`
+	Items        []checklistItemDTO `json:"items"`
+	Checked      int                `json:"checked"`
+	Total        int                `json:"total"`
+	AutoComplete bool               `json:"autoComplete"`
+	IsCompleted  bool               `json:"isCompleted"`
+}
+
+type checklistItemDTO struct {
+	ID      int    `json:"id"`
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+func newChecklistResp(todo *entity.Todo) checklistResp {
+	checked, total := todo.Checklist.Progress()
+	return checklistResp{
+		TodoID:       todo.ID,
+		Items:        checklistItems(todo.Checklist),
+		Checked:      checked,
+		Total:        total,
+		AutoComplete: todo.Checklist.AutoComplete,
+		IsCompleted:  todo.IsCompleted,
+	}
+}
+
+// checklistItems keeps an empty checklist rendering as an empty list.
+func checklistItems(checklist entity.Checklist) []checklistItemDTO {
+	items := make([]checklistItemDTO, len(checklist.Items))
+	for i, item := range checklist.Items {
+		items[i] = checklistItemDTO{ID: item.ID, Text: item.Text, Checked: item.Checked}
+	}
+	return items
+}
+
+func (h *checklistHandler) get(c *gin.Context) {
+	var params checklistTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).Get(params.ID)
+	if err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newChecklistResp(todo))
+}
+
+type updateChecklistReq struct {
+	AutoComplete *bool `json:"autoComplete" binding:"required"`
+}
+
+// update sets whether the todo is completed once every item is checked and
+// reopened when an item is unchecked. Turning it on completes a todo whose
+// items are all checked already.
+func (h *checklistHandler) update(c *gin.Context) {
+	var params checklistTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req updateChecklistReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).SetChecklistAutoComplete(params.ID, *req.AutoComplete)
+	if err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newChecklistResp(todo))
+}
+
+type reorderChecklistReq struct {
+	ItemIDs []int `json:"itemIds" binding:"required"`
+}
+
+func (h *checklistHandler) reorder(c *gin.Context) {
+	var params checklistTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req reorderChecklistReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).ReorderChecklist(params.ID, req.ItemIDs)
+	if err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newChecklistResp(todo))
+}
+
+type addChecklistItemReq struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// addItem appends an unchecked item, which reopens a todo whose checklist
+// auto-completes.
+func (h *checklistHandler) addItem(c *gin.Context) {
+	var params checklistTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req addChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).AddChecklistItem(params.ID, req.Text)
+	if err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newChecklistResp(todo))
+}
+
+type updateChecklistItemReq struct {
+	Text    *string `json:"text"`
+	Checked *bool   `json:"checked"`
+}
+
+// updateItem edits the text of an item or checks it.
+func (h *checklistHandler) updateItem(c *gin.Context) {
+	var params checklistItemParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req updateChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).UpdateChecklistItem(params.ID, params.ItemID, entity.UpdateChecklistItemInput{
+		Text:    req.Text,
+		Checked: req.Checked,
+	})
+	if err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newChecklistResp(todo))
+}
+
+func (h *checklistHandler) removeItem(c *gin.Context) {
+	var params checklistItemParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.todos(c).RemoveChecklistItem(params.ID, params.ItemID); err != nil {
+		checklistError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
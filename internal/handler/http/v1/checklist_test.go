@@ -0,0 +1,318 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type checklistSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTodo
+}
+
+func (s *checklistSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewChecklistRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestChecklistSuite(t *testing.T) {
+	suite.Run(t, new(checklistSuite))
+}
+
+func (s *checklistSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+var checklistTodo = &entity.Todo{
+	ID:          2,
+	IsCompleted: true,
+	Checklist: entity.Checklist{
+		Items:        []entity.ChecklistItem{{ID: 1, Text: "passport", Checked: true}, {ID: 3, Text: "tickets", Checked: true}},
+		AutoComplete: true,
+		NextID:       4,
+	},
+}
+
+const checklistTodoResp = `{"todoId": 2, "items": [{"id": 1, "text": "passport", "checked": true}, {"id": 3, "text": "tickets", "checked": true}], "checked": 2, "total": 2, "autoComplete": true, "isCompleted": true}`
+
+func (s *checklistSuite) TestGet() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Get(2).Return(checklistTodo, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/checklist", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(checklistTodoResp, w.Body.String())
+	})
+	s.Run("empty", func() {
+		s.mockSrv.EXPECT().Get(2).Return(&entity.Todo{ID: 2}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/checklist", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"todoId": 2, "items": [], "checked": 0, "total": 0, "autoComplete": false, "isCompleted": false}`, w.Body.String())
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Get(2).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/checklist", "")
+
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *checklistSuite) TestUpdate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			body: `{"autoComplete": true}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetChecklistAutoComplete(2, true).Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "missing autoComplete",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "forbidden",
+			body: `{"autoComplete": false}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetChecklistAutoComplete(2, false).Return(nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPatch, "/v1/todos/2/checklist", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
+
+func (s *checklistSuite) TestReorder() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"itemIds": [3, 1]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().ReorderChecklist(2, []int{3, 1}).Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: checklistTodoResp,
+		},
+		{
+			desc: "not every item",
+			body: `{"itemIds": [3]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().ReorderChecklist(2, []int{3}).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrChecklistOrder)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: checklist order must list every item exactly once"}`,
+		},
+		{
+			desc:     "missing itemIds",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPut, "/v1/todos/2/checklist/order", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp != "" {
+				s.JSONEq(tt.wantResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *checklistSuite) TestAddItem() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"text": "tickets"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddChecklistItem(2, "tickets").Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: checklistTodoResp,
+		},
+		{
+			desc: "full",
+			body: `{"text": "tickets"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddChecklistItem(2, "tickets").Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrChecklistFull)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: checklist can have at most 100 items"}`,
+		},
+		{
+			desc:     "missing text",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "todo not found",
+			body: `{"text": "tickets"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddChecklistItem(2, "tickets").Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPost, "/v1/todos/2/checklist/items", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp != "" {
+				s.JSONEq(tt.wantResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *checklistSuite) TestUpdateItem() {
+	tests := []struct {
+		desc     string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "check",
+			path: "/v1/todos/2/checklist/items/3",
+			body: `{"checked": true}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateChecklistItem(2, 3, entity.UpdateChecklistItemInput{Checked: lo.ToPtr(true)}).Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			desc: "edit text",
+			path: "/v1/todos/2/checklist/items/3",
+			body: `{"text": "tickets"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateChecklistItem(2, 3, entity.UpdateChecklistItemInput{Text: lo.ToPtr("tickets")}).Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			desc: "item not found",
+			path: "/v1/todos/2/checklist/items/9",
+			body: `{"checked": true}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateChecklistItem(2, 9, gomock.Any()).Return(nil, fmt.Errorf("%w: %w", service.ErrNotFound, entity.ErrChecklistItemNotFound)).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			desc:     "invalid item id",
+			path:     "/v1/todos/2/checklist/items/abc",
+			body:     `{"checked": true}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPatch, tt.path, tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
+
+func (s *checklistSuite) TestRemoveItem() {
+	tests := []struct {
+		desc     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			mock: func() {
+				s.mockSrv.EXPECT().RemoveChecklistItem(2, 3).Return(checklistTodo, nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc: "forbidden",
+			mock: func() {
+				s.mockSrv.EXPECT().RemoveChecklistItem(2, 3).Return(nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+
+			w := s.serve(http.MethodDelete, "/v1/todos/2/checklist/items/3", "")
+
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type commentHandler struct {
+	srv service.Comment
+}
+
+// NewCommentRoutes registers the comments and activity feed of todos, which
+// take the scopes of todos.
+func NewCommentRoutes(rg *gin.RouterGroup, srv service.Comment) {
+	h := &commentHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeTodosRead)
+	write := middleware.RequireScope(entity.ScopeTodosWrite)
+	rg.POST("/todos/:id/comments", write, h.create)
+	rg.GET("/todos/:id/comments", read, h.list)
+	rg.GET("/todos/:id/comments/:commentId", read, h.get)
+	rg.PATCH("/todos/:id/comments/:commentId", write, h.update)
+	rg.DELETE("/todos/:id/comments/:commentId", write, h.remove)
+	rg.GET("/todos/:id/activity", read, h.activity)
+}
+
+// comments returns the comment service as seen by the caller.
+func (h *commentHandler) comments(c *gin.Context) service.Comment {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// commentError writes the status for errors shared by every comment
+// endpoint.
+func commentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type commentTodoParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type commentParams struct {
+	ID        int `uri:"id" binding:"required,min=1"`
+	CommentID int `uri:"commentId" binding:"required,min=1"`
+}
+
+type pageParams struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (p pageParams) query() entity.PageQuery {
+	return entity.PageQuery{Cursor: p.Cursor, Limit: p.Limit}
+}
+
+type createCommentReq struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type createCommentResp struct {
+	ID        int       `json:"id"`
+	TodoID    int       `json:"todoId"`
+	AuthorID  int       `json:"authorId"`
+	Body      string    `json:"body"`
+	Mentions  []int     `json:"mentions"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// create comments on a todo. Users mentioned as @email who may read the
+// todo are notified.
+func (h *commentHandler) create(c *gin.Context) {
+	var params commentTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req createCommentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.comments(c).Create(params.ID, req.Body)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createCommentResp{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		Mentions:  mentionsDTO(comment.Mentions),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	})
+}
+
+type listCommentResp struct {
+	Items      []listCommentItem `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+type listCommentItem struct {
+	ID        int       `json:"id"`
+	AuthorID  int       `json:"authorId"`
+	Body      string    `json:"body"`
+	Mentions  []int     `json:"mentions"`
+	Edited    bool      `json:"edited"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *commentHandler) list(c *gin.Context) {
+	var params commentTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var page pageParams
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := h.comments(c).List(params.ID, page.query())
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+
+	resp := listCommentResp{
+		Items:      make([]listCommentItem, len(comments.Items)),
+		NextCursor: comments.NextCursor,
+	}
+	for i, comment := range comments.Items {
+		resp.Items[i] = listCommentItem{
+			ID:        comment.ID,
+			AuthorID:  comment.AuthorID,
+			Body:      comment.Body,
+			Mentions:  mentionsDTO(comment.Mentions),
+			Edited:    comment.Edited(),
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type getCommentResp struct {
+	ID        int              `json:"id"`
+	TodoID    int              `json:"todoId"`
+	AuthorID  int              `json:"authorId"`
+	Body      string           `json:"body"`
+	Mentions  []int            `json:"mentions"`
+	Edits     []commentEditDTO `json:"edits"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+type commentEditDTO struct {
+	Body     string    `json:"body"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+// get returns a comment along with the bodies it had before, oldest first.
+func (h *commentHandler) get(c *gin.Context) {
+	var params commentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.comments(c).Get(params.ID, params.CommentID)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+
+	edits := make([]commentEditDTO, len(comment.Edits))
+	for i, edit := range comment.Edits {
+		edits[i] = commentEditDTO{Body: edit.Body, EditedAt: edit.EditedAt}
+	}
+	c.JSON(http.StatusOK, getCommentResp{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		Mentions:  mentionsDTO(comment.Mentions),
+		Edits:     edits,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	})
+}
+
+type updateCommentReq struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type updateCommentResp struct {
+	ID        int       `json:"id"`
+	TodoID    int       `json:"todoId"`
+	AuthorID  int       `json:"authorId"`
+	Body      string    `json:"body"`
+	Mentions  []int     `json:"mentions"`
+	Edited    bool      `json:"edited"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// update edits a comment, which only its author may do.
+func (h *commentHandler) update(c *gin.Context) {
+	var params commentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req updateCommentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.comments(c).Update(params.ID, params.CommentID, req.Body)
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updateCommentResp{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		Mentions:  mentionsDTO(comment.Mentions),
+		Edited:    comment.Edited(),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	})
+}
+
+func (h *commentHandler) remove(c *gin.Context) {
+	var params commentParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.comments(c).Delete(params.ID, params.CommentID); err != nil {
+		commentError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type activityResp struct {
+	Items      []activityItem `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+type activityItem struct {
+	Kind    entity.ActivityKind `json:"kind"`
+	At      time.Time           `json:"at"`
+	Comment *listCommentItem    `json:"comment,omitempty"`
+	Change  *activityChange     `json:"change,omitempty"`
+}
+
+type activityChange struct {
+	ActorID int                 `json:"actorId,omitempty"`
+	Fields  []activityFieldDiff `json:"fields"`
+}
+
+type activityFieldDiff struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// activity returns the comments on a todo and the changes made to it, oldest
+// first.
+func (h *commentHandler) activity(c *gin.Context) {
+	var params commentTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var page pageParams
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.comments(c).Activity(params.ID, page.query())
+	if err != nil {
+		commentError(c, err)
+		return
+	}
+
+	resp := activityResp{
+		Items:      make([]activityItem, len(feed.Items)),
+		NextCursor: feed.NextCursor,
+	}
+	for i, activity := range feed.Items {
+		item := activityItem{Kind: activity.Kind, At: activity.At}
+		if comment := activity.Comment; comment != nil {
+			item.Comment = &listCommentItem{
+				ID:        comment.ID,
+				AuthorID:  comment.AuthorID,
+				Body:      comment.Body,
+				Mentions:  mentionsDTO(comment.Mentions),
+				Edited:    comment.Edited(),
+				CreatedAt: comment.CreatedAt,
+				UpdatedAt: comment.UpdatedAt,
+			}
+		}
+		if change := activity.Change; change != nil {
+			fields := make([]activityFieldDiff, len(change.Fields))
+			for j, field := range change.Fields {
+				fields[j] = activityFieldDiff{Field: field.Field, From: field.From, To: field.To}
+			}
+			item.Change = &activityChange{ActorID: change.ActorID, Fields: fields}
+		}
+		resp.Items[i] = item
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// mentionsDTO encodes no mentions as an empty list rather than null.
+func mentionsDTO(mentions []int) []int {
+	if mentions == nil {
+		return []int{}
+	}
+	return mentions
+}
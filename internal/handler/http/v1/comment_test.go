@@ -0,0 +1,245 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type commentSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockComment
+}
+
+func (s *commentSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockComment(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewCommentRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestCommentSuite(t *testing.T) {
+	suite.Run(t, new(commentSuite))
+}
+
+func (s *commentSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *commentSuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"body": "hi @jane@example.com"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(2, "hi @jane@example.com").Return(&entity.Comment{
+					ID:        1,
+					TodoID:    2,
+					AuthorID:  3,
+					Body:      "hi @jane@example.com",
+					Mentions:  []int{4},
+					CreatedAt: time.Unix(123456789, 0),
+					UpdatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 1, "todoId": 2, "authorId": 3, "body": "hi @jane@example.com", "mentions": [4], "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"}`,
+		},
+		{
+			desc:     "missing body",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'createCommentReq.Body' Error:Field validation for 'Body' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "empty body",
+			body: `{"body": " "}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(2, " ").Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrEmptyComment)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: comment must not be empty"}`,
+		},
+		{
+			desc: "todo not found",
+			body: `{"body": "hi"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(2, "hi").Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPost, "/v1/todos/2/comments", tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *commentSuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(2, entity.PageQuery{Cursor: "abc", Limit: 1}).Return(&entity.Page[entity.Comment]{
+			Items: []entity.Comment{{
+				ID:        1,
+				AuthorID:  3,
+				Body:      "hi",
+				Edits:     []entity.CommentEdit{{Body: "hello"}},
+				CreatedAt: time.Unix(123456789, 0),
+				UpdatedAt: time.Unix(123456789, 0),
+			}},
+			NextCursor: "def",
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments?cursor=abc&limit=1", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{
+			"items": [{"id": 1, "authorId": 3, "body": "hi", "mentions": [], "edited": true, "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"}],
+			"nextCursor": "def"
+		}`, w.Body.String())
+	})
+	s.Run("empty", func() {
+		s.mockSrv.EXPECT().List(2, entity.PageQuery{}).Return(&entity.Page[entity.Comment]{}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"items": []}`, w.Body.String())
+	})
+	s.Run("negative limit", func() {
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments?limit=-1", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("limit too large", func() {
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments?limit=101", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("invalid cursor", func() {
+		s.mockSrv.EXPECT().List(2, entity.PageQuery{Cursor: "!"}).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidCursor)).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments?cursor=!", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.JSONEq(`{"error": "invalid input: invalid cursor"}`, w.Body.String())
+	})
+}
+
+func (s *commentSuite) TestGet() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Get(2, 1).Return(&entity.Comment{
+			ID:        1,
+			TodoID:    2,
+			AuthorID:  3,
+			Body:      "hi",
+			Edits:     []entity.CommentEdit{{Body: "hello", EditedAt: time.Unix(123456789, 0)}},
+			CreatedAt: time.Unix(123456789, 0),
+			UpdatedAt: time.Unix(123456789, 0),
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments/1", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{
+			"id": 1, "todoId": 2, "authorId": 3, "body": "hi", "mentions": [],
+			"edits": [{"body": "hello", "editedAt": "1973-11-30T05:33:09+08:00"}],
+			"createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"
+		}`, w.Body.String())
+	})
+	s.Run("invalid comment id", func() {
+		w := s.serve(http.MethodGet, "/v1/todos/2/comments/abc", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
+
+func (s *commentSuite) TestUpdate() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Update(2, 1, "hello").Return(&entity.Comment{
+			ID:        1,
+			TodoID:    2,
+			AuthorID:  3,
+			Body:      "hello",
+			Edits:     []entity.CommentEdit{{Body: "hi"}},
+			CreatedAt: time.Unix(123456789, 0),
+			UpdatedAt: time.Unix(123456789, 0),
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2/comments/1", `{"body": "hello"}`)
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"id": 1, "todoId": 2, "authorId": 3, "body": "hello", "mentions": [], "edited": true, "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"}`, w.Body.String())
+	})
+	s.Run("not the author", func() {
+		s.mockSrv.EXPECT().Update(2, 1, "hello").Return(nil, fmt.Errorf("%w: only the author may edit a comment", service.ErrForbidden)).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2/comments/1", `{"body": "hello"}`)
+		s.Equal(http.StatusForbidden, w.Code)
+		s.JSONEq(`{"error": "forbidden: only the author may edit a comment"}`, w.Body.String())
+	})
+}
+
+func (s *commentSuite) TestDelete() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Delete(2, 1).Return(nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/2/comments/1", "")
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("forbidden", func() {
+		s.mockSrv.EXPECT().Delete(2, 1).Return(service.ErrForbidden).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/2/comments/1", "")
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+}
+
+func (s *commentSuite) TestActivity() {
+	s.Run("success", func() {
+		at := time.Unix(123456789, 0)
+		s.mockSrv.EXPECT().Activity(2, entity.PageQuery{}).Return(&entity.Page[entity.Activity]{
+			Items: []entity.Activity{
+				entity.ChangeActivity(entity.TodoChange{ID: 1, TodoID: 2, ActorID: 3, Fields: []entity.FieldChange{{Field: "title", From: "a", To: "b"}}, At: at}),
+				entity.CommentActivity(entity.Comment{ID: 1, TodoID: 2, AuthorID: 3, Body: "hi", CreatedAt: at, UpdatedAt: at}),
+			},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/activity", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"items": [
+			{"kind": "change", "at": "1973-11-30T05:33:09+08:00", "change": {"actorId": 3, "fields": [{"field": "title", "from": "a", "to": "b"}]}},
+			{"kind": "comment", "at": "1973-11-30T05:33:09+08:00", "comment": {"id": 1, "authorId": 3, "body": "hi", "mentions": [], "edited": false, "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"}}
+		]}`, w.Body.String())
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Activity(2, entity.PageQuery{}).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/activity", "")
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+)
+
+const (
+	dateLayout      = time.DateOnly
+	localTimeLayout = "2006-01-02T15:04:05"
+)
+
+// dateTimeDTO carries either an all-day date or a timed instant. A timed
+// value may omit its offset when a time zone is given, in which case it is
+// read as wall-clock time in that zone.
+type dateTimeDTO struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"dateTime,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+func (d *dateTimeDTO) UnmarshalJSON(b []byte) error {
+	type plain dateTimeDTO
+	if err := json.Unmarshal(b, (*plain)(d)); err != nil {
+		return err
+	}
+	_, err := d.entity()
+	return err
+}
+
+func (d *dateTimeDTO) entity() (entity.DateTime, error) {
+	if (d.Date == "") == (d.DateTime == "") {
+		return entity.DateTime{}, errors.New("exactly one of date and dateTime is required")
+	}
+	loc := time.UTC
+	if d.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(d.TimeZone); err != nil {
+			return entity.DateTime{}, fmt.Errorf("invalid time zone %q", d.TimeZone)
+		}
+	}
+
+	if d.Date != "" {
+		t, err := time.Parse(dateLayout, d.Date)
+		if err != nil {
+			return entity.DateTime{}, fmt.Errorf("invalid date %q", d.Date)
+		}
+		date := entity.NewDate(t.Date())
+		date.TimeZone = d.TimeZone
+		return date, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, d.DateTime)
+	if err != nil && d.TimeZone != "" {
+		t, err = time.ParseInLocation(localTimeLayout, d.DateTime, loc)
+	}
+	if err != nil {
+		return entity.DateTime{}, fmt.Errorf("invalid dateTime %q", d.DateTime)
+	}
+	return entity.NewDateTime(t, d.TimeZone), nil
+}
+
+// toDateTime converts an optional request value, which has already been
+// validated while decoding.
+func toDateTime(d *dateTimeDTO) *entity.DateTime {
+	if d == nil {
+		return nil
+	}
+	dt, _ := d.entity()
+	return &dt
+}
+
+func newDateTimeDTO(dt *entity.DateTime) *dateTimeDTO {
+	if dt == nil {
+		return nil
+	}
+	if dt.AllDay {
+		return &dateTimeDTO{Date: dt.Time.Format(dateLayout), TimeZone: dt.TimeZone}
+	}
+	t := dt.Time
+	if loc, err := time.LoadLocation(dt.TimeZone); dt.TimeZone != "" && err == nil {
+		t = t.In(loc)
+	}
+	return &dateTimeDTO{DateTime: t.Format(time.RFC3339), TimeZone: dt.TimeZone}
+}
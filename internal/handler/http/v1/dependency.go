@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func dependencyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type dependencyParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type addDependencyReq struct {
+	BlockerID int `json:"blockerId" binding:"required,min=1"`
+}
+
+type addDependencyResp struct {
+	TodoID    int       `json:"todoId"`
+	BlockerID int       `json:"blockerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// addDependency records that the todo cannot be completed before the
+// blocking todo.
+func (h *todoHandler) addDependency(c *gin.Context) {
+	var params dependencyParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req addDependencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dep, err := h.todos(c).AddDependency(params.ID, req.BlockerID)
+	if err != nil {
+		dependencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, addDependencyResp{
+		TodoID:    dep.TodoID,
+		BlockerID: dep.BlockerID,
+		CreatedAt: dep.CreatedAt,
+	})
+}
+
+type listDependencyResp struct {
+	TodoID    int       `json:"todoId"`
+	BlockerID int       `json:"blockerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (h *todoHandler) listDependencies(c *gin.Context) {
+	var params dependencyParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deps, err := h.todos(c).Dependencies(params.ID)
+	if err != nil {
+		dependencyError(c, err)
+		return
+	}
+
+	resp := make([]listDependencyResp, len(deps))
+	for i, dep := range deps {
+		resp[i] = listDependencyResp{
+			TodoID:    dep.TodoID,
+			BlockerID: dep.BlockerID,
+			CreatedAt: dep.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type removeDependencyParams struct {
+	ID        int `uri:"id" binding:"required,min=1"`
+	BlockerID int `uri:"blockerId" binding:"required,min=1"`
+}
+
+func (h *todoHandler) removeDependency(c *gin.Context) {
+	var params removeDependencyParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.todos(c).RemoveDependency(params.ID, params.BlockerID); err != nil {
+		dependencyError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
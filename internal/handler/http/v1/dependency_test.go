@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type dependencySuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTodo
+}
+
+func (s *dependencySuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewTodoRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestDependencySuite(t *testing.T) {
+	suite.Run(t, new(dependencySuite))
+}
+
+func (s *dependencySuite) serve(method, path, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *dependencySuite) TestAddDependency() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"blockerId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddDependency(2, 1).Return(&entity.Dependency{
+					TodoID:    2,
+					BlockerID: 1,
+					CreatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"todoId": 2, "blockerId": 1, "createdAt": "1973-11-30T05:33:09+08:00"}`,
+		},
+		{
+			desc:     "missing blocker",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'addDependencyReq.BlockerID' Error:Field validation for 'BlockerID' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "cycle",
+			body: `{"blockerId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddDependency(2, 1).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrDependencyCycle)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: dependency would create a cycle"}`,
+		},
+		{
+			desc: "exists",
+			body: `{"blockerId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddDependency(2, 1).Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrDependencyExists)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: dependency already exists"}`,
+		},
+		{
+			desc: "not found",
+			body: `{"blockerId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddDependency(2, 1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPost, "/v1/todos/2/dependencies", "application/json", tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *dependencySuite) TestListDependencies() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Dependencies(2).Return([]entity.Dependency{
+			{TodoID: 2, BlockerID: 1, CreatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/dependencies", "", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[{"todoId": 2, "blockerId": 1, "createdAt": "1973-11-30T05:33:09+08:00"}]`, w.Body.String())
+	})
+	s.Run("empty", func() {
+		s.mockSrv.EXPECT().Dependencies(2).Return(nil, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/dependencies", "", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[]`, w.Body.String())
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Dependencies(2).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/dependencies", "", "")
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *dependencySuite) TestRemoveDependency() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().RemoveDependency(2, 1).Return(nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/2/dependencies/1", "", "")
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("wrong blocker id", func() {
+		w := s.serve(http.MethodDelete, "/v1/todos/2/dependencies/0", "", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().RemoveDependency(2, 1).Return(service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/2/dependencies/1", "", "")
+		s.Equal(http.StatusNotFound, w.Code)
+		s.JSONEq(`{"error": "not found"}`, w.Body.String())
+	})
+}
+
+func (s *dependencySuite) TestCompleteBlocked() {
+	blocked := fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrBlocked)
+
+	s.Run("update", func() {
+		s.mockSrv.EXPECT().Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}).Return(blocked).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2", "application/json", `{"isCompleted": true}`)
+		s.Equal(http.StatusConflict, w.Code)
+		s.JSONEq(`{"error": "conflict: todo is blocked by open todos"}`, w.Body.String())
+	})
+	s.Run("update with force", func() {
+		s.mockSrv.EXPECT().Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true), Force: true}).Return(nil).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2?force=true", "application/json", `{"isCompleted": true}`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("merge patch", func() {
+		s.mockSrv.EXPECT().UpdateFunc(2, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			todo := entity.Todo{ID: 2, Blocked: true}
+			s.Require().NoError(fn(&todo))
+			s.True(todo.Blocked)
+			return blocked
+		}).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2", "application/merge-patch+json", `{"isCompleted": true}`)
+		s.Equal(http.StatusConflict, w.Code)
+	})
+	s.Run("merge patch with force", func() {
+		s.mockSrv.EXPECT().UpdateFunc(2, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			todo := entity.Todo{ID: 2, Blocked: true}
+			s.Require().NoError(fn(&todo))
+			s.False(todo.Blocked)
+			s.True(todo.IsCompleted)
+			return nil
+		}).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/2?force=true", "application/merge-patch+json", `{"isCompleted": true}`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("replace with force", func() {
+		s.mockSrv.EXPECT().Replace(2, entity.ReplaceTodoInput{Title: "title-2", IsCompleted: true, Force: true}).Return(&entity.Todo{ID: 2, Title: "title-2", IsCompleted: true}, nil).Times(1)
+
+		w := s.serve(http.MethodPut, "/v1/todos/2?force=true", "application/json", `{"title": "title-2", "description": "", "isCompleted": true}`)
+		s.Equal(http.StatusOK, w.Code)
+	})
+	s.Run("batch update", func() {
+		s.mockSrv.EXPECT().BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 2, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+		}, entity.BatchAtomic).Return(nil, &service.BatchError{Index: 0, Err: blocked}).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/todos:batchUpdate", "application/json", `{"items": [{"id": 2, "isCompleted": true}]}`)
+		s.Equal(http.StatusConflict, w.Code)
+		s.JSONEq(`{"error": "item 0: conflict: todo is blocked by open todos", "index": 0}`, w.Body.String())
+	})
+}
+
+func (s *dependencySuite) TestListTopological() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(entity.TodoQuery{Days: 7, Location: time.UTC, Order: entity.OrderTopological}).Return(nil, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos?order=topological", "", "")
+		s.Equal(http.StatusOK, w.Code)
+	})
+}
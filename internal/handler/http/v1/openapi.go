@@ -0,0 +1,1857 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/jsonpatch"
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/samber/lo"
+)
+
+type errorResp struct {
+	Error string `json:"error"`
+}
+
+func NewOpenAPI() *openapi.Document {
+	doc := openapi.New("Todo API", "1.0.0")
+	pingOperations(doc)
+	todoOperations(doc)
+	todoMoveOperations(doc)
+	todoSubtaskOperations(doc)
+	todoDependencyOperations(doc)
+	todoRecurrenceOperations(doc)
+	todoCommentOperations(doc)
+	todoAttachmentOperations(doc)
+	todoChecklistOperations(doc)
+	todoTimeOperations(doc)
+	todoBatchOperations(doc)
+	tagOperations(doc)
+	projectOperations(doc)
+	userOperations(doc)
+	ssoOperations(doc)
+	apiKeyOperations(doc)
+	workspaceOperations(doc)
+	tenantOperations(doc)
+	securedOperations(doc)
+	idempotentOperations(doc)
+	rateLimitedOperations(doc)
+	return doc
+}
+
+// public marks an operation that can be called without an access token.
+var public = []openapi.SecurityRequirement{{}}
+
+// scopedTags are the tags of the operations an API key needs a scope for.
+var scopedTags = []string{"todos", "tags", "projects"}
+
+// securedOperations requires a bearer token for every operation that is not
+// public and documents the responses Auth and RequireScope give. Operations
+// that document their own 403 keep it.
+func securedOperations(doc *openapi.Document) {
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"bearerAuth": {
+			Type:        "http",
+			Description: "Access token from POST /v1/sessions, or an API key from POST /v1/api-keys",
+			Scheme:      "bearer",
+		},
+	}
+	doc.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}}
+	for _, item := range doc.Paths {
+		for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil || op.Security != nil {
+				continue
+			}
+			op.Responses["401"] = errorResponse(doc, "Missing or invalid access token or API key")
+			if _, ok := op.Responses["403"]; !ok && lo.Some(op.Tags, scopedTags) {
+				op.Responses["403"] = errorResponse(doc, "API key lacks the scope the operation needs, the role of the user does not allow it, or it would exceed a quota of the user")
+			}
+		}
+	}
+}
+
+// tenantOperations documents the X-Workspace-ID header accepted by every
+// operation that runs inside a workspace along with the responses Workspace
+// can give.
+func tenantOperations(doc *openapi.Document) {
+	for _, item := range doc.Paths {
+		for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil || !lo.Some(op.Tags, scopedTags) {
+				continue
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:        "X-Workspace-ID",
+				In:          "header",
+				Description: "Workspace to run in, the personal workspace of the user when left out",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			})
+			if _, ok := op.Responses["400"]; !ok {
+				op.Responses["400"] = errorResponse(doc, "Invalid workspace ID")
+			}
+			if _, ok := op.Responses["404"]; !ok {
+				op.Responses["404"] = errorResponse(doc, "Workspace not found")
+			}
+		}
+	}
+}
+
+// idempotentOperations documents the Idempotency-Key header accepted by every
+// mutating operation along with the responses it can produce. Public
+// operations are left out, as keys are scoped to the logged in user.
+func idempotentOperations(doc *openapi.Document) {
+	for _, item := range doc.Paths {
+		for _, op := range []*openapi.Operation{item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil || op.Security != nil {
+				continue
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:        "Idempotency-Key",
+				In:          "header",
+				Description: "Retries with the same key replay the original response",
+				Schema:      &openapi.Schema{Type: "string", MaxLength: lo.ToPtr(255)},
+			})
+			if _, ok := op.Responses["409"]; !ok {
+				op.Responses["409"] = errorResponse(doc, "A request with the same idempotency key is in progress")
+			}
+			if _, ok := op.Responses["422"]; !ok {
+				op.Responses["422"] = errorResponse(doc, "Idempotency key reused with a different request")
+			}
+		}
+	}
+}
+
+// rateLimitedOperations documents the response RateLimit gives every
+// operation once the client used up its requests.
+func rateLimitedOperations(doc *openapi.Document) {
+	seconds := &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(0.0)}
+	for _, item := range doc.Paths {
+		for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+			if op == nil {
+				continue
+			}
+			resp := errorResponse(doc, "Too many requests")
+			resp.Headers = map[string]*openapi.Header{
+				"Retry-After":         {Description: "Seconds until the next request is allowed", Schema: seconds},
+				"RateLimit-Policy":    {Description: "Requests allowed per window, like 600;w=60", Schema: &openapi.Schema{Type: "string"}},
+				"RateLimit-Limit":     {Description: "Requests allowed per window", Schema: seconds},
+				"RateLimit-Remaining": {Description: "Requests left right now", Schema: seconds},
+				"RateLimit-Reset":     {Description: "Seconds until every request of the window is available again", Schema: seconds},
+			}
+			op.Responses["429"] = resp
+		}
+	}
+}
+
+func errorResponse(doc *openapi.Document, description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     openapi.JSON(doc.Ref("Error", errorResp{}, openapi.Output)),
+	}
+}
+
+func idParam(description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer"},
+	}
+}
+
+func forceParam() openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "force",
+		In:          "query",
+		Description: "Complete the todo even when it is blocked by open todos",
+		Schema:      &openapi.Schema{Type: "boolean"},
+	}
+}
+
+// todoQueryParams are the query parameters of every endpoint listing todos.
+func todoQueryParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		{
+			Name:        "tags",
+			In:          "query",
+			Description: `Tag expression such as "work and (urgent or not home)". "&", "|", "!" and "," (or) are accepted too.`,
+			Schema:      &openapi.Schema{Type: "string"},
+		},
+		{
+			Name:        "view",
+			In:          "query",
+			Description: "Only open todos that are overdue, due today or due in the next days",
+			Schema:      &openapi.Schema{Type: "string", Enum: []any{"overdue", "today", "upcoming"}},
+		},
+		{
+			Name:        "days",
+			In:          "query",
+			Description: "How many days the upcoming view covers, 7 by default",
+			Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0), Maximum: lo.ToPtr(365.0)},
+		},
+		{
+			Name:        "tz",
+			In:          "query",
+			Description: "IANA time zone the views are computed in, UTC by default",
+			Schema:      &openapi.Schema{Type: "string"},
+		},
+		{
+			Name:        "order",
+			In:          "query",
+			Description: "Sort by creation, manual rank, priority or due date. Due views sort by due date by default, everything else by creation.",
+			Schema:      &openapi.Schema{Type: "string", Enum: []any{"created", "manual", "priority", "due", "topological"}},
+		},
+	}
+}
+
+func todoMergePatchSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type:        "object",
+		Description: "RFC 7396 merge patch. A null value resets the field.",
+		Properties: map[string]*openapi.Schema{
+			"title":       {Type: []string{"string", "null"}},
+			"description": {Type: []string{"string", "null"}},
+			"isCompleted": {Type: []string{"boolean", "null"}},
+			"priority":    {Type: []string{"string", "null"}, Enum: []any{"none", "low", "medium", "high", "urgent", nil}},
+			"rollup":      {Type: []string{"string", "null"}, Enum: []any{"none", "auto", "cascade", "both", nil}},
+			"start":       openapi.SchemaOf((*dateTimeDTO)(nil), openapi.Input),
+			"due":         openapi.SchemaOf((*dateTimeDTO)(nil), openapi.Input),
+			"recurrence":  {Type: []string{"string", "null"}},
+		},
+		AdditionalProperties: lo.ToPtr(false),
+	}
+}
+
+func jsonPatchSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type:        "array",
+		Description: "RFC 6902 JSON Patch supporting the test, add, replace and remove operations.",
+		Items: &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"op":    {Type: "string", Enum: []any{"test", "add", "replace", "remove"}},
+				"path":  {Type: "string"},
+				"value": {},
+			},
+			Required: []string{"op", "path"},
+		},
+	}
+}
+
+func pingOperations(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/v1/ping", &openapi.Operation{
+		OperationID: "ping",
+		Summary:     "Check the API is reachable",
+		Tags:        []string{"ping"},
+		Security:    public,
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Pong",
+				Content: map[string]openapi.MediaType{
+					"text/plain": {Schema: &openapi.Schema{Type: "string", Enum: []any{"pong"}}},
+				},
+			},
+		},
+	})
+}
+
+func todoOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/v1/todos", &openapi.Operation{
+		OperationID: "createTodo",
+		Summary:     "Create a todo",
+		Tags:        []string{"todos"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateTodoRequest", createTodoReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateTodoResponse", createTodoResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos", &openapi.Operation{
+		OperationID: "listTodos",
+		Summary:     "List todos, optionally narrowed to a due date view",
+		Tags:        []string{"todos"},
+		Parameters: append([]openapi.Parameter{
+			{
+				Name:        "project",
+				In:          "query",
+				Description: "Only todos of the project, or of the inbox for 0",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(0.0)},
+			},
+		}, todoQueryParams()...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListTodoResponse", listTodoResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid query parameter or tag expression"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id", &openapi.Operation{
+		OperationID: "getTodo",
+		Summary:     "Get a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("GetTodoResponse", getTodoResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/v1/todos/:id", &openapi.Operation{
+		OperationID: "replaceTodo",
+		Summary:     "Replace a todo, optionally creating it at the given ID",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			{
+				Name:        "id",
+				In:          "path",
+				Description: "Todo ID",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0), Maximum: lo.ToPtr(float64(entity.MaxTodoID))},
+			},
+			{
+				Name:        "upsert",
+				In:          "query",
+				Description: "Create the todo when it does not exist",
+				Schema:      &openapi.Schema{Type: "boolean"},
+			},
+			forceParam(),
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("ReplaceTodoRequest", replaceTodoReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Replaced",
+				Content:     openapi.JSON(doc.Ref("ReplaceTodoResponse", replaceTodoResp{}, openapi.Output)),
+			},
+			"201": {
+				Description: "Created by upsert",
+				Content:     openapi.JSON(doc.Ref("ReplaceTodoResponse", replaceTodoResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body"),
+			"404": errorResponse(doc, "Todo not found"),
+			"409": errorResponse(doc, "The todo is blocked by open todos"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/todos/:id", &openapi.Operation{
+		OperationID: "updateTodo",
+		Summary:     "Partially update a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), forceParam()},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"application/json":       {Schema: doc.Ref("UpdateTodoRequest", updateTodoReq{}, openapi.Input)},
+				jsonpatch.MergePatchType: {Schema: doc.Define("TodoMergePatch", todoMergePatchSchema())},
+				jsonpatch.JSONPatchType:  {Schema: doc.Define("JSONPatch", jsonPatchSchema())},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Updated"},
+			"400": errorResponse(doc, "Invalid todo ID or request body"),
+			"404": errorResponse(doc, "Todo not found"),
+			"409": errorResponse(doc, "JSON Patch test operation failed or the todo is blocked by open todos"),
+			"422": errorResponse(doc, "Patch targets an unknown or read-only field, or produces an invalid todo"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id", &openapi.Operation{
+		OperationID: "deleteTodo",
+		Summary:     "Delete a todo",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			idParam("Todo ID"),
+			{
+				Name:        "subtasks",
+				In:          "query",
+				Description: "What happens to the subtasks of the todo: delete them too (default), hand them to its parent or block the deletion",
+				Schema:      &openapi.Schema{Type: "string", Enum: []any{"cascade", "promote", "block"}},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid todo ID or query parameter"),
+			"404": errorResponse(doc, "Todo not found"),
+			"409": errorResponse(doc, "The todo has subtasks and the mode is block"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoMoveOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/v1/todos/:id/move", &openapi.Operation{
+		OperationID: "moveTodo",
+		Summary:     "Move a todo directly before or after another one in the manual order",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			{
+				Name:        "id",
+				In:          "path",
+				Description: "Todo ID",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("MoveTodoRequest", moveTodoReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Moved",
+				Content:     openapi.JSON(doc.Ref("MoveTodoResponse", moveTodoResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID, request body or move target"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoSubtaskOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPut, "/v1/todos/:id/parent", &openapi.Operation{
+		OperationID: "setTodoParent",
+		Summary:     "Move a todo below another one, or to the top level with parentId 0",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("SetParentRequest", setParentReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Moved",
+				Content:     openapi.JSON(doc.Ref("SetParentResponse", setParentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body, unknown parent or a cycle"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+
+	// The schema generator cannot follow recursive types, so the children
+	// are added to the node schema by hand.
+	tree := openapi.SchemaOf(getTodoResp{}, openapi.Output)
+	ref := doc.Define("TodoTreeResponse", tree)
+	tree.Properties["children"] = &openapi.Schema{Type: "array", Items: ref}
+	tree.Required = append(tree.Required, "children")
+	slices.Sort(tree.Required)
+	doc.Add(http.MethodGet, "/v1/todos/:id/subtree", &openapi.Operation{
+		OperationID: "getTodoSubtree",
+		Summary:     "Get a todo with all of its subtasks nested below it",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(ref),
+			},
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoDependencyOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/v1/todos/:id/dependencies", &openapi.Operation{
+		OperationID: "addTodoDependency",
+		Summary:     "Block a todo until another one is completed",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("AddDependencyRequest", addDependencyReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("AddDependencyResponse", addDependencyResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body, unknown blocker or a cycle"),
+			"404": errorResponse(doc, "Todo not found"),
+			"409": errorResponse(doc, "Dependency already exists"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/dependencies", &openapi.Operation{
+		OperationID: "listTodoDependencies",
+		Summary:     "List the todos blocking a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListDependencyResponse", listDependencyResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/dependencies/:blockerId", &openapi.Operation{
+		OperationID: "removeTodoDependency",
+		Summary:     "Stop a todo from being blocked by another one",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			idParam("Todo ID"),
+			{
+				Name:        "blockerId",
+				In:          "path",
+				Description: "ID of the blocking todo",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Removed"},
+			"400": errorResponse(doc, "Invalid todo or blocker ID"),
+			"404": errorResponse(doc, "Dependency not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoBatchOperations(doc *openapi.Document) {
+	batchResponses := func(successDescription string) map[string]*openapi.Response {
+		return map[string]*openapi.Response{
+			"200": {
+				Description: successDescription + " (atomic mode)",
+				Content:     openapi.JSON(doc.Ref("BatchResponse", batchResp{}, openapi.Output)),
+			},
+			"207": {
+				Description: "Per item results (bestEffort mode)",
+				Content:     openapi.JSON(doc.Ref("BatchResponse", batchResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body or item"),
+			"404": {
+				Description: "A todo was not found (atomic mode)",
+				Content:     openapi.JSON(doc.Ref("BatchError", batchErrorResp{}, openapi.Output)),
+			},
+			"409": {
+				Description: "A todo is blocked by open todos (atomic mode)",
+				Content:     openapi.JSON(doc.Ref("BatchError", batchErrorResp{}, openapi.Output)),
+			},
+			"500": errorResponse(doc, "Internal error"),
+		}
+	}
+
+	doc.Add(http.MethodPost, "/v1/todos:batchCreate", &openapi.Operation{
+		OperationID: "batchCreateTodos",
+		Summary:     "Create up to 100 todos",
+		Tags:        []string{"todos"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("BatchCreateTodoRequest", batchCreateTodoReq{}, openapi.Input)),
+		},
+		Responses: batchResponses("Created"),
+	})
+	doc.Add(http.MethodPost, "/v1/todos:batchUpdate", &openapi.Operation{
+		OperationID: "batchUpdateTodos",
+		Summary:     "Partially update up to 100 todos",
+		Tags:        []string{"todos"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("BatchUpdateTodoRequest", batchUpdateTodoReq{}, openapi.Input)),
+		},
+		Responses: batchResponses("Updated"),
+	})
+	doc.Add(http.MethodPost, "/v1/todos:batchDelete", &openapi.Operation{
+		OperationID: "batchDeleteTodos",
+		Summary:     "Delete up to 100 todos",
+		Tags:        []string{"todos"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("BatchDeleteTodoRequest", batchDeleteTodoReq{}, openapi.Input)),
+		},
+		Responses: batchResponses("Deleted"),
+	})
+}
+
+func tagOperations(doc *openapi.Document) {
+	tagID := openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Tag ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+	todoID := openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Todo ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+
+	doc.Add(http.MethodPost, "/v1/tags", &openapi.Operation{
+		OperationID: "createTag",
+		Summary:     "Create a tag",
+		Tags:        []string{"tags"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateTagRequest", createTagReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateTagResponse", createTagResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body or tag name"),
+			"409": errorResponse(doc, "A tag with the name already exists"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/tags", &openapi.Operation{
+		OperationID: "listTags",
+		Summary:     "List tags by name with the number of todos carrying them",
+		Tags:        []string{"tags"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListTagResponse", listTagResp{}, openapi.Output),
+				}),
+			},
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/tags/:id", &openapi.Operation{
+		OperationID: "getTag",
+		Summary:     "Get a tag",
+		Tags:        []string{"tags"},
+		Parameters:  []openapi.Parameter{tagID},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("GetTagResponse", getTagResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid tag ID"),
+			"404": errorResponse(doc, "Tag not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/tags/:id", &openapi.Operation{
+		OperationID: "renameTag",
+		Summary:     "Rename a tag on every todo carrying it",
+		Tags:        []string{"tags"},
+		Parameters:  []openapi.Parameter{tagID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("RenameTagRequest", renameTagReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Renamed",
+				Content:     openapi.JSON(doc.Ref("RenameTagResponse", renameTagResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid tag ID, request body or tag name"),
+			"404": errorResponse(doc, "Tag not found"),
+			"409": errorResponse(doc, "Another tag already has the name"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/tags/:id", &openapi.Operation{
+		OperationID: "deleteTag",
+		Summary:     "Delete a tag and detach it from every todo",
+		Tags:        []string{"tags"},
+		Parameters:  []openapi.Parameter{tagID},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid tag ID"),
+			"404": errorResponse(doc, "Tag not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/tags/:id/merge", &openapi.Operation{
+		OperationID: "mergeTag",
+		Summary:     "Merge a tag into another one and delete it",
+		Tags:        []string{"tags"},
+		Parameters:  []openapi.Parameter{tagID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("MergeTagRequest", mergeTagReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "The tag merged into",
+				Content:     openapi.JSON(doc.Ref("MergeTagResponse", mergeTagResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid tag ID or request body, or a merge into itself"),
+			"404": errorResponse(doc, "Tag not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/todos/:id/tags", &openapi.Operation{
+		OperationID: "attachTags",
+		Summary:     "Attach tags to a todo by name, creating missing tags",
+		Tags:        []string{"tags"},
+		Parameters:  []openapi.Parameter{todoID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("AttachTagsRequest", attachTagsReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "The tags of the todo",
+				Content:     openapi.JSON(doc.Ref("TodoTagsResponse", todoTagsResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID, request body or tag name"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/tags/:tag", &openapi.Operation{
+		OperationID: "detachTag",
+		Summary:     "Detach a tag from a todo",
+		Tags:        []string{"tags"},
+		Parameters: []openapi.Parameter{
+			todoID,
+			{
+				Name:        "tag",
+				In:          "path",
+				Description: "Tag name",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Detached, or the todo did not carry the tag"},
+			"400": errorResponse(doc, "Invalid todo ID or tag name"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func projectOperations(doc *openapi.Document) {
+	projectID := openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Project ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+
+	doc.Add(http.MethodPost, "/v1/projects", &openapi.Operation{
+		OperationID: "createProject",
+		Summary:     "Create a project",
+		Tags:        []string{"projects"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateProjectRequest", createProjectReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateProjectResponse", createProjectResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/projects", &openapi.Operation{
+		OperationID: "listProjects",
+		Summary:     "List projects",
+		Tags:        []string{"projects"},
+		Parameters: []openapi.Parameter{
+			{
+				Name:        "archived",
+				In:          "query",
+				Description: "Include archived projects",
+				Schema:      &openapi.Schema{Type: "boolean"},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListProjectResponse", listProjectResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid query parameter"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/projects/:id", &openapi.Operation{
+		OperationID: "getProject",
+		Summary:     "Get a project",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("GetProjectResponse", getProjectResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid project ID"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/projects/:id", &openapi.Operation{
+		OperationID: "updateProject",
+		Summary:     "Update the name or description of a project",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("UpdateProjectRequest", updateProjectReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Updated",
+				Content:     openapi.JSON(doc.Ref("UpdateProjectResponse", updateProjectResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid project ID or request body"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/projects/:id", &openapi.Operation{
+		OperationID: "deleteProject",
+		Summary:     "Delete a project",
+		Tags:        []string{"projects"},
+		Parameters: []openapi.Parameter{
+			projectID,
+			{
+				Name:        "todos",
+				In:          "query",
+				Description: "What happens to the todos of the project: block the deletion (default), delete them too or move them to the inbox",
+				Schema:      &openapi.Schema{Type: "string", Enum: []any{"block", "cascade", "inbox"}},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid project ID or query parameter"),
+			"404": errorResponse(doc, "Project not found"),
+			"409": errorResponse(doc, "The project still has todos and the mode is block"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	for _, archived := range []bool{true, false} {
+		path, operationID, summary := "/v1/projects/:id/archive", "archiveProject", "Archive a project so no todos can be added to it"
+		if !archived {
+			path, operationID, summary = "/v1/projects/:id/unarchive", "unarchiveProject", "Restore an archived project"
+		}
+		doc.Add(http.MethodPost, path, &openapi.Operation{
+			OperationID: operationID,
+			Summary:     summary,
+			Tags:        []string{"projects"},
+			Parameters:  []openapi.Parameter{projectID},
+			Responses: map[string]*openapi.Response{
+				"200": {
+					Description: "OK",
+					Content:     openapi.JSON(doc.Ref("ArchiveProjectResponse", archiveProjectResp{}, openapi.Output)),
+				},
+				"400": errorResponse(doc, "Invalid project ID"),
+				"404": errorResponse(doc, "Project not found"),
+				"500": errorResponse(doc, "Internal error"),
+			},
+		})
+	}
+	doc.Add(http.MethodGet, "/v1/projects/:id/todos", &openapi.Operation{
+		OperationID: "listProjectTodos",
+		Summary:     "List the todos of a project",
+		Tags:        []string{"projects"},
+		Parameters:  append([]openapi.Parameter{projectID}, todoQueryParams()...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListTodoResponse", listTodoResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid project ID, query parameter or tag expression"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/projects/:id/todos", &openapi.Operation{
+		OperationID: "createProjectTodo",
+		Summary:     "Create a todo in a project",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateTodoRequest", createTodoReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateTodoResponse", createTodoResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid project ID or request body"),
+			"404": errorResponse(doc, "Project not found"),
+			"409": errorResponse(doc, "Project is archived"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/projects/:id/critical-path", &openapi.Operation{
+		OperationID: "getProjectCriticalPath",
+		Summary:     "Get the longest chain of open todos in a project that block one another",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("CriticalPathResponse", criticalPathResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid project ID"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/v1/todos/:id/project", &openapi.Operation{
+		OperationID: "moveTodoToProject",
+		Summary:     "Move a todo to another project or to the inbox",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("MoveTodoProjectRequest", moveTodoProjectReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Moved",
+				Content:     openapi.JSON(doc.Ref("MoveTodoProjectResponse", moveTodoProjectResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body"),
+			"404": errorResponse(doc, "Todo or project not found"),
+			"409": errorResponse(doc, "Project is archived"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/projects/:id/shares", &openapi.Operation{
+		OperationID: "shareProject",
+		Summary:     "Share a project with a registered user at a role, adding them to the workspace as a guest",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("ShareProjectRequest", shareProjectReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Role changed",
+				Content:     openapi.JSON(doc.Ref("ShareProjectResponse", shareProjectResp{}, openapi.Output)),
+			},
+			"201": {
+				Description: "Shared",
+				Content:     openapi.JSON(doc.Ref("ShareProjectResponse", shareProjectResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid project ID or request body"),
+			"403": errorResponse(doc, "Called with an API key instead of an access token, or the role of the user does not allow it"),
+			"404": errorResponse(doc, "Project or user not found"),
+			"409": errorResponse(doc, "The workspace is personal"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/projects/:id/shares", &openapi.Operation{
+		OperationID: "listProjectShares",
+		Summary:     "List the users a project is shared with",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{projectID},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListShareResponse", listShareResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid project ID"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/projects/:id/shares/:userId", &openapi.Operation{
+		OperationID: "unshareProject",
+		Summary:     "Stop sharing a project with a user",
+		Tags:        []string{"projects"},
+		Parameters: []openapi.Parameter{
+			projectID,
+			{
+				Name:        "userId",
+				In:          "path",
+				Description: "User ID",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Unshared"},
+			"400": errorResponse(doc, "Invalid project or user ID"),
+			"403": errorResponse(doc, "Called with an API key instead of an access token, or the role of the user does not allow it"),
+			"404": errorResponse(doc, "Project not found or not shared with the user"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoRecurrenceOperations(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/v1/todos/:id/occurrences", &openapi.Operation{
+		OperationID: "listTodoOccurrences",
+		Summary:     "Preview the next occurrences of a recurring todo",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			idParam("Todo ID"),
+			{
+				Name:        "count",
+				In:          "query",
+				Description: "How many occurrences to list, 5 by default",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0), Maximum: lo.ToPtr(100.0)},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("OccurrenceResponse", occurrenceResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or count"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+// pageQueryParams are the query parameters of every paginated endpoint.
+func pageQueryParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		{
+			Name:        "cursor",
+			In:          "query",
+			Description: "nextCursor of the previous page, to continue the list",
+			Schema:      &openapi.Schema{Type: "string"},
+		},
+		{
+			Name:        "limit",
+			In:          "query",
+			Description: "How many items to return, 20 by default",
+			Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0), Maximum: lo.ToPtr(100.0)},
+		},
+	}
+}
+
+func todoCommentOperations(doc *openapi.Document) {
+	commentIDParam := openapi.Parameter{
+		Name:        "commentId",
+		In:          "path",
+		Description: "Comment ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+	doc.Add(http.MethodPost, "/v1/todos/:id/comments", &openapi.Operation{
+		OperationID: "createTodoComment",
+		Summary:     "Comment on a todo, notifying the users mentioned as @email who may read it",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateCommentRequest", createCommentReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateCommentResponse", createCommentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body, or an empty or too long comment"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/comments", &openapi.Operation{
+		OperationID: "listTodoComments",
+		Summary:     "List the comments on a todo, oldest first",
+		Tags:        []string{"todos"},
+		Parameters:  append([]openapi.Parameter{idParam("Todo ID")}, pageQueryParams()...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("ListCommentResponse", listCommentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID, cursor or limit"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/comments/:commentId", &openapi.Operation{
+		OperationID: "getTodoComment",
+		Summary:     "Get a comment along with its edit history",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), commentIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("GetCommentResponse", getCommentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo or comment ID"),
+			"404": errorResponse(doc, "Todo or comment not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/todos/:id/comments/:commentId", &openapi.Operation{
+		OperationID: "updateTodoComment",
+		Summary:     "Edit a comment of your own, keeping the previous body in its edit history",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), commentIDParam},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("UpdateCommentRequest", updateCommentReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("UpdateCommentResponse", updateCommentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo or comment ID or request body, or an empty or too long comment"),
+			"404": errorResponse(doc, "Todo or comment not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/comments/:commentId", &openapi.Operation{
+		OperationID: "deleteTodoComment",
+		Summary:     "Delete a comment of your own, or any comment as an owner",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), commentIDParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid todo or comment ID"),
+			"404": errorResponse(doc, "Todo or comment not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/activity", &openapi.Operation{
+		OperationID: "listTodoActivity",
+		Summary:     "List the comments on a todo merged with the changes made to it, oldest first",
+		Tags:        []string{"todos"},
+		Parameters:  append([]openapi.Parameter{idParam("Todo ID")}, pageQueryParams()...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("ActivityResponse", activityResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID, cursor or limit"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoAttachmentOperations(doc *openapi.Document) {
+	attachmentIDParam := openapi.Parameter{
+		Name:        "attachmentId",
+		In:          "path",
+		Description: "Attachment ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+	doc.Add(http.MethodPost, "/v1/todos/:id/attachments", &openapi.Operation{
+		OperationID: "uploadTodoAttachment",
+		Summary:     "Attach a file to a todo; its content type is sniffed from the content",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"multipart/form-data": {Schema: doc.Define("UploadAttachmentRequest", &openapi.Schema{
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						attachmentField: {Type: "string", Format: "binary"},
+					},
+					Required: []string{attachmentField},
+				})},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("UploadAttachmentResponse", uploadAttachmentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or multipart form, or no file field"),
+			"404": errorResponse(doc, "Todo not found"),
+			"413": errorResponse(doc, "File too large"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/attachments", &openapi.Operation{
+		OperationID: "listTodoAttachments",
+		Summary:     "List the files attached to a todo, oldest first",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("ListAttachmentResponse", listAttachmentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/attachments/:attachmentId", &openapi.Operation{
+		OperationID: "getTodoAttachment",
+		Summary:     "Get what is known about an attached file",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), attachmentIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("GetAttachmentResponse", getAttachmentResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo or attachment ID"),
+			"404": errorResponse(doc, "Todo or attachment not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	content := map[string]openapi.MediaType{
+		"*/*": {Schema: &openapi.Schema{Type: "string", Format: "binary"}},
+	}
+	doc.Add(http.MethodGet, "/v1/todos/:id/attachments/:attachmentId/content", &openapi.Operation{
+		OperationID: "downloadTodoAttachment",
+		Summary:     "Download an attached file, or the byte ranges asked for with the Range header",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			idParam("Todo ID"),
+			attachmentIDParam,
+			{
+				Name:        "Range",
+				In:          "header",
+				Description: "Byte ranges to download, like bytes=0-1023",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The file", Content: content},
+			"206": {Description: "The byte ranges asked for", Content: content},
+			"304": {Description: "Not modified since the ETag in If-None-Match"},
+			"400": errorResponse(doc, "Invalid todo or attachment ID"),
+			"404": errorResponse(doc, "Todo or attachment not found"),
+			"416": {
+				Description: "Range not satisfiable",
+				Content: map[string]openapi.MediaType{
+					"text/plain": {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/attachments/:attachmentId", &openapi.Operation{
+		OperationID: "deleteTodoAttachment",
+		Summary:     "Delete a file you attached, or any file as an owner",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), attachmentIDParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid todo or attachment ID"),
+			"404": errorResponse(doc, "Todo or attachment not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoChecklistOperations(doc *openapi.Document) {
+	itemIDParam := openapi.Parameter{
+		Name:        "itemId",
+		In:          "path",
+		Description: "Checklist item ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+	checklist := func(description string) *openapi.Response {
+		return &openapi.Response{
+			Description: description,
+			Content:     openapi.JSON(doc.Ref("ChecklistResponse", checklistResp{}, openapi.Output)),
+		}
+	}
+	doc.Add(http.MethodGet, "/v1/todos/:id/checklist", &openapi.Operation{
+		OperationID: "getTodoChecklist",
+		Summary:     "Get the checklist of a todo along with its progress",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": checklist("OK"),
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/todos/:id/checklist", &openapi.Operation{
+		OperationID: "updateTodoChecklist",
+		Summary:     "Set whether the todo is completed once every item is checked and reopened when one is not",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("UpdateChecklistRequest", updateChecklistReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": checklist("OK"),
+			"400": errorResponse(doc, "Invalid todo ID or request body"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPut, "/v1/todos/:id/checklist/order", &openapi.Operation{
+		OperationID: "reorderTodoChecklist",
+		Summary:     "Reorder the checklist items, listing every item ID exactly once",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("ReorderChecklistRequest", reorderChecklistReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": checklist("OK"),
+			"400": errorResponse(doc, "Invalid todo ID or request body, or item IDs that are not every item exactly once"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/todos/:id/checklist/items", &openapi.Operation{
+		OperationID: "addTodoChecklistItem",
+		Summary:     "Append an unchecked item to the checklist of a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("AddChecklistItemRequest", addChecklistItemReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": checklist("Created"),
+			"400": errorResponse(doc, "Invalid todo ID or request body, an empty or too long item, or a full checklist"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/todos/:id/checklist/items/:itemId", &openapi.Operation{
+		OperationID: "updateTodoChecklistItem",
+		Summary:     "Edit the text of a checklist item or check it",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), itemIDParam},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("UpdateChecklistItemRequest", updateChecklistItemReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": checklist("OK"),
+			"400": errorResponse(doc, "Invalid todo or item ID or request body, or an empty or too long item"),
+			"404": errorResponse(doc, "Todo or item not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/checklist/items/:itemId", &openapi.Operation{
+		OperationID: "removeTodoChecklistItem",
+		Summary:     "Remove an item from the checklist of a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), itemIDParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid todo or item ID"),
+			"404": errorResponse(doc, "Todo or item not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func todoTimeOperations(doc *openapi.Document) {
+	entryIDParam := openapi.Parameter{
+		Name:        "entryId",
+		In:          "path",
+		Description: "Time entry ID",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+	}
+	entry := func(description string) *openapi.Response {
+		return &openapi.Response{
+			Description: description,
+			Content:     openapi.JSON(doc.Ref("TimeEntryResponse", timeEntryResp{}, openapi.Output)),
+		}
+	}
+	doc.Add(http.MethodGet, "/v1/timer", &openapi.Operation{
+		OperationID: "getRunningTimer",
+		Summary:     "Get the timer the caller has running, which may be in another workspace",
+		Tags:        []string{"todos"},
+		Responses: map[string]*openapi.Response{
+			"200": entry("OK"),
+			"404": errorResponse(doc, "No timer is running"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/timer/stop", &openapi.Operation{
+		OperationID: "stopTimer",
+		Summary:     "Stop the timer the caller has running",
+		Tags:        []string{"todos"},
+		Responses: map[string]*openapi.Response{
+			"200": entry("OK"),
+			"404": errorResponse(doc, "No timer is running"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/todos/:id/timer", &openapi.Operation{
+		OperationID: "startTimer",
+		Summary:     "Start a timer on a todo, stopping the one the caller had running",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Content: openapi.JSON(doc.Ref("StartTimerRequest", startTimerReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("StartTimerResponse", startTimerResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID or request body, or a too long note"),
+			"403": errorResponse(doc, "API key lacks the scope the operation needs, or not allowed to track time on the todo"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/todos/:id/time-entries", &openapi.Operation{
+		OperationID: "createTimeEntry",
+		Summary:     "Record time spent on a todo without a timer",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateTimeEntryRequest", createTimeEntryReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": entry("Created"),
+			"400": errorResponse(doc, "Invalid todo ID or request body, an entry ending before it starts or in the future, or a too long note"),
+			"403": errorResponse(doc, "API key lacks the scope the operation needs, or not allowed to track time on the todo"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/time-entries", &openapi.Operation{
+		OperationID: "listTimeEntries",
+		Summary:     "List the time entries of a todo by start",
+		Tags:        []string{"todos"},
+		Parameters:  append([]openapi.Parameter{idParam("Todo ID")}, pageQueryParams()...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("ListTimeEntryResponse", listTimeEntryResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID, cursor or limit"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/time-entries/:entryId", &openapi.Operation{
+		OperationID: "getTimeEntry",
+		Summary:     "Get a time entry of a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), entryIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": entry("OK"),
+			"400": errorResponse(doc, "Invalid todo or time entry ID"),
+			"404": errorResponse(doc, "Todo or time entry not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPatch, "/v1/todos/:id/time-entries/:entryId", &openapi.Operation{
+		OperationID: "updateTimeEntry",
+		Summary:     "Edit a time entry. Setting the end of a running timer stops it.",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), entryIDParam},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("UpdateTimeEntryRequest", updateTimeEntryReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": entry("OK"),
+			"400": errorResponse(doc, "Invalid todo or time entry ID or request body, an entry ending before it starts or in the future, or a too long note"),
+			"403": errorResponse(doc, "API key lacks the scope the operation needs, or only owners may edit the time entries of others"),
+			"404": errorResponse(doc, "Todo or time entry not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/todos/:id/time-entries/:entryId", &openapi.Operation{
+		OperationID: "removeTimeEntry",
+		Summary:     "Remove a time entry",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID"), entryIDParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"400": errorResponse(doc, "Invalid todo or time entry ID"),
+			"403": errorResponse(doc, "API key lacks the scope the operation needs, or only owners may remove the time entries of others"),
+			"404": errorResponse(doc, "Todo or time entry not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/todos/:id/time", &openapi.Operation{
+		OperationID: "getTodoTime",
+		Summary:     "Get the estimate and tracked time of a todo, on its own and with its subtasks",
+		Tags:        []string{"todos"},
+		Parameters:  []openapi.Parameter{idParam("Todo ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("TodoTimeResponse", todoTimeResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid todo ID"),
+			"404": errorResponse(doc, "Todo not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/projects/:id/time", &openapi.Operation{
+		OperationID: "getProjectTime",
+		Summary:     "Get the estimate and tracked time of the todos of a project",
+		Tags:        []string{"projects"},
+		Parameters:  []openapi.Parameter{idParam("Project ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("ProjectTimeResponse", projectTimeResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid project ID"),
+			"404": errorResponse(doc, "Project not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/time/report", &openapi.Operation{
+		OperationID: "getTimeReport",
+		Summary:     "Report the time tracked on visible todos by day or week",
+		Tags:        []string{"todos"},
+		Parameters: []openapi.Parameter{
+			{
+				Name:        "from",
+				In:          "query",
+				Description: "First day of the report",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string", Format: "date"},
+			},
+			{
+				Name:        "to",
+				In:          "query",
+				Description: "Last day of the report, at most 366 days after from",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string", Format: "date"},
+			},
+			{
+				Name:        "groupBy",
+				In:          "query",
+				Description: "Period of a row, day by default. Weeks start on Monday.",
+				Schema:      &openapi.Schema{Type: "string", Enum: []any{"day", "week"}},
+			},
+			{
+				Name:        "tz",
+				In:          "query",
+				Description: "IANA time zone days start in, UTC by default",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+			{
+				Name:        "project",
+				In:          "query",
+				Description: "Only time of the todos of the project, or of the inbox for 0",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(0.0)},
+			},
+			{
+				Name:        "todoId",
+				In:          "query",
+				Description: "Only time of the todo",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			},
+			{
+				Name:        "userId",
+				In:          "query",
+				Description: "Only time tracked by the user",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: lo.ToPtr(1.0)},
+			},
+			{
+				Name:        "format",
+				In:          "query",
+				Description: "Answer with JSON, the default, or CSV",
+				Schema:      &openapi.Schema{Type: "string", Enum: []any{"json", "csv"}},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: map[string]openapi.MediaType{
+					"application/json": {Schema: doc.Ref("TimeReportResponse", timeReportResp{}, openapi.Output)},
+					"text/csv":         {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"400": errorResponse(doc, "Invalid dates, time zone or filters, or a range of more than 366 days"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func userOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/v1/users", &openapi.Operation{
+		OperationID: "registerUser",
+		Summary:     "Sign up with an email and password",
+		Tags:        []string{"users"},
+		Security:    public,
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("RegisterUserRequest", registerUserReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("RegisterUserResponse", registerUserResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid email or password too short"),
+			"409": errorResponse(doc, "Email already registered"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/sessions", &openapi.Operation{
+		OperationID: "login",
+		Summary:     "Log in and get an access token",
+		Tags:        []string{"users"},
+		Security:    public,
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("LoginRequest", loginReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Logged in",
+				Content:     openapi.JSON(doc.Ref("LoginResponse", loginResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body"),
+			"401": errorResponse(doc, "Wrong email or password"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/sessions/current", &openapi.Operation{
+		OperationID: "logout",
+		Summary:     "Log out, revoking the access token the request is made with",
+		Tags:        []string{"users"},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Logged out"},
+			"403": errorResponse(doc, "Called with an API key instead of an access token"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/users/me", &openapi.Operation{
+		OperationID: "getCurrentUser",
+		Summary:     "Get the logged in user",
+		Tags:        []string{"users"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content:     openapi.JSON(doc.Ref("CurrentUserResponse", meResp{}, openapi.Output)),
+			},
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func ssoOperations(doc *openapi.Document) {
+	query := func(name, description string) openapi.Parameter {
+		return openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: "string"}}
+	}
+
+	doc.Add(http.MethodGet, "/v1/sso/login", &openapi.Operation{
+		OperationID: "ssoLogin",
+		Summary:     "Start logging in with the identity provider; open it in a browser",
+		Tags:        []string{"sso"},
+		Security:    public,
+		Responses: map[string]*openapi.Response{
+			"302": {Description: "Redirect to the identity provider"},
+			"500": errorResponse(doc, "Internal error or identity provider unreachable"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/sso/callback", &openapi.Operation{
+		OperationID: "ssoCallback",
+		Summary:     "Finish logging in with the identity provider, which redirects the browser here, and get an access token",
+		Tags:        []string{"sso"},
+		Security:    public,
+		Parameters: []openapi.Parameter{
+			query("code", "Authorization code from the identity provider"),
+			query("state", "State the login was started with"),
+			query("error", "Error code from the identity provider instead of a code"),
+			query("error_description", "Description of the error"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Logged in",
+				Content:     openapi.JSON(doc.Ref("SSOCallbackResponse", ssoCallbackResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Code or state missing"),
+			"401": errorResponse(doc, "Login refused, expired, started in another browser, or the identity provider did not vouch for the user"),
+			"409": errorResponse(doc, "The identity is linked to another user"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/sso/logout", &openapi.Operation{
+		OperationID: "ssoLogout",
+		Summary:     "Log out, revoking the access token, and get the URL that logs out at the identity provider too",
+		Tags:        []string{"sso"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Logged out",
+				Content:     openapi.JSON(doc.Ref("SSOLogoutResponse", ssoLogoutResp{}, openapi.Output)),
+			},
+			"403": errorResponse(doc, "Called with an API key instead of an access token"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func apiKeyOperations(doc *openapi.Document) {
+	sessionOnly := errorResponse(doc, "Called with an API key instead of an access token")
+
+	doc.Add(http.MethodPost, "/v1/api-keys", &openapi.Operation{
+		OperationID: "createAPIKey",
+		Summary:     "Create an API key with scopes like todos:read or todos:write; the key is only shown once",
+		Tags:        []string{"apiKeys"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateAPIKeyRequest", createAPIKeyReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateAPIKeyResponse", createAPIKeyResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body, unknown scope or expiry in the past"),
+			"403": sessionOnly,
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/api-keys", &openapi.Operation{
+		OperationID: "listAPIKeys",
+		Summary:     "List the API keys of the logged in user, revoked ones included",
+		Tags:        []string{"apiKeys"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListAPIKeyResponse", listAPIKeyResp{}, openapi.Output),
+				}),
+			},
+			"403": sessionOnly,
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodDelete, "/v1/api-keys/:id", &openapi.Operation{
+		OperationID: "revokeAPIKey",
+		Summary:     "Revoke an API key",
+		Tags:        []string{"apiKeys"},
+		Parameters:  []openapi.Parameter{idParam("API key ID")},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Revoked"},
+			"400": errorResponse(doc, "Invalid API key ID"),
+			"403": sessionOnly,
+			"404": errorResponse(doc, "API key not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
+
+func workspaceOperations(doc *openapi.Document) {
+	sessionOnly := errorResponse(doc, "Called with an API key instead of an access token")
+
+	doc.Add(http.MethodPost, "/v1/workspaces", &openapi.Operation{
+		OperationID: "createWorkspace",
+		Summary:     "Create a workspace owned by the logged in user",
+		Tags:        []string{"workspaces"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("CreateWorkspaceRequest", createWorkspaceReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Created",
+				Content:     openapi.JSON(doc.Ref("CreateWorkspaceResponse", createWorkspaceResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid request body"),
+			"403": sessionOnly,
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/workspaces", &openapi.Operation{
+		OperationID: "listWorkspaces",
+		Summary:     "List the workspaces of the logged in user, personal workspace first",
+		Tags:        []string{"workspaces"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListWorkspaceResponse", listWorkspaceResp{}, openapi.Output),
+				}),
+			},
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodPost, "/v1/workspaces/:id/members", &openapi.Operation{
+		OperationID: "addWorkspaceMember",
+		Summary:     "Add a registered user to a workspace by email, as an editor unless a role is given",
+		Tags:        []string{"workspaces"},
+		Parameters:  []openapi.Parameter{idParam("Workspace ID")},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.Ref("AddMemberRequest", addMemberReq{}, openapi.Input)),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {
+				Description: "Added",
+				Content:     openapi.JSON(doc.Ref("AddMemberResponse", addMemberResp{}, openapi.Output)),
+			},
+			"400": errorResponse(doc, "Invalid workspace ID or request body"),
+			"403": errorResponse(doc, "Called with an API key instead of an access token, or not an owner of the workspace"),
+			"404": errorResponse(doc, "Workspace or user not found"),
+			"409": errorResponse(doc, "Already a member, or the workspace is personal"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+	doc.Add(http.MethodGet, "/v1/workspaces/:id/members", &openapi.Operation{
+		OperationID: "listWorkspaceMembers",
+		Summary:     "List the members of a workspace",
+		Tags:        []string{"workspaces"},
+		Parameters:  []openapi.Parameter{idParam("Workspace ID")},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "OK",
+				Content: openapi.JSON(&openapi.Schema{
+					Type:  "array",
+					Items: doc.Ref("ListMemberResponse", listMemberResp{}, openapi.Output),
+				}),
+			},
+			"400": errorResponse(doc, "Invalid workspace ID"),
+			"403": errorResponse(doc, "Guests cannot list the members"),
+			"404": errorResponse(doc, "Workspace not found"),
+			"500": errorResponse(doc, "Internal error"),
+		},
+	})
+}
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/openapi"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type openAPISuite struct {
+	suite.Suite
+	doc *openapi.Document
+}
+
+func (s *openAPISuite) SetupTest() {
+	s.doc = NewOpenAPI()
+}
+
+func TestOpenAPISuite(t *testing.T) {
+	suite.Run(t, new(openAPISuite))
+}
+
+func (s *openAPISuite) TestResponseSchemasMatchHandlerTypes() {
+	tests := []struct {
+		desc   string
+		schema string
+		value  any
+	}{
+		{desc: "create", schema: "CreateTodoResponse", value: createTodoResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "list", schema: "ListTodoResponse", value: listTodoResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Checklist: "1/2", Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "get", schema: "GetTodoResponse", value: getTodoResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Checklist: "1/2", ChecklistItems: []checklistItemDTO{{ID: 1, Text: "a", Checked: true}}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "replace", schema: "ReplaceTodoResponse", value: replaceTodoResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "move", schema: "MoveTodoResponse", value: moveTodoResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "set parent", schema: "SetParentResponse", value: setParentResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "subtree", schema: "TodoTreeResponse", value: todoTreeResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Checklist: "1/2", ChecklistItems: []checklistItemDTO{{ID: 1, Text: "a", Checked: true}}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "add dependency", schema: "AddDependencyResponse", value: addDependencyResp{}},
+		{desc: "list dependencies", schema: "ListDependencyResponse", value: listDependencyResp{}},
+		{desc: "occurrences", schema: "OccurrenceResponse", value: occurrenceResp{Start: &dateTimeDTO{}, Due: &dateTimeDTO{}}},
+		{desc: "create tag", schema: "CreateTagResponse", value: createTagResp{}},
+		{desc: "list tags", schema: "ListTagResponse", value: listTagResp{}},
+		{desc: "get tag", schema: "GetTagResponse", value: getTagResp{}},
+		{desc: "rename tag", schema: "RenameTagResponse", value: renameTagResp{}},
+		{desc: "merge tag", schema: "MergeTagResponse", value: mergeTagResp{}},
+		{desc: "todo tags", schema: "TodoTagsResponse", value: todoTagsResp{}},
+		{desc: "checklist", schema: "ChecklistResponse", value: checklistResp{}},
+		{desc: "time entry", schema: "TimeEntryResponse", value: timeEntryResp{End: &time.Time{}, Note: "review"}},
+		{desc: "start timer", schema: "StartTimerResponse", value: startTimerResp{Stopped: &timeEntryResp{}}},
+		{desc: "list time entries", schema: "ListTimeEntryResponse", value: listTimeEntryResp{NextCursor: "x"}},
+		{desc: "todo time", schema: "TodoTimeResponse", value: todoTimeResp{}},
+		{desc: "project time", schema: "ProjectTimeResponse", value: projectTimeResp{}},
+		{desc: "time report", schema: "TimeReportResponse", value: timeReportResp{}},
+		{desc: "move to project", schema: "MoveTodoProjectResponse", value: moveTodoProjectResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "critical path", schema: "CriticalPathResponse", value: criticalPathResp{Priority: entity.PriorityNone, ProjectID: 1, ParentID: 1, Blocked: true, Rollup: entity.RollupNone, Rank: "i", Tags: []string{"work"}, Start: &dateTimeDTO{}, Due: &dateTimeDTO{}, Recurrence: "FREQ=DAILY", Estimate: 3600}},
+		{desc: "create project", schema: "CreateProjectResponse", value: createProjectResp{ArchivedAt: &time.Time{}}},
+		{desc: "list projects", schema: "ListProjectResponse", value: listProjectResp{ArchivedAt: &time.Time{}}},
+		{desc: "get project", schema: "GetProjectResponse", value: getProjectResp{ArchivedAt: &time.Time{}}},
+		{desc: "update project", schema: "UpdateProjectResponse", value: updateProjectResp{ArchivedAt: &time.Time{}}},
+		{desc: "archive project", schema: "ArchiveProjectResponse", value: archiveProjectResp{ArchivedAt: &time.Time{}}},
+		{desc: "share project", schema: "ShareProjectResponse", value: shareProjectResp{}},
+		{desc: "list shares", schema: "ListShareResponse", value: listShareResp{}},
+		{desc: "register user", schema: "RegisterUserResponse", value: registerUserResp{}},
+		{desc: "login", schema: "LoginResponse", value: loginResp{}},
+		{desc: "sso callback", schema: "SSOCallbackResponse", value: ssoCallbackResp{}},
+		{desc: "sso logout", schema: "SSOLogoutResponse", value: ssoLogoutResp{LogoutURL: "https://idp.example.com/logout"}},
+		{desc: "current user", schema: "CurrentUserResponse", value: meResp{}},
+		{desc: "create api key", schema: "CreateAPIKeyResponse", value: createAPIKeyResp{ExpiresAt: &time.Time{}}},
+		{desc: "list api keys", schema: "ListAPIKeyResponse", value: listAPIKeyResp{ExpiresAt: &time.Time{}, LastUsedAt: &time.Time{}, RevokedAt: &time.Time{}}},
+		{desc: "create workspace", schema: "CreateWorkspaceResponse", value: createWorkspaceResp{}},
+		{desc: "list workspaces", schema: "ListWorkspaceResponse", value: listWorkspaceResp{}},
+		{desc: "add member", schema: "AddMemberResponse", value: addMemberResp{}},
+		{desc: "list members", schema: "ListMemberResponse", value: listMemberResp{}},
+		{desc: "create comment", schema: "CreateCommentResponse", value: createCommentResp{}},
+		{desc: "list comments", schema: "ListCommentResponse", value: listCommentResp{NextCursor: "x"}},
+		{desc: "get comment", schema: "GetCommentResponse", value: getCommentResp{}},
+		{desc: "update comment", schema: "UpdateCommentResponse", value: updateCommentResp{}},
+		{desc: "activity", schema: "ActivityResponse", value: activityResp{NextCursor: "x"}},
+		{desc: "upload attachment", schema: "UploadAttachmentResponse", value: uploadAttachmentResp{}},
+		{desc: "list attachments", schema: "ListAttachmentResponse", value: listAttachmentResp{}},
+		{desc: "get attachment", schema: "GetAttachmentResponse", value: getAttachmentResp{}},
+		{desc: "error", schema: "Error", value: errorResp{}},
+		{desc: "batch error", schema: "BatchError", value: batchErrorResp{}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			b, err := json.Marshal(tt.value)
+			s.Require().NoError(err)
+			var fields map[string]any
+			s.Require().NoError(json.Unmarshal(b, &fields))
+
+			schema := s.doc.Components.Schemas[tt.schema]
+			s.Require().NotNil(schema)
+			for name := range fields {
+				s.Contains(schema.Properties, name)
+			}
+			for name := range schema.Properties {
+				s.Contains(fields, name)
+			}
+		})
+	}
+}
+
+func (s *openAPISuite) TestSecurity() {
+	s.Run("public operations", func() {
+		for _, path := range []string{"/v1/ping", "/v1/users", "/v1/sessions", "/v1/sso/login", "/v1/sso/callback"} {
+			item := s.doc.Paths[path]
+			op := cmp.Or(item.Get, item.Post)
+			s.Equal([]openapi.SecurityRequirement{{}}, op.Security, path)
+			s.False(lo.ContainsBy(op.Parameters, func(p openapi.Parameter) bool { return p.Name == "Idempotency-Key" }), path)
+		}
+	})
+	s.Run("everything else needs a token", func() {
+		s.Equal([]openapi.SecurityRequirement{{"bearerAuth": {}}}, s.doc.Security)
+		s.Contains(s.doc.Components.SecuritySchemes, "bearerAuth")
+		for path, item := range s.doc.Paths {
+			for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+				if op != nil && op.Security == nil {
+					s.Contains(op.Responses, "401", path)
+				}
+			}
+		}
+	})
+	s.Run("scoped operations can be forbidden", func() {
+		for path, item := range s.doc.Paths {
+			for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+				if op != nil && op.Security == nil && !slices.Contains(op.Tags, "users") && !slices.Contains(op.Tags, "ping") && !slices.Contains(op.Tags, "workspaces") {
+					s.Contains(op.Responses, "403", path)
+				}
+			}
+		}
+	})
+	s.Run("changing workspaces needs a session", func() {
+		for path, item := range s.doc.Paths {
+			if op := item.Post; op != nil && slices.Contains(op.Tags, "workspaces") {
+				s.Contains(op.Responses, "403", path)
+			}
+		}
+	})
+}
+
+func (s *openAPISuite) TestTenantOperations() {
+	s.Run("scoped operations take a workspace", func() {
+		for path, item := range s.doc.Paths {
+			for _, op := range []*openapi.Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete} {
+				if op == nil {
+					continue
+				}
+				hasHeader := lo.ContainsBy(op.Parameters, func(p openapi.Parameter) bool { return p.Name == "X-Workspace-ID" })
+				if !lo.Some(op.Tags, scopedTags) {
+					s.False(hasHeader, path)
+					continue
+				}
+				s.True(hasHeader, path)
+				s.Contains(op.Responses, "400", path)
+				s.Contains(op.Responses, "404", path)
+			}
+		}
+	})
+}
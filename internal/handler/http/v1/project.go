@@ -0,0 +1,529 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type projectHandler struct {
+	srv service.Project
+}
+
+func NewProjectRoutes(rg *gin.RouterGroup, srv service.Project) {
+	h := &projectHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeProjectsRead)
+	write := middleware.RequireScope(entity.ScopeProjectsWrite)
+	// Deleting a project can delete or move its todos.
+	remove := middleware.RequireScope(entity.ScopeProjectsWrite, entity.ScopeTodosWrite)
+	// Sharing adds users to the workspace, which API keys may not do.
+	session := middleware.RequireSession()
+	readTodos := middleware.RequireScope(entity.ScopeProjectsRead, entity.ScopeTodosRead)
+	writeTodos := middleware.RequireScope(entity.ScopeProjectsRead, entity.ScopeTodosWrite)
+	rg.POST("/projects", write, h.create)
+	rg.GET("/projects", read, h.list)
+	rg.GET("/projects/:id", read, h.get)
+	rg.PATCH("/projects/:id", write, h.update)
+	rg.DELETE("/projects/:id", remove, h.remove)
+	rg.POST("/projects/:id/archive", write, h.archive)
+	rg.POST("/projects/:id/unarchive", write, h.unarchive)
+	rg.GET("/projects/:id/todos", readTodos, h.listTodos)
+	rg.POST("/projects/:id/todos", writeTodos, h.createTodo)
+	rg.GET("/projects/:id/critical-path", readTodos, h.criticalPath)
+	rg.PUT("/todos/:id/project", writeTodos, h.moveTodo)
+	rg.POST("/projects/:id/shares", session, write, h.share)
+	rg.GET("/projects/:id/shares", read, h.listShares)
+	rg.DELETE("/projects/:id/shares/:userId", session, write, h.unshare)
+}
+
+// projects returns the project service as seen by the caller.
+func (h *projectHandler) projects(c *gin.Context) service.Project {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// projectError writes the status for errors shared by every project
+// endpoint.
+func projectError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type projectParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type createProjectReq struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type createProjectResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (h *projectHandler) create(c *gin.Context) {
+	var req createProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.projects(c).Create(entity.CreateProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createProjectResp{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	})
+}
+
+type listProjectParams struct {
+	Archived bool `form:"archived"`
+}
+
+type listProjectResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (h *projectHandler) list(c *gin.Context) {
+	var params listProjectParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projects, err := h.projects(c).List(params.Archived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]listProjectResp, len(projects))
+	for i, project := range projects {
+		resp[i] = listProjectResp{
+			ID:          project.ID,
+			Name:        project.Name,
+			Description: project.Description,
+			Archived:    project.Archived(),
+			ArchivedAt:  project.ArchivedAt,
+			CreatedAt:   project.CreatedAt,
+			UpdatedAt:   project.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type getProjectResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (h *projectHandler) get(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.projects(c).Get(params.ID)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getProjectResp{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	})
+}
+
+type updateProjectReq struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description"`
+}
+
+type updateProjectResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (h *projectHandler) update(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req updateProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.projects(c).Update(params.ID, entity.UpdateProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updateProjectResp{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	})
+}
+
+type removeProjectParams struct {
+	Todos entity.ProjectDeleteMode `form:"todos" binding:"omitempty,oneof=block cascade inbox"`
+}
+
+func (h *projectHandler) remove(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var query removeProjectParams
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mode := query.Todos
+	if mode == "" {
+		mode = entity.ProjectDeleteBlock
+	}
+	if err := h.projects(c).Delete(params.ID, mode); err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type archiveProjectResp struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (h *projectHandler) archive(c *gin.Context) {
+	h.setArchived(c, h.projects(c).Archive)
+}
+
+func (h *projectHandler) unarchive(c *gin.Context) {
+	h.setArchived(c, h.projects(c).Unarchive)
+}
+
+func (h *projectHandler) setArchived(c *gin.Context, set func(id int) (*entity.Project, error)) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := set(params.ID)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, archiveProjectResp{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	})
+}
+
+// listTodos serves the todos of one project with the same query parameters
+// as GET /todos, except project.
+func (h *projectHandler) listTodos(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var listParams listTodoParams
+	if err := c.ShouldBindQuery(&listParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := listParams.query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.projects(c).ListTodos(params.ID, query)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	resp := make([]listTodoResp, len(todos))
+	for i, todo := range todos {
+		resp[i] = listTodoResp{
+			ID:          todo.ID,
+			Title:       todo.Title,
+			Description: todo.Description,
+			IsCompleted: todo.IsCompleted,
+			Blocked:     todo.Blocked,
+			Priority:    todo.Priority,
+			ProjectID:   todo.ProjectID,
+			ParentID:    todo.ParentID,
+			Rollup:      todo.Rollup,
+			Rank:        todo.Rank,
+			Tags:        todo.Tags,
+			Checklist:   todo.Checklist.String(),
+			Start:       newDateTimeDTO(todo.Start),
+			Due:         newDateTimeDTO(todo.Due),
+			Recurrence:  newRecurrenceDTO(todo.Recurrence),
+			Estimate:    seconds(todo.Estimate),
+			CreatedAt:   todo.CreatedAt,
+			UpdatedAt:   todo.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type criticalPathResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// criticalPath serves the longest chain of open todos in the project that
+// block one another, first blocker first.
+func (h *projectHandler) criticalPath(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.projects(c).CriticalPath(params.ID)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	resp := make([]criticalPathResp, len(todos))
+	for i, todo := range todos {
+		resp[i] = criticalPathResp{
+			ID:          todo.ID,
+			Title:       todo.Title,
+			Description: todo.Description,
+			IsCompleted: todo.IsCompleted,
+			Blocked:     todo.Blocked,
+			Priority:    todo.Priority,
+			ProjectID:   todo.ProjectID,
+			ParentID:    todo.ParentID,
+			Rollup:      todo.Rollup,
+			Rank:        todo.Rank,
+			Tags:        todo.Tags,
+			Start:       newDateTimeDTO(todo.Start),
+			Due:         newDateTimeDTO(todo.Due),
+			Recurrence:  newRecurrenceDTO(todo.Recurrence),
+			Estimate:    seconds(todo.Estimate),
+			CreatedAt:   todo.CreatedAt,
+			UpdatedAt:   todo.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *projectHandler) createTodo(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req createTodoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.projects(c).CreateTodo(params.ID, entity.CreateTodoInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		ParentID:    req.ParentID,
+		Rollup:      req.Rollup,
+		Start:       toDateTime(req.Start),
+		Due:         toDateTime(req.Due),
+		Recurrence:  req.Recurrence.rule(),
+		Estimate:    estimate(req.Estimate),
+	})
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createTodoResp{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	})
+}
+
+type moveTodoProjectReq struct {
+	ProjectID *int `json:"projectId" binding:"required,min=0"`
+}
+
+type moveTodoProjectResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// moveTodo moves a todo into a project, or into the inbox for projectId 0.
+func (h *projectHandler) moveTodo(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req moveTodoProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.projects(c).MoveTodo(params.ID, *req.ProjectID)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moveTodoProjectResp{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	})
+}
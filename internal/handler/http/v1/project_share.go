@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type shareProjectReq struct {
+	Email string      `json:"email" binding:"required,max=254"`
+	Role  entity.Role `json:"role" binding:"required,oneof=viewer editor owner"`
+}
+
+type shareProjectResp struct {
+	ID    int         `json:"id"`
+	Email string      `json:"email"`
+	Name  string      `json:"name"`
+	Role  entity.Role `json:"role"`
+}
+
+// share shares a project with a user, who joins the workspace as a guest
+// when they are not a member yet. Sharing again changes the role.
+func (h *projectHandler) share(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req shareProjectReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, created, err := h.projects(c).Share(params.ID, req.Email, req.Role)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	c.JSON(code, shareProjectResp{
+		ID:    member.User.ID,
+		Email: member.User.Email,
+		Name:  member.User.Name,
+		Role:  member.Role,
+	})
+}
+
+type listShareResp struct {
+	ID    int         `json:"id"`
+	Email string      `json:"email"`
+	Name  string      `json:"name"`
+	Role  entity.Role `json:"role"`
+}
+
+func (h *projectHandler) listShares(c *gin.Context) {
+	var params projectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := h.projects(c).Shares(params.ID)
+	if err != nil {
+		projectError(c, err)
+		return
+	}
+
+	resp := make([]listShareResp, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, listShareResp{
+			ID:    member.User.ID,
+			Email: member.User.Email,
+			Name:  member.User.Name,
+			Role:  member.Role,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type unshareProjectParams struct {
+	ID     int `uri:"id" binding:"required,min=1"`
+	UserID int `uri:"userId" binding:"required,min=1"`
+}
+
+func (h *projectHandler) unshare(c *gin.Context) {
+	var params unshareProjectParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.projects(c).Unshare(params.ID, params.UserID); err != nil {
+		projectError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type projectShareSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockProject
+}
+
+func (s *projectShareSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockProject(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewProjectRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestProjectShareSuite(t *testing.T) {
+	suite.Run(t, new(projectShareSuite))
+}
+
+func (s *projectShareSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *projectShareSuite) TestShare() {
+	bob := &entity.Member{User: entity.User{ID: 5, Email: "bob@example.com", Name: "Bob"}, Role: entity.RoleEditor}
+
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "new share",
+			body: `{"email": "bob@example.com", "role": "editor"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Share(1, "bob@example.com", entity.RoleEditor).Return(bob, true, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 5, "email": "bob@example.com", "name": "Bob", "role": "editor"}`,
+		},
+		{
+			desc: "role changed",
+			body: `{"email": "bob@example.com", "role": "editor"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Share(1, "bob@example.com", entity.RoleEditor).Return(bob, false, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"id": 5, "email": "bob@example.com", "name": "Bob", "role": "editor"}`,
+		},
+		{
+			desc:     "guest role",
+			body:     `{"email": "bob@example.com", "role": "guest"}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'shareProjectReq.Role' Error:Field validation for 'Role' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc: "not an owner",
+			body: `{"email": "bob@example.com", "role": "viewer"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Share(1, "bob@example.com", entity.RoleViewer).Return(nil, false, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: `{"error": "forbidden"}`,
+		},
+		{
+			desc: "personal workspace",
+			body: `{"email": "bob@example.com", "role": "viewer"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Share(1, "bob@example.com", entity.RoleViewer).Return(nil, false, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrPersonalWorkspace)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: personal workspaces cannot have other members"}`,
+		},
+		{
+			desc: "unknown user",
+			body: `{"email": "bob@example.com", "role": "viewer"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Share(1, "bob@example.com", entity.RoleViewer).Return(nil, false, fmt.Errorf("%w: no user has the email", service.ErrNotFound)).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found: no user has the email"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+			w := s.serve(http.MethodPost, "/v1/projects/1/shares", tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *projectShareSuite) TestListShares() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Shares(1).Return([]entity.Member{
+			{User: entity.User{ID: 5, Email: "bob@example.com", Name: "Bob"}, Role: entity.RoleViewer},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/projects/1/shares", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[{"id": 5, "email": "bob@example.com", "name": "Bob", "role": "viewer"}]`, w.Body.String())
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Shares(1).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/projects/1/shares", "")
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *projectShareSuite) TestUnshare() {
+	tests := []struct {
+		desc     string
+		path     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			path: "/v1/projects/1/shares/5",
+			mock: func() {
+				s.mockSrv.EXPECT().Unshare(1, 5).Return(nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:     "invalid user id",
+			path:     "/v1/projects/1/shares/abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "not shared",
+			path: "/v1/projects/1/shares/5",
+			mock: func() {
+				s.mockSrv.EXPECT().Unshare(1, 5).Return(service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			desc: "not an owner",
+			path: "/v1/projects/1/shares/5",
+			mock: func() {
+				s.mockSrv.EXPECT().Unshare(1, 5).Return(service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+			w := s.serve(http.MethodDelete, tt.path, "")
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
+
+func (s *projectShareSuite) TestAPIKey() {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodPost, path: "/v1/projects/1/shares", body: `{"email": "bob@example.com", "role": "editor"}`},
+		{method: http.MethodDelete, path: "/v1/projects/1/shares/5"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.method+" with an api key", func() {
+			ctrl := gomock.NewController(s.T())
+			apiKeys := mocks.NewMockAPIKey(ctrl)
+			apiKeys.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(&entity.User{ID: 1}, &entity.APIKey{ID: 1, Scopes: entity.Scopes}, nil).Times(1)
+			router := gin.New()
+			NewProjectRoutes(router.Group("v1", middleware.Auth(mocks.NewMockUser(ctrl), apiKeys)), s.mockSrv)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer tdk_AAAAAAAA_SECRET")
+			router.ServeHTTP(w, req)
+
+			s.Equal(http.StatusForbidden, w.Code)
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type projectSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockProject
+}
+
+func (s *projectSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockProject(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewProjectRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestProjectSuite(t *testing.T) {
+	suite.Run(t, new(projectSuite))
+}
+
+func (s *projectSuite) TestProjects() {
+	archivedAt := time.Unix(123456790, 0)
+	project := &entity.Project{
+		ID:          1,
+		Name:        "home",
+		Description: "chores",
+		CreatedAt:   time.Unix(123456789, 0),
+		UpdatedAt:   time.Unix(123456789, 0),
+	}
+	projectResp := `{
+	  "id": 1,
+	  "name": "home",
+	  "description": "chores",
+	  "archived": false,
+	  "createdAt": "1973-11-30T05:33:09+08:00",
+	  "updatedAt": "1973-11-30T05:33:09+08:00"
+	}`
+
+	tests := []struct {
+		desc     string
+		method   string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "create",
+			method: http.MethodPost,
+			path:   "/v1/projects",
+			body:   `{"name": "home", "description": "chores"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(entity.CreateProjectInput{Name: "home", Description: "chores"}).Return(project, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: projectResp,
+		},
+		{
+			desc:     "create without name",
+			method:   http.MethodPost,
+			path:     "/v1/projects",
+			body:     `{"description": "chores"}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'createProjectReq.Name' Error:Field validation for 'Name' failed on the 'required' tag"}`,
+		},
+		{
+			desc:   "list",
+			method: http.MethodGet,
+			path:   "/v1/projects",
+			mock: func() {
+				s.mockSrv.EXPECT().List(false).Return([]entity.Project{*project}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `[` + projectResp + `]`,
+		},
+		{
+			desc:   "list with archived",
+			method: http.MethodGet,
+			path:   "/v1/projects?archived=true",
+			mock: func() {
+				s.mockSrv.EXPECT().List(true).Return(nil, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `[]`,
+		},
+		{
+			desc:   "get",
+			method: http.MethodGet,
+			path:   "/v1/projects/1",
+			mock: func() {
+				s.mockSrv.EXPECT().Get(1).Return(project, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: projectResp,
+		},
+		{
+			desc:   "get not found",
+			method: http.MethodGet,
+			path:   "/v1/projects/1",
+			mock: func() {
+				s.mockSrv.EXPECT().Get(1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc:   "archive forbidden",
+			method: http.MethodPost,
+			path:   "/v1/projects/1/archive",
+			mock: func() {
+				s.mockSrv.EXPECT().Archive(1).Return(nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: `{"error": "forbidden"}`,
+		},
+		{
+			desc:   "update",
+			method: http.MethodPatch,
+			path:   "/v1/projects/1",
+			body:   `{"description": "chores"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Update(1, entity.UpdateProjectInput{Description: lo.ToPtr("chores")}).Return(project, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: projectResp,
+		},
+		{
+			desc:     "update empty name",
+			method:   http.MethodPatch,
+			path:     "/v1/projects/1",
+			body:     `{"name": ""}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'updateProjectReq.Name' Error:Field validation for 'Name' failed on the 'min' tag"}`,
+		},
+		{
+			desc:   "archive",
+			method: http.MethodPost,
+			path:   "/v1/projects/1/archive",
+			mock: func() {
+				archived := *project
+				archived.ArchivedAt = &archivedAt
+				s.mockSrv.EXPECT().Archive(1).Return(&archived, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{
+			  "id": 1,
+			  "name": "home",
+			  "description": "chores",
+			  "archived": true,
+			  "archivedAt": "1973-11-30T05:33:10+08:00",
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:   "unarchive",
+			method: http.MethodPost,
+			path:   "/v1/projects/1/unarchive",
+			mock: func() {
+				s.mockSrv.EXPECT().Unarchive(1).Return(project, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: projectResp,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *projectSuite) TestDelete() {
+	tests := []struct {
+		desc     string
+		path     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "blocks by default",
+			path: "/v1/projects/1",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.ProjectDeleteBlock).Return(nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc: "cascade",
+			path: "/v1/projects/1?todos=cascade",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.ProjectDeleteCascade).Return(nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc: "not empty",
+			path: "/v1/projects/1?todos=block",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.ProjectDeleteBlock).Return(fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrProjectNotEmpty)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: project still has todos"}`,
+		},
+		{
+			desc:     "invalid mode",
+			path:     "/v1/projects/1?todos=archive",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'removeProjectParams.Todos' Error:Field validation for 'Todos' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc: "service delete failed",
+			path: "/v1/projects/1?todos=inbox",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.ProjectDeleteInbox).Return(errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp == "" {
+				s.Empty(w.Body.String())
+				return
+			}
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *projectSuite) TestTodos() {
+	todo := &entity.Todo{
+		ID:        1,
+		Title:     "title-1",
+		ProjectID: 1,
+		Priority:  entity.PriorityNone,
+		Rank:      "i",
+		CreatedAt: time.Unix(123456789, 0),
+		UpdatedAt: time.Unix(123456789, 0),
+	}
+	todoResp := `{
+	  "id": 1,
+	  "title": "title-1",
+	  "description": "",
+	  "isCompleted": false,
+	  "priority": "none",
+	  "projectId": 1,
+	  "rank": "i",
+	  "createdAt": "1973-11-30T05:33:09+08:00",
+	  "updatedAt": "1973-11-30T05:33:09+08:00"
+	}`
+
+	tests := []struct {
+		desc     string
+		method   string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "list",
+			method: http.MethodGet,
+			path:   "/v1/projects/1/todos?order=manual",
+			mock: func() {
+				s.mockSrv.EXPECT().ListTodos(1, entity.TodoQuery{Days: 7, Location: time.UTC, Order: entity.OrderManual}).Return([]entity.Todo{*todo}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `[` + todoResp + `]`,
+		},
+		{
+			desc:   "list not found",
+			method: http.MethodGet,
+			path:   "/v1/projects/1/todos",
+			mock: func() {
+				s.mockSrv.EXPECT().ListTodos(1, entity.TodoQuery{Days: 7, Location: time.UTC}).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc:     "list invalid tag expression",
+			method:   http.MethodGet,
+			path:     "/v1/projects/1/todos?tags=or",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid tag expression: unexpected \"or\""}`,
+		},
+		{
+			desc:   "create",
+			method: http.MethodPost,
+			path:   "/v1/projects/1/todos",
+			body:   `{"title": "title-1"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTodo(1, entity.CreateTodoInput{Title: "title-1"}).Return(todo, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: todoResp,
+		},
+		{
+			desc:   "create in archived project",
+			method: http.MethodPost,
+			path:   "/v1/projects/1/todos",
+			body:   `{"title": "title-1"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTodo(1, entity.CreateTodoInput{Title: "title-1"}).Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrProjectArchived)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: project is archived"}`,
+		},
+		{
+			desc:   "critical path",
+			method: http.MethodGet,
+			path:   "/v1/projects/1/critical-path",
+			mock: func() {
+				s.mockSrv.EXPECT().CriticalPath(1).Return([]entity.Todo{*todo}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `[` + todoResp + `]`,
+		},
+		{
+			desc:   "critical path not found",
+			method: http.MethodGet,
+			path:   "/v1/projects/1/critical-path",
+			mock: func() {
+				s.mockSrv.EXPECT().CriticalPath(1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc:   "move",
+			method: http.MethodPut,
+			path:   "/v1/todos/1/project",
+			body:   `{"projectId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().MoveTodo(1, 1).Return(todo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: todoResp,
+		},
+		{
+			desc:   "move to inbox",
+			method: http.MethodPut,
+			path:   "/v1/todos/1/project",
+			body:   `{"projectId": 0}`,
+			mock: func() {
+				s.mockSrv.EXPECT().MoveTodo(1, entity.Inbox).Return(&entity.Todo{ID: 1, Title: "title-1", CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "move without project",
+			method:   http.MethodPut,
+			path:     "/v1/todos/1/project",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'moveTodoProjectReq.ProjectID' Error:Field validation for 'ProjectID' failed on the 'required' tag"}`,
+		},
+		{
+			desc:   "move not found",
+			method: http.MethodPut,
+			path:   "/v1/todos/1/project",
+			body:   `{"projectId": 9}`,
+			mock: func() {
+				s.mockSrv.EXPECT().MoveTodo(1, 9).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
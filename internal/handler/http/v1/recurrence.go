@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultOccurrences is how many occurrences the preview lists when count
+// is omitted.
+const defaultOccurrences = 5
+
+// recurrenceDTO is an RFC 5545 RRULE value such as "FREQ=WEEKLY;BYDAY=MO",
+// checked while decoding. An empty rule means the todo does not recur.
+type recurrenceDTO string
+
+func (r *recurrenceDTO) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s != "" {
+		if _, err := entity.ParseRRule(s); err != nil {
+			return err
+		}
+	}
+	*r = recurrenceDTO(s)
+	return nil
+}
+
+// rule returns the parsed rule, or nil for an empty one.
+func (r recurrenceDTO) rule() *entity.Recurrence {
+	if r == "" {
+		return nil
+	}
+	rule, _ := entity.ParseRRule(string(r))
+	return rule
+}
+
+// toRecurrenceUpdate converts an optional update value: nil keeps the rule
+// and an empty rule removes it.
+func toRecurrenceUpdate(r *recurrenceDTO) *entity.Recurrence {
+	if r == nil {
+		return nil
+	}
+	if rule := r.rule(); rule != nil {
+		return rule
+	}
+	return &entity.Recurrence{}
+}
+
+func newRecurrenceDTO(r *entity.Recurrence) recurrenceDTO {
+	if r == nil {
+		return ""
+	}
+	return recurrenceDTO(r.String())
+}
+
+type occurrenceParams struct {
+	ID    int `uri:"id" binding:"required,min=1"`
+	Count int `form:"count" binding:"omitempty,min=1,max=100"`
+}
+
+type occurrenceResp struct {
+	Start *dateTimeDTO `json:"start,omitempty"`
+	Due   *dateTimeDTO `json:"due,omitempty"`
+}
+
+// occurrences previews the dates of the next occurrences of a recurring
+// todo.
+func (h *todoHandler) occurrences(c *gin.Context) {
+	var params occurrenceParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	occurrences, err := h.todos(c).Occurrences(params.ID, cmp.Or(params.Count, defaultOccurrences))
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]occurrenceResp, len(occurrences))
+	for i, occurrence := range occurrences {
+		resp[i] = occurrenceResp{
+			Start: newDateTimeDTO(occurrence.Start),
+			Due:   newDateTimeDTO(occurrence.Due),
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type recurrenceSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTodo
+}
+
+func (s *recurrenceSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewTodoRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestRecurrenceSuite(t *testing.T) {
+	suite.Run(t, new(recurrenceSuite))
+}
+
+func (s *recurrenceSuite) serve(method, path, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *recurrenceSuite) rule(rrule string) *entity.Recurrence {
+	rule, err := entity.ParseRRule(rrule)
+	s.Require().NoError(err)
+	return rule
+}
+
+func (s *recurrenceSuite) TestCreate() {
+	due := entity.NewDate(2026, 10, 19)
+
+	s.Run("success", func() {
+		rule := s.rule("FREQ=WEEKLY;BYDAY=MO")
+		s.mockSrv.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Due: &due, Recurrence: rule}).Return(&entity.Todo{
+			ID:         1,
+			Title:      "title-1",
+			Due:        &due,
+			Recurrence: rule,
+			CreatedAt:  time.Unix(123456789, 0),
+			UpdatedAt:  time.Unix(123456789, 0),
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/todos", "application/json", `{"title": "title-1", "due": {"date": "2026-10-19"}, "recurrence": "rrule:freq=weekly;byday=mo"}`)
+		s.Equal(http.StatusCreated, w.Code)
+		s.JSONEq(`{
+			"id": 1,
+			"title": "title-1",
+			"description": "",
+			"isCompleted": false,
+			"due": {"date": "2026-10-19"},
+			"recurrence": "FREQ=WEEKLY;BYDAY=MO",
+			"createdAt": "1973-11-30T05:33:09+08:00",
+			"updatedAt": "1973-11-30T05:33:09+08:00"
+		}`, w.Body.String())
+	})
+	s.Run("invalid rule", func() {
+		w := s.serve(http.MethodPost, "/v1/todos", "application/json", `{"title": "title-1", "recurrence": "FREQ=HOURLY"}`)
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.Contains(w.Body.String(), "invalid recurrence rule")
+	})
+	s.Run("missing date", func() {
+		s.mockSrv.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Recurrence: s.rule("FREQ=DAILY")}).Return(nil, service.ErrInvalidInput).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/todos", "application/json", `{"title": "title-1", "recurrence": "FREQ=DAILY"}`)
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
+
+func (s *recurrenceSuite) TestUpdate() {
+	s.Run("set rule", func() {
+		s.mockSrv.EXPECT().Update(1, entity.UpdateTodoInput{Recurrence: s.rule("FREQ=DAILY;COUNT=3")}).Return(nil).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/json", `{"recurrence": "FREQ=DAILY;COUNT=3"}`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("clear rule", func() {
+		s.mockSrv.EXPECT().Update(1, entity.UpdateTodoInput{Recurrence: &entity.Recurrence{}}).Return(nil).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/json", `{"recurrence": ""}`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("invalid rule", func() {
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/json", `{"recurrence": "FREQ=DAILY;COUNT=0"}`)
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("merge patch clears rule", func() {
+		s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			todo := entity.Todo{ID: 1, Due: lo.ToPtr(entity.NewDate(2026, 10, 19)), Recurrence: s.rule("FREQ=DAILY")}
+			s.Require().NoError(fn(&todo))
+			s.Nil(todo.Recurrence)
+			return nil
+		}).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/merge-patch+json", `{"recurrence": null}`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("json patch sets rule", func() {
+		s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			todo := entity.Todo{ID: 1, Due: lo.ToPtr(entity.NewDate(2026, 10, 19))}
+			s.Require().NoError(fn(&todo))
+			s.Equal(s.rule("FREQ=MONTHLY;BYMONTHDAY=-1"), todo.Recurrence)
+			return nil
+		}).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/json-patch+json", `[{"op": "replace", "path": "/recurrence", "value": "FREQ=MONTHLY;BYMONTHDAY=-1"}]`)
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("merge patch invalid rule", func() {
+		s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			return fn(&entity.Todo{ID: 1})
+		}).Times(1)
+
+		w := s.serve(http.MethodPatch, "/v1/todos/1", "application/merge-patch+json", `{"recurrence": "FREQ=SECONDLY"}`)
+		s.Equal(http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func (s *recurrenceSuite) TestOccurrences() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Occurrences(1, 2).Return([]entity.Occurrence{
+			{Due: lo.ToPtr(entity.NewDate(2026, 10, 26))},
+			{Start: lo.ToPtr(entity.NewDateTime(time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), "UTC")), Due: lo.ToPtr(entity.NewDate(2026, 11, 2))},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/1/occurrences?count=2", "", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[
+			{"due": {"date": "2026-10-26"}},
+			{"start": {"dateTime": "2026-11-02T09:00:00Z", "timeZone": "UTC"}, "due": {"date": "2026-11-02"}}
+		]`, w.Body.String())
+	})
+	s.Run("default count", func() {
+		s.mockSrv.EXPECT().Occurrences(1, defaultOccurrences).Return(nil, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/1/occurrences", "", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[]`, w.Body.String())
+	})
+	s.Run("count too large", func() {
+		w := s.serve(http.MethodGet, "/v1/todos/1/occurrences?count=101", "", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Occurrences(1, defaultOccurrences).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/1/occurrences", "", "")
+		s.Equal(http.StatusNotFound, w.Code)
+		s.JSONEq(`{"error": "not found"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,138 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ssoStateCookie binds a login to the browser that started it, so a
+	// callback URL from someone else's login cannot log the victim in as
+	// them.
+	ssoStateCookie = "sso_state"
+	ssoCookiePath  = "/v1/sso"
+)
+
+type ssoHandler struct {
+	srv service.SSO
+}
+
+// NewSSORoutes registers login with the identity provider, which must stay
+// outside of Auth.
+func NewSSORoutes(rg *gin.RouterGroup, srv service.SSO) {
+	h := &ssoHandler{
+		srv: srv,
+	}
+	rg.GET("/sso/login", h.login)
+	rg.GET("/sso/callback", h.callback)
+}
+
+// NewSSOSessionRoutes registers logout from the identity provider, which
+// needs Auth.
+func NewSSOSessionRoutes(rg *gin.RouterGroup, srv service.SSO) {
+	h := &ssoHandler{
+		srv: srv,
+	}
+	rg.POST("/sso/logout", middleware.RequireSession(), h.logout)
+}
+
+// login sends the browser to the identity provider.
+func (h *ssoHandler) login(c *gin.Context) {
+	authURL, state, err := h.srv.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, state, 0, ssoCookiePath, "", c.Request.TLS != nil, true)
+	c.Header("Location", authURL)
+	c.Status(http.StatusFound)
+}
+
+type ssoCallbackQuery struct {
+	Code  string `form:"code"`
+	State string `form:"state"`
+	// Error and ErrorDescription are sent instead of a code when the login
+	// failed at the provider.
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+type ssoCallbackResp struct {
+	AccessToken string    `json:"accessToken"`
+	TokenType   string    `json:"tokenType"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	UserID      int       `json:"userId"`
+}
+
+// callback is where the identity provider sends the browser back to.
+func (h *ssoHandler) callback(c *gin.Context) {
+	var query ssoCallbackQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cookie, _ := c.Cookie(ssoStateCookie)
+	c.SetCookie(ssoStateCookie, "", -1, ssoCookiePath, "", c.Request.TLS != nil, true)
+
+	switch {
+	case query.Error != "":
+		msg := strings.TrimSpace("identity provider refused the login: " + query.Error + " " + query.ErrorDescription)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+		return
+	case query.Code == "" || query.State == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	case cookie != query.State:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "login was started in another browser"})
+		return
+	}
+
+	user, token, err := h.srv.Complete(c.Request.Context(), query.State, query.Code)
+	if errors.Is(err, service.ErrUnauthorized) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ssoCallbackResp{
+		AccessToken: token.Value,
+		TokenType:   "Bearer",
+		ExpiresAt:   token.ExpiresAt,
+		UserID:      user.ID,
+	})
+}
+
+type ssoLogoutResp struct {
+	// LogoutURL is where to send the browser to log out at the identity
+	// provider as well.
+	LogoutURL string `json:"logoutUrl,omitempty"`
+}
+
+// logout revokes the access token the request is made with and returns the
+// URL that ends the session at the identity provider.
+func (h *ssoHandler) logout(c *gin.Context) {
+	logoutURL, err := h.srv.Logout(c.Request.Context(), middleware.AccessToken(c))
+	if errors.Is(err, service.ErrUnauthorized) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ssoLogoutResp{LogoutURL: logoutURL})
+}
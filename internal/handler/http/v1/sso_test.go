@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type ssoSuite struct {
+	suite.Suite
+	router     *gin.Engine
+	mockSrv    *mocks.MockSSO
+	mockUser   *mocks.MockUser
+	mockAPIKey *mocks.MockAPIKey
+}
+
+func (s *ssoSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockSSO(ctrl)
+	s.mockUser = mocks.NewMockUser(ctrl)
+	s.mockAPIKey = mocks.NewMockAPIKey(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewSSORoutes(s.router.Group("v1"), s.mockSrv)
+	NewSSOSessionRoutes(s.router.Group("v1", middleware.Auth(s.mockUser, s.mockAPIKey)), s.mockSrv)
+}
+
+func TestSSOSuite(t *testing.T) {
+	suite.Run(t, new(ssoSuite))
+}
+
+func (s *ssoSuite) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *ssoSuite) TestLogin() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Begin(gomock.Any()).Return("https://idp.example.com/authorize?state=state-1", "state-1", nil).Times(1)
+
+		w := s.serve(httptest.NewRequest(http.MethodGet, "/v1/sso/login", nil))
+		s.Equal(http.StatusFound, w.Code)
+		s.Equal("https://idp.example.com/authorize?state=state-1", w.Header().Get("Location"))
+		s.Equal("sso_state=state-1; Path=/v1/sso; HttpOnly; SameSite=Lax", w.Header().Get("Set-Cookie"))
+		s.Empty(w.Body.String())
+	})
+	s.Run("service begin failed", func() {
+		s.mockSrv.EXPECT().Begin(gomock.Any()).Return("", "", errors.New("something wrong")).Times(1)
+
+		w := s.serve(httptest.NewRequest(http.MethodGet, "/v1/sso/login", nil))
+		s.Equal(http.StatusInternalServerError, w.Code)
+		s.JSONEq(`{"error": "something wrong"}`, w.Body.String())
+	})
+}
+
+func (s *ssoSuite) TestCallback() {
+	tests := []struct {
+		desc     string
+		query    string
+		cookie   string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "success",
+			query:  "code=code-1&state=state-1",
+			cookie: "state-1",
+			mock: func() {
+				s.mockSrv.EXPECT().Complete(gomock.Any(), "state-1", "code-1").Return(
+					&entity.User{ID: 1},
+					&entity.AccessToken{Value: "token-1", ExpiresAt: time.Unix(123456789, 0)},
+					nil,
+				).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"accessToken": "token-1", "tokenType": "Bearer", "expiresAt": "1973-11-30T05:33:09+08:00", "userId": 1}`,
+		},
+		{
+			desc:     "refused by the provider",
+			query:    "error=access_denied&error_description=user+cancelled&state=state-1",
+			cookie:   "state-1",
+			mock:     func() {},
+			wantCode: http.StatusUnauthorized,
+			wantResp: `{"error": "identity provider refused the login: access_denied user cancelled"}`,
+		},
+		{
+			desc:     "missing code",
+			query:    "state=state-1",
+			cookie:   "state-1",
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "code and state are required"}`,
+		},
+		{
+			desc:     "started in another browser",
+			query:    "code=code-1&state=state-1",
+			cookie:   "state-2",
+			mock:     func() {},
+			wantCode: http.StatusUnauthorized,
+			wantResp: `{"error": "login was started in another browser"}`,
+		},
+		{
+			desc:     "without cookie",
+			query:    "code=code-1&state=state-1",
+			mock:     func() {},
+			wantCode: http.StatusUnauthorized,
+			wantResp: `{"error": "login was started in another browser"}`,
+		},
+		{
+			desc:   "unauthorized",
+			query:  "code=code-1&state=state-1",
+			cookie: "state-1",
+			mock: func() {
+				s.mockSrv.EXPECT().Complete(gomock.Any(), "state-1", "code-1").Return(nil, nil, service.ErrUnauthorized).Times(1)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantResp: `{"error": "unauthorized"}`,
+		},
+		{
+			desc:   "conflict",
+			query:  "code=code-1&state=state-1",
+			cookie: "state-1",
+			mock: func() {
+				s.mockSrv.EXPECT().Complete(gomock.Any(), "state-1", "code-1").Return(nil, nil, service.ErrConflict).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict"}`,
+		},
+		{
+			desc:   "service complete failed",
+			query:  "code=code-1&state=state-1",
+			cookie: "state-1",
+			mock: func() {
+				s.mockSrv.EXPECT().Complete(gomock.Any(), "state-1", "code-1").Return(nil, nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			req := httptest.NewRequest(http.MethodGet, "/v1/sso/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: ssoStateCookie, Value: tt.cookie})
+			}
+
+			w := s.serve(req)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+			s.Contains(w.Header().Get("Set-Cookie"), "sso_state=; Path=/v1/sso; Max-Age=0", "the cookie is cleared")
+		})
+	}
+}
+
+func (s *ssoSuite) TestLogout() {
+	logout := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/sso/logout", nil)
+		req.Header.Set("Authorization", "Bearer token-1")
+		return s.serve(req)
+	}
+	s.Run("success", func() {
+		s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockSrv.EXPECT().Logout(gomock.Any(), "token-1").Return("https://idp.example.com/logout?id_token_hint=id-token", nil).Times(1)
+
+		w := logout()
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"logoutUrl": "https://idp.example.com/logout?id_token_hint=id-token"}`, w.Body.String())
+	})
+	s.Run("password session", func() {
+		s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockSrv.EXPECT().Logout(gomock.Any(), "token-1").Return("", nil).Times(1)
+
+		w := logout()
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{}`, w.Body.String())
+	})
+	s.Run("service logout failed", func() {
+		s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockSrv.EXPECT().Logout(gomock.Any(), "token-1").Return("", errors.New("something wrong")).Times(1)
+
+		w := logout()
+		s.Equal(http.StatusInternalServerError, w.Code)
+		s.JSONEq(`{"error": "something wrong"}`, w.Body.String())
+	})
+}
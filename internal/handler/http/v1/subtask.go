@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func subtaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type subtaskParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type setParentReq struct {
+	ParentID *int `json:"parentId" binding:"required,min=0"`
+}
+
+type setParentResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// setParent moves a todo below another one, or to the top level for
+// parentId 0.
+func (h *todoHandler) setParent(c *gin.Context) {
+	var params subtaskParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req setParentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).SetParent(params.ID, *req.ParentID)
+	if err != nil {
+		subtaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setParentResp{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	})
+}
+
+type todoTreeResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	// Checklist and ChecklistItems are as in getTodoResp.
+	Checklist      string             `json:"checklist,omitempty"`
+	ChecklistItems []checklistItemDTO `json:"checklistItems,omitempty"`
+	Start          *dateTimeDTO       `json:"start,omitempty"`
+	Due            *dateTimeDTO       `json:"due,omitempty"`
+	Recurrence     recurrenceDTO      `json:"recurrence,omitempty"`
+	Estimate       int64              `json:"estimateSeconds,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+	Children       []*todoTreeResp    `json:"children"`
+}
+
+// subtree serves a todo with all of its subtasks nested below it.
+func (h *todoHandler) subtree(c *gin.Context) {
+	var params subtaskParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.todos(c).Subtree(params.ID)
+	if err != nil {
+		subtaskError(c, err)
+		return
+	}
+
+	// Parents come before their children, so every parent node exists by
+	// the time its children are attached.
+	nodes := make(map[int]*todoTreeResp, len(todos))
+	for i, todo := range todos {
+		node := &todoTreeResp{
+			ID:             todo.ID,
+			Title:          todo.Title,
+			Description:    todo.Description,
+			IsCompleted:    todo.IsCompleted,
+			Blocked:        todo.Blocked,
+			Priority:       todo.Priority,
+			ProjectID:      todo.ProjectID,
+			ParentID:       todo.ParentID,
+			Rollup:         todo.Rollup,
+			Rank:           todo.Rank,
+			Tags:           todo.Tags,
+			Checklist:      todo.Checklist.String(),
+			ChecklistItems: checklistItems(todo.Checklist),
+			Start:          newDateTimeDTO(todo.Start),
+			Due:            newDateTimeDTO(todo.Due),
+			Recurrence:     newRecurrenceDTO(todo.Recurrence),
+			Estimate:       seconds(todo.Estimate),
+			CreatedAt:      todo.CreatedAt,
+			UpdatedAt:      todo.UpdatedAt,
+			Children:       []*todoTreeResp{},
+		}
+		nodes[todo.ID] = node
+		if i > 0 {
+			parent := nodes[todo.ParentID]
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	c.JSON(http.StatusOK, nodes[todos[0].ID])
+}
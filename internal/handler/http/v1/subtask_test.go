@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type subtaskSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTodo
+}
+
+func (s *subtaskSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewTodoRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestSubtaskSuite(t *testing.T) {
+	suite.Run(t, new(subtaskSuite))
+}
+
+func (s *subtaskSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *subtaskSuite) TestSetParent() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"parentId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetParent(2, 1).Return(&entity.Todo{
+					ID:        2,
+					Title:     "title-2",
+					Priority:  entity.PriorityNone,
+					ParentID:  1,
+					Rollup:    entity.RollupNone,
+					CreatedAt: time.Unix(123456789, 0),
+					UpdatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{
+			  "id": 2,
+			  "title": "title-2",
+			  "description": "",
+			  "isCompleted": false,
+			  "priority": "none",
+			  "parentId": 1,
+			  "rollup": "none",
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "missing parent",
+			body:     `{}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'setParentReq.ParentID' Error:Field validation for 'ParentID' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "cycle",
+			body: `{"parentId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetParent(2, 1).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrParentCycle)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: cannot move a todo under itself or its subtasks"}`,
+		},
+		{
+			desc: "not found",
+			body: `{"parentId": 0}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetParent(2, 0).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc: "service failed",
+			body: `{"parentId": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().SetParent(2, 1).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPut, "/v1/todos/2/parent", tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *subtaskSuite) TestSubtree() {
+	tests := []struct {
+		desc     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			mock: func() {
+				s.mockSrv.EXPECT().Subtree(1).Return([]entity.Todo{
+					{ID: 1, Title: "title-1", Rollup: entity.RollupAuto, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+					{ID: 2, Title: "title-2", ParentID: 1, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+					{ID: 4, Title: "title-4", ParentID: 2, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+					{ID: 3, Title: "title-3", ParentID: 1, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{
+			  "id": 1, "title": "title-1", "description": "", "isCompleted": false, "rollup": "auto",
+			  "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00",
+			  "children": [
+			    {
+			      "id": 2, "title": "title-2", "description": "", "isCompleted": false, "parentId": 1,
+			      "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00",
+			      "children": [
+			        {
+			          "id": 4, "title": "title-4", "description": "", "isCompleted": false, "parentId": 2,
+			          "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00",
+			          "children": []
+			        }
+			      ]
+			    },
+			    {
+			      "id": 3, "title": "title-3", "description": "", "isCompleted": false, "parentId": 1,
+			      "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00",
+			      "children": []
+			    }
+			  ]
+			}`,
+		},
+		{
+			desc: "not found",
+			mock: func() {
+				s.mockSrv.EXPECT().Subtree(1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodGet, "/v1/todos/1/subtree", "")
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
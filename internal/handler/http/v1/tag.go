@@ -0,0 +1,307 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type tagHandler struct {
+	srv service.Todo
+}
+
+func NewTagRoutes(rg *gin.RouterGroup, srv service.Todo) {
+	h := &tagHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeTagsRead)
+	write := middleware.RequireScope(entity.ScopeTagsWrite)
+	// Tagging a todo changes the todo and may create tags.
+	tagTodo := middleware.RequireScope(entity.ScopeTodosWrite, entity.ScopeTagsWrite)
+	rg.POST("/tags", write, h.create)
+	rg.GET("/tags", read, h.list)
+	rg.GET("/tags/:id", read, h.get)
+	rg.PATCH("/tags/:id", write, h.rename)
+	rg.DELETE("/tags/:id", write, h.remove)
+	rg.POST("/tags/:id/merge", write, h.merge)
+	rg.POST("/todos/:id/tags", tagTodo, h.attach)
+	rg.DELETE("/todos/:id/tags/:tag", tagTodo, h.detach)
+}
+
+// todos returns the todo service as seen by the caller. Tags are shared by
+// every member of a workspace, so their role decides what they may do with
+// them.
+func (h *tagHandler) todos(c *gin.Context) service.Todo {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// tagError writes the status for errors shared by every tag endpoint.
+func tagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type tagParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type createTagReq struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+type createTagResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	TodoCount int       `json:"todoCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *tagHandler) create(c *gin.Context) {
+	var req createTagReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.todos(c).CreateTag(req.Name)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createTagResp{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		TodoCount: tag.TodoCount,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	})
+}
+
+type listTagResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	TodoCount int       `json:"todoCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *tagHandler) list(c *gin.Context) {
+	tags, err := h.todos(c).ListTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]listTagResp, len(tags))
+	for i, tag := range tags {
+		resp[i] = listTagResp{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			TodoCount: tag.TodoCount,
+			CreatedAt: tag.CreatedAt,
+			UpdatedAt: tag.UpdatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type getTagResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	TodoCount int       `json:"todoCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *tagHandler) get(c *gin.Context) {
+	var params tagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.todos(c).GetTag(params.ID)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getTagResp{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		TodoCount: tag.TodoCount,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	})
+}
+
+type renameTagReq struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+type renameTagResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	TodoCount int       `json:"todoCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *tagHandler) rename(c *gin.Context) {
+	var params tagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req renameTagReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.todos(c).RenameTag(params.ID, req.Name)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, renameTagResp{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		TodoCount: tag.TodoCount,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	})
+}
+
+func (h *tagHandler) remove(c *gin.Context) {
+	var params tagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.todos(c).DeleteTag(params.ID); err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type mergeTagReq struct {
+	Into int `json:"into" binding:"required,min=1"`
+}
+
+type mergeTagResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	TodoCount int       `json:"todoCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *tagHandler) merge(c *gin.Context) {
+	var params tagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req mergeTagReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.todos(c).MergeTags(params.ID, req.Into)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mergeTagResp{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		TodoCount: tag.TodoCount,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	})
+}
+
+type attachTagsReq struct {
+	Tags []string `json:"tags" binding:"required,min=1,max=50"`
+}
+
+type todoTagsResp struct {
+	ID   int      `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+func (h *tagHandler) attach(c *gin.Context) {
+	var params tagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req attachTagsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todo, err := h.todos(c).AttachTags(params.ID, req.Tags)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, todoTagsResp{
+		ID:   todo.ID,
+		Tags: tagNames(todo.Tags),
+	})
+}
+
+type detachTagParams struct {
+	ID  int    `uri:"id" binding:"required,min=1"`
+	Tag string `uri:"tag" binding:"required"`
+}
+
+func (h *tagHandler) detach(c *gin.Context) {
+	var params detachTagParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.todos(c).DetachTags(params.ID, []string{params.Tag}); err != nil {
+		tagError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// tagNames keeps an untagged todo rendering as an empty list.
+func tagNames(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
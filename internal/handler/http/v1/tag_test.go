@@ -0,0 +1,382 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type tagSuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTodo
+}
+
+func (s *tagSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewTodoRoutes(s.router.Group("v1"), s.mockSrv)
+	NewTagRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func TestTagSuite(t *testing.T) {
+	suite.Run(t, new(tagSuite))
+}
+
+func (s *tagSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *tagSuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"name": "Work"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTag("Work").Return(&entity.Tag{
+					ID:        1,
+					Name:      "work",
+					CreatedAt: time.Unix(123456789, 0),
+					UpdatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "id": 1,
+			  "name": "work",
+			  "todoCount": 0,
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "missing name",
+			body:     `{}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'createTagReq.Name' Error:Field validation for 'Name' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "invalid name",
+			body: `{"name": "a b"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTag("a b").Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidTagName)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: invalid tag name"}`,
+		},
+		{
+			desc: "exists",
+			body: `{"name": "work"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTag("work").Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrTagExists)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: tag already exists"}`,
+		},
+		{
+			desc: "service create failed",
+			body: `{"name": "work"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().CreateTag("work").Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, "/v1/tags", tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *tagSuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().ListTags().Return([]entity.Tag{
+			{ID: 2, Name: "home", TodoCount: 1, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+			{ID: 1, Name: "work", TodoCount: 3, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/tags", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[
+		  {"id": 2, "name": "home", "todoCount": 1, "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"},
+		  {"id": 1, "name": "work", "todoCount": 3, "createdAt": "1973-11-30T05:33:09+08:00", "updatedAt": "1973-11-30T05:33:09+08:00"}
+		]`, w.Body.String())
+	})
+	s.Run("empty", func() {
+		s.mockSrv.EXPECT().ListTags().Return(nil, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/tags", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[]`, w.Body.String())
+	})
+}
+
+func (s *tagSuite) TestUpdate() {
+	tag := &entity.Tag{
+		ID:        1,
+		Name:      "office",
+		TodoCount: 2,
+		CreatedAt: time.Unix(123456789, 0),
+		UpdatedAt: time.Unix(123456789, 0),
+	}
+	tagResp := `{
+	  "id": 1,
+	  "name": "office",
+	  "todoCount": 2,
+	  "createdAt": "1973-11-30T05:33:09+08:00",
+	  "updatedAt": "1973-11-30T05:33:09+08:00"
+	}`
+
+	tests := []struct {
+		desc     string
+		method   string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "get",
+			method: http.MethodGet,
+			path:   "/v1/tags/1",
+			mock: func() {
+				s.mockSrv.EXPECT().GetTag(1).Return(tag, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: tagResp,
+		},
+		{
+			desc:     "get invalid id",
+			method:   http.MethodGet,
+			path:     "/v1/tags/abc",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "strconv.ParseInt: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			desc:   "get not found",
+			method: http.MethodGet,
+			path:   "/v1/tags/1",
+			mock: func() {
+				s.mockSrv.EXPECT().GetTag(1).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc:   "rename",
+			method: http.MethodPatch,
+			path:   "/v1/tags/1",
+			body:   `{"name": "office"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().RenameTag(1, "office").Return(tag, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: tagResp,
+		},
+		{
+			desc:   "rename taken",
+			method: http.MethodPatch,
+			path:   "/v1/tags/1",
+			body:   `{"name": "home"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().RenameTag(1, "home").Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrTagExists)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: tag already exists"}`,
+		},
+		{
+			desc:   "merge",
+			method: http.MethodPost,
+			path:   "/v1/tags/2/merge",
+			body:   `{"into": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().MergeTags(2, 1).Return(tag, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: tagResp,
+		},
+		{
+			desc:     "merge missing target",
+			method:   http.MethodPost,
+			path:     "/v1/tags/2/merge",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'mergeTagReq.Into' Error:Field validation for 'Into' failed on the 'required' tag"}`,
+		},
+		{
+			desc:   "merge into itself",
+			method: http.MethodPost,
+			path:   "/v1/tags/1/merge",
+			body:   `{"into": 1}`,
+			mock: func() {
+				s.mockSrv.EXPECT().MergeTags(1, 1).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrMergeSelf)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: cannot merge a tag into itself"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+			w := s.serve(tt.method, tt.path, tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *tagSuite) TestDelete() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().DeleteTag(1).Return(nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/tags/1", "")
+		s.Equal(http.StatusNoContent, w.Code)
+		s.Empty(w.Body.String())
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().DeleteTag(1).Return(service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/tags/1", "")
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *tagSuite) TestAttachDetach() {
+	tests := []struct {
+		desc     string
+		method   string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc:   "attach",
+			method: http.MethodPost,
+			path:   "/v1/todos/1/tags",
+			body:   `{"tags": ["work", "Home"]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AttachTags(1, []string{"work", "Home"}).Return(&entity.Todo{ID: 1, Tags: []string{"home", "work"}}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"id": 1, "tags": ["home", "work"]}`,
+		},
+		{
+			desc:     "attach nothing",
+			method:   http.MethodPost,
+			path:     "/v1/todos/1/tags",
+			body:     `{"tags": []}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'attachTagsReq.Tags' Error:Field validation for 'Tags' failed on the 'min' tag"}`,
+		},
+		{
+			desc:   "attach to missing todo",
+			method: http.MethodPost,
+			path:   "/v1/todos/1/tags",
+			body:   `{"tags": ["work"]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AttachTags(1, []string{"work"}).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc:   "detach",
+			method: http.MethodDelete,
+			path:   "/v1/todos/1/tags/work",
+			mock: func() {
+				s.mockSrv.EXPECT().DetachTags(1, []string{"work"}).Return(&entity.Todo{ID: 1}, nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:   "detach invalid name",
+			method: http.MethodDelete,
+			path:   "/v1/todos/1/tags/a%7Cb",
+			mock: func() {
+				s.mockSrv.EXPECT().DetachTags(1, []string{"a|b"}).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidTagName)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: invalid tag name"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+			w := s.serve(tt.method, tt.path, tt.body)
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp == "" {
+				s.Empty(w.Body.String())
+				return
+			}
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *tagSuite) TestListTodosByTags() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(entity.TodoQuery{
+			Tags:     entity.TagAnd{Left: entity.TagName("work"), Right: entity.TagNot{Expr: entity.TagName("home")}},
+			Days:     7,
+			Location: time.UTC,
+		}).Return([]entity.Todo{
+			{ID: 1, Title: "title-1", Tags: []string{"work"}, CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos?tags=Work+and+not+home", "")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[{
+		  "id": 1,
+		  "title": "title-1",
+		  "description": "",
+		  "isCompleted": false,
+		  "tags": ["work"],
+		  "createdAt": "1973-11-30T05:33:09+08:00",
+		  "updatedAt": "1973-11-30T05:33:09+08:00"
+		}]`, w.Body.String())
+	})
+	s.Run("invalid expression", func() {
+		w := s.serve(http.MethodGet, "/v1/todos?tags=work+and", "")
+		s.Equal(http.StatusBadRequest, w.Code)
+		s.JSONEq(`{"error": "invalid tag expression: unexpected end"}`, w.Body.String())
+	})
+}
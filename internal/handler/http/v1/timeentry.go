@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+// timeNow is when running timers are counted up to.
+var timeNow = time.Now
+
+type timeEntryHandler struct {
+	srv service.TimeEntry
+}
+
+// NewTimeEntryRoutes registers the timers, time entries, totals and reports
+// of tracked time, which take the scopes of todos. Project totals take the
+// scopes of projects as well.
+func NewTimeEntryRoutes(rg *gin.RouterGroup, srv service.TimeEntry) {
+	h := &timeEntryHandler{
+		srv: srv,
+	}
+	read := middleware.RequireScope(entity.ScopeTodosRead)
+	write := middleware.RequireScope(entity.ScopeTodosWrite)
+	readProject := middleware.RequireScope(entity.ScopeProjectsRead, entity.ScopeTodosRead)
+	rg.GET("/timer", read, h.running)
+	rg.POST("/timer/stop", write, h.stop)
+	rg.POST("/todos/:id/timer", write, h.start)
+	rg.POST("/todos/:id/time-entries", write, h.create)
+	rg.GET("/todos/:id/time-entries", read, h.list)
+	rg.GET("/todos/:id/time-entries/:entryId", read, h.get)
+	rg.PATCH("/todos/:id/time-entries/:entryId", write, h.update)
+	rg.DELETE("/todos/:id/time-entries/:entryId", write, h.remove)
+	rg.GET("/todos/:id/time", read, h.todoTotals)
+	rg.GET("/projects/:id/time", readProject, h.projectTotals)
+	rg.GET("/time/report", read, h.report)
+}
+
+// entries returns the time entry service as seen by the caller.
+func (h *timeEntryHandler) entries(c *gin.Context) service.TimeEntry {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
+}
+
+// timeEntryError writes the status for errors shared by every time entry
+// endpoint.
+func timeEntryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type timeEntryTodoParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type timeEntryParams struct {
+	ID      int `uri:"id" binding:"required,min=1"`
+	EntryID int `uri:"entryId" binding:"required,min=1"`
+}
+
+// timeEntryResp is an entry as every endpoint answers with it. Seconds of a
+// running timer count up to the time of the response.
+type timeEntryResp struct {
+	ID          int        `json:"id"`
+	WorkspaceID int        `json:"workspaceId"`
+	TodoID      int        `json:"todoId"`
+	UserID      int        `json:"userId"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"`
+	Running     bool       `json:"running"`
+	Seconds     int64      `json:"seconds"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func newTimeEntryResp(entry entity.TimeEntry) timeEntryResp {
+	return timeEntryResp{
+		ID:          entry.ID,
+		WorkspaceID: entry.WorkspaceID,
+		TodoID:      entry.TodoID,
+		UserID:      entry.UserID,
+		Start:       entry.Start,
+		End:         entry.End,
+		Running:     entry.Running(),
+		Seconds:     seconds(entry.Duration(timeNow())),
+		Note:        entry.Note,
+		CreatedAt:   entry.CreatedAt,
+		UpdatedAt:   entry.UpdatedAt,
+	}
+}
+
+// seconds rounds a duration down to whole seconds.
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+// running serves the timer the caller has running, which may be in another
+// workspace.
+func (h *timeEntryHandler) running(c *gin.Context) {
+	entry, err := h.entries(c).Running()
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTimeEntryResp(*entry))
+}
+
+func (h *timeEntryHandler) stop(c *gin.Context) {
+	entry, err := h.entries(c).Stop()
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTimeEntryResp(*entry))
+}
+
+type startTimerReq struct {
+	Note string `json:"note"`
+}
+
+type startTimerResp struct {
+	Started timeEntryResp `json:"started"`
+	// Stopped is the timer that was running until this one started.
+	Stopped *timeEntryResp `json:"stopped,omitempty"`
+}
+
+// start starts a timer on a todo, stopping the one the caller had running.
+func (h *timeEntryHandler) start(c *gin.Context) {
+	var params timeEntryTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req startTimerReq
+	// The body is optional.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	started, stopped, err := h.entries(c).Start(params.ID, req.Note)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	resp := startTimerResp{Started: newTimeEntryResp(*started)}
+	if stopped != nil {
+		resp.Stopped = lo.ToPtr(newTimeEntryResp(*stopped))
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+type createTimeEntryReq struct {
+	Start *time.Time `json:"start" binding:"required"`
+	End   *time.Time `json:"end" binding:"required"`
+	Note  string     `json:"note"`
+}
+
+// create records time spent without a timer.
+func (h *timeEntryHandler) create(c *gin.Context) {
+	var params timeEntryTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req createTimeEntryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.entries(c).Create(params.ID, *req.Start, *req.End, req.Note)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newTimeEntryResp(*entry))
+}
+
+type listTimeEntryResp struct {
+	Items      []timeEntryResp `json:"items"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+func (h *timeEntryHandler) list(c *gin.Context) {
+	var params timeEntryTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var page pageParams
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.entries(c).List(params.ID, page.query())
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	resp := listTimeEntryResp{
+		Items:      make([]timeEntryResp, len(entries.Items)),
+		NextCursor: entries.NextCursor,
+	}
+	for i, entry := range entries.Items {
+		resp.Items[i] = newTimeEntryResp(entry)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *timeEntryHandler) get(c *gin.Context) {
+	var params timeEntryParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.entries(c).Get(params.ID, params.EntryID)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTimeEntryResp(*entry))
+}
+
+type updateTimeEntryReq struct {
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+	Note  *string    `json:"note"`
+}
+
+// update edits an entry. Setting the end of a running timer stops it.
+func (h *timeEntryHandler) update(c *gin.Context) {
+	var params timeEntryParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req updateTimeEntryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.entries(c).Update(params.ID, params.EntryID, entity.UpdateTimeEntryInput{
+		Start: req.Start,
+		End:   req.End,
+		Note:  req.Note,
+	})
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTimeEntryResp(*entry))
+}
+
+func (h *timeEntryHandler) remove(c *gin.Context) {
+	var params timeEntryParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.entries(c).Delete(params.ID, params.EntryID); err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type timeTotalsDTO struct {
+	EstimateSeconds  int64 `json:"estimateSeconds"`
+	TrackedSeconds   int64 `json:"trackedSeconds"`
+	RemainingSeconds int64 `json:"remainingSeconds"`
+}
+
+func newTimeTotalsDTO(totals entity.TimeTotals) timeTotalsDTO {
+	return timeTotalsDTO{
+		EstimateSeconds:  seconds(totals.Estimate),
+		TrackedSeconds:   seconds(totals.Tracked),
+		RemainingSeconds: seconds(totals.Remaining()),
+	}
+}
+
+type todoTimeResp struct {
+	TodoID int           `json:"todoId"`
+	Own    timeTotalsDTO `json:"own"`
+	// WithSubtasks adds up the todo and all of its subtasks.
+	WithSubtasks timeTotalsDTO `json:"withSubtasks"`
+}
+
+func (h *timeEntryHandler) todoTotals(c *gin.Context) {
+	var params timeEntryTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	own, withSubtasks, err := h.entries(c).TodoTotals(params.ID)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, todoTimeResp{
+		TodoID:       params.ID,
+		Own:          newTimeTotalsDTO(own),
+		WithSubtasks: newTimeTotalsDTO(withSubtasks),
+	})
+}
+
+type projectTimeResp struct {
+	ProjectID int           `json:"projectId"`
+	Total     timeTotalsDTO `json:"total"`
+}
+
+func (h *timeEntryHandler) projectTotals(c *gin.Context) {
+	var params timeEntryTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	total, err := h.entries(c).ProjectTotals(params.ID)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, projectTimeResp{
+		ProjectID: params.ID,
+		Total:     newTimeTotalsDTO(total),
+	})
+}
+
+type timeReportParams struct {
+	From     string                `form:"from" binding:"required"`
+	To       string                `form:"to" binding:"required"`
+	GroupBy  entity.TimeReportUnit `form:"groupBy" binding:"omitempty,oneof=day week"`
+	TimeZone string                `form:"tz"`
+	Project  *int                  `form:"project" binding:"omitempty,min=0"`
+	TodoID   int                   `form:"todoId" binding:"omitempty,min=1"`
+	UserID   int                   `form:"userId" binding:"omitempty,min=1"`
+	Format   string                `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+func (p timeReportParams) query() (entity.TimeReportQuery, error) {
+	query := entity.TimeReportQuery{
+		GroupBy:  entity.TimeReportDay,
+		Location: time.UTC,
+		Project:  p.Project,
+		TodoID:   p.TodoID,
+		UserID:   p.UserID,
+	}
+	if p.GroupBy != "" {
+		query.GroupBy = p.GroupBy
+	}
+	if p.TimeZone != "" {
+		loc, err := time.LoadLocation(p.TimeZone)
+		if err != nil {
+			return entity.TimeReportQuery{}, fmt.Errorf("invalid time zone %q", p.TimeZone)
+		}
+		query.Location = loc
+	}
+	var err error
+	if query.From, err = time.ParseInLocation(time.DateOnly, p.From, query.Location); err != nil {
+		return entity.TimeReportQuery{}, fmt.Errorf("invalid from date %q", p.From)
+	}
+	if query.To, err = time.ParseInLocation(time.DateOnly, p.To, query.Location); err != nil {
+		return entity.TimeReportQuery{}, fmt.Errorf("invalid to date %q", p.To)
+	}
+	return query, nil
+}
+
+type timeReportResp struct {
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	GroupBy      string             `json:"groupBy"`
+	TimeZone     string             `json:"tz"`
+	Rows         []timeReportRowDTO `json:"rows"`
+	TotalSeconds int64              `json:"totalSeconds"`
+}
+
+type timeReportRowDTO struct {
+	// Period is the day, or the Monday of the week, the row is for.
+	Period  string `json:"period"`
+	Seconds int64  `json:"seconds"`
+	Entries int    `json:"entries"`
+}
+
+// report serves the time tracked by day or week, as JSON or as CSV with
+// format=csv.
+func (h *timeEntryHandler) report(c *gin.Context) {
+	var params timeReportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.entries(c).Report(query)
+	if err != nil {
+		timeEntryError(c, err)
+		return
+	}
+	if params.Format == "csv" {
+		writeTimeReportCSV(c, params, report)
+		return
+	}
+
+	resp := timeReportResp{
+		From:         params.From,
+		To:           params.To,
+		GroupBy:      string(query.GroupBy),
+		TimeZone:     query.Location.String(),
+		Rows:         make([]timeReportRowDTO, len(report.Rows)),
+		TotalSeconds: seconds(report.Tracked),
+	}
+	for i, row := range report.Rows {
+		resp.Rows[i] = timeReportRowDTO{
+			Period:  row.Period.Format(time.DateOnly),
+			Seconds: seconds(row.Tracked),
+			Entries: row.Entries,
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// writeTimeReportCSV writes a row per period with the time in seconds and
+// in hours, for spreadsheets.
+func writeTimeReportCSV(c *gin.Context, params timeReportParams, report *entity.TimeReport) {
+	filename := fmt.Sprintf("time-report-%s-%s.csv", params.From, params.To)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"period", "seconds", "hours", "entries"})
+	for _, row := range report.Rows {
+		_ = w.Write([]string{
+			row.Period.Format(time.DateOnly),
+			strconv.FormatInt(seconds(row.Tracked), 10),
+			strconv.FormatFloat(row.Tracked.Hours(), 'f', 2, 64),
+			strconv.Itoa(row.Entries),
+		})
+	}
+	w.Flush()
+}
+
+// estimate turns the estimate of a request into a duration.
+func estimate(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+func toEstimate(seconds *int) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	return lo.ToPtr(estimate(*seconds))
+}
@@ -0,0 +1,437 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type timeEntrySuite struct {
+	suite.Suite
+	router  *gin.Engine
+	mockSrv *mocks.MockTimeEntry
+}
+
+func (s *timeEntrySuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockTimeEntry(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	timeNow = func() time.Time {
+		return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	}
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewTimeEntryRoutes(s.router.Group("v1"), s.mockSrv)
+}
+
+func (s *timeEntrySuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestTimeEntrySuite(t *testing.T) {
+	suite.Run(t, new(timeEntrySuite))
+}
+
+func (s *timeEntrySuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+var (
+	runningEntry = &entity.TimeEntry{
+		ID:        3,
+		TodoID:    2,
+		UserID:    1,
+		Start:     time.Date(2026, 10, 19, 11, 30, 0, 0, time.UTC),
+		CreatedAt: time.Date(2026, 10, 19, 11, 30, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 10, 19, 11, 30, 0, 0, time.UTC),
+	}
+	stoppedEntry = &entity.TimeEntry{
+		ID:        1,
+		TodoID:    5,
+		UserID:    1,
+		Start:     time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		End:       lo.ToPtr(time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)),
+		Note:      "review",
+		CreatedAt: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+	}
+)
+
+const (
+	runningEntryResp = `{"id": 3, "workspaceId": 0, "todoId": 2, "userId": 1, "start": "2026-10-19T11:30:00Z", "running": true, "seconds": 1800, "createdAt": "2026-10-19T11:30:00Z", "updatedAt": "2026-10-19T11:30:00Z"}`
+	stoppedEntryResp = `{"id": 1, "workspaceId": 0, "todoId": 5, "userId": 1, "start": "2026-10-19T09:00:00Z", "end": "2026-10-19T10:00:00Z", "running": false, "seconds": 3600, "note": "review", "createdAt": "2026-10-19T09:00:00Z", "updatedAt": "2026-10-19T10:00:00Z"}`
+)
+
+func (s *timeEntrySuite) TestRunning() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Running().Return(runningEntry, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/timer", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(runningEntryResp, w.Body.String())
+	})
+	s.Run("nothing running", func() {
+		s.mockSrv.EXPECT().Running().Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/timer", "")
+
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestStop() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Stop().Return(stoppedEntry, nil).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/timer/stop", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(stoppedEntryResp, w.Body.String())
+	})
+	s.Run("nothing running", func() {
+		s.mockSrv.EXPECT().Stop().Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/timer/stop", "")
+
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestStart() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"note": "review"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Start(2, "review").Return(runningEntry, nil, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"started": ` + runningEntryResp + `}`,
+		},
+		{
+			desc: "stops the running timer",
+			mock: func() {
+				s.mockSrv.EXPECT().Start(2, "").Return(runningEntry, stoppedEntry, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"started": ` + runningEntryResp + `, "stopped": ` + stoppedEntryResp + `}`,
+		},
+		{
+			desc:     "invalid body",
+			body:     `{"note": 1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "forbidden",
+			mock: func() {
+				s.mockSrv.EXPECT().Start(2, "").Return(nil, nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPost, "/v1/todos/2/timer", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp != "" {
+				s.JSONEq(tt.wantResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *timeEntrySuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			body: `{"start": "2026-10-19T09:00:00Z", "end": "2026-10-19T10:00:00Z", "note": "review"}`,
+			mock: func() {
+				start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+				end := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+				s.mockSrv.EXPECT().Create(5, start, end, "review").Return(stoppedEntry, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			desc:     "missing end",
+			body:     `{"start": "2026-10-19T09:00:00Z"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "ends before it starts",
+			body: `{"start": "2026-10-19T10:00:00Z", "end": "2026-10-19T09:00:00Z"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(5, gomock.Any(), gomock.Any(), "").Return(nil, service.ErrInvalidInput).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "todo not found",
+			body: `{"start": "2026-10-19T09:00:00Z", "end": "2026-10-19T10:00:00Z"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(5, gomock.Any(), gomock.Any(), "").Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPost, "/v1/todos/5/time-entries", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusCreated {
+				s.JSONEq(stoppedEntryResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *timeEntrySuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(5, entity.PageQuery{Limit: 1}).Return(&entity.Page[entity.TimeEntry]{
+			Items:      []entity.TimeEntry{*stoppedEntry},
+			NextCursor: "next",
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/5/time-entries?limit=1", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"items": [`+stoppedEntryResp+`], "nextCursor": "next"}`, w.Body.String())
+	})
+	s.Run("invalid cursor", func() {
+		s.mockSrv.EXPECT().List(5, entity.PageQuery{Cursor: "x"}).Return(nil, service.ErrInvalidInput).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/5/time-entries?cursor=x", "")
+
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestGet() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Get(5, 1).Return(stoppedEntry, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/5/time-entries/1", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(stoppedEntryResp, w.Body.String())
+	})
+	s.Run("invalid id", func() {
+		w := s.serve(http.MethodGet, "/v1/todos/5/time-entries/abc", "")
+
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+	s.Run("not found", func() {
+		s.mockSrv.EXPECT().Get(5, 1).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/5/time-entries/1", "")
+
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestUpdate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+	}{
+		{
+			desc: "success",
+			body: `{"end": "2026-10-19T10:00:00Z", "note": "review"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Update(5, 1, entity.UpdateTimeEntryInput{
+					End:  lo.ToPtr(time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)),
+					Note: lo.ToPtr("review"),
+				}).Return(stoppedEntry, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "invalid body",
+			body:     `{"start": "yesterday"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc: "someone else's entry",
+			body: `{"note": "review"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Update(5, 1, gomock.Any()).Return(nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := s.serve(http.MethodPatch, "/v1/todos/5/time-entries/1", tt.body)
+
+			s.Equal(tt.wantCode, w.Code)
+		})
+	}
+}
+
+func (s *timeEntrySuite) TestRemove() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Delete(5, 1).Return(nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/5/time-entries/1", "")
+
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("forbidden", func() {
+		s.mockSrv.EXPECT().Delete(5, 1).Return(service.ErrForbidden).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/todos/5/time-entries/1", "")
+
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestTotals() {
+	s.Run("todo", func() {
+		s.mockSrv.EXPECT().TodoTotals(2).Return(
+			entity.TimeTotals{Estimate: time.Hour, Tracked: 90 * time.Minute},
+			entity.TimeTotals{Estimate: 3 * time.Hour, Tracked: 2 * time.Hour},
+			nil,
+		).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/todos/2/time", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{
+			"todoId": 2,
+			"own": {"estimateSeconds": 3600, "trackedSeconds": 5400, "remainingSeconds": 0},
+			"withSubtasks": {"estimateSeconds": 10800, "trackedSeconds": 7200, "remainingSeconds": 3600}
+		}`, w.Body.String())
+	})
+	s.Run("project", func() {
+		s.mockSrv.EXPECT().ProjectTotals(4).Return(entity.TimeTotals{Tracked: time.Hour}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/projects/4/time", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{"projectId": 4, "total": {"estimateSeconds": 0, "trackedSeconds": 3600, "remainingSeconds": 0}}`, w.Body.String())
+	})
+	s.Run("project not found", func() {
+		s.mockSrv.EXPECT().ProjectTotals(4).Return(entity.TimeTotals{}, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/projects/4/time", "")
+
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
+
+func (s *timeEntrySuite) TestReport() {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	report := &entity.TimeReport{
+		Rows: []entity.TimeReportRow{
+			{Period: time.Date(2026, 10, 12, 0, 0, 0, 0, taipei), Tracked: 90 * time.Minute, Entries: 2},
+			{Period: time.Date(2026, 10, 19, 0, 0, 0, 0, taipei)},
+		},
+		Tracked: 90 * time.Minute,
+	}
+
+	s.Run("json", func() {
+		s.mockSrv.EXPECT().Report(entity.TimeReportQuery{
+			From:     time.Date(2026, 10, 12, 0, 0, 0, 0, taipei),
+			To:       time.Date(2026, 10, 25, 0, 0, 0, 0, taipei),
+			GroupBy:  entity.TimeReportWeek,
+			Location: taipei,
+			Project:  lo.ToPtr(0),
+			UserID:   3,
+		}).Return(report, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/time/report?from=2026-10-12&to=2026-10-25&groupBy=week&tz=Asia/Taipei&project=0&userId=3", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{
+			"from": "2026-10-12",
+			"to": "2026-10-25",
+			"groupBy": "week",
+			"tz": "Asia/Taipei",
+			"rows": [
+				{"period": "2026-10-12", "seconds": 5400, "entries": 2},
+				{"period": "2026-10-19", "seconds": 0, "entries": 0}
+			],
+			"totalSeconds": 5400
+		}`, w.Body.String())
+	})
+	s.Run("csv", func() {
+		s.mockSrv.EXPECT().Report(gomock.Any()).Return(report, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/time/report?from=2026-10-12&to=2026-10-25&groupBy=week&format=csv", "")
+
+		s.Equal(http.StatusOK, w.Code)
+		s.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		s.Equal("attachment; filename=time-report-2026-10-12-2026-10-25.csv", w.Header().Get("Content-Disposition"))
+		s.Equal("period,seconds,hours,entries\n2026-10-12,5400,1.50,2\n2026-10-19,0,0.00,0\n", w.Body.String())
+	})
+
+	tests := []struct {
+		desc  string
+		query string
+	}{
+		{desc: "missing to", query: "from=2026-10-12"},
+		{desc: "invalid from", query: "from=12/10/2026&to=2026-10-25"},
+		{desc: "unknown group", query: "from=2026-10-12&to=2026-10-25&groupBy=month"},
+		{desc: "unknown time zone", query: "from=2026-10-12&to=2026-10-25&tz=Mars/Olympus"},
+		{desc: "unknown format", query: "from=2026-10-12&to=2026-10-25&format=xml"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			w := s.serve(http.MethodGet, "/v1/time/report?"+tt.query, "")
+
+			s.Equal(http.StatusBadRequest, w.Code)
+		})
+	}
+	s.Run("range too long", func() {
+		s.mockSrv.EXPECT().Report(gomock.Any()).Return(nil, service.ErrInvalidInput).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/time/report?from=2025-01-01&to=2026-10-25", "")
+
+		s.Equal(http.StatusBadRequest, w.Code)
+	})
+}
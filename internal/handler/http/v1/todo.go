@@ -1,15 +1,23 @@
 package v1
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/jsonpatch"
 	"github.com/cloudingcity/todo/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+// defaultUpcomingDays is how far ahead the upcoming view looks when the
+// days parameter is omitted.
+const defaultUpcomingDays = 7
+
 type todoHandler struct {
 	srv service.Todo
 }
@@ -18,25 +26,59 @@ func NewTodoRoutes(rg *gin.RouterGroup, srv service.Todo) {
 	h := &todoHandler{
 		srv: srv,
 	}
-	rg.POST("/todos", h.create)
-	rg.GET("/todos", h.list)
-	rg.GET("/todos/:id", h.get)
-	rg.PATCH("/todos/:id", h.update)
-	rg.DELETE("/todos/:id", h.remove)
+	read := middleware.RequireScope(entity.ScopeTodosRead)
+	write := middleware.RequireScope(entity.ScopeTodosWrite)
+	rg.POST("/todos", write, h.create)
+	rg.POST("/todos:action", write, customMethods(h.customMethods()))
+	rg.GET("/todos", read, h.list)
+	rg.GET("/todos/:id", read, h.get)
+	rg.PUT("/todos/:id", write, h.replace)
+	rg.PATCH("/todos/:id", write, h.update)
+	rg.DELETE("/todos/:id", write, h.remove)
+	rg.POST("/todos/:id/move", write, h.move)
+	rg.PUT("/todos/:id/parent", write, h.setParent)
+	rg.GET("/todos/:id/subtree", read, h.subtree)
+	rg.POST("/todos/:id/dependencies", write, h.addDependency)
+	rg.GET("/todos/:id/dependencies", read, h.listDependencies)
+	rg.DELETE("/todos/:id/dependencies/:blockerId", write, h.removeDependency)
+	rg.GET("/todos/:id/occurrences", read, h.occurrences)
+}
+
+// todos returns the todo service as seen by the caller.
+func (h *todoHandler) todos(c *gin.Context) service.Todo {
+	return h.srv.ForWorkspace(middleware.WorkspaceID(c)).As(middleware.CurrentActor(c))
 }
 
 type createTodoReq struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Priority    entity.Priority `json:"priority" binding:"omitempty,oneof=none low medium high urgent"`
+	ParentID    int             `json:"parentId" binding:"omitempty,min=1"`
+	Rollup      entity.Rollup   `json:"rollup" binding:"omitempty,oneof=none auto cascade both"`
+	Start       *dateTimeDTO    `json:"start"`
+	Due         *dateTimeDTO    `json:"due"`
+	Recurrence  recurrenceDTO   `json:"recurrence"`
+	Estimate    int             `json:"estimateSeconds" binding:"min=0"`
 }
 
 type createTodoResp struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	IsCompleted bool      `json:"isCompleted"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
 }
 
 func (h *todoHandler) create(c *gin.Context) {
@@ -47,8 +89,24 @@ func (h *todoHandler) create(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.srv.Create(req.Title, req.Description)
-	if err != nil {
+	todo, err := h.todos(c).Create(entity.CreateTodoInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		ParentID:    req.ParentID,
+		Rollup:      req.Rollup,
+		Start:       toDateTime(req.Start),
+		Due:         toDateTime(req.Due),
+		Recurrence:  req.Recurrence.rule(),
+		Estimate:    estimate(req.Estimate),
+	})
+	if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -58,22 +116,92 @@ func (h *todoHandler) create(c *gin.Context) {
 		Title:       todo.Title,
 		Description: todo.Description,
 		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
 		CreatedAt:   todo.CreatedAt,
 		UpdatedAt:   todo.UpdatedAt,
 	})
 }
 
 type listTodoResp struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	IsCompleted bool      `json:"isCompleted"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	// Checklist is the progress of the checklist, like 3/5.
+	Checklist  string        `json:"checklist,omitempty"`
+	Start      *dateTimeDTO  `json:"start,omitempty"`
+	Due        *dateTimeDTO  `json:"due,omitempty"`
+	Recurrence recurrenceDTO `json:"recurrence,omitempty"`
+	Estimate   int64         `json:"estimateSeconds,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+type listTodoParams struct {
+	Project  *int             `form:"project" binding:"omitempty,min=0"`
+	Tags     string           `form:"tags"`
+	View     entity.DueView   `form:"view" binding:"omitempty,oneof=overdue today upcoming"`
+	Days     int              `form:"days" binding:"omitempty,min=1,max=365"`
+	TimeZone string           `form:"tz"`
+	Order    entity.TodoOrder `form:"order" binding:"omitempty,oneof=created manual priority due topological"`
+}
+
+// query builds the todo query shared by every endpoint listing todos.
+func (p listTodoParams) query() (entity.TodoQuery, error) {
+	query := entity.TodoQuery{
+		Project:  p.Project,
+		View:     p.View,
+		Days:     cmp.Or(p.Days, defaultUpcomingDays),
+		Location: time.UTC,
+		Order:    p.Order,
+	}
+	if p.Tags != "" {
+		expr, err := entity.ParseTagExpr(p.Tags)
+		if err != nil {
+			return entity.TodoQuery{}, err
+		}
+		query.Tags = expr
+	}
+	if p.TimeZone != "" {
+		loc, err := time.LoadLocation(p.TimeZone)
+		if err != nil {
+			return entity.TodoQuery{}, fmt.Errorf("invalid time zone %q", p.TimeZone)
+		}
+		query.Location = loc
+	}
+	return query, nil
 }
 
 func (h *todoHandler) list(c *gin.Context) {
-	todos, err := h.srv.List()
+	var params listTodoParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, err := params.query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.todos(c).List(query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -86,6 +214,18 @@ func (h *todoHandler) list(c *gin.Context) {
 			Title:       todo.Title,
 			Description: todo.Description,
 			IsCompleted: todo.IsCompleted,
+			Blocked:     todo.Blocked,
+			Priority:    todo.Priority,
+			ProjectID:   todo.ProjectID,
+			ParentID:    todo.ParentID,
+			Rollup:      todo.Rollup,
+			Rank:        todo.Rank,
+			Tags:        todo.Tags,
+			Checklist:   todo.Checklist.String(),
+			Start:       newDateTimeDTO(todo.Start),
+			Due:         newDateTimeDTO(todo.Due),
+			Recurrence:  newRecurrenceDTO(todo.Recurrence),
+			Estimate:    seconds(todo.Estimate),
 			CreatedAt:   todo.CreatedAt,
 			UpdatedAt:   todo.UpdatedAt,
 		}
@@ -98,12 +238,27 @@ type getTodoReq struct {
 }
 
 type getTodoResp struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	IsCompleted bool      `json:"isCompleted"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	// Checklist is the progress of the checklist, like 3/5, and
+	// ChecklistItems its items.
+	Checklist      string             `json:"checklist,omitempty"`
+	ChecklistItems []checklistItemDTO `json:"checklistItems,omitempty"`
+	Start          *dateTimeDTO       `json:"start,omitempty"`
+	Due            *dateTimeDTO       `json:"due,omitempty"`
+	Recurrence     recurrenceDTO      `json:"recurrence,omitempty"`
+	Estimate       int64              `json:"estimateSeconds,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
 }
 
 func (h *todoHandler) get(c *gin.Context) {
@@ -113,7 +268,7 @@ func (h *todoHandler) get(c *gin.Context) {
 		return
 	}
 
-	todo, err := h.srv.Get(req.ID)
+	todo, err := h.todos(c).Get(req.ID)
 	if errors.Is(err, service.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -123,20 +278,43 @@ func (h *todoHandler) get(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, getTodoResp{
-		ID:          todo.ID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		IsCompleted: todo.IsCompleted,
-		CreatedAt:   todo.CreatedAt,
-		UpdatedAt:   todo.UpdatedAt,
+		ID:             todo.ID,
+		Title:          todo.Title,
+		Description:    todo.Description,
+		IsCompleted:    todo.IsCompleted,
+		Blocked:        todo.Blocked,
+		Priority:       todo.Priority,
+		ProjectID:      todo.ProjectID,
+		ParentID:       todo.ParentID,
+		Rollup:         todo.Rollup,
+		Rank:           todo.Rank,
+		Tags:           todo.Tags,
+		Checklist:      todo.Checklist.String(),
+		ChecklistItems: checklistItems(todo.Checklist),
+		Start:          newDateTimeDTO(todo.Start),
+		Due:            newDateTimeDTO(todo.Due),
+		Recurrence:     newRecurrenceDTO(todo.Recurrence),
+		Estimate:       seconds(todo.Estimate),
+		CreatedAt:      todo.CreatedAt,
+		UpdatedAt:      todo.UpdatedAt,
 	})
 }
 
 type updateTodoReq struct {
-	ID          int     `uri:"id" binding:"required"`
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	IsCompleted *bool   `json:"isCompleted"`
+	ID          int              `uri:"id" binding:"required"`
+	Title       *string          `json:"title"`
+	Description *string          `json:"description"`
+	IsCompleted *bool            `json:"isCompleted"`
+	Priority    *entity.Priority `json:"priority" binding:"omitempty,oneof=none low medium high urgent"`
+	Rollup      *entity.Rollup   `json:"rollup" binding:"omitempty,oneof=none auto cascade both"`
+	Start       *dateTimeDTO     `json:"start"`
+	Due         *dateTimeDTO     `json:"due"`
+	Recurrence  *recurrenceDTO   `json:"recurrence"`
+	Estimate    *int             `json:"estimateSeconds" binding:"omitempty,min=0"`
+}
+
+type updateTodoParams struct {
+	Force bool `form:"force"`
 }
 
 func (h *todoHandler) update(c *gin.Context) {
@@ -145,6 +323,16 @@ func (h *todoHandler) update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var params updateTodoParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch c.ContentType() {
+	case jsonpatch.MergePatchType, jsonpatch.JSONPatchType:
+		h.patch(c, req.ID, params.Force)
+		return
+	}
 	if err := c.ShouldBind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -154,10 +342,26 @@ func (h *todoHandler) update(c *gin.Context) {
 		Title:       req.Title,
 		Description: req.Description,
 		IsCompleted: req.IsCompleted,
+		Priority:    req.Priority,
+		Rollup:      req.Rollup,
+		Start:       toDateTime(req.Start),
+		Due:         toDateTime(req.Due),
+		Recurrence:  toRecurrenceUpdate(req.Recurrence),
+		Estimate:    toEstimate(req.Estimate),
+		Force:       params.Force,
 	}
-	if err := h.srv.Update(req.ID, input); errors.Is(err, service.ErrNotFound) {
+	if err := h.todos(c).Update(req.ID, input); errors.Is(err, service.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -166,20 +370,158 @@ func (h *todoHandler) update(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+type replaceTodoParams struct {
+	ID     int  `uri:"id" binding:"required,min=1,max=2147483647"`
+	Upsert bool `form:"upsert"`
+	Force  bool `form:"force"`
+}
+
+type replaceTodoReq struct {
+	Title       *string         `json:"title" binding:"required"`
+	Description *string         `json:"description" binding:"required"`
+	IsCompleted *bool           `json:"isCompleted" binding:"required"`
+	Priority    entity.Priority `json:"priority" binding:"omitempty,oneof=none low medium high urgent"`
+	Rollup      entity.Rollup   `json:"rollup" binding:"omitempty,oneof=none auto cascade both"`
+	Start       *dateTimeDTO    `json:"start"`
+	Due         *dateTimeDTO    `json:"due"`
+	Recurrence  recurrenceDTO   `json:"recurrence"`
+	Estimate    int             `json:"estimateSeconds" binding:"min=0"`
+}
+
+type replaceTodoResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func (h *todoHandler) replace(c *gin.Context) {
+	var params replaceTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req replaceTodoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := entity.ReplaceTodoInput{
+		Title:       *req.Title,
+		Description: *req.Description,
+		IsCompleted: *req.IsCompleted,
+		Priority:    req.Priority,
+		Rollup:      req.Rollup,
+		Start:       toDateTime(req.Start),
+		Due:         toDateTime(req.Due),
+		Recurrence:  req.Recurrence.rule(),
+		Estimate:    estimate(req.Estimate),
+		Force:       params.Force,
+	}
+
+	var (
+		todo    *entity.Todo
+		created bool
+		err     error
+	)
+	if params.Upsert {
+		todo, created, err = h.todos(c).Upsert(params.ID, input)
+	} else {
+		todo, err = h.todos(c).Replace(params.ID, input)
+	}
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	c.JSON(code, replaceTodoResp{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	})
+}
+
 type removeTodoReq struct {
 	ID int `uri:"id" binding:"required"`
 }
 
+type removeTodoParams struct {
+	Subtasks entity.SubtaskDeleteMode `form:"subtasks" binding:"omitempty,oneof=cascade promote block"`
+}
+
 func (h *todoHandler) remove(c *gin.Context) {
 	var req removeTodoReq
 	if err := c.ShouldBindUri(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var params removeTodoParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if err := h.srv.Delete(req.ID); errors.Is(err, service.ErrNotFound) {
+	mode := params.Subtasks
+	if mode == "" {
+		mode = entity.SubtaskDeleteCascade
+	}
+	if err := h.todos(c).Delete(req.ID, mode); errors.Is(err, service.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -187,3 +529,84 @@ func (h *todoHandler) remove(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+type moveTodoParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type moveTodoReq struct {
+	Before *int `json:"before" binding:"omitempty,min=1"`
+	After  *int `json:"after" binding:"omitempty,min=1"`
+}
+
+type moveTodoResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func (h *todoHandler) move(c *gin.Context) {
+	var params moveTodoParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req moveTodoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Before == nil) == (req.After == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of before and after is required"})
+		return
+	}
+
+	todo, err := h.todos(c).Move(params.ID, entity.MoveTodoInput{Before: req.Before, After: req.After})
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, moveTodoResp{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Blocked:     todo.Blocked,
+		Priority:    todo.Priority,
+		ProjectID:   todo.ProjectID,
+		ParentID:    todo.ParentID,
+		Rollup:      todo.Rollup,
+		Rank:        todo.Rank,
+		Tags:        todo.Tags,
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    seconds(todo.Estimate),
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	})
+}
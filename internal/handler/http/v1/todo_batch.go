@@ -0,0 +1,271 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// customMethods dispatches custom methods such as POST /todos:batchCreate.
+// gin cannot register those as static routes next to /todos, so they share a
+// single /todos:action route.
+func customMethods(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, ok := methods[strings.TrimPrefix(c.Param("action"), ":")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		method(c)
+	}
+}
+
+// TodoCustomMethods names the custom methods the /todos:action route
+// dispatches, which are documented under their literal paths.
+func TodoCustomMethods() []string {
+	return slices.Sorted(maps.Keys((&todoHandler{}).customMethods()))
+}
+
+func (h *todoHandler) customMethods() map[string]gin.HandlerFunc {
+	return map[string]gin.HandlerFunc{
+		"batchCreate": h.batchCreate,
+		"batchUpdate": h.batchUpdate,
+		"batchDelete": h.batchDelete,
+	}
+}
+
+type batchReq struct {
+	Mode  entity.BatchMode  `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+	Items []json.RawMessage `json:"items" binding:"required,min=1,max=100"`
+}
+
+type batchCreateTodoReq struct {
+	Mode  string          `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+	Items []createTodoReq `json:"items" binding:"required,min=1,max=100"`
+}
+
+type batchUpdateTodoItem struct {
+	ID          int              `json:"id" binding:"required,min=1"`
+	Title       *string          `json:"title"`
+	Description *string          `json:"description"`
+	IsCompleted *bool            `json:"isCompleted"`
+	Priority    *entity.Priority `json:"priority" binding:"omitempty,oneof=none low medium high urgent"`
+	Rollup      *entity.Rollup   `json:"rollup" binding:"omitempty,oneof=none auto cascade both"`
+	Start       *dateTimeDTO     `json:"start"`
+	Due         *dateTimeDTO     `json:"due"`
+	Recurrence  *recurrenceDTO   `json:"recurrence"`
+	Estimate    *int             `json:"estimateSeconds" binding:"omitempty,min=0"`
+	Force       bool             `json:"force"`
+}
+
+type batchUpdateTodoReq struct {
+	Mode  string                `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+	Items []batchUpdateTodoItem `json:"items" binding:"required,min=1,max=100"`
+}
+
+type batchDeleteTodoItem struct {
+	ID int `json:"id" binding:"required,min=1"`
+}
+
+type batchDeleteTodoReq struct {
+	Mode  string                `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+	Items []batchDeleteTodoItem `json:"items" binding:"required,min=1,max=100"`
+}
+
+type batchTodoResp struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Blocked     bool            `json:"blocked,omitempty"`
+	Priority    entity.Priority `json:"priority,omitempty"`
+	ProjectID   int             `json:"projectId,omitempty"`
+	ParentID    int             `json:"parentId,omitempty"`
+	Rollup      entity.Rollup   `json:"rollup,omitempty"`
+	Rank        string          `json:"rank,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Start       *dateTimeDTO    `json:"start,omitempty"`
+	Due         *dateTimeDTO    `json:"due,omitempty"`
+	Recurrence  recurrenceDTO   `json:"recurrence,omitempty"`
+	Estimate    int64           `json:"estimateSeconds,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+type batchItemResp struct {
+	Index  int            `json:"index"`
+	Status int            `json:"status"`
+	Todo   *batchTodoResp `json:"todo,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+type batchResp struct {
+	Items []batchItemResp `json:"items"`
+}
+
+type batchErrorResp struct {
+	Error string `json:"error"`
+	Index int    `json:"index"`
+}
+
+// batch binds every item on its own so best-effort requests can report
+// invalid items individually, then hands the valid ones to run.
+func batch[T any](c *gin.Context, successStatus int, run func(items []T, mode entity.BatchMode) ([]entity.BatchResult, error)) {
+	var req batchReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = entity.BatchAtomic
+	}
+
+	resp := batchResp{Items: make([]batchItemResp, len(req.Items))}
+	items := make([]T, 0, len(req.Items))
+	indexes := make([]int, 0, len(req.Items))
+	for i, raw := range req.Items {
+		var item T
+		if err := binding.JSON.BindBody(raw, &item); err != nil {
+			if req.Mode == entity.BatchAtomic {
+				c.JSON(http.StatusBadRequest, batchErrorResp{Error: err.Error(), Index: i})
+				return
+			}
+			resp.Items[i] = batchItemResp{Index: i, Status: http.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+		items = append(items, item)
+		indexes = append(indexes, i)
+	}
+
+	results, err := run(items, req.Mode)
+	var batchErr *service.BatchError
+	if errors.As(err, &batchErr) {
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(batchErr, service.ErrNotFound):
+			code = http.StatusNotFound
+		case errors.Is(batchErr, service.ErrForbidden):
+			code = http.StatusForbidden
+		case errors.Is(batchErr, service.ErrInvalidInput):
+			code = http.StatusBadRequest
+		case errors.Is(batchErr, service.ErrConflict):
+			code = http.StatusConflict
+		}
+		c.JSON(code, batchErrorResp{Error: batchErr.Error(), Index: indexes[batchErr.Index]})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i, result := range results {
+		item := batchItemResp{Index: indexes[i], Status: successStatus}
+		switch {
+		case errors.Is(result.Err, service.ErrNotFound):
+			item.Status = http.StatusNotFound
+			item.Error = result.Err.Error()
+		case errors.Is(result.Err, service.ErrForbidden):
+			item.Status = http.StatusForbidden
+			item.Error = result.Err.Error()
+		case errors.Is(result.Err, service.ErrInvalidInput):
+			item.Status = http.StatusBadRequest
+			item.Error = result.Err.Error()
+		case errors.Is(result.Err, service.ErrConflict):
+			item.Status = http.StatusConflict
+			item.Error = result.Err.Error()
+		case result.Err != nil:
+			item.Status = http.StatusInternalServerError
+			item.Error = result.Err.Error()
+		case result.Todo != nil:
+			item.Todo = &batchTodoResp{
+				ID:          result.Todo.ID,
+				Title:       result.Todo.Title,
+				Description: result.Todo.Description,
+				IsCompleted: result.Todo.IsCompleted,
+				Blocked:     result.Todo.Blocked,
+				Priority:    result.Todo.Priority,
+				ProjectID:   result.Todo.ProjectID,
+				ParentID:    result.Todo.ParentID,
+				Rollup:      result.Todo.Rollup,
+				Rank:        result.Todo.Rank,
+				Tags:        result.Todo.Tags,
+				Start:       newDateTimeDTO(result.Todo.Start),
+				Due:         newDateTimeDTO(result.Todo.Due),
+				Recurrence:  newRecurrenceDTO(result.Todo.Recurrence),
+				Estimate:    seconds(result.Todo.Estimate),
+				CreatedAt:   result.Todo.CreatedAt,
+				UpdatedAt:   result.Todo.UpdatedAt,
+			}
+		}
+		resp.Items[indexes[i]] = item
+	}
+
+	if req.Mode == entity.BatchBestEffort {
+		c.JSON(http.StatusMultiStatus, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *todoHandler) batchCreate(c *gin.Context) {
+	batch(c, http.StatusCreated, func(items []createTodoReq, mode entity.BatchMode) ([]entity.BatchResult, error) {
+		inputs := make([]entity.CreateTodoInput, len(items))
+		for i, item := range items {
+			inputs[i] = entity.CreateTodoInput{
+				Title:       item.Title,
+				Description: item.Description,
+				Priority:    item.Priority,
+				ParentID:    item.ParentID,
+				Rollup:      item.Rollup,
+				Start:       toDateTime(item.Start),
+				Due:         toDateTime(item.Due),
+				Recurrence:  item.Recurrence.rule(),
+				Estimate:    estimate(item.Estimate),
+			}
+		}
+		return h.todos(c).BatchCreate(inputs, mode)
+	})
+}
+
+func (h *todoHandler) batchUpdate(c *gin.Context) {
+	batch(c, http.StatusNoContent, func(items []batchUpdateTodoItem, mode entity.BatchMode) ([]entity.BatchResult, error) {
+		inputs := make([]entity.BatchUpdateTodoInput, len(items))
+		for i, item := range items {
+			inputs[i] = entity.BatchUpdateTodoInput{
+				ID: item.ID,
+				Input: entity.UpdateTodoInput{
+					Title:       item.Title,
+					Description: item.Description,
+					IsCompleted: item.IsCompleted,
+					Priority:    item.Priority,
+					Rollup:      item.Rollup,
+					Start:       toDateTime(item.Start),
+					Due:         toDateTime(item.Due),
+					Recurrence:  toRecurrenceUpdate(item.Recurrence),
+					Estimate:    toEstimate(item.Estimate),
+					Force:       item.Force,
+				},
+			}
+		}
+		return h.todos(c).BatchUpdate(inputs, mode)
+	})
+}
+
+func (h *todoHandler) batchDelete(c *gin.Context) {
+	batch(c, http.StatusNoContent, func(items []batchDeleteTodoItem, mode entity.BatchMode) ([]entity.BatchResult, error) {
+		ids := make([]int, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+		return h.todos(c).BatchDelete(ids, mode)
+	})
+}
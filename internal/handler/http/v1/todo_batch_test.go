@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *todoSuite) TestBatchCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "atomic",
+			body: `{"items": [{"title": "title-1", "description": "desc-1"}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchCreate([]entity.CreateTodoInput{
+					{Title: "title-1", Description: "desc-1"},
+				}, entity.BatchAtomic).Return([]entity.BatchResult{
+					{Todo: &entity.Todo{
+						ID:          1,
+						Title:       "title-1",
+						Description: "desc-1",
+						CreatedAt:   time.Unix(123456789, 0),
+						UpdatedAt:   time.Unix(123456789, 0),
+					}},
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"items": [{
+				"index": 0,
+				"status": 201,
+				"todo": {
+				  "id": 1,
+				  "title": "title-1",
+				  "description": "desc-1",
+				  "isCompleted": false,
+				  "createdAt": "1973-11-30T05:33:09+08:00",
+				  "updatedAt": "1973-11-30T05:33:09+08:00"
+				}
+			}]}`,
+		},
+		{
+			desc:     "atomic invalid item",
+			body:     `{"items": [{"title": "title-1"}, {"title": 1}]}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"index": 1, "error": "json: cannot unmarshal number into Go struct field createTodoReq.title of type string"}`,
+		},
+		{
+			desc: "best effort invalid item",
+			body: `{"mode": "bestEffort", "items": [{"title": 1}, {"title": "title-2"}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchCreate([]entity.CreateTodoInput{
+					{Title: "title-2"},
+				}, entity.BatchBestEffort).Return([]entity.BatchResult{
+					{Err: errors.New("something wrong")},
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusMultiStatus,
+			wantResp: `{"items": [
+				{"index": 0, "status": 400, "error": "json: cannot unmarshal number into Go struct field createTodoReq.title of type string"},
+				{"index": 1, "status": 500, "error": "something wrong"}
+			]}`,
+		},
+		{
+			desc:     "invalid mode",
+			body:     `{"mode": "sometimes", "items": [{"title": "title-1"}]}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'batchReq.Mode' Error:Field validation for 'Mode' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc:     "empty items",
+			body:     `{"items": []}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'batchReq.Items' Error:Field validation for 'Items' failed on the 'min' tag"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/todos:batchCreate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchUpdate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "atomic",
+			body: `{"mode": "atomic", "items": [{"id": 1, "isCompleted": true}, {"id": 2, "title": "title-2"}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchUpdate([]entity.BatchUpdateTodoInput{
+					{ID: 1, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+					{ID: 2, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title-2")}},
+				}, entity.BatchAtomic).Return(make([]entity.BatchResult, 2), nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"items": [{"index": 0, "status": 204}, {"index": 1, "status": 204}]}`,
+		},
+		{
+			desc: "atomic not found",
+			body: `{"items": [{"id": 1, "isCompleted": true}, {"id": 2, "isCompleted": true}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchUpdate(gomock.Any(), entity.BatchAtomic).
+					Return(nil, &service.BatchError{Index: 1, Err: service.ErrNotFound}).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"index": 1, "error": "item 1: not found"}`,
+		},
+		{
+			desc: "best effort not found",
+			body: `{"mode": "bestEffort", "items": [{"id": 1, "isCompleted": true}, {"id": 2, "isCompleted": true}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchUpdate(gomock.Any(), entity.BatchBestEffort).Return([]entity.BatchResult{
+					{},
+					{Err: service.ErrNotFound},
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusMultiStatus,
+			wantResp: `{"items": [{"index": 0, "status": 204}, {"index": 1, "status": 404, "error": "not found"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/todos:batchUpdate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchDelete() {
+	tests := []struct {
+		desc     string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "atomic",
+			path: "/v1/todos:batchDelete",
+			body: `{"items": [{"id": 1}, {"id": 2}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchDelete([]int{1, 2}, entity.BatchAtomic).Return(make([]entity.BatchResult, 2), nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{"items": [{"index": 0, "status": 204}, {"index": 1, "status": 204}]}`,
+		},
+		{
+			desc: "atomic failed",
+			path: "/v1/todos:batchDelete",
+			body: `{"items": [{"id": 1}]}`,
+			mock: func() {
+				s.mockSrv.EXPECT().BatchDelete([]int{1}, entity.BatchAtomic).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+		{
+			desc:     "unknown custom method",
+			path:     "/v1/todos:batchArchive",
+			body:     `{"items": [{"id": 1}]}`,
+			mock:     func() {},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/jsonpatch"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// todoPatchDoc is the JSON document merge and JSON patches operate on. Only
+// the fields clients may change are exposed, so patches touching anything
+// else fail with a path error.
+type todoPatchDoc struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	IsCompleted bool            `json:"isCompleted"`
+	Priority    entity.Priority `json:"priority"`
+	Rollup      entity.Rollup   `json:"rollup"`
+	Start       *dateTimeDTO    `json:"start"`
+	Due         *dateTimeDTO    `json:"due"`
+	Recurrence  recurrenceDTO   `json:"recurrence"`
+	Estimate    int             `json:"estimateSeconds"`
+}
+
+// patch applies a merge or JSON patch. force lets the patch complete a
+// blocked todo.
+func (h *todoHandler) patch(c *gin.Context, id int, force bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var apply func(doc any) (any, error)
+	switch c.ContentType() {
+	case jsonpatch.MergePatchType:
+		var patch any
+		if err := json.Unmarshal(body, &patch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		apply = func(doc any) (any, error) {
+			return jsonpatch.MergePatch(doc, patch), nil
+		}
+	case jsonpatch.JSONPatchType:
+		patch, err := jsonpatch.Decode(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		apply = patch.Apply
+	}
+
+	err = h.todos(c).UpdateFunc(id, func(todo *entity.Todo) error {
+		if force {
+			todo.Blocked = false
+		}
+		return patchTodo(todo, apply)
+	})
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, jsonpatch.ErrTestFailed), errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, jsonpatch.ErrInvalid), errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func patchTodo(todo *entity.Todo, apply func(doc any) (any, error)) error {
+	b, err := json.Marshal(todoPatchDoc{
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		Priority:    cmp.Or(todo.Priority, entity.PriorityNone),
+		Rollup:      cmp.Or(todo.Rollup, entity.RollupNone),
+		Start:       newDateTimeDTO(todo.Start),
+		Due:         newDateTimeDTO(todo.Due),
+		Recurrence:  newRecurrenceDTO(todo.Recurrence),
+		Estimate:    int(seconds(todo.Estimate)),
+	})
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	doc, err = apply(doc)
+	if err != nil {
+		return err
+	}
+	if _, ok := doc.(map[string]any); !ok {
+		return fmt.Errorf("%w: result must be an object", jsonpatch.ErrInvalid)
+	}
+
+	// Fields removed by the patch decode to their zero value, which is how a
+	// client clears the description or a date.
+	b, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var patched todoPatchDoc
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patched); err != nil {
+		return fmt.Errorf("%w: %w", jsonpatch.ErrInvalid, err)
+	}
+	if !patched.Priority.Valid() {
+		return fmt.Errorf("%w: unknown priority %q", jsonpatch.ErrInvalid, patched.Priority)
+	}
+	if !patched.Rollup.Valid() {
+		return fmt.Errorf("%w: unknown rollup %q", jsonpatch.ErrInvalid, patched.Rollup)
+	}
+
+	todo.Title = patched.Title
+	todo.Description = patched.Description
+	todo.IsCompleted = patched.IsCompleted
+	todo.Priority = patched.Priority
+	todo.Rollup = patched.Rollup
+	todo.Start = toDateTime(patched.Start)
+	todo.Due = toDateTime(patched.Due)
+	todo.Recurrence = patched.Recurrence.rule()
+	todo.Estimate = estimate(patched.Estimate)
+	return nil
+}
@@ -27,6 +27,8 @@ type todoSuite struct {
 func (s *todoSuite) SetupSubTest() {
 	ctrl := gomock.NewController(s.T())
 	s.mockSrv = mocks.NewMockTodo(ctrl)
+	s.mockSrv.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockSrv).AnyTimes()
+	s.mockSrv.EXPECT().As(gomock.Any()).Return(s.mockSrv).AnyTimes()
 
 	gin.SetMode(gin.TestMode)
 	s.router = gin.Default()
@@ -49,7 +51,7 @@ func (s *todoSuite) TestCreate() {
 			desc: "success",
 			body: `{"title": "title-1", "description": "desc-1"}`,
 			mock: func() {
-				s.mockSrv.EXPECT().Create("title-1", "desc-1").Return(&entity.Todo{
+				s.mockSrv.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"}).Return(&entity.Todo{
 					ID:          999,
 					Title:       "title-1",
 					Description: "desc-1",
@@ -68,6 +70,37 @@ func (s *todoSuite) TestCreate() {
 			  "updatedAt": "1973-11-30T05:33:09+08:00"
 			}`,
 		},
+		{
+			desc: "with estimate",
+			body: `{"title": "title-1", "estimateSeconds": 5400}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Estimate: 90 * time.Minute}).Return(&entity.Todo{
+					ID:        999,
+					Title:     "title-1",
+					Estimate:  90 * time.Minute,
+					CreatedAt: time.Unix(123456789, 0),
+					UpdatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "id": 999,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "estimateSeconds": 5400,
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "negative estimate",
+			body:     `{"title": "title-1", "estimateSeconds": -1}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{
+				"error": "Key: 'createTodoReq.Estimate' Error:Field validation for 'Estimate' failed on the 'min' tag"
+			}`,
+		},
 		{
 			desc: "invalid request",
 			body: `{"title": 999, "description": 0}`,
@@ -82,7 +115,7 @@ func (s *todoSuite) TestCreate() {
 			desc: "service create failed",
 			body: `{"title": "title-1", "description": "desc-1"}`,
 			mock: func() {
-				s.mockSrv.EXPECT().Create("title-1", "desc-1").Return(nil, errors.New("something wrong")).Times(1)
+				s.mockSrv.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"}).Return(nil, errors.New("something wrong")).Times(1)
 			},
 			wantCode: http.StatusInternalServerError,
 			wantResp: `{
@@ -118,7 +151,7 @@ func (s *todoSuite) TestList() {
 		{
 			desc: "success",
 			mock: func() {
-				s.mockSrv.EXPECT().List().Return([]entity.Todo{
+				s.mockSrv.EXPECT().List(entity.TodoQuery{Days: 7, Location: time.UTC}).Return([]entity.Todo{
 					{
 						ID:          1,
 						Title:       "title-1",
@@ -176,7 +209,7 @@ func (s *todoSuite) TestList() {
 		{
 			desc: "service list failed",
 			mock: func() {
-				s.mockSrv.EXPECT().List().Return(nil, errors.New("something wrong")).Times(1)
+				s.mockSrv.EXPECT().List(entity.TodoQuery{Days: 7, Location: time.UTC}).Return(nil, errors.New("something wrong")).Times(1)
 			},
 			wantCode: http.StatusInternalServerError,
 			wantResp: `{
@@ -342,6 +375,18 @@ func (s *todoSuite) TestUpdate() {
 				"error": "not found"
 			}`,
 		},
+		{
+			desc: "forbidden",
+			id:   "1",
+			body: `{"title": "title-1"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("title-1")}).Return(service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: `{
+				"error": "forbidden"
+			}`,
+		},
 		{
 			desc: "service update failed",
 			id:   "1",
@@ -391,11 +436,37 @@ func (s *todoSuite) TestDelete() {
 			desc: "success",
 			id:   "1",
 			mock: func() {
-				s.mockSrv.EXPECT().Delete(1).Return(nil).Times(1)
+				s.mockSrv.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(nil).Times(1)
 			},
 			wantCode: http.StatusNoContent,
 			wantResp: "",
 		},
+		{
+			desc: "promote subtasks",
+			id:   "1?subtasks=promote",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.SubtaskDeletePromote).Return(nil).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+			wantResp: "",
+		},
+		{
+			desc: "block with subtasks",
+			id:   "1?subtasks=block",
+			mock: func() {
+				s.mockSrv.EXPECT().Delete(1, entity.SubtaskDeleteBlock).Return(fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrHasSubtasks)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{
+				"error": "conflict: todo has subtasks"
+			}`,
+		},
+		{
+			desc:     "unknown subtasks mode",
+			id:       "1?subtasks=orphan",
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+		},
 		{
 			desc:     "wrong id",
 			id:       "wrong-id",
@@ -409,7 +480,7 @@ func (s *todoSuite) TestDelete() {
 			desc: "not found",
 			id:   "1",
 			mock: func() {
-				s.mockSrv.EXPECT().Delete(1).Return(service.ErrNotFound).Times(1)
+				s.mockSrv.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(service.ErrNotFound).Times(1)
 			},
 			wantCode: http.StatusNotFound,
 			wantResp: `{
@@ -420,7 +491,7 @@ func (s *todoSuite) TestDelete() {
 			desc: "service delete failed",
 			id:   "1",
 			mock: func() {
-				s.mockSrv.EXPECT().Delete(1).Return(errors.New("something wrong")).Times(1)
+				s.mockSrv.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(errors.New("something wrong")).Times(1)
 			},
 			wantCode: http.StatusInternalServerError,
 			wantResp: `{
@@ -443,3 +514,616 @@ func (s *todoSuite) TestDelete() {
 		})
 	}
 }
+
+func (s *todoSuite) TestPatch() {
+	current := entity.Todo{
+		ID:          1,
+		Title:       "title-1",
+		Description: "desc-1",
+		IsCompleted: false,
+		Priority:    entity.PriorityNone,
+		Rollup:      entity.RollupNone,
+	}
+	applyTo := func(todo entity.Todo, want *entity.Todo) func(int, func(*entity.Todo) error) error {
+		return func(_ int, fn func(*entity.Todo) error) error {
+			if err := fn(&todo); err != nil {
+				return err
+			}
+			s.Equal(*want, todo)
+			return nil
+		}
+	}
+
+	tests := []struct {
+		desc        string
+		contentType string
+		body        string
+		mock        func()
+		wantCode    int
+		wantResp    string
+	}{
+		{
+			desc:        "merge patch clears description",
+			contentType: "application/merge-patch+json",
+			body:        `{"description": null, "isCompleted": true}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, &entity.Todo{
+					ID:          1,
+					Title:       "title-1",
+					Description: "",
+					IsCompleted: true,
+					Priority:    entity.PriorityNone,
+					Rollup:      entity.RollupNone,
+				})).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:        "merge patch sets rollup",
+			contentType: "application/merge-patch+json",
+			body:        `{"rollup": "auto"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, &entity.Todo{
+					ID:          1,
+					Title:       "title-1",
+					Description: "desc-1",
+					Priority:    entity.PriorityNone,
+					Rollup:      entity.RollupAuto,
+				})).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:        "merge patch unknown rollup",
+			contentType: "application/merge-patch+json",
+			body:        `{"rollup": "sometimes"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, nil)).Times(1)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: `{"error": "invalid patch: unknown rollup \"sometimes\""}`,
+		},
+		{
+			desc:        "merge patch unknown field",
+			contentType: "application/merge-patch+json",
+			body:        `{"id": 2}`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, nil)).Times(1)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: `{"error": "invalid patch: json: unknown field \"id\""}`,
+		},
+		{
+			desc:        "merge patch malformed",
+			contentType: "application/merge-patch+json",
+			body:        `{`,
+			mock:        func() {},
+			wantCode:    http.StatusBadRequest,
+			wantResp:    `{"error": "unexpected end of JSON input"}`,
+		},
+		{
+			desc:        "json patch",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/title", "value": "title-1"}, {"op": "replace", "path": "/title", "value": "title-2"}, {"op": "remove", "path": "/description"}]`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, &entity.Todo{
+					ID:       1,
+					Title:    "title-2",
+					Priority: entity.PriorityNone,
+					Rollup:   entity.RollupNone,
+				})).Times(1)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			desc:        "json patch test failed",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "test", "path": "/title", "value": "other"}, {"op": "replace", "path": "/title", "value": "title-2"}]`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, nil)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "operation 0 (test /title): test operation failed"}`,
+		},
+		{
+			desc:        "json patch read-only path",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "replace", "path": "/createdAt", "value": "2020-01-01T00:00:00Z"}]`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, nil)).Times(1)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: `{"error": "operation 0 (replace /createdAt): invalid patch: path not found"}`,
+		},
+		{
+			desc:        "json patch wrong type",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "replace", "path": "/isCompleted", "value": "yes"}]`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(applyTo(current, nil)).Times(1)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: `{"error": "invalid patch: json: cannot unmarshal string into Go struct field todoPatchDoc.isCompleted of type bool"}`,
+		},
+		{
+			desc:        "not found",
+			contentType: "application/json-patch+json",
+			body:        `[]`,
+			mock: func() {
+				s.mockSrv.EXPECT().UpdateFunc(1, gomock.Any()).Return(service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/v1/todos/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			if tt.wantResp != "" {
+				s.JSONEq(tt.wantResp, w.Body.String())
+			}
+		})
+	}
+}
+
+func (s *todoSuite) TestReplace() {
+	body := `{"title": "title-1", "description": "desc-1", "isCompleted": true}`
+	input := entity.ReplaceTodoInput{
+		Title:       "title-1",
+		Description: "desc-1",
+		IsCompleted: true,
+	}
+	todo := &entity.Todo{
+		ID:          1,
+		Title:       "title-1",
+		Description: "desc-1",
+		IsCompleted: true,
+		CreatedAt:   time.Unix(123456789, 0),
+		UpdatedAt:   time.Unix(123456789, 0),
+	}
+	todoResp := `{
+	  "id": 1,
+	  "title": "title-1",
+	  "description": "desc-1",
+	  "isCompleted": true,
+	  "createdAt": "1973-11-30T05:33:09+08:00",
+	  "updatedAt": "1973-11-30T05:33:09+08:00"
+	}`
+
+	tests := []struct {
+		desc     string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "replace",
+			path: "/v1/todos/1",
+			body: body,
+			mock: func() {
+				s.mockSrv.EXPECT().Replace(1, input).Return(todo, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: todoResp,
+		},
+		{
+			desc: "replace not found",
+			path: "/v1/todos/1",
+			body: body,
+			mock: func() {
+				s.mockSrv.EXPECT().Replace(1, input).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc: "upsert created",
+			path: "/v1/todos/1?upsert=true",
+			body: body,
+			mock: func() {
+				s.mockSrv.EXPECT().Upsert(1, input).Return(todo, true, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: todoResp,
+		},
+		{
+			desc: "upsert replaced",
+			path: "/v1/todos/1?upsert=true",
+			body: body,
+			mock: func() {
+				s.mockSrv.EXPECT().Upsert(1, input).Return(todo, false, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: todoResp,
+		},
+		{
+			desc:     "missing field",
+			path:     "/v1/todos/1",
+			body:     `{"title": "title-1", "isCompleted": true}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'replaceTodoReq.Description' Error:Field validation for 'Description' failed on the 'required' tag"}`,
+		},
+		{
+			desc:     "invalid id",
+			path:     "/v1/todos/0",
+			body:     body,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'replaceTodoParams.ID' Error:Field validation for 'ID' failed on the 'required' tag"}`,
+		},
+		{
+			desc:     "id too large",
+			path:     "/v1/todos/9223372036854775807?upsert=true",
+			body:     body,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'replaceTodoParams.ID' Error:Field validation for 'ID' failed on the 'max' tag"}`,
+		},
+		{
+			desc: "service replace failed",
+			path: "/v1/todos/1",
+			body: body,
+			mock: func() {
+				s.mockSrv.EXPECT().Replace(1, input).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *todoSuite) TestCreateWithDates() {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	tests := []struct {
+		desc      string
+		body      string
+		wantStart *entity.DateTime
+		wantDue   *entity.DateTime
+		mockErr   error
+		wantCode  int
+		wantResp  string
+	}{
+		{
+			desc:      "all-day and local time in a zone",
+			body:      `{"title": "title-1", "start": {"date": "2024-03-09"}, "due": {"dateTime": "2024-03-10T09:00:00", "timeZone": "Asia/Taipei"}}`,
+			wantStart: lo.ToPtr(entity.NewDate(2024, 3, 9)),
+			wantDue:   lo.ToPtr(entity.NewDateTime(time.Date(2024, 3, 10, 9, 0, 0, 0, taipei), "Asia/Taipei")),
+			wantCode:  http.StatusCreated,
+			wantResp: `{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "start": {"date": "2024-03-09"},
+			  "due": {"dateTime": "2024-03-10T09:00:00+08:00", "timeZone": "Asia/Taipei"},
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "RFC 3339 rendered in its time zone",
+			body:     `{"title": "title-1", "due": {"dateTime": "2024-03-10T01:00:00Z", "timeZone": "Asia/Taipei"}}`,
+			wantDue:  lo.ToPtr(entity.NewDateTime(time.Date(2024, 3, 10, 1, 0, 0, 0, time.UTC), "Asia/Taipei")),
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "due": {"dateTime": "2024-03-10T09:00:00+08:00", "timeZone": "Asia/Taipei"},
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "both date and dateTime",
+			body:     `{"title": "title-1", "due": {"date": "2024-03-10", "dateTime": "2024-03-10T09:00:00Z"}}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "exactly one of date and dateTime is required"}`,
+		},
+		{
+			desc:     "local time without a zone",
+			body:     `{"title": "title-1", "due": {"dateTime": "2024-03-10T09:00:00"}}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid dateTime \"2024-03-10T09:00:00\""}`,
+		},
+		{
+			desc:     "unknown time zone",
+			body:     `{"title": "title-1", "due": {"date": "2024-03-10", "timeZone": "Mars/Olympus"}}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid time zone \"Mars/Olympus\""}`,
+		},
+		{
+			desc:      "start after due",
+			body:      `{"title": "title-1", "start": {"date": "2024-03-11"}, "due": {"date": "2024-03-10"}}`,
+			wantStart: lo.ToPtr(entity.NewDate(2024, 3, 11)),
+			wantDue:   lo.ToPtr(entity.NewDate(2024, 3, 10)),
+			mockErr:   fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrStartAfterDue),
+			wantCode:  http.StatusBadRequest,
+			wantResp:  `{"error": "invalid input: start must not be after due"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.wantCode != http.StatusBadRequest || tt.mockErr != nil {
+				s.mockSrv.EXPECT().Create(gomock.Any()).DoAndReturn(func(input entity.CreateTodoInput) (*entity.Todo, error) {
+					s.equalDateTime(tt.wantStart, input.Start)
+					s.equalDateTime(tt.wantDue, input.Due)
+					if tt.mockErr != nil {
+						return nil, tt.mockErr
+					}
+					return &entity.Todo{
+						ID:        1,
+						Title:     input.Title,
+						Start:     input.Start,
+						Due:       input.Due,
+						CreatedAt: time.Unix(123456789, 0),
+						UpdatedAt: time.Unix(123456789, 0),
+					}, nil
+				}).Times(1)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/todos", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *todoSuite) equalDateTime(want, got *entity.DateTime) {
+	if want == nil {
+		s.Nil(got)
+		return
+	}
+	s.Require().NotNil(got)
+	s.True(want.Time.Equal(got.Time), "want %v, got %v", want.Time, got.Time)
+	s.Equal(want.AllDay, got.AllDay)
+	s.Equal(want.TimeZone, got.TimeZone)
+}
+
+func (s *todoSuite) TestListViews() {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	tests := []struct {
+		desc       string
+		query      string
+		wantFilter entity.TodoQuery
+		wantCode   int
+		wantResp   string
+	}{
+		{
+			desc:       "overdue in UTC by default",
+			query:      "view=overdue",
+			wantFilter: entity.TodoQuery{View: entity.DueOverdue, Days: 7, Location: time.UTC},
+			wantCode:   http.StatusOK,
+			wantResp: `[{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "due": {"date": "2024-03-09"},
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}]`,
+		},
+		{
+			desc:       "upcoming in a time zone",
+			query:      "view=upcoming&days=30&tz=Asia/Taipei",
+			wantFilter: entity.TodoQuery{View: entity.DueUpcoming, Days: 30, Location: taipei},
+			wantCode:   http.StatusOK,
+			wantResp: `[{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "due": {"date": "2024-03-09"},
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}]`,
+		},
+		{
+			desc:       "order by priority",
+			query:      "order=priority",
+			wantFilter: entity.TodoQuery{Days: 7, Location: time.UTC, Order: entity.OrderPriority},
+			wantCode:   http.StatusOK,
+			wantResp: `[{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "due": {"date": "2024-03-09"},
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}]`,
+		},
+		{
+			desc:     "unknown order",
+			query:    "order=random",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'listTodoParams.Order' Error:Field validation for 'Order' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc:     "unknown view",
+			query:    "view=someday",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'listTodoParams.View' Error:Field validation for 'View' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc:     "days out of range",
+			query:    "view=upcoming&days=366",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'listTodoParams.Days' Error:Field validation for 'Days' failed on the 'max' tag"}`,
+		},
+		{
+			desc:     "unknown time zone",
+			query:    "view=today&tz=Mars/Olympus",
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid time zone \"Mars/Olympus\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.wantCode == http.StatusOK {
+				s.mockSrv.EXPECT().List(gomock.Any()).DoAndReturn(func(filter entity.TodoQuery) ([]entity.Todo, error) {
+					s.Equal(tt.wantFilter.View, filter.View)
+					s.Equal(tt.wantFilter.Days, filter.Days)
+					s.Equal(tt.wantFilter.Location.String(), filter.Location.String())
+					s.Equal(tt.wantFilter.Order, filter.Order)
+					return []entity.Todo{{
+						ID:        1,
+						Title:     "title-1",
+						Due:       lo.ToPtr(entity.NewDate(2024, 3, 9)),
+						CreatedAt: time.Unix(123456789, 0),
+						UpdatedAt: time.Unix(123456789, 0),
+					}}, nil
+				}).Times(1)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/v1/todos?"+tt.query, nil)
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *todoSuite) TestMove() {
+	tests := []struct {
+		desc     string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			path: "/v1/todos/1/move",
+			body: `{"before": 2}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Move(1, entity.MoveTodoInput{Before: lo.ToPtr(2)}).Return(&entity.Todo{
+					ID:        1,
+					Title:     "title-1",
+					Priority:  entity.PriorityHigh,
+					Rank:      "9",
+					CreatedAt: time.Unix(123456789, 0),
+					UpdatedAt: time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+			wantResp: `{
+			  "id": 1,
+			  "title": "title-1",
+			  "description": "",
+			  "isCompleted": false,
+			  "priority": "high",
+			  "rank": "9",
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "both before and after",
+			path:     "/v1/todos/1/move",
+			body:     `{"before": 2, "after": 3}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "exactly one of before and after is required"}`,
+		},
+		{
+			desc:     "neither before nor after",
+			path:     "/v1/todos/1/move",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "exactly one of before and after is required"}`,
+		},
+		{
+			desc:     "invalid id",
+			path:     "/v1/todos/0/move",
+			body:     `{"after": 2}`,
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'moveTodoParams.ID' Error:Field validation for 'ID' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "not found",
+			path: "/v1/todos/1/move",
+			body: `{"after": 2}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Move(1, entity.MoveTodoInput{After: lo.ToPtr(2)}).Return(nil, service.ErrNotFound).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found"}`,
+		},
+		{
+			desc: "target not found",
+			path: "/v1/todos/1/move",
+			body: `{"after": 2}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Move(1, entity.MoveTodoInput{After: lo.ToPtr(2)}).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrMoveTarget)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: move target not found"}`,
+		},
+		{
+			desc: "service move failed",
+			path: "/v1/todos/1/move",
+			body: `{"after": 2}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Move(1, entity.MoveTodoInput{After: lo.ToPtr(2)}).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			s.router.ServeHTTP(w, req)
+
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
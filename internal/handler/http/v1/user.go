@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type userHandler struct {
+	srv service.User
+}
+
+// NewUserRoutes registers sign-up and login, which must stay outside of
+// Auth.
+func NewUserRoutes(rg *gin.RouterGroup, srv service.User) {
+	h := &userHandler{
+		srv: srv,
+	}
+	rg.POST("/users", h.register)
+	rg.POST("/sessions", h.login)
+}
+
+// NewCurrentUserRoutes registers the endpoints about the caller, which need
+// Auth.
+func NewCurrentUserRoutes(rg *gin.RouterGroup, srv service.User) {
+	h := &userHandler{
+		srv: srv,
+	}
+	rg.GET("/users/me", me)
+	rg.DELETE("/sessions/current", middleware.RequireSession(), h.logout)
+}
+
+type registerUserReq struct {
+	Email    string `json:"email" binding:"required,max=254"`
+	Name     string `json:"name" binding:"max=100"`
+	Password string `json:"password" binding:"required,max=1024"`
+}
+
+type registerUserResp struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (h *userHandler) register(c *gin.Context) {
+	var req registerUserReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.srv.Register(entity.RegisterInput{
+		Email:    req.Email,
+		Name:     req.Name,
+		Password: req.Password,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registerUserResp{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	})
+}
+
+type loginReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required,max=1024"`
+}
+
+type loginResp struct {
+	AccessToken string    `json:"accessToken"`
+	TokenType   string    `json:"tokenType"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	UserID      int       `json:"userId"`
+}
+
+func (h *userHandler) login(c *gin.Context) {
+	var req loginReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, token, err := h.srv.Login(req.Email, req.Password)
+	if errors.Is(err, service.ErrUnauthorized) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, loginResp{
+		AccessToken: token.Value,
+		TokenType:   "Bearer",
+		ExpiresAt:   token.ExpiresAt,
+		UserID:      user.ID,
+	})
+}
+
+// logout revokes the access token the request is made with.
+func (h *userHandler) logout(c *gin.Context) {
+	err := h.srv.Logout(middleware.AccessToken(c))
+	if errors.Is(err, service.ErrUnauthorized) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type meResp struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func me(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meResp{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	})
+}
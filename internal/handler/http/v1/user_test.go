@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type userSuite struct {
+	suite.Suite
+	router     *gin.Engine
+	mockSrv    *mocks.MockUser
+	mockAPIKey *mocks.MockAPIKey
+}
+
+func (s *userSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockUser(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewUserRoutes(s.router.Group("v1"), s.mockSrv)
+	s.mockAPIKey = mocks.NewMockAPIKey(ctrl)
+	NewCurrentUserRoutes(s.router.Group("v1", middleware.Auth(s.mockSrv, s.mockAPIKey)), s.mockSrv)
+}
+
+func TestUserSuite(t *testing.T) {
+	suite.Run(t, new(userSuite))
+}
+
+func (s *userSuite) serve(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *userSuite) TestRegister() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"email": "a@example.com", "name": "A", "password": "correct horse"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Register(entity.RegisterInput{Email: "a@example.com", Name: "A", Password: "correct horse"}).Return(&entity.User{
+					ID:           1,
+					Email:        "a@example.com",
+					Name:         "A",
+					PasswordHash: "hash",
+					CreatedAt:    time.Unix(123456789, 0),
+					UpdatedAt:    time.Unix(123456789, 0),
+				}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "id": 1,
+			  "email": "a@example.com",
+			  "name": "A",
+			  "createdAt": "1973-11-30T05:33:09+08:00",
+			  "updatedAt": "1973-11-30T05:33:09+08:00"
+			}`,
+		},
+		{
+			desc:     "missing password",
+			body:     `{"email": "a@example.com"}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'registerUserReq.Password' Error:Field validation for 'Password' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "password too short",
+			body: `{"email": "a@example.com", "password": "short"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Register(gomock.Any()).Return(nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrPasswordTooShort)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: password is too short"}`,
+		},
+		{
+			desc: "email taken",
+			body: `{"email": "a@example.com", "password": "correct horse"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Register(gomock.Any()).Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrEmailTaken)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: email is already registered"}`,
+		},
+		{
+			desc: "service register failed",
+			body: `{"email": "a@example.com", "password": "correct horse"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Register(gomock.Any()).Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, "/v1/users", tt.body, nil)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *userSuite) TestLogin() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"email": "a@example.com", "password": "correct horse"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Login("a@example.com", "correct horse").Return(
+					&entity.User{ID: 1},
+					&entity.AccessToken{Value: "token-1", ExpiresAt: time.Unix(123456789, 0)},
+					nil,
+				).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{
+			  "accessToken": "token-1",
+			  "tokenType": "Bearer",
+			  "expiresAt": "1973-11-30T05:33:09+08:00",
+			  "userId": 1
+			}`,
+		},
+		{
+			desc:     "missing email",
+			body:     `{"password": "correct horse"}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'loginReq.Email' Error:Field validation for 'Email' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "wrong password",
+			body: `{"email": "a@example.com", "password": "battery staple"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Login("a@example.com", "battery staple").Return(nil, nil, service.ErrUnauthorized).Times(1)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantResp: `{"error": "invalid email or password"}`,
+		},
+		{
+			desc: "service login failed",
+			body: `{"email": "a@example.com", "password": "correct horse"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Login("a@example.com", "correct horse").Return(nil, nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, "/v1/sessions", tt.body, nil)
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+			if w.Code == http.StatusCreated {
+				s.Equal("no-store", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func (s *userSuite) TestMe() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Authenticate("token-1").Return(&entity.User{
+			ID:           1,
+			Email:        "a@example.com",
+			Name:         "A",
+			PasswordHash: "hash",
+			CreatedAt:    time.Unix(123456789, 0),
+			UpdatedAt:    time.Unix(123456789, 0),
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/users/me", "", http.Header{"Authorization": {"Bearer token-1"}})
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`{
+		  "id": 1,
+		  "email": "a@example.com",
+		  "name": "A",
+		  "createdAt": "1973-11-30T05:33:09+08:00",
+		  "updatedAt": "1973-11-30T05:33:09+08:00"
+		}`, w.Body.String())
+	})
+	s.Run("not logged in", func() {
+		w := s.serve(http.MethodGet, "/v1/users/me", "", nil)
+		s.Equal(http.StatusUnauthorized, w.Code)
+	})
+}
+
+func (s *userSuite) TestLogout() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockSrv.EXPECT().Logout("token-1").Return(nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/sessions/current", "", http.Header{"Authorization": {"Bearer token-1"}})
+		s.Equal(http.StatusNoContent, w.Code)
+	})
+	s.Run("api key", func() {
+		s.mockAPIKey.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(&entity.User{ID: 1}, &entity.APIKey{ID: 1}, nil).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/sessions/current", "", http.Header{"Authorization": {"Bearer tdk_AAAAAAAA_SECRET"}})
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+	s.Run("service logout failed", func() {
+		s.mockSrv.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockSrv.EXPECT().Logout("token-1").Return(errors.New("something wrong")).Times(1)
+
+		w := s.serve(http.MethodDelete, "/v1/sessions/current", "", http.Header{"Authorization": {"Bearer token-1"}})
+		s.Equal(http.StatusInternalServerError, w.Code)
+		s.JSONEq(`{"error": "something wrong"}`, w.Body.String())
+	})
+}
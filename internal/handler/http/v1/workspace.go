@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type workspaceHandler struct {
+	srv service.Workspace
+}
+
+// NewWorkspaceRoutes registers workspace management, which needs Auth.
+// Changing workspaces is closed to API keys.
+func NewWorkspaceRoutes(rg *gin.RouterGroup, srv service.Workspace) {
+	h := &workspaceHandler{
+		srv: srv,
+	}
+	session := middleware.RequireSession()
+	rg.POST("/workspaces", session, h.create)
+	rg.GET("/workspaces", h.list)
+	rg.POST("/workspaces/:id/members", session, h.addMember)
+	rg.GET("/workspaces/:id/members", h.members)
+}
+
+type workspaceParams struct {
+	ID int `uri:"id" binding:"required,min=1"`
+}
+
+type createWorkspaceReq struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type createWorkspaceResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   int       `json:"ownerId"`
+	Personal  bool      `json:"personal"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (h *workspaceHandler) create(c *gin.Context) {
+	var req createWorkspaceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workspace, err := h.srv.Create(middleware.UserID(c), req.Name)
+	if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createWorkspaceResp{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		OwnerID:   workspace.OwnerID,
+		Personal:  workspace.Personal,
+		CreatedAt: workspace.CreatedAt,
+	})
+}
+
+type listWorkspaceResp struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   int       `json:"ownerId"`
+	Personal  bool      `json:"personal"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (h *workspaceHandler) list(c *gin.Context) {
+	workspaces, err := h.srv.List(middleware.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]listWorkspaceResp, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		resp = append(resp, listWorkspaceResp{
+			ID:        workspace.ID,
+			Name:      workspace.Name,
+			OwnerID:   workspace.OwnerID,
+			Personal:  workspace.Personal,
+			CreatedAt: workspace.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type addMemberReq struct {
+	Email string      `json:"email" binding:"required,max=254"`
+	Role  entity.Role `json:"role" binding:"omitempty,oneof=guest viewer editor owner"`
+}
+
+type addMemberResp struct {
+	ID    int         `json:"id"`
+	Email string      `json:"email"`
+	Name  string      `json:"name"`
+	Role  entity.Role `json:"role"`
+}
+
+// addMember adds members as editors unless the request says otherwise.
+
+func (h *workspaceHandler) addMember(c *gin.Context) {
+	var params workspaceParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req addMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = entity.RoleEditor
+	}
+	member, err := h.srv.AddMember(middleware.UserID(c), params.ID, req.Email, role)
+	if errors.Is(err, service.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, addMemberResp{
+		ID:    member.User.ID,
+		Email: member.User.Email,
+		Name:  member.User.Name,
+		Role:  member.Role,
+	})
+}
+
+type listMemberResp struct {
+	ID    int         `json:"id"`
+	Email string      `json:"email"`
+	Name  string      `json:"name"`
+	Role  entity.Role `json:"role"`
+}
+
+func (h *workspaceHandler) members(c *gin.Context) {
+	var params workspaceParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	members, err := h.srv.Members(middleware.UserID(c), params.ID)
+	if errors.Is(err, service.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]listMemberResp, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, listMemberResp{
+			ID:    member.User.ID,
+			Email: member.User.Email,
+			Name:  member.User.Name,
+			Role:  member.Role,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
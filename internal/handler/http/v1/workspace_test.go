@@ -0,0 +1,235 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/handler/http/middleware"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type workspaceSuite struct {
+	suite.Suite
+	router     *gin.Engine
+	mockSrv    *mocks.MockWorkspace
+	mockUser   *mocks.MockUser
+	mockAPIKey *mocks.MockAPIKey
+}
+
+func (s *workspaceSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockSrv = mocks.NewMockWorkspace(ctrl)
+	s.mockUser = mocks.NewMockUser(ctrl)
+	s.mockAPIKey = mocks.NewMockAPIKey(ctrl)
+	s.mockUser.EXPECT().Authenticate("token-1").Return(&entity.User{ID: 1}, nil).AnyTimes()
+
+	gin.SetMode(gin.TestMode)
+	s.router = gin.Default()
+	NewWorkspaceRoutes(s.router.Group("v1", middleware.Auth(s.mockUser, s.mockAPIKey)), s.mockSrv)
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(workspaceSuite))
+}
+
+func (s *workspaceSuite) serve(method, path, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func (s *workspaceSuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "success",
+			body: `{"name": "team"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(1, "team").Return(&entity.Workspace{ID: 2, Name: "team", OwnerID: 1, CreatedAt: time.Unix(123456789, 0)}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 2, "name": "team", "ownerId": 1, "personal": false, "createdAt": "1973-11-30T05:33:09+08:00"}`,
+		},
+		{
+			desc:     "missing name",
+			body:     `{}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'createWorkspaceReq.Name' Error:Field validation for 'Name' failed on the 'required' tag"}`,
+		},
+		{
+			desc: "blank name",
+			body: `{"name": " "}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(1, " ").Return(nil, fmt.Errorf("%w: name is required", service.ErrInvalidInput)).Times(1)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "invalid input: name is required"}`,
+		},
+		{
+			desc: "service create failed",
+			body: `{"name": "team"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().Create(1, "team").Return(nil, errors.New("something wrong")).Times(1)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantResp: `{"error": "something wrong"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, "/v1/workspaces", tt.body, "token-1")
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+	s.Run("api key cannot create", func() {
+		s.mockAPIKey.EXPECT().Authenticate("tdk_AAAAAAAA_SECRET").Return(&entity.User{ID: 1}, &entity.APIKey{ID: 1, Scopes: entity.Scopes}, nil).Times(1)
+
+		w := s.serve(http.MethodPost, "/v1/workspaces", `{"name": "team"}`, "tdk_AAAAAAAA_SECRET")
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+}
+
+func (s *workspaceSuite) TestList() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().List(1).Return([]entity.Workspace{
+			{ID: 1, Name: "Personal", OwnerID: 1, Personal: true, CreatedAt: time.Unix(123456789, 0)},
+			{ID: 2, Name: "team", OwnerID: 3, CreatedAt: time.Unix(123456789, 0)},
+		}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/workspaces", "", "token-1")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[
+		  {"id": 1, "name": "Personal", "ownerId": 1, "personal": true, "createdAt": "1973-11-30T05:33:09+08:00"},
+		  {"id": 2, "name": "team", "ownerId": 3, "personal": false, "createdAt": "1973-11-30T05:33:09+08:00"}
+		]`, w.Body.String())
+	})
+}
+
+func (s *workspaceSuite) TestAddMember() {
+	tests := []struct {
+		desc     string
+		path     string
+		body     string
+		mock     func()
+		wantCode int
+		wantResp string
+	}{
+		{
+			desc: "editor by default",
+			path: "/v1/workspaces/2/members",
+			body: `{"email": "bob@example.com"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddMember(1, 2, "bob@example.com", entity.RoleEditor).Return(&entity.Member{User: entity.User{ID: 5, Email: "bob@example.com", Name: "Bob"}, Role: entity.RoleEditor}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 5, "email": "bob@example.com", "name": "Bob", "role": "editor"}`,
+		},
+		{
+			desc: "with role",
+			path: "/v1/workspaces/2/members",
+			body: `{"email": "bob@example.com", "role": "viewer"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddMember(1, 2, "bob@example.com", entity.RoleViewer).Return(&entity.Member{User: entity.User{ID: 5, Email: "bob@example.com", Name: "Bob"}, Role: entity.RoleViewer}, nil).Times(1)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: `{"id": 5, "email": "bob@example.com", "name": "Bob", "role": "viewer"}`,
+		},
+		{
+			desc:     "invalid role",
+			path:     "/v1/workspaces/2/members",
+			body:     `{"email": "bob@example.com", "role": "admin"}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "Key: 'addMemberReq.Role' Error:Field validation for 'Role' failed on the 'oneof' tag"}`,
+		},
+		{
+			desc: "not an owner",
+			path: "/v1/workspaces/2/members",
+			body: `{"email": "bob@example.com"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddMember(1, 2, "bob@example.com", entity.RoleEditor).Return(nil, service.ErrForbidden).Times(1)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: `{"error": "forbidden"}`,
+		},
+		{
+			desc:     "invalid id",
+			path:     "/v1/workspaces/abc/members",
+			body:     `{"email": "bob@example.com"}`,
+			mock:     func() {},
+			wantCode: http.StatusBadRequest,
+			wantResp: `{"error": "strconv.ParseInt: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			desc: "unknown user",
+			path: "/v1/workspaces/2/members",
+			body: `{"email": "bob@example.com"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddMember(1, 2, "bob@example.com", entity.RoleEditor).Return(nil, fmt.Errorf("%w: no user has the email", service.ErrNotFound)).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: `{"error": "not found: no user has the email"}`,
+		},
+		{
+			desc: "already a member",
+			path: "/v1/workspaces/2/members",
+			body: `{"email": "bob@example.com"}`,
+			mock: func() {
+				s.mockSrv.EXPECT().AddMember(1, 2, "bob@example.com", entity.RoleEditor).Return(nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrAlreadyMember)).Times(1)
+			},
+			wantCode: http.StatusConflict,
+			wantResp: `{"error": "conflict: user is already a member"}`,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.mock()
+			w := s.serve(http.MethodPost, tt.path, tt.body, "token-1")
+			s.Equal(tt.wantCode, w.Code)
+			s.JSONEq(tt.wantResp, w.Body.String())
+		})
+	}
+}
+
+func (s *workspaceSuite) TestMembers() {
+	s.Run("success", func() {
+		s.mockSrv.EXPECT().Members(1, 2).Return([]entity.Member{{User: entity.User{ID: 1, Email: "alice@example.com", Name: "Alice"}, Role: entity.RoleOwner}}, nil).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/workspaces/2/members", "", "token-1")
+		s.Equal(http.StatusOK, w.Code)
+		s.JSONEq(`[{"id": 1, "email": "alice@example.com", "name": "Alice", "role": "owner"}]`, w.Body.String())
+	})
+	s.Run("guest", func() {
+		s.mockSrv.EXPECT().Members(1, 2).Return(nil, service.ErrForbidden).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/workspaces/2/members", "", "token-1")
+		s.Equal(http.StatusForbidden, w.Code)
+	})
+	s.Run("not a member", func() {
+		s.mockSrv.EXPECT().Members(1, 2).Return(nil, service.ErrNotFound).Times(1)
+
+		w := s.serve(http.MethodGet, "/v1/workspaces/2/members", "", "token-1")
+		s.Equal(http.StatusNotFound, w.Code)
+	})
+}
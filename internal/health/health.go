@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var timeNow = time.Now
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type Result struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"durationNs"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type check struct {
+	name    string
+	checker Checker
+	timeout time.Duration
+
+	mu     sync.Mutex
+	last   *Result
+	expire time.Time
+}
+
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu           sync.RWMutex
+	checks       map[string]*check
+	shuttingDown atomic.Bool
+}
+
+type Option func(*Registry)
+
+func WithTimeout(d time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = d
+	}
+}
+
+func WithCacheTTL(d time.Duration) Option {
+	return func(r *Registry) {
+		r.cacheTTL = d
+	}
+}
+
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		timeout:  time.Second,
+		cacheTTL: time.Second,
+		checks:   make(map[string]*check),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds a named readiness check. A zero timeout falls back to the
+// registry default. Registering an existing name replaces the previous check.
+func (r *Registry) Register(name string, checker Checker, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = r.timeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = &check{
+		name:    name,
+		checker: checker,
+		timeout: timeout,
+	}
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Shutdown flips readiness to failing so load balancers stop routing new
+// traffic while in-flight requests drain.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, r.cacheTTL)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := Report{
+		Status: StatusOK,
+		Checks: results,
+	}
+	if r.ShuttingDown() {
+		report.Status = StatusFail
+		report.Checks = append(report.Checks, Result{
+			Name:      "shutdown",
+			Status:    StatusFail,
+			Error:     "server is shutting down",
+			CheckedAt: timeNow(),
+		})
+	}
+	for _, res := range results {
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *check) run(ctx context.Context, ttl time.Duration) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := timeNow()
+	if c.last != nil && now.Before(c.expire) {
+		return *c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		Duration:  timeNow().Sub(now),
+		CheckedAt: now,
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	c.last = &res
+	c.expire = now.Add(ttl)
+	return res
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type healthSuite struct {
+	suite.Suite
+	registry *Registry
+}
+
+func (s *healthSuite) SetupSubTest() {
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+	s.registry = NewRegistry(WithTimeout(50*time.Millisecond), WithCacheTTL(time.Minute))
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(healthSuite))
+}
+
+func (s *healthSuite) TestCheck() {
+	tests := []struct {
+		desc       string
+		setup      func()
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			desc:       "no checks",
+			setup:      func() {},
+			wantStatus: StatusOK,
+			wantChecks: map[string]string{},
+		},
+		{
+			desc: "all passing",
+			setup: func() {
+				s.registry.Register("a", CheckerFunc(func(ctx context.Context) error { return nil }), 0)
+				s.registry.Register("b", CheckerFunc(func(ctx context.Context) error { return nil }), 0)
+			},
+			wantStatus: StatusOK,
+			wantChecks: map[string]string{"a": "", "b": ""},
+		},
+		{
+			desc: "one failing",
+			setup: func() {
+				s.registry.Register("a", CheckerFunc(func(ctx context.Context) error { return nil }), 0)
+				s.registry.Register("b", CheckerFunc(func(ctx context.Context) error { return errors.New("broken") }), 0)
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]string{"a": "", "b": "broken"},
+		},
+		{
+			desc: "timeout",
+			setup: func() {
+				s.registry.Register("slow", CheckerFunc(func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				}), 10*time.Millisecond)
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]string{"slow": context.DeadlineExceeded.Error()},
+		},
+		{
+			desc: "shutting down",
+			setup: func() {
+				s.registry.Register("a", CheckerFunc(func(ctx context.Context) error { return nil }), 0)
+				s.registry.Shutdown()
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]string{"a": "", "shutdown": "server is shutting down"},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			report := s.registry.Check(context.Background())
+			s.Equal(tt.wantStatus, report.Status)
+
+			got := make(map[string]string, len(report.Checks))
+			for _, res := range report.Checks {
+				got[res.Name] = res.Error
+			}
+			s.Equal(tt.wantChecks, got)
+		})
+	}
+}
+
+func (s *healthSuite) TestCheckCached() {
+	s.Run("cached until ttl expires", func() {
+		calls := 0
+		s.registry.Register("a", CheckerFunc(func(ctx context.Context) error {
+			calls++
+			return nil
+		}), 0)
+
+		s.registry.Check(context.Background())
+		s.registry.Check(context.Background())
+		s.Equal(1, calls)
+
+		timeNow = func() time.Time {
+			return time.Unix(123456789, 0).Add(2 * time.Minute)
+		}
+		s.registry.Check(context.Background())
+		s.Equal(2, calls)
+	})
+}
@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInProgress  = errors.New("a request with this idempotency key is still in progress")
+	ErrKeyMismatch = errors.New("idempotency key was already used with a different request")
+)
+
+var timeNow = time.Now
+
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type Store interface {
+	// Begin reserves key for a request with the given fingerprint. It returns
+	// the stored response when the key already completed, ErrInProgress when
+	// another request holds it, and ErrKeyMismatch when the fingerprints
+	// differ. A nil response with a nil error means the caller owns the key.
+	Begin(key, fingerprint string) (*Response, error)
+	// Complete stores the response for a key reserved by Begin.
+	Complete(key string, resp Response)
+	// Release drops a reservation so the request can be retried.
+	Release(key string)
+}
+
+type record struct {
+	fingerprint string
+	response    *Response
+	expireAt    time.Time
+}
+
+type memoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	records map[string]*record
+	// prunedAt is when expired records were last dropped. Lookups ignore
+	// expired records on their own, so pruning only keeps the map from
+	// growing and is done at most once per ttl.
+	prunedAt time.Time
+}
+
+func NewMemoryStore(ttl time.Duration) Store {
+	return &memoryStore{
+		ttl:      ttl,
+		records:  make(map[string]*record),
+		prunedAt: timeNow(),
+	}
+}
+
+func (s *memoryStore) Begin(key, fingerprint string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := timeNow()
+	if now.Sub(s.prunedAt) >= s.ttl {
+		s.prune(now)
+	}
+
+	rec, ok := s.records[key]
+	if !ok || !now.Before(rec.expireAt) {
+		s.records[key] = &record{
+			fingerprint: fingerprint,
+			expireAt:    now.Add(s.ttl),
+		}
+		return nil, nil
+	}
+	if rec.fingerprint != fingerprint {
+		return nil, ErrKeyMismatch
+	}
+	if rec.response == nil {
+		return nil, ErrInProgress
+	}
+	return rec.response, nil
+}
+
+func (s *memoryStore) Complete(key string, resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[key]; ok {
+		rec.response = &resp
+		rec.expireAt = timeNow().Add(s.ttl)
+	}
+}
+
+func (s *memoryStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+}
+
+func (s *memoryStore) prune(now time.Time) {
+	for key, rec := range s.records {
+		if !now.Before(rec.expireAt) {
+			delete(s.records, key)
+		}
+	}
+	s.prunedAt = now
+}
@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type storeSuite struct {
+	suite.Suite
+	store Store
+}
+
+func (s *storeSuite) SetupSubTest() {
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+	s.store = NewMemoryStore(time.Hour)
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(storeSuite))
+}
+
+func (s *storeSuite) TestBegin() {
+	stored := Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"id": 1}`),
+	}
+	tests := []struct {
+		desc        string
+		fingerprint string
+		setup       func()
+		want        *Response
+		wantErr     error
+	}{
+		{
+			desc:        "new key",
+			fingerprint: "fp-1",
+			setup:       func() {},
+			want:        nil,
+			wantErr:     nil,
+		},
+		{
+			desc:        "in progress",
+			fingerprint: "fp-1",
+			setup: func() {
+				_, _ = s.store.Begin("key", "fp-1")
+			},
+			want:    nil,
+			wantErr: ErrInProgress,
+		},
+		{
+			desc:        "completed",
+			fingerprint: "fp-1",
+			setup: func() {
+				_, _ = s.store.Begin("key", "fp-1")
+				s.store.Complete("key", stored)
+			},
+			want:    &stored,
+			wantErr: nil,
+		},
+		{
+			desc:        "fingerprint mismatch",
+			fingerprint: "fp-2",
+			setup: func() {
+				_, _ = s.store.Begin("key", "fp-1")
+				s.store.Complete("key", stored)
+			},
+			want:    nil,
+			wantErr: ErrKeyMismatch,
+		},
+		{
+			desc:        "released",
+			fingerprint: "fp-2",
+			setup: func() {
+				_, _ = s.store.Begin("key", "fp-1")
+				s.store.Release("key")
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			desc:        "expired",
+			fingerprint: "fp-2",
+			setup: func() {
+				_, _ = s.store.Begin("key", "fp-1")
+				s.store.Complete("key", stored)
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0).Add(time.Hour)
+				}
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.store.Begin("key", tt.fingerprint)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *storeSuite) TestPrune() {
+	start := time.Unix(123456789, 0)
+	at := func(d time.Duration) {
+		timeNow = func() time.Time {
+			return start.Add(d)
+		}
+	}
+	records := func() int {
+		return len(s.store.(*memoryStore).records)
+	}
+
+	s.Run("expired records are dropped", func() {
+		_, _ = s.store.Begin("key-1", "fp-1")
+		at(30 * time.Minute)
+		_, _ = s.store.Begin("key-2", "fp-1")
+		at(time.Hour)
+		_, _ = s.store.Begin("key-3", "fp-1")
+		s.Equal(2, records())
+	})
+	s.Run("at most once per ttl", func() {
+		_, _ = s.store.Begin("key-1", "fp-1")
+		at(30 * time.Minute)
+		_, _ = s.store.Begin("key-2", "fp-1")
+		at(time.Hour)
+		_, _ = s.store.Begin("key-3", "fp-1")
+		at(100 * time.Minute)
+		_, _ = s.store.Begin("key-4", "fp-1")
+		s.Equal(3, records(), "key-2 expired but the last prune was under a ttl ago")
+
+		got, err := s.store.Begin("key-2", "fp-2")
+		s.Nil(got)
+		s.NoError(err, "expired records are ignored until pruned")
+	})
+}
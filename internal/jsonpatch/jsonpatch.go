@@ -0,0 +1,250 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrTestFailed = errors.New("test operation failed")
+	ErrInvalid    = errors.New("invalid patch")
+)
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type Patch []Operation
+
+func Decode(b []byte) (Patch, error) {
+	var p Patch
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Apply runs every operation of an RFC 6902 patch against a copy of doc and
+// returns the result. doc is left untouched when any operation fails.
+func (p Patch) Apply(doc any) (any, error) {
+	doc = deepCopy(doc)
+	for i, op := range p {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func (op Operation) apply(doc any) (any, error) {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		got, err := get(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(got, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return set(doc, tokens, value, true)
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := get(doc, tokens); err != nil {
+			return nil, err
+		}
+		return set(doc, tokens, value, false)
+	case "remove":
+		return remove(doc, tokens)
+	}
+	return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalid, op.Op)
+}
+
+func (op Operation) value() (any, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w: missing value", ErrInvalid)
+	}
+	var v any
+	if err := json.Unmarshal(op.Value, &v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return v, nil
+}
+
+// MergePatch applies an RFC 7396 merge patch to a copy of target.
+func MergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return deepCopy(patch)
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	} else {
+		targetObj = deepCopy(targetObj).(map[string]any)
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = MergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
+
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalid, path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func get(doc any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: path not found", ErrInvalid)
+			}
+			doc = v
+		case []any:
+			idx, err := index(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("%w: path not found", ErrInvalid)
+		}
+	}
+	return doc, nil
+}
+
+func set(doc any, tokens []string, value any, insert bool) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return doc, nil
+	case []any:
+		idx, err := index(last, len(node), insert)
+		if err != nil {
+			return nil, err
+		}
+		if insert {
+			node = append(node[:idx], append([]any{value}, node[idx:]...)...)
+		} else {
+			node[idx] = value
+		}
+		return set(doc, tokens[:len(tokens)-1], node, false)
+	}
+	return nil, fmt.Errorf("%w: path not found", ErrInvalid)
+}
+
+func remove(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalid)
+	}
+	parent, err := get(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("%w: path not found", ErrInvalid)
+		}
+		delete(node, last)
+		return doc, nil
+	case []any:
+		idx, err := index(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:idx], node[idx+1:]...)
+		return set(doc, tokens[:len(tokens)-1], node, false)
+	}
+	return nil, fmt.Errorf("%w: path not found", ErrInvalid)
+}
+
+func index(token string, length int, insert bool) (int, error) {
+	if insert && token == "-" {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(token)
+	max := length - 1
+	if insert {
+		max = length
+	}
+	if err != nil || idx < 0 || idx > max {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalid, token)
+	}
+	return idx, nil
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = deepCopy(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = deepCopy(item)
+		}
+		return out
+	}
+	return v
+}
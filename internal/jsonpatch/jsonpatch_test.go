@@ -0,0 +1,140 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type jsonPatchSuite struct {
+	suite.Suite
+}
+
+func TestJSONPatchSuite(t *testing.T) {
+	suite.Run(t, new(jsonPatchSuite))
+}
+
+func decode(s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (s *jsonPatchSuite) TestApply() {
+	tests := []struct {
+		desc    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			desc:  "replace",
+			doc:   `{"title": "a", "done": false}`,
+			patch: `[{"op": "replace", "path": "/title", "value": "b"}]`,
+			want:  `{"title": "b", "done": false}`,
+		},
+		{
+			desc:  "add and remove",
+			doc:   `{"title": "a", "note": "x"}`,
+			patch: `[{"op": "remove", "path": "/note"}, {"op": "add", "path": "/done", "value": true}]`,
+			want:  `{"title": "a", "done": true}`,
+		},
+		{
+			desc:  "test passes",
+			doc:   `{"title": "a"}`,
+			patch: `[{"op": "test", "path": "/title", "value": "a"}, {"op": "replace", "path": "/title", "value": "b"}]`,
+			want:  `{"title": "b"}`,
+		},
+		{
+			desc:  "array insert and append",
+			doc:   `{"tags": ["a", "c"]}`,
+			patch: `[{"op": "add", "path": "/tags/1", "value": "b"}, {"op": "add", "path": "/tags/-", "value": "d"}]`,
+			want:  `{"tags": ["a", "b", "c", "d"]}`,
+		},
+		{
+			desc:  "escaped pointer",
+			doc:   `{"a/b": 1, "c~d": 2}`,
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 3}, {"op": "remove", "path": "/c~0d"}]`,
+			want:  `{"a/b": 3}`,
+		},
+		{
+			desc:    "test fails",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "test", "path": "/title", "value": "b"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			desc:    "replace missing path",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "replace", "path": "/missing", "value": 1}]`,
+			wantErr: ErrInvalid,
+		},
+		{
+			desc:    "remove missing path",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "remove", "path": "/missing"}]`,
+			wantErr: ErrInvalid,
+		},
+		{
+			desc:    "unsupported op",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "move", "from": "/title", "path": "/name"}]`,
+			wantErr: ErrInvalid,
+		},
+		{
+			desc:    "missing value",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "add", "path": "/title"}]`,
+			wantErr: ErrInvalid,
+		},
+		{
+			desc:    "invalid pointer",
+			doc:     `{"title": "a"}`,
+			patch:   `[{"op": "remove", "path": "title"}]`,
+			wantErr: ErrInvalid,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			patch, err := Decode([]byte(tt.patch))
+			s.Require().NoError(err)
+
+			doc := decode(tt.doc)
+			got, err := patch.Apply(doc)
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+				s.Equal(decode(tt.doc), doc, "original document must be untouched")
+				return
+			}
+			s.NoError(err)
+			s.Equal(decode(tt.want), got)
+		})
+	}
+}
+
+func (s *jsonPatchSuite) TestMergePatch() {
+	tests := []struct {
+		desc   string
+		target string
+		patch  string
+		want   string
+	}{
+		{desc: "replace value", target: `{"a": "b"}`, patch: `{"a": "c"}`, want: `{"a": "c"}`},
+		{desc: "add value", target: `{"a": "b"}`, patch: `{"b": "c"}`, want: `{"a": "b", "b": "c"}`},
+		{desc: "remove value", target: `{"a": "b", "b": "c"}`, patch: `{"a": null}`, want: `{"b": "c"}`},
+		{desc: "nested", target: `{"a": {"b": "c", "d": "e"}}`, patch: `{"a": {"d": null, "f": "g"}}`, want: `{"a": {"b": "c", "f": "g"}}`},
+		{desc: "array replaced", target: `{"a": [1, 2]}`, patch: `{"a": [3]}`, want: `{"a": [3]}`},
+		{desc: "non object patch", target: `{"a": "b"}`, patch: `["c"]`, want: `["c"]`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			target := decode(tt.target)
+			s.Equal(decode(tt.want), MergePatch(target, decode(tt.patch)))
+			s.Equal(decode(tt.target), target, "target must be untouched")
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrExpired     = errors.New("token is expired")
+	ErrNotYetValid = errors.New("token is not valid yet")
+	ErrIssuer      = errors.New("unexpected issuer")
+	ErrAudience    = errors.New("unexpected audience")
+)
+
+// Audience is the "aud" claim, which may be a single string or an array.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Claims are the registered claims. Times are seconds since the epoch and
+// zero means absent.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Expected describes what Validate checks beyond the time claims. Empty
+// fields are not checked.
+type Expected struct {
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew between issuer and verifier.
+	Leeway time.Duration
+}
+
+// Validate checks the claims at now. A token without exp never expires, so
+// issuers in this service always set it.
+func (c Claims) Validate(now time.Time, want Expected) error {
+	if c.ExpiresAt != 0 && !now.Before(time.Unix(c.ExpiresAt, 0).Add(want.Leeway)) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Add(want.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return ErrNotYetValid
+	}
+	if want.Issuer != "" && c.Issuer != want.Issuer {
+		return ErrIssuer
+	}
+	if want.Audience != "" && !slices.Contains(c.Audience, want.Audience) {
+		return ErrAudience
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type jwtSuite struct {
+	suite.Suite
+	hs     Method
+	ed     Method
+	edPub  ed25519.PublicKey
+	rsa    *rsa.PrivateKey
+	claims Claims
+}
+
+// SetupSuite generates the RSA key once, as it is too slow to do per test.
+func (s *jwtSuite) SetupSuite() {
+	var err error
+	s.rsa, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+}
+
+func (s *jwtSuite) SetupSubTest() {
+	s.hs = HS256([]byte("0123456789abcdef0123456789abcdef"))
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	s.ed, s.edPub = EdDSA(priv), pub
+	s.claims = Claims{Issuer: "todo", Subject: "1", Audience: Audience{"api"}, ExpiresAt: 123456789}
+}
+
+func TestJWTSuite(t *testing.T) {
+	suite.Run(t, new(jwtSuite))
+}
+
+func (s *jwtSuite) TestRoundTrip() {
+	s.Run("HS256", func() {
+		token, err := Sign(s.hs, "", s.claims)
+		s.Require().NoError(err)
+		s.True(strings.HasPrefix(token, "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9."), token)
+
+		var got Claims
+		s.Require().NoError(Parse(token, s.hs, &got))
+		s.Equal(s.claims, got)
+	})
+	s.Run("EdDSA with public key", func() {
+		token, err := Sign(s.ed, "key-1", s.claims)
+		s.Require().NoError(err)
+
+		h, err := DecodeHeader(token)
+		s.Require().NoError(err)
+		s.Equal(Header{Alg: "EdDSA", Typ: "JWT", Kid: "key-1"}, h)
+
+		var got Claims
+		s.Require().NoError(Parse(token, EdDSAVerifier(s.edPub), &got))
+		s.Equal(s.claims, got)
+	})
+	s.Run("RS256 with public key", func() {
+		token, err := Sign(RS256(s.rsa), "key-1", s.claims)
+		s.Require().NoError(err)
+		s.True(strings.HasPrefix(token, "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCIsImtpZCI6ImtleS0xIn0."), token)
+
+		var got Claims
+		s.Require().NoError(Parse(token, RS256Verifier(&s.rsa.PublicKey), &got))
+		s.Equal(s.claims, got)
+	})
+	s.Run("verifier cannot sign", func() {
+		_, err := Sign(EdDSAVerifier(s.edPub), "", s.claims)
+		s.ErrorIs(err, ErrCannotSign)
+		_, err = Sign(RS256Verifier(&s.rsa.PublicKey), "", s.claims)
+		s.ErrorIs(err, ErrCannotSign)
+	})
+}
+
+func (s *jwtSuite) TestParse() {
+	forge := func(header string) string {
+		token, _ := Sign(s.hs, "", s.claims)
+		parts := strings.Split(token, ".")
+		return b64.EncodeToString([]byte(header)) + "." + parts[1] + "." + parts[2]
+	}
+	tests := []struct {
+		desc    string
+		token   func() string
+		method  func() Method
+		wantErr error
+	}{
+		{
+			desc:    "wrong secret",
+			token:   func() string { t, _ := Sign(s.hs, "", s.claims); return t },
+			method:  func() Method { return HS256([]byte("other")) },
+			wantErr: ErrSignature,
+		},
+		{
+			desc: "tampered payload",
+			token: func() string {
+				t, _ := Sign(s.hs, "", s.claims)
+				p := strings.Split(t, ".")
+				return p[0] + ".e30." + p[2]
+			},
+			method:  func() Method { return s.hs },
+			wantErr: ErrSignature,
+		},
+		{
+			desc: "wrong RSA key",
+			token: func() string {
+				t, _ := Sign(RS256(s.rsa), "", s.claims)
+				return t
+			},
+			method: func() Method {
+				n := new(big.Int).Add(s.rsa.N, big.NewInt(2))
+				return RS256Verifier(&rsa.PublicKey{N: n, E: s.rsa.E})
+			},
+			wantErr: ErrSignature,
+		},
+		{
+			desc:    "alg none",
+			token:   func() string { return forge(`{"alg":"none"}`) },
+			method:  func() Method { return s.hs },
+			wantErr: ErrAlgorithm,
+		},
+		{
+			desc:    "alg mismatch",
+			token:   func() string { t, _ := Sign(s.hs, "", s.claims); return t },
+			method:  func() Method { return EdDSAVerifier(s.edPub) },
+			wantErr: ErrAlgorithm,
+		},
+		{
+			desc:    "two segments",
+			token:   func() string { return "a.b" },
+			method:  func() Method { return s.hs },
+			wantErr: ErrMalformed,
+		},
+		{
+			desc:    "bad base64",
+			token:   func() string { return "!!.e30.sig" },
+			method:  func() Method { return s.hs },
+			wantErr: ErrMalformed,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			var got Claims
+			s.ErrorIs(Parse(tt.token(), tt.method(), &got), tt.wantErr)
+		})
+	}
+}
+
+// TestEdDSAVector checks the example of RFC 8037 appendix A.4.
+func (s *jwtSuite) TestEdDSAVector() {
+	s.Run("verify", func() {
+		pub, err := b64.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+		s.Require().NoError(err)
+		seed, err := b64.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+		s.Require().NoError(err)
+		input := []byte("eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc")
+		want := "hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+
+		sig, err := EdDSA(ed25519.NewKeyFromSeed(seed)).Sign(input)
+		s.Require().NoError(err)
+		s.Equal(want, b64.EncodeToString(sig))
+		s.NoError(EdDSAVerifier(pub).Verify(input, sig))
+	})
+}
+
+func (s *jwtSuite) TestValidate() {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		desc    string
+		claims  Claims
+		want    Expected
+		wantErr error
+	}{
+		{desc: "valid", claims: Claims{Issuer: "todo", Audience: Audience{"web", "api"}, ExpiresAt: 1001, NotBefore: 1000}, want: Expected{Issuer: "todo", Audience: "api"}},
+		{desc: "no time claims", claims: Claims{}},
+		{desc: "expired", claims: Claims{ExpiresAt: 1000}, wantErr: ErrExpired},
+		{desc: "expired within leeway", claims: Claims{ExpiresAt: 999}, want: Expected{Leeway: 5 * time.Second}},
+		{desc: "not yet valid", claims: Claims{NotBefore: 1001}, wantErr: ErrNotYetValid},
+		{desc: "not yet valid within leeway", claims: Claims{NotBefore: 1001}, want: Expected{Leeway: 5 * time.Second}},
+		{desc: "wrong issuer", claims: Claims{Issuer: "other"}, want: Expected{Issuer: "todo"}, wantErr: ErrIssuer},
+		{desc: "wrong audience", claims: Claims{Audience: Audience{"web"}}, want: Expected{Audience: "api"}, wantErr: ErrAudience},
+		{desc: "missing audience", claims: Claims{}, want: Expected{Audience: "api"}, wantErr: ErrAudience},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.ErrorIs(tt.claims.Validate(now, tt.want), tt.wantErr)
+		})
+	}
+}
+
+func (s *jwtSuite) TestAudience() {
+	tests := []struct {
+		desc string
+		json string
+		want Audience
+	}{
+		{desc: "string", json: `"api"`, want: Audience{"api"}},
+		{desc: "array", json: `["web","api"]`, want: Audience{"web", "api"}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			var got Audience
+			s.Require().NoError(got.UnmarshalJSON([]byte(tt.json)))
+			s.Equal(tt.want, got)
+
+			b, err := got.MarshalJSON()
+			s.Require().NoError(err)
+			s.JSONEq(tt.json, string(b))
+		})
+	}
+}
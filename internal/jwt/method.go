@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+)
+
+var ErrCannotSign = errors.New("method cannot sign")
+
+// Method is a signing algorithm bound to a key.
+type Method interface {
+	// Alg is the JWS "alg" header value.
+	Alg() string
+	Sign(input []byte) ([]byte, error)
+	Verify(input, sig []byte) error
+}
+
+type hs256 struct {
+	secret []byte
+}
+
+// HS256 signs with HMAC-SHA256. The secret should be at least 32 random
+// bytes.
+func HS256(secret []byte) Method {
+	return &hs256{secret: secret}
+}
+
+func (m *hs256) Alg() string {
+	return "HS256"
+}
+
+func (m *hs256) Sign(input []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
+
+func (m *hs256) Verify(input, sig []byte) error {
+	want, _ := m.Sign(input)
+	if !hmac.Equal(want, sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+type eddsa struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+// EdDSA signs with Ed25519 and verifies with the key's public half.
+func EdDSA(key ed25519.PrivateKey) Method {
+	return &eddsa{private: key, public: key.Public().(ed25519.PublicKey)}
+}
+
+// EdDSAVerifier only verifies; Sign returns ErrCannotSign.
+func EdDSAVerifier(key ed25519.PublicKey) Method {
+	return &eddsa{public: key}
+}
+
+func (m *eddsa) Alg() string {
+	return "EdDSA"
+}
+
+func (m *eddsa) Sign(input []byte) ([]byte, error) {
+	if m.private == nil {
+		return nil, ErrCannotSign
+	}
+	return ed25519.Sign(m.private, input), nil
+}
+
+func (m *eddsa) Verify(input, sig []byte) error {
+	if len(m.public) != ed25519.PublicKeySize || !ed25519.Verify(m.public, input, sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+type rs256 struct {
+	private *rsa.PrivateKey
+	public  *rsa.PublicKey
+}
+
+// RS256 signs with RSASSA-PKCS1-v1_5 and SHA-256, the algorithm identity
+// providers sign ID tokens with unless told otherwise.
+func RS256(key *rsa.PrivateKey) Method {
+	return &rs256{private: key, public: &key.PublicKey}
+}
+
+// RS256Verifier only verifies; Sign returns ErrCannotSign.
+func RS256Verifier(key *rsa.PublicKey) Method {
+	return &rs256{public: key}
+}
+
+func (m *rs256) Alg() string {
+	return "RS256"
+}
+
+func (m *rs256) Sign(input []byte) ([]byte, error) {
+	if m.private == nil {
+		return nil, ErrCannotSign
+	}
+	digest := sha256.Sum256(input)
+	return rsa.SignPKCS1v15(rand.Reader, m.private, crypto.SHA256, digest[:])
+}
+
+func (m *rs256) Verify(input, sig []byte) error {
+	if m.public == nil {
+		return ErrSignature
+	}
+	digest := sha256.Sum256(input)
+	if rsa.VerifyPKCS1v15(m.public, crypto.SHA256, digest[:], sig) != nil {
+		return ErrSignature
+	}
+	return nil
+}
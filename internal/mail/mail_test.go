@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/mail/mailtest"
+	"github.com/stretchr/testify/suite"
+)
+
+type mailSuite struct {
+	suite.Suite
+	srv *mailtest.Server
+	cfg Config
+	msg Message
+}
+
+func (s *mailSuite) SetupSubTest() {
+	s.srv = mailtest.NewServer(s.T())
+	s.cfg = Config{Addr: s.srv.Addr(), From: "todo@example.com"}
+	s.msg = Message{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "hello",
+		Body:    "first line\r\nsecond line\r\n",
+		Date:    time.Unix(123456789, 0).UTC(),
+	}
+}
+
+func TestMailSuite(t *testing.T) {
+	suite.Run(t, new(mailSuite))
+}
+
+func (s *mailSuite) TestSend() {
+	s.Run("success", func() {
+		s.Require().NoError(Send(context.Background(), s.cfg, s.msg))
+
+		s.Equal("<todo@example.com>", s.srv.From())
+		s.Equal([]string{"<a@example.com>", "<b@example.com>"}, s.srv.To())
+		s.Require().Len(s.srv.Messages(), 1)
+		msg := s.srv.Messages()[0]
+		s.Contains(msg, "From: todo@example.com\n")
+		s.Contains(msg, "To: a@example.com, b@example.com\n")
+		s.Contains(msg, "Subject: hello\n")
+		s.Contains(msg, "Date: Thu, 29 Nov 1973 21:33:09 +0000\n")
+		s.Contains(msg, "@example.com>\n")
+		s.Contains(msg, "\n\nfirst line\nsecond line\n")
+	})
+	s.Run("encodes subject", func() {
+		s.msg.Subject = "繳費\r\nBcc: x@example.com"
+
+		s.Require().NoError(Send(context.Background(), s.cfg, s.msg))
+		s.Require().Len(s.srv.Messages(), 1)
+		s.Contains(s.srv.Messages()[0], "Subject: =?utf-8?q?")
+		s.NotContains(s.srv.Messages()[0], "\nBcc:")
+	})
+	s.Run("rejected recipient", func() {
+		s.srv.RejectRcpt("b@example.com")
+
+		s.ErrorContains(Send(context.Background(), s.cfg, s.msg), "no such user")
+		s.Empty(s.srv.Messages())
+	})
+	s.Run("unreachable", func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		s.Require().NoError(err)
+		s.cfg.Addr = ln.Addr().String()
+		ln.Close()
+
+		s.Error(Send(context.Background(), s.cfg, s.msg))
+	})
+}
@@ -0,0 +1,129 @@
+// Package mailtest runs a stand-in mail server for tests, the way
+// net/http/httptest runs a stand-in server.
+package mailtest
+
+import (
+	"net"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server accepts every message over plain SMTP and keeps what it received.
+type Server struct {
+	ln net.Listener
+
+	mu         sync.Mutex
+	rejectRcpt string
+	from       string
+	to         []string
+	messages   []string
+}
+
+// NewServer starts a server listening on a local port, which is closed when
+// the test ends.
+func NewServer(t testing.TB) *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{ln: ln}
+	go srv.serve()
+	t.Cleanup(func() { ln.Close() })
+	return srv
+}
+
+// Addr is the host:port the server listens on.
+func (srv *Server) Addr() string {
+	return srv.ln.Addr().String()
+}
+
+// RejectRcpt makes RCPT fail for addr.
+func (srv *Server) RejectRcpt(addr string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.rejectRcpt = addr
+}
+
+// From is the sender of the last message, as given to MAIL.
+func (srv *Server) From() string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.from
+}
+
+// To lists the recipients of every message, as given to RCPT.
+func (srv *Server) To() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return slices.Clone(srv.to)
+}
+
+// Messages are the messages received, with their headers and with line
+// endings as "\n".
+func (srv *Server) Messages() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return slices.Clone(srv.messages)
+}
+
+func (srv *Server) serve() {
+	for {
+		conn, err := srv.ln.Accept()
+		if err != nil {
+			return
+		}
+		go srv.handle(textproto.NewConn(conn))
+	}
+}
+
+func (srv *Server) handle(c *textproto.Conn) {
+	defer c.Close()
+	_ = c.PrintfLine("220 localhost ready")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			_ = c.PrintfLine("250 localhost")
+		case "MAIL":
+			srv.mu.Lock()
+			srv.from = strings.TrimPrefix(arg, "FROM:")
+			srv.mu.Unlock()
+			_ = c.PrintfLine("250 OK")
+		case "RCPT":
+			to := strings.TrimPrefix(arg, "TO:")
+			srv.mu.Lock()
+			rejected := to == "<"+srv.rejectRcpt+">"
+			if !rejected {
+				srv.to = append(srv.to, to)
+			}
+			srv.mu.Unlock()
+			if rejected {
+				_ = c.PrintfLine("550 no such user")
+				continue
+			}
+			_ = c.PrintfLine("250 OK")
+		case "DATA":
+			_ = c.PrintfLine("354 go ahead")
+			b, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			srv.mu.Lock()
+			srv.messages = append(srv.messages, string(b))
+			srv.mu.Unlock()
+			_ = c.PrintfLine("250 queued")
+		case "QUIT":
+			_ = c.PrintfLine("221 bye")
+			return
+		default:
+			_ = c.PrintfLine("502 not implemented")
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/jwt"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+const (
+	// DefaultKeyTTL is how long a key set is cached when the provider does
+	// not say with Cache-Control.
+	DefaultKeyTTL = time.Hour
+	// minRefresh keeps tokens naming keys that do not exist from making
+	// every verification fetch the key set.
+	minRefresh = 10 * time.Second
+	// minRSABits rejects keys too short to trust.
+	minRSABits = 2048
+)
+
+// KeySet is the JSON Web Key Set (RFC 7517) of a provider. It is fetched on
+// first use and cached, and fetched again early when a token names a key
+// it does not know, which is how providers roll over to a new key.
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]jwt.Method
+	fetched time.Time
+	expires time.Time
+}
+
+func NewKeySet(url string, client *http.Client) *KeySet {
+	return &KeySet{url: url, client: client}
+}
+
+// Method returns the verifier of the key with the given ID. Keys without an
+// ID are found under the empty one.
+func (ks *KeySet) Method(ctx context.Context, kid string) (jwt.Method, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := timeNow()
+	if now.Before(ks.expires) {
+		if m, ok := ks.keys[kid]; ok {
+			return m, nil
+		}
+		if now.Sub(ks.fetched) < minRefresh {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+		}
+	}
+	if err := ks.refresh(ctx, now); err != nil {
+		return nil, err
+	}
+	if m, ok := ks.keys[kid]; ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// N and E are the modulus and exponent of an RSA key.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv and X are the curve and public key of an OKP key.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+func (ks *KeySet) refresh(ctx context.Context, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key set responded %s", resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("key set: %w", err)
+	}
+
+	keys := make(map[string]jwt.Method, len(set.Keys))
+	for _, key := range set.Keys {
+		if _, ok := keys[key.Kid]; ok || key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		// Keys of other types, such as EC keys, are not used by this
+		// service and are skipped rather than failing the whole set.
+		if m, err := key.method(); err == nil {
+			keys[key.Kid] = m
+		}
+	}
+	ks.keys = keys
+	ks.fetched = now
+	ks.expires = now.Add(maxAge(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+func (k jwk) method() (jwt.Method, error) {
+	switch {
+	case k.Kty == "RSA" && (k.Alg == "" || k.Alg == "RS256"):
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < minRSABits || key.E < 3 {
+			return nil, errors.New("weak RSA key")
+		}
+		return jwt.RS256Verifier(key), nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519" && (k.Alg == "" || k.Alg == "EdDSA"):
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("bad Ed25519 key")
+		}
+		return jwt.EdDSAVerifier(ed25519.PublicKey(x)), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// maxAge returns the max-age of a Cache-Control header, or DefaultKeyTTL
+// when there is none.
+func maxAge(cacheControl string) time.Duration {
+	for directive := range strings.SplitSeq(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return DefaultKeyTTL
+}
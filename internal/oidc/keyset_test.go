@@ -0,0 +1,129 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type keySetSuite struct {
+	suite.Suite
+	srv     *httptest.Server
+	keys    []map[string]string
+	header  string
+	fetches int
+	now     time.Time
+	ed      ed25519.PrivateKey
+}
+
+func (s *keySetSuite) SetupSubTest() {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	s.ed = priv
+	s.keys = []map[string]string{{"kty": "OKP", "crv": "Ed25519", "kid": "ed", "x": b64.EncodeToString(pub)}}
+	s.header = ""
+	s.fetches = 0
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches++
+		w.Header().Set("Cache-Control", s.header)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time { return s.now }
+}
+
+func (s *keySetSuite) TearDownSubTest() {
+	s.srv.Close()
+	timeNow = time.Now
+}
+
+func TestKeySetSuite(t *testing.T) {
+	suite.Run(t, new(keySetSuite))
+}
+
+func (s *keySetSuite) verify(ks *KeySet, kid string) error {
+	token, err := jwt.Sign(jwt.EdDSA(s.ed), kid, jwt.Claims{Subject: "1"})
+	s.Require().NoError(err)
+	m, err := ks.Method(context.Background(), kid)
+	if err != nil {
+		return err
+	}
+	return jwt.Parse(token, m, &jwt.Claims{})
+}
+
+func (s *keySetSuite) TestMethod() {
+	s.Run("Ed25519", func() {
+		s.NoError(s.verify(NewKeySet(s.srv.URL, s.srv.Client()), "ed"))
+	})
+	s.Run("unusable keys are skipped", func() {
+		weak, err := rsa.GenerateKey(rand.Reader, 1024)
+		s.Require().NoError(err)
+		s.keys = append(s.keys,
+			map[string]string{"kty": "EC", "crv": "P-256", "kid": "ec"},
+			map[string]string{"kty": "OKP", "crv": "Ed25519", "kid": "enc", "use": "enc", "x": s.keys[0]["x"]},
+			map[string]string{"kty": "RSA", "kid": "weak", "n": b64.EncodeToString(weak.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(weak.E)).Bytes())},
+		)
+		ks := NewKeySet(s.srv.URL, s.srv.Client())
+
+		s.NoError(s.verify(ks, "ed"))
+		for _, kid := range []string{"ec", "enc", "weak"} {
+			_, err := ks.Method(context.Background(), kid)
+			s.ErrorIs(err, ErrUnknownKey, kid)
+		}
+	})
+	s.Run("server error", func() {
+		s.srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+		_, err := NewKeySet(s.srv.URL, s.srv.Client()).Method(context.Background(), "ed")
+		s.EqualError(err, "key set responded 502 Bad Gateway")
+	})
+}
+
+func (s *keySetSuite) TestCache() {
+	s.Run("until max-age", func() {
+		s.header = "public, max-age=60"
+		ks := NewKeySet(s.srv.URL, s.srv.Client())
+		s.Require().NoError(s.verify(ks, "ed"))
+
+		s.now = s.now.Add(59 * time.Second)
+		s.Require().NoError(s.verify(ks, "ed"))
+		s.Equal(1, s.fetches)
+
+		s.now = s.now.Add(time.Second)
+		s.Require().NoError(s.verify(ks, "ed"))
+		s.Equal(2, s.fetches)
+	})
+	s.Run("for an hour by default", func() {
+		ks := NewKeySet(s.srv.URL, s.srv.Client())
+		s.Require().NoError(s.verify(ks, "ed"))
+
+		s.now = s.now.Add(DefaultKeyTTL - time.Second)
+		s.Require().NoError(s.verify(ks, "ed"))
+		s.Equal(1, s.fetches)
+	})
+	s.Run("unknown keys refetch at most every 10 seconds", func() {
+		ks := NewKeySet(s.srv.URL, s.srv.Client())
+		s.Require().NoError(s.verify(ks, "ed"))
+
+		s.ErrorIs(s.verify(ks, "other"), ErrUnknownKey)
+		s.ErrorIs(s.verify(ks, "other"), ErrUnknownKey)
+		s.Equal(1, s.fetches)
+
+		s.now = s.now.Add(minRefresh)
+		s.keys[0]["kid"] = "other"
+		s.NoError(s.verify(ks, "other"))
+		s.Equal(2, s.fetches)
+	})
+}
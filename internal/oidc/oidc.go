@@ -0,0 +1,321 @@
+// Package oidc logs users in with an external OpenID Connect provider using
+// the authorization code flow with PKCE.
+//
+// The endpoints of the provider are discovered from its issuer URL, and ID
+// tokens are verified against its key set, so the only configuration needed
+// is the issuer and the client registered there.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/jwt"
+)
+
+var (
+	ErrDiscovery = errors.New("provider discovery failed")
+	ErrExchange  = errors.New("code exchange failed")
+	ErrIDToken   = errors.New("invalid ID token")
+)
+
+var timeNow = time.Now
+
+const (
+	// DefaultLeeway tolerates clock skew with the provider.
+	DefaultLeeway = time.Minute
+	discoveryPath = "/.well-known/openid-configuration"
+)
+
+// DefaultScopes are requested besides openid when Config.Scopes is empty.
+var DefaultScopes = []string{"email", "profile"}
+
+type Config struct {
+	// Issuer is the URL of the provider exactly as it appears in the iss
+	// claim of its tokens.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the user back with a code.
+	RedirectURL string
+	Scopes      []string
+	Leeway      time.Duration
+	HTTPClient  *http.Client
+}
+
+// Metadata is the part of the discovery document of a provider the client
+// uses.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	// EndSessionEndpoint is empty when the provider does not support
+	// logging out from the client.
+	EndSessionEndpoint string `json:"end_session_endpoint"`
+}
+
+type Client struct {
+	cfg Config
+
+	mu   sync.Mutex
+	meta *Metadata
+	keys *KeySet
+}
+
+func NewClient(cfg Config) *Client {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	if cfg.Leeway == 0 {
+		cfg.Leeway = DefaultLeeway
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Client{cfg: cfg}
+}
+
+// Discover fetches the discovery document on first use and keeps it, so
+// the service can start while the provider is down. A failed fetch is
+// tried again the next time.
+func (c *Client) Discover(ctx context.Context) (*Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.meta != nil {
+		return c.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.Issuer+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: provider responded %s", ErrDiscovery, resp.Status)
+	}
+	var meta Metadata
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	// The issuer must match, or a provider could vouch for users of
+	// another (OpenID Connect Discovery 1.0, section 4.3).
+	if meta.Issuer != c.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match", ErrDiscovery, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: endpoints missing", ErrDiscovery)
+	}
+	c.meta = &meta
+	c.keys = NewKeySet(meta.JWKSURI, c.cfg.HTTPClient)
+	return c.meta, nil
+}
+
+// AuthRequest is what ties a login to the browser that started it. All of
+// it must be kept until the provider redirects back.
+type AuthRequest struct {
+	State string
+	Nonce string
+	// Verifier is the PKCE code verifier; only its challenge is sent.
+	Verifier string
+}
+
+// AuthCodeURL returns the URL of the provider to send the user to.
+func (c *Client) AuthCodeURL(ctx context.Context, ar AuthRequest) (string, error) {
+	meta, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return withQuery(meta.AuthorizationEndpoint, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, c.cfg.Scopes...), " ")},
+		"state":                 {ar.State},
+		"nonce":                 {ar.Nonce},
+		"code_challenge":        {Challenge(ar.Verifier)},
+		"code_challenge_method": {"S256"},
+	})
+}
+
+// Tokens are what the token endpoint returns for a code.
+type Tokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Exchange trades the code the provider redirected back with for tokens.
+// The ID token is not verified; call Verify for that.
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	meta, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if c.cfg.ClientSecret == "" {
+		form.Set("client_id", c.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		// RFC 6749 section 2.3.1 has both form encoded first.
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e tokenError
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Code != "" {
+			return nil, fmt.Errorf("%w: %s", ErrExchange, strings.TrimSpace(e.Code+" "+e.Description))
+		}
+		return nil, fmt.Errorf("%w: provider responded %s", ErrExchange, resp.Status)
+	}
+	var tokens Tokens
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExchange, err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no ID token", ErrExchange)
+	}
+	return &tokens, nil
+}
+
+// IDToken is a verified ID token.
+type IDToken struct {
+	jwt.Claims
+	Nonce           string `json:"nonce,omitempty"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	// Extra has every claim of the token, for the ones the provider adds.
+	Extra map[string]any `json:"-"`
+}
+
+// StringClaim returns a string claim, or an empty string when it is missing or
+// not a string.
+func (t *IDToken) StringClaim(name string) string {
+	s, _ := t.Extra[name].(string)
+	return s
+}
+
+// BoolClaim returns a boolean claim. Some providers send booleans as strings,
+// so "true" counts as well.
+func (t *IDToken) BoolClaim(name string) bool {
+	switch v := t.Extra[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Verify checks the signature and claims of an ID token issued for this
+// client in the login started with nonce (OpenID Connect Core 1.0, section
+// 3.1.3.7).
+func (c *Client) Verify(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	if _, err := c.Discover(ctx); err != nil {
+		return nil, err
+	}
+	header, err := jwt.DecodeHeader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	}
+	method, err := c.keys.Method(ctx, header.Kid)
+	if errors.Is(err, ErrUnknownKey) {
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	} else if err != nil {
+		return nil, err
+	}
+	var payload json.RawMessage
+	if err := jwt.Parse(raw, method, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	}
+	var token IDToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	}
+	if err := json.Unmarshal(payload, &token.Extra); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	}
+
+	err = token.Validate(timeNow(), jwt.Expected{Issuer: c.cfg.Issuer, Audience: c.cfg.ClientID, Leeway: c.cfg.Leeway})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", ErrIDToken, err)
+	case token.ExpiresAt == 0:
+		return nil, fmt.Errorf("%w: token does not expire", ErrIDToken)
+	case token.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", ErrIDToken)
+	case token.AuthorizedParty != "" && token.AuthorizedParty != c.cfg.ClientID,
+		token.AuthorizedParty == "" && len(token.Audience) > 1:
+		return nil, fmt.Errorf("%w: issued to another client", ErrIDToken)
+	case token.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce does not match", ErrIDToken)
+	}
+	return &token, nil
+}
+
+// EndSessionURL returns the URL that logs the user out at the provider and
+// then sends them to postLogoutRedirect when it is not empty, or an empty
+// string when the provider does not support it.
+func (c *Client) EndSessionURL(ctx context.Context, idToken, postLogoutRedirect string) (string, error) {
+	meta, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	if meta.EndSessionEndpoint == "" {
+		return "", nil
+	}
+	query := url.Values{
+		"client_id":     {c.cfg.ClientID},
+		"id_token_hint": {idToken},
+	}
+	if postLogoutRedirect != "" {
+		query.Set("post_logout_redirect_uri", postLogoutRedirect)
+	}
+	return withQuery(meta.EndSessionEndpoint, query)
+}
+
+// withQuery adds query to endpoint, keeping the parameters it already has.
+func withQuery(endpoint string, query url.Values) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for name, values := range query {
+		q[name] = values
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
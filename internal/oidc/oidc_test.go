@@ -0,0 +1,333 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/cloudingcity/todo/internal/oidc/oidctest"
+	"github.com/stretchr/testify/suite"
+)
+
+const redirectURL = "https://todo.example.com/v1/sso/callback"
+
+type oidcSuite struct {
+	suite.Suite
+	provider *oidctest.Provider
+	client   *Client
+	ctx      context.Context
+}
+
+func (s *oidcSuite) SetupSubTest() {
+	s.provider = oidctest.NewProvider("todo", "s3cr:t")
+	s.provider.SetUser(map[string]any{"sub": "248289761001", "email": "jane@example.com", "email_verified": true, "name": "Jane Doe"})
+	s.client = NewClient(Config{
+		Issuer:       s.provider.Issuer(),
+		ClientID:     "todo",
+		ClientSecret: "s3cr:t",
+		RedirectURL:  redirectURL,
+		HTTPClient:   s.provider.Client(),
+	})
+	s.ctx = context.Background()
+}
+
+func (s *oidcSuite) TearDownSubTest() {
+	s.provider.Close()
+}
+
+func TestOIDCSuite(t *testing.T) {
+	suite.Run(t, new(oidcSuite))
+}
+
+// login goes through the provider and returns the parameters it redirected
+// back with.
+func (s *oidcSuite) login(ar AuthRequest) url.Values {
+	authURL, err := s.client.AuthCodeURL(s.ctx, ar)
+	s.Require().NoError(err)
+	back, err := s.provider.Login(authURL)
+	s.Require().NoError(err)
+	return back.Query()
+}
+
+// TestChallenge checks the example of RFC 7636 appendix B.
+func (s *oidcSuite) TestChallenge() {
+	s.Run("S256", func() {
+		s.Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+	})
+	s.Run("verifier", func() {
+		verifier := NewVerifier()
+		s.Len(verifier, 43)
+		s.NotEqual(verifier, NewVerifier())
+	})
+}
+
+func (s *oidcSuite) TestLogin() {
+	s.Run("success", func() {
+		ar := AuthRequest{State: "state-1", Nonce: "nonce-1", Verifier: NewVerifier()}
+		authURL, err := s.client.AuthCodeURL(s.ctx, ar)
+		s.Require().NoError(err)
+		u, err := url.Parse(authURL)
+		s.Require().NoError(err)
+		s.Equal(s.provider.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+		s.Equal(url.Values{
+			"response_type":         {"code"},
+			"client_id":             {"todo"},
+			"redirect_uri":          {redirectURL},
+			"scope":                 {"openid email profile"},
+			"state":                 {"state-1"},
+			"nonce":                 {"nonce-1"},
+			"code_challenge":        {Challenge(ar.Verifier)},
+			"code_challenge_method": {"S256"},
+		}, u.Query())
+
+		back, err := s.provider.Login(authURL)
+		s.Require().NoError(err)
+		s.Equal(redirectURL, back.Scheme+"://"+back.Host+back.Path)
+		s.Equal("state-1", back.Query().Get("state"))
+
+		tokens, err := s.client.Exchange(s.ctx, back.Query().Get("code"), ar.Verifier)
+		s.Require().NoError(err)
+		token, err := s.client.Verify(s.ctx, tokens.IDToken, "nonce-1")
+		s.Require().NoError(err)
+		s.Equal("248289761001", token.Subject)
+		s.Equal(s.provider.Issuer(), token.Issuer)
+		s.Equal("jane@example.com", token.StringClaim("email"))
+		s.Equal("Jane Doe", token.StringClaim("name"))
+		s.True(token.BoolClaim("email_verified"))
+		s.False(token.BoolClaim("phone_number_verified"))
+	})
+	s.Run("denied", func() {
+		s.provider.Deny("access_denied")
+
+		back := s.login(AuthRequest{State: "state-1", Nonce: "nonce-1", Verifier: NewVerifier()})
+		s.Equal("access_denied", back.Get("error"))
+		s.Equal("state-1", back.Get("state"))
+	})
+}
+
+func (s *oidcSuite) TestExchange() {
+	tests := []struct {
+		desc    string
+		client  func() *Client
+		code    func(code string) string
+		wantErr string
+	}{
+		{
+			desc:    "wrong verifier",
+			code:    func(code string) string { return code },
+			wantErr: "code exchange failed: invalid_grant code_verifier does not match",
+		},
+		{
+			desc:    "unknown code",
+			code:    func(string) string { return "made-up" },
+			wantErr: "code exchange failed: invalid_grant unknown or used code",
+		},
+		{
+			desc: "wrong secret",
+			client: func() *Client {
+				return NewClient(Config{Issuer: s.provider.Issuer(), ClientID: "todo", ClientSecret: "other", RedirectURL: redirectURL, HTTPClient: s.provider.Client()})
+			},
+			code:    func(code string) string { return code },
+			wantErr: "code exchange failed: invalid_client",
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.client != nil {
+				s.client = tt.client()
+			}
+			back := s.login(AuthRequest{Verifier: NewVerifier()})
+
+			_, err := s.client.Exchange(s.ctx, tt.code(back.Get("code")), NewVerifier())
+			s.ErrorIs(err, ErrExchange)
+			s.EqualError(err, tt.wantErr)
+		})
+	}
+	s.Run("code is single use", func() {
+		ar := AuthRequest{Verifier: NewVerifier()}
+		code := s.login(ar).Get("code")
+
+		_, err := s.client.Exchange(s.ctx, code, ar.Verifier)
+		s.Require().NoError(err)
+		_, err = s.client.Exchange(s.ctx, code, ar.Verifier)
+		s.ErrorIs(err, ErrExchange)
+	})
+}
+
+func (s *oidcSuite) TestVerify() {
+	claims := func(change func(map[string]any)) func() string {
+		return func() string {
+			c := s.provider.IDToken("nonce-1")
+			change(c)
+			return s.provider.Sign(c)
+		}
+	}
+	tests := []struct {
+		desc    string
+		token   func() string
+		wantErr string
+	}{
+		{
+			desc:  "valid",
+			token: claims(func(map[string]any) {}),
+		},
+		{
+			desc:  "expired within leeway",
+			token: claims(func(c map[string]any) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() }),
+		},
+		{
+			desc:  "authorized party",
+			token: claims(func(c map[string]any) { c["aud"] = []string{"todo", "other"}; c["azp"] = "todo" }),
+		},
+		{
+			desc:    "expired",
+			token:   claims(func(c map[string]any) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }),
+			wantErr: "invalid ID token: token is expired",
+		},
+		{
+			desc:    "does not expire",
+			token:   claims(func(c map[string]any) { delete(c, "exp") }),
+			wantErr: "invalid ID token: token does not expire",
+		},
+		{
+			desc:    "other issuer",
+			token:   claims(func(c map[string]any) { c["iss"] = "https://evil.example.com" }),
+			wantErr: "invalid ID token: unexpected issuer",
+		},
+		{
+			desc:    "other client",
+			token:   claims(func(c map[string]any) { c["aud"] = "other" }),
+			wantErr: "invalid ID token: unexpected audience",
+		},
+		{
+			desc:    "several audiences without authorized party",
+			token:   claims(func(c map[string]any) { c["aud"] = []string{"todo", "other"} }),
+			wantErr: "invalid ID token: issued to another client",
+		},
+		{
+			desc:    "authorized party is another client",
+			token:   claims(func(c map[string]any) { c["azp"] = "other" }),
+			wantErr: "invalid ID token: issued to another client",
+		},
+		{
+			desc:    "no subject",
+			token:   claims(func(c map[string]any) { delete(c, "sub") }),
+			wantErr: "invalid ID token: no subject",
+		},
+		{
+			desc:    "other nonce",
+			token:   claims(func(c map[string]any) { c["nonce"] = "nonce-2" }),
+			wantErr: "invalid ID token: nonce does not match",
+		},
+		{
+			desc:    "no nonce",
+			token:   claims(func(c map[string]any) { delete(c, "nonce") }),
+			wantErr: "invalid ID token: nonce does not match",
+		},
+		{
+			desc: "signed with a key of someone else",
+			token: func() string {
+				key, err := rsa.GenerateKey(rand.Reader, 2048)
+				s.Require().NoError(err)
+				token, err := jwt.Sign(jwt.RS256(key), "key-1", s.provider.IDToken("nonce-1"))
+				s.Require().NoError(err)
+				return token
+			},
+			wantErr: "invalid ID token: invalid signature",
+		},
+		{
+			desc: "unknown key",
+			token: func() string {
+				token, err := jwt.Sign(jwt.HS256([]byte("0123456789abcdef0123456789abcdef")), "key-9", s.provider.IDToken("nonce-1"))
+				s.Require().NoError(err)
+				return token
+			},
+			wantErr: `invalid ID token: unknown signing key: "key-9"`,
+		},
+		{
+			desc: "HMAC keyed with a known key ID",
+			token: func() string {
+				token, err := jwt.Sign(jwt.HS256([]byte("0123456789abcdef0123456789abcdef")), "key-1", s.provider.IDToken("nonce-1"))
+				s.Require().NoError(err)
+				return token
+			},
+			wantErr: `invalid ID token: unexpected signing algorithm: "HS256"`,
+		},
+		{
+			desc:    "malformed",
+			token:   func() string { return "a.b" },
+			wantErr: "invalid ID token: malformed token",
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			token, err := s.client.Verify(s.ctx, tt.token(), "nonce-1")
+			if tt.wantErr != "" {
+				s.ErrorIs(err, ErrIDToken)
+				s.EqualError(err, tt.wantErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal("248289761001", token.Subject)
+		})
+	}
+}
+
+func (s *oidcSuite) TestKeyRotation() {
+	s.Run("new key is fetched", func() {
+		old := s.provider.Sign(s.provider.IDToken(""))
+		_, err := s.client.Verify(s.ctx, old, "")
+		s.Require().NoError(err)
+		_, err = s.client.Verify(s.ctx, old, "")
+		s.Require().NoError(err)
+		s.Equal(1, s.provider.JWKSFetches(), "the key set is cached")
+
+		s.provider.RotateKey()
+		timeNow = func() time.Time { return time.Now().Add(minRefresh) }
+		defer func() { timeNow = time.Now }()
+		_, err = s.client.Verify(s.ctx, s.provider.Sign(s.provider.IDToken("")), "")
+		s.Require().NoError(err)
+		s.Equal(2, s.provider.JWKSFetches())
+		_, err = s.client.Verify(s.ctx, old, "")
+		s.NoError(err, "tokens signed with the old key stay valid")
+	})
+}
+
+func (s *oidcSuite) TestDiscover() {
+	s.Run("cached", func() {
+		_, err := s.client.Discover(s.ctx)
+		s.Require().NoError(err)
+		s.provider.Close()
+
+		meta, err := s.client.Discover(s.ctx)
+		s.Require().NoError(err)
+		s.Equal(s.provider.URL+"/token", meta.TokenEndpoint)
+	})
+	s.Run("issuer does not match", func() {
+		client := NewClient(Config{Issuer: s.provider.Issuer() + "/tenant", HTTPClient: s.provider.Client()})
+		_, err := client.Discover(s.ctx)
+		s.ErrorIs(err, ErrDiscovery)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"issuer": "https://evil.example.com", "authorization_endpoint": "a", "token_endpoint": "t", "jwks_uri": "j"}`))
+		}))
+		defer srv.Close()
+		_, err = NewClient(Config{Issuer: srv.URL}).Discover(s.ctx)
+		s.EqualError(err, `provider discovery failed: issuer "https://evil.example.com" does not match`)
+	})
+}
+
+func (s *oidcSuite) TestEndSessionURL() {
+	s.Run("success", func() {
+		got, err := s.client.EndSessionURL(s.ctx, "id-token", "https://todo.example.com/")
+		s.Require().NoError(err)
+		s.Equal(s.provider.URL+"/logout?client_id=todo&id_token_hint=id-token&post_logout_redirect_uri=https%3A%2F%2Ftodo.example.com%2F", got)
+	})
+}
@@ -0,0 +1,324 @@
+// Package oidctest runs a stand-in OpenID Connect provider for tests, the way
+// net/http/httptest runs a stand-in server.
+//
+// The provider logs in whoever is set with SetUser without asking, and
+// checks everything a client sends like a real provider would: the client
+// credentials, the redirect URI and the PKCE verifier.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/jwt"
+)
+
+var b64 = base64.RawURLEncoding
+
+// TokenTTL is how long the ID tokens of the provider are valid.
+const TokenTTL = time.Hour
+
+type key struct {
+	id      string
+	private *rsa.PrivateKey
+}
+
+type grant struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	claims      map[string]any
+}
+
+// Provider is an OpenID Connect provider listening on a local port. Its
+// issuer is the URL of the server.
+type Provider struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	mu          sync.Mutex
+	keys        []key
+	user        map[string]any
+	denial      string
+	grants      map[string]grant
+	jwksFetches int
+	logouts     []string
+}
+
+// NewProvider starts a provider with one signing key and a registered
+// client. Close it when done.
+func NewProvider(clientID, clientSecret string) *Provider {
+	p := &Provider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		grants:       make(map[string]grant),
+	}
+	p.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /logout", p.logout)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+// Issuer is the iss claim of the tokens of the provider.
+func (p *Provider) Issuer() string {
+	return p.URL
+}
+
+// SetUser sets the claims of the user the next logins are for. They must
+// include sub.
+func (p *Provider) SetUser(claims map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = maps.Clone(claims)
+}
+
+// Deny makes the next logins fail with the given error code, like
+// access_denied when the user declines. An empty code lets them through
+// again.
+func (p *Provider) Deny(code string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.denial = code
+}
+
+// RotateKey signs with a new key from now on. The old keys stay in the key
+// set, as they would while tokens signed with them are still valid.
+func (p *Provider) RotateKey() {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append([]key{{id: fmt.Sprintf("key-%d", len(p.keys)+1), private: private}}, p.keys...)
+}
+
+// JWKSFetches returns how many times the key set was fetched.
+func (p *Provider) JWKSFetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksFetches
+}
+
+// Logouts returns the ID token hints of the logouts so far.
+func (p *Provider) Logouts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.logouts...)
+}
+
+// Sign returns an ID token with the given claims signed with the current
+// key, for tests that need a token the provider would not issue.
+func (p *Provider) Sign(claims map[string]any) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sign(claims)
+}
+
+// IDToken returns the claims of the ID token the provider would issue to
+// the client for the current user.
+func (p *Provider) IDToken(nonce string) map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.idToken(p.user, nonce)
+}
+
+func (p *Provider) idToken(user map[string]any, nonce string) map[string]any {
+	now := time.Now()
+	claims := map[string]any{}
+	maps.Copy(claims, user)
+	maps.Copy(claims, map[string]any{
+		"iss": p.URL,
+		"aud": p.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(TokenTTL).Unix(),
+	})
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	return claims
+}
+
+func (p *Provider) sign(claims map[string]any) string {
+	current := p.keys[0]
+	token, err := jwt.Sign(jwt.RS256(current.private), current.id, claims)
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// Login follows authURL like a browser would and returns the URL the
+// provider redirects back to.
+func (p *Provider) Login(authURL string) (*url.URL, error) {
+	client := p.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return nil, fmt.Errorf("provider responded %s", resp.Status)
+	}
+	return resp.Location()
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"end_session_endpoint":                  p.URL + "/logout",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirect.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != p.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	back := redirect.Query()
+	back.Set("state", q.Get("state"))
+	p.mu.Lock()
+	switch {
+	case q.Get("response_type") != "code":
+		back.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		back.Set("error", "invalid_request")
+		back.Set("error_description", "PKCE with S256 is required")
+	case p.denial != "":
+		back.Set("error", p.denial)
+	case p.user == nil:
+		back.Set("error", "login_required")
+	default:
+		code := rand.Text()
+		p.grants[code] = grant{
+			redirectURI: q.Get("redirect_uri"),
+			challenge:   q.Get("code_challenge"),
+			nonce:       q.Get("nonce"),
+			claims:      maps.Clone(p.user),
+		}
+		back.Set("code", code)
+	}
+	p.mu.Unlock()
+
+	redirect.RawQuery = back.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	}
+	if !ok || id != p.ClientID || secret != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	code := r.PostFormValue("code")
+	g, ok := p.grants[code]
+	// Codes are single use.
+	delete(p.grants, code)
+	if err := g.check(ok, r.PostFormValue("redirect_uri"), r.PostFormValue("code_verifier")); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": err.Error()})
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   int(TokenTTL.Seconds()),
+		"id_token":     p.sign(p.idToken(g.claims, g.nonce)),
+	})
+}
+
+func (g grant) check(ok bool, redirectURI, verifier string) error {
+	sum := sha256.Sum256([]byte(verifier))
+	switch {
+	case !ok:
+		return errors.New("unknown or used code")
+	case redirectURI != g.redirectURI:
+		return errors.New("redirect_uri does not match")
+	case b64.EncodeToString(sum[:]) != g.challenge:
+		return errors.New("code_verifier does not match")
+	}
+	return nil
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksFetches++
+	keys := make([]map[string]string, 0, len(p.keys))
+	for _, k := range p.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": k.id,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   b64.EncodeToString(k.private.N.Bytes()),
+			"e":   b64.EncodeToString(big.NewInt(int64(k.private.E)).Bytes()),
+		})
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (p *Provider) logout(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.logouts = append(p.logouts, r.URL.Query().Get("id_token_hint"))
+	p.mu.Unlock()
+	if redirect := r.URL.Query().Get("post_logout_redirect_uri"); redirect != "" {
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
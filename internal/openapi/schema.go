@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Mode int
+
+const (
+	// Input marks only fields tagged binding:"required" as required.
+	Input Mode = iota
+	// Output marks every non-pointer field without omitempty as required.
+	Output
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func SchemaOf(v any, mode Mode) *Schema {
+	return schemaOf(reflect.TypeOf(v), mode)
+}
+
+func schemaOf(t reflect.Type, mode Mode) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := schemaOf(t.Elem(), mode)
+		if typ, ok := s.Type.(string); ok {
+			s.Type = []string{typ, "null"}
+		}
+		return s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), mode)}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return structSchema(t, mode)
+	}
+	return &Schema{}
+}
+
+func structSchema(t reflect.Type, mode Mode) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("uri"); ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup("form"); ok {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := schemaOf(f.Type, mode)
+		applyBinding(prop, f.Tag.Get("binding"))
+		s.Properties[name] = prop
+
+		if isRequired(f, opts, mode) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	slices.Sort(s.Required)
+	return s
+}
+
+func isRequired(f reflect.StructField, jsonOpts string, mode Mode) bool {
+	if slices.Contains(strings.Split(f.Tag.Get("binding"), ","), "required") {
+		return true
+	}
+	if mode != Output {
+		return false
+	}
+	return f.Type.Kind() != reflect.Pointer && !slices.Contains(strings.Split(jsonOpts, ","), "omitempty")
+}
+
+// applyBinding mirrors the gin validator rules oneof, min and max onto the
+// schema so the document and the handler binding cannot disagree.
+func applyBinding(s *Schema, binding string) {
+	for _, rule := range strings.Split(binding, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			s.limit(name == "min", n)
+		}
+	}
+}
+
+func (s *Schema) limit(min bool, n int) {
+	switch {
+	case s.allows("string"):
+		if min {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	case s.allows("array"):
+		if min {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	case s.allows("integer"), s.allows("number"):
+		f := float64(n)
+		if min {
+			s.Minimum = &f
+		} else {
+			s.Maximum = &f
+		}
+	}
+}
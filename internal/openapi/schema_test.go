@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type schemaSuite struct {
+	suite.Suite
+}
+
+func TestSchemaSuite(t *testing.T) {
+	suite.Run(t, new(schemaSuite))
+}
+
+type sampleReq struct {
+	ID      int       `uri:"id" binding:"required"`
+	Name    string    `json:"name" binding:"required"`
+	Note    *string   `json:"note"`
+	Tags    []string  `json:"tags,omitempty"`
+	At      time.Time `json:"at"`
+	Ignored string    `json:"-"`
+}
+
+type constrainedReq struct {
+	Mode  string `json:"mode" binding:"omitempty,oneof=a b"`
+	Name  string `json:"name" binding:"required,min=1,max=10"`
+	Count int    `json:"count" binding:"min=1"`
+	IDs   []int  `json:"ids" binding:"required,min=1,max=100"`
+}
+
+func (s *schemaSuite) TestSchemaOf() {
+	tests := []struct {
+		desc string
+		mode Mode
+		want *Schema
+	}{
+		{
+			desc: "input",
+			mode: Input,
+			want: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name": {Type: "string"},
+					"note": {Type: []string{"string", "null"}},
+					"tags": {Type: "array", Items: &Schema{Type: "string"}},
+					"at":   {Type: "string", Format: "date-time"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			desc: "output",
+			mode: Output,
+			want: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"name": {Type: "string"},
+					"note": {Type: []string{"string", "null"}},
+					"tags": {Type: "array", Items: &Schema{Type: "string"}},
+					"at":   {Type: "string", Format: "date-time"},
+				},
+				Required: []string{"at", "name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.Equal(tt.want, SchemaOf(sampleReq{}, tt.mode))
+		})
+	}
+}
+
+func (s *schemaSuite) TestSchemaOfBinding() {
+	s.Equal(&Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"mode":  {Type: "string", Enum: []any{"a", "b"}},
+			"name":  {Type: "string", MinLength: lo.ToPtr(1), MaxLength: lo.ToPtr(10)},
+			"count": {Type: "integer", Minimum: lo.ToPtr(1.0)},
+			"ids":   {Type: "array", Items: &Schema{Type: "integer"}, MinItems: lo.ToPtr(1), MaxItems: lo.ToPtr(100)},
+		},
+		Required: []string{"ids", "name"},
+	}, SchemaOf(constrainedReq{}, Input))
+}
+
+func (s *schemaSuite) TestPath() {
+	tests := []struct {
+		desc string
+		path string
+		want string
+	}{
+		{desc: "static", path: "/v1/todos", want: "/v1/todos"},
+		{desc: "param", path: "/v1/todos/:id", want: "/v1/todos/{id}"},
+		{desc: "nested params", path: "/v1/projects/:id/todos/:todoID", want: "/v1/projects/{id}/todos/{todoID}"},
+		{desc: "wildcard", path: "/files/*path", want: "/files/{path}"},
+		{desc: "custom method", path: "/v1/todos:batchCreate", want: "/v1/todos:batchCreate"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.Equal(tt.want, Path(tt.path))
+		})
+	}
+}
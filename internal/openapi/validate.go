@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// DecodeJSON decodes b keeping numbers as json.Number so integer checks stay
+// exact.
+func DecodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Validate checks a decoded JSON value against s, resolving component
+// references through the document.
+func (d *Document) Validate(s *Schema, v any) error {
+	return d.validate("", s, v)
+}
+
+// ParseParam converts a raw path or query parameter into a value matching the
+// parameter schema and validates it.
+func (d *Document) ParseParam(p Parameter, raw string) (any, error) {
+	s := d.Resolve(p.Schema)
+	var v any = raw
+	switch {
+	case s.allows("integer"):
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, &ValidationError{Path: p.Name, Message: fmt.Sprintf("invalid integer %q", raw)}
+		}
+		v = json.Number(strconv.FormatInt(n, 10))
+	case s.allows("number"):
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, &ValidationError{Path: p.Name, Message: fmt.Sprintf("invalid number %q", raw)}
+		}
+		v = json.Number(raw)
+	case s.allows("boolean"):
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &ValidationError{Path: p.Name, Message: fmt.Sprintf("invalid boolean %q", raw)}
+		}
+		v = b
+	}
+	if err := d.validate(p.Name, s, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (d *Document) validate(path string, s *Schema, v any) error {
+	s = d.Resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if s.Type != nil {
+		typ := typeOf(v)
+		if !s.allows(typ) && !(typ == "integer" && s.allows("number")) {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", s.typeNames(), typ)}
+		}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be one of %v", s.Enum)}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return s.validateString(path, v)
+	case json.Number:
+		return s.validateNumber(path, v)
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.MinItems)}
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)}
+		}
+		for i, item := range v {
+			if err := d.validate(join(path, strconv.Itoa(i)), s.Items, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return d.validateObject(path, s, v)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(path string, s *Schema, v map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			return &ValidationError{Path: join(path, name), Message: "is required"}
+		}
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return &ValidationError{Path: join(path, name), Message: "unknown field"}
+			}
+			continue
+		}
+		if err := d.validate(join(path, name), prop, v[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(path, v string) error {
+	n := utf8.RuneCountInString(v)
+	if s.MinLength != nil && n < *s.MinLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)}
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+	}
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return &ValidationError{Path: path, Message: "must be an RFC 3339 date-time"}
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return &ValidationError{Path: path, Message: "must be a date (YYYY-MM-DD)"}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(path string, v json.Number) error {
+	f, err := v.Float64()
+	if err != nil {
+		return &ValidationError{Path: path, Message: "invalid number"}
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", *s.Minimum)}
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", *s.Maximum)}
+	}
+	return nil
+}
+
+func (s *Schema) allows(typ string) bool {
+	switch t := s.Type.(type) {
+	case string:
+		return t == typ
+	case []string:
+		return slices.Contains(t, typ)
+	case []any:
+		return slices.Contains(t, any(typ))
+	}
+	return s.Type == nil
+}
+
+func (s *Schema) typeNames() string {
+	switch t := s.Type.(type) {
+	case []string:
+		return strings.Join(t, " or ")
+	case []any:
+		names := make([]string, len(t))
+		for i, n := range t {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(s.Type)
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func equal(a, b any) bool {
+	if an, ok := a.(json.Number); ok {
+		a = an.String()
+	}
+	if bn, ok := b.(json.Number); ok {
+		b = bn.String()
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
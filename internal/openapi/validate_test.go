@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type validateSuite struct {
+	suite.Suite
+	doc *Document
+}
+
+func (s *validateSuite) SetupTest() {
+	s.doc = New("test", "1.0.0")
+	s.doc.Components.Schemas["Item"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":  {Type: "string", MinLength: lo.ToPtr(1), MaxLength: lo.ToPtr(5)},
+			"count": {Type: "integer", Minimum: lo.ToPtr(0.0)},
+			"note":  {Type: []string{"string", "null"}},
+			"at":    {Type: "string", Format: "date-time"},
+			"kind":  {Type: "string", Enum: []any{"a", "b"}},
+			"tags":  {Type: "array", Items: &Schema{Type: "string"}},
+		},
+		Required:             []string{"name"},
+		AdditionalProperties: lo.ToPtr(false),
+	}
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, new(validateSuite))
+}
+
+func (s *validateSuite) TestValidate() {
+	tests := []struct {
+		desc    string
+		body    string
+		wantErr string
+	}{
+		{desc: "valid", body: `{"name": "x", "count": 1, "note": null, "at": "2024-01-02T03:04:05Z", "kind": "a", "tags": ["t"]}`},
+		{desc: "missing required", body: `{"count": 1}`, wantErr: "name: is required"},
+		{desc: "wrong type", body: `{"name": 1}`, wantErr: "name: expected string, got integer"},
+		{desc: "not integer", body: `{"name": "x", "count": 1.5}`, wantErr: "count: expected integer, got number"},
+		{desc: "below minimum", body: `{"name": "x", "count": -1}`, wantErr: "count: must be >= 0"},
+		{desc: "too short", body: `{"name": ""}`, wantErr: "name: must be at least 1 characters"},
+		{desc: "too long", body: `{"name": "abcdef"}`, wantErr: "name: must be at most 5 characters"},
+		{desc: "bad date-time", body: `{"name": "x", "at": "yesterday"}`, wantErr: "at: must be an RFC 3339 date-time"},
+		{desc: "not in enum", body: `{"name": "x", "kind": "c"}`, wantErr: "kind: must be one of [a b]"},
+		{desc: "array item", body: `{"name": "x", "tags": ["t", 1]}`, wantErr: "tags.1: expected string, got integer"},
+		{desc: "unknown field", body: `{"name": "x", "extra": true}`, wantErr: "extra: unknown field"},
+		{desc: "not an object", body: `[]`, wantErr: "expected object, got array"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			v, err := DecodeJSON([]byte(tt.body))
+			s.Require().NoError(err)
+
+			err = s.doc.Validate(&Schema{Ref: "#/components/schemas/Item"}, v)
+			if tt.wantErr == "" {
+				s.NoError(err)
+				return
+			}
+			s.EqualError(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *validateSuite) TestParseParam() {
+	tests := []struct {
+		desc    string
+		param   Parameter
+		raw     string
+		wantErr string
+	}{
+		{desc: "integer", param: Parameter{Name: "id", Schema: &Schema{Type: "integer"}}, raw: "1"},
+		{desc: "invalid integer", param: Parameter{Name: "id", Schema: &Schema{Type: "integer"}}, raw: "x", wantErr: `id: invalid integer "x"`},
+		{desc: "minimum", param: Parameter{Name: "id", Schema: &Schema{Type: "integer", Minimum: lo.ToPtr(1.0)}}, raw: "0", wantErr: "id: must be >= 1"},
+		{desc: "boolean", param: Parameter{Name: "done", Schema: &Schema{Type: "boolean"}}, raw: "true"},
+		{desc: "invalid boolean", param: Parameter{Name: "done", Schema: &Schema{Type: "boolean"}}, raw: "maybe", wantErr: `done: invalid boolean "maybe"`},
+		{desc: "string", param: Parameter{Name: "q", Schema: &Schema{Type: "string"}}, raw: "anything"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			_, err := s.doc.ParseParam(tt.param, tt.raw)
+			if tt.wantErr == "" {
+				s.NoError(err)
+				return
+			}
+			s.EqualError(err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type passwordSuite struct {
+	suite.Suite
+}
+
+func TestPasswordSuite(t *testing.T) {
+	suite.Run(t, new(passwordSuite))
+}
+
+// fast keeps the tests quick. Verify reads the parameters from the hash.
+var fast = Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func (s *passwordSuite) TestHash() {
+	s.Run("default parameters", func() {
+		encoded, err := Hash("correct horse")
+		s.Require().NoError(err)
+		s.True(strings.HasPrefix(encoded, "$argon2id$v=19$m=65536,t=3,p=4$"), encoded)
+
+		ok, err := Verify("correct horse", encoded)
+		s.Require().NoError(err)
+		s.True(ok)
+	})
+	s.Run("salted", func() {
+		a, _ := HashWith(fast, "correct horse")
+		b, _ := HashWith(fast, "correct horse")
+		s.NotEqual(a, b)
+	})
+}
+
+func (s *passwordSuite) TestVerify() {
+	encoded, err := HashWith(fast, "correct horse")
+	s.Require().NoError(err)
+
+	tests := []struct {
+		desc     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  error
+	}{
+		{desc: "match", password: "correct horse", encoded: encoded, want: true},
+		{desc: "mismatch", password: "battery staple", encoded: encoded},
+		{desc: "other algorithm", password: "x", encoded: "$argon2i$v=19$m=64,t=1,p=1$c29tZXNhbHQ$aGFzaA", wantErr: ErrInvalidHash},
+		{desc: "other version", password: "x", encoded: "$argon2id$v=16$m=64,t=1,p=1$c29tZXNhbHQ$aGFzaA", wantErr: ErrInvalidHash},
+		{desc: "bad parameters", password: "x", encoded: "$argon2id$v=19$m=0,t=1,p=1$c29tZXNhbHQ$aGFzaA", wantErr: ErrInvalidHash},
+		{desc: "bad salt", password: "x", encoded: "$argon2id$v=19$m=64,t=1,p=1$!!$aGFzaA", wantErr: ErrInvalidHash},
+		{desc: "truncated", password: "x", encoded: "$argon2id$v=19$m=64,t=1,p=1", wantErr: ErrInvalidHash},
+		{desc: "empty", password: "x", encoded: "", wantErr: ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			got, err := Verify(tt.password, tt.encoded)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(tt.want, got)
+		})
+	}
+}
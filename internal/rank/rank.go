@@ -0,0 +1,117 @@
+// Package rank generates lexicographic rank keys for manual ordering. A key
+// can always be generated between two others, so moving one item never
+// renumbers its neighbours.
+package rank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLength is the key length past which callers should Spread their keys
+// again. Keys grow by roughly one digit each time the same gap is halved.
+const MaxLength = 24
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var ErrInvalid = errors.New("invalid rank key")
+
+// Between returns a key sorting strictly between a and b. An empty a means
+// before every key and an empty b means after every key.
+func Between(a, b string) (string, error) {
+	if err := validate(a); err != nil {
+		return "", err
+	}
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if a != "" && b != "" && a >= b {
+		return "", fmt.Errorf("%w: %q is not before %q", ErrInvalid, a, b)
+	}
+	return midpoint(a, b), nil
+}
+
+// Spread returns n evenly spaced keys of equal length, leaving room to insert
+// between any two of them.
+func Spread(n int) []string {
+	width := 1
+	for capacity := base; capacity < 2*(n+1); capacity *= base {
+		width++
+	}
+	capacity := 1
+	for range width {
+		capacity *= base
+	}
+
+	keys := make([]string, n)
+	for i := range n {
+		keys[i] = strings.TrimRight(encode((i+1)*capacity/(n+1), width), "0")
+	}
+	return keys
+}
+
+// midpoint follows the digit-by-digit construction used for fractional
+// indexing: keys never end in the zero digit, so a key between any two
+// distinct keys always exists.
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(digits, a[0])
+	}
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(digits[(lo+hi+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(digits[lo]) + midpoint(rest, "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func encode(v, width int) string {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = digits[v%base]
+		v /= base
+	}
+	return string(b)
+}
+
+func validate(key string) error {
+	for i := range len(key) {
+		if strings.IndexByte(digits, key[i]) == -1 {
+			return fmt.Errorf("%w: %q", ErrInvalid, key)
+		}
+	}
+	if strings.HasSuffix(key, digits[:1]) {
+		return fmt.Errorf("%w: %q ends in zero", ErrInvalid, key)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rank
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type rankSuite struct {
+	suite.Suite
+}
+
+func TestRankSuite(t *testing.T) {
+	suite.Run(t, new(rankSuite))
+}
+
+func (s *rankSuite) TestBetween() {
+	tests := []struct {
+		desc    string
+		a, b    string
+		want    string
+		wantErr error
+	}{
+		{desc: "empty list", want: "i"},
+		{desc: "after last", a: "i", want: "r"},
+		{desc: "before first", b: "i", want: "9"},
+		{desc: "wide gap", a: "1", b: "9", want: "5"},
+		{desc: "adjacent digits", a: "1", b: "2", want: "1i"},
+		{desc: "adjacent digits with longer upper bound", a: "1", b: "2a", want: "2"},
+		{desc: "common prefix", a: "1", b: "10i", want: "109"},
+		{desc: "after the last digit", a: "z", want: "zi"},
+		{desc: "before the first digit", b: "1", want: "0i"},
+		{desc: "out of order", a: "b", b: "a", wantErr: ErrInvalid},
+		{desc: "equal", a: "a", b: "a", wantErr: ErrInvalid},
+		{desc: "trailing zero", a: "a0", wantErr: ErrInvalid},
+		{desc: "invalid digit", a: "A", wantErr: ErrInvalid},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			got, err := Between(tt.a, tt.b)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *rankSuite) TestBetweenRepeatedly() {
+	s.Run("insert at the front", func() {
+		b := ""
+		for range 200 {
+			key, err := Between("", b)
+			s.Require().NoError(err)
+			if b != "" {
+				s.Less(key, b)
+			}
+			b = key
+		}
+	})
+	s.Run("halve the same gap", func() {
+		a, b := "a", "b"
+		for range 200 {
+			key, err := Between(a, b)
+			s.Require().NoError(err)
+			s.Less(a, key)
+			s.Less(key, b)
+			b = key
+		}
+	})
+}
+
+func (s *rankSuite) TestSpread() {
+	for _, n := range []int{0, 1, 2, 17, 35, 36, 1000} {
+		keys := Spread(n)
+		s.Len(keys, n)
+		s.True(slices.IsSorted(keys))
+		s.Len(slices.Compact(slices.Clone(keys)), n)
+		for _, key := range keys {
+			s.NoError(validate(key))
+			s.NotEmpty(key)
+		}
+		for i := 1; i < n; i++ {
+			_, err := Between(keys[i-1], keys[i])
+			s.NoError(err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var timeNow = time.Now
+
+// Limit allows a client Requests requests at once, and refills at the same
+// number of requests per Per. A zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit like "600/1m". "0" and "off" parse to the zero
+// Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "0" || s == "off" {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q is not requests/period", s)
+	}
+	var (
+		l   Limit
+		err error
+	)
+	if l.Requests, err = strconv.Atoi(requests); err != nil || l.Requests < 1 {
+		return Limit{}, fmt.Errorf("rate limit %q needs a positive number of requests", s)
+	}
+	if l.Per, err = time.ParseDuration(per); err != nil || l.Per <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q needs a positive period", s)
+	}
+	return l, nil
+}
+
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// interval is how long one request takes to refill.
+func (l Limit) interval() float64 {
+	return float64(l.Per) / float64(l.Requests)
+}
+
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is how many more requests are allowed right away.
+	Remaining int
+	// Reset is how long until the client has the full Limit again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, zero when
+	// it is allowed right away.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	// Allow takes a request from the bucket of key.
+	Allow(key string) Result
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens that came in since the bucket was last updated.
+func (b *bucket) refill(limit Limit, now time.Time) {
+	elapsed := float64(now.Sub(b.updated))
+	b.tokens = min(float64(limit.Requests), b.tokens+elapsed/limit.interval())
+	b.updated = now
+}
+
+// memoryLimiter keeps a token bucket per key. Buckets that refilled are
+// dropped, so memory only grows with the clients active within one period.
+type memoryLimiter struct {
+	limit Limit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	pruned  time.Time
+}
+
+func NewMemoryLimiter(limit Limit) Limiter {
+	return &memoryLimiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *memoryLimiter) Allow(key string) Result {
+	if l.limit.IsZero() {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := timeNow()
+	if now.Sub(l.pruned) >= l.limit.Per {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Requests), updated: now}
+		l.buckets[key] = b
+	}
+	b.refill(l.limit, now)
+
+	res := Result{Limit: l.limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * l.limit.interval())
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((float64(l.limit.Requests) - b.tokens) * l.limit.interval())
+	return res
+}
+
+// prune drops the buckets that are full by now, as a new bucket is the
+// same. It must be called with mu held.
+func (l *memoryLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		b.refill(l.limit, now)
+		if b.tokens >= float64(l.limit.Requests) {
+			delete(l.buckets, key)
+		}
+	}
+	l.pruned = now
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type limiterSuite struct {
+	suite.Suite
+	now     time.Time
+	limiter Limiter
+}
+
+func (s *limiterSuite) SetupSubTest() {
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time {
+		return s.now
+	}
+	s.limiter = NewMemoryLimiter(Limit{Requests: 3, Per: 3 * time.Second})
+}
+
+func (s *limiterSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestLimiterSuite(t *testing.T) {
+	suite.Run(t, new(limiterSuite))
+}
+
+func (s *limiterSuite) TestAllow() {
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+	s.Run("burst then wait", func() {
+		s.Equal(Result{Allowed: true, Limit: limit, Remaining: 2, Reset: time.Second}, s.limiter.Allow("user:1"))
+		s.Equal(Result{Allowed: true, Limit: limit, Remaining: 1, Reset: 2 * time.Second}, s.limiter.Allow("user:1"))
+		s.Equal(Result{Allowed: true, Limit: limit, Remaining: 0, Reset: 3 * time.Second}, s.limiter.Allow("user:1"))
+		s.Equal(Result{Allowed: false, Limit: limit, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}, s.limiter.Allow("user:1"))
+
+		s.now = s.now.Add(500 * time.Millisecond)
+		s.Equal(Result{Allowed: false, Limit: limit, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}, s.limiter.Allow("user:1"))
+
+		s.now = s.now.Add(500 * time.Millisecond)
+		s.True(s.limiter.Allow("user:1").Allowed)
+		s.False(s.limiter.Allow("user:1").Allowed)
+	})
+	s.Run("keys have their own buckets", func() {
+		for range 3 {
+			s.limiter.Allow("user:1")
+		}
+
+		s.False(s.limiter.Allow("user:1").Allowed)
+		s.True(s.limiter.Allow("user:2").Allowed)
+	})
+	s.Run("refills up to the limit", func() {
+		s.limiter.Allow("user:1")
+		s.now = s.now.Add(time.Hour)
+
+		s.Equal(2, s.limiter.Allow("user:1").Remaining)
+	})
+	s.Run("zero limit allows everything", func() {
+		limiter := NewMemoryLimiter(Limit{})
+		for range 100 {
+			s.Equal(Result{Allowed: true}, limiter.Allow("user:1"))
+		}
+	})
+}
+
+func (s *limiterSuite) TestPrune() {
+	s.Run("full buckets are dropped", func() {
+		limiter := s.limiter.(*memoryLimiter)
+		limiter.Allow("user:1")
+		s.now = s.now.Add(time.Second)
+		for range 3 {
+			limiter.Allow("user:2")
+		}
+		s.Len(limiter.buckets, 2)
+
+		s.now = s.now.Add(2 * time.Second)
+		limiter.Allow("user:3")
+		s.Equal([]string{"user:2", "user:3"}, slices.Sorted(maps.Keys(limiter.buckets)))
+	})
+}
+
+func (s *limiterSuite) TestParseLimit() {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr string
+	}{
+		{in: "600/1m", want: Limit{Requests: 600, Per: time.Minute}},
+		{in: "10/1s", want: Limit{Requests: 10, Per: time.Second}},
+		{in: "off", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "600", wantErr: `rate limit "600" is not requests/period`},
+		{in: "-1/1m", wantErr: `rate limit "-1/1m" needs a positive number of requests`},
+		{in: "10/soon", wantErr: `rate limit "10/soon" needs a positive period`},
+		{in: "10/0s", wantErr: `rate limit "10/0s" needs a positive period`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.in, func() {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr != "" {
+				s.EqualError(err, tt.wantErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.want, got)
+		})
+	}
+}
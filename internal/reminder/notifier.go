@@ -0,0 +1,131 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/mail"
+)
+
+// Notifier delivers a reminder through one channel.
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+type NotifierFunc func(ctx context.Context, r Reminder) error
+
+func (f NotifierFunc) Notify(ctx context.Context, r Reminder) error {
+	return f(ctx, r)
+}
+
+// Multi delivers reminders through every notifier, reporting all failures.
+func Multi(notifiers ...Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, r Reminder) error {
+		var errs []error
+		for _, n := range notifiers {
+			errs = append(errs, n.Notify(ctx, r))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// subject is the one-line summary every channel uses. It names the due time
+// rather than the offset, which would be wrong for reminders fired late.
+func subject(r Reminder) string {
+	title := strings.Join(strings.Fields(r.Title), " ")
+	return fmt.Sprintf("%q is due at %s", title, r.Due.Format("2006-01-02 15:04 MST"))
+}
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(_ context.Context, r Reminder) error {
+	n.logger.Printf("reminder: workspace %d todo %d: %s", r.WorkspaceID, r.TodoID, subject(r))
+	return nil
+}
+
+// webhookPayload carries the workspace because todo ids are only unique
+// within one.
+type webhookPayload struct {
+	WorkspaceID int       `json:"workspaceId"`
+	TodoID      int       `json:"todoId"`
+	Title       string    `json:"title"`
+	Due         time.Time `json:"due"`
+	Offset      string    `json:"offset"`
+	FireAt      time.Time `json:"fireAt"`
+	Message     string    `json:"message"`
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier posts reminders as JSON to url. Any status other than
+// 2xx is a failed delivery. Reminders of every workspace go to the same url,
+// so it must belong to the operator rather than to any one tenant.
+func NewWebhookNotifier(url string, client *http.Client) Notifier {
+	return &webhookNotifier{url: url, client: client}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, r Reminder) error {
+	body, err := json.Marshal(webhookPayload{
+		WorkspaceID: r.WorkspaceID,
+		TodoID:      r.TodoID,
+		Title:       r.Title,
+		Due:         r.Due,
+		Offset:      r.Offset.String(),
+		FireAt:      r.FireAt,
+		Message:     subject(r),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+type smtpNotifier struct {
+	cfg mail.Config
+	to  []string
+}
+
+// NewSMTPNotifier mails reminders to every address in to. Like the webhook,
+// the addresses receive the reminders of every workspace.
+func NewSMTPNotifier(cfg mail.Config, to []string) Notifier {
+	return &smtpNotifier{cfg: cfg, to: to}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, r Reminder) error {
+	return mail.Send(ctx, n.cfg, mail.Message{
+		To:      n.to,
+		Subject: "Reminder: " + subject(r),
+		Body:    fmt.Sprintf("%s\r\n\r\nTodo %d of workspace %d is due at %s.\r\n", subject(r), r.TodoID, r.WorkspaceID, r.Due.Format(time.RFC1123Z)),
+		Date:    timeNow(),
+	})
+}
@@ -0,0 +1,148 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/mail"
+	"github.com/cloudingcity/todo/internal/mail/mailtest"
+	"github.com/stretchr/testify/suite"
+)
+
+type notifierSuite struct {
+	suite.Suite
+	reminder Reminder
+}
+
+func (s *notifierSuite) SetupSubTest() {
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0).UTC()
+	}
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	s.reminder = Reminder{WorkspaceID: 2, TodoID: 1, Title: "ship release", Due: due, Offset: time.Hour, FireAt: due.Add(-time.Hour)}
+}
+
+func TestNotifierSuite(t *testing.T) {
+	suite.Run(t, new(notifierSuite))
+}
+
+func (s *notifierSuite) TestLog() {
+	s.Run("success", func() {
+		var buf bytes.Buffer
+		err := NewLogNotifier(log.New(&buf, "", 0)).Notify(context.Background(), s.reminder)
+		s.Require().NoError(err)
+		s.Equal("reminder: workspace 2 todo 1: \"ship release\" is due at 2026-10-19 09:00 UTC\n", buf.String())
+	})
+}
+
+func (s *notifierSuite) TestWebhook() {
+	s.Run("success", func() {
+		var got map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.Equal(http.MethodPost, r.Method)
+			s.Equal("application/json", r.Header.Get("Content-Type"))
+			b, _ := io.ReadAll(r.Body)
+			s.Require().NoError(json.Unmarshal(b, &got))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+
+		err := NewWebhookNotifier(srv.URL, srv.Client()).Notify(context.Background(), s.reminder)
+		s.Require().NoError(err)
+		s.Equal(map[string]any{
+			"workspaceId": 2.0,
+			"todoId":      1.0,
+			"title":       "ship release",
+			"due":         "2026-10-19T09:00:00Z",
+			"offset":      "1h0m0s",
+			"fireAt":      "2026-10-19T08:00:00Z",
+			"message":     `"ship release" is due at 2026-10-19 09:00 UTC`,
+		}, got)
+	})
+	s.Run("error status", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		err := NewWebhookNotifier(srv.URL, srv.Client()).Notify(context.Background(), s.reminder)
+		s.EqualError(err, "webhook responded 502 Bad Gateway")
+	})
+	s.Run("canceled", func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewWebhookNotifier(srv.URL, srv.Client()).Notify(ctx, s.reminder)
+		s.ErrorIs(err, context.Canceled)
+	})
+}
+
+func (s *notifierSuite) TestSMTP() {
+	s.Run("success", func() {
+		srv := mailtest.NewServer(s.T())
+		notifier := NewSMTPNotifier(mail.Config{Addr: srv.Addr(), From: "todo@example.com"}, []string{"a@example.com", "b@example.com"})
+
+		s.Require().NoError(notifier.Notify(context.Background(), s.reminder))
+		s.Equal("<todo@example.com>", srv.From())
+		s.Equal([]string{"<a@example.com>", "<b@example.com>"}, srv.To())
+		s.Require().Len(srv.Messages(), 1)
+		msg := srv.Messages()[0]
+		s.Contains(msg, "From: todo@example.com\n")
+		s.Contains(msg, "To: a@example.com, b@example.com\n")
+		s.Contains(msg, "Subject: Reminder: \"ship release\" is due at 2026-10-19 09:00 UTC\n")
+		s.Contains(msg, "Date: Thu, 29 Nov 1973 21:33:09 +0000\n")
+		s.Contains(msg, "@example.com>\n")
+		s.Contains(msg, "\n\nTodo 1 of workspace 2 is due at Mon, 19 Oct 2026 09:00:00 +0000.\n")
+	})
+	s.Run("encodes subject", func() {
+		srv := mailtest.NewServer(s.T())
+		s.reminder.Title = "繳費\r\nBcc: x@example.com"
+		notifier := NewSMTPNotifier(mail.Config{Addr: srv.Addr(), From: "todo@example.com"}, []string{"a@example.com"})
+
+		s.Require().NoError(notifier.Notify(context.Background(), s.reminder))
+		s.Require().Len(srv.Messages(), 1)
+		s.Contains(srv.Messages()[0], "Subject: =?utf-8?q?")
+		s.NotContains(srv.Messages()[0], "\nBcc:")
+	})
+	s.Run("rejected recipient", func() {
+		srv := mailtest.NewServer(s.T())
+		srv.RejectRcpt("b@example.com")
+		notifier := NewSMTPNotifier(mail.Config{Addr: srv.Addr(), From: "todo@example.com"}, []string{"a@example.com", "b@example.com"})
+
+		err := notifier.Notify(context.Background(), s.reminder)
+		s.ErrorContains(err, "no such user")
+		s.Empty(srv.Messages())
+	})
+	s.Run("unreachable", func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		s.Require().NoError(err)
+		addr := ln.Addr().String()
+		ln.Close()
+
+		err = NewSMTPNotifier(mail.Config{Addr: addr, From: "todo@example.com"}, []string{"a@example.com"}).Notify(context.Background(), s.reminder)
+		s.Error(err)
+	})
+}
+
+func (s *notifierSuite) TestMulti() {
+	s.Run("reports every failure", func() {
+		first, second := &recorder{err: errors.New("first")}, &recorder{}
+		third := NotifierFunc(func(context.Context, Reminder) error { return errors.New("third") })
+
+		err := Multi(first, second, third).Notify(context.Background(), s.reminder)
+		s.EqualError(err, "first\nthird")
+		s.Equal([]int{1}, first.todoIDs())
+		s.Equal([]int{1}, second.todoIDs())
+	})
+}
@@ -0,0 +1,221 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Queue holds the pending reminders and remembers the ones that fired, so a
+// reminder is delivered at most once.
+type Queue interface {
+	// Sync makes reminders the pending set, leaving out the ones that fired
+	// already, and forgets fired reminders that were due before now and are
+	// not in reminders any more.
+	Sync(reminders []Reminder, now time.Time) error
+	// Next returns when the earliest pending reminder fires.
+	Next() (time.Time, bool)
+	// Pop removes the reminders that fire at or before now and records them
+	// as fired before returning them, so a crash while they are delivered
+	// cannot deliver them again.
+	Pop(now time.Time) ([]Reminder, error)
+}
+
+// syncFlushInterval is how long a file queue may hold changes made by Sync
+// before writing them. Sync only rebuilds what the todos say, so losing its
+// changes in a crash costs a rebuild at most. Pop, whose changes keep a
+// reminder from firing twice, writes at once.
+const syncFlushInterval = time.Minute
+
+type queueState struct {
+	Pending map[string]Reminder  `json:"pending"`
+	Fired   map[string]time.Time `json:"fired"`
+}
+
+type queue struct {
+	// path is the file the queue is kept in. Memory queues leave it empty.
+	path string
+
+	mu    sync.Mutex
+	state queueState
+	// dirty is set when Sync changed state without writing it.
+	dirty     bool
+	writtenAt time.Time
+}
+
+func NewMemoryQueue() Queue {
+	return &queue{state: newQueueState()}
+}
+
+// OpenFileQueue loads the queue kept in path, starting an empty one when the
+// file does not exist yet. Fired reminders are written back before Pop
+// returns them, while the changes of Sync are written at most once per
+// syncFlushInterval.
+func OpenFileQueue(path string) (Queue, error) {
+	q := &queue{path: path, state: newQueueState()}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &q.state); err != nil {
+		return nil, err
+	}
+	if q.state.Pending == nil {
+		q.state.Pending = make(map[string]Reminder)
+	}
+	if q.state.Fired == nil {
+		q.state.Fired = make(map[string]time.Time)
+	}
+	return q, nil
+}
+
+func newQueueState() queueState {
+	return queueState{
+		Pending: make(map[string]Reminder),
+		Fired:   make(map[string]time.Time),
+	}
+}
+
+func (q *queue) Sync(reminders []Reminder, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	state := queueState{
+		Pending: make(map[string]Reminder, len(reminders)),
+		Fired:   make(map[string]time.Time, len(q.state.Fired)),
+	}
+	wanted := make(map[string]bool, len(reminders))
+	for _, r := range reminders {
+		wanted[r.Key()] = true
+	}
+	for key, due := range q.state.Fired {
+		if wanted[key] || !due.Before(now) {
+			state.Fired[key] = due
+		}
+	}
+	for _, r := range reminders {
+		if _, ok := state.Fired[r.Key()]; !ok {
+			state.Pending[r.Key()] = r
+		}
+	}
+	if !q.dirty && maps.Equal(state.Pending, q.state.Pending) && maps.Equal(state.Fired, q.state.Fired) {
+		return nil
+	}
+	if q.path != "" && now.Sub(q.writtenAt) < syncFlushInterval {
+		q.state = state
+		q.dirty = true
+		return nil
+	}
+	return q.commit(state, now)
+}
+
+func (q *queue) Next() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var (
+		next time.Time
+		ok   bool
+	)
+	for _, r := range q.state.Pending {
+		if !ok || r.FireAt.Before(next) {
+			next, ok = r.FireAt, true
+		}
+	}
+	return next, ok
+}
+
+func (q *queue) Pop(now time.Time) ([]Reminder, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	state := queueState{
+		Pending: maps.Clone(q.state.Pending),
+		Fired:   maps.Clone(q.state.Fired),
+	}
+	var due []Reminder
+	for key, r := range q.state.Pending {
+		if r.FireAt.After(now) {
+			continue
+		}
+		delete(state.Pending, key)
+		state.Fired[key] = r.Due
+		due = append(due, r)
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	if err := q.commit(state, now); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(due, func(a, b Reminder) int {
+		return a.FireAt.Compare(b.FireAt)
+	})
+	return due, nil
+}
+
+// Check reports a file queue as unhealthy when a file cannot be created next
+// to it, which would fail the next write. Memory queues are always healthy.
+func (q *queue) Check(context.Context) error {
+	if q.path == "" {
+		return nil
+	}
+	f, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".check-*")
+	if err != nil {
+		return err
+	}
+	err = f.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// commit writes state to the queue file and then adopts it, so a failed
+// write leaves the queue as it was.
+func (q *queue) commit(state queueState, now time.Time) error {
+	if q.path != "" {
+		if err := writeFile(q.path, state); err != nil {
+			return err
+		}
+		q.dirty = false
+		q.writtenAt = now
+	}
+	q.state = state
+	return nil
+}
+
+// writeFile replaces path through a rename so readers never see a partly
+// written queue.
+func writeFile(path string, state queueState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,211 @@
+package reminder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type queueSuite struct {
+	suite.Suite
+	path string
+	now  time.Time
+}
+
+func (s *queueSuite) SetupSubTest() {
+	s.path = filepath.Join(s.T().TempDir(), "reminders.json")
+	s.now = time.Unix(123456789, 0).UTC()
+}
+
+func TestQueueSuite(t *testing.T) {
+	suite.Run(t, new(queueSuite))
+}
+
+func (s *queueSuite) reminder(todoID int, fireIn time.Duration) Reminder {
+	due := s.now.Add(fireIn + time.Hour)
+	return Reminder{TodoID: todoID, Title: "title", Due: due, Offset: time.Hour, FireAt: due.Add(-time.Hour)}
+}
+
+func (s *queueSuite) open() Queue {
+	q, err := OpenFileQueue(s.path)
+	s.Require().NoError(err)
+	return q
+}
+
+func (s *queueSuite) TestNext() {
+	s.Run("empty", func() {
+		_, ok := NewMemoryQueue().Next()
+		s.False(ok)
+	})
+	s.Run("earliest", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour), s.reminder(2, time.Minute)}, s.now))
+
+		next, ok := q.Next()
+		s.True(ok)
+		s.Equal(s.now.Add(time.Minute), next)
+	})
+}
+
+func (s *queueSuite) TestPop() {
+	s.Run("due reminders in fire order", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0), s.reminder(2, -time.Minute), s.reminder(3, time.Minute)}, s.now))
+
+		got, err := q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Equal([]Reminder{s.reminder(2, -time.Minute), s.reminder(1, 0)}, got)
+
+		got, err = q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+	s.Run("fired reminders are not scheduled again", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		_, _ = q.Pop(s.now)
+
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		got, err := q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+	s.Run("moved due date is reminded again", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		_, _ = q.Pop(s.now)
+
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, -time.Minute)}, s.now))
+		got, err := q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Len(got, 1)
+	})
+}
+
+func (s *queueSuite) TestSync() {
+	s.Run("drops reminders no longer wanted", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Minute), s.reminder(2, time.Minute)}, s.now))
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(2, time.Minute)}, s.now))
+
+		got, err := q.Pop(s.now.Add(time.Minute))
+		s.Require().NoError(err)
+		s.Equal([]Reminder{s.reminder(2, time.Minute)}, got)
+	})
+	s.Run("forgets fired reminders once due", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		_, _ = q.Pop(s.now)
+
+		// The todo is due an hour after the reminder fired.
+		s.Require().NoError(q.Sync(nil, s.now.Add(2*time.Hour)))
+		s.Empty(q.(*queue).state.Fired)
+	})
+	s.Run("remembers fired reminders still wanted", func() {
+		q := NewMemoryQueue()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		_, _ = q.Pop(s.now)
+
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now.Add(2*time.Hour)))
+		got, err := q.Pop(s.now.Add(2 * time.Hour))
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+}
+
+func (s *queueSuite) TestFileQueue() {
+	s.Run("missing file starts empty", func() {
+		_, ok := s.open().Next()
+		s.False(ok)
+	})
+	s.Run("pending reminders survive a restart", func() {
+		s.Require().NoError(s.open().Sync([]Reminder{s.reminder(1, time.Minute)}, s.now))
+
+		got, err := s.open().Pop(s.now.Add(time.Minute))
+		s.Require().NoError(err)
+		s.Len(got, 1)
+		s.Equal(s.reminder(1, time.Minute).Key(), got[0].Key())
+	})
+	s.Run("fired reminders do not fire after a restart", func() {
+		q := s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		got, err := q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Len(got, 1)
+
+		q = s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		got, err = q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+	s.Run("failed write keeps reminders pending", func() {
+		q := s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, 0)}, s.now))
+		q.(*queue).path = filepath.Join(s.path, "missing", "reminders.json")
+
+		_, err := q.Pop(s.now)
+		s.Error(err)
+		_, ok := q.Next()
+		s.True(ok)
+	})
+	s.Run("unchanged sync is not written", func() {
+		q := s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour)}, s.now))
+		s.Require().NoError(os.Remove(s.path))
+
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour)}, s.now.Add(time.Minute)))
+		s.NoFileExists(s.path)
+	})
+	s.Run("sync changes are written at most once per interval", func() {
+		q := s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour)}, s.now))
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour), s.reminder(2, time.Minute)}, s.now.Add(30*time.Second)))
+		next, _ := s.open().Next()
+		s.Equal(s.reminder(1, time.Hour).FireAt, next, "the second sync is held back")
+
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour), s.reminder(2, time.Minute)}, s.now.Add(time.Minute)))
+		next, _ = s.open().Next()
+		s.Equal(s.reminder(2, time.Minute).FireAt, next)
+	})
+	s.Run("pop writes held back sync changes", func() {
+		q := s.open()
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour)}, s.now))
+		s.Require().NoError(q.Sync([]Reminder{s.reminder(1, time.Hour), s.reminder(2, 0), s.reminder(3, time.Minute)}, s.now))
+		got, err := q.Pop(s.now)
+		s.Require().NoError(err)
+		s.Len(got, 1)
+
+		next, _ := s.open().Next()
+		s.Equal(s.reminder(3, time.Minute).FireAt, next)
+	})
+	s.Run("corrupt file", func() {
+		s.Require().NoError(os.WriteFile(s.path, []byte("{"), 0o600))
+
+		_, err := OpenFileQueue(s.path)
+		s.Error(err)
+	})
+}
+
+func (s *queueSuite) TestCheck() {
+	s.Run("memory queue", func() {
+		s.NoError(NewMemoryQueue().(*queue).Check(context.Background()))
+	})
+	s.Run("file queue", func() {
+		s.NoError(s.open().(*queue).Check(context.Background()))
+
+		entries, err := os.ReadDir(filepath.Dir(s.path))
+		s.Require().NoError(err)
+		s.Empty(entries, "the probe file is removed")
+	})
+	s.Run("directory gone", func() {
+		q := s.open()
+		s.Require().NoError(os.RemoveAll(filepath.Dir(s.path)))
+
+		s.Error(q.(*queue).Check(context.Background()))
+	})
+}
@@ -0,0 +1,242 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+)
+
+var timeNow = time.Now
+
+var (
+	// DefaultOffsets remind an hour before a todo is due and when it is due.
+	DefaultOffsets = []time.Duration{time.Hour, 0}
+	// DefaultSyncInterval is how often the scheduler picks up changed todos.
+	DefaultSyncInterval = 30 * time.Second
+)
+
+// Reminder is a notification that a todo is due, fired Offset before Due.
+type Reminder struct {
+	WorkspaceID int           `json:"workspaceId,omitempty"`
+	TodoID      int           `json:"todoId"`
+	Title       string        `json:"title"`
+	Due         time.Time     `json:"due"`
+	Offset      time.Duration `json:"offset"`
+	FireAt      time.Time     `json:"fireAt"`
+}
+
+// Key identifies the reminder. Moving the due date of a todo changes the key,
+// so the todo is reminded again for its new date. Todo IDs are only unique
+// within a workspace, so the workspace is part of the key.
+func (r Reminder) Key() string {
+	key := fmt.Sprintf("%d/%s/%s", r.TodoID, r.Offset, r.Due.UTC().Format(time.RFC3339))
+	if r.WorkspaceID != 0 {
+		key = fmt.Sprintf("w%d/%s", r.WorkspaceID, key)
+	}
+	return key
+}
+
+// TodoLister is the part of the todo service the scheduler reads.
+type TodoLister interface {
+	List(query entity.TodoQuery) ([]entity.Todo, error)
+}
+
+// TodoListerFunc adapts a function to TodoLister.
+type TodoListerFunc func(query entity.TodoQuery) ([]entity.Todo, error)
+
+func (f TodoListerFunc) List(query entity.TodoQuery) ([]entity.Todo, error) {
+	return f(query)
+}
+
+// AcrossWorkspaces lists the todos of every workspace workspaces returns,
+// with forWorkspace giving the todos of a single one.
+func AcrossWorkspaces(workspaces func() ([]int, error), forWorkspace func(id int) TodoLister) TodoLister {
+	return TodoListerFunc(func(query entity.TodoQuery) ([]entity.Todo, error) {
+		ids, err := workspaces()
+		if err != nil {
+			return nil, err
+		}
+		var todos []entity.Todo
+		for _, id := range ids {
+			got, err := forWorkspace(id).List(query)
+			if err != nil {
+				return nil, fmt.Errorf("workspace %d: %w", id, err)
+			}
+			todos = append(todos, got...)
+		}
+		return todos, nil
+	})
+}
+
+type Scheduler struct {
+	todos    TodoLister
+	queue    Queue
+	notifier Notifier
+	offsets  []time.Duration
+	interval time.Duration
+	logger   *log.Logger
+
+	// mu guards what Check reports: when the scheduler last ticked and
+	// whether that tick failed.
+	mu       sync.Mutex
+	lastTick time.Time
+	lastErr  error
+}
+
+type Option func(*Scheduler)
+
+// WithOffsets sets how long before the due time reminders fire.
+func WithOffsets(offsets ...time.Duration) Option {
+	return func(s *Scheduler) {
+		s.offsets = offsets
+	}
+}
+
+func WithSyncInterval(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = d
+	}
+}
+
+// WithLogger sets where failed syncs and deliveries are reported.
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+func NewScheduler(todos TodoLister, queue Queue, notifier Notifier, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		todos:    todos,
+		queue:    queue,
+		notifier: notifier,
+		offsets:  DefaultOffsets,
+		interval: DefaultSyncInterval,
+		logger:   log.Default(),
+		lastTick: timeNow(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run schedules and fires reminders until ctx is done. Todos are synced
+// every interval and whenever a reminder fires.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.Tick(ctx)
+
+		wait := s.interval
+		if next, ok := s.queue.Next(); ok {
+			wait = min(wait, max(next.Sub(timeNow()), 0))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Tick syncs the queue with the todos and delivers the reminders that are
+// due. A failed delivery is logged and not retried.
+func (s *Scheduler) Tick(ctx context.Context) {
+	now := timeNow()
+	syncErr := s.sync(now)
+	if syncErr != nil {
+		s.logger.Printf("reminder: sync: %v", syncErr)
+	}
+
+	reminders, err := s.queue.Pop(now)
+	if err != nil {
+		s.logger.Printf("reminder: pop: %v", err)
+		s.ticked(now, fmt.Errorf("pop: %w", err))
+		return
+	}
+	if syncErr != nil {
+		s.ticked(now, fmt.Errorf("sync: %w", syncErr))
+	} else {
+		s.ticked(now, nil)
+	}
+	for _, r := range reminders {
+		if err := s.notifier.Notify(ctx, r); err != nil {
+			s.logger.Printf("reminder: notify todo %d: %v", r.TodoID, err)
+		}
+	}
+}
+
+func (s *Scheduler) ticked(now time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastTick = now
+	s.lastErr = err
+}
+
+// Check reports the scheduler as unhealthy when its last tick failed, or
+// when it has not ticked for three sync intervals, which means it is stuck,
+// for example on a notifier that does not return.
+func (s *Scheduler) Check(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastErr != nil {
+		return s.lastErr
+	}
+	if since := timeNow().Sub(s.lastTick); since > 3*s.interval {
+		return fmt.Errorf("no tick for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *Scheduler) sync(now time.Time) error {
+	todos, err := s.todos.List(entity.TodoQuery{})
+	if err != nil {
+		return err
+	}
+	return s.queue.Sync(s.reminders(todos, now), now)
+}
+
+// reminders returns the reminders of the open todos that are not due yet,
+// or became due within the last sync interval so the reminder at due time
+// is still delivered. Reminders of a todo due soon fire right away when
+// their time has passed.
+func (s *Scheduler) reminders(todos []entity.Todo, now time.Time) []Reminder {
+	since := now.Add(-s.interval)
+	var reminders []Reminder
+	for _, todo := range todos {
+		if todo.IsCompleted || todo.Due == nil {
+			continue
+		}
+		due := dueTime(*todo.Due)
+		if !due.After(since) {
+			continue
+		}
+		for _, offset := range s.offsets {
+			reminders = append(reminders, Reminder{
+				WorkspaceID: todo.WorkspaceID,
+				TodoID:      todo.ID,
+				Title:       todo.Title,
+				Due:         due,
+				Offset:      offset,
+				FireAt:      due.Add(-offset),
+			})
+		}
+	}
+	return reminders
+}
+
+// dueTime is the instant a todo becomes overdue. All-day dates end at
+// midnight in their time zone, or UTC when they have none.
+func dueTime(due entity.DateTime) time.Time {
+	loc := time.UTC
+	if l, err := time.LoadLocation(due.TimeZone); due.TimeZone != "" && err == nil {
+		loc = l
+	}
+	return due.Deadline(loc)
+}
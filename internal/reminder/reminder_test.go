@@ -0,0 +1,265 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+// recorder is a notifier that remembers what it delivered.
+type recorder struct {
+	mu        sync.Mutex
+	reminders []Reminder
+	err       error
+}
+
+func (r *recorder) Notify(_ context.Context, reminder Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reminders = append(r.reminders, reminder)
+	return r.err
+}
+
+func (r *recorder) todoIDs() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return lo.Map(r.reminders, func(reminder Reminder, _ int) int { return reminder.TodoID })
+}
+
+type schedulerSuite struct {
+	suite.Suite
+	now      time.Time
+	todos    []entity.Todo
+	listErr  error
+	notifier *recorder
+	logs     *bytes.Buffer
+}
+
+func (s *schedulerSuite) SetupSubTest() {
+	s.now = time.Unix(123456789, 0).UTC()
+	timeNow = func() time.Time {
+		return s.now
+	}
+	s.todos = nil
+	s.listErr = nil
+	s.notifier = &recorder{}
+	s.logs = &bytes.Buffer{}
+}
+
+func TestSchedulerSuite(t *testing.T) {
+	suite.Run(t, new(schedulerSuite))
+}
+
+func (s *schedulerSuite) scheduler(queue Queue, opts ...Option) *Scheduler {
+	lister := TodoListerFunc(func(entity.TodoQuery) ([]entity.Todo, error) {
+		return s.todos, s.listErr
+	})
+	opts = append([]Option{WithLogger(log.New(s.logs, "", 0))}, opts...)
+	return NewScheduler(lister, queue, s.notifier, opts...)
+}
+
+func (s *schedulerSuite) dueIn(d time.Duration) *entity.DateTime {
+	return lo.ToPtr(entity.NewDateTime(s.now.Add(d), ""))
+}
+
+func (s *schedulerSuite) TestTick() {
+	s.Run("fires at each offset", func() {
+		s.todos = []entity.Todo{{ID: 1, Title: "title-1", Due: s.dueIn(2 * time.Hour)}}
+		queue := NewMemoryQueue()
+		sched := s.scheduler(queue)
+
+		sched.Tick(context.Background())
+		s.Empty(s.notifier.todoIDs())
+		next, _ := queue.Next()
+		s.Equal(s.now.Add(time.Hour), next)
+
+		s.now = s.now.Add(time.Hour)
+		sched.Tick(context.Background())
+		s.Equal([]int{1}, s.notifier.todoIDs())
+		s.Equal(time.Hour, s.notifier.reminders[0].Offset)
+
+		s.now = s.now.Add(time.Hour)
+		sched.Tick(context.Background())
+		s.Equal([]int{1, 1}, s.notifier.todoIDs())
+		s.Equal(time.Duration(0), s.notifier.reminders[1].Offset)
+
+		s.now = s.now.Add(time.Hour)
+		sched.Tick(context.Background())
+		s.Equal([]int{1, 1}, s.notifier.todoIDs(), "overdue todos are not reminded again")
+	})
+	s.Run("todo due soon fires right away", func() {
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(30 * time.Minute)}}
+		sched := s.scheduler(NewMemoryQueue())
+
+		sched.Tick(context.Background())
+		sched.Tick(context.Background())
+		s.Equal([]int{1}, s.notifier.todoIDs())
+	})
+	s.Run("skips todos without reminders", func() {
+		s.todos = []entity.Todo{
+			{ID: 1},
+			{ID: 2, IsCompleted: true, Due: s.dueIn(time.Minute)},
+			{ID: 3, Due: s.dueIn(-time.Minute)},
+			{ID: 4, Due: s.dueIn(time.Minute)},
+		}
+		sched := s.scheduler(NewMemoryQueue(), WithOffsets(time.Hour))
+
+		sched.Tick(context.Background())
+		s.Equal([]int{4}, s.notifier.todoIDs())
+	})
+	s.Run("completed todo is not reminded", func() {
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(2 * time.Hour)}}
+		sched := s.scheduler(NewMemoryQueue())
+		sched.Tick(context.Background())
+
+		s.todos[0].IsCompleted = true
+		s.now = s.now.Add(time.Hour)
+		sched.Tick(context.Background())
+		s.Empty(s.notifier.todoIDs())
+	})
+	s.Run("all-day todo is due at the end of its day", func() {
+		date := entity.NewDate(1973, 11, 30)
+		date.TimeZone = "Asia/Taipei"
+		s.todos = []entity.Todo{{ID: 1, Due: &date}}
+		queue := NewMemoryQueue()
+		sched := s.scheduler(queue, WithOffsets(0))
+
+		sched.Tick(context.Background())
+		next, ok := queue.Next()
+		s.True(ok)
+		s.Equal("1973-12-01T00:00:00+08:00", next.In(time.FixedZone("", 8*60*60)).Format(time.RFC3339))
+	})
+	s.Run("failed delivery is not retried", func() {
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(time.Minute)}}
+		s.notifier.err = errors.New("unreachable")
+		sched := s.scheduler(NewMemoryQueue())
+
+		sched.Tick(context.Background())
+		sched.Tick(context.Background())
+		s.Equal([]int{1}, s.notifier.todoIDs())
+		s.Contains(s.logs.String(), "reminder: notify todo 1: unreachable")
+	})
+	s.Run("failed sync still fires queued reminders", func() {
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(2 * time.Hour)}}
+		sched := s.scheduler(NewMemoryQueue())
+		sched.Tick(context.Background())
+
+		s.listErr = errors.New("unavailable")
+		s.now = s.now.Add(time.Hour)
+		sched.Tick(context.Background())
+		s.Equal([]int{1}, s.notifier.todoIDs())
+		s.Contains(s.logs.String(), "reminder: sync: unavailable")
+	})
+	s.Run("restart does not fire twice", func() {
+		path := filepath.Join(s.T().TempDir(), "reminders.json")
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(time.Minute)}}
+
+		for range 2 {
+			queue, err := OpenFileQueue(path)
+			s.Require().NoError(err)
+			s.scheduler(queue).Tick(context.Background())
+		}
+		s.Equal([]int{1}, s.notifier.todoIDs())
+	})
+}
+
+func (s *schedulerSuite) TestRun() {
+	s.Run("fires until stopped", func() {
+		s.todos = []entity.Todo{{ID: 1, Due: s.dueIn(time.Minute)}}
+		sched := s.scheduler(NewMemoryQueue(), WithSyncInterval(time.Millisecond))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			sched.Run(ctx)
+			close(done)
+		}()
+
+		s.Eventually(func() bool {
+			return len(s.notifier.todoIDs()) == 1
+		}, time.Second, time.Millisecond)
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			s.Fail("scheduler did not stop")
+		}
+		s.Equal([]int{1}, s.notifier.todoIDs())
+	})
+}
+
+func (s *schedulerSuite) TestCheck() {
+	s.Run("healthy after a tick", func() {
+		sched := s.scheduler(NewMemoryQueue())
+		sched.Tick(context.Background())
+
+		s.NoError(sched.Check(context.Background()))
+	})
+	s.Run("failed sync", func() {
+		s.listErr = errors.New("boom")
+		sched := s.scheduler(NewMemoryQueue())
+		sched.Tick(context.Background())
+
+		s.ErrorContains(sched.Check(context.Background()), "sync: boom")
+	})
+	s.Run("recovers on the next tick", func() {
+		s.listErr = errors.New("boom")
+		sched := s.scheduler(NewMemoryQueue())
+		sched.Tick(context.Background())
+		s.listErr = nil
+		sched.Tick(context.Background())
+
+		s.NoError(sched.Check(context.Background()))
+	})
+	s.Run("stuck", func() {
+		sched := s.scheduler(NewMemoryQueue(), WithSyncInterval(time.Minute))
+		sched.Tick(context.Background())
+		s.now = s.now.Add(4 * time.Minute)
+
+		s.ErrorContains(sched.Check(context.Background()), "no tick for 4m0s")
+	})
+}
+
+func (s *schedulerSuite) TestAcrossWorkspaces() {
+	s.Run("same todo id in two workspaces", func() {
+		due := s.dueIn(-time.Minute + time.Hour)
+		lister := AcrossWorkspaces(func() ([]int, error) {
+			return []int{1, 2}, nil
+		}, func(id int) TodoLister {
+			return TodoListerFunc(func(entity.TodoQuery) ([]entity.Todo, error) {
+				return []entity.Todo{{ID: 1, WorkspaceID: id, Due: due}}, nil
+			})
+		})
+		sched := NewScheduler(lister, NewMemoryQueue(), s.notifier, WithOffsets(time.Hour))
+
+		sched.Tick(context.Background())
+		s.Equal([]int{1, 1}, s.notifier.todoIDs())
+		s.ElementsMatch([]int{1, 2}, lo.Map(s.notifier.reminders, func(r Reminder, _ int) int { return r.WorkspaceID }))
+	})
+	s.Run("workspace list failed", func() {
+		lister := AcrossWorkspaces(func() ([]int, error) {
+			return nil, errors.New("something wrong")
+		}, nil)
+
+		_, err := lister.List(entity.TodoQuery{})
+		s.Error(err)
+	})
+}
+
+func (s *schedulerSuite) TestKey() {
+	s.Run("workspace is part of the key", func() {
+		r := Reminder{TodoID: 1, Due: s.now, Offset: time.Hour}
+		s.Equal("1/1h0m0s/1973-11-29T21:33:09Z", r.Key())
+		r.WorkspaceID = 2
+		s.Equal("w2/1/1h0m0s/1973-11-29T21:33:09Z", r.Key())
+	})
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+type apiKeyRepo struct {
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.APIKey
+}
+
+func NewAPIKeyRepo() repo.APIKey {
+	return &apiKeyRepo{
+		idCounter: 1,
+	}
+}
+
+func (r *apiKeyRepo) Create(input entity.CreateAPIKeyInput) (*entity.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOfPrefix(input.Prefix) != -1 {
+		return nil, entity.ErrAPIKeyPrefixTaken
+	}
+
+	key := entity.APIKey{
+		ID:        r.idCounter,
+		UserID:    input.UserID,
+		Name:      input.Name,
+		Prefix:    input.Prefix,
+		Hash:      input.Hash,
+		Scopes:    slices.Clone(input.Scopes),
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: timeNow(),
+	}
+	r.store = append(r.store, key)
+	r.idCounter++
+	return &key, nil
+}
+
+func (r *apiKeyRepo) Get(id int) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	key := r.store[idx]
+	return &key, nil
+}
+
+func (r *apiKeyRepo) GetByPrefix(prefix string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOfPrefix(prefix)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	key := r.store[idx]
+	return &key, nil
+}
+
+func (r *apiKeyRepo) ListByUser(userID int) ([]entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []entity.APIKey{}
+	for _, key := range r.store {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepo) Revoke(id int, at time.Time) (*entity.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	key := &r.store[idx]
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	revoked := *key
+	return &revoked, nil
+}
+
+func (r *apiKeyRepo) Touch(id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	r.store[idx].LastUsedAt = &at
+	return nil
+}
+
+func (r *apiKeyRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(key entity.APIKey) bool {
+		return key.ID == id
+	})
+}
+
+func (r *apiKeyRepo) indexOfPrefix(prefix string) int {
+	return slices.IndexFunc(r.store, func(key entity.APIKey) bool {
+		return key.Prefix == prefix
+	})
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/stretchr/testify/suite"
+)
+
+type apiKeySuite struct {
+	suite.Suite
+	repo repo.APIKey
+}
+
+func (s *apiKeySuite) SetupSubTest() {
+	s.repo = NewAPIKeyRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *apiKeySuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestAPIKeySuite(t *testing.T) {
+	suite.Run(t, new(apiKeySuite))
+}
+
+func (s *apiKeySuite) TestCreate() {
+	s.Run("success", func() {
+		scopes := []entity.Scope{entity.ScopeTodosRead}
+		got, err := s.repo.Create(entity.CreateAPIKeyInput{UserID: 1, Name: "ci", Prefix: "tdk_a", Hash: "hash", Scopes: scopes})
+		s.Require().NoError(err)
+		s.Equal(&entity.APIKey{
+			ID:        1,
+			UserID:    1,
+			Name:      "ci",
+			Prefix:    "tdk_a",
+			Hash:      "hash",
+			Scopes:    []entity.Scope{entity.ScopeTodosRead},
+			CreatedAt: time.Unix(123456789, 0),
+		}, got)
+
+		scopes[0] = entity.ScopeTodosWrite
+		stored, _ := s.repo.Get(1)
+		s.Equal([]entity.Scope{entity.ScopeTodosRead}, stored.Scopes)
+	})
+	s.Run("prefix taken", func() {
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{Prefix: "tdk_a"})
+
+		_, err := s.repo.Create(entity.CreateAPIKeyInput{Prefix: "tdk_a"})
+		s.ErrorIs(err, entity.ErrAPIKeyPrefixTaken)
+	})
+}
+
+func (s *apiKeySuite) TestGet() {
+	s.Run("success", func() {
+		created, _ := s.repo.Create(entity.CreateAPIKeyInput{Prefix: "tdk_a"})
+
+		got, err := s.repo.Get(created.ID)
+		s.Require().NoError(err)
+		s.Equal(created, got)
+
+		got, err = s.repo.GetByPrefix("tdk_a")
+		s.Require().NoError(err)
+		s.Equal(created, got)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+
+		_, err = s.repo.GetByPrefix("tdk_a")
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *apiKeySuite) TestListByUser() {
+	s.Run("success", func() {
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{UserID: 1, Prefix: "tdk_a"})
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{UserID: 2, Prefix: "tdk_b"})
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{UserID: 1, Prefix: "tdk_c"})
+
+		got, err := s.repo.ListByUser(1)
+		s.Require().NoError(err)
+		s.Len(got, 2)
+		s.Equal("tdk_a", got[0].Prefix)
+		s.Equal("tdk_c", got[1].Prefix)
+	})
+	s.Run("empty", func() {
+		got, err := s.repo.ListByUser(1)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+}
+
+func (s *apiKeySuite) TestRevoke() {
+	s.Run("keeps the first revocation time", func() {
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{Prefix: "tdk_a"})
+		first := time.Unix(100, 0)
+
+		_, err := s.repo.Revoke(1, first)
+		s.Require().NoError(err)
+		got, err := s.repo.Revoke(1, time.Unix(200, 0))
+		s.Require().NoError(err)
+		s.Equal(first, *got.RevokedAt)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Revoke(1, time.Unix(100, 0))
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *apiKeySuite) TestTouch() {
+	s.Run("success", func() {
+		_, _ = s.repo.Create(entity.CreateAPIKeyInput{Prefix: "tdk_a"})
+
+		s.Require().NoError(s.repo.Touch(1, time.Unix(100, 0)))
+		got, _ := s.repo.Get(1)
+		s.Equal(time.Unix(100, 0), *got.LastUsedAt)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.Touch(1, time.Unix(100, 0)), repo.ErrNotFound)
+	})
+}
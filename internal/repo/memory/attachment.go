@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// attachmentRepo holds the attachments of one workspace.
+type attachmentRepo struct {
+	workspaceID int
+	workspaces  *partitions[*attachmentRepo]
+
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.Attachment
+}
+
+// NewAttachmentRepo returns the repo of workspace zero. ForWorkspace reaches
+// the other workspaces.
+func NewAttachmentRepo() repo.Attachment {
+	var workspaces *partitions[*attachmentRepo]
+	workspaces = newPartitions(func(workspaceID int) *attachmentRepo {
+		return &attachmentRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			idCounter:   1,
+		}
+	})
+	return workspaces.get(0)
+}
+
+func (r *attachmentRepo) ForWorkspace(workspaceID int) repo.Attachment {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *attachmentRepo) Create(input entity.CreateAttachmentInput) (*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachment := entity.Attachment{
+		ID:          r.idCounter,
+		WorkspaceID: r.workspaceID,
+		TodoID:      input.TodoID,
+		UploaderID:  input.UploaderID,
+		Name:        input.Name,
+		ContentType: input.ContentType,
+		Size:        input.Size,
+		BlobKey:     input.BlobKey,
+		CreatedAt:   timeNow(),
+	}
+	r.store = append(r.store, attachment)
+	r.idCounter++
+	return &attachment, nil
+}
+
+func (r *attachmentRepo) Get(id int) (*entity.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	attachment := r.store[idx]
+	return &attachment, nil
+}
+
+func (r *attachmentRepo) List(todoID int) ([]entity.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attachments []entity.Attachment
+	for _, attachment := range r.store {
+		if attachment.TodoID == todoID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepo) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+	r.store = slices.Delete(r.store, idx, idx+1)
+	return nil
+}
+
+func (r *attachmentRepo) All() ([]entity.Attachment, error) {
+	var attachments []entity.Attachment
+	for _, ws := range r.workspaces.all() {
+		ws.mu.RLock()
+		attachments = append(attachments, ws.store...)
+		ws.mu.RUnlock()
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(attachment entity.Attachment) bool {
+		return attachment.ID == id
+	})
+}
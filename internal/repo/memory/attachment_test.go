@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type attachmentSuite struct {
+	suite.Suite
+	repo repo.Attachment
+}
+
+func (s *attachmentSuite) SetupSubTest() {
+	s.repo = NewAttachmentRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *attachmentSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestAttachmentSuite(t *testing.T) {
+	suite.Run(t, new(attachmentSuite))
+}
+
+func (s *attachmentSuite) create(workspaceID, todoID int) *entity.Attachment {
+	attachment, err := s.repo.ForWorkspace(workspaceID).Create(entity.CreateAttachmentInput{
+		TodoID:      todoID,
+		UploaderID:  3,
+		Name:        "a.png",
+		ContentType: "image/png",
+		Size:        5,
+		BlobKey:     "abc",
+	})
+	s.Require().NoError(err)
+	return attachment
+}
+
+func (s *attachmentSuite) TestCreate() {
+	s.Run("success", func() {
+		s.Equal(&entity.Attachment{
+			ID:          1,
+			WorkspaceID: 2,
+			TodoID:      1,
+			UploaderID:  3,
+			Name:        "a.png",
+			ContentType: "image/png",
+			Size:        5,
+			BlobKey:     "abc",
+			CreatedAt:   time.Unix(123456789, 0),
+		}, s.create(2, 1))
+
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound, "workspaces have their own attachments")
+		s.Equal(1, s.create(3, 1).ID, "workspaces have their own IDs")
+	})
+}
+
+func (s *attachmentSuite) TestList() {
+	s.Run("attachments of the todo", func() {
+		s.create(0, 1)
+		s.create(0, 2)
+		s.create(0, 1)
+
+		got, err := s.repo.List(1)
+		s.Require().NoError(err)
+		s.Equal([]int{1, 3}, lo.Map(got, func(a entity.Attachment, _ int) int { return a.ID }))
+	})
+}
+
+func (s *attachmentSuite) TestDelete() {
+	s.Run("success", func() {
+		s.create(0, 1)
+
+		s.NoError(s.repo.Delete(1))
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.Delete(1), repo.ErrNotFound)
+	})
+}
+
+func (s *attachmentSuite) TestAll() {
+	s.Run("every workspace", func() {
+		s.create(2, 1)
+		s.create(1, 1)
+		s.create(1, 2)
+
+		got, err := s.repo.ForWorkspace(3).All()
+		s.Require().NoError(err)
+		s.Equal([]int{1, 1, 2}, lo.Map(got, func(a entity.Attachment, _ int) int { return a.WorkspaceID }), "ordered by workspace")
+	})
+}
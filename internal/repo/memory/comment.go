@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// commentRepo holds the comments of one workspace.
+type commentRepo struct {
+	workspaceID int
+	workspaces  *partitions[*commentRepo]
+
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.Comment
+}
+
+// NewCommentRepo returns the repo of workspace zero. ForWorkspace reaches
+// the other workspaces.
+func NewCommentRepo() repo.Comment {
+	var workspaces *partitions[*commentRepo]
+	workspaces = newPartitions(func(workspaceID int) *commentRepo {
+		return &commentRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			idCounter:   1,
+		}
+	})
+	return workspaces.get(0)
+}
+
+func (r *commentRepo) ForWorkspace(workspaceID int) repo.Comment {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *commentRepo) Create(input entity.CreateCommentInput) (*entity.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timeNow()
+	comment := entity.Comment{
+		ID:          r.idCounter,
+		WorkspaceID: r.workspaceID,
+		TodoID:      input.TodoID,
+		AuthorID:    input.AuthorID,
+		Body:        input.Body,
+		Mentions:    slices.Clone(input.Mentions),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.store = append(r.store, comment)
+	r.idCounter++
+	return cloneComment(comment), nil
+}
+
+func (r *commentRepo) Get(id int) (*entity.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	return cloneComment(r.store[idx]), nil
+}
+
+func (r *commentRepo) List(todoID int) ([]entity.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var comments []entity.Comment
+	for _, comment := range r.store {
+		if comment.TodoID == todoID {
+			comments = append(comments, *cloneComment(comment))
+		}
+	}
+	return comments, nil
+}
+
+func (r *commentRepo) Update(id int, input entity.UpdateCommentInput) (*entity.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	comment := &r.store[idx]
+	now := timeNow()
+	comment.Edits = append(comment.Edits, entity.CommentEdit{Body: comment.Body, EditedAt: now})
+	comment.Body = input.Body
+	comment.Mentions = slices.Clone(input.Mentions)
+	comment.UpdatedAt = now
+	return cloneComment(*comment), nil
+}
+
+func (r *commentRepo) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+	r.store = slices.Delete(r.store, idx, idx+1)
+	return nil
+}
+
+func (r *commentRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(comment entity.Comment) bool {
+		return comment.ID == id
+	})
+}
+
+// cloneComment copies the slices of a comment too, so callers cannot change
+// the stored one.
+func cloneComment(comment entity.Comment) *entity.Comment {
+	comment.Mentions = slices.Clone(comment.Mentions)
+	comment.Edits = slices.Clone(comment.Edits)
+	return &comment
+}
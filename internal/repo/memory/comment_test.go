@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/stretchr/testify/suite"
+)
+
+type commentSuite struct {
+	suite.Suite
+	repo repo.Comment
+	now  time.Time
+}
+
+func (s *commentSuite) SetupSubTest() {
+	s.repo = NewCommentRepo()
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time {
+		return s.now
+	}
+}
+
+func (s *commentSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestCommentSuite(t *testing.T) {
+	suite.Run(t, new(commentSuite))
+}
+
+func (s *commentSuite) TestCreate() {
+	s.Run("success", func() {
+		got, err := s.repo.ForWorkspace(2).Create(entity.CreateCommentInput{TodoID: 1, AuthorID: 3, Body: "hi @a@example.com", Mentions: []int{4}})
+		s.Require().NoError(err)
+		s.Equal(&entity.Comment{
+			ID:          1,
+			WorkspaceID: 2,
+			TodoID:      1,
+			AuthorID:    3,
+			Body:        "hi @a@example.com",
+			Mentions:    []int{4},
+			CreatedAt:   time.Unix(123456789, 0),
+			UpdatedAt:   time.Unix(123456789, 0),
+		}, got)
+
+		_, err = s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound, "workspaces have their own comments")
+	})
+}
+
+func (s *commentSuite) TestList() {
+	s.Run("comments of the todo, oldest first", func() {
+		_, _ = s.repo.Create(entity.CreateCommentInput{TodoID: 1, Body: "first"})
+		_, _ = s.repo.Create(entity.CreateCommentInput{TodoID: 2, Body: "elsewhere"})
+		_, _ = s.repo.Create(entity.CreateCommentInput{TodoID: 1, Body: "second"})
+
+		got, err := s.repo.List(1)
+		s.Require().NoError(err)
+		s.Len(got, 2)
+		s.Equal([]int{1, 3}, []int{got[0].ID, got[1].ID})
+	})
+}
+
+func (s *commentSuite) TestUpdate() {
+	s.Run("keeps the edit history", func() {
+		_, _ = s.repo.Create(entity.CreateCommentInput{TodoID: 1, Body: "v1", Mentions: []int{4}})
+		s.now = s.now.Add(time.Minute)
+		_, err := s.repo.Update(1, entity.UpdateCommentInput{Body: "v2"})
+		s.Require().NoError(err)
+		s.now = s.now.Add(time.Minute)
+
+		got, err := s.repo.Update(1, entity.UpdateCommentInput{Body: "v3", Mentions: []int{5}})
+		s.Require().NoError(err)
+		s.Equal("v3", got.Body)
+		s.Equal([]int{5}, got.Mentions)
+		s.Equal([]entity.CommentEdit{
+			{Body: "v1", EditedAt: time.Unix(123456789+60, 0)},
+			{Body: "v2", EditedAt: time.Unix(123456789+120, 0)},
+		}, got.Edits)
+		s.Equal(time.Unix(123456789, 0), got.CreatedAt)
+		s.Equal(time.Unix(123456789+120, 0), got.UpdatedAt)
+
+		got.Edits[0].Body = "changed"
+		stored, _ := s.repo.Get(1)
+		s.Equal("v1", stored.Edits[0].Body, "callers get a copy")
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Update(1, entity.UpdateCommentInput{Body: "v2"})
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *commentSuite) TestDelete() {
+	s.Run("success", func() {
+		_, _ = s.repo.Create(entity.CreateCommentInput{TodoID: 1, Body: "v1"})
+
+		s.Require().NoError(s.repo.Delete(1))
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+		s.ErrorIs(s.repo.Delete(1), repo.ErrNotFound)
+	})
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"slices"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+func (r *todoRepo) AddDependency(todoID, blockerID int) (*entity.Dependency, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(todoID) == -1 {
+		return nil, repo.ErrNotFound
+	}
+	if r.indexOf(blockerID) == -1 {
+		return nil, entity.ErrBlockerNotFound
+	}
+	if todoID == blockerID {
+		return nil, entity.ErrDependencySelf
+	}
+	if r.dependencyIndex(todoID, blockerID) != -1 {
+		return nil, entity.ErrDependencyExists
+	}
+	if r.blockedBy(blockerID, todoID) {
+		return nil, entity.ErrDependencyCycle
+	}
+
+	dep := entity.Dependency{TodoID: todoID, BlockerID: blockerID, CreatedAt: timeNow()}
+	r.deps = append(r.deps, dep)
+	r.refreshBlocked(r.store)
+	return &dep, nil
+}
+
+func (r *todoRepo) RemoveDependency(todoID, blockerID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.dependencyIndex(todoID, blockerID)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	r.deps = slices.Delete(r.deps, idx, idx+1)
+	r.refreshBlocked(r.store)
+	return nil
+}
+
+func (r *todoRepo) ListDependencies() ([]entity.Dependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.deps), nil
+}
+
+func (r *todoRepo) dependencyIndex(todoID, blockerID int) int {
+	return slices.IndexFunc(r.deps, func(dep entity.Dependency) bool {
+		return dep.TodoID == todoID && dep.BlockerID == blockerID
+	})
+}
+
+// blockedBy reports whether the todo with id waits on blockerID, directly or
+// through other todos.
+func (r *todoRepo) blockedBy(id, blockerID int) bool {
+	seen := map[int]bool{id: true}
+	queue := []int{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range r.deps {
+			if dep.TodoID != current || seen[dep.BlockerID] {
+				continue
+			}
+			if dep.BlockerID == blockerID {
+				return true
+			}
+			seen[dep.BlockerID] = true
+			queue = append(queue, dep.BlockerID)
+		}
+	}
+	return false
+}
+
+// refreshBlocked recomputes the Blocked flag of every todo in store. It runs
+// after anything that changes completion or dependencies.
+func (r *todoRepo) refreshBlocked(store []entity.Todo) {
+	open := make(map[int]bool, len(store))
+	for _, todo := range store {
+		open[todo.ID] = !todo.IsCompleted
+	}
+	blocked := make(map[int]bool)
+	for _, dep := range r.deps {
+		if open[dep.BlockerID] {
+			blocked[dep.TodoID] = true
+		}
+	}
+	for i := range store {
+		store[i].Blocked = blocked[store[i].ID]
+	}
+}
+
+// checkBlocked refuses to complete a blocked todo. The update overrides the
+// check by clearing Blocked on the new version.
+func checkBlocked(prev, todo entity.Todo) error {
+	if todo.IsCompleted && !prev.IsCompleted && todo.Blocked {
+		return entity.ErrBlocked
+	}
+	return nil
+}
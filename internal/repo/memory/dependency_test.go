@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+)
+
+func (s *todoSuite) blocked() map[int]bool {
+	todos, _ := s.repo.List()
+	return lo.SliceToMap(todos, func(todo entity.Todo) (int, bool) { return todo.ID, todo.Blocked })
+}
+
+func (s *todoSuite) TestAddDependency() {
+	tests := []struct {
+		desc      string
+		todoID    int
+		blockerID int
+		wantErr   error
+	}{
+		{desc: "success", todoID: 3, blockerID: 1},
+		{desc: "exists", todoID: 2, blockerID: 1, wantErr: entity.ErrDependencyExists},
+		{desc: "self", todoID: 2, blockerID: 2, wantErr: entity.ErrDependencySelf},
+		{desc: "direct cycle", todoID: 1, blockerID: 2, wantErr: entity.ErrDependencyCycle},
+		{desc: "indirect cycle", todoID: 1, blockerID: 3, wantErr: entity.ErrDependencyCycle},
+		{desc: "blocker not found", todoID: 2, blockerID: 9, wantErr: entity.ErrBlockerNotFound},
+		{desc: "not found", todoID: 9, blockerID: 1, wantErr: repo.ErrNotFound},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			timeNow = func() time.Time {
+				return time.Unix(123456789, 0)
+			}
+			s.createTree(entity.RollupNone, 0, 0, 0)
+			_, _ = s.repo.AddDependency(2, 1)
+			_, _ = s.repo.AddDependency(3, 2)
+
+			got, err := s.repo.AddDependency(tt.todoID, tt.blockerID)
+			s.ErrorIs(err, tt.wantErr)
+			if tt.wantErr != nil {
+				s.Nil(got)
+				return
+			}
+			s.Equal(&entity.Dependency{TodoID: tt.todoID, BlockerID: tt.blockerID, CreatedAt: time.Unix(123456789, 0)}, got)
+			deps, _ := s.repo.ListDependencies()
+			s.Len(deps, 3)
+		})
+	}
+}
+
+func (s *todoSuite) TestRemoveDependency() {
+	s.Run("success", func() {
+		s.createTree(entity.RollupNone, 0, 0)
+		_, _ = s.repo.AddDependency(2, 1)
+
+		s.Require().NoError(s.repo.RemoveDependency(2, 1))
+		deps, _ := s.repo.ListDependencies()
+		s.Empty(deps)
+		s.Equal(map[int]bool{1: false, 2: false}, s.blocked())
+	})
+	s.Run("not found", func() {
+		s.createTree(entity.RollupNone, 0, 0)
+
+		s.ErrorIs(s.repo.RemoveDependency(2, 1), repo.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestBlocked() {
+	s.Run("follows the blocker", func() {
+		s.createTree(entity.RollupNone, 0, 0, 0)
+		_, _ = s.repo.AddDependency(3, 1)
+		_, _ = s.repo.AddDependency(3, 2)
+		s.Equal(map[int]bool{1: false, 2: false, 3: true}, s.blocked())
+
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: false, 2: false, 3: true}, s.blocked())
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: false, 2: false, 3: false}, s.blocked())
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(false)}))
+		s.Equal(map[int]bool{1: false, 2: false, 3: true}, s.blocked())
+	})
+	s.Run("completed blocker", func() {
+		s.createTree(entity.RollupNone, 0, 0)
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+
+		_, _ = s.repo.AddDependency(2, 1)
+		s.Equal(map[int]bool{1: false, 2: false}, s.blocked())
+	})
+	s.Run("deleting the blocker drops the dependency", func() {
+		s.createTree(entity.RollupNone, 0, 0)
+		_, _ = s.repo.AddDependency(2, 1)
+
+		s.Require().NoError(s.repo.Delete(1, entity.SubtaskDeleteCascade))
+		s.Equal(map[int]bool{2: false}, s.blocked())
+		deps, _ := s.repo.ListDependencies()
+		s.Empty(deps)
+	})
+	s.Run("rollup after a delete unblocks", func() {
+		s.createTree(entity.RollupAuto, 0, 1, 1, 0)
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		_, _ = s.repo.AddDependency(4, 1)
+
+		s.Require().NoError(s.repo.Delete(3, entity.SubtaskDeleteCascade))
+		s.Equal(map[int]bool{1: false, 2: false, 4: false}, s.blocked())
+	})
+	s.Run("batch delete drops the dependency", func() {
+		s.createTree(entity.RollupNone, 0, 0)
+		_, _ = s.repo.AddDependency(2, 1)
+
+		s.Require().NoError(s.repo.BatchDelete([]int{1}))
+		deps, _ := s.repo.ListDependencies()
+		s.Empty(deps)
+	})
+}
+
+func (s *todoSuite) TestCompleteBlocked() {
+	setup := func() {
+		s.createTree(entity.RollupNone, 0, 0)
+		_, _ = s.repo.AddDependency(2, 1)
+	}
+
+	s.Run("update", func() {
+		setup()
+
+		err := s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)})
+		s.ErrorIs(err, entity.ErrBlocked)
+		s.Equal(map[int]bool{1: false, 2: false}, s.completed())
+	})
+	s.Run("update with force", func() {
+		setup()
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true), Force: true}))
+		s.Equal(map[int]bool{1: false, 2: true}, s.completed())
+		s.Equal(map[int]bool{1: false, 2: true}, s.blocked())
+	})
+	s.Run("other changes are allowed", func() {
+		setup()
+
+		s.NoError(s.repo.Update(2, entity.UpdateTodoInput{Title: lo.ToPtr("title")}))
+	})
+	s.Run("update func", func() {
+		setup()
+
+		err := s.repo.UpdateFunc(2, func(todo *entity.Todo) error {
+			todo.IsCompleted = true
+			return nil
+		})
+		s.ErrorIs(err, entity.ErrBlocked)
+	})
+	s.Run("update func clearing blocked", func() {
+		setup()
+
+		err := s.repo.UpdateFunc(2, func(todo *entity.Todo) error {
+			todo.Blocked = false
+			todo.IsCompleted = true
+			return nil
+		})
+		s.Require().NoError(err)
+		s.Equal(map[int]bool{1: false, 2: true}, s.completed())
+	})
+	s.Run("replace", func() {
+		setup()
+
+		_, err := s.repo.Replace(2, entity.ReplaceTodoInput{Title: "title", IsCompleted: true})
+		s.ErrorIs(err, entity.ErrBlocked)
+
+		_, err = s.repo.Replace(2, entity.ReplaceTodoInput{Title: "title", IsCompleted: true, Force: true})
+		s.NoError(err)
+	})
+	s.Run("batch update", func() {
+		setup()
+
+		err := s.repo.BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 1, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title")}},
+			{ID: 2, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+		})
+		s.Equal(&repo.BatchError{Index: 1, Err: entity.ErrBlocked}, err)
+		s.Equal(map[int]bool{1: false, 2: false}, s.completed())
+	})
+	s.Run("batch update completing the blocker first", func() {
+		setup()
+
+		err := s.repo.BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 1, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+			{ID: 2, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+		})
+		s.Require().NoError(err)
+		s.Equal(map[int]bool{1: true, 2: true}, s.completed())
+	})
+}
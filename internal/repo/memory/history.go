@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// historyRepo holds the changes to the todos of one workspace.
+type historyRepo struct {
+	workspaceID int
+	workspaces  *partitions[*historyRepo]
+
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.TodoChange
+}
+
+// NewHistoryRepo returns the repo of workspace zero. ForWorkspace reaches
+// the other workspaces.
+func NewHistoryRepo() repo.History {
+	var workspaces *partitions[*historyRepo]
+	workspaces = newPartitions(func(workspaceID int) *historyRepo {
+		return &historyRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			idCounter:   1,
+		}
+	})
+	return workspaces.get(0)
+}
+
+func (r *historyRepo) ForWorkspace(workspaceID int) repo.History {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *historyRepo) Record(change entity.TodoChange) (*entity.TodoChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change.ID = r.idCounter
+	change.WorkspaceID = r.workspaceID
+	change.Fields = slices.Clone(change.Fields)
+	change.At = timeNow()
+	r.store = append(r.store, change)
+	r.idCounter++
+	return &change, nil
+}
+
+func (r *historyRepo) List(todoID int) ([]entity.TodoChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var changes []entity.TodoChange
+	for _, change := range r.store {
+		if change.TodoID == todoID {
+			change.Fields = slices.Clone(change.Fields)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
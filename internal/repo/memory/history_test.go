@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/stretchr/testify/suite"
+)
+
+type historySuite struct {
+	suite.Suite
+	repo repo.History
+}
+
+func (s *historySuite) SetupSubTest() {
+	s.repo = NewHistoryRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *historySuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestHistorySuite(t *testing.T) {
+	suite.Run(t, new(historySuite))
+}
+
+func (s *historySuite) TestRecord() {
+	s.Run("success", func() {
+		fields := []entity.FieldChange{{Field: "title", From: "a", To: "b"}}
+		got, err := s.repo.ForWorkspace(2).Record(entity.TodoChange{TodoID: 1, ActorID: 3, Fields: fields})
+		s.Require().NoError(err)
+		s.Equal(&entity.TodoChange{ID: 1, WorkspaceID: 2, TodoID: 1, ActorID: 3, Fields: fields, At: time.Unix(123456789, 0)}, got)
+
+		changes, _ := s.repo.ForWorkspace(2).List(1)
+		s.Equal([]entity.TodoChange{*got}, changes)
+		changes, _ = s.repo.List(1)
+		s.Empty(changes, "workspaces have their own history")
+	})
+}
+
+func (s *historySuite) TestList() {
+	s.Run("changes of the todo, oldest first", func() {
+		_, _ = s.repo.Record(entity.TodoChange{TodoID: 1, Fields: []entity.FieldChange{{Field: "title"}}})
+		_, _ = s.repo.Record(entity.TodoChange{TodoID: 2, Fields: []entity.FieldChange{{Field: "title"}}})
+		_, _ = s.repo.Record(entity.TodoChange{TodoID: 1, Fields: []entity.FieldChange{{Field: "due"}}})
+
+		got, err := s.repo.List(1)
+		s.Require().NoError(err)
+		s.Len(got, 2)
+		s.Equal([]int{1, 3}, []int{got[0].ID, got[1].ID})
+	})
+}
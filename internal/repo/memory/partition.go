@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+)
+
+// partitions hands out one repo per workspace, created on first use. Each
+// repo has its own data, lock and ID sequences, so a query on one workspace
+// has nothing of another workspace to return.
+type partitions[T any] struct {
+	mu      sync.Mutex
+	repos   map[int]T
+	newRepo func(workspaceID int) T
+}
+
+func newPartitions[T any](newRepo func(workspaceID int) T) *partitions[T] {
+	return &partitions[T]{
+		repos:   make(map[int]T),
+		newRepo: newRepo,
+	}
+}
+
+func (p *partitions[T]) get(workspaceID int) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.repos[workspaceID]
+	if !ok {
+		r = p.newRepo(workspaceID)
+		p.repos[workspaceID] = r
+	}
+	return r
+}
+
+// all returns the repos of every workspace used so far, ordered by
+// workspace ID.
+func (p *partitions[T]) all() []T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]int, 0, len(p.repos))
+	for id := range p.repos {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	repos := make([]T, len(ids))
+	for i, id := range ids {
+		repos[i] = p.repos[id]
+	}
+	return repos
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"reflect"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+)
+
+// TestWorkspaceIsolation calls every method of repo.Todo on workspace 2
+// with the IDs of the todos and tags of workspace 1, and expects nothing of
+// workspace 1 to be returned or changed.
+func (s *todoSuite) TestWorkspaceIsolation() {
+	const (
+		theirs = 1
+		ours   = 2
+	)
+	seed := func() (repo.Todo, repo.Todo) {
+		them := s.repo.ForWorkspace(theirs)
+		for _, title := range []string{"a1", "a2", "a3"} {
+			_, err := them.Create(entity.CreateTodoInput{Title: title})
+			s.Require().NoError(err)
+		}
+		_, err := them.SetParent(2, 1)
+		s.Require().NoError(err)
+		_, err = them.AddDependency(3, 2)
+		s.Require().NoError(err)
+		_, err = them.AttachTags(1, []string{"a", "b"})
+		s.Require().NoError(err)
+
+		us := s.repo.ForWorkspace(ours)
+		_, err = us.Create(entity.CreateTodoInput{Title: "b1"})
+		s.Require().NoError(err)
+		return them, us
+	}
+	one := func(todo *entity.Todo) []entity.Todo {
+		if todo == nil {
+			return nil
+		}
+		return []entity.Todo{*todo}
+	}
+
+	tests := []struct {
+		method  string
+		call    func(r repo.Todo) ([]entity.Todo, error)
+		wantErr bool
+	}{
+		{method: "Create", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.Create(entity.CreateTodoInput{Title: "b2", ParentID: 2})
+			return one(todo), err
+		}, wantErr: true},
+		{method: "List", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return r.List()
+		}},
+		{method: "Get", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.Get(3)
+			return one(todo), err
+		}, wantErr: true},
+		{method: "Update", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.Update(3, entity.UpdateTodoInput{Title: lo.ToPtr("b")})
+		}, wantErr: true},
+		{method: "UpdateFunc", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.UpdateFunc(3, func(todo *entity.Todo) error {
+				todo.Title = "b"
+				return nil
+			})
+		}, wantErr: true},
+		{method: "Replace", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.Replace(3, entity.ReplaceTodoInput{Title: "b"})
+			return one(todo), err
+		}, wantErr: true},
+		{method: "Upsert", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, _, err := r.Upsert(3, entity.ReplaceTodoInput{Title: "b"})
+			return one(todo), err
+		}},
+		{method: "Delete", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.Delete(3, entity.SubtaskDeleteCascade)
+		}, wantErr: true},
+		{method: "Move", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.Move(1, entity.MoveTodoInput{After: lo.ToPtr(3)})
+			return one(todo), err
+		}, wantErr: true},
+		{method: "BatchCreate", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return r.BatchCreate([]entity.CreateTodoInput{{Title: "b2"}, {Title: "b3"}})
+		}},
+		{method: "BatchUpdate", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.BatchUpdate([]entity.BatchUpdateTodoInput{{ID: 3, Input: entity.UpdateTodoInput{Title: lo.ToPtr("b")}}})
+		}, wantErr: true},
+		{method: "BatchDelete", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.BatchDelete([]int{3})
+		}, wantErr: true},
+		{method: "SetParent", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.SetParent(1, 3)
+			return one(todo), err
+		}, wantErr: true},
+		{method: "Subtree", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return r.Subtree(3)
+		}, wantErr: true},
+		{method: "AddDependency", call: func(r repo.Todo) ([]entity.Todo, error) {
+			_, err := r.AddDependency(1, 3)
+			return nil, err
+		}, wantErr: true},
+		{method: "RemoveDependency", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.RemoveDependency(3, 2)
+		}, wantErr: true},
+		{method: "ListDependencies", call: func(r repo.Todo) ([]entity.Todo, error) {
+			deps, err := r.ListDependencies()
+			s.Empty(deps)
+			return nil, err
+		}},
+		{method: "CreateTag", call: func(r repo.Todo) ([]entity.Todo, error) {
+			tag, err := r.CreateTag("a")
+			s.Equal(1, tag.ID)
+			s.Zero(tag.TodoCount)
+			return nil, err
+		}},
+		{method: "ListTags", call: func(r repo.Todo) ([]entity.Todo, error) {
+			tags, err := r.ListTags()
+			s.Empty(tags)
+			return nil, err
+		}},
+		{method: "GetTag", call: func(r repo.Todo) ([]entity.Todo, error) {
+			_, err := r.GetTag(1)
+			return nil, err
+		}, wantErr: true},
+		{method: "RenameTag", call: func(r repo.Todo) ([]entity.Todo, error) {
+			_, err := r.RenameTag(1, "c")
+			return nil, err
+		}, wantErr: true},
+		{method: "DeleteTag", call: func(r repo.Todo) ([]entity.Todo, error) {
+			return nil, r.DeleteTag(1)
+		}, wantErr: true},
+		{method: "MergeTags", call: func(r repo.Todo) ([]entity.Todo, error) {
+			_, err := r.MergeTags(1, 2)
+			return nil, err
+		}, wantErr: true},
+		{method: "AttachTags", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.AttachTags(3, []string{"a"})
+			return one(todo), err
+		}, wantErr: true},
+		{method: "DetachTags", call: func(r repo.Todo) ([]entity.Todo, error) {
+			todo, err := r.DetachTags(1, []string{"a"})
+			return one(todo), err
+		}},
+		{method: "ListTagged", call: func(r repo.Todo) ([]entity.Todo, error) {
+			expr, err := entity.ParseTagExpr("a or b")
+			s.Require().NoError(err)
+			return r.ListTagged(expr)
+		}},
+	}
+
+	s.Run("every method is covered", func() {
+		var covered []string
+		for _, tt := range tests {
+			covered = append(covered, tt.method)
+		}
+		typ := reflect.TypeFor[repo.Todo]()
+		for i := range typ.NumMethod() {
+			name := typ.Method(i).Name
+			if name == "ForWorkspace" {
+				// Picking the workspace is up to the caller.
+				continue
+			}
+			if name == "CountOwned" {
+				// Quotas span workspaces, see TestCountOwned.
+				continue
+			}
+			s.Contains(covered, name)
+		}
+	})
+	for _, tt := range tests {
+		s.Run(tt.method, func() {
+			them, us := seed()
+			before, err := them.List()
+			s.Require().NoError(err)
+			deps, err := them.ListDependencies()
+			s.Require().NoError(err)
+			tags, err := them.ListTags()
+			s.Require().NoError(err)
+
+			got, err := tt.call(us)
+			if tt.wantErr {
+				s.Error(err)
+			} else {
+				s.NoError(err)
+			}
+			for _, todo := range got {
+				s.Equal(ours, todo.WorkspaceID, todo.Title)
+				s.NotContains([]string{"a1", "a2", "a3"}, todo.Title)
+			}
+
+			after, err := them.List()
+			s.Require().NoError(err)
+			s.Equal(before, after)
+			gotDeps, err := them.ListDependencies()
+			s.Require().NoError(err)
+			s.Equal(deps, gotDeps)
+			gotTags, err := them.ListTags()
+			s.Require().NoError(err)
+			s.Equal(tags, gotTags)
+		})
+	}
+}
+
+func (s *todoSuite) TestWorkspaceIDSequence() {
+	s.Run("each workspace numbers its own todos", func() {
+		for _, workspaceID := range []int{1, 2, 1} {
+			_, err := s.repo.ForWorkspace(workspaceID).Create(entity.CreateTodoInput{Title: "t"})
+			s.Require().NoError(err)
+		}
+
+		first, _ := s.repo.ForWorkspace(1).List()
+		second, _ := s.repo.ForWorkspace(2).List()
+		s.Equal([]int{1, 2}, lo.Map(first, func(todo entity.Todo, _ int) int { return todo.ID }))
+		s.Equal([]int{1}, lo.Map(second, func(todo entity.Todo, _ int) int { return todo.ID }))
+		s.Equal(2, second[0].WorkspaceID)
+	})
+	s.Run("the same workspace is the same repo", func() {
+		_, err := s.repo.ForWorkspace(1).Create(entity.CreateTodoInput{Title: "t"})
+		s.Require().NoError(err)
+
+		got, err := s.repo.ForWorkspace(2).ForWorkspace(1).Get(1)
+		s.Require().NoError(err)
+		s.Equal("t", got.Title)
+	})
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// projectRepo holds the projects of one workspace, numbered per workspace
+// like todos.
+type projectRepo struct {
+	workspaceID int
+	workspaces  *partitions[*projectRepo]
+
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.Project
+	shares    []entity.ProjectShare
+}
+
+// NewProjectRepo returns the repo of workspace zero. ForWorkspace reaches
+// the other workspaces.
+func NewProjectRepo() repo.Project {
+	var workspaces *partitions[*projectRepo]
+	workspaces = newPartitions(func(workspaceID int) *projectRepo {
+		return &projectRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			idCounter:   1,
+		}
+	})
+	return workspaces.get(0)
+}
+
+func (r *projectRepo) ForWorkspace(workspaceID int) repo.Project {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *projectRepo) Create(input entity.CreateProjectInput) (*entity.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := timeNow()
+	project := entity.Project{
+		ID:          r.idCounter,
+		WorkspaceID: r.workspaceID,
+		OwnerID:     input.OwnerID,
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.store = append(r.store, project)
+	r.idCounter++
+	return &project, nil
+}
+
+func (r *projectRepo) List() ([]entity.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.store), nil
+}
+
+func (r *projectRepo) Get(id int) (*entity.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	project := r.store[idx]
+	return &project, nil
+}
+
+func (r *projectRepo) Update(id int, input entity.UpdateProjectInput) (*entity.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	project := &r.store[idx]
+	input.Apply(project)
+	project.UpdatedAt = timeNow()
+
+	result := *project
+	return &result, nil
+}
+
+func (r *projectRepo) SetArchived(id int, archived bool) (*entity.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	project := &r.store[idx]
+	if project.Archived() != archived {
+		now := timeNow()
+		project.ArchivedAt = nil
+		if archived {
+			project.ArchivedAt = &now
+		}
+		project.UpdatedAt = now
+	}
+
+	result := *project
+	return &result, nil
+}
+
+func (r *projectRepo) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	r.store = slices.Delete(r.store, idx, idx+1)
+	r.shares = slices.DeleteFunc(r.shares, func(share entity.ProjectShare) bool {
+		return share.ProjectID == id
+	})
+	return nil
+}
+
+func (r *projectRepo) Share(projectID, userID int, role entity.Role) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(projectID) == -1 {
+		return false, repo.ErrNotFound
+	}
+	if idx := r.shareIndex(projectID, userID); idx != -1 {
+		r.shares[idx].Role = role
+		return false, nil
+	}
+	r.shares = append(r.shares, entity.ProjectShare{
+		ProjectID: projectID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: timeNow(),
+	})
+	return true, nil
+}
+
+func (r *projectRepo) Unshare(projectID, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.shareIndex(projectID, userID)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+	r.shares = slices.Delete(r.shares, idx, idx+1)
+	return nil
+}
+
+func (r *projectRepo) Shares(projectID int) ([]entity.ProjectShare, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.indexOf(projectID) == -1 {
+		return nil, repo.ErrNotFound
+	}
+	shares := []entity.ProjectShare{}
+	for _, share := range r.shares {
+		if share.ProjectID == projectID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (r *projectRepo) SharesOf(userID int) ([]entity.ProjectShare, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	shares := []entity.ProjectShare{}
+	for _, share := range r.shares {
+		if share.UserID == userID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (r *projectRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(project entity.Project) bool {
+		return project.ID == id
+	})
+}
+
+func (r *projectRepo) shareIndex(projectID, userID int) int {
+	return slices.IndexFunc(r.shares, func(share entity.ProjectShare) bool {
+		return share.ProjectID == projectID && share.UserID == userID
+	})
+}
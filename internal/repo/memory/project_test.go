@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type projectSuite struct {
+	suite.Suite
+	repo repo.Project
+}
+
+func (s *projectSuite) SetupSubTest() {
+	s.repo = NewProjectRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *projectSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestProjectSuite(t *testing.T) {
+	suite.Run(t, new(projectSuite))
+}
+
+func (s *projectSuite) TestCreate() {
+	s.Run("success", func() {
+		got, err := s.repo.Create(entity.CreateProjectInput{Name: "home", Description: "chores"})
+		s.Require().NoError(err)
+		s.Equal(&entity.Project{
+			ID:          1,
+			Name:        "home",
+			Description: "chores",
+			CreatedAt:   time.Unix(123456789, 0),
+			UpdatedAt:   time.Unix(123456789, 0),
+		}, got)
+
+		projects, _ := s.repo.List()
+		s.Equal([]entity.Project{*got}, projects)
+	})
+}
+
+func (s *projectSuite) TestGet() {
+	s.Run("success", func() {
+		created, _ := s.repo.Create(entity.CreateProjectInput{Name: "home"})
+
+		got, err := s.repo.Get(created.ID)
+		s.Require().NoError(err)
+		s.Equal(created, got)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestUpdate() {
+	s.Run("success", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home", Description: "chores"})
+		timeNow = func() time.Time {
+			return time.Unix(123456790, 0)
+		}
+
+		got, err := s.repo.Update(1, entity.UpdateProjectInput{Name: lo.ToPtr("house")})
+		s.Require().NoError(err)
+		s.Equal("house", got.Name)
+		s.Equal("chores", got.Description)
+		s.Equal(time.Unix(123456790, 0), got.UpdatedAt)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Update(1, entity.UpdateProjectInput{Name: lo.ToPtr("house")})
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestSetArchived() {
+	s.Run("archive and restore", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home"})
+
+		got, err := s.repo.SetArchived(1, true)
+		s.Require().NoError(err)
+		s.Equal(lo.ToPtr(time.Unix(123456789, 0)), got.ArchivedAt)
+
+		timeNow = func() time.Time {
+			return time.Unix(123456790, 0)
+		}
+		got, err = s.repo.SetArchived(1, true)
+		s.Require().NoError(err)
+		s.Equal(lo.ToPtr(time.Unix(123456789, 0)), got.ArchivedAt)
+
+		got, err = s.repo.SetArchived(1, false)
+		s.Require().NoError(err)
+		s.Nil(got.ArchivedAt)
+		s.Equal(time.Unix(123456790, 0), got.UpdatedAt)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.SetArchived(1, true)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestDelete() {
+	s.Run("success", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home"})
+
+		s.Require().NoError(s.repo.Delete(1))
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.Delete(1), repo.ErrNotFound)
+	})
+	s.Run("shares go with the project", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home"})
+		_, _ = s.repo.Share(1, 7, entity.RoleEditor)
+
+		s.Require().NoError(s.repo.Delete(1))
+		got, err := s.repo.SharesOf(7)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+}
+
+func (s *projectSuite) TestShare() {
+	s.Run("share and change role", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home"})
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "work"})
+
+		created, err := s.repo.Share(1, 7, entity.RoleViewer)
+		s.Require().NoError(err)
+		s.True(created)
+		created, err = s.repo.Share(1, 7, entity.RoleEditor)
+		s.Require().NoError(err)
+		s.False(created)
+		_, _ = s.repo.Share(2, 7, entity.RoleOwner)
+		_, _ = s.repo.Share(1, 8, entity.RoleViewer)
+
+		shares, err := s.repo.Shares(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.ProjectShare{
+			{ProjectID: 1, UserID: 7, Role: entity.RoleEditor, CreatedAt: time.Unix(123456789, 0)},
+			{ProjectID: 1, UserID: 8, Role: entity.RoleViewer, CreatedAt: time.Unix(123456789, 0)},
+		}, shares)
+		shares, err = s.repo.SharesOf(7)
+		s.Require().NoError(err)
+		s.Equal([]entity.Role{entity.RoleEditor, entity.RoleOwner}, lo.Map(shares, func(share entity.ProjectShare, _ int) entity.Role { return share.Role }))
+	})
+	s.Run("unshare", func() {
+		_, _ = s.repo.Create(entity.CreateProjectInput{Name: "home"})
+		_, _ = s.repo.Share(1, 7, entity.RoleViewer)
+
+		s.Require().NoError(s.repo.Unshare(1, 7))
+		s.ErrorIs(s.repo.Unshare(1, 7), repo.ErrNotFound)
+		shares, err := s.repo.Shares(1)
+		s.Require().NoError(err)
+		s.Empty(shares)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Share(1, 7, entity.RoleViewer)
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = s.repo.Shares(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestForWorkspace() {
+	s.Run("workspaces do not share projects", func() {
+		theirs := s.repo.ForWorkspace(1)
+		ours := s.repo.ForWorkspace(2)
+		_, _ = theirs.Create(entity.CreateProjectInput{Name: "theirs"})
+		_, _ = theirs.Create(entity.CreateProjectInput{Name: "theirs"})
+
+		created, err := ours.Create(entity.CreateProjectInput{Name: "ours"})
+		s.Require().NoError(err)
+		s.Equal(1, created.ID)
+		s.Equal(2, created.WorkspaceID)
+
+		got, err := ours.List()
+		s.Require().NoError(err)
+		s.Equal([]entity.Project{*created}, got)
+		_, err = ours.Get(2)
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = ours.Update(2, entity.UpdateProjectInput{Name: lo.ToPtr("ours")})
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = ours.SetArchived(2, true)
+		s.ErrorIs(err, repo.ErrNotFound)
+		s.ErrorIs(ours.Delete(2), repo.ErrNotFound)
+
+		left, _ := theirs.List()
+		s.Len(left, 2)
+		s.Equal("theirs", left[1].Name)
+	})
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// Subtree returns the todo followed by its subtasks, every parent before its
+// children and siblings in manual order.
+func (r *todoRepo) Subtree(id int) ([]entity.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.indexOf(id) == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	idxs := subtree(r.store, id)
+	todos := make([]entity.Todo, len(idxs))
+	for i, idx := range idxs {
+		todos[i] = r.store[idx]
+	}
+	return todos, nil
+}
+
+func (r *todoRepo) SetParent(id, parentID int) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	if err := validateParent(r.store, id, parentID); err != nil {
+		return nil, err
+	}
+
+	prev := r.store[idx]
+	if prev.ParentID != parentID {
+		now := timeNow()
+		r.store[idx].ParentID = parentID
+		r.store[idx].UpdatedAt = now
+		rollup(r.store, idx, prev, now)
+		r.refreshBlocked(r.store)
+	}
+
+	todo := r.store[idx]
+	return &todo, nil
+}
+
+// validateParent checks that the todo with id may hang below parentID. A new
+// todo passes a zero id.
+func validateParent(store []entity.Todo, id, parentID int) error {
+	if parentID == 0 {
+		return nil
+	}
+	if !slices.ContainsFunc(store, func(todo entity.Todo) bool { return todo.ID == parentID }) {
+		return entity.ErrParentNotFound
+	}
+	if id == 0 {
+		return nil
+	}
+	for _, idx := range subtree(store, id) {
+		if store[idx].ID == parentID {
+			return entity.ErrParentCycle
+		}
+	}
+	return nil
+}
+
+// subtree returns the store indexes of the todo with id and its descendants
+// in depth-first order.
+func subtree(store []entity.Todo, id int) []int {
+	children := make(map[int][]int)
+	root := -1
+	for idx, todo := range store {
+		if todo.ID == id {
+			root = idx
+		}
+		if todo.ParentID != 0 {
+			children[todo.ParentID] = append(children[todo.ParentID], idx)
+		}
+	}
+	if root == -1 {
+		return nil
+	}
+
+	var idxs []int
+	var walk func(idx int)
+	walk = func(idx int) {
+		idxs = append(idxs, idx)
+		kids := children[store[idx].ID]
+		slices.SortFunc(kids, func(a, b int) int {
+			return strings.Compare(store[a].Rank, store[b].Rank)
+		})
+		for _, kid := range kids {
+			walk(kid)
+		}
+	}
+	walk(root)
+	return idxs
+}
+
+// rollup applies the rollup rules after the todo at idx changed from prev.
+// New todos pass a zero prev.
+func rollup(store []entity.Todo, idx int, prev entity.Todo, now time.Time) {
+	todo := store[idx]
+	if todo.IsCompleted && !prev.IsCompleted && todo.Rollup.Cascades() {
+		completeSubtree(store, todo.ID, now)
+	}
+	if todo.Rollup != prev.Rollup {
+		rollupFrom(store, todo.ID, now)
+	}
+	if todo.ParentID != prev.ParentID {
+		rollupFrom(store, prev.ParentID, now)
+	}
+	if todo.ParentID != prev.ParentID || todo.IsCompleted != prev.IsCompleted {
+		rollupFrom(store, todo.ParentID, now)
+	}
+}
+
+// rollupFrom recomputes the completion of auto rollup todos, starting at id
+// and walking up for as long as something changes.
+func rollupFrom(store []entity.Todo, id int, now time.Time) {
+	for id != 0 {
+		idx := slices.IndexFunc(store, func(todo entity.Todo) bool { return todo.ID == id })
+		if idx == -1 || !store[idx].Rollup.AutoCompletes() {
+			return
+		}
+
+		hasChildren, complete := false, true
+		for _, todo := range store {
+			if todo.ParentID == id {
+				hasChildren = true
+				complete = complete && todo.IsCompleted
+			}
+		}
+		if !hasChildren || store[idx].IsCompleted == complete {
+			return
+		}
+
+		store[idx].IsCompleted = complete
+		store[idx].UpdatedAt = now
+		if complete && store[idx].Rollup.Cascades() {
+			completeSubtree(store, id, now)
+		}
+		id = store[idx].ParentID
+	}
+}
+
+func completeSubtree(store []entity.Todo, id int, now time.Time) {
+	for _, idx := range subtree(store, id)[1:] {
+		if !store[idx].IsCompleted {
+			store[idx].IsCompleted = true
+			store[idx].UpdatedAt = now
+		}
+	}
+}
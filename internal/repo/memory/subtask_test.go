@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+)
+
+// createTree creates todos from parent IDs, where parents[i] is the parent of
+// todo i+1.
+func (s *todoSuite) createTree(rollup entity.Rollup, parents ...int) {
+	for _, parentID := range parents {
+		_, err := s.repo.Create(entity.CreateTodoInput{Title: "title", ParentID: parentID, Rollup: rollup})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *todoSuite) completed() map[int]bool {
+	todos, _ := s.repo.List()
+	return lo.SliceToMap(todos, func(todo entity.Todo) (int, bool) { return todo.ID, todo.IsCompleted })
+}
+
+func (s *todoSuite) TestCreateSubtask() {
+	s.Run("success", func() {
+		s.createTree(entity.RollupNone, 0)
+
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-2", ParentID: 1, Rollup: entity.RollupAuto})
+		s.Require().NoError(err)
+		s.Equal(1, got.ParentID)
+		s.Equal(entity.RollupAuto, got.Rollup)
+	})
+	s.Run("default rollup", func() {
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		s.Require().NoError(err)
+		s.Equal(entity.RollupNone, got.Rollup)
+	})
+	s.Run("parent not found", func() {
+		_, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", ParentID: 2})
+		s.ErrorIs(err, entity.ErrParentNotFound)
+	})
+	s.Run("batch parent not found", func() {
+		_, err := s.repo.BatchCreate([]entity.CreateTodoInput{{Title: "title-1"}, {Title: "title-2", ParentID: 5}})
+		s.ErrorIs(err, entity.ErrParentNotFound)
+		s.Equal(&repo.BatchError{Index: 1, Err: entity.ErrParentNotFound}, err)
+	})
+}
+
+func (s *todoSuite) TestSubtree() {
+	s.Run("parents before children in manual order", func() {
+		s.createTree(entity.RollupNone, 0, 1, 1, 2, 0)
+		_, err := s.repo.Move(3, entity.MoveTodoInput{Before: lo.ToPtr(2)})
+		s.Require().NoError(err)
+
+		got, err := s.repo.Subtree(1)
+		s.Require().NoError(err)
+		s.Equal([]int{1, 3, 2, 4}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("leaf", func() {
+		s.createTree(entity.RollupNone, 0, 1)
+
+		got, err := s.repo.Subtree(2)
+		s.Require().NoError(err)
+		s.Len(got, 1)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Subtree(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestSetParent() {
+	tests := []struct {
+		desc     string
+		id       int
+		parentID int
+		wantErr  error
+	}{
+		{desc: "below another todo", id: 3, parentID: 2},
+		{desc: "to the top level", id: 2, parentID: 0},
+		{desc: "below itself", id: 2, parentID: 2, wantErr: entity.ErrParentCycle},
+		{desc: "below a descendant", id: 1, parentID: 2, wantErr: entity.ErrParentCycle},
+		{desc: "parent not found", id: 2, parentID: 9, wantErr: entity.ErrParentNotFound},
+		{desc: "not found", id: 9, parentID: 1, wantErr: repo.ErrNotFound},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.createTree(entity.RollupNone, 0, 1, 0)
+
+			got, err := s.repo.SetParent(tt.id, tt.parentID)
+			s.ErrorIs(err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			s.Equal(tt.parentID, got.ParentID)
+			stored, _ := s.repo.Get(tt.id)
+			s.Equal(got, stored)
+		})
+	}
+}
+
+func (s *todoSuite) TestRollup() {
+	s.Run("auto completes and reopens the parent", func() {
+		s.createTree(entity.RollupAuto, 0, 1, 1)
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: false, 2: true, 3: false}, s.completed())
+
+		s.Require().NoError(s.repo.Update(3, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: true, 2: true, 3: true}, s.completed())
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(false)}))
+		s.Equal(map[int]bool{1: false, 2: false, 3: true}, s.completed())
+	})
+	s.Run("auto walks up the tree", func() {
+		s.createTree(entity.RollupAuto, 0, 1, 2)
+
+		s.Require().NoError(s.repo.Update(3, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: true, 2: true, 3: true}, s.completed())
+	})
+	s.Run("new open subtask reopens the parent", func() {
+		s.createTree(entity.RollupAuto, 0, 1)
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+
+		s.createTree(entity.RollupAuto, 1)
+		s.Equal(map[int]bool{1: false, 2: true, 3: false}, s.completed())
+	})
+	s.Run("none leaves the parent alone", func() {
+		s.createTree(entity.RollupNone, 0, 1)
+
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: false, 2: true}, s.completed())
+	})
+	s.Run("cascade completes the subtree", func() {
+		s.createTree(entity.RollupCascade, 0, 1, 2, 0)
+
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: true, 2: true, 3: true, 4: false}, s.completed())
+	})
+	s.Run("cascade does not reopen", func() {
+		s.createTree(entity.RollupCascade, 0, 1)
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(false)}))
+		s.Equal(map[int]bool{1: false, 2: true}, s.completed())
+	})
+	s.Run("switching to auto applies at once", func() {
+		s.createTree(entity.RollupNone, 0, 1)
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{Rollup: lo.ToPtr(entity.RollupAuto)}))
+		s.Equal(map[int]bool{1: true, 2: true}, s.completed())
+	})
+	s.Run("re-parenting recomputes both parents", func() {
+		s.createTree(entity.RollupAuto, 0, 0, 1, 2)
+		s.Require().NoError(s.repo.Update(3, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+		s.Equal(map[int]bool{1: true, 2: false, 3: true, 4: false}, s.completed())
+
+		_, err := s.repo.SetParent(4, 1)
+		s.Require().NoError(err)
+		s.Equal(map[int]bool{1: false, 2: false, 3: true, 4: false}, s.completed())
+	})
+	s.Run("batch update", func() {
+		s.createTree(entity.RollupAuto, 0, 1, 1)
+
+		err := s.repo.BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 2, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+			{ID: 3, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+		})
+		s.Require().NoError(err)
+		s.Equal(map[int]bool{1: true, 2: true, 3: true}, s.completed())
+	})
+	s.Run("replace", func() {
+		s.createTree(entity.RollupAuto, 0, 1)
+
+		_, err := s.repo.Replace(2, entity.ReplaceTodoInput{Title: "title", IsCompleted: true})
+		s.Require().NoError(err)
+		s.Equal(map[int]bool{1: true, 2: true}, s.completed())
+	})
+}
+
+func (s *todoSuite) TestDeleteSubtasks() {
+	s.Run("cascade", func() {
+		s.createTree(entity.RollupNone, 0, 1, 2, 0)
+		_, _ = s.repo.AttachTags(3, []string{"work"})
+
+		s.Require().NoError(s.repo.Delete(1, entity.SubtaskDeleteCascade))
+		todos, _ := s.repo.List()
+		s.Equal([]int{4}, lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID }))
+		tags, _ := s.repo.ListTags()
+		s.Equal(0, tags[0].TodoCount)
+	})
+	s.Run("promote", func() {
+		s.createTree(entity.RollupNone, 0, 1, 2, 3)
+
+		s.Require().NoError(s.repo.Delete(2, entity.SubtaskDeletePromote))
+		got, _ := s.repo.Get(3)
+		s.Equal(1, got.ParentID)
+		got, _ = s.repo.Get(4)
+		s.Equal(3, got.ParentID)
+	})
+	s.Run("block", func() {
+		s.createTree(entity.RollupNone, 0, 1)
+
+		s.ErrorIs(s.repo.Delete(1, entity.SubtaskDeleteBlock), entity.ErrHasSubtasks)
+		s.NoError(s.repo.Delete(2, entity.SubtaskDeleteBlock))
+	})
+	s.Run("deleting the last open subtask completes an auto parent", func() {
+		s.createTree(entity.RollupAuto, 0, 1, 1)
+		s.Require().NoError(s.repo.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}))
+
+		s.Require().NoError(s.repo.Delete(3, entity.SubtaskDeleteCascade))
+		s.Equal(map[int]bool{1: true, 2: true}, s.completed())
+	})
+	s.Run("batch delete cascades", func() {
+		s.createTree(entity.RollupNone, 0, 1, 0, 3)
+
+		s.Require().NoError(s.repo.BatchDelete([]int{1, 4}))
+		todos, _ := s.repo.List()
+		s.Equal([]int{3}, lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+}
@@ -0,0 +1,327 @@
+package memory
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+func (r *todoRepo) CreateTag(name string) (*entity.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name, err := entity.NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.tagIndexOf(name) != -1 {
+		return nil, entity.ErrTagExists
+	}
+
+	tag := r.createTag(name)
+	return &tag, nil
+}
+
+func (r *todoRepo) ListTags() ([]entity.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]entity.Tag, len(r.tags))
+	for i, tag := range r.tags {
+		tags[i] = r.counted(tag)
+	}
+	slices.SortFunc(tags, func(a, b entity.Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tags, nil
+}
+
+func (r *todoRepo) GetTag(id int) (*entity.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.tagIndexByID(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	tag := r.counted(r.tags[idx])
+	return &tag, nil
+}
+
+// RenameTag renames the tag on every todo carrying it.
+func (r *todoRepo) RenameTag(id int, name string) (*entity.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.tagIndexByID(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	name, err := entity.NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if other := r.tagIndexOf(name); other != -1 && other != idx {
+		return nil, entity.ErrTagExists
+	}
+
+	old := r.tags[idx].Name
+	if old != name {
+		now := timeNow()
+		for todoID := range r.tagged[old] {
+			todo := &r.store[r.indexOf(todoID)]
+			todo.Tags = withTag(withoutTag(todo.Tags, old), name)
+			todo.UpdatedAt = now
+		}
+		if ids, ok := r.tagged[old]; ok {
+			r.tagged[name] = ids
+			delete(r.tagged, old)
+		}
+		r.tags[idx].Name = name
+		r.tags[idx].UpdatedAt = now
+	}
+
+	tag := r.counted(r.tags[idx])
+	return &tag, nil
+}
+
+// DeleteTag detaches the tag from every todo before deleting it.
+func (r *todoRepo) DeleteTag(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.tagIndexByID(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	name := r.tags[idx].Name
+	now := timeNow()
+	for todoID := range r.tagged[name] {
+		todo := &r.store[r.indexOf(todoID)]
+		todo.Tags = withoutTag(todo.Tags, name)
+		todo.UpdatedAt = now
+	}
+	delete(r.tagged, name)
+	r.tags = slices.Delete(r.tags, idx, idx+1)
+	return nil
+}
+
+func (r *todoRepo) MergeTags(sourceID, targetID int) (*entity.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sourceID == targetID {
+		return nil, entity.ErrMergeSelf
+	}
+	source, target := r.tagIndexByID(sourceID), r.tagIndexByID(targetID)
+	if source == -1 || target == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	from, into := r.tags[source].Name, r.tags[target].Name
+	now := timeNow()
+	for todoID := range r.tagged[from] {
+		todo := &r.store[r.indexOf(todoID)]
+		todo.Tags = withTag(withoutTag(todo.Tags, from), into)
+		todo.UpdatedAt = now
+		r.index(into, todoID)
+	}
+	delete(r.tagged, from)
+	r.tags[target].UpdatedAt = now
+	tag := r.counted(r.tags[target])
+	r.tags = slices.Delete(r.tags, source, source+1)
+	return &tag, nil
+}
+
+func (r *todoRepo) AttachTags(todoID int, names []string) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(todoID)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := &r.store[idx]
+	for _, name := range names {
+		if r.tagIndexOf(name) == -1 {
+			r.createTag(name)
+		}
+		if !slices.Contains(todo.Tags, name) {
+			todo.Tags = withTag(todo.Tags, name)
+			todo.UpdatedAt = timeNow()
+			r.index(name, todoID)
+		}
+	}
+
+	result := *todo
+	return &result, nil
+}
+
+func (r *todoRepo) DetachTags(todoID int, names []string) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(todoID)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := &r.store[idx]
+	for _, name := range names {
+		if slices.Contains(todo.Tags, name) {
+			todo.Tags = withoutTag(todo.Tags, name)
+			todo.UpdatedAt = timeNow()
+			delete(r.tagged[name], todoID)
+		}
+	}
+
+	result := *todo
+	return &result, nil
+}
+
+// ListTagged resolves the expression against the inverted index and returns
+// the matching todos in store order.
+func (r *todoRepo) ListTagged(expr entity.TagExpr) ([]entity.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.matchTags(expr)
+	var todos []entity.Todo
+	for _, todo := range r.store {
+		if _, ok := ids[todo.ID]; ok {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (r *todoRepo) matchTags(expr entity.TagExpr) map[int]struct{} {
+	switch e := expr.(type) {
+	case entity.TagName:
+		return r.tagged[string(e)]
+	case entity.TagAnd:
+		left, right := r.matchTags(e.Left), r.matchTags(e.Right)
+		ids := make(map[int]struct{})
+		for id := range left {
+			if _, ok := right[id]; ok {
+				ids[id] = struct{}{}
+			}
+		}
+		return ids
+	case entity.TagOr:
+		ids := maps.Clone(r.matchTags(e.Left))
+		if ids == nil {
+			ids = make(map[int]struct{})
+		}
+		maps.Copy(ids, r.matchTags(e.Right))
+		return ids
+	case entity.TagNot:
+		exclude := r.matchTags(e.Expr)
+		ids := make(map[int]struct{})
+		for _, todo := range r.store {
+			if _, ok := exclude[todo.ID]; !ok {
+				ids[todo.ID] = struct{}{}
+			}
+		}
+		return ids
+	}
+
+	// Expressions the index does not know about are evaluated per todo.
+	ids := make(map[int]struct{})
+	for _, todo := range r.store {
+		if expr.Eval(func(tag string) bool { return slices.Contains(todo.Tags, tag) }) {
+			ids[todo.ID] = struct{}{}
+		}
+	}
+	return ids
+}
+
+func (r *todoRepo) createTag(name string) entity.Tag {
+	now := timeNow()
+	tag := entity.Tag{
+		ID:        r.tagCounter,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.tags = append(r.tags, tag)
+	r.tagCounter++
+	return tag
+}
+
+func (r *todoRepo) counted(tag entity.Tag) entity.Tag {
+	tag.TodoCount = len(r.tagged[tag.Name])
+	return tag
+}
+
+func (r *todoRepo) index(name string, todoID int) {
+	if r.tagged[name] == nil {
+		r.tagged[name] = make(map[int]struct{})
+	}
+	r.tagged[name][todoID] = struct{}{}
+}
+
+// untagTodo drops a deleted todo from the inverted index.
+func (r *todoRepo) untagTodo(todo entity.Todo) {
+	for _, name := range todo.Tags {
+		delete(r.tagged[name], todo.ID)
+	}
+}
+
+func (r *todoRepo) tagIndexOf(name string) int {
+	return slices.IndexFunc(r.tags, func(tag entity.Tag) bool {
+		return tag.Name == name
+	})
+}
+
+func (r *todoRepo) tagIndexByID(id int) int {
+	return slices.IndexFunc(r.tags, func(tag entity.Tag) bool {
+		return tag.ID == id
+	})
+}
+
+func normalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		var err error
+		if normalized[i], err = entity.NormalizeTagName(name); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
+
+// withTag and withoutTag return new sorted slices so copies of a todo handed
+// out earlier never see the change.
+func withTag(tags []string, name string) []string {
+	if slices.Contains(tags, name) {
+		return tags
+	}
+	tags = append(slices.Clone(tags), name)
+	slices.Sort(tags)
+	return tags
+}
+
+func withoutTag(tags []string, name string) []string {
+	tags = slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+		return tag == name
+	})
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,281 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+)
+
+func (s *todoSuite) TestCreateTag() {
+	tests := []struct {
+		desc    string
+		setup   func()
+		name    string
+		want    *entity.Tag
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+			},
+			name: " Work ",
+			want: &entity.Tag{
+				ID:        1,
+				Name:      "work",
+				CreatedAt: time.Unix(123456789, 0),
+				UpdatedAt: time.Unix(123456789, 0),
+			},
+		},
+		{
+			desc:    "invalid name",
+			setup:   func() {},
+			name:    "two words",
+			wantErr: entity.ErrInvalidTagName,
+		},
+		{
+			desc:    "reserved name",
+			setup:   func() {},
+			name:    "NOT",
+			wantErr: entity.ErrInvalidTagName,
+		},
+		{
+			desc: "exists",
+			setup: func() {
+				_, _ = s.repo.CreateTag("work")
+			},
+			name:    "WORK",
+			wantErr: entity.ErrTagExists,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.repo.CreateTag(tt.name)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *todoSuite) TestAttachTags() {
+	s.Run("creates missing tags and counts todos", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2"})
+		_, _ = s.repo.CreateTag("home")
+
+		got, err := s.repo.AttachTags(1, []string{"Work", "home", "work"})
+		s.Require().NoError(err)
+		s.Equal([]string{"home", "work"}, got.Tags)
+		_, _ = s.repo.AttachTags(2, []string{"work"})
+
+		tags, _ := s.repo.ListTags()
+		s.Equal([]string{"home", "work"}, lo.Map(tags, func(tag entity.Tag, _ int) string { return tag.Name }))
+		s.Equal([]int{1, 2}, lo.Map(tags, func(tag entity.Tag, _ int) int { return tag.TodoCount }))
+	})
+	s.Run("todo not found", func() {
+		_, err := s.repo.AttachTags(1, []string{"work"})
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("invalid name attaches nothing", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+
+		_, err := s.repo.AttachTags(1, []string{"work", "a|b"})
+		s.ErrorIs(err, entity.ErrInvalidTagName)
+
+		got, _ := s.repo.Get(1)
+		s.Empty(got.Tags)
+		tags, _ := s.repo.ListTags()
+		s.Empty(tags)
+	})
+	s.Run("detach keeps the tag", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		_, _ = s.repo.AttachTags(1, []string{"work", "home"})
+
+		got, err := s.repo.DetachTags(1, []string{"WORK", "unknown"})
+		s.Require().NoError(err)
+		s.Equal([]string{"home"}, got.Tags)
+
+		tag, _ := s.repo.GetTag(1)
+		s.Equal("work", tag.Name)
+		s.Zero(tag.TodoCount)
+	})
+	s.Run("copies handed out are not changed", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		before, _ := s.repo.AttachTags(1, []string{"work"})
+
+		_, _ = s.repo.AttachTags(1, []string{"home"})
+		s.Equal([]string{"work"}, before.Tags)
+	})
+	s.Run("deleted todos leave the index", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2"})
+		_, _ = s.repo.AttachTags(1, []string{"work"})
+		_, _ = s.repo.AttachTags(2, []string{"work"})
+
+		_ = s.repo.Delete(1, entity.SubtaskDeleteCascade)
+
+		tag, _ := s.repo.GetTag(1)
+		s.Equal(1, tag.TodoCount)
+		todos, _ := s.repo.ListTagged(entity.TagName("work"))
+		s.Equal([]int{2}, lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+}
+
+func (s *todoSuite) TestRenameTag() {
+	setup := func() {
+		timeNow = func() time.Time {
+			return time.Unix(123456789, 0)
+		}
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		_, _ = s.repo.AttachTags(1, []string{"work", "home"})
+		timeNow = func() time.Time {
+			return time.Unix(123456790, 0)
+		}
+	}
+
+	s.Run("success", func() {
+		setup()
+
+		got, err := s.repo.RenameTag(2, "Office")
+		s.Require().NoError(err)
+		s.Equal("office", got.Name)
+		s.Equal(1, got.TodoCount)
+		s.Equal(time.Unix(123456790, 0), got.UpdatedAt)
+
+		todo, _ := s.repo.Get(1)
+		s.Equal([]string{"office", "work"}, todo.Tags)
+		s.Equal(time.Unix(123456790, 0), todo.UpdatedAt)
+		todos, _ := s.repo.ListTagged(entity.TagName("office"))
+		s.Len(todos, 1)
+		todos, _ = s.repo.ListTagged(entity.TagName("home"))
+		s.Empty(todos)
+	})
+	s.Run("same name", func() {
+		setup()
+
+		got, err := s.repo.RenameTag(1, "WORK")
+		s.Require().NoError(err)
+		s.Equal(time.Unix(123456789, 0), got.UpdatedAt)
+	})
+	s.Run("taken", func() {
+		setup()
+
+		_, err := s.repo.RenameTag(1, "home")
+		s.ErrorIs(err, entity.ErrTagExists)
+	})
+	s.Run("not found", func() {
+		setup()
+
+		_, err := s.repo.RenameTag(9, "office")
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestDeleteTag() {
+	s.Run("detaches from todos", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		_, _ = s.repo.AttachTags(1, []string{"work", "home"})
+
+		s.Require().NoError(s.repo.DeleteTag(1))
+
+		todo, _ := s.repo.Get(1)
+		s.Equal([]string{"home"}, todo.Tags)
+		_, err := s.repo.GetTag(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+		todos, _ := s.repo.ListTagged(entity.TagName("work"))
+		s.Empty(todos)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.DeleteTag(1), repo.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestMergeTags() {
+	setup := func() {
+		for range 3 {
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title"})
+		}
+		_, _ = s.repo.AttachTags(1, []string{"job"})
+		_, _ = s.repo.AttachTags(2, []string{"job", "work"})
+		_, _ = s.repo.AttachTags(3, []string{"work"})
+	}
+
+	s.Run("success", func() {
+		setup()
+
+		got, err := s.repo.MergeTags(1, 2)
+		s.Require().NoError(err)
+		s.Equal("work", got.Name)
+		s.Equal(3, got.TodoCount)
+
+		for id := 1; id <= 3; id++ {
+			todo, _ := s.repo.Get(id)
+			s.Equal([]string{"work"}, todo.Tags)
+		}
+		_, err = s.repo.GetTag(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("into itself", func() {
+		setup()
+
+		_, err := s.repo.MergeTags(1, 1)
+		s.ErrorIs(err, entity.ErrMergeSelf)
+	})
+	s.Run("not found", func() {
+		setup()
+
+		_, err := s.repo.MergeTags(1, 9)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestListTagged() {
+	setup := func() {
+		for _, tags := range [][]string{{"work", "urgent"}, {"work"}, {"home"}, nil} {
+			todo, _ := s.repo.Create(entity.CreateTodoInput{Title: "title"})
+			if tags != nil {
+				_, _ = s.repo.AttachTags(todo.ID, tags)
+			}
+		}
+	}
+
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{expr: "work", want: []int{1, 2}},
+		{expr: "unknown", want: []int{}},
+		{expr: "work and urgent", want: []int{1}},
+		{expr: "work & not urgent", want: []int{2}},
+		{expr: "urgent or home", want: []int{1, 3}},
+		{expr: "urgent, home", want: []int{1, 3}},
+		{expr: "!work", want: []int{3, 4}},
+		{expr: "not (work or home)", want: []int{4}},
+		{expr: "home or work and not urgent", want: []int{2, 3}},
+		{expr: "(home or work) and not urgent", want: []int{2, 3}},
+		{expr: "not not home", want: []int{3}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.expr, func() {
+			setup()
+			expr, err := entity.ParseTagExpr(tt.expr)
+			s.Require().NoError(err)
+
+			got, err := s.repo.ListTagged(expr)
+			s.Require().NoError(err)
+			s.Equal(tt.want, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+		})
+	}
+
+	for _, expr := range []string{"", "work and", "(work", "work)", "work home", "a@b", "and"} {
+		s.Run("invalid "+expr, func() {
+			_, err := entity.ParseTagExpr(expr)
+			s.ErrorIs(err, entity.ErrInvalidTagExpr)
+		})
+	}
+}
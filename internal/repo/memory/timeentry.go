@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// timeEntryRepo holds the time entries of one workspace.
+type timeEntryRepo struct {
+	workspaceID int
+	workspaces  *partitions[*timeEntryRepo]
+	// timers is shared by every workspace and held while a timer starts, so
+	// a user cannot end up with two running timers in different workspaces.
+	timers *sync.Mutex
+
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.TimeEntry
+}
+
+// NewTimeEntryRepo returns the repo of workspace zero. ForWorkspace reaches
+// the other workspaces.
+func NewTimeEntryRepo() repo.TimeEntry {
+	var workspaces *partitions[*timeEntryRepo]
+	timers := &sync.Mutex{}
+	workspaces = newPartitions(func(workspaceID int) *timeEntryRepo {
+		return &timeEntryRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			timers:      timers,
+			idCounter:   1,
+		}
+	})
+	return workspaces.get(0)
+}
+
+func (r *timeEntryRepo) ForWorkspace(workspaceID int) repo.TimeEntry {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *timeEntryRepo) Create(input entity.CreateTimeEntryInput) (*entity.TimeEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(input), nil
+}
+
+func (r *timeEntryRepo) Start(input entity.CreateTimeEntryInput) (*entity.TimeEntry, *entity.TimeEntry, error) {
+	r.timers.Lock()
+	defer r.timers.Unlock()
+
+	stopped, err := r.stop(input.UserID, input.Start)
+	if err != nil && !errors.Is(err, entity.ErrNoRunningTimer) {
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	input.End = nil
+	return r.insert(input), stopped, nil
+}
+
+func (r *timeEntryRepo) Stop(userID int, end time.Time) (*entity.TimeEntry, error) {
+	r.timers.Lock()
+	defer r.timers.Unlock()
+
+	return r.stop(userID, end)
+}
+
+// stop ends the running timer of userID at end, or at its start when it
+// started later. The caller holds timers.
+func (r *timeEntryRepo) stop(userID int, end time.Time) (*entity.TimeEntry, error) {
+	for _, workspace := range r.workspaces.all() {
+		workspace.mu.Lock()
+		idx := workspace.runningOf(userID)
+		if idx == -1 {
+			workspace.mu.Unlock()
+			continue
+		}
+		entry := &workspace.store[idx]
+		stoppedAt := end
+		if stoppedAt.Before(entry.Start) {
+			stoppedAt = entry.Start
+		}
+		entry.End = &stoppedAt
+		entry.UpdatedAt = timeNow()
+		stopped := *entry
+		workspace.mu.Unlock()
+		return &stopped, nil
+	}
+	return nil, entity.ErrNoRunningTimer
+}
+
+func (r *timeEntryRepo) Running(userID int) (*entity.TimeEntry, error) {
+	for _, workspace := range r.workspaces.all() {
+		workspace.mu.RLock()
+		idx := workspace.runningOf(userID)
+		if idx != -1 {
+			entry := workspace.store[idx]
+			workspace.mu.RUnlock()
+			return &entry, nil
+		}
+		workspace.mu.RUnlock()
+	}
+	return nil, entity.ErrNoRunningTimer
+}
+
+func (r *timeEntryRepo) Get(id int) (*entity.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	entry := r.store[idx]
+	return &entry, nil
+}
+
+func (r *timeEntryRepo) List(todoID int) ([]entity.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []entity.TimeEntry
+	for _, entry := range r.store {
+		if entry.TodoID == todoID {
+			entries = append(entries, entry)
+		}
+	}
+	sortTimeEntries(entries)
+	return entries, nil
+}
+
+func (r *timeEntryRepo) ListAll() ([]entity.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := slices.Clone(r.store)
+	sortTimeEntries(entries)
+	return entries, nil
+}
+
+func (r *timeEntryRepo) Update(id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	entry := &r.store[idx]
+	input.Apply(entry)
+	entry.UpdatedAt = timeNow()
+	updated := *entry
+	return &updated, nil
+}
+
+func (r *timeEntryRepo) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+	r.store = slices.Delete(r.store, idx, idx+1)
+	return nil
+}
+
+// insert stores a new entry. The caller holds mu.
+func (r *timeEntryRepo) insert(input entity.CreateTimeEntryInput) *entity.TimeEntry {
+	now := timeNow()
+	entry := entity.TimeEntry{
+		ID:          r.idCounter,
+		WorkspaceID: r.workspaceID,
+		TodoID:      input.TodoID,
+		UserID:      input.UserID,
+		Start:       input.Start,
+		End:         input.End,
+		Note:        input.Note,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	r.store = append(r.store, entry)
+	r.idCounter++
+	return &entry
+}
+
+func (r *timeEntryRepo) runningOf(userID int) int {
+	return slices.IndexFunc(r.store, func(entry entity.TimeEntry) bool {
+		return entry.UserID == userID && entry.Running()
+	})
+}
+
+func (r *timeEntryRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(entry entity.TimeEntry) bool {
+		return entry.ID == id
+	})
+}
+
+func sortTimeEntries(entries []entity.TimeEntry) {
+	slices.SortFunc(entries, func(a, b entity.TimeEntry) int {
+		return strings.Compare(a.Key(), b.Key())
+	})
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+)
+
+type timeEntrySuite struct {
+	suite.Suite
+	repo repo.TimeEntry
+}
+
+func (s *timeEntrySuite) SetupSubTest() {
+	s.repo = NewTimeEntryRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *timeEntrySuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestTimeEntrySuite(t *testing.T) {
+	suite.Run(t, new(timeEntrySuite))
+}
+
+func (s *timeEntrySuite) start(workspaceID, todoID, userID int, start time.Time) (*entity.TimeEntry, *entity.TimeEntry) {
+	started, stopped, err := s.repo.ForWorkspace(workspaceID).Start(entity.CreateTimeEntryInput{
+		TodoID: todoID,
+		UserID: userID,
+		Start:  start,
+	})
+	s.Require().NoError(err)
+	return started, stopped
+}
+
+func (s *timeEntrySuite) TestCreate() {
+	s.Run("success", func() {
+		got, err := s.repo.ForWorkspace(2).Create(entity.CreateTimeEntryInput{
+			TodoID: 1,
+			UserID: 3,
+			Start:  time.Unix(100, 0),
+			End:    lo.ToPtr(time.Unix(200, 0)),
+			Note:   "review",
+		})
+		s.Require().NoError(err)
+		s.Equal(&entity.TimeEntry{
+			ID:          1,
+			WorkspaceID: 2,
+			TodoID:      1,
+			UserID:      3,
+			Start:       time.Unix(100, 0),
+			End:         lo.ToPtr(time.Unix(200, 0)),
+			Note:        "review",
+			CreatedAt:   time.Unix(123456789, 0),
+			UpdatedAt:   time.Unix(123456789, 0),
+		}, got)
+
+		_, err = s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound, "other workspaces do not see the entry")
+	})
+}
+
+func (s *timeEntrySuite) TestStart() {
+	s.Run("stops the running timer", func() {
+		first, stopped := s.start(0, 1, 3, time.Unix(100, 0))
+		s.Nil(stopped)
+		s.True(first.Running())
+
+		second, stopped := s.start(0, 2, 3, time.Unix(400, 0))
+		s.True(second.Running())
+		s.Require().NotNil(stopped)
+		s.Equal(first.ID, stopped.ID)
+		s.Equal(time.Unix(400, 0), *stopped.End)
+
+		got, err := s.repo.Get(first.ID)
+		s.Require().NoError(err)
+		s.False(got.Running())
+	})
+	s.Run("stops the running timer of another workspace", func() {
+		first, _ := s.start(1, 1, 3, time.Unix(100, 0))
+
+		_, stopped := s.start(2, 1, 3, time.Unix(400, 0))
+		s.Require().NotNil(stopped)
+		s.Equal(1, stopped.WorkspaceID)
+		s.Equal(first.ID, stopped.ID)
+
+		running, err := s.repo.Running(3)
+		s.Require().NoError(err)
+		s.Equal(2, running.WorkspaceID)
+	})
+	s.Run("leaves the timers of other users running", func() {
+		s.start(0, 1, 3, time.Unix(100, 0))
+
+		_, stopped := s.start(0, 1, 4, time.Unix(400, 0))
+		s.Nil(stopped)
+		_, err := s.repo.Running(3)
+		s.NoError(err)
+	})
+}
+
+func (s *timeEntrySuite) TestStop() {
+	s.Run("success", func() {
+		started, _ := s.start(0, 1, 3, time.Unix(100, 0))
+
+		stopped, err := s.repo.ForWorkspace(2).Stop(3, time.Unix(300, 0))
+		s.Require().NoError(err)
+		s.Equal(started.ID, stopped.ID)
+		s.Equal(time.Unix(300, 0), *stopped.End)
+
+		_, err = s.repo.Running(3)
+		s.ErrorIs(err, entity.ErrNoRunningTimer)
+	})
+	s.Run("never ends before the start", func() {
+		s.start(0, 1, 3, time.Unix(100, 0))
+
+		stopped, err := s.repo.Stop(3, time.Unix(50, 0))
+		s.Require().NoError(err)
+		s.Equal(time.Unix(100, 0), *stopped.End)
+	})
+	s.Run("nothing running", func() {
+		_, err := s.repo.Stop(3, time.Unix(300, 0))
+		s.ErrorIs(err, entity.ErrNoRunningTimer)
+	})
+}
+
+func (s *timeEntrySuite) TestList() {
+	s.Run("ordered by start", func() {
+		_, err := s.repo.Create(entity.CreateTimeEntryInput{TodoID: 1, Start: time.Unix(300, 0), End: lo.ToPtr(time.Unix(400, 0))})
+		s.Require().NoError(err)
+		_, err = s.repo.Create(entity.CreateTimeEntryInput{TodoID: 2, Start: time.Unix(200, 0), End: lo.ToPtr(time.Unix(250, 0))})
+		s.Require().NoError(err)
+		_, err = s.repo.Create(entity.CreateTimeEntryInput{TodoID: 1, Start: time.Unix(100, 0), End: lo.ToPtr(time.Unix(200, 0))})
+		s.Require().NoError(err)
+
+		got, err := s.repo.List(1)
+		s.Require().NoError(err)
+		s.Equal([]int{3, 1}, lo.Map(got, func(entry entity.TimeEntry, _ int) int { return entry.ID }))
+
+		all, err := s.repo.ListAll()
+		s.Require().NoError(err)
+		s.Equal([]int{3, 2, 1}, lo.Map(all, func(entry entity.TimeEntry, _ int) int { return entry.ID }))
+	})
+}
+
+func (s *timeEntrySuite) TestUpdate() {
+	s.Run("success", func() {
+		started, _ := s.start(0, 1, 3, time.Unix(100, 0))
+
+		got, err := s.repo.Update(started.ID, entity.UpdateTimeEntryInput{
+			End:  lo.ToPtr(time.Unix(200, 0)),
+			Note: lo.ToPtr("done"),
+		})
+		s.Require().NoError(err)
+		s.Equal(time.Unix(100, 0), got.Start)
+		s.Equal(time.Unix(200, 0), *got.End)
+		s.Equal("done", got.Note)
+
+		_, err = s.repo.Running(3)
+		s.ErrorIs(err, entity.ErrNoRunningTimer, "setting the end stops the timer")
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Update(1, entity.UpdateTimeEntryInput{Note: lo.ToPtr("done")})
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *timeEntrySuite) TestDelete() {
+	s.Run("success", func() {
+		started, _ := s.start(0, 1, 3, time.Unix(100, 0))
+
+		s.Require().NoError(s.repo.Delete(started.ID))
+		_, err := s.repo.Get(started.ID)
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = s.repo.Running(3)
+		s.ErrorIs(err, entity.ErrNoRunningTimer)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.Delete(1), repo.ErrNotFound)
+	})
+}
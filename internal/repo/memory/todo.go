@@ -1,85 +1,566 @@
 package memory
 
 import (
+	"context"
 	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/rank"
 	"github.com/cloudingcity/todo/internal/repo"
 )
 
 var timeNow = time.Now
 
+// todoRepo holds the todos of one workspace. Every workspace gets its own
+// todoRepo from workspaces, so IDs are numbered per workspace.
 type todoRepo struct {
+	workspaceID int
+	workspaces  *partitions[*todoRepo]
+
+	mu        sync.RWMutex
 	idCounter int
 	store     []entity.Todo
+
+	tagCounter int
+	tags       []entity.Tag
+	// tagged is the inverted index from tag name to the IDs of the todos
+	// carrying it.
+	tagged map[string]map[int]struct{}
+
+	deps []entity.Dependency
 }
 
+// NewTodoRepo returns the repo of workspace zero. ForWorkspace reaches the
+// other workspaces.
 func NewTodoRepo() repo.Todo {
-	return &todoRepo{
-		idCounter: 1,
-	}
+	var workspaces *partitions[*todoRepo]
+	workspaces = newPartitions(func(workspaceID int) *todoRepo {
+		return &todoRepo{
+			workspaceID: workspaceID,
+			workspaces:  workspaces,
+			idCounter:   1,
+			tagCounter:  1,
+			tagged:      make(map[string]map[int]struct{}),
+		}
+	})
+	return workspaces.get(0)
 }
 
-func (r *todoRepo) Create(title, description string) (*entity.Todo, error) {
+func (r *todoRepo) ForWorkspace(workspaceID int) repo.Todo {
+	return r.workspaces.get(workspaceID)
+}
+
+func (r *todoRepo) Create(input entity.CreateTodoInput) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := entity.ValidateSchedule(input.Start, input.Due); err != nil {
+		return nil, err
+	}
+	if err := entity.ValidateRecurrence(input.Recurrence, input.Start, input.Due); err != nil {
+		return nil, err
+	}
+	if err := entity.ValidateEstimate(input.Estimate); err != nil {
+		return nil, err
+	}
+	if err := validateParent(r.store, 0, input.ParentID); err != nil {
+		return nil, err
+	}
+
 	now := timeNow()
-	todo := entity.Todo{
+	todo := r.insert(entity.Todo{
 		ID:          r.idCounter,
-		Title:       title,
-		Description: description,
+		WorkspaceID: r.workspaceID,
+		OwnerID:     input.OwnerID,
+		Title:       input.Title,
+		Description: input.Description,
 		IsCompleted: false,
+		Priority:    input.Priority,
+		ProjectID:   input.ProjectID,
+		ParentID:    input.ParentID,
+		Rollup:      input.Rollup,
+		Checklist:   input.Checklist,
+		Estimate:    input.Estimate,
+		Start:       input.Start,
+		Due:         input.Due,
+		Recurrence:  input.Recurrence,
 		CreatedAt:   now,
 		UpdatedAt:   now,
-	}
-	r.store = append(r.store, todo)
+	})
 	r.idCounter++
+	rollup(r.store, len(r.store)-1, entity.Todo{}, now)
+	r.refreshBlocked(r.store)
 	return &todo, nil
 }
 
 func (r *todoRepo) List() ([]entity.Todo, error) {
-	return r.store, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.store), nil
+}
+
+func (r *todoRepo) CountOwned(ownerID int) (int, error) {
+	var n int
+	for _, workspace := range r.workspaces.all() {
+		workspace.mu.RLock()
+		for _, todo := range workspace.store {
+			if todo.OwnerID == ownerID {
+				n++
+			}
+		}
+		workspace.mu.RUnlock()
+	}
+	return n, nil
 }
 
 func (r *todoRepo) Get(id int) (*entity.Todo, error) {
-	idx := slices.IndexFunc(r.store, func(todo entity.Todo) bool {
-		return todo.ID == id
-	})
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
 	if idx == -1 {
 		return nil, repo.ErrNotFound
 	}
 
-	return &r.store[idx], nil
+	todo := r.store[idx]
+	return &todo, nil
 }
 
 func (r *todoRepo) Update(id int, input entity.UpdateTodoInput) error {
-	idx := slices.IndexFunc(r.store, func(todo entity.Todo) bool {
-		return todo.ID == id
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	todo := r.store[idx]
+	input.Apply(&todo)
+	return r.save(idx, todo)
+}
+
+// UpdateFunc hands fn a copy of the todo under the write lock and stores the
+// result only when fn succeeds, so read-modify-write updates are atomic.
+func (r *todoRepo) UpdateFunc(id int, fn func(todo *entity.Todo) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return repo.ErrNotFound
+	}
+
+	todo := r.store[idx]
+	if err := fn(&todo); err != nil {
+		return err
+	}
+	todo.ID = r.store[idx].ID
+	todo.ParentID = r.store[idx].ParentID
+	todo.Rank = r.store[idx].Rank
+	todo.Tags = r.store[idx].Tags
+	todo.CreatedAt = r.store[idx].CreatedAt
+	return r.save(idx, todo)
+}
+
+func (r *todoRepo) Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	if err := r.save(idx, replaced(r.store[idx], input)); err != nil {
+		return nil, err
+	}
+	todo := r.store[idx]
+	return &todo, nil
+}
+
+func (r *todoRepo) Upsert(id int, input entity.ReplaceTodoInput) (*entity.Todo, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if idx := r.indexOf(id); idx != -1 {
+		if err := r.save(idx, replaced(r.store[idx], input)); err != nil {
+			return nil, false, err
+		}
+		todo := r.store[idx]
+		return &todo, false, nil
+	}
+
+	if id > entity.MaxTodoID {
+		return nil, false, entity.ErrTodoIDTooLarge
+	}
+	if err := entity.ValidateSchedule(input.Start, input.Due); err != nil {
+		return nil, false, err
+	}
+	if err := entity.ValidateRecurrence(input.Recurrence, input.Start, input.Due); err != nil {
+		return nil, false, err
+	}
+	if err := entity.ValidateEstimate(input.Estimate); err != nil {
+		return nil, false, err
+	}
+
+	now := timeNow()
+	todo := r.insert(entity.Todo{
+		ID:          id,
+		WorkspaceID: r.workspaceID,
+		OwnerID:     input.OwnerID,
+		Title:       input.Title,
+		Description: input.Description,
+		IsCompleted: input.IsCompleted,
+		Priority:    input.Priority,
+		Rollup:      input.Rollup,
+		Estimate:    input.Estimate,
+		Start:       input.Start,
+		Due:         input.Due,
+		Recurrence:  input.Recurrence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	})
+	// Keep generated IDs clear of client chosen ones.
+	if id >= r.idCounter {
+		r.idCounter = id + 1
+	}
+	return &todo, true, nil
+}
+
+func (r *todoRepo) Delete(id int, mode entity.SubtaskDeleteMode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
 	if idx == -1 {
 		return repo.ErrNotFound
 	}
-	if input.Title != nil {
-		r.store[idx].Title = *input.Title
+	deleted := r.store[idx]
+
+	now := timeNow()
+	remove := map[int]bool{id: true}
+	for _, i := range subtree(r.store, id)[1:] {
+		switch {
+		case mode == entity.SubtaskDeleteBlock:
+			return entity.ErrHasSubtasks
+		case mode == entity.SubtaskDeleteCascade:
+			remove[r.store[i].ID] = true
+		case r.store[i].ParentID == id:
+			r.store[i].ParentID = deleted.ParentID
+			r.store[i].UpdatedAt = now
+		}
 	}
-	if input.Description != nil {
-		r.store[idx].Description = *input.Description
+
+	r.remove(remove)
+	rollupFrom(r.store, deleted.ParentID, now)
+	r.refreshBlocked(r.store)
+	return nil
+}
+
+func (r *todoRepo) BatchCreate(inputs []entity.CreateTodoInput) ([]entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, input := range inputs {
+		if err := entity.ValidateSchedule(input.Start, input.Due); err != nil {
+			return nil, &repo.BatchError{Index: i, Err: err}
+		}
+		if err := entity.ValidateRecurrence(input.Recurrence, input.Start, input.Due); err != nil {
+			return nil, &repo.BatchError{Index: i, Err: err}
+		}
+		if err := entity.ValidateEstimate(input.Estimate); err != nil {
+			return nil, &repo.BatchError{Index: i, Err: err}
+		}
+		if err := validateParent(r.store, 0, input.ParentID); err != nil {
+			return nil, &repo.BatchError{Index: i, Err: err}
+		}
 	}
-	if input.IsCompleted != nil {
-		r.store[idx].IsCompleted = *input.IsCompleted
+
+	now := timeNow()
+	todos := make([]entity.Todo, len(inputs))
+	for i, input := range inputs {
+		todos[i] = r.insert(entity.Todo{
+			ID:          r.idCounter,
+			WorkspaceID: r.workspaceID,
+			OwnerID:     input.OwnerID,
+			Title:       input.Title,
+			Description: input.Description,
+			IsCompleted: false,
+			Priority:    input.Priority,
+			ProjectID:   input.ProjectID,
+			ParentID:    input.ParentID,
+			Rollup:      input.Rollup,
+			Checklist:   input.Checklist,
+			Estimate:    input.Estimate,
+			Start:       input.Start,
+			Due:         input.Due,
+			Recurrence:  input.Recurrence,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		r.idCounter++
+		rollup(r.store, len(r.store)-1, entity.Todo{}, now)
+	}
+	r.refreshBlocked(r.store)
+	// Rollup may have reopened a parent that is also in the batch.
+	for i := range todos {
+		todos[i] = r.store[r.indexOf(todos[i].ID)]
+	}
+	return todos, nil
+}
+
+func (r *todoRepo) BatchUpdate(inputs []entity.BatchUpdateTodoInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Apply to a scratch copy first so a failing item leaves the store
+	// untouched.
+	scratch := slices.Clone(r.store)
+	idxs := make([]int, len(inputs))
+	for i, input := range inputs {
+		idxs[i] = r.indexOf(input.ID)
+		if idxs[i] == -1 {
+			return &repo.BatchError{Index: i, Err: repo.ErrNotFound}
+		}
+		todo := &scratch[idxs[i]]
+		input.Input.Apply(todo)
+		if err := entity.ValidateSchedule(todo.Start, todo.Due); err != nil {
+			return &repo.BatchError{Index: i, Err: err}
+		}
+		if err := entity.ValidateRecurrence(todo.Recurrence, todo.Start, todo.Due); err != nil {
+			return &repo.BatchError{Index: i, Err: err}
+		}
+		if err := entity.ValidateEstimate(todo.Estimate); err != nil {
+			return &repo.BatchError{Index: i, Err: err}
+		}
+		if err := checkBlocked(r.store[idxs[i]], *todo); err != nil {
+			return &repo.BatchError{Index: i, Err: err}
+		}
+		// Later items may complete todos this one was blocking.
+		r.refreshBlocked(scratch)
+	}
+
+	now := timeNow()
+	for _, idx := range idxs {
+		scratch[idx].UpdatedAt = now
+		rollup(scratch, idx, r.store[idx], now)
+	}
+	r.refreshBlocked(scratch)
+	r.store = scratch
+	return nil
+}
+
+func (r *todoRepo) BatchDelete(ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remove := make(map[int]bool, len(ids))
+	for i, id := range ids {
+		if r.indexOf(id) == -1 || remove[id] {
+			return &repo.BatchError{Index: i, Err: repo.ErrNotFound}
+		}
+		remove[id] = true
+	}
+
+	var parents []int
+	for _, id := range ids {
+		for _, idx := range subtree(r.store, id) {
+			remove[r.store[idx].ID] = true
+		}
+		parents = append(parents, r.store[r.indexOf(id)].ParentID)
+	}
+
+	r.remove(remove)
+	now := timeNow()
+	for _, parentID := range parents {
+		rollupFrom(r.store, parentID, now)
+	}
+	r.refreshBlocked(r.store)
+	return nil
+}
+
+// Move gives the todo a rank key between the target and its neighbour, so
+// no other todo changes unless the keys have to be spread out again.
+func (r *todoRepo) Move(id int, input entity.MoveTodoInput) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	targetID := input.After
+	if input.Before != nil {
+		targetID = input.Before
+	}
+	if *targetID == id {
+		return nil, entity.ErrMoveSelf
+	}
+	target := r.indexOf(*targetID)
+	if target == -1 {
+		return nil, entity.ErrMoveTarget
+	}
+
+	order := slices.DeleteFunc(r.rankOrder(), func(i int) bool { return i == idx })
+	pos := slices.Index(order, target)
+	if input.After != nil {
+		pos++
+	}
+	var lower, upper string
+	if pos > 0 {
+		lower = r.store[order[pos-1]].Rank
+	}
+	if pos < len(order) {
+		upper = r.store[order[pos]].Rank
+	}
+
+	key, err := rank.Between(lower, upper)
+	if err != nil || len(key) > rank.MaxLength {
+		r.spread(slices.Insert(order, pos, idx))
+	} else {
+		r.store[idx].Rank = key
 	}
 	r.store[idx].UpdatedAt = timeNow()
+
+	todo := r.store[idx]
+	return &todo, nil
+}
+
+// Check reports the repo as healthy once the lock of every workspace can be
+// acquired, which surfaces a wedged writer to the readiness probe instead of
+// hanging it.
+func (r *todoRepo) Check(ctx context.Context) error {
+	for _, workspace := range r.workspaces.all() {
+		if err := workspace.checkLock(ctx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r *todoRepo) Delete(id int) error {
-	idx := slices.IndexFunc(r.store, func(todo entity.Todo) bool {
+func (r *todoRepo) checkLock(ctx context.Context) error {
+	acquired := make(chan struct{})
+	go func() {
+		r.mu.RLock()
+		close(acquired)
+		r.mu.RUnlock()
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *todoRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(todo entity.Todo) bool {
 		return todo.ID == id
 	})
-	if idx == -1 {
-		return repo.ErrNotFound
+}
+
+// remove deletes the todos whose IDs are set in ids.
+func (r *todoRepo) remove(ids map[int]bool) {
+	r.store = slices.DeleteFunc(r.store, func(todo entity.Todo) bool {
+		if ids[todo.ID] {
+			r.untagTodo(todo)
+		}
+		return ids[todo.ID]
+	})
+	r.deps = slices.DeleteFunc(r.deps, func(dep entity.Dependency) bool {
+		return ids[dep.TodoID] || ids[dep.BlockerID]
+	})
+}
+
+// insert appends todo at the end of the manual order.
+func (r *todoRepo) insert(todo entity.Todo) entity.Todo {
+	if todo.Priority == "" {
+		todo.Priority = entity.PriorityNone
+	}
+	if todo.Rollup == "" {
+		todo.Rollup = entity.RollupNone
+	}
+	var last string
+	for _, stored := range r.store {
+		last = max(last, stored.Rank)
+	}
+
+	key, err := rank.Between(last, "")
+	todo.Rank = key
+	r.store = append(r.store, todo)
+	if err != nil || len(key) > rank.MaxLength {
+		r.spread(r.rankOrder())
 	}
+	return r.store[len(r.store)-1]
+}
 
-	r.store = slices.Delete(r.store, idx, idx+1)
+// rankOrder returns the store indexes in manual order.
+func (r *todoRepo) rankOrder() []int {
+	order := make([]int, len(r.store))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(r.store[a].Rank, r.store[b].Rank)
+	})
+	return order
+}
+
+// spread rebalances the rank keys of the todos at the given indexes, which
+// must cover the whole store, so that keys are short again.
+func (r *todoRepo) spread(order []int) {
+	for i, key := range rank.Spread(len(order)) {
+		r.store[order[i]].Rank = key
+	}
+}
 
+// save validates todo and stores it at idx with a fresh UpdatedAt, then
+// applies the rollup rules of its tree and refreshes the blocked flags.
+func (r *todoRepo) save(idx int, todo entity.Todo) error {
+	if err := entity.ValidateSchedule(todo.Start, todo.Due); err != nil {
+		return err
+	}
+	if err := entity.ValidateRecurrence(todo.Recurrence, todo.Start, todo.Due); err != nil {
+		return err
+	}
+	if err := entity.ValidateEstimate(todo.Estimate); err != nil {
+		return err
+	}
+	if err := checkBlocked(r.store[idx], todo); err != nil {
+		return err
+	}
+	if todo.Priority == "" {
+		todo.Priority = entity.PriorityNone
+	}
+	if todo.Rollup == "" {
+		todo.Rollup = entity.RollupNone
+	}
+	todo.UpdatedAt = timeNow()
+	prev := r.store[idx]
+	r.store[idx] = todo
+	rollup(r.store, idx, prev, todo.UpdatedAt)
+	r.refreshBlocked(r.store)
 	return nil
 }
+
+func replaced(todo entity.Todo, input entity.ReplaceTodoInput) entity.Todo {
+	todo.Title = input.Title
+	todo.Description = input.Description
+	todo.IsCompleted = input.IsCompleted
+	todo.Priority = input.Priority
+	todo.Rollup = input.Rollup
+	todo.Estimate = input.Estimate
+	todo.Start = input.Start
+	todo.Due = input.Due
+	todo.Recurrence = input.Recurrence
+	if input.Force {
+		todo.Blocked = false
+	}
+	return todo
+}
@@ -1,10 +1,16 @@
 package memory
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/rank"
 	"github.com/cloudingcity/todo/internal/repo"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/suite"
@@ -49,6 +55,9 @@ func (s *todoSuite) TestCreate() {
 				Title:       "title-1",
 				Description: "desc-1",
 				IsCompleted: false,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
 				CreatedAt:   time.Unix(123456789, 0),
 				UpdatedAt:   time.Unix(123456789, 0),
 			},
@@ -58,13 +67,28 @@ func (s *todoSuite) TestCreate() {
 	for _, tt := range tests {
 		s.Run(tt.desc, func() {
 			tt.setup()
-			got, err := s.repo.Create(tt.title, tt.description)
+			got, err := s.repo.Create(entity.CreateTodoInput{Title: tt.title, Description: tt.description})
 			s.Equal(tt.want, got)
 			s.ErrorIs(tt.wantErr, err)
 		})
 	}
 }
 
+func (s *todoSuite) TestCountOwned() {
+	s.Run("every workspace", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", OwnerID: 1})
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2", OwnerID: 2})
+		_, _ = s.repo.ForWorkspace(2).BatchCreate([]entity.CreateTodoInput{{Title: "title-3", OwnerID: 1}, {Title: "title-4", OwnerID: 1}})
+
+		got, err := s.repo.CountOwned(1)
+		s.Require().NoError(err)
+		s.Equal(3, got)
+		got, err = s.repo.ForWorkspace(3).CountOwned(2)
+		s.Require().NoError(err)
+		s.Equal(1, got)
+	})
+}
+
 func (s *todoSuite) TestList() {
 	tests := []struct {
 		desc    string
@@ -78,9 +102,9 @@ func (s *todoSuite) TestList() {
 				timeNow = func() time.Time {
 					return time.Unix(123456789, 0)
 				}
-				_, _ = s.repo.Create("title-1", "desc-1")
-				_, _ = s.repo.Create("title-2", "desc-2")
-				_, _ = s.repo.Create("title-3", "desc-3")
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2", Description: "desc-2"})
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-3", Description: "desc-3"})
 			},
 			want: []entity.Todo{
 				{
@@ -88,6 +112,9 @@ func (s *todoSuite) TestList() {
 					Title:       "title-1",
 					Description: "desc-1",
 					IsCompleted: false,
+					Priority:    entity.PriorityNone,
+					Rollup:      entity.RollupNone,
+					Rank:        "i",
 					CreatedAt:   time.Unix(123456789, 0),
 					UpdatedAt:   time.Unix(123456789, 0),
 				},
@@ -96,6 +123,9 @@ func (s *todoSuite) TestList() {
 					Title:       "title-2",
 					Description: "desc-2",
 					IsCompleted: false,
+					Priority:    entity.PriorityNone,
+					Rollup:      entity.RollupNone,
+					Rank:        "r",
 					CreatedAt:   time.Unix(123456789, 0),
 					UpdatedAt:   time.Unix(123456789, 0),
 				},
@@ -104,6 +134,9 @@ func (s *todoSuite) TestList() {
 					Title:       "title-3",
 					Description: "desc-3",
 					IsCompleted: false,
+					Priority:    entity.PriorityNone,
+					Rollup:      entity.RollupNone,
+					Rank:        "w",
 					CreatedAt:   time.Unix(123456789, 0),
 					UpdatedAt:   time.Unix(123456789, 0),
 				},
@@ -136,13 +169,16 @@ func (s *todoSuite) TestGet() {
 				timeNow = func() time.Time {
 					return time.Unix(123456789, 0)
 				}
-				_, _ = s.repo.Create("title-1", "desc-1")
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
 			},
 			want: &entity.Todo{
 				ID:          1,
 				Title:       "title-1",
 				Description: "desc-1",
 				IsCompleted: false,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
 				CreatedAt:   time.Unix(123456789, 0),
 				UpdatedAt:   time.Unix(123456789, 0),
 			},
@@ -187,13 +223,16 @@ func (s *todoSuite) TestUpdate() {
 				timeNow = func() time.Time {
 					return time.Unix(123456789, 0)
 				}
-				_, _ = s.repo.Create("title-1", "desc-1")
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
 			},
 			want: &entity.Todo{
 				ID:          1,
 				Title:       "title-update",
 				Description: "desc-update",
 				IsCompleted: true,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
 				CreatedAt:   time.Unix(123456789, 0),
 				UpdatedAt:   time.Unix(123456789, 0),
 			},
@@ -238,7 +277,7 @@ func (s *todoSuite) TestDelete() {
 				timeNow = func() time.Time {
 					return time.Unix(123456789, 0)
 				}
-				_, _ = s.repo.Create("title-1", "desc-1")
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
 			},
 			wantErr: nil,
 		},
@@ -252,8 +291,567 @@ func (s *todoSuite) TestDelete() {
 	for _, tt := range tests {
 		s.Run(tt.desc, func() {
 			tt.setup()
-			err := s.repo.Delete(tt.id)
+			err := s.repo.Delete(tt.id, entity.SubtaskDeleteCascade)
 			s.ErrorIs(tt.wantErr, err)
 		})
 	}
 }
+
+func (s *todoSuite) TestCheck() {
+	s.Run("success", func() {
+		checker, ok := s.repo.(interface {
+			Check(ctx context.Context) error
+		})
+		s.Require().True(ok)
+		s.NoError(checker.Check(context.Background()))
+	})
+}
+
+func (s *todoSuite) TestUpdateFunc() {
+	tests := []struct {
+		desc    string
+		id      int
+		fn      func(todo *entity.Todo) error
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success",
+			id:   1,
+			fn: func(todo *entity.Todo) error {
+				todo.ID = 99
+				todo.Title = "title-update"
+				todo.Description = ""
+				todo.CreatedAt = time.Unix(0, 0)
+				return nil
+			},
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
+				timeNow = func() time.Time {
+					return time.Unix(987654321, 0)
+				}
+			},
+			want: &entity.Todo{
+				ID:          1,
+				Title:       "title-update",
+				Description: "",
+				IsCompleted: false,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
+				CreatedAt:   time.Unix(123456789, 0),
+				UpdatedAt:   time.Unix(987654321, 0),
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "fn failed leaves todo untouched",
+			id:   1,
+			fn: func(todo *entity.Todo) error {
+				todo.Title = "title-update"
+				return errors.New("something wrong")
+			},
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
+			},
+			want: &entity.Todo{
+				ID:          1,
+				Title:       "title-1",
+				Description: "desc-1",
+				IsCompleted: false,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
+				CreatedAt:   time.Unix(123456789, 0),
+				UpdatedAt:   time.Unix(123456789, 0),
+			},
+		},
+		{
+			desc:    "not found",
+			id:      1,
+			fn:      func(todo *entity.Todo) error { return nil },
+			setup:   func() {},
+			want:    nil,
+			wantErr: repo.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			err := s.repo.UpdateFunc(tt.id, tt.fn)
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+			}
+
+			got, _ := s.repo.Get(tt.id)
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *todoSuite) TestReplace() {
+	tests := []struct {
+		desc    string
+		id      int
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success keeps created at",
+			id:   1,
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
+				timeNow = func() time.Time {
+					return time.Unix(987654321, 0)
+				}
+			},
+			want: &entity.Todo{
+				ID:          1,
+				Title:       "title-update",
+				Description: "",
+				IsCompleted: true,
+				Priority:    entity.PriorityNone,
+				Rollup:      entity.RollupNone,
+				Rank:        "i",
+				CreatedAt:   time.Unix(123456789, 0),
+				UpdatedAt:   time.Unix(987654321, 0),
+			},
+			wantErr: nil,
+		},
+		{
+			desc:    "not found",
+			id:      1,
+			setup:   func() {},
+			want:    nil,
+			wantErr: repo.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.repo.Replace(tt.id, entity.ReplaceTodoInput{
+				Title:       "title-update",
+				IsCompleted: true,
+			})
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestUpsert() {
+	tests := []struct {
+		desc        string
+		id          int
+		setup       func()
+		want        *entity.Todo
+		wantCreated bool
+		wantNextID  int
+	}{
+		{
+			desc: "create at client chosen id",
+			id:   5,
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+			},
+			want: &entity.Todo{
+				ID:        5,
+				Title:     "title-update",
+				Priority:  entity.PriorityNone,
+				Rollup:    entity.RollupNone,
+				Rank:      "i",
+				CreatedAt: time.Unix(123456789, 0),
+				UpdatedAt: time.Unix(123456789, 0),
+			},
+			wantCreated: true,
+			wantNextID:  6,
+		},
+		{
+			desc: "replace existing",
+			id:   1,
+			setup: func() {
+				timeNow = func() time.Time {
+					return time.Unix(123456789, 0)
+				}
+				_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"})
+				timeNow = func() time.Time {
+					return time.Unix(987654321, 0)
+				}
+			},
+			want: &entity.Todo{
+				ID:        1,
+				Title:     "title-update",
+				Priority:  entity.PriorityNone,
+				Rollup:    entity.RollupNone,
+				Rank:      "i",
+				CreatedAt: time.Unix(123456789, 0),
+				UpdatedAt: time.Unix(987654321, 0),
+			},
+			wantCreated: false,
+			wantNextID:  2,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, created, err := s.repo.Upsert(tt.id, entity.ReplaceTodoInput{Title: "title-update"})
+			s.NoError(err)
+			s.Equal(tt.want, got)
+			s.Equal(tt.wantCreated, created)
+
+			next, _ := s.repo.Create(entity.CreateTodoInput{Title: "next"})
+			s.Equal(tt.wantNextID, next.ID)
+		})
+	}
+	s.Run("id too large", func() {
+		_, _, err := s.repo.Upsert(entity.MaxTodoID+1, entity.ReplaceTodoInput{Title: "title-update"})
+		s.ErrorIs(err, entity.ErrTodoIDTooLarge)
+
+		next, _ := s.repo.Create(entity.CreateTodoInput{Title: "next"})
+		s.Equal(1, next.ID)
+	})
+}
+
+func (s *todoSuite) TestBatchCreate() {
+	s.Run("success", func() {
+		timeNow = func() time.Time {
+			return time.Unix(123456789, 0)
+		}
+		got, err := s.repo.BatchCreate([]entity.CreateTodoInput{
+			{Title: "title-1", Description: "desc-1"},
+			{Title: "title-2"},
+		})
+		s.NoError(err)
+		s.Equal([]entity.Todo{
+			{ID: 1, Title: "title-1", Description: "desc-1", Priority: entity.PriorityNone, Rollup: entity.RollupNone, Rank: "i", CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+			{ID: 2, Title: "title-2", Priority: entity.PriorityNone, Rollup: entity.RollupNone, Rank: "r", CreatedAt: time.Unix(123456789, 0), UpdatedAt: time.Unix(123456789, 0)},
+		}, got)
+
+		list, _ := s.repo.List()
+		s.Equal(got, list)
+	})
+}
+
+func (s *todoSuite) TestBatchUpdate() {
+	tests := []struct {
+		desc       string
+		inputs     []entity.BatchUpdateTodoInput
+		wantErr    error
+		wantTitles []string
+	}{
+		{
+			desc: "success",
+			inputs: []entity.BatchUpdateTodoInput{
+				{ID: 1, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title-1-update")}},
+				{ID: 2, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title-2-update")}},
+			},
+			wantTitles: []string{"title-1-update", "title-2-update"},
+		},
+		{
+			desc: "not found leaves every todo untouched",
+			inputs: []entity.BatchUpdateTodoInput{
+				{ID: 1, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title-1-update")}},
+				{ID: 3, Input: entity.UpdateTodoInput{Title: lo.ToPtr("title-3-update")}},
+			},
+			wantErr:    &repo.BatchError{Index: 1, Err: repo.ErrNotFound},
+			wantTitles: []string{"title-1", "title-2"},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2"})
+
+			err := s.repo.BatchUpdate(tt.inputs)
+			s.Equal(tt.wantErr, err)
+
+			list, _ := s.repo.List()
+			s.Equal(tt.wantTitles, lo.Map(list, func(todo entity.Todo, _ int) string { return todo.Title }))
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchDelete() {
+	tests := []struct {
+		desc    string
+		ids     []int
+		wantErr error
+		wantIDs []int
+	}{
+		{
+			desc:    "success",
+			ids:     []int{1, 3},
+			wantIDs: []int{2},
+		},
+		{
+			desc:    "not found leaves every todo in place",
+			ids:     []int{1, 4},
+			wantErr: &repo.BatchError{Index: 1, Err: repo.ErrNotFound},
+			wantIDs: []int{1, 2, 3},
+		},
+		{
+			desc:    "duplicate id",
+			ids:     []int{1, 1},
+			wantErr: &repo.BatchError{Index: 1, Err: repo.ErrNotFound},
+			wantIDs: []int{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2"})
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-3"})
+
+			err := s.repo.BatchDelete(tt.ids)
+			s.Equal(tt.wantErr, err)
+
+			list, _ := s.repo.List()
+			s.Equal(tt.wantIDs, lo.Map(list, func(todo entity.Todo, _ int) int { return todo.ID }))
+		})
+	}
+}
+
+func (s *todoSuite) TestSchedule() {
+	start := entity.NewDate(2024, 3, 10)
+	due := entity.NewDateTime(time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC), "")
+
+	s.Run("create rejects start after due", func() {
+		_, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", Start: &start, Due: &due})
+		s.ErrorIs(err, entity.ErrStartAfterDue)
+
+		todos, _ := s.repo.List()
+		s.Empty(todos)
+	})
+	s.Run("create keeps dates", func() {
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", Start: &due, Due: &start})
+		s.Require().NoError(err)
+		s.Equal(&due, got.Start)
+		s.Equal(&start, got.Due)
+	})
+	s.Run("update validates against stored dates", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due})
+
+		err := s.repo.Update(1, entity.UpdateTodoInput{Start: &start})
+		s.ErrorIs(err, entity.ErrStartAfterDue)
+
+		got, _ := s.repo.Get(1)
+		s.Nil(got.Start)
+	})
+	s.Run("update func can clear a date", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Start: &due, Due: &start})
+
+		err := s.repo.UpdateFunc(1, func(todo *entity.Todo) error {
+			todo.Due = nil
+			return nil
+		})
+		s.Require().NoError(err)
+
+		got, _ := s.repo.Get(1)
+		s.Nil(got.Due)
+		s.Equal(&due, got.Start)
+	})
+	s.Run("batch update rolls back on invalid item", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due})
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-2", Due: &due})
+
+		err := s.repo.BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 1, Input: entity.UpdateTodoInput{Title: lo.ToPtr("changed")}},
+			{ID: 2, Input: entity.UpdateTodoInput{Start: &start}},
+		})
+		var batchErr *repo.BatchError
+		s.Require().ErrorAs(err, &batchErr)
+		s.Equal(1, batchErr.Index)
+		s.ErrorIs(err, entity.ErrStartAfterDue)
+
+		got, _ := s.repo.Get(1)
+		s.Equal("title-1", got.Title)
+	})
+}
+
+func (s *todoSuite) TestRecurrence() {
+	due := entity.NewDate(2024, 3, 10)
+	rule, err := entity.ParseRRule("FREQ=WEEKLY;BYDAY=MO")
+	s.Require().NoError(err)
+
+	s.Run("create keeps rule", func() {
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due, Recurrence: rule})
+		s.Require().NoError(err)
+		s.Equal(rule, got.Recurrence)
+	})
+	s.Run("create needs a date", func() {
+		_, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", Recurrence: rule})
+		s.ErrorIs(err, entity.ErrRecurrenceAnchor)
+	})
+	s.Run("update clears rule", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due, Recurrence: rule})
+
+		s.Require().NoError(s.repo.Update(1, entity.UpdateTodoInput{Recurrence: &entity.Recurrence{}}))
+
+		got, _ := s.repo.Get(1)
+		s.Nil(got.Recurrence)
+	})
+	s.Run("clearing the date keeps rule anchored", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due, Recurrence: rule})
+
+		err := s.repo.UpdateFunc(1, func(todo *entity.Todo) error {
+			todo.Due = nil
+			return nil
+		})
+		s.ErrorIs(err, entity.ErrRecurrenceAnchor)
+
+		got, _ := s.repo.Get(1)
+		s.Equal(&due, got.Due)
+	})
+	s.Run("replace sets rule", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", Due: &due, Recurrence: rule})
+
+		got, err := s.repo.Replace(1, entity.ReplaceTodoInput{Title: "title-1", Start: &due})
+		s.Require().NoError(err)
+		s.Nil(got.Recurrence)
+
+		got, err = s.repo.Replace(1, entity.ReplaceTodoInput{Title: "title-1", Start: &due, Recurrence: rule})
+		s.Require().NoError(err)
+		s.Equal(rule, got.Recurrence)
+	})
+}
+
+func (s *todoSuite) TestMove() {
+	order := func() []int {
+		todos, _ := s.repo.List()
+		slices.SortFunc(todos, func(a, b entity.Todo) int { return strings.Compare(a.Rank, b.Rank) })
+		return lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID })
+	}
+	setup := func() {
+		timeNow = func() time.Time {
+			return time.Unix(123456789, 0)
+		}
+		for i := range 4 {
+			_, _ = s.repo.Create(entity.CreateTodoInput{Title: fmt.Sprintf("title-%d", i+1)})
+		}
+	}
+
+	tests := []struct {
+		desc      string
+		id        int
+		input     entity.MoveTodoInput
+		wantOrder []int
+		wantErr   error
+	}{
+		{desc: "before first", id: 3, input: entity.MoveTodoInput{Before: lo.ToPtr(1)}, wantOrder: []int{3, 1, 2, 4}},
+		{desc: "before middle", id: 4, input: entity.MoveTodoInput{Before: lo.ToPtr(2)}, wantOrder: []int{1, 4, 2, 3}},
+		{desc: "after middle", id: 1, input: entity.MoveTodoInput{After: lo.ToPtr(3)}, wantOrder: []int{2, 3, 1, 4}},
+		{desc: "after last", id: 2, input: entity.MoveTodoInput{After: lo.ToPtr(4)}, wantOrder: []int{1, 3, 4, 2}},
+		{desc: "to the same place", id: 2, input: entity.MoveTodoInput{After: lo.ToPtr(1)}, wantOrder: []int{1, 2, 3, 4}},
+		{desc: "not found", id: 9, input: entity.MoveTodoInput{After: lo.ToPtr(1)}, wantOrder: []int{1, 2, 3, 4}, wantErr: repo.ErrNotFound},
+		{desc: "target not found", id: 1, input: entity.MoveTodoInput{After: lo.ToPtr(9)}, wantOrder: []int{1, 2, 3, 4}, wantErr: entity.ErrMoveTarget},
+		{desc: "relative to itself", id: 1, input: entity.MoveTodoInput{Before: lo.ToPtr(1)}, wantOrder: []int{1, 2, 3, 4}, wantErr: entity.ErrMoveSelf},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			setup()
+			before, _ := s.repo.List()
+
+			got, err := s.repo.Move(tt.id, tt.input)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(tt.wantOrder, order())
+			if err != nil {
+				return
+			}
+			s.Equal(tt.id, got.ID)
+
+			// Only the moved todo gets a new key.
+			after, _ := s.repo.List()
+			for i := range before {
+				if before[i].ID != tt.id {
+					s.Equal(before[i].Rank, after[i].Rank)
+				}
+			}
+		})
+	}
+
+	s.Run("rebalances long keys", func() {
+		setup()
+		// Keep moving the last todo into the gap right after the first one,
+		// which halves that gap every time.
+		last := 4
+		for range 3 * rank.MaxLength {
+			_, err := s.repo.Move(last, entity.MoveTodoInput{After: lo.ToPtr(1)})
+			s.Require().NoError(err)
+			ids := order()
+			last = ids[len(ids)-1]
+		}
+
+		todos, _ := s.repo.List()
+		for _, todo := range todos {
+			s.LessOrEqual(len(todo.Rank), rank.MaxLength)
+		}
+		s.Len(lo.UniqBy(todos, func(todo entity.Todo) string { return todo.Rank }), 4)
+	})
+}
+
+func (s *todoSuite) TestPriority() {
+	s.Run("defaults to none", func() {
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1"})
+		s.Require().NoError(err)
+		s.Equal(entity.PriorityNone, got.Priority)
+	})
+	s.Run("update keeps the rank", func() {
+		created, _ := s.repo.Create(entity.CreateTodoInput{Title: "title-1", Priority: entity.PriorityHigh})
+
+		err := s.repo.UpdateFunc(created.ID, func(todo *entity.Todo) error {
+			todo.Priority = entity.PriorityUrgent
+			todo.Rank = "0"
+			return nil
+		})
+		s.Require().NoError(err)
+
+		got, _ := s.repo.Get(created.ID)
+		s.Equal(entity.PriorityUrgent, got.Priority)
+		s.Equal(created.Rank, got.Rank)
+	})
+}
+
+func (s *todoSuite) TestProject() {
+	s.Run("create in a project", func() {
+		got, err := s.repo.Create(entity.CreateTodoInput{Title: "title-1", ProjectID: 2})
+		s.Require().NoError(err)
+		s.Equal(2, got.ProjectID)
+	})
+	s.Run("batch update moves to the inbox", func() {
+		_, _ = s.repo.BatchCreate([]entity.CreateTodoInput{
+			{Title: "title-1", ProjectID: 2},
+			{Title: "title-2", ProjectID: 2},
+		})
+
+		err := s.repo.BatchUpdate([]entity.BatchUpdateTodoInput{
+			{ID: 1, Input: entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}},
+			{ID: 2, Input: entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}},
+		})
+		s.Require().NoError(err)
+
+		todos, _ := s.repo.List()
+		for _, todo := range todos {
+			s.Equal(entity.Inbox, todo.ProjectID)
+		}
+	})
+	s.Run("replace keeps the project", func() {
+		_, _ = s.repo.Create(entity.CreateTodoInput{Title: "title-1", ProjectID: 2})
+
+		got, err := s.repo.Replace(1, entity.ReplaceTodoInput{Title: "title-2", Priority: entity.PriorityNone})
+		s.Require().NoError(err)
+		s.Equal(2, got.ProjectID)
+	})
+}
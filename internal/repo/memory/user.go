@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+type userRepo struct {
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.User
+	// identities maps issuer and subject to the ID of the linked user.
+	identities map[[2]string]int
+}
+
+func NewUserRepo() repo.User {
+	return &userRepo{
+		idCounter:  1,
+		identities: make(map[[2]string]int),
+	}
+}
+
+func (r *userRepo) Create(input entity.CreateUserInput) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOfEmail(input.Email) != -1 {
+		return nil, entity.ErrEmailTaken
+	}
+
+	now := timeNow()
+	user := entity.User{
+		ID:           r.idCounter,
+		Email:        input.Email,
+		Name:         input.Name,
+		PasswordHash: input.PasswordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	r.store = append(r.store, user)
+	r.idCounter++
+	return &user, nil
+}
+
+func (r *userRepo) Get(id int) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := slices.IndexFunc(r.store, func(user entity.User) bool {
+		return user.ID == id
+	})
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	user := r.store[idx]
+	return &user, nil
+}
+
+func (r *userRepo) GetByEmail(email string) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOfEmail(email)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	user := r.store[idx]
+	return &user, nil
+}
+
+func (r *userRepo) indexOfEmail(email string) int {
+	return slices.IndexFunc(r.store, func(user entity.User) bool {
+		return user.Email == email
+	})
+}
+
+func (r *userRepo) LinkIdentity(userID int, issuer, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := [2]string{issuer, subject}
+	if _, ok := r.identities[key]; ok {
+		return entity.ErrIdentityLinked
+	}
+	r.identities[key] = userID
+	return nil
+}
+
+func (r *userRepo) GetByIdentity(issuer, subject string) (*entity.User, error) {
+	r.mu.RLock()
+	id, ok := r.identities[[2]string{issuer, subject}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, repo.ErrNotFound
+	}
+	return r.Get(id)
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/stretchr/testify/suite"
+)
+
+type userSuite struct {
+	suite.Suite
+	repo repo.User
+}
+
+func (s *userSuite) SetupSubTest() {
+	s.repo = NewUserRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *userSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestUserSuite(t *testing.T) {
+	suite.Run(t, new(userSuite))
+}
+
+func (s *userSuite) TestCreate() {
+	s.Run("success", func() {
+		got, err := s.repo.Create(entity.CreateUserInput{Email: "a@example.com", Name: "A", PasswordHash: "hash"})
+		s.Require().NoError(err)
+		s.Equal(&entity.User{
+			ID:           1,
+			Email:        "a@example.com",
+			Name:         "A",
+			PasswordHash: "hash",
+			CreatedAt:    time.Unix(123456789, 0),
+			UpdatedAt:    time.Unix(123456789, 0),
+		}, got)
+
+		second, err := s.repo.Create(entity.CreateUserInput{Email: "b@example.com"})
+		s.Require().NoError(err)
+		s.Equal(2, second.ID)
+	})
+	s.Run("email taken", func() {
+		_, _ = s.repo.Create(entity.CreateUserInput{Email: "a@example.com"})
+
+		_, err := s.repo.Create(entity.CreateUserInput{Email: "a@example.com"})
+		s.ErrorIs(err, entity.ErrEmailTaken)
+	})
+}
+
+func (s *userSuite) TestGet() {
+	s.Run("success", func() {
+		created, _ := s.repo.Create(entity.CreateUserInput{Email: "a@example.com"})
+
+		got, err := s.repo.Get(created.ID)
+		s.Require().NoError(err)
+		s.Equal(created, got)
+
+		got, err = s.repo.GetByEmail("a@example.com")
+		s.Require().NoError(err)
+		s.Equal(created, got)
+	})
+	s.Run("not found", func() {
+		_, err := s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+
+		_, err = s.repo.GetByEmail("a@example.com")
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
+
+func (s *userSuite) TestIdentity() {
+	s.Run("success", func() {
+		created, _ := s.repo.Create(entity.CreateUserInput{Email: "a@example.com"})
+		s.Require().NoError(s.repo.LinkIdentity(created.ID, "https://idp.example.com", "sub-1"))
+
+		got, err := s.repo.GetByIdentity("https://idp.example.com", "sub-1")
+		s.Require().NoError(err)
+		s.Equal(created, got)
+
+		_, err = s.repo.GetByIdentity("https://other.example.com", "sub-1")
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("linked already", func() {
+		a, _ := s.repo.Create(entity.CreateUserInput{Email: "a@example.com"})
+		b, _ := s.repo.Create(entity.CreateUserInput{Email: "b@example.com"})
+		s.Require().NoError(s.repo.LinkIdentity(a.ID, "https://idp.example.com", "sub-1"))
+
+		err := s.repo.LinkIdentity(b.ID, "https://idp.example.com", "sub-1")
+		s.ErrorIs(err, entity.ErrIdentityLinked)
+		s.NoError(s.repo.LinkIdentity(b.ID, "https://idp.example.com", "sub-2"))
+	})
+}
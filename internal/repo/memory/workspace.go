@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+type workspaceRepo struct {
+	mu        sync.RWMutex
+	idCounter int
+	store     []entity.Workspace
+	// members maps a workspace ID to its members in the order they joined.
+	members map[int][]entity.Membership
+}
+
+func NewWorkspaceRepo() repo.Workspace {
+	return &workspaceRepo{
+		idCounter: 1,
+		members:   make(map[int][]entity.Membership),
+	}
+}
+
+func (r *workspaceRepo) Create(input entity.CreateWorkspaceInput) (*entity.Workspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspace := r.create(input)
+	return &workspace, nil
+}
+
+func (r *workspaceRepo) create(input entity.CreateWorkspaceInput) entity.Workspace {
+	workspace := entity.Workspace{
+		ID:        r.idCounter,
+		Name:      input.Name,
+		OwnerID:   input.OwnerID,
+		Personal:  input.Personal,
+		CreatedAt: timeNow(),
+	}
+	r.store = append(r.store, workspace)
+	r.members[workspace.ID] = []entity.Membership{{WorkspaceID: workspace.ID, UserID: input.OwnerID, Role: entity.RoleOwner}}
+	r.idCounter++
+	return workspace
+}
+
+func (r *workspaceRepo) Get(id int) (*entity.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.indexOf(id)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+
+	workspace := r.store[idx]
+	return &workspace, nil
+}
+
+func (r *workspaceRepo) List() ([]entity.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.store), nil
+}
+
+func (r *workspaceRepo) ListByMember(userID int) ([]entity.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspaces := []entity.Workspace{}
+	for _, workspace := range r.store {
+		if r.memberIndex(workspace.ID, userID) != -1 {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepo) Personal(userID int) (*entity.Workspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := slices.IndexFunc(r.store, func(workspace entity.Workspace) bool {
+		return workspace.Personal && workspace.OwnerID == userID
+	})
+	if idx != -1 {
+		workspace := r.store[idx]
+		return &workspace, nil
+	}
+
+	workspace := r.create(entity.CreateWorkspaceInput{Name: "Personal", OwnerID: userID, Personal: true})
+	return &workspace, nil
+}
+
+func (r *workspaceRepo) AddMember(workspaceID, userID int, role entity.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(workspaceID) == -1 {
+		return repo.ErrNotFound
+	}
+	if r.memberIndex(workspaceID, userID) != -1 {
+		return entity.ErrAlreadyMember
+	}
+	r.members[workspaceID] = append(r.members[workspaceID], entity.Membership{WorkspaceID: workspaceID, UserID: userID, Role: role})
+	return nil
+}
+
+func (r *workspaceRepo) Member(workspaceID, userID int) (*entity.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	idx := r.memberIndex(workspaceID, userID)
+	if idx == -1 {
+		return nil, repo.ErrNotFound
+	}
+	member := r.members[workspaceID][idx]
+	return &member, nil
+}
+
+func (r *workspaceRepo) Members(workspaceID int) ([]entity.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.indexOf(workspaceID) == -1 {
+		return nil, repo.ErrNotFound
+	}
+	return slices.Clone(r.members[workspaceID]), nil
+}
+
+func (r *workspaceRepo) indexOf(id int) int {
+	return slices.IndexFunc(r.store, func(workspace entity.Workspace) bool {
+		return workspace.ID == id
+	})
+}
+
+func (r *workspaceRepo) memberIndex(workspaceID, userID int) int {
+	return slices.IndexFunc(r.members[workspaceID], func(member entity.Membership) bool {
+		return member.UserID == userID
+	})
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/stretchr/testify/suite"
+)
+
+type workspaceSuite struct {
+	suite.Suite
+	repo repo.Workspace
+}
+
+func (s *workspaceSuite) SetupSubTest() {
+	s.repo = NewWorkspaceRepo()
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0)
+	}
+}
+
+func (s *workspaceSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(workspaceSuite))
+}
+
+func (s *workspaceSuite) TestCreate() {
+	s.Run("owner is the first member", func() {
+		got, err := s.repo.Create(entity.CreateWorkspaceInput{Name: "team", OwnerID: 7})
+		s.Require().NoError(err)
+		s.Equal(&entity.Workspace{ID: 1, Name: "team", OwnerID: 7, CreatedAt: time.Unix(123456789, 0)}, got)
+
+		members, err := s.repo.Members(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.Membership{{WorkspaceID: 1, UserID: 7, Role: entity.RoleOwner}}, members)
+	})
+}
+
+func (s *workspaceSuite) TestPersonal() {
+	s.Run("created once", func() {
+		first, err := s.repo.Personal(7)
+		s.Require().NoError(err)
+		s.True(first.Personal)
+		s.Equal(7, first.OwnerID)
+
+		second, err := s.repo.Personal(7)
+		s.Require().NoError(err)
+		s.Equal(first, second)
+
+		other, err := s.repo.Personal(8)
+		s.Require().NoError(err)
+		s.NotEqual(first.ID, other.ID)
+	})
+}
+
+func (s *workspaceSuite) TestMembers() {
+	s.Run("add member", func() {
+		_, _ = s.repo.Create(entity.CreateWorkspaceInput{Name: "team", OwnerID: 7})
+
+		s.Require().NoError(s.repo.AddMember(1, 8, entity.RoleViewer))
+		s.ErrorIs(s.repo.AddMember(1, 8, entity.RoleEditor), entity.ErrAlreadyMember)
+
+		member, err := s.repo.Member(1, 8)
+		s.Require().NoError(err)
+		s.Equal(&entity.Membership{WorkspaceID: 1, UserID: 8, Role: entity.RoleViewer}, member)
+		_, err = s.repo.Member(1, 9)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+	s.Run("list by member", func() {
+		_, _ = s.repo.Create(entity.CreateWorkspaceInput{Name: "a", OwnerID: 7})
+		_, _ = s.repo.Create(entity.CreateWorkspaceInput{Name: "b", OwnerID: 8})
+		_, _ = s.repo.Create(entity.CreateWorkspaceInput{Name: "c", OwnerID: 8})
+		_ = s.repo.AddMember(3, 7, entity.RoleGuest)
+
+		got, err := s.repo.ListByMember(7)
+		s.Require().NoError(err)
+		s.Len(got, 2)
+		s.Equal("a", got[0].Name)
+		s.Equal("c", got[1].Name)
+	})
+	s.Run("not found", func() {
+		s.ErrorIs(s.repo.AddMember(1, 8, entity.RoleEditor), repo.ErrNotFound)
+		_, err := s.repo.Member(1, 8)
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = s.repo.Members(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+		_, err = s.repo.Get(1)
+		s.ErrorIs(err, repo.ErrNotFound)
+	})
+}
@@ -11,8 +11,10 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/cloudingcity/todo/internal/entity"
+	repo "github.com/cloudingcity/todo/internal/repo"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,33 +42,179 @@ func (m *MockTodo) EXPECT() *MockTodoMockRecorder {
 	return m.recorder
 }
 
+// AddDependency mocks base method.
+func (m *MockTodo) AddDependency(todoID, blockerID int) (*entity.Dependency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDependency", todoID, blockerID)
+	ret0, _ := ret[0].(*entity.Dependency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddDependency indicates an expected call of AddDependency.
+func (mr *MockTodoMockRecorder) AddDependency(todoID, blockerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDependency", reflect.TypeOf((*MockTodo)(nil).AddDependency), todoID, blockerID)
+}
+
+// AttachTags mocks base method.
+func (m *MockTodo) AttachTags(todoID int, names []string) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTags", todoID, names)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachTags indicates an expected call of AttachTags.
+func (mr *MockTodoMockRecorder) AttachTags(todoID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTags", reflect.TypeOf((*MockTodo)(nil).AttachTags), todoID, names)
+}
+
+// BatchCreate mocks base method.
+func (m *MockTodo) BatchCreate(inputs []entity.CreateTodoInput) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreate", inputs)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCreate indicates an expected call of BatchCreate.
+func (mr *MockTodoMockRecorder) BatchCreate(inputs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockTodo)(nil).BatchCreate), inputs)
+}
+
+// BatchDelete mocks base method.
+func (m *MockTodo) BatchDelete(ids []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockTodoMockRecorder) BatchDelete(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockTodo)(nil).BatchDelete), ids)
+}
+
+// BatchUpdate mocks base method.
+func (m *MockTodo) BatchUpdate(inputs []entity.BatchUpdateTodoInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdate", inputs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchUpdate indicates an expected call of BatchUpdate.
+func (mr *MockTodoMockRecorder) BatchUpdate(inputs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdate", reflect.TypeOf((*MockTodo)(nil).BatchUpdate), inputs)
+}
+
+// CountOwned mocks base method.
+func (m *MockTodo) CountOwned(ownerID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOwned", ownerID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOwned indicates an expected call of CountOwned.
+func (mr *MockTodoMockRecorder) CountOwned(ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOwned", reflect.TypeOf((*MockTodo)(nil).CountOwned), ownerID)
+}
+
 // Create mocks base method.
-func (m *MockTodo) Create(title, description string) (*entity.Todo, error) {
+func (m *MockTodo) Create(input entity.CreateTodoInput) (*entity.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", title, description)
+	ret := m.ctrl.Call(m, "Create", input)
 	ret0, _ := ret[0].(*entity.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTodoMockRecorder) Create(title, description any) *gomock.Call {
+func (mr *MockTodoMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodo)(nil).Create), input)
+}
+
+// CreateTag mocks base method.
+func (m *MockTodo) CreateTag(name string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", name)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTodoMockRecorder) CreateTag(name any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodo)(nil).Create), title, description)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTodo)(nil).CreateTag), name)
 }
 
 // Delete mocks base method.
-func (m *MockTodo) Delete(id int) error {
+func (m *MockTodo) Delete(id int, mode entity.SubtaskDeleteMode) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", id, mode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTodoMockRecorder) Delete(id any) *gomock.Call {
+func (mr *MockTodoMockRecorder) Delete(id, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodo)(nil).Delete), id, mode)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTodo) DeleteTag(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTodoMockRecorder) DeleteTag(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTodo)(nil).DeleteTag), id)
+}
+
+// DetachTags mocks base method.
+func (m *MockTodo) DetachTags(todoID int, names []string) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTags", todoID, names)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetachTags indicates an expected call of DetachTags.
+func (mr *MockTodoMockRecorder) DetachTags(todoID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTags", reflect.TypeOf((*MockTodo)(nil).DetachTags), todoID, names)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockTodo) ForWorkspace(workspaceID int) repo.Todo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.Todo)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockTodoMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodo)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockTodo)(nil).ForWorkspace), workspaceID)
 }
 
 // Get mocks base method.
@@ -84,6 +232,21 @@ func (mr *MockTodoMockRecorder) Get(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTodo)(nil).Get), id)
 }
 
+// GetTag mocks base method.
+func (m *MockTodo) GetTag(id int) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", id)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockTodoMockRecorder) GetTag(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockTodo)(nil).GetTag), id)
+}
+
 // List mocks base method.
 func (m *MockTodo) List() ([]entity.Todo, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +262,155 @@ func (mr *MockTodoMockRecorder) List() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodo)(nil).List))
 }
 
+// ListDependencies mocks base method.
+func (m *MockTodo) ListDependencies() ([]entity.Dependency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDependencies")
+	ret0, _ := ret[0].([]entity.Dependency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDependencies indicates an expected call of ListDependencies.
+func (mr *MockTodoMockRecorder) ListDependencies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDependencies", reflect.TypeOf((*MockTodo)(nil).ListDependencies))
+}
+
+// ListTagged mocks base method.
+func (m *MockTodo) ListTagged(expr entity.TagExpr) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagged", expr)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagged indicates an expected call of ListTagged.
+func (mr *MockTodoMockRecorder) ListTagged(expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagged", reflect.TypeOf((*MockTodo)(nil).ListTagged), expr)
+}
+
+// ListTags mocks base method.
+func (m *MockTodo) ListTags() ([]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags")
+	ret0, _ := ret[0].([]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockTodoMockRecorder) ListTags() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockTodo)(nil).ListTags))
+}
+
+// MergeTags mocks base method.
+func (m *MockTodo) MergeTags(sourceID, targetID int) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", sourceID, targetID)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeTags indicates an expected call of MergeTags.
+func (mr *MockTodoMockRecorder) MergeTags(sourceID, targetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockTodo)(nil).MergeTags), sourceID, targetID)
+}
+
+// Move mocks base method.
+func (m *MockTodo) Move(id int, input entity.MoveTodoInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockTodoMockRecorder) Move(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockTodo)(nil).Move), id, input)
+}
+
+// RemoveDependency mocks base method.
+func (m *MockTodo) RemoveDependency(todoID, blockerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDependency", todoID, blockerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDependency indicates an expected call of RemoveDependency.
+func (mr *MockTodoMockRecorder) RemoveDependency(todoID, blockerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDependency", reflect.TypeOf((*MockTodo)(nil).RemoveDependency), todoID, blockerID)
+}
+
+// RenameTag mocks base method.
+func (m *MockTodo) RenameTag(id int, name string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", id, name)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockTodoMockRecorder) RenameTag(id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockTodo)(nil).RenameTag), id, name)
+}
+
+// Replace mocks base method.
+func (m *MockTodo) Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockTodoMockRecorder) Replace(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockTodo)(nil).Replace), id, input)
+}
+
+// SetParent mocks base method.
+func (m *MockTodo) SetParent(id, parentID int) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetParent", id, parentID)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetParent indicates an expected call of SetParent.
+func (mr *MockTodoMockRecorder) SetParent(id, parentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParent", reflect.TypeOf((*MockTodo)(nil).SetParent), id, parentID)
+}
+
+// Subtree mocks base method.
+func (m *MockTodo) Subtree(id int) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subtree", id)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subtree indicates an expected call of Subtree.
+func (mr *MockTodoMockRecorder) Subtree(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subtree", reflect.TypeOf((*MockTodo)(nil).Subtree), id)
+}
+
 // Update mocks base method.
 func (m *MockTodo) Update(id int, input entity.UpdateTodoInput) error {
 	m.ctrl.T.Helper()
@@ -112,3 +424,1038 @@ func (mr *MockTodoMockRecorder) Update(id, input any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodo)(nil).Update), id, input)
 }
+
+// UpdateFunc mocks base method.
+func (m *MockTodo) UpdateFunc(id int, fn func(*entity.Todo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFunc", id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFunc indicates an expected call of UpdateFunc.
+func (mr *MockTodoMockRecorder) UpdateFunc(id, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFunc", reflect.TypeOf((*MockTodo)(nil).UpdateFunc), id, fn)
+}
+
+// Upsert mocks base method.
+func (m *MockTodo) Upsert(id int, input entity.ReplaceTodoInput) (*entity.Todo, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockTodoMockRecorder) Upsert(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTodo)(nil).Upsert), id, input)
+}
+
+// MockProject is a mock of Project interface.
+type MockProject struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectMockRecorder
+	isgomock struct{}
+}
+
+// MockProjectMockRecorder is the mock recorder for MockProject.
+type MockProjectMockRecorder struct {
+	mock *MockProject
+}
+
+// NewMockProject creates a new mock instance.
+func NewMockProject(ctrl *gomock.Controller) *MockProject {
+	mock := &MockProject{ctrl: ctrl}
+	mock.recorder = &MockProjectMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProject) EXPECT() *MockProjectMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProject) Create(input entity.CreateProjectInput) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProjectMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProject)(nil).Create), input)
+}
+
+// Delete mocks base method.
+func (m *MockProject) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProject)(nil).Delete), id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockProject) ForWorkspace(workspaceID int) repo.Project {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.Project)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockProjectMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockProject)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockProject) Get(id int) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProjectMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProject)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockProject) List() ([]entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProjectMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProject)(nil).List))
+}
+
+// SetArchived mocks base method.
+func (m *MockProject) SetArchived(id int, archived bool) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArchived", id, archived)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetArchived indicates an expected call of SetArchived.
+func (mr *MockProjectMockRecorder) SetArchived(id, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArchived", reflect.TypeOf((*MockProject)(nil).SetArchived), id, archived)
+}
+
+// Share mocks base method.
+func (m *MockProject) Share(projectID, userID int, role entity.Role) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", projectID, userID, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockProjectMockRecorder) Share(projectID, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockProject)(nil).Share), projectID, userID, role)
+}
+
+// Shares mocks base method.
+func (m *MockProject) Shares(projectID int) ([]entity.ProjectShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shares", projectID)
+	ret0, _ := ret[0].([]entity.ProjectShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Shares indicates an expected call of Shares.
+func (mr *MockProjectMockRecorder) Shares(projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shares", reflect.TypeOf((*MockProject)(nil).Shares), projectID)
+}
+
+// SharesOf mocks base method.
+func (m *MockProject) SharesOf(userID int) ([]entity.ProjectShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SharesOf", userID)
+	ret0, _ := ret[0].([]entity.ProjectShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SharesOf indicates an expected call of SharesOf.
+func (mr *MockProjectMockRecorder) SharesOf(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SharesOf", reflect.TypeOf((*MockProject)(nil).SharesOf), userID)
+}
+
+// Unshare mocks base method.
+func (m *MockProject) Unshare(projectID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", projectID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockProjectMockRecorder) Unshare(projectID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockProject)(nil).Unshare), projectID, userID)
+}
+
+// Update mocks base method.
+func (m *MockProject) Update(id int, input entity.UpdateProjectInput) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, input)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectMockRecorder) Update(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProject)(nil).Update), id, input)
+}
+
+// MockComment is a mock of Comment interface.
+type MockComment struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentMockRecorder
+	isgomock struct{}
+}
+
+// MockCommentMockRecorder is the mock recorder for MockComment.
+type MockCommentMockRecorder struct {
+	mock *MockComment
+}
+
+// NewMockComment creates a new mock instance.
+func NewMockComment(ctrl *gomock.Controller) *MockComment {
+	mock := &MockComment{ctrl: ctrl}
+	mock.recorder = &MockCommentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComment) EXPECT() *MockCommentMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockComment) Create(input entity.CreateCommentInput) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockComment)(nil).Create), input)
+}
+
+// Delete mocks base method.
+func (m *MockComment) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockComment)(nil).Delete), id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockComment) ForWorkspace(workspaceID int) repo.Comment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.Comment)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockCommentMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockComment)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockComment) Get(id int) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCommentMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockComment)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockComment) List(todoID int) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCommentMockRecorder) List(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockComment)(nil).List), todoID)
+}
+
+// Update mocks base method.
+func (m *MockComment) Update(id int, input entity.UpdateCommentInput) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, input)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentMockRecorder) Update(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockComment)(nil).Update), id, input)
+}
+
+// MockAttachment is a mock of Attachment interface.
+type MockAttachment struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachmentMockRecorder is the mock recorder for MockAttachment.
+type MockAttachmentMockRecorder struct {
+	mock *MockAttachment
+}
+
+// NewMockAttachment creates a new mock instance.
+func NewMockAttachment(ctrl *gomock.Controller) *MockAttachment {
+	mock := &MockAttachment{ctrl: ctrl}
+	mock.recorder = &MockAttachmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachment) EXPECT() *MockAttachmentMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method.
+func (m *MockAttachment) All() ([]entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All")
+	ret0, _ := ret[0].([]entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// All indicates an expected call of All.
+func (mr *MockAttachmentMockRecorder) All() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockAttachment)(nil).All))
+}
+
+// Create mocks base method.
+func (m *MockAttachment) Create(input entity.CreateAttachmentInput) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAttachmentMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAttachment)(nil).Create), input)
+}
+
+// Delete mocks base method.
+func (m *MockAttachment) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAttachmentMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachment)(nil).Delete), id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockAttachment) ForWorkspace(workspaceID int) repo.Attachment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.Attachment)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockAttachmentMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockAttachment)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockAttachment) Get(id int) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAttachmentMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAttachment)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockAttachment) List(todoID int) ([]entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID)
+	ret0, _ := ret[0].([]entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAttachmentMockRecorder) List(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAttachment)(nil).List), todoID)
+}
+
+// MockTimeEntry is a mock of TimeEntry interface.
+type MockTimeEntry struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimeEntryMockRecorder
+	isgomock struct{}
+}
+
+// MockTimeEntryMockRecorder is the mock recorder for MockTimeEntry.
+type MockTimeEntryMockRecorder struct {
+	mock *MockTimeEntry
+}
+
+// NewMockTimeEntry creates a new mock instance.
+func NewMockTimeEntry(ctrl *gomock.Controller) *MockTimeEntry {
+	mock := &MockTimeEntry{ctrl: ctrl}
+	mock.recorder = &MockTimeEntryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimeEntry) EXPECT() *MockTimeEntryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTimeEntry) Create(input entity.CreateTimeEntryInput) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTimeEntryMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTimeEntry)(nil).Create), input)
+}
+
+// Delete mocks base method.
+func (m *MockTimeEntry) Delete(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTimeEntryMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTimeEntry)(nil).Delete), id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockTimeEntry) ForWorkspace(workspaceID int) repo.TimeEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.TimeEntry)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockTimeEntryMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockTimeEntry)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockTimeEntry) Get(id int) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTimeEntryMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTimeEntry)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockTimeEntry) List(todoID int) ([]entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID)
+	ret0, _ := ret[0].([]entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTimeEntryMockRecorder) List(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTimeEntry)(nil).List), todoID)
+}
+
+// ListAll mocks base method.
+func (m *MockTimeEntry) ListAll() ([]entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll")
+	ret0, _ := ret[0].([]entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAll indicates an expected call of ListAll.
+func (mr *MockTimeEntryMockRecorder) ListAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockTimeEntry)(nil).ListAll))
+}
+
+// Running mocks base method.
+func (m *MockTimeEntry) Running(userID int) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Running", userID)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Running indicates an expected call of Running.
+func (mr *MockTimeEntryMockRecorder) Running(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Running", reflect.TypeOf((*MockTimeEntry)(nil).Running), userID)
+}
+
+// Start mocks base method.
+func (m *MockTimeEntry) Start(input entity.CreateTimeEntryInput) (*entity.TimeEntry, *entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", input)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(*entity.TimeEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockTimeEntryMockRecorder) Start(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockTimeEntry)(nil).Start), input)
+}
+
+// Stop mocks base method.
+func (m *MockTimeEntry) Stop(userID int, end time.Time) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", userID, end)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockTimeEntryMockRecorder) Stop(userID, end any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockTimeEntry)(nil).Stop), userID, end)
+}
+
+// Update mocks base method.
+func (m *MockTimeEntry) Update(id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, input)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTimeEntryMockRecorder) Update(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTimeEntry)(nil).Update), id, input)
+}
+
+// MockHistory is a mock of History interface.
+type MockHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHistoryMockRecorder is the mock recorder for MockHistory.
+type MockHistoryMockRecorder struct {
+	mock *MockHistory
+}
+
+// NewMockHistory creates a new mock instance.
+func NewMockHistory(ctrl *gomock.Controller) *MockHistory {
+	mock := &MockHistory{ctrl: ctrl}
+	mock.recorder = &MockHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistory) EXPECT() *MockHistoryMockRecorder {
+	return m.recorder
+}
+
+// ForWorkspace mocks base method.
+func (m *MockHistory) ForWorkspace(workspaceID int) repo.History {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(repo.History)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockHistoryMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockHistory)(nil).ForWorkspace), workspaceID)
+}
+
+// List mocks base method.
+func (m *MockHistory) List(todoID int) ([]entity.TodoChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID)
+	ret0, _ := ret[0].([]entity.TodoChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockHistoryMockRecorder) List(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockHistory)(nil).List), todoID)
+}
+
+// Record mocks base method.
+func (m *MockHistory) Record(change entity.TodoChange) (*entity.TodoChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", change)
+	ret0, _ := ret[0].(*entity.TodoChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockHistoryMockRecorder) Record(change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockHistory)(nil).Record), change)
+}
+
+// MockUser is a mock of User interface.
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+	isgomock struct{}
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser.
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance.
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUser) Create(input entity.CreateUserInput) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUser)(nil).Create), input)
+}
+
+// Get mocks base method.
+func (m *MockUser) Get(id int) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUserMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUser)(nil).Get), id)
+}
+
+// GetByEmail mocks base method.
+func (m *MockUser) GetByEmail(email string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", email)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserMockRecorder) GetByEmail(email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUser)(nil).GetByEmail), email)
+}
+
+// GetByIdentity mocks base method.
+func (m *MockUser) GetByIdentity(issuer, subject string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdentity", issuer, subject)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdentity indicates an expected call of GetByIdentity.
+func (mr *MockUserMockRecorder) GetByIdentity(issuer, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdentity", reflect.TypeOf((*MockUser)(nil).GetByIdentity), issuer, subject)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockUser) LinkIdentity(userID int, issuer, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", userID, issuer, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockUserMockRecorder) LinkIdentity(userID, issuer, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockUser)(nil).LinkIdentity), userID, issuer, subject)
+}
+
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyMockRecorder
+	isgomock struct{}
+}
+
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
+}
+
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKey) Create(input entity.CreateAPIKeyInput) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKey)(nil).Create), input)
+}
+
+// Get mocks base method.
+func (m *MockAPIKey) Get(id int) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAPIKeyMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAPIKey)(nil).Get), id)
+}
+
+// GetByPrefix mocks base method.
+func (m *MockAPIKey) GetByPrefix(prefix string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPrefix", prefix)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPrefix indicates an expected call of GetByPrefix.
+func (mr *MockAPIKeyMockRecorder) GetByPrefix(prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPrefix", reflect.TypeOf((*MockAPIKey)(nil).GetByPrefix), prefix)
+}
+
+// ListByUser mocks base method.
+func (m *MockAPIKey) ListByUser(userID int) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockAPIKeyMockRecorder) ListByUser(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockAPIKey)(nil).ListByUser), userID)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKey) Revoke(id int, at time.Time) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", id, at)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyMockRecorder) Revoke(id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKey)(nil).Revoke), id, at)
+}
+
+// Touch mocks base method.
+func (m *MockAPIKey) Touch(id int, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockAPIKeyMockRecorder) Touch(id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockAPIKey)(nil).Touch), id, at)
+}
+
+// MockWorkspace is a mock of Workspace interface.
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+	isgomock struct{}
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace.
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance.
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockWorkspace) AddMember(workspaceID, userID int, role entity.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", workspaceID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockWorkspaceMockRecorder) AddMember(workspaceID, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockWorkspace)(nil).AddMember), workspaceID, userID, role)
+}
+
+// Create mocks base method.
+func (m *MockWorkspace) Create(input entity.CreateWorkspaceInput) (*entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWorkspaceMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWorkspace)(nil).Create), input)
+}
+
+// Get mocks base method.
+func (m *MockWorkspace) Get(id int) (*entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWorkspaceMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWorkspace)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockWorkspace) List() ([]entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWorkspaceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWorkspace)(nil).List))
+}
+
+// ListByMember mocks base method.
+func (m *MockWorkspace) ListByMember(userID int) ([]entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMember", userID)
+	ret0, _ := ret[0].([]entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMember indicates an expected call of ListByMember.
+func (mr *MockWorkspaceMockRecorder) ListByMember(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMember", reflect.TypeOf((*MockWorkspace)(nil).ListByMember), userID)
+}
+
+// Member mocks base method.
+func (m *MockWorkspace) Member(workspaceID, userID int) (*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Member", workspaceID, userID)
+	ret0, _ := ret[0].(*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Member indicates an expected call of Member.
+func (mr *MockWorkspaceMockRecorder) Member(workspaceID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Member", reflect.TypeOf((*MockWorkspace)(nil).Member), workspaceID, userID)
+}
+
+// Members mocks base method.
+func (m *MockWorkspace) Members(workspaceID int) ([]entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Members", workspaceID)
+	ret0, _ := ret[0].([]entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Members indicates an expected call of Members.
+func (mr *MockWorkspaceMockRecorder) Members(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Members", reflect.TypeOf((*MockWorkspace)(nil).Members), workspaceID)
+}
+
+// Personal mocks base method.
+func (m *MockWorkspace) Personal(userID int) (*entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Personal", userID)
+	ret0, _ := ret[0].(*entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Personal indicates an expected call of Personal.
+func (mr *MockWorkspaceMockRecorder) Personal(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Personal", reflect.TypeOf((*MockWorkspace)(nil).Personal), userID)
+}
@@ -2,6 +2,8 @@ package repo
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
 )
@@ -10,11 +12,188 @@ var (
 	ErrNotFound = errors.New("not found")
 )
 
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
 //go:generate mockgen -source=repo.go -destination mocks/repo.go -package mocks
 type Todo interface {
-	Create(title, description string) (*entity.Todo, error)
+	// ForWorkspace returns the repo of another workspace. Workspaces share
+	// nothing, not even ID sequences.
+	ForWorkspace(workspaceID int) Todo
+	Create(input entity.CreateTodoInput) (*entity.Todo, error)
 	List() ([]entity.Todo, error)
+	// CountOwned counts the todos ownerID owns in every workspace.
+	CountOwned(ownerID int) (int, error)
 	Get(id int) (*entity.Todo, error)
 	Update(id int, input entity.UpdateTodoInput) error
+	UpdateFunc(id int, fn func(todo *entity.Todo) error) error
+	Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error)
+	Upsert(id int, input entity.ReplaceTodoInput) (todo *entity.Todo, created bool, err error)
+	// Delete removes a todo, handling its subtasks as mode says.
+	Delete(id int, mode entity.SubtaskDeleteMode) error
+	Move(id int, input entity.MoveTodoInput) (*entity.Todo, error)
+	BatchCreate(inputs []entity.CreateTodoInput) ([]entity.Todo, error)
+	BatchUpdate(inputs []entity.BatchUpdateTodoInput) error
+	// BatchDelete removes the todos together with their subtasks.
+	BatchDelete(ids []int) error
+	// SetParent moves a todo below another one, or to the top level when
+	// parentID is zero.
+	SetParent(id, parentID int) (*entity.Todo, error)
+	// Subtree returns the todo followed by all of its subtasks.
+	Subtree(id int) ([]entity.Todo, error)
+
+	// AddDependency records that todoID is blocked by blockerID, refusing
+	// edges that would close a cycle.
+	AddDependency(todoID, blockerID int) (*entity.Dependency, error)
+	RemoveDependency(todoID, blockerID int) error
+	ListDependencies() ([]entity.Dependency, error)
+
+	CreateTag(name string) (*entity.Tag, error)
+	ListTags() ([]entity.Tag, error)
+	GetTag(id int) (*entity.Tag, error)
+	RenameTag(id int, name string) (*entity.Tag, error)
+	DeleteTag(id int) error
+	// MergeTags moves every todo tagged with the source tag to the target
+	// tag and deletes the source tag.
+	MergeTags(sourceID, targetID int) (*entity.Tag, error)
+	// AttachTags tags a todo by name, creating tags that do not exist yet.
+	AttachTags(todoID int, names []string) (*entity.Todo, error)
+	DetachTags(todoID int, names []string) (*entity.Todo, error)
+	ListTagged(expr entity.TagExpr) ([]entity.Todo, error)
+}
+
+type Project interface {
+	// ForWorkspace returns the repo of another workspace.
+	ForWorkspace(workspaceID int) Project
+	Create(input entity.CreateProjectInput) (*entity.Project, error)
+	List() ([]entity.Project, error)
+	Get(id int) (*entity.Project, error)
+	Update(id int, input entity.UpdateProjectInput) (*entity.Project, error)
+	// SetArchived archives or restores a project. Archiving an archived
+	// project keeps its original archive time.
+	SetArchived(id int, archived bool) (*entity.Project, error)
+	// Delete deletes a project along with its shares.
 	Delete(id int) error
+	// Share shares a project with a user, or changes the role of an
+	// existing share. created reports whether the share is new.
+	Share(projectID, userID int, role entity.Role) (created bool, err error)
+	Unshare(projectID, userID int) error
+	// Shares returns the shares of a project in the order they were made.
+	Shares(projectID int) ([]entity.ProjectShare, error)
+	// SharesOf returns the shares of every project with a user.
+	SharesOf(userID int) ([]entity.ProjectShare, error)
+}
+
+type Comment interface {
+	// ForWorkspace returns the repo of another workspace.
+	ForWorkspace(workspaceID int) Comment
+	Create(input entity.CreateCommentInput) (*entity.Comment, error)
+	Get(id int) (*entity.Comment, error)
+	// List returns the comments on a todo, oldest first.
+	List(todoID int) ([]entity.Comment, error)
+	// Update replaces the body of a comment, keeping the one it replaces in
+	// Edits.
+	Update(id int, input entity.UpdateCommentInput) (*entity.Comment, error)
+	Delete(id int) error
+}
+
+type Attachment interface {
+	// ForWorkspace returns the repo of another workspace.
+	ForWorkspace(workspaceID int) Attachment
+	Create(input entity.CreateAttachmentInput) (*entity.Attachment, error)
+	Get(id int) (*entity.Attachment, error)
+	// List returns the attachments of a todo, oldest first.
+	List(todoID int) ([]entity.Attachment, error)
+	Delete(id int) error
+	// All returns the attachments of every workspace, for cleanups that
+	// span them.
+	All() ([]entity.Attachment, error)
+}
+
+type TimeEntry interface {
+	// ForWorkspace returns the repo of another workspace.
+	ForWorkspace(workspaceID int) TimeEntry
+	// Create records an entry that has ended.
+	Create(input entity.CreateTimeEntryInput) (*entity.TimeEntry, error)
+	// Start starts a timer for the user of input, stopping the timer they
+	// had running in any workspace at the start of the new one. stopped is
+	// that timer, or nil.
+	Start(input entity.CreateTimeEntryInput) (started, stopped *entity.TimeEntry, err error)
+	// Stop stops the timer userID has running in any workspace. It fails
+	// with entity.ErrNoRunningTimer when there is none.
+	Stop(userID int, end time.Time) (*entity.TimeEntry, error)
+	// Running returns the timer userID has running in any workspace, or
+	// fails with entity.ErrNoRunningTimer.
+	Running(userID int) (*entity.TimeEntry, error)
+	Get(id int) (*entity.TimeEntry, error)
+	// List returns the entries of a todo in the order they started.
+	List(todoID int) ([]entity.TimeEntry, error)
+	// ListAll returns the entries of every todo in the order they started.
+	ListAll() ([]entity.TimeEntry, error)
+	Update(id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error)
+	Delete(id int) error
+}
+
+// History keeps what was changed on todos.
+type History interface {
+	// ForWorkspace returns the repo of another workspace.
+	ForWorkspace(workspaceID int) History
+	Record(change entity.TodoChange) (*entity.TodoChange, error)
+	// List returns the changes of a todo, oldest first.
+	List(todoID int) ([]entity.TodoChange, error)
+}
+
+type User interface {
+	// Create fails with entity.ErrEmailTaken when the email is in use.
+	Create(input entity.CreateUserInput) (*entity.User, error)
+	Get(id int) (*entity.User, error)
+	GetByEmail(email string) (*entity.User, error)
+	// LinkIdentity lets a user log in as the subject of an identity
+	// provider. It fails with entity.ErrIdentityLinked when the subject is
+	// linked to a user already.
+	LinkIdentity(userID int, issuer, subject string) error
+	// GetByIdentity returns the user the subject of a provider is linked to.
+	GetByIdentity(issuer, subject string) (*entity.User, error)
+}
+
+type APIKey interface {
+	// Create fails with entity.ErrAPIKeyPrefixTaken when the prefix is in
+	// use.
+	Create(input entity.CreateAPIKeyInput) (*entity.APIKey, error)
+	Get(id int) (*entity.APIKey, error)
+	GetByPrefix(prefix string) (*entity.APIKey, error)
+	ListByUser(userID int) ([]entity.APIKey, error)
+	// Revoke revokes a key. Revoking a revoked key keeps the original
+	// revocation time.
+	Revoke(id int, at time.Time) (*entity.APIKey, error)
+	// Touch records that a key was used at the given time.
+	Touch(id int, at time.Time) error
+}
+
+type Workspace interface {
+	// Create creates a workspace with its owner as the first member, at
+	// entity.RoleOwner.
+	Create(input entity.CreateWorkspaceInput) (*entity.Workspace, error)
+	Get(id int) (*entity.Workspace, error)
+	List() ([]entity.Workspace, error)
+	ListByMember(userID int) ([]entity.Workspace, error)
+	// Personal returns the personal workspace of a user, creating it the
+	// first time.
+	Personal(userID int) (*entity.Workspace, error)
+	// AddMember fails with entity.ErrAlreadyMember for a member.
+	AddMember(workspaceID, userID int, role entity.Role) error
+	// Member fails with ErrNotFound when the user is not a member.
+	Member(workspaceID, userID int) (*entity.Membership, error)
+	// Members returns the members in the order they joined.
+	Members(workspaceID int) ([]entity.Membership, error)
 }
@@ -0,0 +1,141 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+var (
+	timeNow = time.Now
+	// newText returns 26 characters of random base32.
+	newText = rand.Text
+)
+
+const (
+	// idLength is how many characters after entity.APIKeyPrefix identify a
+	// key. Together they make its visible prefix.
+	idLength = 8
+	// issueAttempts bounds the retries when a new key collides with the
+	// prefix of an existing one.
+	issueAttempts = 3
+)
+
+// Service issues API keys of the form tdk_<id>_<secret>. Keys are random
+// enough that a plain SHA-256 is enough to store them, which keeps checking
+// a key on every request cheap.
+type Service struct {
+	repo  repo.APIKey
+	users repo.User
+}
+
+func NewService(repo repo.APIKey, users repo.User) service.APIKey {
+	return &Service{
+		repo:  repo,
+		users: users,
+	}
+}
+
+func (s *Service) Issue(input entity.IssueAPIKeyInput) (*entity.APIKey, string, error) {
+	scopes, err := entity.ParseScopes(input.Scopes)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(timeNow()) {
+		return nil, "", fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrExpiryPassed)
+	}
+
+	for range issueAttempts {
+		prefix := entity.APIKeyPrefix + newText()[:idLength]
+		key := prefix + "_" + newText()
+		created, err := s.repo.Create(entity.CreateAPIKeyInput{
+			UserID:    input.UserID,
+			Name:      strings.TrimSpace(input.Name),
+			Prefix:    prefix,
+			Hash:      hash(key),
+			Scopes:    scopes,
+			ExpiresAt: input.ExpiresAt,
+		})
+		if errors.Is(err, entity.ErrAPIKeyPrefixTaken) {
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+		return created, key, nil
+	}
+	return nil, "", entity.ErrAPIKeyPrefixTaken
+}
+
+func (s *Service) List(userID int) ([]entity.APIKey, error) {
+	return s.repo.ListByUser(userID)
+}
+
+func (s *Service) Revoke(userID, id int) (*entity.APIKey, error) {
+	key, err := s.repo.Get(id)
+	if errors.Is(err, repo.ErrNotFound) || err == nil && key.UserID != userID {
+		return nil, service.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	key, err = s.repo.Revoke(id, timeNow())
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, service.ErrNotFound
+	}
+	return key, err
+}
+
+func (s *Service) Authenticate(key string) (*entity.User, *entity.APIKey, error) {
+	prefix, ok := prefixOf(key)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: malformed api key", service.ErrUnauthorized)
+	}
+	apiKey, err := s.repo.GetByPrefix(prefix)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%w: unknown api key", service.ErrUnauthorized)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash(key)), []byte(apiKey.Hash)) != 1 {
+		return nil, nil, fmt.Errorf("%w: unknown api key", service.ErrUnauthorized)
+	}
+	now := timeNow()
+	if !apiKey.Active(now) {
+		return nil, nil, fmt.Errorf("%w: api key is revoked or expired", service.ErrUnauthorized)
+	}
+
+	user, err := s.users.Get(apiKey.UserID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%w: unknown user", service.ErrUnauthorized)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Touch(apiKey.ID, now); err != nil {
+		return nil, nil, err
+	}
+	apiKey.LastUsedAt = &now
+	return user, apiKey, nil
+}
+
+// prefixOf returns the visible prefix of a well-formed key.
+func prefixOf(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, entity.APIKeyPrefix)
+	if !ok || len(rest) <= idLength+1 || rest[idLength] != '_' {
+		return "", false
+	}
+	return key[:len(entity.APIKeyPrefix)+idLength], true
+}
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,256 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var mockErr = errors.New("something wrong")
+
+const testKey = "tdk_AAAAAAAA_SECRETSECRETSECRETSECRETSE"
+
+type apiKeySuite struct {
+	suite.Suite
+	srv       service.APIKey
+	mockRepo  *mocks.MockAPIKey
+	mockUsers *mocks.MockUser
+	now       time.Time
+}
+
+func (s *apiKeySuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = mocks.NewMockAPIKey(ctrl)
+	s.mockUsers = mocks.NewMockUser(ctrl)
+	s.srv = NewService(s.mockRepo, s.mockUsers)
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time {
+		return s.now
+	}
+}
+
+func (s *apiKeySuite) TearDownSubTest() {
+	timeNow = time.Now
+	newText = rand.Text
+}
+
+func TestAPIKeySuite(t *testing.T) {
+	suite.Run(t, new(apiKeySuite))
+}
+
+func (s *apiKeySuite) TestIssue() {
+	s.Run("success", func() {
+		texts := []string{"AAAAAAAAXXXXXXXXXXXXXXXXXX", "SECRETSECRETSECRETSECRETSE"}
+		newText = func() string {
+			text := texts[0]
+			texts = texts[1:]
+			return text
+		}
+		expiresAt := s.now.Add(time.Hour)
+		s.mockRepo.EXPECT().Create(entity.CreateAPIKeyInput{
+			UserID:    1,
+			Name:      "ci",
+			Prefix:    "tdk_AAAAAAAA",
+			Hash:      hash(testKey),
+			Scopes:    []entity.Scope{entity.ScopeTodosRead, entity.ScopeTodosWrite},
+			ExpiresAt: &expiresAt,
+		}).Return(&entity.APIKey{ID: 1}, nil).Times(1)
+
+		got, key, err := s.srv.Issue(entity.IssueAPIKeyInput{
+			UserID:    1,
+			Name:      " ci ",
+			Scopes:    []entity.Scope{entity.ScopeTodosWrite, entity.ScopeTodosRead, entity.ScopeTodosWrite},
+			ExpiresAt: &expiresAt,
+		})
+		s.Require().NoError(err)
+		s.Equal(1, got.ID)
+		s.Equal(testKey, key)
+	})
+	s.Run("retries a taken prefix", func() {
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, entity.ErrAPIKeyPrefixTaken),
+			s.mockRepo.EXPECT().Create(gomock.Any()).Return(&entity.APIKey{ID: 1}, nil),
+		)
+
+		_, _, err := s.srv.Issue(entity.IssueAPIKeyInput{Scopes: []entity.Scope{entity.ScopeTodosRead}})
+		s.NoError(err)
+	})
+
+	tests := []struct {
+		desc    string
+		input   entity.IssueAPIKeyInput
+		setup   func()
+		wantErr []error
+	}{
+		{
+			desc:    "no scopes",
+			input:   entity.IssueAPIKeyInput{},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrNoScopes},
+		},
+		{
+			desc:    "unknown scope",
+			input:   entity.IssueAPIKeyInput{Scopes: []entity.Scope{"admin"}},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrInvalidScope},
+		},
+		{
+			desc:    "expired",
+			input:   entity.IssueAPIKeyInput{Scopes: []entity.Scope{entity.ScopeTodosRead}, ExpiresAt: lo.ToPtr(time.Unix(123456789, 0))},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrExpiryPassed},
+		},
+		{
+			desc:  "prefix keeps colliding",
+			input: entity.IssueAPIKeyInput{Scopes: []entity.Scope{entity.ScopeTodosRead}},
+			setup: func() {
+				s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, entity.ErrAPIKeyPrefixTaken).Times(issueAttempts)
+			},
+			wantErr: []error{entity.ErrAPIKeyPrefixTaken},
+		},
+		{
+			desc:  "repo error",
+			input: entity.IssueAPIKeyInput{Scopes: []entity.Scope{entity.ScopeTodosRead}},
+			setup: func() {
+				s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, mockErr).Times(1)
+			},
+			wantErr: []error{mockErr},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, _, err := s.srv.Issue(tt.input)
+			for _, want := range tt.wantErr {
+				s.ErrorIs(err, want)
+			}
+		})
+	}
+}
+
+func (s *apiKeySuite) TestRevoke() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.APIKey{ID: 1, UserID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Revoke(1, s.now).Return(&entity.APIKey{ID: 1, UserID: 1, RevokedAt: &s.now}, nil).Times(1)
+
+		got, err := s.srv.Revoke(1, 1)
+		s.Require().NoError(err)
+		s.Equal(&s.now, got.RevokedAt)
+	})
+	s.Run("other user", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.APIKey{ID: 1, UserID: 2}, nil).Times(1)
+
+		_, err := s.srv.Revoke(1, 1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Revoke(1, 1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *apiKeySuite) TestAuthenticate() {
+	stored := func() *entity.APIKey {
+		return &entity.APIKey{ID: 3, UserID: 1, Prefix: "tdk_AAAAAAAA", Hash: hash(testKey)}
+	}
+
+	s.Run("success", func() {
+		user := &entity.User{ID: 1}
+		s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(stored(), nil).Times(1)
+		s.mockUsers.EXPECT().Get(1).Return(user, nil).Times(1)
+		s.mockRepo.EXPECT().Touch(3, s.now).Return(nil).Times(1)
+
+		gotUser, gotKey, err := s.srv.Authenticate(testKey)
+		s.Require().NoError(err)
+		s.Equal(user, gotUser)
+		s.Equal(3, gotKey.ID)
+		s.Equal(&s.now, gotKey.LastUsedAt)
+	})
+
+	tests := []struct {
+		desc    string
+		key     string
+		setup   func()
+		wantErr error
+	}{
+		{
+			desc:    "malformed",
+			key:     "tdk_short",
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "unknown prefix",
+			key:  testKey,
+			setup: func() {
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "wrong secret",
+			key:  "tdk_AAAAAAAA_WRONG",
+			setup: func() {
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(stored(), nil).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "revoked",
+			key:  testKey,
+			setup: func() {
+				key := stored()
+				key.RevokedAt = lo.ToPtr(s.now.Add(-time.Second))
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(key, nil).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "expired",
+			key:  testKey,
+			setup: func() {
+				key := stored()
+				key.ExpiresAt = lo.ToPtr(s.now)
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(key, nil).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "deleted user",
+			key:  testKey,
+			setup: func() {
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(stored(), nil).Times(1)
+				s.mockUsers.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "repo error",
+			key:  testKey,
+			setup: func() {
+				s.mockRepo.EXPECT().GetByPrefix("tdk_AAAAAAAA").Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, _, err := s.srv.Authenticate(tt.key)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
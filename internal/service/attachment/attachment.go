@@ -0,0 +1,270 @@
+package attachment
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/blob"
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/policy"
+)
+
+// DefaultSweepGrace is how long Sweep leaves content nothing refers to, so
+// content that was just uploaded survives until its attachment is created.
+const DefaultSweepGrace = time.Hour
+
+// sniffLen is how much of the content http.DetectContentType looks at.
+const sniffLen = 512
+
+var timeNow = time.Now
+
+// Limits caps what can be attached. Zero leaves a limit off.
+type Limits struct {
+	// MaxSize is the largest file in bytes.
+	MaxSize int64
+	// MaxPerTodo is how many files a todo can have.
+	MaxPerTodo int
+}
+
+type Option func(*Service)
+
+func WithLimits(limits Limits) Option {
+	return func(s *Service) {
+		s.limits = limits
+	}
+}
+
+// Service manages attachments. Todos are reached through the todo service,
+// so an attachment is only as visible as its todo.
+type Service struct {
+	repo   repo.Attachment
+	blobs  blob.Store
+	todos  service.Todo
+	limits Limits
+	grace  time.Duration
+	// actor is who the service is scoped to, or nil for the unscoped
+	// service.
+	actor *entity.Actor
+}
+
+func NewService(repo repo.Attachment, blobs blob.Store, todos service.Todo, opts ...Option) service.Attachment {
+	s := &Service{
+		repo:  repo,
+		blobs: blobs,
+		todos: todos,
+		grace: DefaultSweepGrace,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ForWorkspace returns a copy of the service working on the attachments and
+// todos of another workspace. Content is shared by every workspace.
+func (s *Service) ForWorkspace(workspaceID int) service.Attachment {
+	scoped := *s
+	scoped.repo = s.repo.ForWorkspace(workspaceID)
+	scoped.todos = s.todos.ForWorkspace(workspaceID)
+	return &scoped
+}
+
+// As scopes a copy of the service, and the todo service it uses, to actor.
+func (s *Service) As(actor entity.Actor) service.Attachment {
+	scoped := *s
+	scoped.actor = &actor
+	scoped.todos = s.todos.As(actor)
+	return &scoped
+}
+
+func (s *Service) Upload(todoID int, name string, r io.Reader) (*entity.Attachment, error) {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return nil, err
+	}
+	if s.actor != nil {
+		if err := policy.Check(*s.actor, entity.ActionUpdate, policy.Todo(*todo)); err != nil {
+			return nil, err
+		}
+	}
+	if s.limits.MaxPerTodo > 0 {
+		attachments, err := s.repo.List(todoID)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		if len(attachments) >= s.limits.MaxPerTodo {
+			return nil, fmt.Errorf("%w: %w, at most %d are allowed", service.ErrQuotaExceeded, entity.ErrTooManyAttachments, s.limits.MaxPerTodo)
+		}
+	}
+
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+
+	var content io.Reader = br
+	if s.limits.MaxSize > 0 {
+		content = &maxReader{r: br, n: s.limits.MaxSize}
+	}
+	info, err := s.blobs.Put(content)
+	if errors.Is(err, entity.ErrAttachmentTooLarge) {
+		return nil, fmt.Errorf("%w: %w, at most %d bytes are allowed", service.ErrInvalidInput, err, s.limits.MaxSize)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var uploaderID int
+	if s.actor != nil {
+		uploaderID = s.actor.UserID
+	}
+	attachment, err := s.repo.Create(entity.CreateAttachmentInput{
+		TodoID:      todoID,
+		UploaderID:  uploaderID,
+		Name:        entity.NormalizeFileName(name),
+		ContentType: contentType,
+		Size:        info.Size,
+		BlobKey:     info.Key,
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return attachment, nil
+}
+
+func (s *Service) List(todoID int) ([]entity.Attachment, error) {
+	if _, err := s.todos.Get(todoID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.List(todoID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return attachments, nil
+}
+
+func (s *Service) Get(todoID, id int) (*entity.Attachment, error) {
+	_, attachment, err := s.get(todoID, id)
+	return attachment, err
+}
+
+func (s *Service) Open(todoID, id int) (*entity.Attachment, io.ReadSeekCloser, error) {
+	_, attachment, err := s.get(todoID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, _, err := s.blobs.Open(attachment.BlobKey)
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+	return attachment, content, nil
+}
+
+func (s *Service) Delete(todoID, id int) error {
+	todo, attachment, err := s.get(todoID, id)
+	if err != nil {
+		return err
+	}
+	if s.actor != nil {
+		resource := policy.Resource{OwnerID: attachment.UploaderID, ProjectID: todo.ProjectID}
+		if err := policy.Check(*s.actor, entity.ActionDelete, resource); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// Sweep leaves content stored within the grace period alone even when
+// nothing refers to it, since its attachment may be about to be created.
+// Storing content again restarts its grace period, so uploads deduplicated
+// against content about to be swept are safe too.
+func (s *Service) Sweep() (int, error) {
+	attachments, err := s.repo.All()
+	if err != nil {
+		return 0, err
+	}
+	referenced := make(map[string]bool)
+	for _, attachment := range attachments {
+		_, err := s.todos.ForWorkspace(attachment.WorkspaceID).Get(attachment.TodoID)
+		if errors.Is(err, service.ErrNotFound) {
+			err = s.repo.ForWorkspace(attachment.WorkspaceID).Delete(attachment.ID)
+			if err != nil && !errors.Is(err, repo.ErrNotFound) {
+				return 0, err
+			}
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		referenced[attachment.BlobKey] = true
+	}
+
+	blobs, err := s.blobs.List()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := timeNow().Add(-s.grace)
+	deleted := 0
+	for _, info := range blobs {
+		if referenced[info.Key] || info.ModTime.After(cutoff) {
+			continue
+		}
+		if err := s.blobs.Delete(info.Key); err != nil && !errors.Is(err, blob.ErrNotFound) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// get returns an attachment along with its todo, which the actor must be
+// able to read. Attachments of other todos are not found.
+func (s *Service) get(todoID, id int) (*entity.Todo, *entity.Attachment, error) {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.repo.Get(id)
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+	if attachment.TodoID != todoID {
+		return nil, nil, service.ErrNotFound
+	}
+	return todo, attachment, nil
+}
+
+// maxReader fails with entity.ErrAttachmentTooLarge once r has more than n
+// bytes, rather than silently cutting the file short like io.LimitReader.
+type maxReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *maxReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	if int64(n) > r.n {
+		return int(r.n), entity.ErrAttachmentTooLarge
+	}
+	r.n -= int64(n)
+	return n, err
+}
+
+func mapError(err error) error {
+	if errors.Is(err, repo.ErrNotFound) || errors.Is(err, blob.ErrNotFound) {
+		return service.ErrNotFound
+	}
+	return err
+}
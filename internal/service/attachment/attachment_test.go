@@ -0,0 +1,243 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/blob"
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	repomocks "github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockErr = errors.New("something wrong")
+)
+
+// pngHeader is enough of a PNG for its content type to be sniffed.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+type attachmentSuite struct {
+	suite.Suite
+	srv      *Service
+	blobs    blob.Store
+	mockRepo *repomocks.MockAttachment
+	mockTodo *mocks.MockTodo
+}
+
+func (s *attachmentSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = repomocks.NewMockAttachment(ctrl)
+	s.mockTodo = mocks.NewMockTodo(ctrl)
+	var err error
+	s.blobs, err = blob.NewFileStore(s.T().TempDir())
+	s.Require().NoError(err)
+	s.srv = NewService(s.mockRepo, s.blobs, s.mockTodo, WithLimits(Limits{MaxSize: 16, MaxPerTodo: 2})).(*Service)
+}
+
+func TestAttachmentSuite(t *testing.T) {
+	suite.Run(t, new(attachmentSuite))
+}
+
+// as scopes the service to actor, with the todo service as it would be.
+func (s *attachmentSuite) as(actor entity.Actor) service.Attachment {
+	s.mockTodo.EXPECT().As(actor).Return(s.mockTodo).Times(1)
+	return s.srv.As(actor)
+}
+
+func (s *attachmentSuite) TestUpload() {
+	todo := &entity.Todo{ID: 1, ProjectID: 2}
+	editor := entity.Actor{UserID: 3, Role: entity.RoleEditor}
+
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return([]entity.Attachment{{ID: 1}}, nil).Times(1)
+		want := &entity.Attachment{ID: 2}
+		s.mockRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(input entity.CreateAttachmentInput) (*entity.Attachment, error) {
+			s.Equal(1, input.TodoID)
+			s.Equal(3, input.UploaderID)
+			s.Equal("shot.png", input.Name)
+			s.Equal("image/png", input.ContentType, "sniffed from the content")
+			s.Equal(int64(len(pngHeader)), input.Size)
+			s.True(blob.ValidKey(input.BlobKey))
+			return want, nil
+		}).Times(1)
+
+		got, err := s.as(editor).Upload(1, `C:\Users\me\shot.png`, strings.NewReader(pngHeader))
+		s.Require().NoError(err)
+		s.Equal(want, got)
+	})
+	s.Run("viewer", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+
+		_, err := s.as(entity.Actor{UserID: 3, Role: entity.RoleViewer}).Upload(1, "a.txt", strings.NewReader("hi"))
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("too many", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(make([]entity.Attachment, 2), nil).Times(1)
+
+		_, err := s.srv.Upload(1, "a.txt", strings.NewReader("hi"))
+		s.ErrorIs(err, service.ErrQuotaExceeded)
+		s.ErrorIs(err, entity.ErrTooManyAttachments)
+	})
+	s.Run("too large", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(nil, nil).Times(1)
+
+		_, err := s.srv.Upload(1, "a.txt", strings.NewReader(strings.Repeat("x", 17)))
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.EqualError(err, "invalid input: attachment is too large, at most 16 bytes are allowed")
+		blobs, err := s.blobs.List()
+		s.Require().NoError(err)
+		s.Empty(blobs, "nothing is stored")
+	})
+	s.Run("at the limit", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(nil, nil).Times(1)
+		s.mockRepo.EXPECT().Create(gomock.Any()).Return(&entity.Attachment{}, nil).Times(1)
+
+		_, err := s.srv.Upload(1, "a.txt", strings.NewReader(strings.Repeat("x", 16)))
+		s.NoError(err)
+	})
+	s.Run("same content is stored once", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(2)
+		s.mockRepo.EXPECT().List(1).Return(nil, nil).Times(2)
+		var keys []string
+		s.mockRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(input entity.CreateAttachmentInput) (*entity.Attachment, error) {
+			keys = append(keys, input.BlobKey)
+			return &entity.Attachment{}, nil
+		}).Times(2)
+
+		_, err := s.srv.Upload(1, "a.txt", strings.NewReader("hi"))
+		s.Require().NoError(err)
+		_, err = s.srv.Upload(1, "b.txt", strings.NewReader("hi"))
+		s.Require().NoError(err)
+		s.Equal(keys[0], keys[1])
+		blobs, err := s.blobs.List()
+		s.Require().NoError(err)
+		s.Len(blobs, 1)
+	})
+	s.Run("todo not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(nil, service.ErrNotFound).Times(1)
+
+		_, err := s.srv.Upload(1, "a.txt", strings.NewReader("hi"))
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *attachmentSuite) TestOpen() {
+	s.Run("success", func() {
+		info, err := s.blobs.Put(strings.NewReader("hello"))
+		s.Require().NoError(err)
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Attachment{ID: 2, TodoID: 1, BlobKey: info.Key}, nil).Times(1)
+
+		_, content, err := s.srv.Open(1, 2)
+		s.Require().NoError(err)
+		defer content.Close()
+		b, err := io.ReadAll(content)
+		s.Require().NoError(err)
+		s.Equal("hello", string(b))
+	})
+	s.Run("attachment of another todo", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Attachment{ID: 2, TodoID: 3}, nil).Times(1)
+
+		_, _, err := s.srv.Open(1, 2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *attachmentSuite) TestDelete() {
+	todo := &entity.Todo{ID: 1, ProjectID: 2}
+	attachment := &entity.Attachment{ID: 3, TodoID: 1, UploaderID: 4}
+
+	tests := []struct {
+		desc    string
+		actor   entity.Actor
+		wantErr error
+	}{
+		{desc: "uploader", actor: entity.Actor{UserID: 4, Role: entity.RoleEditor}},
+		{desc: "owner", actor: entity.Actor{UserID: 5, Role: entity.RoleOwner}},
+		{desc: "editor", actor: entity.Actor{UserID: 5, Role: entity.RoleEditor}, wantErr: service.ErrForbidden},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+			s.mockRepo.EXPECT().Get(3).Return(attachment, nil).Times(1)
+			if tt.wantErr == nil {
+				s.mockRepo.EXPECT().Delete(3).Return(nil).Times(1)
+			}
+
+			err := s.as(tt.actor).Delete(1, 3)
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+				return
+			}
+			s.NoError(err)
+		})
+	}
+}
+
+func (s *attachmentSuite) TestSweep() {
+	s.Run("deletes what deleted todos left", func() {
+		kept, err := s.blobs.Put(strings.NewReader("kept"))
+		s.Require().NoError(err)
+		shared, err := s.blobs.Put(strings.NewReader("shared"))
+		s.Require().NoError(err)
+		orphan, err := s.blobs.Put(strings.NewReader("orphan"))
+		s.Require().NoError(err)
+		timeNow = func() time.Time {
+			return time.Now().Add(DefaultSweepGrace + time.Minute)
+		}
+		defer func() { timeNow = time.Now }()
+
+		s.mockRepo.EXPECT().All().Return([]entity.Attachment{
+			{ID: 1, WorkspaceID: 1, TodoID: 1, BlobKey: kept.Key},
+			{ID: 2, WorkspaceID: 1, TodoID: 2, BlobKey: shared.Key},
+			{ID: 1, WorkspaceID: 2, TodoID: 1, BlobKey: shared.Key},
+			{ID: 3, WorkspaceID: 1, TodoID: 3, BlobKey: orphan.Key},
+		}, nil).Times(1)
+		s.mockTodo.EXPECT().ForWorkspace(1).Return(s.mockTodo).Times(3)
+		s.mockTodo.EXPECT().ForWorkspace(2).Return(s.mockTodo).Times(1)
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{}, nil).Times(2)
+		s.mockTodo.EXPECT().Get(2).Return(nil, service.ErrNotFound).Times(1)
+		s.mockTodo.EXPECT().Get(3).Return(nil, service.ErrNotFound).Times(1)
+		s.mockRepo.EXPECT().ForWorkspace(1).Return(s.mockRepo).Times(2)
+		s.mockRepo.EXPECT().Delete(2).Return(nil).Times(1)
+		s.mockRepo.EXPECT().Delete(3).Return(repo.ErrNotFound).Times(1)
+
+		deleted, err := s.srv.Sweep()
+		s.Require().NoError(err)
+		s.Equal(1, deleted)
+		_, _, err = s.blobs.Open(orphan.Key)
+		s.ErrorIs(err, blob.ErrNotFound)
+		for _, key := range []string{kept.Key, shared.Key} {
+			_, _, err = s.blobs.Open(key)
+			s.NoError(err, "content another todo refers to is kept")
+		}
+	})
+	s.Run("recent content is kept", func() {
+		_, err := s.blobs.Put(strings.NewReader("uploading"))
+		s.Require().NoError(err)
+		s.mockRepo.EXPECT().All().Return(nil, nil).Times(1)
+
+		deleted, err := s.srv.Sweep()
+		s.Require().NoError(err)
+		s.Zero(deleted)
+	})
+	s.Run("repo failed", func() {
+		s.mockRepo.EXPECT().All().Return(nil, mockErr).Times(1)
+
+		_, err := s.srv.Sweep()
+		s.ErrorIs(err, mockErr)
+	})
+}
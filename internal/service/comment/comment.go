@@ -0,0 +1,273 @@
+package comment
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/policy"
+)
+
+// Notifier tells users they were mentioned. It is called once the comment
+// is saved, and cannot fail the call that saved it.
+type Notifier interface {
+	Mentioned(mention entity.Mention)
+}
+
+// NotifierFunc adapts a function to Notifier.
+type NotifierFunc func(mention entity.Mention)
+
+func (f NotifierFunc) Mentioned(mention entity.Mention) {
+	f(mention)
+}
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier logs mentions rather than delivering them.
+func NewLogNotifier(logger *log.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Mentioned(mention entity.Mention) {
+	n.logger.Printf("mention: user %d in comment %d on todo %d", mention.UserID, mention.Comment.ID, mention.Comment.TodoID)
+}
+
+// Service manages comments. Todos are reached through the todo service, so
+// a comment is only as visible as its todo.
+type Service struct {
+	repo       repo.Comment
+	history    repo.History
+	todos      service.Todo
+	users      repo.User
+	workspaces service.Workspace
+	notifier   Notifier
+	// actor is who the service is scoped to, or nil for the unscoped
+	// service.
+	actor *entity.Actor
+}
+
+func NewService(repo repo.Comment, history repo.History, todos service.Todo, users repo.User, workspaces service.Workspace, notifier Notifier) service.Comment {
+	return &Service{
+		repo:       repo,
+		history:    history,
+		todos:      todos,
+		users:      users,
+		workspaces: workspaces,
+		notifier:   notifier,
+	}
+}
+
+// ForWorkspace returns a copy of the service working on the comments and
+// todos of another workspace.
+func (s *Service) ForWorkspace(workspaceID int) service.Comment {
+	scoped := *s
+	scoped.repo = s.repo.ForWorkspace(workspaceID)
+	scoped.history = s.history.ForWorkspace(workspaceID)
+	scoped.todos = s.todos.ForWorkspace(workspaceID)
+	return &scoped
+}
+
+// As scopes a copy of the service, and the todo service it uses, to actor.
+func (s *Service) As(actor entity.Actor) service.Comment {
+	scoped := *s
+	scoped.actor = &actor
+	scoped.todos = s.todos.As(actor)
+	return &scoped
+}
+
+func (s *Service) Create(todoID int, body string) (*entity.Comment, error) {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return nil, err
+	}
+	body, err = entity.NormalizeComment(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	mentions, err := s.mentions(*todo, body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.repo.Create(entity.CreateCommentInput{
+		TodoID:   todoID,
+		AuthorID: s.actorID(),
+		Body:     body,
+		Mentions: mentions,
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	s.notify(*comment, nil)
+	return comment, nil
+}
+
+func (s *Service) List(todoID int, query entity.PageQuery) (*entity.Page[entity.Comment], error) {
+	if _, err := s.todos.Get(todoID); err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.List(todoID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	page, err := entity.Paginate(comments, query, commentKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	return page, nil
+}
+
+func (s *Service) Get(todoID, id int) (*entity.Comment, error) {
+	_, comment, err := s.get(todoID, id)
+	return comment, err
+}
+
+func (s *Service) Update(todoID, id int, body string) (*entity.Comment, error) {
+	todo, comment, err := s.get(todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.actor != nil && comment.AuthorID != s.actor.UserID {
+		return nil, fmt.Errorf("%w: only the author may edit a comment", service.ErrForbidden)
+	}
+	body, err = entity.NormalizeComment(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	mentions, err := s.mentions(*todo, body)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(id, entity.UpdateCommentInput{Body: body, Mentions: mentions})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	s.notify(*updated, comment.Mentions)
+	return updated, nil
+}
+
+func (s *Service) Delete(todoID, id int) error {
+	todo, comment, err := s.get(todoID, id)
+	if err != nil {
+		return err
+	}
+	if s.actor != nil && comment.AuthorID != s.actor.UserID {
+		resource := policy.Resource{OwnerID: comment.AuthorID, ProjectID: todo.ProjectID}
+		if !policy.Allowed(*s.actor, entity.ActionDelete, resource) {
+			return service.ErrForbidden
+		}
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *Service) Activity(todoID int, query entity.PageQuery) (*entity.Page[entity.Activity], error) {
+	if _, err := s.todos.Get(todoID); err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.List(todoID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	changes, err := s.history.List(todoID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	feed := make([]entity.Activity, 0, len(comments)+len(changes))
+	for _, comment := range comments {
+		feed = append(feed, entity.CommentActivity(comment))
+	}
+	for _, change := range changes {
+		feed = append(feed, entity.ChangeActivity(change))
+	}
+	slices.SortFunc(feed, func(a, b entity.Activity) int {
+		return strings.Compare(a.Key(), b.Key())
+	})
+
+	page, err := entity.Paginate(feed, query, entity.Activity.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	return page, nil
+}
+
+// get returns a comment along with its todo, which the actor must be able
+// to read. Comments on other todos are not found.
+func (s *Service) get(todoID, id int) (*entity.Todo, *entity.Comment, error) {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	comment, err := s.repo.Get(id)
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+	if comment.TodoID != todoID {
+		return nil, nil, service.ErrNotFound
+	}
+	return todo, comment, nil
+}
+
+// mentions returns the IDs of the users mentioned in body who may read the
+// todo. Emails of nobody, or of users who could not follow the mention, are
+// left as plain text.
+func (s *Service) mentions(todo entity.Todo, body string) ([]int, error) {
+	var ids []int
+	for _, email := range entity.ParseMentions(body) {
+		user, err := s.users.GetByEmail(email)
+		if errors.Is(err, repo.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		actor, err := s.workspaces.Resolve(user.ID, todo.WorkspaceID)
+		if errors.Is(err, service.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if policy.Allowed(*actor, entity.ActionRead, policy.Todo(todo)) {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// notify tells the users a comment mentions, except for its author and
+// those in notified, who were told before.
+func (s *Service) notify(comment entity.Comment, notified []int) {
+	for _, userID := range comment.Mentions {
+		if userID == comment.AuthorID || slices.Contains(notified, userID) {
+			continue
+		}
+		s.notifier.Mentioned(entity.Mention{UserID: userID, Comment: comment})
+	}
+}
+
+func (s *Service) actorID() int {
+	if s.actor == nil {
+		return 0
+	}
+	return s.actor.UserID
+}
+
+// commentKey orders comments by ID, which is the order they were created in.
+func commentKey(comment entity.Comment) string {
+	return fmt.Sprintf("%010d", comment.ID)
+}
+
+func mapError(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return service.ErrNotFound
+	}
+	return err
+}
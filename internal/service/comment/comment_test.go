@@ -0,0 +1,261 @@
+package comment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	repomocks "github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockErr = errors.New("something wrong")
+)
+
+type commentSuite struct {
+	suite.Suite
+	srv         service.Comment
+	mockRepo    *repomocks.MockComment
+	mockHistory *repomocks.MockHistory
+	mockTodo    *mocks.MockTodo
+	mockUser    *repomocks.MockUser
+	mockWS      *mocks.MockWorkspace
+	mentioned   []entity.Mention
+}
+
+func (s *commentSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = repomocks.NewMockComment(ctrl)
+	s.mockHistory = repomocks.NewMockHistory(ctrl)
+	s.mockTodo = mocks.NewMockTodo(ctrl)
+	s.mockUser = repomocks.NewMockUser(ctrl)
+	s.mockWS = mocks.NewMockWorkspace(ctrl)
+	s.mentioned = nil
+	notifier := NotifierFunc(func(mention entity.Mention) {
+		s.mentioned = append(s.mentioned, mention)
+	})
+	s.srv = NewService(s.mockRepo, s.mockHistory, s.mockTodo, s.mockUser, s.mockWS, notifier)
+}
+
+func TestCommentSuite(t *testing.T) {
+	suite.Run(t, new(commentSuite))
+}
+
+// as scopes the service to actor, with the todo service as it would be.
+func (s *commentSuite) as(actor entity.Actor) service.Comment {
+	s.mockTodo.EXPECT().As(actor).Return(s.mockTodo).Times(1)
+	return s.srv.As(actor)
+}
+
+func (s *commentSuite) TestCreate() {
+	todo := &entity.Todo{ID: 1, WorkspaceID: 2, ProjectID: 3}
+	author := entity.Actor{UserID: 1, WorkspaceID: 2, Role: entity.RoleViewer}
+
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockUser.EXPECT().GetByEmail("jane@example.com").Return(&entity.User{ID: 4}, nil).Times(1)
+		s.mockWS.EXPECT().Resolve(4, 2).Return(&entity.Actor{UserID: 4, WorkspaceID: 2, Role: entity.RoleViewer}, nil).Times(1)
+		s.mockUser.EXPECT().GetByEmail("nobody@example.com").Return(nil, repo.ErrNotFound).Times(1)
+		s.mockUser.EXPECT().GetByEmail("stranger@example.com").Return(&entity.User{ID: 5}, nil).Times(1)
+		s.mockWS.EXPECT().Resolve(5, 2).Return(nil, service.ErrNotFound).Times(1)
+		s.mockUser.EXPECT().GetByEmail("guest@example.com").Return(&entity.User{ID: 6}, nil).Times(1)
+		s.mockWS.EXPECT().Resolve(6, 2).Return(&entity.Actor{UserID: 6, WorkspaceID: 2, Role: entity.RoleGuest}, nil).Times(1)
+		s.mockUser.EXPECT().GetByEmail("me@example.com").Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockWS.EXPECT().Resolve(1, 2).Return(&author, nil).Times(1)
+		want := &entity.Comment{ID: 7, TodoID: 1, AuthorID: 1, Body: "hi", Mentions: []int{4, 1}}
+		s.mockRepo.EXPECT().Create(entity.CreateCommentInput{
+			TodoID:   1,
+			AuthorID: 1,
+			Body:     "@Jane@example.com @nobody@example.com @stranger@example.com @guest@example.com @me@example.com",
+			Mentions: []int{4, 1},
+		}).Return(want, nil).Times(1)
+
+		got, err := s.as(author).Create(1, "  @Jane@example.com @nobody@example.com @stranger@example.com @guest@example.com @me@example.com\n")
+		s.Require().NoError(err)
+		s.Equal(want, got)
+		s.Equal([]entity.Mention{{UserID: 4, Comment: *want}}, s.mentioned, "only readers other than the author are notified")
+	})
+	s.Run("todo not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(nil, service.ErrNotFound).Times(1)
+
+		_, err := s.srv.Create(1, "hi")
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("empty body", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+
+		_, err := s.srv.Create(1, " \n ")
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrEmptyComment)
+	})
+	s.Run("repo failed", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, mockErr).Times(1)
+
+		_, err := s.srv.Create(1, "hi")
+		s.ErrorIs(err, mockErr)
+		s.Empty(s.mentioned)
+	})
+}
+
+func (s *commentSuite) TestList() {
+	comments := lo.Map(lo.Range(3), func(i, _ int) entity.Comment {
+		return entity.Comment{ID: i + 1, TodoID: 1}
+	})
+
+	s.Run("pages", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(2)
+		s.mockRepo.EXPECT().List(1).Return(comments, nil).Times(2)
+
+		page, err := s.srv.List(1, entity.PageQuery{Limit: 2})
+		s.Require().NoError(err)
+		s.Equal(comments[:2], page.Items)
+		s.NotEmpty(page.NextCursor)
+
+		page, err = s.srv.List(1, entity.PageQuery{Cursor: page.NextCursor, Limit: 2})
+		s.Require().NoError(err)
+		s.Equal(comments[2:], page.Items)
+		s.Empty(page.NextCursor)
+	})
+	s.Run("invalid cursor", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(comments, nil).Times(1)
+
+		_, err := s.srv.List(1, entity.PageQuery{Cursor: "!"})
+		s.ErrorIs(err, service.ErrInvalidInput)
+	})
+	s.Run("todo not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(nil, service.ErrNotFound).Times(1)
+
+		_, err := s.srv.List(1, entity.PageQuery{})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *commentSuite) TestGet() {
+	s.Run("comment on another todo", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Comment{ID: 2, TodoID: 3}, nil).Times(1)
+
+		_, err := s.srv.Get(1, 2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("comment not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(2).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Get(1, 2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *commentSuite) TestUpdate() {
+	todo := &entity.Todo{ID: 1, WorkspaceID: 2}
+	comment := &entity.Comment{ID: 3, TodoID: 1, AuthorID: 1, Body: "hi @jane@example.com", Mentions: []int{4}}
+
+	s.Run("notifies new mentions", func() {
+		author := entity.Actor{UserID: 1, WorkspaceID: 2, Role: entity.RoleViewer}
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(3).Return(comment, nil).Times(1)
+		for id, email := range map[int]string{4: "jane@example.com", 5: "joe@example.com"} {
+			s.mockUser.EXPECT().GetByEmail(email).Return(&entity.User{ID: id}, nil).Times(1)
+			s.mockWS.EXPECT().Resolve(id, 2).Return(&entity.Actor{UserID: id, WorkspaceID: 2, Role: entity.RoleViewer}, nil).Times(1)
+		}
+		want := &entity.Comment{ID: 3, TodoID: 1, AuthorID: 1, Body: "hi @jane@example.com @joe@example.com", Mentions: []int{4, 5}}
+		s.mockRepo.EXPECT().Update(3, entity.UpdateCommentInput{Body: want.Body, Mentions: []int{4, 5}}).Return(want, nil).Times(1)
+
+		got, err := s.as(author).Update(1, 3, want.Body)
+		s.Require().NoError(err)
+		s.Equal(want, got)
+		s.Equal([]entity.Mention{{UserID: 5, Comment: *want}}, s.mentioned)
+	})
+	s.Run("only the author", func() {
+		owner := entity.Actor{UserID: 9, WorkspaceID: 2, Role: entity.RoleOwner}
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(3).Return(comment, nil).Times(1)
+
+		_, err := s.as(owner).Update(1, 3, "mine now")
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("empty body", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(3).Return(comment, nil).Times(1)
+
+		_, err := s.srv.Update(1, 3, "")
+		s.ErrorIs(err, entity.ErrEmptyComment)
+	})
+}
+
+func (s *commentSuite) TestDelete() {
+	todo := &entity.Todo{ID: 1, WorkspaceID: 2, ProjectID: 3}
+	comment := &entity.Comment{ID: 4, TodoID: 1, AuthorID: 1}
+
+	tests := []struct {
+		desc    string
+		actor   entity.Actor
+		wantErr error
+	}{
+		{desc: "author", actor: entity.Actor{UserID: 1, Role: entity.RoleViewer}},
+		{desc: "workspace owner", actor: entity.Actor{UserID: 2, Role: entity.RoleOwner}},
+		{desc: "project owner", actor: entity.Actor{UserID: 2, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{3: entity.RoleOwner}}},
+		{desc: "editor", actor: entity.Actor{UserID: 2, Role: entity.RoleEditor}, wantErr: service.ErrForbidden},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+			s.mockRepo.EXPECT().Get(4).Return(comment, nil).Times(1)
+			if tt.wantErr == nil {
+				s.mockRepo.EXPECT().Delete(4).Return(nil).Times(1)
+			}
+
+			err := s.as(tt.actor).Delete(1, 4)
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+				return
+			}
+			s.NoError(err)
+		})
+	}
+}
+
+func (s *commentSuite) TestActivity() {
+	at := time.Unix(123456789, 0)
+	comments := []entity.Comment{
+		{ID: 1, TodoID: 1, CreatedAt: at.Add(time.Minute)},
+		{ID: 2, TodoID: 1, CreatedAt: at.Add(3 * time.Minute)},
+	}
+	changes := []entity.TodoChange{
+		{ID: 1, TodoID: 1, At: at},
+		{ID: 2, TodoID: 1, At: at.Add(time.Minute)},
+	}
+
+	s.Run("merged oldest first", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(comments, nil).Times(1)
+		s.mockHistory.EXPECT().List(1).Return(changes, nil).Times(1)
+
+		page, err := s.srv.Activity(1, entity.PageQuery{})
+		s.Require().NoError(err)
+		s.Equal([]entity.Activity{
+			entity.ChangeActivity(changes[0]),
+			entity.ChangeActivity(changes[1]),
+			entity.CommentActivity(comments[0]),
+			entity.CommentActivity(comments[1]),
+		}, page.Items, "changes come before comments made at the same time")
+	})
+	s.Run("history failed", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().List(1).Return(comments, nil).Times(1)
+		s.mockHistory.EXPECT().List(1).Return(nil, mockErr).Times(1)
+
+		_, err := s.srv.Activity(1, entity.PageQuery{})
+		s.ErrorIs(err, mockErr)
+	})
+}
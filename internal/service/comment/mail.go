@@ -0,0 +1,49 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/mail"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+var timeNow = time.Now
+
+type mailNotifier struct {
+	users  repo.User
+	cfg    mail.Config
+	logger *log.Logger
+}
+
+// NewMailNotifier mails each mentioned user at their own address, and no
+// one else. Mail is sent in the background so a slow server does not hold
+// up the comment, and failures are logged since they cannot fail it.
+func NewMailNotifier(users repo.User, cfg mail.Config, logger *log.Logger) Notifier {
+	return &mailNotifier{users: users, cfg: cfg, logger: logger}
+}
+
+func (n *mailNotifier) Mentioned(mention entity.Mention) {
+	go func() {
+		if err := n.send(mention); err != nil {
+			n.logger.Printf("mention: user %d in comment %d on todo %d: %v", mention.UserID, mention.Comment.ID, mention.Comment.TodoID, err)
+		}
+	}()
+}
+
+func (n *mailNotifier) send(mention entity.Mention) error {
+	user, err := n.users.Get(mention.UserID)
+	if err != nil {
+		return err
+	}
+	comment := mention.Comment
+	return mail.Send(context.Background(), n.cfg, mail.Message{
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("You were mentioned on todo %d", comment.TodoID),
+		Body:    fmt.Sprintf("%s\r\n\r\nComment %d on todo %d of workspace %d.\r\n", comment.Body, comment.ID, comment.TodoID, comment.WorkspaceID),
+		Date:    timeNow(),
+	})
+}
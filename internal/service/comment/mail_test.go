@@ -0,0 +1,92 @@
+package comment
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/mail"
+	"github.com/cloudingcity/todo/internal/mail/mailtest"
+	repomocks "github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+// syncBuffer is written by the notifier's goroutine while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+type mailSuite struct {
+	suite.Suite
+	srv      *mailtest.Server
+	mockUser *repomocks.MockUser
+	logs     *syncBuffer
+	notifier Notifier
+	mention  entity.Mention
+}
+
+func (s *mailSuite) SetupSubTest() {
+	timeNow = func() time.Time {
+		return time.Unix(123456789, 0).UTC()
+	}
+	s.srv = mailtest.NewServer(s.T())
+	s.mockUser = repomocks.NewMockUser(gomock.NewController(s.T()))
+	s.logs = &syncBuffer{}
+	s.notifier = NewMailNotifier(s.mockUser, mail.Config{Addr: s.srv.Addr(), From: "todo@example.com"}, log.New(s.logs, "", 0))
+	s.mention = entity.Mention{UserID: 2, Comment: entity.Comment{ID: 3, WorkspaceID: 4, TodoID: 5, AuthorID: 1, Body: "@bob@example.com take a look"}}
+}
+
+func (s *mailSuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestMailSuite(t *testing.T) {
+	suite.Run(t, new(mailSuite))
+}
+
+func (s *mailSuite) TestMentioned() {
+	s.Run("mails the mentioned user", func() {
+		s.mockUser.EXPECT().Get(2).Return(&entity.User{ID: 2, Email: "bob@example.com"}, nil).Times(1)
+
+		s.notifier.Mentioned(s.mention)
+		s.Eventually(func() bool { return len(s.srv.Messages()) == 1 }, time.Second, time.Millisecond)
+		s.Equal([]string{"<bob@example.com>"}, s.srv.To())
+		msg := s.srv.Messages()[0]
+		s.Contains(msg, "Subject: You were mentioned on todo 5\n")
+		s.Contains(msg, "Date: Thu, 29 Nov 1973 21:33:09 +0000\n")
+		s.Contains(msg, "\n\n@bob@example.com take a look\n\nComment 3 on todo 5 of workspace 4.\n")
+	})
+	s.Run("unknown user is logged", func() {
+		s.mockUser.EXPECT().Get(2).Return(nil, mockErr).Times(1)
+
+		s.notifier.Mentioned(s.mention)
+		s.Eventually(func() bool { return s.logs.String() != "" }, time.Second, time.Millisecond)
+		s.Equal("mention: user 2 in comment 3 on todo 5: something wrong\n", s.logs.String())
+		s.Empty(s.srv.Messages())
+	})
+	s.Run("failed delivery is logged", func() {
+		s.mockUser.EXPECT().Get(2).Return(&entity.User{ID: 2, Email: "bob@example.com"}, nil).Times(1)
+		s.srv.RejectRcpt("bob@example.com")
+
+		s.notifier.Mentioned(s.mention)
+		s.Eventually(func() bool { return s.logs.String() != "" }, time.Second, time.Millisecond)
+		s.Contains(s.logs.String(), "no such user")
+	})
+}
@@ -10,9 +10,13 @@
 package mocks
 
 import (
+	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/cloudingcity/todo/internal/entity"
+	service "github.com/cloudingcity/todo/internal/service"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,33 +44,225 @@ func (m *MockTodo) EXPECT() *MockTodoMockRecorder {
 	return m.recorder
 }
 
+// AddChecklistItem mocks base method.
+func (m *MockTodo) AddChecklistItem(todoID int, text string) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChecklistItem", todoID, text)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddChecklistItem indicates an expected call of AddChecklistItem.
+func (mr *MockTodoMockRecorder) AddChecklistItem(todoID, text any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChecklistItem", reflect.TypeOf((*MockTodo)(nil).AddChecklistItem), todoID, text)
+}
+
+// AddDependency mocks base method.
+func (m *MockTodo) AddDependency(todoID, blockerID int) (*entity.Dependency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDependency", todoID, blockerID)
+	ret0, _ := ret[0].(*entity.Dependency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddDependency indicates an expected call of AddDependency.
+func (mr *MockTodoMockRecorder) AddDependency(todoID, blockerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDependency", reflect.TypeOf((*MockTodo)(nil).AddDependency), todoID, blockerID)
+}
+
+// As mocks base method.
+func (m *MockTodo) As(actor entity.Actor) service.Todo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(service.Todo)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockTodoMockRecorder) As(actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockTodo)(nil).As), actor)
+}
+
+// AttachTags mocks base method.
+func (m *MockTodo) AttachTags(todoID int, names []string) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTags", todoID, names)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachTags indicates an expected call of AttachTags.
+func (mr *MockTodoMockRecorder) AttachTags(todoID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTags", reflect.TypeOf((*MockTodo)(nil).AttachTags), todoID, names)
+}
+
+// BatchCreate mocks base method.
+func (m *MockTodo) BatchCreate(inputs []entity.CreateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreate", inputs, mode)
+	ret0, _ := ret[0].([]entity.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCreate indicates an expected call of BatchCreate.
+func (mr *MockTodoMockRecorder) BatchCreate(inputs, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockTodo)(nil).BatchCreate), inputs, mode)
+}
+
+// BatchDelete mocks base method.
+func (m *MockTodo) BatchDelete(ids []int, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", ids, mode)
+	ret0, _ := ret[0].([]entity.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockTodoMockRecorder) BatchDelete(ids, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockTodo)(nil).BatchDelete), ids, mode)
+}
+
+// BatchUpdate mocks base method.
+func (m *MockTodo) BatchUpdate(inputs []entity.BatchUpdateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdate", inputs, mode)
+	ret0, _ := ret[0].([]entity.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchUpdate indicates an expected call of BatchUpdate.
+func (mr *MockTodoMockRecorder) BatchUpdate(inputs, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdate", reflect.TypeOf((*MockTodo)(nil).BatchUpdate), inputs, mode)
+}
+
 // Create mocks base method.
-func (m *MockTodo) Create(title, description string) (*entity.Todo, error) {
+func (m *MockTodo) Create(input entity.CreateTodoInput) (*entity.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", title, description)
+	ret := m.ctrl.Call(m, "Create", input)
 	ret0, _ := ret[0].(*entity.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTodoMockRecorder) Create(title, description any) *gomock.Call {
+func (mr *MockTodoMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodo)(nil).Create), input)
+}
+
+// CreateTag mocks base method.
+func (m *MockTodo) CreateTag(name string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", name)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTodoMockRecorder) CreateTag(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTodo)(nil).CreateTag), name)
+}
+
+// CriticalPath mocks base method.
+func (m *MockTodo) CriticalPath(projectID int) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CriticalPath", projectID)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CriticalPath indicates an expected call of CriticalPath.
+func (mr *MockTodoMockRecorder) CriticalPath(projectID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodo)(nil).Create), title, description)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CriticalPath", reflect.TypeOf((*MockTodo)(nil).CriticalPath), projectID)
 }
 
 // Delete mocks base method.
-func (m *MockTodo) Delete(id int) error {
+func (m *MockTodo) Delete(id int, mode entity.SubtaskDeleteMode) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", id, mode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTodoMockRecorder) Delete(id any) *gomock.Call {
+func (mr *MockTodoMockRecorder) Delete(id, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodo)(nil).Delete), id, mode)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTodo) DeleteTag(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTodoMockRecorder) DeleteTag(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTodo)(nil).DeleteTag), id)
+}
+
+// Dependencies mocks base method.
+func (m *MockTodo) Dependencies(todoID int) ([]entity.Dependency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dependencies", todoID)
+	ret0, _ := ret[0].([]entity.Dependency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dependencies indicates an expected call of Dependencies.
+func (mr *MockTodoMockRecorder) Dependencies(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dependencies", reflect.TypeOf((*MockTodo)(nil).Dependencies), todoID)
+}
+
+// DetachTags mocks base method.
+func (m *MockTodo) DetachTags(todoID int, names []string) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTags", todoID, names)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetachTags indicates an expected call of DetachTags.
+func (mr *MockTodoMockRecorder) DetachTags(todoID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTags", reflect.TypeOf((*MockTodo)(nil).DetachTags), todoID, names)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockTodo) ForWorkspace(workspaceID int) service.Todo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(service.Todo)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockTodoMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodo)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockTodo)(nil).ForWorkspace), workspaceID)
 }
 
 // Get mocks base method.
@@ -84,19 +280,213 @@ func (mr *MockTodoMockRecorder) Get(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTodo)(nil).Get), id)
 }
 
+// GetTag mocks base method.
+func (m *MockTodo) GetTag(id int) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", id)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockTodoMockRecorder) GetTag(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockTodo)(nil).GetTag), id)
+}
+
 // List mocks base method.
-func (m *MockTodo) List() ([]entity.Todo, error) {
+func (m *MockTodo) List(query entity.TodoQuery) ([]entity.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List")
+	ret := m.ctrl.Call(m, "List", query)
 	ret0, _ := ret[0].([]entity.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockTodoMockRecorder) List() *gomock.Call {
+func (mr *MockTodoMockRecorder) List(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodo)(nil).List), query)
+}
+
+// ListTags mocks base method.
+func (m *MockTodo) ListTags() ([]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags")
+	ret0, _ := ret[0].([]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockTodoMockRecorder) ListTags() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockTodo)(nil).ListTags))
+}
+
+// MergeTags mocks base method.
+func (m *MockTodo) MergeTags(sourceID, targetID int) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", sourceID, targetID)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeTags indicates an expected call of MergeTags.
+func (mr *MockTodoMockRecorder) MergeTags(sourceID, targetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockTodo)(nil).MergeTags), sourceID, targetID)
+}
+
+// Move mocks base method.
+func (m *MockTodo) Move(id int, input entity.MoveTodoInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockTodoMockRecorder) Move(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockTodo)(nil).Move), id, input)
+}
+
+// Occurrences mocks base method.
+func (m *MockTodo) Occurrences(id, n int) ([]entity.Occurrence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Occurrences", id, n)
+	ret0, _ := ret[0].([]entity.Occurrence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Occurrences indicates an expected call of Occurrences.
+func (mr *MockTodoMockRecorder) Occurrences(id, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Occurrences", reflect.TypeOf((*MockTodo)(nil).Occurrences), id, n)
+}
+
+// RemoveChecklistItem mocks base method.
+func (m *MockTodo) RemoveChecklistItem(todoID, itemID int) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveChecklistItem", todoID, itemID)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveChecklistItem indicates an expected call of RemoveChecklistItem.
+func (mr *MockTodoMockRecorder) RemoveChecklistItem(todoID, itemID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveChecklistItem", reflect.TypeOf((*MockTodo)(nil).RemoveChecklistItem), todoID, itemID)
+}
+
+// RemoveDependency mocks base method.
+func (m *MockTodo) RemoveDependency(todoID, blockerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDependency", todoID, blockerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDependency indicates an expected call of RemoveDependency.
+func (mr *MockTodoMockRecorder) RemoveDependency(todoID, blockerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDependency", reflect.TypeOf((*MockTodo)(nil).RemoveDependency), todoID, blockerID)
+}
+
+// RenameTag mocks base method.
+func (m *MockTodo) RenameTag(id int, name string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", id, name)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockTodoMockRecorder) RenameTag(id, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockTodo)(nil).RenameTag), id, name)
+}
+
+// ReorderChecklist mocks base method.
+func (m *MockTodo) ReorderChecklist(todoID int, itemIDs []int) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderChecklist", todoID, itemIDs)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReorderChecklist indicates an expected call of ReorderChecklist.
+func (mr *MockTodoMockRecorder) ReorderChecklist(todoID, itemIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderChecklist", reflect.TypeOf((*MockTodo)(nil).ReorderChecklist), todoID, itemIDs)
+}
+
+// Replace mocks base method.
+func (m *MockTodo) Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockTodoMockRecorder) Replace(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockTodo)(nil).Replace), id, input)
+}
+
+// SetChecklistAutoComplete mocks base method.
+func (m *MockTodo) SetChecklistAutoComplete(todoID int, autoComplete bool) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChecklistAutoComplete", todoID, autoComplete)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetChecklistAutoComplete indicates an expected call of SetChecklistAutoComplete.
+func (mr *MockTodoMockRecorder) SetChecklistAutoComplete(todoID, autoComplete any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChecklistAutoComplete", reflect.TypeOf((*MockTodo)(nil).SetChecklistAutoComplete), todoID, autoComplete)
+}
+
+// SetParent mocks base method.
+func (m *MockTodo) SetParent(id, parentID int) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetParent", id, parentID)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetParent indicates an expected call of SetParent.
+func (mr *MockTodoMockRecorder) SetParent(id, parentID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodo)(nil).List))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParent", reflect.TypeOf((*MockTodo)(nil).SetParent), id, parentID)
+}
+
+// Subtree mocks base method.
+func (m *MockTodo) Subtree(id int) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subtree", id)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subtree indicates an expected call of Subtree.
+func (mr *MockTodoMockRecorder) Subtree(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subtree", reflect.TypeOf((*MockTodo)(nil).Subtree), id)
 }
 
 // Update mocks base method.
@@ -112,3 +502,1166 @@ func (mr *MockTodoMockRecorder) Update(id, input any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodo)(nil).Update), id, input)
 }
+
+// UpdateChecklistItem mocks base method.
+func (m *MockTodo) UpdateChecklistItem(todoID, itemID int, input entity.UpdateChecklistItemInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChecklistItem", todoID, itemID, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChecklistItem indicates an expected call of UpdateChecklistItem.
+func (mr *MockTodoMockRecorder) UpdateChecklistItem(todoID, itemID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChecklistItem", reflect.TypeOf((*MockTodo)(nil).UpdateChecklistItem), todoID, itemID, input)
+}
+
+// UpdateFunc mocks base method.
+func (m *MockTodo) UpdateFunc(id int, fn func(*entity.Todo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFunc", id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFunc indicates an expected call of UpdateFunc.
+func (mr *MockTodoMockRecorder) UpdateFunc(id, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFunc", reflect.TypeOf((*MockTodo)(nil).UpdateFunc), id, fn)
+}
+
+// Upsert mocks base method.
+func (m *MockTodo) Upsert(id int, input entity.ReplaceTodoInput) (*entity.Todo, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", id, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockTodoMockRecorder) Upsert(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTodo)(nil).Upsert), id, input)
+}
+
+// MockProject is a mock of Project interface.
+type MockProject struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectMockRecorder
+	isgomock struct{}
+}
+
+// MockProjectMockRecorder is the mock recorder for MockProject.
+type MockProjectMockRecorder struct {
+	mock *MockProject
+}
+
+// NewMockProject creates a new mock instance.
+func NewMockProject(ctrl *gomock.Controller) *MockProject {
+	mock := &MockProject{ctrl: ctrl}
+	mock.recorder = &MockProjectMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProject) EXPECT() *MockProjectMockRecorder {
+	return m.recorder
+}
+
+// Archive mocks base method.
+func (m *MockProject) Archive(id int) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", id)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockProjectMockRecorder) Archive(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockProject)(nil).Archive), id)
+}
+
+// As mocks base method.
+func (m *MockProject) As(actor entity.Actor) service.Project {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(service.Project)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockProjectMockRecorder) As(actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockProject)(nil).As), actor)
+}
+
+// Create mocks base method.
+func (m *MockProject) Create(input entity.CreateProjectInput) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", input)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProjectMockRecorder) Create(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProject)(nil).Create), input)
+}
+
+// CreateTodo mocks base method.
+func (m *MockProject) CreateTodo(projectID int, input entity.CreateTodoInput) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTodo", projectID, input)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTodo indicates an expected call of CreateTodo.
+func (mr *MockProjectMockRecorder) CreateTodo(projectID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTodo", reflect.TypeOf((*MockProject)(nil).CreateTodo), projectID, input)
+}
+
+// CriticalPath mocks base method.
+func (m *MockProject) CriticalPath(projectID int) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CriticalPath", projectID)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CriticalPath indicates an expected call of CriticalPath.
+func (mr *MockProjectMockRecorder) CriticalPath(projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CriticalPath", reflect.TypeOf((*MockProject)(nil).CriticalPath), projectID)
+}
+
+// Delete mocks base method.
+func (m *MockProject) Delete(id int, mode entity.ProjectDeleteMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectMockRecorder) Delete(id, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProject)(nil).Delete), id, mode)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockProject) ForWorkspace(workspaceID int) service.Project {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(service.Project)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockProjectMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockProject)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockProject) Get(id int) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", id)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProjectMockRecorder) Get(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProject)(nil).Get), id)
+}
+
+// List mocks base method.
+func (m *MockProject) List(includeArchived bool) ([]entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", includeArchived)
+	ret0, _ := ret[0].([]entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProjectMockRecorder) List(includeArchived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProject)(nil).List), includeArchived)
+}
+
+// ListTodos mocks base method.
+func (m *MockProject) ListTodos(projectID int, query entity.TodoQuery) ([]entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTodos", projectID, query)
+	ret0, _ := ret[0].([]entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTodos indicates an expected call of ListTodos.
+func (mr *MockProjectMockRecorder) ListTodos(projectID, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTodos", reflect.TypeOf((*MockProject)(nil).ListTodos), projectID, query)
+}
+
+// MoveTodo mocks base method.
+func (m *MockProject) MoveTodo(todoID, projectID int) (*entity.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTodo", todoID, projectID)
+	ret0, _ := ret[0].(*entity.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveTodo indicates an expected call of MoveTodo.
+func (mr *MockProjectMockRecorder) MoveTodo(todoID, projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTodo", reflect.TypeOf((*MockProject)(nil).MoveTodo), todoID, projectID)
+}
+
+// Share mocks base method.
+func (m *MockProject) Share(projectID int, email string, role entity.Role) (*entity.Member, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", projectID, email, role)
+	ret0, _ := ret[0].(*entity.Member)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockProjectMockRecorder) Share(projectID, email, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockProject)(nil).Share), projectID, email, role)
+}
+
+// Shares mocks base method.
+func (m *MockProject) Shares(projectID int) ([]entity.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shares", projectID)
+	ret0, _ := ret[0].([]entity.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Shares indicates an expected call of Shares.
+func (mr *MockProjectMockRecorder) Shares(projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shares", reflect.TypeOf((*MockProject)(nil).Shares), projectID)
+}
+
+// Unarchive mocks base method.
+func (m *MockProject) Unarchive(id int) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unarchive", id)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unarchive indicates an expected call of Unarchive.
+func (mr *MockProjectMockRecorder) Unarchive(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unarchive", reflect.TypeOf((*MockProject)(nil).Unarchive), id)
+}
+
+// Unshare mocks base method.
+func (m *MockProject) Unshare(projectID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", projectID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockProjectMockRecorder) Unshare(projectID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockProject)(nil).Unshare), projectID, userID)
+}
+
+// Update mocks base method.
+func (m *MockProject) Update(id int, input entity.UpdateProjectInput) (*entity.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, input)
+	ret0, _ := ret[0].(*entity.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectMockRecorder) Update(id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProject)(nil).Update), id, input)
+}
+
+// MockComment is a mock of Comment interface.
+type MockComment struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentMockRecorder
+	isgomock struct{}
+}
+
+// MockCommentMockRecorder is the mock recorder for MockComment.
+type MockCommentMockRecorder struct {
+	mock *MockComment
+}
+
+// NewMockComment creates a new mock instance.
+func NewMockComment(ctrl *gomock.Controller) *MockComment {
+	mock := &MockComment{ctrl: ctrl}
+	mock.recorder = &MockCommentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComment) EXPECT() *MockCommentMockRecorder {
+	return m.recorder
+}
+
+// Activity mocks base method.
+func (m *MockComment) Activity(todoID int, query entity.PageQuery) (*entity.Page[entity.Activity], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activity", todoID, query)
+	ret0, _ := ret[0].(*entity.Page[entity.Activity])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Activity indicates an expected call of Activity.
+func (mr *MockCommentMockRecorder) Activity(todoID, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activity", reflect.TypeOf((*MockComment)(nil).Activity), todoID, query)
+}
+
+// As mocks base method.
+func (m *MockComment) As(actor entity.Actor) service.Comment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(service.Comment)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockCommentMockRecorder) As(actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockComment)(nil).As), actor)
+}
+
+// Create mocks base method.
+func (m *MockComment) Create(todoID int, body string) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", todoID, body)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentMockRecorder) Create(todoID, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockComment)(nil).Create), todoID, body)
+}
+
+// Delete mocks base method.
+func (m *MockComment) Delete(todoID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", todoID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentMockRecorder) Delete(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockComment)(nil).Delete), todoID, id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockComment) ForWorkspace(workspaceID int) service.Comment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(service.Comment)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockCommentMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockComment)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockComment) Get(todoID, id int) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", todoID, id)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCommentMockRecorder) Get(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockComment)(nil).Get), todoID, id)
+}
+
+// List mocks base method.
+func (m *MockComment) List(todoID int, query entity.PageQuery) (*entity.Page[entity.Comment], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID, query)
+	ret0, _ := ret[0].(*entity.Page[entity.Comment])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCommentMockRecorder) List(todoID, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockComment)(nil).List), todoID, query)
+}
+
+// Update mocks base method.
+func (m *MockComment) Update(todoID, id int, body string) (*entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", todoID, id, body)
+	ret0, _ := ret[0].(*entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentMockRecorder) Update(todoID, id, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockComment)(nil).Update), todoID, id, body)
+}
+
+// MockAttachment is a mock of Attachment interface.
+type MockAttachment struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentMockRecorder
+	isgomock struct{}
+}
+
+// MockAttachmentMockRecorder is the mock recorder for MockAttachment.
+type MockAttachmentMockRecorder struct {
+	mock *MockAttachment
+}
+
+// NewMockAttachment creates a new mock instance.
+func NewMockAttachment(ctrl *gomock.Controller) *MockAttachment {
+	mock := &MockAttachment{ctrl: ctrl}
+	mock.recorder = &MockAttachmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachment) EXPECT() *MockAttachmentMockRecorder {
+	return m.recorder
+}
+
+// As mocks base method.
+func (m *MockAttachment) As(actor entity.Actor) service.Attachment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(service.Attachment)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockAttachmentMockRecorder) As(actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockAttachment)(nil).As), actor)
+}
+
+// Delete mocks base method.
+func (m *MockAttachment) Delete(todoID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", todoID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAttachmentMockRecorder) Delete(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachment)(nil).Delete), todoID, id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockAttachment) ForWorkspace(workspaceID int) service.Attachment {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(service.Attachment)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockAttachmentMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockAttachment)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockAttachment) Get(todoID, id int) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", todoID, id)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAttachmentMockRecorder) Get(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAttachment)(nil).Get), todoID, id)
+}
+
+// List mocks base method.
+func (m *MockAttachment) List(todoID int) ([]entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID)
+	ret0, _ := ret[0].([]entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAttachmentMockRecorder) List(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAttachment)(nil).List), todoID)
+}
+
+// Open mocks base method.
+func (m *MockAttachment) Open(todoID, id int) (*entity.Attachment, io.ReadSeekCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", todoID, id)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(io.ReadSeekCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockAttachmentMockRecorder) Open(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockAttachment)(nil).Open), todoID, id)
+}
+
+// Sweep mocks base method.
+func (m *MockAttachment) Sweep() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sweep")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sweep indicates an expected call of Sweep.
+func (mr *MockAttachmentMockRecorder) Sweep() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sweep", reflect.TypeOf((*MockAttachment)(nil).Sweep))
+}
+
+// Upload mocks base method.
+func (m *MockAttachment) Upload(todoID int, name string, r io.Reader) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", todoID, name, r)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockAttachmentMockRecorder) Upload(todoID, name, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockAttachment)(nil).Upload), todoID, name, r)
+}
+
+// MockTimeEntry is a mock of TimeEntry interface.
+type MockTimeEntry struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimeEntryMockRecorder
+	isgomock struct{}
+}
+
+// MockTimeEntryMockRecorder is the mock recorder for MockTimeEntry.
+type MockTimeEntryMockRecorder struct {
+	mock *MockTimeEntry
+}
+
+// NewMockTimeEntry creates a new mock instance.
+func NewMockTimeEntry(ctrl *gomock.Controller) *MockTimeEntry {
+	mock := &MockTimeEntry{ctrl: ctrl}
+	mock.recorder = &MockTimeEntryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimeEntry) EXPECT() *MockTimeEntryMockRecorder {
+	return m.recorder
+}
+
+// As mocks base method.
+func (m *MockTimeEntry) As(actor entity.Actor) service.TimeEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(service.TimeEntry)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockTimeEntryMockRecorder) As(actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockTimeEntry)(nil).As), actor)
+}
+
+// Create mocks base method.
+func (m *MockTimeEntry) Create(todoID int, start, end time.Time, note string) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", todoID, start, end, note)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTimeEntryMockRecorder) Create(todoID, start, end, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTimeEntry)(nil).Create), todoID, start, end, note)
+}
+
+// Delete mocks base method.
+func (m *MockTimeEntry) Delete(todoID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", todoID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTimeEntryMockRecorder) Delete(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTimeEntry)(nil).Delete), todoID, id)
+}
+
+// ForWorkspace mocks base method.
+func (m *MockTimeEntry) ForWorkspace(workspaceID int) service.TimeEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForWorkspace", workspaceID)
+	ret0, _ := ret[0].(service.TimeEntry)
+	return ret0
+}
+
+// ForWorkspace indicates an expected call of ForWorkspace.
+func (mr *MockTimeEntryMockRecorder) ForWorkspace(workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForWorkspace", reflect.TypeOf((*MockTimeEntry)(nil).ForWorkspace), workspaceID)
+}
+
+// Get mocks base method.
+func (m *MockTimeEntry) Get(todoID, id int) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", todoID, id)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTimeEntryMockRecorder) Get(todoID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTimeEntry)(nil).Get), todoID, id)
+}
+
+// List mocks base method.
+func (m *MockTimeEntry) List(todoID int, query entity.PageQuery) (*entity.Page[entity.TimeEntry], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", todoID, query)
+	ret0, _ := ret[0].(*entity.Page[entity.TimeEntry])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTimeEntryMockRecorder) List(todoID, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTimeEntry)(nil).List), todoID, query)
+}
+
+// ProjectTotals mocks base method.
+func (m *MockTimeEntry) ProjectTotals(projectID int) (entity.TimeTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProjectTotals", projectID)
+	ret0, _ := ret[0].(entity.TimeTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProjectTotals indicates an expected call of ProjectTotals.
+func (mr *MockTimeEntryMockRecorder) ProjectTotals(projectID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectTotals", reflect.TypeOf((*MockTimeEntry)(nil).ProjectTotals), projectID)
+}
+
+// Report mocks base method.
+func (m *MockTimeEntry) Report(query entity.TimeReportQuery) (*entity.TimeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", query)
+	ret0, _ := ret[0].(*entity.TimeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockTimeEntryMockRecorder) Report(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockTimeEntry)(nil).Report), query)
+}
+
+// Running mocks base method.
+func (m *MockTimeEntry) Running() (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Running")
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Running indicates an expected call of Running.
+func (mr *MockTimeEntryMockRecorder) Running() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Running", reflect.TypeOf((*MockTimeEntry)(nil).Running))
+}
+
+// Start mocks base method.
+func (m *MockTimeEntry) Start(todoID int, note string) (*entity.TimeEntry, *entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", todoID, note)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(*entity.TimeEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockTimeEntryMockRecorder) Start(todoID, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockTimeEntry)(nil).Start), todoID, note)
+}
+
+// Stop mocks base method.
+func (m *MockTimeEntry) Stop() (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop")
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockTimeEntryMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockTimeEntry)(nil).Stop))
+}
+
+// TodoTotals mocks base method.
+func (m *MockTimeEntry) TodoTotals(todoID int) (entity.TimeTotals, entity.TimeTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TodoTotals", todoID)
+	ret0, _ := ret[0].(entity.TimeTotals)
+	ret1, _ := ret[1].(entity.TimeTotals)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TodoTotals indicates an expected call of TodoTotals.
+func (mr *MockTimeEntryMockRecorder) TodoTotals(todoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TodoTotals", reflect.TypeOf((*MockTimeEntry)(nil).TodoTotals), todoID)
+}
+
+// Update mocks base method.
+func (m *MockTimeEntry) Update(todoID, id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", todoID, id, input)
+	ret0, _ := ret[0].(*entity.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTimeEntryMockRecorder) Update(todoID, id, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTimeEntry)(nil).Update), todoID, id, input)
+}
+
+// MockUser is a mock of User interface.
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+	isgomock struct{}
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser.
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance.
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockUser) Authenticate(token string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", token)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockUserMockRecorder) Authenticate(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockUser)(nil).Authenticate), token)
+}
+
+// Login mocks base method.
+func (m *MockUser) Login(email, password string) (*entity.User, *entity.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", email, password)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockUserMockRecorder) Login(email, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUser)(nil).Login), email, password)
+}
+
+// LoginExternal mocks base method.
+func (m *MockUser) LoginExternal(ext entity.ExternalUser) (*entity.User, *entity.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginExternal", ext)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LoginExternal indicates an expected call of LoginExternal.
+func (mr *MockUserMockRecorder) LoginExternal(ext any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginExternal", reflect.TypeOf((*MockUser)(nil).LoginExternal), ext)
+}
+
+// Logout mocks base method.
+func (m *MockUser) Logout(token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUserMockRecorder) Logout(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUser)(nil).Logout), token)
+}
+
+// Register mocks base method.
+func (m *MockUser) Register(input entity.RegisterInput) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", input)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUserMockRecorder) Register(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUser)(nil).Register), input)
+}
+
+// MockSSO is a mock of SSO interface.
+type MockSSO struct {
+	ctrl     *gomock.Controller
+	recorder *MockSSOMockRecorder
+	isgomock struct{}
+}
+
+// MockSSOMockRecorder is the mock recorder for MockSSO.
+type MockSSOMockRecorder struct {
+	mock *MockSSO
+}
+
+// NewMockSSO creates a new mock instance.
+func NewMockSSO(ctrl *gomock.Controller) *MockSSO {
+	mock := &MockSSO{ctrl: ctrl}
+	mock.recorder = &MockSSOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSSO) EXPECT() *MockSSOMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockSSO) Begin(ctx context.Context) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockSSOMockRecorder) Begin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockSSO)(nil).Begin), ctx)
+}
+
+// Complete mocks base method.
+func (m *MockSSO) Complete(ctx context.Context, state, code string) (*entity.User, *entity.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, state, code)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockSSOMockRecorder) Complete(ctx, state, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockSSO)(nil).Complete), ctx, state, code)
+}
+
+// Logout mocks base method.
+func (m *MockSSO) Logout(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockSSOMockRecorder) Logout(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockSSO)(nil).Logout), ctx, token)
+}
+
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyMockRecorder
+	isgomock struct{}
+}
+
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
+}
+
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKey) Authenticate(key string) (*entity.User, *entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", key)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.APIKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyMockRecorder) Authenticate(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKey)(nil).Authenticate), key)
+}
+
+// Issue mocks base method.
+func (m *MockAPIKey) Issue(input entity.IssueAPIKeyInput) (*entity.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", input)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockAPIKeyMockRecorder) Issue(input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockAPIKey)(nil).Issue), input)
+}
+
+// List mocks base method.
+func (m *MockAPIKey) List(userID int) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyMockRecorder) List(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKey)(nil).List), userID)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKey) Revoke(userID, id int) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", userID, id)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyMockRecorder) Revoke(userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKey)(nil).Revoke), userID, id)
+}
+
+// MockWorkspace is a mock of Workspace interface.
+type MockWorkspace struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceMockRecorder
+	isgomock struct{}
+}
+
+// MockWorkspaceMockRecorder is the mock recorder for MockWorkspace.
+type MockWorkspaceMockRecorder struct {
+	mock *MockWorkspace
+}
+
+// NewMockWorkspace creates a new mock instance.
+func NewMockWorkspace(ctrl *gomock.Controller) *MockWorkspace {
+	mock := &MockWorkspace{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspace) EXPECT() *MockWorkspaceMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockWorkspace) AddMember(userID, workspaceID int, email string, role entity.Role) (*entity.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", userID, workspaceID, email, role)
+	ret0, _ := ret[0].(*entity.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockWorkspaceMockRecorder) AddMember(userID, workspaceID, email, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockWorkspace)(nil).AddMember), userID, workspaceID, email, role)
+}
+
+// Create mocks base method.
+func (m *MockWorkspace) Create(userID int, name string) (*entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", userID, name)
+	ret0, _ := ret[0].(*entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWorkspaceMockRecorder) Create(userID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWorkspace)(nil).Create), userID, name)
+}
+
+// List mocks base method.
+func (m *MockWorkspace) List(userID int) ([]entity.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userID)
+	ret0, _ := ret[0].([]entity.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWorkspaceMockRecorder) List(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWorkspace)(nil).List), userID)
+}
+
+// Members mocks base method.
+func (m *MockWorkspace) Members(userID, workspaceID int) ([]entity.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Members", userID, workspaceID)
+	ret0, _ := ret[0].([]entity.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Members indicates an expected call of Members.
+func (mr *MockWorkspaceMockRecorder) Members(userID, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Members", reflect.TypeOf((*MockWorkspace)(nil).Members), userID, workspaceID)
+}
+
+// Resolve mocks base method.
+func (m *MockWorkspace) Resolve(userID, workspaceID int) (*entity.Actor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", userID, workspaceID)
+	ret0, _ := ret[0].(*entity.Actor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockWorkspaceMockRecorder) Resolve(userID, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockWorkspace)(nil).Resolve), userID, workspaceID)
+}
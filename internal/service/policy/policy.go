@@ -0,0 +1,90 @@
+// Package policy decides what an actor may do with the todos and projects
+// of a workspace. Services check every call against it, so the handlers
+// never make access decisions.
+package policy
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+// Resource is what an action is checked against.
+type Resource struct {
+	// OwnerID is the user who created the resource, or zero for a resource
+	// that does not exist yet.
+	OwnerID int
+	// ProjectID is the project the resource is in, the project itself for
+	// a project, or entity.Inbox for what belongs to the workspace as a
+	// whole.
+	ProjectID int
+}
+
+// Todo is the resource of an existing todo.
+func Todo(todo entity.Todo) Resource {
+	return Resource{OwnerID: todo.OwnerID, ProjectID: todo.ProjectID}
+}
+
+// Project is the resource of an existing project.
+func Project(project entity.Project) Resource {
+	return Resource{OwnerID: project.OwnerID, ProjectID: project.ID}
+}
+
+// In is the resource of something about to be created in a project, or in
+// the workspace for entity.Inbox.
+func In(projectID int) Resource {
+	return Resource{ProjectID: projectID}
+}
+
+// Workspace is the resource of what belongs to the workspace as a whole,
+// like tags.
+func Workspace() Resource {
+	return In(entity.Inbox)
+}
+
+// rule decides an action given the role of the actor where the resource is
+// and whether they created it.
+type rule func(role entity.Role, own bool) bool
+
+func atLeast(min entity.Role) rule {
+	return func(role entity.Role, _ bool) bool {
+		return role.AtLeast(min)
+	}
+}
+
+// rules say who may take each action. Editors may only delete what they
+// created themselves.
+var rules = map[entity.Action]rule{
+	entity.ActionRead:   atLeast(entity.RoleViewer),
+	entity.ActionCreate: atLeast(entity.RoleEditor),
+	entity.ActionUpdate: atLeast(entity.RoleEditor),
+	entity.ActionDelete: func(role entity.Role, own bool) bool {
+		return role.AtLeast(entity.RoleOwner) || own && role.AtLeast(entity.RoleEditor)
+	},
+	entity.ActionShare: atLeast(entity.RoleOwner),
+}
+
+// Allowed reports whether the actor may take action on the resource.
+// Unknown actions are never allowed.
+func Allowed(actor entity.Actor, action entity.Action, resource Resource) bool {
+	rule, ok := rules[action]
+	if !ok {
+		return false
+	}
+	own := resource.OwnerID != 0 && resource.OwnerID == actor.UserID
+	return rule(actor.RoleIn(resource.ProjectID), own)
+}
+
+// Check is Allowed as an error. An actor who may not read the resource gets
+// service.ErrNotFound, so they cannot tell it exists, and one who may read
+// it but not take the action gets service.ErrForbidden. Creating in the
+// workspace hides nothing, so it is only ever forbidden.
+func Check(actor entity.Actor, action entity.Action, resource Resource) error {
+	if Allowed(actor, action, resource) {
+		return nil
+	}
+	hidden := !Allowed(actor, entity.ActionRead, resource)
+	if hidden && !(action == entity.ActionCreate && resource.ProjectID == entity.Inbox) {
+		return service.ErrNotFound
+	}
+	return service.ErrForbidden
+}
@@ -0,0 +1,210 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	actorID  = 1
+	otherID  = 2
+	shared   = 10
+	unshared = 11
+)
+
+// grants is the policy written out: what each role allows where the
+// resource is, as the initials of create, read, update, delete and share,
+// first for resources of someone else and then for the actor's own.
+var grants = map[entity.Role][2]string{
+	entity.RoleGuest:  {"", ""},
+	entity.RoleViewer: {"r", "r"},
+	entity.RoleEditor: {"cru", "crud"},
+	entity.RoleOwner:  {"cruds", "cruds"},
+}
+
+// effective is the role an actor has in a project shared with them, by
+// their workspace role and the role the project is shared at.
+var effective = map[[2]entity.Role]entity.Role{
+	{entity.RoleGuest, entity.RoleViewer}:  entity.RoleViewer,
+	{entity.RoleGuest, entity.RoleEditor}:  entity.RoleEditor,
+	{entity.RoleGuest, entity.RoleOwner}:   entity.RoleOwner,
+	{entity.RoleViewer, entity.RoleViewer}: entity.RoleViewer,
+	{entity.RoleViewer, entity.RoleEditor}: entity.RoleEditor,
+	{entity.RoleViewer, entity.RoleOwner}:  entity.RoleOwner,
+	{entity.RoleEditor, entity.RoleViewer}: entity.RoleEditor,
+	{entity.RoleEditor, entity.RoleEditor}: entity.RoleEditor,
+	{entity.RoleEditor, entity.RoleOwner}:  entity.RoleOwner,
+	{entity.RoleOwner, entity.RoleViewer}:  entity.RoleOwner,
+	{entity.RoleOwner, entity.RoleEditor}:  entity.RoleOwner,
+	{entity.RoleOwner, entity.RoleOwner}:   entity.RoleOwner,
+}
+
+type policySuite struct {
+	suite.Suite
+}
+
+func TestPolicySuite(t *testing.T) {
+	suite.Run(t, new(policySuite))
+}
+
+func (s *policySuite) TestTablesAreComplete() {
+	s.Run("every role and action is written out", func() {
+		for _, role := range entity.Roles {
+			s.Contains(grants, role)
+		}
+		for _, action := range entity.Actions {
+			s.Contains(rules, action)
+		}
+		s.Len(effective, len(entity.Roles)*(len(entity.Roles)-1))
+	})
+}
+
+func (s *policySuite) TestRoleIn() {
+	for pair, want := range effective {
+		s.Run(fmt.Sprintf("%s shared at %s", pair[0], pair[1]), func() {
+			actor := entity.Actor{UserID: actorID, Role: pair[0], ProjectRoles: map[int]entity.Role{shared: pair[1]}}
+
+			s.Equal(want, actor.RoleIn(shared))
+			s.Equal(pair[0], actor.RoleIn(unshared))
+			s.Equal(pair[0], actor.RoleIn(entity.Inbox))
+		})
+	}
+	s.Run("shares never apply to the inbox", func() {
+		actor := entity.Actor{Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{entity.Inbox: entity.RoleOwner}}
+		s.Equal(entity.RoleGuest, actor.RoleIn(entity.Inbox))
+	})
+}
+
+// TestMatrix checks every action of an actor with every workspace role and
+// share on the resources of every place and owner against grants.
+func (s *policySuite) TestMatrix() {
+	shares := append([]entity.Role{""}, entity.Roles[1:]...)
+	places := map[string]int{"inbox": entity.Inbox, "shared project": shared, "other project": unshared}
+	owners := map[string]int{"own": actorID, "others'": otherID, "new": 0}
+
+	for _, role := range entity.Roles {
+		for _, share := range shares {
+			actor := entity.Actor{UserID: actorID, WorkspaceID: 1, Role: role}
+			if share != "" {
+				actor.ProjectRoles = map[int]entity.Role{shared: share}
+			}
+			for placeName, projectID := range places {
+				want := role
+				if projectID == shared && share != "" {
+					want = effective[[2]entity.Role{role, share}]
+				}
+				for ownerName, ownerID := range owners {
+					granted := grants[want][0]
+					if ownerID == actorID {
+						granted = grants[want][1]
+					}
+					resource := Resource{OwnerID: ownerID, ProjectID: projectID}
+					for _, action := range entity.Actions {
+						desc := fmt.Sprintf("%s shared at %q %s %s resource in %s", role, share, action, ownerName, placeName)
+						s.Run(desc, func() {
+							allowed := strings.Contains(granted, string(action[0]))
+							s.Equal(allowed, Allowed(actor, action, resource))
+
+							err := Check(actor, action, resource)
+							switch {
+							case allowed:
+								s.NoError(err)
+							case !strings.Contains(granted, "r") && !(action == entity.ActionCreate && projectID == entity.Inbox):
+								s.ErrorIs(err, service.ErrNotFound)
+							default:
+								s.ErrorIs(err, service.ErrForbidden)
+							}
+						})
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *policySuite) TestCheck() {
+	tests := []struct {
+		desc     string
+		actor    entity.Actor
+		action   entity.Action
+		resource Resource
+		wantErr  error
+	}{
+		{
+			desc:     "guest cannot tell an inbox todo exists",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleGuest},
+			action:   entity.ActionUpdate,
+			resource: Todo(entity.Todo{OwnerID: otherID}),
+			wantErr:  service.ErrNotFound,
+		},
+		{
+			desc:     "guest cannot create in the inbox",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleGuest},
+			action:   entity.ActionCreate,
+			resource: In(entity.Inbox),
+			wantErr:  service.ErrForbidden,
+		},
+		{
+			desc:     "guest cannot tell an unshared project exists",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleGuest},
+			action:   entity.ActionCreate,
+			resource: In(unshared),
+			wantErr:  service.ErrNotFound,
+		},
+		{
+			desc:     "viewer sees the todo they cannot change",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleViewer},
+			action:   entity.ActionDelete,
+			resource: Todo(entity.Todo{OwnerID: actorID}),
+			wantErr:  service.ErrForbidden,
+		},
+		{
+			desc:     "editor deletes their own project",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleEditor},
+			action:   entity.ActionDelete,
+			resource: Project(entity.Project{ID: unshared, OwnerID: actorID}),
+		},
+		{
+			desc:     "editor cannot share",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleEditor},
+			action:   entity.ActionShare,
+			resource: Project(entity.Project{ID: unshared, OwnerID: actorID}),
+			wantErr:  service.ErrForbidden,
+		},
+		{
+			desc:     "project owner by share",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{shared: entity.RoleOwner}},
+			action:   entity.ActionShare,
+			resource: Project(entity.Project{ID: shared, OwnerID: otherID}),
+		},
+		{
+			desc:     "no role",
+			actor:    entity.Actor{UserID: actorID},
+			action:   entity.ActionRead,
+			resource: Workspace(),
+			wantErr:  service.ErrNotFound,
+		},
+		{
+			desc:     "unknown action",
+			actor:    entity.Actor{UserID: actorID, Role: entity.RoleOwner},
+			action:   "archive",
+			resource: Workspace(),
+			wantErr:  service.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			err := Check(tt.actor, tt.action, tt.resource)
+			if tt.wantErr == nil {
+				s.NoError(err)
+				return
+			}
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/policy"
+	"github.com/samber/lo"
+)
+
+// Service manages projects and who they are shared with. Todos are reached
+// through the todo service so they keep its validation, error mapping and
+// access checks.
+type Service struct {
+	repo       repo.Project
+	todos      service.Todo
+	users      repo.User
+	workspaces repo.Workspace
+	// workspaceID is the workspace the service works on.
+	workspaceID int
+	// actor is who the service is scoped to, or nil for the unscoped
+	// service.
+	actor *entity.Actor
+}
+
+func NewService(repo repo.Project, todos service.Todo, users repo.User, workspaces repo.Workspace) service.Project {
+	return &Service{
+		repo:       repo,
+		todos:      todos,
+		users:      users,
+		workspaces: workspaces,
+	}
+}
+
+// ForWorkspace returns a copy of the service working on the projects and
+// todos of another workspace.
+func (s *Service) ForWorkspace(workspaceID int) service.Project {
+	scoped := *s
+	scoped.workspaceID = workspaceID
+	scoped.repo = s.repo.ForWorkspace(workspaceID)
+	scoped.todos = s.todos.ForWorkspace(workspaceID)
+	return &scoped
+}
+
+// As scopes a copy of the service, and the todo service it uses, to actor.
+func (s *Service) As(actor entity.Actor) service.Project {
+	scoped := *s
+	scoped.actor = &actor
+	scoped.todos = s.todos.As(actor)
+	return &scoped
+}
+
+func (s *Service) Create(input entity.CreateProjectInput) (*entity.Project, error) {
+	if s.actor != nil {
+		if err := policy.Check(*s.actor, entity.ActionCreate, policy.Workspace()); err != nil {
+			return nil, err
+		}
+		input.OwnerID = s.actor.UserID
+	}
+	return s.repo.Create(input)
+}
+
+func (s *Service) List(includeArchived bool) ([]entity.Project, error) {
+	projects, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	projects = slices.DeleteFunc(projects, func(project entity.Project) bool {
+		return !s.visible(project) || !includeArchived && project.Archived()
+	})
+	return projects, nil
+}
+
+// Get reports projects the actor may not read as not found.
+func (s *Service) Get(id int) (*entity.Project, error) {
+	return s.get(id, entity.ActionRead)
+}
+
+func (s *Service) Update(id int, input entity.UpdateProjectInput) (*entity.Project, error) {
+	if err := s.authorize(id, entity.ActionUpdate); err != nil {
+		return nil, err
+	}
+	project, err := s.repo.Update(id, input)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return project, nil
+}
+
+func (s *Service) Archive(id int) (*entity.Project, error) {
+	if err := s.authorize(id, entity.ActionUpdate); err != nil {
+		return nil, err
+	}
+	project, err := s.repo.SetArchived(id, true)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return project, nil
+}
+
+func (s *Service) Unarchive(id int) (*entity.Project, error) {
+	if err := s.authorize(id, entity.ActionUpdate); err != nil {
+		return nil, err
+	}
+	project, err := s.repo.SetArchived(id, false)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return project, nil
+}
+
+func (s *Service) Delete(id int, mode entity.ProjectDeleteMode) error {
+	if _, err := s.get(id, entity.ActionDelete); err != nil {
+		return err
+	}
+	todos, err := s.todos.List(entity.TodoQuery{Project: &id})
+	if err != nil {
+		return err
+	}
+
+	if len(todos) > 0 {
+		switch mode {
+		case entity.ProjectDeleteCascade:
+			ids := lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID })
+			if _, err := s.todos.BatchDelete(ids, entity.BatchAtomic); err != nil {
+				return err
+			}
+		case entity.ProjectDeleteInbox:
+			inputs := lo.Map(todos, func(todo entity.Todo, _ int) entity.BatchUpdateTodoInput {
+				return entity.BatchUpdateTodoInput{
+					ID:    todo.ID,
+					Input: entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)},
+				}
+			})
+			if _, err := s.todos.BatchUpdate(inputs, entity.BatchAtomic); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrProjectNotEmpty)
+		}
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *Service) CreateTodo(projectID int, input entity.CreateTodoInput) (*entity.Todo, error) {
+	if err := s.checkOpen(projectID); err != nil {
+		return nil, err
+	}
+	input.ProjectID = projectID
+	return s.todos.Create(input)
+}
+
+func (s *Service) ListTodos(projectID int, query entity.TodoQuery) ([]entity.Todo, error) {
+	if _, err := s.Get(projectID); err != nil {
+		return nil, err
+	}
+	query.Project = &projectID
+	return s.todos.List(query)
+}
+
+func (s *Service) CriticalPath(projectID int) ([]entity.Todo, error) {
+	if _, err := s.Get(projectID); err != nil {
+		return nil, err
+	}
+	return s.todos.CriticalPath(projectID)
+}
+
+func (s *Service) MoveTodo(todoID, projectID int) (*entity.Todo, error) {
+	if projectID != entity.Inbox {
+		if err := s.checkOpen(projectID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.todos.Update(todoID, entity.UpdateTodoInput{ProjectID: &projectID}); err != nil {
+		return nil, err
+	}
+	return s.todos.Get(todoID)
+}
+
+// Share only shares at roles above guest, since a guest share would grant
+// nothing.
+func (s *Service) Share(projectID int, email string, role entity.Role) (*entity.Member, bool, error) {
+	if _, err := s.get(projectID, entity.ActionShare); err != nil {
+		return nil, false, err
+	}
+	workspace, err := s.workspaces.Get(s.workspaceID)
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	if workspace.Personal {
+		return nil, false, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrPersonalWorkspace)
+	}
+	if !role.Valid() || role == entity.RoleGuest {
+		return nil, false, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidRole)
+	}
+	email, err = entity.NormalizeEmail(email)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	user, err := s.users.GetByEmail(email)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, false, fmt.Errorf("%w: no user has the email", service.ErrNotFound)
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	_, err = s.workspaces.Member(workspace.ID, user.ID)
+	if errors.Is(err, repo.ErrNotFound) {
+		err = s.workspaces.AddMember(workspace.ID, user.ID, entity.RoleGuest)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	created, err := s.repo.Share(projectID, user.ID, role)
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	return &entity.Member{User: *user, Role: role}, created, nil
+}
+
+func (s *Service) Shares(projectID int) ([]entity.Member, error) {
+	if _, err := s.Get(projectID); err != nil {
+		return nil, err
+	}
+	shares, err := s.repo.Shares(projectID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	members := make([]entity.Member, 0, len(shares))
+	for _, share := range shares {
+		user, err := s.users.Get(share.UserID)
+		if errors.Is(err, repo.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		members = append(members, entity.Member{User: *user, Role: share.Role})
+	}
+	return members, nil
+}
+
+func (s *Service) Unshare(projectID, userID int) error {
+	if _, err := s.get(projectID, entity.ActionShare); err != nil {
+		return err
+	}
+	if err := s.repo.Unshare(projectID, userID); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *Service) visible(project entity.Project) bool {
+	return s.actor == nil || policy.Allowed(*s.actor, entity.ActionRead, policy.Project(project))
+}
+
+// get returns the project once the actor may take action on it. Projects
+// the actor may not read are not found.
+func (s *Service) get(id int, action entity.Action) (*entity.Project, error) {
+	project, err := s.repo.Get(id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if s.actor != nil {
+		if err := policy.Check(*s.actor, action, policy.Project(*project)); err != nil {
+			return nil, err
+		}
+	}
+	return project, nil
+}
+
+// authorize is get for calls that need no project, costing a lookup only
+// for scoped services.
+func (s *Service) authorize(id int, action entity.Action) error {
+	if s.actor == nil {
+		return nil
+	}
+	_, err := s.get(id, action)
+	return err
+}
+
+// checkOpen makes sure todos can be added to the project.
+func (s *Service) checkOpen(id int) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if project.Archived() {
+		return fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrProjectArchived)
+	}
+	return nil
+}
+
+func mapError(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return service.ErrNotFound
+	}
+	return err
+}
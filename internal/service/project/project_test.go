@@ -0,0 +1,578 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	repomocks "github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockErr = errors.New("something wrong")
+)
+
+type projectSuite struct {
+	suite.Suite
+	srv      service.Project
+	mockRepo *repomocks.MockProject
+	mockTodo *mocks.MockTodo
+	mockUser *repomocks.MockUser
+	mockWS   *repomocks.MockWorkspace
+}
+
+func (s *projectSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = repomocks.NewMockProject(ctrl)
+	s.mockTodo = mocks.NewMockTodo(ctrl)
+	s.mockUser = repomocks.NewMockUser(ctrl)
+	s.mockWS = repomocks.NewMockWorkspace(ctrl)
+	s.srv = NewService(s.mockRepo, s.mockTodo, s.mockUser, s.mockWS)
+}
+
+func TestProjectSuite(t *testing.T) {
+	suite.Run(t, new(projectSuite))
+}
+
+func (s *projectSuite) TestList() {
+	archivedAt := time.Unix(123456789, 0)
+	projects := []entity.Project{
+		{ID: 1, Name: "home"},
+		{ID: 2, Name: "old", ArchivedAt: &archivedAt},
+	}
+
+	tests := []struct {
+		desc            string
+		includeArchived bool
+		want            []int
+	}{
+		{desc: "without archived", want: []int{1}},
+		{desc: "with archived", includeArchived: true, want: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockRepo.EXPECT().List().Return(append([]entity.Project(nil), projects...), nil).Times(1)
+
+			got, err := s.srv.List(tt.includeArchived)
+			s.Require().NoError(err)
+			s.Equal(tt.want, lo.Map(got, func(project entity.Project, _ int) int { return project.ID }))
+		})
+	}
+}
+
+func (s *projectSuite) TestGet() {
+	tests := []struct {
+		desc    string
+		setup   func()
+		want    *entity.Project
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1, Name: "home"}, nil).Times(1)
+			},
+			want: &entity.Project{ID: 1, Name: "home"},
+		},
+		{
+			desc: "not found",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "get failed",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.Get(1)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *projectSuite) TestArchive() {
+	s.Run("archive", func() {
+		s.mockRepo.EXPECT().SetArchived(1, true).Return(&entity.Project{ID: 1}, nil).Times(1)
+
+		_, err := s.srv.Archive(1)
+		s.NoError(err)
+	})
+	s.Run("unarchive not found", func() {
+		s.mockRepo.EXPECT().SetArchived(1, false).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Unarchive(1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestDelete() {
+	todos := []entity.Todo{{ID: 3, ProjectID: 1}, {ID: 5, ProjectID: 1}}
+	expectTodos := func(todos []entity.Todo) {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockTodo.EXPECT().List(entity.TodoQuery{Project: lo.ToPtr(1)}).Return(todos, nil).Times(1)
+	}
+
+	tests := []struct {
+		desc    string
+		mode    entity.ProjectDeleteMode
+		setup   func()
+		wantErr error
+	}{
+		{
+			desc: "block empty project",
+			mode: entity.ProjectDeleteBlock,
+			setup: func() {
+				expectTodos(nil)
+				s.mockRepo.EXPECT().Delete(1).Return(nil).Times(1)
+			},
+		},
+		{
+			desc: "block project with todos",
+			mode: entity.ProjectDeleteBlock,
+			setup: func() {
+				expectTodos(todos)
+			},
+			wantErr: entity.ErrProjectNotEmpty,
+		},
+		{
+			desc: "cascade",
+			mode: entity.ProjectDeleteCascade,
+			setup: func() {
+				expectTodos(todos)
+				s.mockTodo.EXPECT().BatchDelete([]int{3, 5}, entity.BatchAtomic).Return(nil, nil).Times(1)
+				s.mockRepo.EXPECT().Delete(1).Return(nil).Times(1)
+			},
+		},
+		{
+			desc: "cascade failed",
+			mode: entity.ProjectDeleteCascade,
+			setup: func() {
+				expectTodos(todos)
+				s.mockTodo.EXPECT().BatchDelete([]int{3, 5}, entity.BatchAtomic).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+		{
+			desc: "inbox",
+			mode: entity.ProjectDeleteInbox,
+			setup: func() {
+				expectTodos(todos)
+				s.mockTodo.EXPECT().BatchUpdate([]entity.BatchUpdateTodoInput{
+					{ID: 3, Input: entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}},
+					{ID: 5, Input: entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}},
+				}, entity.BatchAtomic).Return(nil, nil).Times(1)
+				s.mockRepo.EXPECT().Delete(1).Return(nil).Times(1)
+			},
+		},
+		{
+			desc: "not found",
+			mode: entity.ProjectDeleteCascade,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			err := s.srv.Delete(1, tt.mode)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+
+	s.Run("block is a conflict", func() {
+		expectTodos(todos)
+
+		s.ErrorIs(s.srv.Delete(1, entity.ProjectDeleteBlock), service.ErrConflict)
+	})
+}
+
+func (s *projectSuite) TestCreateTodo() {
+	archivedAt := time.Unix(123456789, 0)
+	tests := []struct {
+		desc    string
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+				s.mockTodo.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", ProjectID: 1}).Return(&entity.Todo{ID: 1, ProjectID: 1}, nil).Times(1)
+			},
+			want: &entity.Todo{ID: 1, ProjectID: 1},
+		},
+		{
+			desc: "archived",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1, ArchivedAt: &archivedAt}, nil).Times(1)
+			},
+			wantErr: service.ErrConflict,
+		},
+		{
+			desc: "not found",
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.CreateTodo(1, entity.CreateTodoInput{Title: "title-1"})
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *projectSuite) TestListTodos() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockTodo.EXPECT().List(entity.TodoQuery{Project: lo.ToPtr(1), Order: entity.OrderManual}).Return([]entity.Todo{{ID: 1, ProjectID: 1}}, nil).Times(1)
+
+		got, err := s.srv.ListTodos(1, entity.TodoQuery{Project: lo.ToPtr(2), Order: entity.OrderManual})
+		s.Require().NoError(err)
+		s.Equal([]entity.Todo{{ID: 1, ProjectID: 1}}, got)
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.ListTodos(1, entity.TodoQuery{})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestCriticalPath() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockTodo.EXPECT().CriticalPath(1).Return([]entity.Todo{{ID: 2}, {ID: 1}}, nil).Times(1)
+
+		got, err := s.srv.CriticalPath(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.Todo{{ID: 2}, {ID: 1}}, got)
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.CriticalPath(1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *projectSuite) TestMoveTodo() {
+	archivedAt := time.Unix(123456789, 0)
+	tests := []struct {
+		desc      string
+		projectID int
+		setup     func()
+		want      *entity.Todo
+		wantErr   error
+	}{
+		{
+			desc:      "into a project",
+			projectID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Project{ID: 2}, nil).Times(1)
+				s.mockTodo.EXPECT().Update(1, entity.UpdateTodoInput{ProjectID: lo.ToPtr(2)}).Return(nil).Times(1)
+				s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, ProjectID: 2}, nil).Times(1)
+			},
+			want: &entity.Todo{ID: 1, ProjectID: 2},
+		},
+		{
+			desc:      "into the inbox",
+			projectID: entity.Inbox,
+			setup: func() {
+				s.mockTodo.EXPECT().Update(1, entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}).Return(nil).Times(1)
+				s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+			},
+			want: &entity.Todo{ID: 1},
+		},
+		{
+			desc:      "into an archived project",
+			projectID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Project{ID: 2, ArchivedAt: &archivedAt}, nil).Times(1)
+			},
+			wantErr: entity.ErrProjectArchived,
+		},
+		{
+			desc:      "todo not found",
+			projectID: entity.Inbox,
+			setup: func() {
+				s.mockTodo.EXPECT().Update(1, entity.UpdateTodoInput{ProjectID: lo.ToPtr(entity.Inbox)}).Return(service.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.MoveTodo(1, tt.projectID)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *projectSuite) TestAs() {
+	setup := func(actor entity.Actor) service.Project {
+		s.mockTodo.EXPECT().As(actor).Return(s.mockTodo).Times(1)
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1, OwnerID: 1}, nil).AnyTimes()
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Project{ID: 2, OwnerID: 2}, nil).AnyTimes()
+		return s.srv.As(actor)
+	}
+	guest := entity.Actor{UserID: 1, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{1: entity.RoleOwner}}
+	viewer := entity.Actor{UserID: 1, Role: entity.RoleViewer}
+	editor := entity.Actor{UserID: 1, Role: entity.RoleEditor}
+
+	s.Run("create stamps the owner", func() {
+		srv := setup(editor)
+		s.mockRepo.EXPECT().Create(entity.CreateProjectInput{OwnerID: 1, Name: "home"}).Return(&entity.Project{ID: 3, OwnerID: 1}, nil).Times(1)
+
+		_, err := srv.Create(entity.CreateProjectInput{Name: "home"})
+		s.NoError(err)
+	})
+	s.Run("list only returns readable projects", func() {
+		srv := setup(guest)
+		s.mockRepo.EXPECT().List().Return([]entity.Project{{ID: 1, OwnerID: 2}, {ID: 2, OwnerID: 2}}, nil).Times(1)
+
+		got, err := srv.List(true)
+		s.Require().NoError(err)
+		s.Equal([]entity.Project{{ID: 1, OwnerID: 2}}, got)
+	})
+	s.Run("todos go through the scoped todo service", func() {
+		srv := setup(guest)
+		s.mockTodo.EXPECT().List(entity.TodoQuery{Project: lo.ToPtr(1)}).Return(nil, nil).Times(1)
+
+		_, err := srv.ListTodos(1, entity.TodoQuery{})
+		s.NoError(err)
+	})
+	s.Run("editor deletes their own project", func() {
+		srv := setup(editor)
+		s.mockTodo.EXPECT().List(entity.TodoQuery{Project: lo.ToPtr(1)}).Return(nil, nil).Times(1)
+		s.mockRepo.EXPECT().Delete(1).Return(nil).Times(1)
+
+		s.NoError(srv.Delete(1, entity.ProjectDeleteCascade))
+	})
+
+	tests := []struct {
+		desc string
+		call func(srv service.Project) error
+	}{
+		{desc: "get", call: func(srv service.Project) error { _, err := srv.Get(2); return err }},
+		{desc: "update", call: func(srv service.Project) error {
+			_, err := srv.Update(2, entity.UpdateProjectInput{Name: lo.ToPtr("mine")})
+			return err
+		}},
+		{desc: "archive", call: func(srv service.Project) error { _, err := srv.Archive(2); return err }},
+		{desc: "unarchive", call: func(srv service.Project) error { _, err := srv.Unarchive(2); return err }},
+		{desc: "delete", call: func(srv service.Project) error { return srv.Delete(2, entity.ProjectDeleteCascade) }},
+		{desc: "create todo", call: func(srv service.Project) error {
+			_, err := srv.CreateTodo(2, entity.CreateTodoInput{Title: "mine"})
+			return err
+		}},
+		{desc: "list todos", call: func(srv service.Project) error { _, err := srv.ListTodos(2, entity.TodoQuery{}); return err }},
+		{desc: "move todo", call: func(srv service.Project) error { _, err := srv.MoveTodo(1, 2); return err }},
+		{desc: "critical path", call: func(srv service.Project) error { _, err := srv.CriticalPath(2); return err }},
+		{desc: "share", call: func(srv service.Project) error {
+			_, _, err := srv.Share(2, "bob@example.com", entity.RoleViewer)
+			return err
+		}},
+		{desc: "shares", call: func(srv service.Project) error { _, err := srv.Shares(2); return err }},
+		{desc: "unshare", call: func(srv service.Project) error { return srv.Unshare(2, 5) }},
+	}
+	for _, tt := range tests {
+		s.Run("project not shared with a guest: "+tt.desc, func() {
+			s.ErrorIs(tt.call(setup(guest)), service.ErrNotFound)
+		})
+	}
+
+	forbidden := map[string][]entity.Actor{
+		"update":    {viewer},
+		"archive":   {viewer},
+		"unarchive": {viewer},
+		"delete":    {viewer, editor},
+		"share":     {viewer, editor},
+		"unshare":   {viewer, editor},
+	}
+	for _, tt := range tests {
+		for _, actor := range forbidden[tt.desc] {
+			s.Run(fmt.Sprintf("%s project of someone else: %s", actor.Role, tt.desc), func() {
+				s.ErrorIs(tt.call(setup(actor)), service.ErrForbidden)
+			})
+		}
+	}
+	s.Run("viewer creates no project", func() {
+		_, err := setup(viewer).Create(entity.CreateProjectInput{Name: "home"})
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+}
+
+func (s *projectSuite) TestShare() {
+	expectProject := func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1, OwnerID: 1}, nil).Times(1)
+	}
+	expectWorkspace := func(personal bool) {
+		s.mockWS.EXPECT().Get(0).Return(&entity.Workspace{ID: 0, Personal: personal}, nil).Times(1)
+	}
+	bob := &entity.User{ID: 5, Email: "bob@example.com"}
+
+	tests := []struct {
+		desc        string
+		role        entity.Role
+		setup       func()
+		wantCreated bool
+		wantErr     []error
+	}{
+		{
+			desc: "member",
+			role: entity.RoleEditor,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+				s.mockUser.EXPECT().GetByEmail("bob@example.com").Return(bob, nil).Times(1)
+				s.mockWS.EXPECT().Member(0, 5).Return(&entity.Membership{UserID: 5, Role: entity.RoleViewer}, nil).Times(1)
+				s.mockRepo.EXPECT().Share(1, 5, entity.RoleEditor).Return(true, nil).Times(1)
+			},
+			wantCreated: true,
+		},
+		{
+			desc: "not a member joins as a guest",
+			role: entity.RoleViewer,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+				s.mockUser.EXPECT().GetByEmail("bob@example.com").Return(bob, nil).Times(1)
+				s.mockWS.EXPECT().Member(0, 5).Return(nil, repo.ErrNotFound).Times(1)
+				s.mockWS.EXPECT().AddMember(0, 5, entity.RoleGuest).Return(nil).Times(1)
+				s.mockRepo.EXPECT().Share(1, 5, entity.RoleViewer).Return(true, nil).Times(1)
+			},
+			wantCreated: true,
+		},
+		{
+			desc: "change role",
+			role: entity.RoleOwner,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+				s.mockUser.EXPECT().GetByEmail("bob@example.com").Return(bob, nil).Times(1)
+				s.mockWS.EXPECT().Member(0, 5).Return(&entity.Membership{UserID: 5, Role: entity.RoleGuest}, nil).Times(1)
+				s.mockRepo.EXPECT().Share(1, 5, entity.RoleOwner).Return(false, nil).Times(1)
+			},
+		},
+		{
+			desc: "personal workspace",
+			role: entity.RoleViewer,
+			setup: func() {
+				expectProject()
+				expectWorkspace(true)
+			},
+			wantErr: []error{service.ErrConflict, entity.ErrPersonalWorkspace},
+		},
+		{
+			desc: "guest role",
+			role: entity.RoleGuest,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+			},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrInvalidRole},
+		},
+		{
+			desc: "unknown email",
+			role: entity.RoleViewer,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+				s.mockUser.EXPECT().GetByEmail("bob@example.com").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: []error{service.ErrNotFound},
+		},
+		{
+			desc: "project not found",
+			role: entity.RoleViewer,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: []error{service.ErrNotFound},
+		},
+		{
+			desc: "repo error",
+			role: entity.RoleViewer,
+			setup: func() {
+				expectProject()
+				expectWorkspace(false)
+				s.mockUser.EXPECT().GetByEmail("bob@example.com").Return(bob, nil).Times(1)
+				s.mockWS.EXPECT().Member(0, 5).Return(nil, mockErr).Times(1)
+			},
+			wantErr: []error{mockErr},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			got, created, err := s.srv.Share(1, " Bob@example.com ", tt.role)
+			if tt.wantErr == nil {
+				s.Require().NoError(err)
+				s.Equal(&entity.Member{User: *bob, Role: tt.role}, got)
+				s.Equal(tt.wantCreated, created)
+			}
+			for _, want := range tt.wantErr {
+				s.ErrorIs(err, want)
+			}
+		})
+	}
+}
+
+func (s *projectSuite) TestShares() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Shares(1).Return([]entity.ProjectShare{
+			{ProjectID: 1, UserID: 5, Role: entity.RoleViewer},
+			{ProjectID: 1, UserID: 6, Role: entity.RoleEditor},
+		}, nil).Times(1)
+		s.mockUser.EXPECT().Get(5).Return(&entity.User{ID: 5}, nil).Times(1)
+		s.mockUser.EXPECT().Get(6).Return(nil, repo.ErrNotFound).Times(1)
+
+		got, err := s.srv.Shares(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.Member{{User: entity.User{ID: 5}, Role: entity.RoleViewer}}, got)
+	})
+}
+
+func (s *projectSuite) TestUnshare() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Unshare(1, 5).Return(nil).Times(1)
+
+		s.NoError(s.srv.Unshare(1, 5))
+	})
+	s.Run("not shared", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Project{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Unshare(1, 5).Return(repo.ErrNotFound).Times(1)
+
+		s.ErrorIs(s.srv.Unshare(1, 5), service.ErrNotFound)
+	})
+}
@@ -1,20 +1,273 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
 )
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	// ErrUnauthorized is returned for bad credentials and tokens.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the caller may see a resource but their
+	// role does not allow the action.
+	ErrForbidden = errors.New("forbidden")
+	// ErrQuotaExceeded is returned when an action would take the user over
+	// one of their quotas. It is an ErrForbidden.
+	ErrQuotaExceeded = fmt.Errorf("%w: quota exceeded", ErrForbidden)
 )
 
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
 //go:generate mockgen -source=service.go -destination mocks/service.go -package mocks
 type Todo interface {
-	Create(title, description string) (*entity.Todo, error)
-	List() ([]entity.Todo, error)
+	// ForWorkspace returns the service for the todos of a workspace.
+	// Workspaces share nothing, so IDs from one mean nothing in another.
+	ForWorkspace(workspaceID int) Todo
+	// As returns the service as seen by an actor: todos are created for the
+	// actor, every call is checked against their role, and todos they may
+	// not read are reported as not found.
+	As(actor entity.Actor) Todo
+	Create(input entity.CreateTodoInput) (*entity.Todo, error)
+	List(query entity.TodoQuery) ([]entity.Todo, error)
 	Get(id int) (*entity.Todo, error)
+	// Update completing a recurring todo creates its next occurrence.
 	Update(id int, input entity.UpdateTodoInput) error
-	Delete(id int) error
+	UpdateFunc(id int, fn func(todo *entity.Todo) error) error
+	Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error)
+	Upsert(id int, input entity.ReplaceTodoInput) (todo *entity.Todo, created bool, err error)
+	// Delete deletes a todo, handling its subtasks according to mode.
+	Delete(id int, mode entity.SubtaskDeleteMode) error
+	Move(id int, input entity.MoveTodoInput) (*entity.Todo, error)
+	BatchCreate(inputs []entity.CreateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error)
+	BatchUpdate(inputs []entity.BatchUpdateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error)
+	BatchDelete(ids []int, mode entity.BatchMode) ([]entity.BatchResult, error)
+	// SetParent moves a todo below another one, or to the top level when
+	// parentID is zero.
+	SetParent(id, parentID int) (*entity.Todo, error)
+	// Subtree returns the todo followed by all of its subtasks, each
+	// parent before its children.
+	Subtree(id int) ([]entity.Todo, error)
+	AddDependency(todoID, blockerID int) (*entity.Dependency, error)
+	RemoveDependency(todoID, blockerID int) error
+	// Dependencies returns the dependencies of the todos blocking todoID.
+	Dependencies(todoID int) ([]entity.Dependency, error)
+	// CriticalPath returns the longest chain of open todos in a project
+	// that block one another, first blocker first.
+	CriticalPath(projectID int) ([]entity.Todo, error)
+	// Occurrences previews the next n occurrences of a recurring todo.
+	Occurrences(id, n int) ([]entity.Occurrence, error)
+
+	CreateTag(name string) (*entity.Tag, error)
+	ListTags() ([]entity.Tag, error)
+	GetTag(id int) (*entity.Tag, error)
+	RenameTag(id int, name string) (*entity.Tag, error)
+	DeleteTag(id int) error
+	MergeTags(sourceID, targetID int) (*entity.Tag, error)
+	AttachTags(todoID int, names []string) (*entity.Todo, error)
+	DetachTags(todoID int, names []string) (*entity.Todo, error)
+
+	AddChecklistItem(todoID int, text string) (*entity.Todo, error)
+	// UpdateChecklistItem edits or checks an item. Checking the last item of
+	// a checklist that auto-completes completes the todo.
+	UpdateChecklistItem(todoID, itemID int, input entity.UpdateChecklistItemInput) (*entity.Todo, error)
+	// ReorderChecklist puts the items in the order of itemIDs, which must
+	// list every item exactly once.
+	ReorderChecklist(todoID int, itemIDs []int) (*entity.Todo, error)
+	RemoveChecklistItem(todoID, itemID int) (*entity.Todo, error)
+	SetChecklistAutoComplete(todoID int, autoComplete bool) (*entity.Todo, error)
+}
+
+type Project interface {
+	// ForWorkspace returns the service for the projects of a workspace.
+	ForWorkspace(workspaceID int) Project
+	// As returns the service as seen by an actor, whose role decides what
+	// they may do with each project and its todos.
+	As(actor entity.Actor) Project
+	Create(input entity.CreateProjectInput) (*entity.Project, error)
+	List(includeArchived bool) ([]entity.Project, error)
+	Get(id int) (*entity.Project, error)
+	Update(id int, input entity.UpdateProjectInput) (*entity.Project, error)
+	Archive(id int) (*entity.Project, error)
+	Unarchive(id int) (*entity.Project, error)
+	// Delete deletes a project, handling its todos according to mode.
+	Delete(id int, mode entity.ProjectDeleteMode) error
+	CreateTodo(projectID int, input entity.CreateTodoInput) (*entity.Todo, error)
+	ListTodos(projectID int, query entity.TodoQuery) ([]entity.Todo, error)
+	// MoveTodo moves a todo into a project, or into the inbox when
+	// projectID is entity.Inbox.
+	MoveTodo(todoID, projectID int) (*entity.Todo, error)
+	CriticalPath(projectID int) ([]entity.Todo, error)
+	// Share shares a project with the user with email at a role, adding
+	// them to the workspace as a guest when they are not a member yet, and
+	// reports whether the project was not shared with them before.
+	Share(projectID int, email string, role entity.Role) (member *entity.Member, created bool, err error)
+	// Shares returns the users a project is shared with.
+	Shares(projectID int) ([]entity.Member, error)
+	Unshare(projectID, userID int) error
+}
+
+// Comment manages the comments on todos. Comments are reached through
+// their todo, so whoever may read a todo may read and add its comments.
+type Comment interface {
+	// ForWorkspace returns the service for the comments of a workspace.
+	ForWorkspace(workspaceID int) Comment
+	// As returns the service as seen by an actor, who writes the comments
+	// it creates.
+	As(actor entity.Actor) Comment
+	// Create comments on a todo and notifies the users it mentions who may
+	// read the todo.
+	Create(todoID int, body string) (*entity.Comment, error)
+	// List returns the comments on a todo, oldest first.
+	List(todoID int, query entity.PageQuery) (*entity.Page[entity.Comment], error)
+	Get(todoID, id int) (*entity.Comment, error)
+	// Update changes the body of a comment, keeping the old one in its
+	// edits, and notifies users it did not mention before. Only the author
+	// may edit a comment.
+	Update(todoID, id int, body string) (*entity.Comment, error)
+	// Delete deletes a comment, which its author and whoever may delete
+	// what others created may do.
+	Delete(todoID, id int) error
+	// Activity returns the comments on a todo merged with the changes made
+	// to it, oldest first.
+	Activity(todoID int, query entity.PageQuery) (*entity.Page[entity.Activity], error)
+}
+
+// Attachment manages the files attached to todos. Whoever may read a todo
+// may download its attachments, and whoever may update it may attach files.
+type Attachment interface {
+	// ForWorkspace returns the service for the attachments of a workspace.
+	ForWorkspace(workspaceID int) Attachment
+	// As returns the service as seen by an actor, who uploads the files it
+	// attaches.
+	As(actor entity.Actor) Attachment
+	// Upload attaches the file read from r to a todo. The content type is
+	// sniffed from the content, whatever the client claims.
+	Upload(todoID int, name string, r io.Reader) (*entity.Attachment, error)
+	// List returns the attachments of a todo, oldest first.
+	List(todoID int) ([]entity.Attachment, error)
+	Get(todoID, id int) (*entity.Attachment, error)
+	// Open returns an attachment along with its content, which the caller
+	// must close.
+	Open(todoID, id int) (*entity.Attachment, io.ReadSeekCloser, error)
+	// Delete deletes an attachment, which whoever may delete what its
+	// uploader created may do. The content is left for Sweep.
+	Delete(todoID, id int) error
+	// Sweep deletes the attachments of deleted todos, then the content no
+	// attachment refers to anymore, across workspaces. It returns how much
+	// content it deleted, and is meant for the unscoped service.
+	Sweep() (int, error)
+}
+
+// TimeEntry tracks the time spent on todos. Whoever may read a todo may
+// read the time tracked on it, and whoever may update it may track time on
+// it.
+type TimeEntry interface {
+	// ForWorkspace returns the service for the time entries of a
+	// workspace.
+	ForWorkspace(workspaceID int) TimeEntry
+	// As returns the service as seen by an actor, whose time it tracks.
+	As(actor entity.Actor) TimeEntry
+	// Start starts a timer on a todo. The timer the actor had running, in
+	// any workspace, is stopped and returned as stopped.
+	Start(todoID int, note string) (started, stopped *entity.TimeEntry, err error)
+	// Stop stops the timer the actor has running in any workspace.
+	Stop() (*entity.TimeEntry, error)
+	// Running returns the timer the actor has running in any workspace.
+	Running() (*entity.TimeEntry, error)
+	// Create records time spent on a todo without a timer.
+	Create(todoID int, start, end time.Time, note string) (*entity.TimeEntry, error)
+	// List returns the entries of a todo in the order they started.
+	List(todoID int, query entity.PageQuery) (*entity.Page[entity.TimeEntry], error)
+	Get(todoID, id int) (*entity.TimeEntry, error)
+	// Update edits an entry, which the user who tracked it and whoever may
+	// delete what they created may do. Setting the end of a running timer
+	// stops it.
+	Update(todoID, id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error)
+	// Delete deletes an entry, which whoever may update it may do.
+	Delete(todoID, id int) error
+	// TodoTotals sums the estimate and tracked time of a todo, on its own
+	// and together with all of its subtasks.
+	TodoTotals(todoID int) (own, withSubtasks entity.TimeTotals, err error)
+	// ProjectTotals sums the estimates and tracked time of the todos of a
+	// project.
+	ProjectTotals(projectID int) (entity.TimeTotals, error)
+	// Report groups the time tracked on the todos the actor may read by
+	// day or week.
+	Report(query entity.TimeReportQuery) (*entity.TimeReport, error)
+}
+
+type User interface {
+	Register(input entity.RegisterInput) (*entity.User, error)
+	// Login checks the password and issues an access token.
+	Login(email, password string) (*entity.User, *entity.AccessToken, error)
+	// LoginExternal issues an access token to the user an identity provider
+	// vouches for, linking or creating them the first time.
+	LoginExternal(ext entity.ExternalUser) (*entity.User, *entity.AccessToken, error)
+	// Authenticate returns the user an access token was issued to.
+	Authenticate(token string) (*entity.User, error)
+	// Logout revokes an access token.
+	Logout(token string) error
+}
+
+// SSO logs users in with an external identity provider.
+type SSO interface {
+	// Begin starts a login and returns the URL of the provider to send the
+	// user to, along with the state it must send them back with.
+	Begin(ctx context.Context) (authURL, state string, err error)
+	// Complete finishes the login the provider sent the user back from.
+	Complete(ctx context.Context, state, code string) (*entity.User, *entity.AccessToken, error)
+	// Logout revokes an access token and returns the URL that ends the
+	// session at the provider, which is empty when the token is not from
+	// Complete or the provider does not support it.
+	Logout(ctx context.Context, token string) (string, error)
+}
+
+type APIKey interface {
+	// Issue creates an API key and returns it with the key itself, which is
+	// only stored as a hash and cannot be shown again.
+	Issue(input entity.IssueAPIKeyInput) (*entity.APIKey, string, error)
+	List(userID int) ([]entity.APIKey, error)
+	// Revoke revokes an API key of the user. Keys of other users are not
+	// found.
+	Revoke(userID, id int) (*entity.APIKey, error)
+	// Authenticate returns the API key and its owner, and records the use
+	// of the key.
+	Authenticate(key string) (*entity.User, *entity.APIKey, error)
+}
+
+type Workspace interface {
+	// Create creates a workspace with the user as its owner and first
+	// member.
+	Create(userID int, name string) (*entity.Workspace, error)
+	// List returns the workspaces the user is a member of, starting with
+	// their personal workspace.
+	List(userID int) ([]entity.Workspace, error)
+	// Resolve returns the user acting in the workspace a request works on:
+	// the one with workspaceID, or their personal workspace when it is zero.
+	// Workspaces the user is not a member of are not found.
+	Resolve(userID, workspaceID int) (*entity.Actor, error)
+	// AddMember adds the user with email to a workspace at a role. Only
+	// owners may add members.
+	AddMember(userID, workspaceID int, email string, role entity.Role) (*entity.Member, error)
+	// Members returns the members of a workspace, which guests may not see.
+	Members(userID, workspaceID int) ([]entity.Member, error)
 }
@@ -0,0 +1,203 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/oidc"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+var timeNow = time.Now
+
+// DefaultLoginTTL is how long the user has to log in at the provider.
+const DefaultLoginTTL = 10 * time.Minute
+
+// Claims are the names of the ID token claims a user is mapped from.
+type Claims struct {
+	Email         string
+	EmailVerified string
+	Name          string
+}
+
+// DefaultClaims are the standard claims of OpenID Connect Core 1.0.
+var DefaultClaims = Claims{Email: "email", EmailVerified: "email_verified", Name: "name"}
+
+type Option func(*Service)
+
+// WithClaims maps users from other claims, for providers that put the email
+// somewhere else, like upn.
+func WithClaims(claims Claims) Option {
+	return func(s *Service) {
+		s.claims = claims
+	}
+}
+
+// WithTrustedEmails takes every email from the provider as verified, for a
+// company provider that only hands out addresses it owns and does not send
+// email_verified.
+func WithTrustedEmails() Option {
+	return func(s *Service) {
+		s.trustEmails = true
+	}
+}
+
+// WithPostLogoutRedirect sets where the provider sends the user once they
+// are logged out there.
+func WithPostLogoutRedirect(url string) Option {
+	return func(s *Service) {
+		s.postLogoutRedirect = url
+	}
+}
+
+func WithLoginTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.loginTTL = ttl
+	}
+}
+
+// login is a login waiting for the provider to send the user back.
+type login struct {
+	nonce     string
+	verifier  string
+	expiresAt time.Time
+}
+
+// session is what logging out at the provider needs of a login.
+type session struct {
+	idToken   string
+	expiresAt time.Time
+}
+
+// Service logs users in with an OpenID Connect provider and issues them
+// the same access tokens as a password login. Logins in progress and the ID
+// tokens of sessions are only kept in memory, so a restart makes users
+// start their login over.
+type Service struct {
+	client             *oidc.Client
+	users              service.User
+	claims             Claims
+	trustEmails        bool
+	postLogoutRedirect string
+	loginTTL           time.Duration
+
+	mu     sync.Mutex
+	logins map[string]login
+	// sessions are keyed by the hash of the access token.
+	sessions map[[sha256.Size]byte]session
+}
+
+func NewService(client *oidc.Client, users service.User, opts ...Option) service.SSO {
+	s := &Service{
+		client:   client,
+		users:    users,
+		claims:   DefaultClaims,
+		loginTTL: DefaultLoginTTL,
+		logins:   make(map[string]login),
+		sessions: make(map[[sha256.Size]byte]session),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Service) Begin(ctx context.Context) (string, string, error) {
+	state := rand.Text()
+	l := login{nonce: rand.Text(), verifier: oidc.NewVerifier()}
+	authURL, err := s.client.AuthCodeURL(ctx, oidc.AuthRequest{State: state, Nonce: l.nonce, Verifier: l.verifier})
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timeNow()
+	s.prune(now)
+	l.expiresAt = now.Add(s.loginTTL)
+	s.logins[state] = l
+	return authURL, state, nil
+}
+
+// Complete fails with service.ErrUnauthorized when the state is unknown or
+// the provider does not vouch for the user, and each state can only
+// complete a login once.
+func (s *Service) Complete(ctx context.Context, state, code string) (*entity.User, *entity.AccessToken, error) {
+	s.mu.Lock()
+	l, ok := s.logins[state]
+	delete(s.logins, state)
+	s.mu.Unlock()
+	if !ok || !timeNow().Before(l.expiresAt) {
+		return nil, nil, fmt.Errorf("%w: login expired or was not started here", service.ErrUnauthorized)
+	}
+
+	tokens, err := s.client.Exchange(ctx, code, l.verifier)
+	if errors.Is(err, oidc.ErrExchange) {
+		return nil, nil, fmt.Errorf("%w: %w", service.ErrUnauthorized, err)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	idToken, err := s.client.Verify(ctx, tokens.IDToken, l.nonce)
+	if errors.Is(err, oidc.ErrIDToken) {
+		return nil, nil, fmt.Errorf("%w: %w", service.ErrUnauthorized, err)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	user, token, err := s.users.LoginExternal(s.externalUser(idToken))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sha256.Sum256([]byte(token.Value))] = session{idToken: tokens.IDToken, expiresAt: token.ExpiresAt}
+	return user, token, nil
+}
+
+func (s *Service) externalUser(idToken *oidc.IDToken) entity.ExternalUser {
+	return entity.ExternalUser{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         idToken.StringClaim(s.claims.Email),
+		EmailVerified: s.trustEmails || idToken.BoolClaim(s.claims.EmailVerified),
+		Name:          idToken.StringClaim(s.claims.Name),
+	}
+}
+
+func (s *Service) Logout(ctx context.Context, token string) (string, error) {
+	if err := s.users.Logout(token); err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(token))
+	s.mu.Lock()
+	sess, ok := s.sessions[key]
+	delete(s.sessions, key)
+	s.mu.Unlock()
+	if !ok {
+		return "", nil
+	}
+	return s.client.EndSessionURL(ctx, sess.idToken, s.postLogoutRedirect)
+}
+
+// prune forgets the logins and sessions that expired. It must be called
+// with mu held.
+func (s *Service) prune(now time.Time) {
+	for state, l := range s.logins {
+		if !now.Before(l.expiresAt) {
+			delete(s.logins, state)
+		}
+	}
+	for key, sess := range s.sessions {
+		if !now.Before(sess.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package sso
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/oidc"
+	"github.com/cloudingcity/todo/internal/oidc/oidctest"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var mockErr = errors.New("something wrong")
+
+const callbackURL = "https://todo.example.com/v1/sso/callback"
+
+type ssoSuite struct {
+	suite.Suite
+	provider  *oidctest.Provider
+	mockUsers *mocks.MockUser
+	ctx       context.Context
+	now       time.Time
+}
+
+func (s *ssoSuite) SetupSubTest() {
+	s.provider = oidctest.NewProvider("todo", "secret")
+	s.provider.SetUser(map[string]any{"sub": "sub-1", "email": "jane@example.com", "email_verified": true, "name": "Jane"})
+	s.mockUsers = mocks.NewMockUser(gomock.NewController(s.T()))
+	s.ctx = context.Background()
+	s.now = time.Now()
+	timeNow = func() time.Time {
+		return s.now
+	}
+}
+
+func (s *ssoSuite) TearDownSubTest() {
+	s.provider.Close()
+	timeNow = time.Now
+}
+
+func TestSSOSuite(t *testing.T) {
+	suite.Run(t, new(ssoSuite))
+}
+
+func (s *ssoSuite) newService(opts ...Option) service.SSO {
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       s.provider.Issuer(),
+		ClientID:     "todo",
+		ClientSecret: "secret",
+		RedirectURL:  callbackURL,
+		HTTPClient:   s.provider.Client(),
+	})
+	return NewService(client, s.mockUsers, opts...)
+}
+
+// login begins a login and goes through the provider, returning the state
+// and code it sent the user back with.
+func (s *ssoSuite) login(srv service.SSO) (string, string) {
+	authURL, state, err := srv.Begin(s.ctx)
+	s.Require().NoError(err)
+	back, err := s.provider.Login(authURL)
+	s.Require().NoError(err)
+	s.Require().Equal(state, back.Query().Get("state"))
+	return state, back.Query().Get("code")
+}
+
+func (s *ssoSuite) TestBegin() {
+	s.Run("success", func() {
+		authURL, state, err := s.newService().Begin(s.ctx)
+		s.Require().NoError(err)
+		s.True(strings.HasPrefix(authURL, s.provider.URL+"/authorize?"), authURL)
+		u, err := url.Parse(authURL)
+		s.Require().NoError(err)
+		s.Equal(state, u.Query().Get("state"))
+		s.Equal(callbackURL, u.Query().Get("redirect_uri"))
+		s.NotEmpty(u.Query().Get("nonce"))
+		s.NotEmpty(u.Query().Get("code_challenge"))
+	})
+	s.Run("provider down", func() {
+		srv := s.newService()
+		s.provider.Close()
+
+		_, _, err := srv.Begin(s.ctx)
+		s.ErrorIs(err, oidc.ErrDiscovery)
+	})
+}
+
+func (s *ssoSuite) TestComplete() {
+	s.Run("success", func() {
+		srv := s.newService()
+		state, code := s.login(srv)
+		user := &entity.User{ID: 1, Email: "jane@example.com"}
+		token := &entity.AccessToken{Value: "token-1", ExpiresAt: s.now.Add(time.Hour)}
+		s.mockUsers.EXPECT().LoginExternal(entity.ExternalUser{
+			Issuer:        s.provider.Issuer(),
+			Subject:       "sub-1",
+			Email:         "jane@example.com",
+			EmailVerified: true,
+			Name:          "Jane",
+		}).Return(user, token, nil).Times(1)
+
+		gotUser, gotToken, err := srv.Complete(s.ctx, state, code)
+		s.Require().NoError(err)
+		s.Equal(user, gotUser)
+		s.Equal(token, gotToken)
+	})
+	s.Run("claims mapped", func() {
+		s.provider.SetUser(map[string]any{"sub": "sub-1", "upn": "jane@example.com", "given_name": "Jane"})
+		srv := s.newService(WithClaims(Claims{Email: "upn", Name: "given_name"}), WithTrustedEmails())
+		state, code := s.login(srv)
+		s.mockUsers.EXPECT().LoginExternal(entity.ExternalUser{
+			Issuer:        s.provider.Issuer(),
+			Subject:       "sub-1",
+			Email:         "jane@example.com",
+			EmailVerified: true,
+			Name:          "Jane",
+		}).Return(&entity.User{ID: 1}, &entity.AccessToken{Value: "token-1"}, nil).Times(1)
+
+		_, _, err := srv.Complete(s.ctx, state, code)
+		s.NoError(err)
+	})
+	s.Run("email not verified", func() {
+		s.provider.SetUser(map[string]any{"sub": "sub-1", "email": "jane@example.com"})
+		srv := s.newService()
+		state, code := s.login(srv)
+		s.mockUsers.EXPECT().LoginExternal(entity.ExternalUser{
+			Issuer:  s.provider.Issuer(),
+			Subject: "sub-1",
+			Email:   "jane@example.com",
+		}).Return(nil, nil, service.ErrUnauthorized).Times(1)
+
+		_, _, err := srv.Complete(s.ctx, state, code)
+		s.ErrorIs(err, service.ErrUnauthorized)
+	})
+
+	tests := []struct {
+		desc    string
+		state   func(state string) string
+		code    func(code string) string
+		setup   func(srv service.SSO, state, code string)
+		wantErr error
+	}{
+		{
+			desc:    "unknown state",
+			state:   func(string) string { return "other" },
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:  "state used twice",
+			state: func(state string) string { return state },
+			setup: func(srv service.SSO, state, code string) {
+				s.mockUsers.EXPECT().LoginExternal(gomock.Any()).Return(&entity.User{ID: 1}, &entity.AccessToken{Value: "token-1"}, nil).Times(1)
+				_, _, err := srv.Complete(s.ctx, state, code)
+				s.Require().NoError(err)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:  "login expired",
+			state: func(state string) string { return state },
+			setup: func(service.SSO, string, string) {
+				s.now = s.now.Add(DefaultLoginTTL)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:    "code refused",
+			state:   func(state string) string { return state },
+			code:    func(string) string { return "made-up" },
+			wantErr: oidc.ErrExchange,
+		},
+		{
+			desc:  "user service error",
+			state: func(state string) string { return state },
+			setup: func(service.SSO, string, string) {
+				s.mockUsers.EXPECT().LoginExternal(gomock.Any()).Return(nil, nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			srv := s.newService()
+			state, code := s.login(srv)
+			if tt.setup != nil {
+				tt.setup(srv, state, code)
+			}
+			if tt.code != nil {
+				code = tt.code(code)
+			}
+
+			_, _, err := srv.Complete(s.ctx, tt.state(state), code)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *ssoSuite) TestLogout() {
+	s.Run("ends the session at the provider", func() {
+		srv := s.newService(WithPostLogoutRedirect("https://todo.example.com/"))
+		state, code := s.login(srv)
+		s.mockUsers.EXPECT().LoginExternal(gomock.Any()).Return(&entity.User{ID: 1}, &entity.AccessToken{Value: "token-1", ExpiresAt: s.now.Add(time.Hour)}, nil).Times(1)
+		_, _, err := srv.Complete(s.ctx, state, code)
+		s.Require().NoError(err)
+		s.mockUsers.EXPECT().Logout("token-1").Return(nil).Times(2)
+
+		got, err := srv.Logout(s.ctx, "token-1")
+		s.Require().NoError(err)
+		u, err := url.Parse(got)
+		s.Require().NoError(err)
+		s.Equal(s.provider.URL+"/logout", u.Scheme+"://"+u.Host+u.Path)
+		s.Equal("https://todo.example.com/", u.Query().Get("post_logout_redirect_uri"))
+		s.Equal("sub-1", s.decodeSubject(u.Query().Get("id_token_hint")))
+
+		got, err = srv.Logout(s.ctx, "token-1")
+		s.Require().NoError(err)
+		s.Empty(got, "the session is forgotten")
+	})
+	s.Run("password session", func() {
+		s.mockUsers.EXPECT().Logout("token-1").Return(nil).Times(1)
+
+		got, err := s.newService().Logout(s.ctx, "token-1")
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+	s.Run("invalid token", func() {
+		s.mockUsers.EXPECT().Logout("token-1").Return(service.ErrUnauthorized).Times(1)
+
+		_, err := s.newService().Logout(s.ctx, "token-1")
+		s.ErrorIs(err, service.ErrUnauthorized)
+	})
+}
+
+// decodeSubject returns the sub claim of an ID token without verifying it.
+func (s *ssoSuite) decodeSubject(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	s.Require().Len(parts, 3)
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	s.Require().NoError(err)
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &claims))
+	return claims.Subject
+}
@@ -0,0 +1,285 @@
+package timeentry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/policy"
+)
+
+var timeNow = time.Now
+
+// Service manages time entries. Todos and projects are reached through
+// their services, so time is only as visible as the todo it was tracked on.
+// Entries of deleted todos are left out of totals and reports.
+type Service struct {
+	repo     repo.TimeEntry
+	todos    service.Todo
+	projects service.Project
+	// actor is who the service is scoped to, or nil for the unscoped
+	// service.
+	actor *entity.Actor
+}
+
+func NewService(repo repo.TimeEntry, todos service.Todo, projects service.Project) service.TimeEntry {
+	return &Service{
+		repo:     repo,
+		todos:    todos,
+		projects: projects,
+	}
+}
+
+// ForWorkspace returns a copy of the service working on the time entries,
+// todos and projects of another workspace. Timers span workspaces.
+func (s *Service) ForWorkspace(workspaceID int) service.TimeEntry {
+	scoped := *s
+	scoped.repo = s.repo.ForWorkspace(workspaceID)
+	scoped.todos = s.todos.ForWorkspace(workspaceID)
+	scoped.projects = s.projects.ForWorkspace(workspaceID)
+	return &scoped
+}
+
+// As scopes a copy of the service, and the services it uses, to actor.
+func (s *Service) As(actor entity.Actor) service.TimeEntry {
+	scoped := *s
+	scoped.actor = &actor
+	scoped.todos = s.todos.As(actor)
+	scoped.projects = s.projects.As(actor)
+	return &scoped
+}
+
+func (s *Service) Start(todoID int, note string) (*entity.TimeEntry, *entity.TimeEntry, error) {
+	if err := s.checkTrack(todoID); err != nil {
+		return nil, nil, err
+	}
+	now := timeNow()
+	entry, err := entity.NormalizeTimeEntry(entity.TimeEntry{Start: now, Note: note}, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	started, stopped, err := s.repo.Start(entity.CreateTimeEntryInput{
+		TodoID: todoID,
+		UserID: s.actorID(),
+		Start:  entry.Start,
+		Note:   entry.Note,
+	})
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+	return started, stopped, nil
+}
+
+func (s *Service) Stop() (*entity.TimeEntry, error) {
+	entry, err := s.repo.Stop(s.actorID(), timeNow())
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return entry, nil
+}
+
+func (s *Service) Running() (*entity.TimeEntry, error) {
+	entry, err := s.repo.Running(s.actorID())
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return entry, nil
+}
+
+func (s *Service) Create(todoID int, start, end time.Time, note string) (*entity.TimeEntry, error) {
+	if err := s.checkTrack(todoID); err != nil {
+		return nil, err
+	}
+	entry, err := entity.NormalizeTimeEntry(entity.TimeEntry{Start: start, End: &end, Note: note}, timeNow())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	created, err := s.repo.Create(entity.CreateTimeEntryInput{
+		TodoID: todoID,
+		UserID: s.actorID(),
+		Start:  entry.Start,
+		End:    entry.End,
+		Note:   entry.Note,
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return created, nil
+}
+
+func (s *Service) List(todoID int, query entity.PageQuery) (*entity.Page[entity.TimeEntry], error) {
+	if _, err := s.todos.Get(todoID); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.List(todoID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	page, err := entity.Paginate(entries, query, entity.TimeEntry.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	return page, nil
+}
+
+func (s *Service) Get(todoID, id int) (*entity.TimeEntry, error) {
+	_, entry, err := s.get(todoID, id)
+	return entry, err
+}
+
+func (s *Service) Update(todoID, id int, input entity.UpdateTimeEntryInput) (*entity.TimeEntry, error) {
+	entry, err := s.getOwn(todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	input.Apply(entry)
+	normalized, err := entity.NormalizeTimeEntry(*entry, timeNow())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	if input.Note != nil {
+		input.Note = &normalized.Note
+	}
+	updated, err := s.repo.Update(id, input)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return updated, nil
+}
+
+func (s *Service) Delete(todoID, id int) error {
+	if _, err := s.getOwn(todoID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *Service) TodoTotals(todoID int) (entity.TimeTotals, entity.TimeTotals, error) {
+	todos, err := s.todos.Subtree(todoID)
+	if err != nil {
+		return entity.TimeTotals{}, entity.TimeTotals{}, err
+	}
+	entries, err := s.repo.ListAll()
+	if err != nil {
+		return entity.TimeTotals{}, entity.TimeTotals{}, mapError(err)
+	}
+	now := timeNow()
+	own := entity.TimeTotals{}.Add(todos[0], entries, now)
+	return own, sum(todos, entries, now), nil
+}
+
+func (s *Service) ProjectTotals(projectID int) (entity.TimeTotals, error) {
+	todos, err := s.projects.ListTodos(projectID, entity.TodoQuery{})
+	if err != nil {
+		return entity.TimeTotals{}, err
+	}
+	entries, err := s.repo.ListAll()
+	if err != nil {
+		return entity.TimeTotals{}, mapError(err)
+	}
+	return sum(todos, entries, timeNow()), nil
+}
+
+func (s *Service) Report(query entity.TimeReportQuery) (*entity.TimeReport, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	todos, err := s.todos.List(entity.TodoQuery{Project: query.Project})
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		visible[todo.ID] = true
+	}
+	entries, err := s.repo.ListAll()
+	if err != nil {
+		return nil, mapError(err)
+	}
+	var selected []entity.TimeEntry
+	for _, entry := range entries {
+		if visible[entry.TodoID] && query.Match(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	report := entity.NewTimeReport(selected, query, timeNow())
+	return &report, nil
+}
+
+// checkTrack checks that the actor may track time on a todo, which takes
+// being allowed to update it.
+func (s *Service) checkTrack(todoID int) error {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return err
+	}
+	if s.actor != nil {
+		return policy.Check(*s.actor, entity.ActionUpdate, policy.Todo(*todo))
+	}
+	return nil
+}
+
+// get returns an entry along with its todo, which the actor must be able to
+// read. Entries of other todos are not found.
+func (s *Service) get(todoID, id int) (*entity.Todo, *entity.TimeEntry, error) {
+	todo, err := s.todos.Get(todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, err := s.repo.Get(id)
+	if err != nil {
+		return nil, nil, mapError(err)
+	}
+	if entry.TodoID != todoID {
+		return nil, nil, service.ErrNotFound
+	}
+	return todo, entry, nil
+}
+
+// getOwn returns an entry the actor may change, which are the entries they
+// tracked themselves and, for owners, those of everyone else.
+func (s *Service) getOwn(todoID, id int) (*entity.TimeEntry, error) {
+	todo, entry, err := s.get(todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.actor != nil {
+		resource := policy.Resource{OwnerID: entry.UserID, ProjectID: todo.ProjectID}
+		if err := policy.Check(*s.actor, entity.ActionDelete, resource); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+func (s *Service) actorID() int {
+	if s.actor == nil {
+		return 0
+	}
+	return s.actor.UserID
+}
+
+// sum adds up the totals of todos.
+func sum(todos []entity.Todo, entries []entity.TimeEntry, now time.Time) entity.TimeTotals {
+	var totals entity.TimeTotals
+	for _, todo := range todos {
+		totals = totals.Add(todo, entries, now)
+	}
+	return totals
+}
+
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		return service.ErrNotFound
+	case errors.Is(err, entity.ErrNoRunningTimer):
+		return fmt.Errorf("%w: %w", service.ErrNotFound, err)
+	}
+	return err
+}
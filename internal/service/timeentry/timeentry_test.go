@@ -0,0 +1,360 @@
+package timeentry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	repomocks "github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/mocks"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+// now is a Monday.
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+type timeEntrySuite struct {
+	suite.Suite
+	srv         *Service
+	mockRepo    *repomocks.MockTimeEntry
+	mockTodo    *mocks.MockTodo
+	mockProject *mocks.MockProject
+}
+
+func (s *timeEntrySuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = repomocks.NewMockTimeEntry(ctrl)
+	s.mockTodo = mocks.NewMockTodo(ctrl)
+	s.mockProject = mocks.NewMockProject(ctrl)
+	s.srv = NewService(s.mockRepo, s.mockTodo, s.mockProject).(*Service)
+	timeNow = func() time.Time {
+		return now
+	}
+}
+
+func (s *timeEntrySuite) TearDownSubTest() {
+	timeNow = time.Now
+}
+
+func TestTimeEntrySuite(t *testing.T) {
+	suite.Run(t, new(timeEntrySuite))
+}
+
+// as scopes the service to actor, with the todo and project services as
+// they would be.
+func (s *timeEntrySuite) as(actor entity.Actor) service.TimeEntry {
+	s.mockTodo.EXPECT().As(actor).Return(s.mockTodo).Times(1)
+	s.mockProject.EXPECT().As(actor).Return(s.mockProject).Times(1)
+	return s.srv.As(actor)
+}
+
+func stopped(id, todoID, userID int, start, end time.Time) entity.TimeEntry {
+	return entity.TimeEntry{ID: id, TodoID: todoID, UserID: userID, Start: start, End: &end}
+}
+
+func (s *timeEntrySuite) TestStart() {
+	todo := &entity.Todo{ID: 1, ProjectID: 2}
+	editor := entity.Actor{UserID: 3, Role: entity.RoleEditor}
+
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		started := &entity.TimeEntry{ID: 2}
+		previous := &entity.TimeEntry{ID: 1}
+		s.mockRepo.EXPECT().Start(entity.CreateTimeEntryInput{TodoID: 1, UserID: 3, Start: now, Note: "review"}).Return(started, previous, nil).Times(1)
+
+		gotStarted, gotStopped, err := s.as(editor).Start(1, " review ")
+		s.Require().NoError(err)
+		s.Equal(started, gotStarted)
+		s.Equal(previous, gotStopped)
+	})
+	s.Run("viewer", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+
+		_, _, err := s.as(entity.Actor{UserID: 3, Role: entity.RoleViewer}).Start(1, "")
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("note too long", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+
+		_, _, err := s.as(editor).Start(1, strings.Repeat("x", entity.MaxTimeEntryNoteLength+1))
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrTimeEntryNoteTooLong)
+	})
+	s.Run("todo not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(nil, service.ErrNotFound).Times(1)
+
+		_, _, err := s.as(editor).Start(1, "")
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *timeEntrySuite) TestStop() {
+	editor := entity.Actor{UserID: 3, Role: entity.RoleEditor}
+
+	s.Run("success", func() {
+		want := &entity.TimeEntry{ID: 1}
+		s.mockRepo.EXPECT().Stop(3, now).Return(want, nil).Times(1)
+
+		got, err := s.as(editor).Stop()
+		s.Require().NoError(err)
+		s.Equal(want, got)
+	})
+	s.Run("nothing running", func() {
+		s.mockRepo.EXPECT().Stop(3, now).Return(nil, entity.ErrNoRunningTimer).Times(1)
+
+		_, err := s.as(editor).Stop()
+		s.ErrorIs(err, service.ErrNotFound)
+		s.ErrorIs(err, entity.ErrNoRunningTimer)
+	})
+}
+
+func (s *timeEntrySuite) TestCreate() {
+	todo := &entity.Todo{ID: 1}
+	editor := entity.Actor{UserID: 3, Role: entity.RoleEditor}
+	start := now.Add(-2 * time.Hour)
+
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		want := &entity.TimeEntry{ID: 1}
+		s.mockRepo.EXPECT().Create(entity.CreateTimeEntryInput{TodoID: 1, UserID: 3, Start: start, End: &now, Note: "call"}).Return(want, nil).Times(1)
+
+		got, err := s.as(editor).Create(1, start, now, "call ")
+		s.Require().NoError(err)
+		s.Equal(want, got)
+	})
+	tests := []struct {
+		desc    string
+		start   time.Time
+		end     time.Time
+		wantErr error
+	}{
+		{desc: "ends before it starts", start: start, end: start.Add(-time.Minute), wantErr: entity.ErrTimeEntryRange},
+		{desc: "empty", start: start, end: start, wantErr: entity.ErrTimeEntryRange},
+		{desc: "ends in the future", start: start, end: now.Add(time.Minute), wantErr: entity.ErrTimeEntryInFuture},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+
+			_, err := s.as(editor).Create(1, tt.start, tt.end, "")
+			s.ErrorIs(err, service.ErrInvalidInput)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *timeEntrySuite) TestList() {
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		entries := []entity.TimeEntry{
+			stopped(2, 1, 3, now.Add(-3*time.Hour), now.Add(-2*time.Hour)),
+			stopped(1, 1, 3, now.Add(-time.Hour), now),
+		}
+		s.mockRepo.EXPECT().List(1).Return(entries, nil).Times(1)
+
+		got, err := s.srv.List(1, entity.PageQuery{Limit: 1})
+		s.Require().NoError(err)
+		s.Equal(entries[:1], got.Items)
+		s.NotEmpty(got.NextCursor)
+	})
+}
+
+func (s *timeEntrySuite) TestUpdate() {
+	todo := &entity.Todo{ID: 1, ProjectID: 2}
+	entry := stopped(5, 1, 3, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	s.Run("own entry", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+		want := &entity.TimeEntry{ID: 5}
+		s.mockRepo.EXPECT().Update(5, entity.UpdateTimeEntryInput{Note: lo.ToPtr("call")}).Return(want, nil).Times(1)
+
+		got, err := s.as(entity.Actor{UserID: 3, Role: entity.RoleEditor}).Update(1, 5, entity.UpdateTimeEntryInput{Note: lo.ToPtr(" call ")})
+		s.Require().NoError(err)
+		s.Equal(want, got)
+	})
+	s.Run("entry of another user", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+
+		_, err := s.as(entity.Actor{UserID: 4, Role: entity.RoleEditor}).Update(1, 5, entity.UpdateTimeEntryInput{Note: lo.ToPtr("call")})
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("owner edits any entry", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+		s.mockRepo.EXPECT().Update(5, gomock.Any()).Return(&entity.TimeEntry{ID: 5}, nil).Times(1)
+
+		_, err := s.as(entity.Actor{UserID: 4, Role: entity.RoleOwner}).Update(1, 5, entity.UpdateTimeEntryInput{Note: lo.ToPtr("call")})
+		s.NoError(err)
+	})
+	s.Run("ends before it starts", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+
+		_, err := s.srv.Update(1, 5, entity.UpdateTimeEntryInput{Start: lo.ToPtr(now.Add(-30 * time.Minute))})
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrTimeEntryRange)
+	})
+	s.Run("entry of another todo", func() {
+		s.mockTodo.EXPECT().Get(2).Return(&entity.Todo{ID: 2}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+
+		_, err := s.srv.Update(2, 5, entity.UpdateTimeEntryInput{Note: lo.ToPtr("call")})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *timeEntrySuite) TestDelete() {
+	todo := &entity.Todo{ID: 1, ProjectID: 2}
+	entry := stopped(5, 1, 3, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	s.Run("success", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(&entry, nil).Times(1)
+		s.mockRepo.EXPECT().Delete(5).Return(nil).Times(1)
+
+		s.NoError(s.as(entity.Actor{UserID: 3, Role: entity.RoleEditor}).Delete(1, 5))
+	})
+	s.Run("not found", func() {
+		s.mockTodo.EXPECT().Get(1).Return(todo, nil).Times(1)
+		s.mockRepo.EXPECT().Get(5).Return(nil, repo.ErrNotFound).Times(1)
+
+		s.ErrorIs(s.srv.Delete(1, 5), service.ErrNotFound)
+	})
+}
+
+func (s *timeEntrySuite) TestTotals() {
+	todos := []entity.Todo{
+		{ID: 1, Estimate: time.Hour},
+		{ID: 2, ParentID: 1, Estimate: 30 * time.Minute},
+	}
+	entries := []entity.TimeEntry{
+		stopped(1, 1, 3, now.Add(-time.Hour), now.Add(-40*time.Minute)),
+		stopped(2, 2, 3, now.Add(-30*time.Minute), now.Add(-20*time.Minute)),
+		stopped(3, 9, 3, now.Add(-20*time.Minute), now.Add(-15*time.Minute)),
+		{ID: 4, TodoID: 2, UserID: 4, Start: now.Add(-5 * time.Minute)},
+	}
+
+	s.Run("todo", func() {
+		s.mockTodo.EXPECT().Subtree(1).Return(todos, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+
+		own, withSubtasks, err := s.srv.TodoTotals(1)
+		s.Require().NoError(err)
+		s.Equal(entity.TimeTotals{Estimate: time.Hour, Tracked: 20 * time.Minute}, own)
+		s.Equal(entity.TimeTotals{Estimate: 90 * time.Minute, Tracked: 35 * time.Minute}, withSubtasks, "running timers count up to now")
+		s.Equal(55*time.Minute, withSubtasks.Remaining())
+	})
+	s.Run("project", func() {
+		s.mockProject.EXPECT().ListTodos(7, entity.TodoQuery{}).Return(todos, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+
+		got, err := s.srv.ProjectTotals(7)
+		s.Require().NoError(err)
+		s.Equal(entity.TimeTotals{Estimate: 90 * time.Minute, Tracked: 35 * time.Minute}, got)
+	})
+	s.Run("project not found", func() {
+		s.mockProject.EXPECT().ListTodos(7, entity.TodoQuery{}).Return(nil, service.ErrNotFound).Times(1)
+
+		_, err := s.srv.ProjectTotals(7)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *timeEntrySuite) TestReport() {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 10, day, hour, 0, 0, 0, taipei)
+	}
+	entries := []entity.TimeEntry{
+		// Spans midnight, so it is split between two days.
+		stopped(1, 1, 3, at(12, 22), at(13, 1)),
+		stopped(2, 1, 4, at(13, 9), at(13, 10)),
+		// Before the report.
+		stopped(3, 1, 3, at(10, 9), at(10, 10)),
+		// On a todo the actor may not read.
+		stopped(4, 9, 3, at(13, 9), at(13, 10)),
+		// Running since 18:00 in Taipei, which is 10:00 UTC on the 19th.
+		{ID: 5, TodoID: 1, UserID: 3, Start: at(19, 18)},
+	}
+	query := entity.TimeReportQuery{
+		From:     at(12, 0),
+		To:       at(19, 0),
+		GroupBy:  entity.TimeReportDay,
+		Location: taipei,
+	}
+
+	s.Run("by day", func() {
+		s.mockTodo.EXPECT().List(entity.TodoQuery{}).Return([]entity.Todo{{ID: 1}}, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+
+		got, err := s.srv.Report(query)
+		s.Require().NoError(err)
+		s.Len(got.Rows, 8)
+		s.Equal(entity.TimeReportRow{Period: at(12, 0), Tracked: 2 * time.Hour, Entries: 1}, got.Rows[0])
+		s.Equal(entity.TimeReportRow{Period: at(13, 0), Tracked: 2 * time.Hour, Entries: 2}, got.Rows[1])
+		s.Equal(entity.TimeReportRow{Period: at(14, 0)}, got.Rows[2])
+		s.Equal(entity.TimeReportRow{Period: at(19, 0), Tracked: 2 * time.Hour, Entries: 1}, got.Rows[7])
+		s.Equal(6*time.Hour, got.Tracked)
+	})
+	s.Run("by week of one user", func() {
+		s.mockTodo.EXPECT().List(entity.TodoQuery{}).Return([]entity.Todo{{ID: 1}}, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+		byWeek := query
+		byWeek.GroupBy = entity.TimeReportWeek
+		byWeek.UserID = 3
+
+		got, err := s.srv.Report(byWeek)
+		s.Require().NoError(err)
+		s.Equal([]entity.TimeReportRow{
+			{Period: at(12, 0), Tracked: 3 * time.Hour, Entries: 1},
+			{Period: at(19, 0), Tracked: 2 * time.Hour, Entries: 1},
+		}, got.Rows)
+	})
+	s.Run("weeks start on Monday", func() {
+		s.mockTodo.EXPECT().List(entity.TodoQuery{}).Return([]entity.Todo{{ID: 1}}, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+		midweek := query
+		midweek.GroupBy = entity.TimeReportWeek
+		midweek.From = at(13, 0)
+
+		got, err := s.srv.Report(midweek)
+		s.Require().NoError(err)
+		s.Equal(at(12, 0), got.Rows[0].Period)
+		s.Equal(2*time.Hour, got.Rows[0].Tracked, "days before the report are left out of its first week")
+	})
+	s.Run("project", func() {
+		project := 7
+		s.mockTodo.EXPECT().List(entity.TodoQuery{Project: &project}).Return(nil, nil).Times(1)
+		s.mockRepo.EXPECT().ListAll().Return(entries, nil).Times(1)
+		inProject := query
+		inProject.Project = &project
+
+		got, err := s.srv.Report(inProject)
+		s.Require().NoError(err)
+		s.Zero(got.Tracked)
+	})
+	tests := []struct {
+		desc    string
+		query   entity.TimeReportQuery
+		wantErr error
+	}{
+		{desc: "ends before it starts", query: entity.TimeReportQuery{From: at(19, 0), To: at(12, 0), GroupBy: entity.TimeReportDay}, wantErr: entity.ErrTimeReportRange},
+		{desc: "too long", query: entity.TimeReportQuery{From: at(1, 0), To: at(1, 0).AddDate(1, 1, 0), GroupBy: entity.TimeReportDay}, wantErr: entity.ErrTimeReportRange},
+		{desc: "unknown unit", query: entity.TimeReportQuery{From: at(12, 0), To: at(19, 0), GroupBy: "month"}, wantErr: entity.ErrUnknownTimeReportUnit},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			_, err := s.srv.Report(tt.query)
+			s.ErrorIs(err, service.ErrInvalidInput)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
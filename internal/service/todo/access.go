@@ -0,0 +1,112 @@
+package todo
+
+import (
+	"errors"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/cloudingcity/todo/internal/service/policy"
+)
+
+// ForWorkspace returns a copy of the service working on the todos of
+// another workspace.
+func (s *Service) ForWorkspace(workspaceID int) service.Todo {
+	scoped := *s
+	scoped.repo = s.repo.ForWorkspace(workspaceID)
+	if s.history != nil {
+		scoped.history = s.history.ForWorkspace(workspaceID)
+	}
+	return &scoped
+}
+
+// As scopes a copy of the service to actor. The unscoped service may do
+// anything with every todo and is meant for background jobs.
+func (s *Service) As(actor entity.Actor) service.Todo {
+	scoped := *s
+	scoped.actor = &actor
+	return &scoped
+}
+
+// visible reports whether the actor may read todo.
+func (s *Service) visible(todo entity.Todo) bool {
+	return s.actor == nil || policy.Allowed(*s.actor, entity.ActionRead, policy.Todo(todo))
+}
+
+// check fails unless the actor may take action on todo. A todo the actor
+// may not read fails with notFound, so it cannot be told apart from a
+// missing one.
+func (s *Service) check(todo entity.Todo, action entity.Action, notFound error) error {
+	if s.actor == nil {
+		return nil
+	}
+	err := policy.Check(*s.actor, action, policy.Todo(todo))
+	if errors.Is(err, service.ErrNotFound) {
+		return mapError(notFound)
+	}
+	return err
+}
+
+// authorize does check for the todo with id, failing with notFound when it
+// is missing.
+func (s *Service) authorize(id int, action entity.Action, notFound error) error {
+	if s.actor == nil {
+		return nil
+	}
+	todo, err := s.repo.Get(id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return mapError(notFound)
+	} else if err != nil {
+		return err
+	}
+	return s.check(*todo, action, notFound)
+}
+
+// authorizeAll does authorize for every todo of a batch.
+func (s *Service) authorizeAll(ids []int, action entity.Action) error {
+	for i, id := range ids {
+		if err := s.authorize(id, action, repo.ErrNotFound); err != nil {
+			return &service.BatchError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// checkCreate stamps the actor as the owner on input and makes sure they
+// may create todos in its project and read its parent, and that its
+// description fits the quota.
+func (s *Service) checkCreate(input *entity.CreateTodoInput) error {
+	if s.actor == nil {
+		return nil
+	}
+	input.OwnerID = s.actor.UserID
+	if err := policy.Check(*s.actor, entity.ActionCreate, policy.In(input.ProjectID)); err != nil {
+		return err
+	}
+	if err := s.checkDescription(&input.Description); err != nil {
+		return err
+	}
+	if input.ParentID != 0 {
+		return s.authorize(input.ParentID, entity.ActionRead, entity.ErrParentNotFound)
+	}
+	return nil
+}
+
+// checkProject makes sure the actor may move a todo into the project with
+// projectID, which is a create there. A nil projectID leaves the project
+// alone.
+func (s *Service) checkProject(projectID *int) error {
+	if s.actor == nil || projectID == nil {
+		return nil
+	}
+	return policy.Check(*s.actor, entity.ActionCreate, policy.In(*projectID))
+}
+
+// checkWorkspace fails unless the actor may take action on what belongs
+// to the workspace as a whole, like tags.
+func (s *Service) checkWorkspace(action entity.Action) error {
+	if s.actor == nil {
+		return nil
+	}
+	return policy.Check(*s.actor, action, policy.Workspace())
+}
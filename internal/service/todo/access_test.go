@@ -0,0 +1,424 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+// TestAsGuest runs every todo operation as guest 1 against todo 2, which
+// belongs to user 2 and is in no project shared with them, and expects it to
+// look missing.
+func (s *todoSuite) TestAsGuest() {
+	guest := entity.Actor{UserID: 1, WorkspaceID: 1, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{4: entity.RoleEditor}}
+	mine := &entity.Todo{ID: 1, OwnerID: 1, ProjectID: 4}
+	theirs := &entity.Todo{ID: 2, OwnerID: 2}
+	expectGet := func() {
+		s.mockRepo.EXPECT().Get(1).Return(mine, nil).AnyTimes()
+		s.mockRepo.EXPECT().Get(2).Return(theirs, nil).AnyTimes()
+	}
+
+	tests := []struct {
+		desc    string
+		call    func(srv service.Todo) error
+		wantErr error
+	}{
+		{
+			desc: "get",
+			call: func(srv service.Todo) error {
+				_, err := srv.Get(2)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "update",
+			call: func(srv service.Todo) error {
+				return srv.Update(2, entity.UpdateTodoInput{Title: lo.ToPtr("mine")})
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "complete",
+			call: func(srv service.Todo) error {
+				return srv.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)})
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "update func",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().UpdateFunc(2, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+					return fn(lo.ToPtr(*theirs))
+				})
+				return srv.UpdateFunc(2, func(*entity.Todo) error {
+					s.Fail("fn must not see the todo")
+					return nil
+				})
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "replace",
+			call: func(srv service.Todo) error {
+				_, err := srv.Replace(2, entity.ReplaceTodoInput{Title: "mine"})
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "upsert",
+			call: func(srv service.Todo) error {
+				_, _, err := srv.Upsert(2, entity.ReplaceTodoInput{Title: "mine"})
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "delete",
+			call: func(srv service.Todo) error {
+				return srv.Delete(2, entity.SubtaskDeleteCascade)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "move",
+			call: func(srv service.Todo) error {
+				_, err := srv.Move(2, entity.MoveTodoInput{After: lo.ToPtr(1)})
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "move after their todo",
+			call: func(srv service.Todo) error {
+				_, err := srv.Move(1, entity.MoveTodoInput{After: lo.ToPtr(2)})
+				return err
+			},
+			wantErr: entity.ErrMoveTarget,
+		},
+		{
+			desc: "create below their todo",
+			call: func(srv service.Todo) error {
+				_, err := srv.Create(entity.CreateTodoInput{Title: "sub", ProjectID: 4, ParentID: 2})
+				return err
+			},
+			wantErr: entity.ErrParentNotFound,
+		},
+		{
+			desc: "set parent",
+			call: func(srv service.Todo) error {
+				_, err := srv.SetParent(1, 2)
+				return err
+			},
+			wantErr: entity.ErrParentNotFound,
+		},
+		{
+			desc: "subtree",
+			call: func(srv service.Todo) error {
+				_, err := srv.Subtree(2)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "blocked by their todo",
+			call: func(srv service.Todo) error {
+				_, err := srv.AddDependency(1, 2)
+				return err
+			},
+			wantErr: entity.ErrBlockerNotFound,
+		},
+		{
+			desc: "remove dependency",
+			call: func(srv service.Todo) error {
+				return srv.RemoveDependency(2, 1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "dependencies",
+			call: func(srv service.Todo) error {
+				_, err := srv.Dependencies(2)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "occurrences",
+			call: func(srv service.Todo) error {
+				_, err := srv.Occurrences(2, 1)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "attach tags",
+			call: func(srv service.Todo) error {
+				_, err := srv.AttachTags(2, []string{"work"})
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "detach tags",
+			call: func(srv service.Todo) error {
+				_, err := srv.DetachTags(2, []string{"work"})
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "atomic batch update",
+			call: func(srv service.Todo) error {
+				_, err := srv.BatchUpdate([]entity.BatchUpdateTodoInput{{ID: 1}, {ID: 2}}, entity.BatchAtomic)
+				s.Equal(&service.BatchError{Index: 1, Err: service.ErrNotFound}, err)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "atomic batch delete",
+			call: func(srv service.Todo) error {
+				_, err := srv.BatchDelete([]int{2}, entity.BatchAtomic)
+				s.Equal(&service.BatchError{Index: 0, Err: service.ErrNotFound}, err)
+				return err
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "best-effort batch delete",
+			call: func(srv service.Todo) error {
+				results, err := srv.BatchDelete([]int{2}, entity.BatchBestEffort)
+				s.Require().NoError(err)
+				return results[0].Err
+			},
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			expectGet()
+
+			err := tt.call(s.srv.As(guest))
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+// TestAsViewer runs every change as a viewer, who sees the todos and gets
+// forbidden rather than not found.
+func (s *todoSuite) TestAsViewer() {
+	viewer := entity.Actor{UserID: 1, WorkspaceID: 1, Role: entity.RoleViewer}
+	expectGet := func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 1}, nil).AnyTimes()
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2, OwnerID: 2}, nil).AnyTimes()
+	}
+
+	calls := map[string]func(srv service.Todo) error{
+		"create": func(srv service.Todo) error {
+			_, err := srv.Create(entity.CreateTodoInput{Title: "new"})
+			return err
+		},
+		"update": func(srv service.Todo) error {
+			return srv.Update(2, entity.UpdateTodoInput{Title: lo.ToPtr("mine")})
+		},
+		"complete": func(srv service.Todo) error {
+			return srv.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)})
+		},
+		"update func": func(srv service.Todo) error {
+			s.mockRepo.EXPECT().UpdateFunc(2, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+				return fn(&entity.Todo{ID: 2, OwnerID: 2})
+			})
+			return srv.UpdateFunc(2, func(*entity.Todo) error {
+				s.Fail("fn must not see the todo")
+				return nil
+			})
+		},
+		"replace": func(srv service.Todo) error {
+			_, err := srv.Replace(2, entity.ReplaceTodoInput{Title: "mine"})
+			return err
+		},
+		"upsert": func(srv service.Todo) error {
+			_, _, err := srv.Upsert(2, entity.ReplaceTodoInput{Title: "mine"})
+			return err
+		},
+		"upsert new": func(srv service.Todo) error {
+			s.mockRepo.EXPECT().Get(3).Return(nil, repo.ErrNotFound)
+			_, _, err := srv.Upsert(3, entity.ReplaceTodoInput{Title: "new"})
+			return err
+		},
+		"delete own": func(srv service.Todo) error {
+			return srv.Delete(1, entity.SubtaskDeleteCascade)
+		},
+		"move": func(srv service.Todo) error {
+			_, err := srv.Move(1, entity.MoveTodoInput{After: lo.ToPtr(2)})
+			return err
+		},
+		"set parent": func(srv service.Todo) error {
+			_, err := srv.SetParent(1, 2)
+			return err
+		},
+		"add dependency": func(srv service.Todo) error {
+			_, err := srv.AddDependency(1, 2)
+			return err
+		},
+		"remove dependency": func(srv service.Todo) error {
+			return srv.RemoveDependency(1, 2)
+		},
+		"attach tags": func(srv service.Todo) error {
+			_, err := srv.AttachTags(1, []string{"work"})
+			return err
+		},
+		"create tag": func(srv service.Todo) error {
+			_, err := srv.CreateTag("work")
+			return err
+		},
+		"rename tag": func(srv service.Todo) error {
+			_, err := srv.RenameTag(1, "work")
+			return err
+		},
+		"atomic batch update": func(srv service.Todo) error {
+			_, err := srv.BatchUpdate([]entity.BatchUpdateTodoInput{{ID: 1}}, entity.BatchAtomic)
+			s.Equal(&service.BatchError{Index: 0, Err: service.ErrForbidden}, err)
+			return err
+		},
+	}
+	for desc, call := range calls {
+		s.Run(desc, func() {
+			expectGet()
+
+			s.ErrorIs(call(s.srv.As(viewer)), service.ErrForbidden)
+		})
+	}
+	s.Run("reads", func() {
+		expectGet()
+		s.mockRepo.EXPECT().List().Return([]entity.Todo{{ID: 1, OwnerID: 1}, {ID: 2, OwnerID: 2}}, nil)
+		s.mockRepo.EXPECT().Subtree(2).Return([]entity.Todo{{ID: 2}}, nil)
+		srv := s.srv.As(viewer)
+
+		got, err := srv.List(entity.TodoQuery{})
+		s.Require().NoError(err)
+		s.Len(got, 2)
+		_, err = srv.Get(2)
+		s.Require().NoError(err)
+		_, err = srv.Subtree(2)
+		s.NoError(err)
+	})
+}
+
+// TestAsEditor covers what editors may not do: delete the todos of others,
+// change tags beyond creating them, and move todos into projects they
+// cannot create in.
+func (s *todoSuite) TestAsEditor() {
+	editor := entity.Actor{UserID: 1, WorkspaceID: 1, Role: entity.RoleEditor, ProjectRoles: map[int]entity.Role{}}
+	expectGet := func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 1}, nil).AnyTimes()
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2, OwnerID: 2}, nil).AnyTimes()
+	}
+
+	s.Run("delete own todo", func() {
+		expectGet()
+		s.mockRepo.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(nil)
+
+		s.NoError(s.srv.As(editor).Delete(1, entity.SubtaskDeleteCascade))
+	})
+	s.Run("delete their todo", func() {
+		expectGet()
+
+		s.ErrorIs(s.srv.As(editor).Delete(2, entity.SubtaskDeleteCascade), service.ErrForbidden)
+	})
+	s.Run("atomic batch delete", func() {
+		expectGet()
+
+		_, err := s.srv.As(editor).BatchDelete([]int{1, 2}, entity.BatchAtomic)
+		s.Equal(&service.BatchError{Index: 1, Err: service.ErrForbidden}, err)
+	})
+	s.Run("delete tag", func() {
+		s.ErrorIs(s.srv.As(editor).DeleteTag(1), service.ErrForbidden)
+	})
+	s.Run("merge tags", func() {
+		_, err := s.srv.As(editor).MergeTags(1, 2)
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("move into a project shared as viewer", func() {
+		expectGet()
+		editor := editor
+		editor.Role = entity.RoleGuest
+		editor.ProjectRoles = map[int]entity.Role{4: entity.RoleEditor, 5: entity.RoleViewer}
+		s.mockRepo.EXPECT().Get(3).Return(&entity.Todo{ID: 3, OwnerID: 2, ProjectID: 4}, nil).AnyTimes()
+
+		err := s.srv.As(editor).Update(3, entity.UpdateTodoInput{ProjectID: lo.ToPtr(5)})
+		s.ErrorIs(err, service.ErrForbidden)
+		err = s.srv.As(editor).Update(3, entity.UpdateTodoInput{ProjectID: lo.ToPtr(6)})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("update func moving into a project shared as viewer", func() {
+		guest := entity.Actor{UserID: 1, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{4: entity.RoleEditor, 5: entity.RoleViewer}}
+		s.mockRepo.EXPECT().UpdateFunc(3, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			return fn(&entity.Todo{ID: 3, OwnerID: 2, ProjectID: 4})
+		})
+
+		err := s.srv.As(guest).UpdateFunc(3, func(todo *entity.Todo) error {
+			todo.ProjectID = 5
+			return nil
+		})
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("create in a hidden project", func() {
+		guest := entity.Actor{UserID: 1, Role: entity.RoleGuest}
+		_, err := s.srv.As(guest).Create(entity.CreateTodoInput{Title: "new", ProjectID: 4})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestAsOwnTodos() {
+	actor := entity.Actor{UserID: 1, WorkspaceID: 1, Role: entity.RoleEditor}
+
+	s.Run("create stamps the owner", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 1}, nil)
+		s.mockRepo.EXPECT().Create(entity.CreateTodoInput{OwnerID: 1, Title: "sub", ParentID: 1}).Return(&entity.Todo{ID: 2, OwnerID: 1}, nil)
+
+		got, err := s.srv.As(actor).Create(entity.CreateTodoInput{Title: "sub", ParentID: 1})
+		s.Require().NoError(err)
+		s.Equal(1, got.OwnerID)
+	})
+	s.Run("batch create stamps the owner", func() {
+		s.mockRepo.EXPECT().BatchCreate([]entity.CreateTodoInput{{OwnerID: 1, Title: "a"}, {OwnerID: 1, Title: "b"}}).Return([]entity.Todo{{ID: 1}, {ID: 2}}, nil)
+
+		inputs := []entity.CreateTodoInput{{Title: "a"}, {Title: "b"}}
+		_, err := s.srv.As(actor).BatchCreate(inputs, entity.BatchAtomic)
+		s.Require().NoError(err)
+		s.Zero(inputs[0].OwnerID)
+	})
+	s.Run("upsert creates for the user", func() {
+		s.mockRepo.EXPECT().Get(3).Return(nil, repo.ErrNotFound)
+		s.mockRepo.EXPECT().Upsert(3, entity.ReplaceTodoInput{Title: "new", OwnerID: 1}).Return(&entity.Todo{ID: 3, OwnerID: 1}, true, nil)
+
+		_, created, err := s.srv.As(actor).Upsert(3, entity.ReplaceTodoInput{Title: "new"})
+		s.Require().NoError(err)
+		s.True(created)
+	})
+	s.Run("list only returns readable todos", func() {
+		s.mockRepo.EXPECT().List().Return([]entity.Todo{{ID: 1, OwnerID: 1, ProjectID: 4}, {ID: 2, OwnerID: 2}, {ID: 3, OwnerID: 2, ProjectID: 4}}, nil)
+
+		got, err := s.srv.As(entity.Actor{UserID: 1, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{4: entity.RoleViewer}}).List(entity.TodoQuery{})
+		s.Require().NoError(err)
+		s.Equal([]int{1, 3}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("update own todo", func() {
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 1}, nil)
+		s.mockRepo.EXPECT().Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("new")}).Return(nil)
+
+		s.NoError(s.srv.As(actor).Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("new")}))
+	})
+	s.Run("unscoped sees every todo", func() {
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2, OwnerID: 2}, nil)
+
+		got, err := s.srv.Get(2)
+		s.Require().NoError(err)
+		s.Equal(2, got.OwnerID)
+	})
+}
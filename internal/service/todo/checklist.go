@@ -0,0 +1,57 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+)
+
+func (s *Service) AddChecklistItem(todoID int, text string) (*entity.Todo, error) {
+	return s.updateChecklist(todoID, func(checklist entity.Checklist) (entity.Checklist, error) {
+		checklist, _, err := checklist.Add(text)
+		return checklist, err
+	})
+}
+
+func (s *Service) UpdateChecklistItem(todoID, itemID int, input entity.UpdateChecklistItemInput) (*entity.Todo, error) {
+	return s.updateChecklist(todoID, func(checklist entity.Checklist) (entity.Checklist, error) {
+		checklist, _, err := checklist.Update(itemID, input)
+		return checklist, err
+	})
+}
+
+func (s *Service) ReorderChecklist(todoID int, itemIDs []int) (*entity.Todo, error) {
+	return s.updateChecklist(todoID, func(checklist entity.Checklist) (entity.Checklist, error) {
+		return checklist.Reorder(itemIDs)
+	})
+}
+
+func (s *Service) RemoveChecklistItem(todoID, itemID int) (*entity.Todo, error) {
+	return s.updateChecklist(todoID, func(checklist entity.Checklist) (entity.Checklist, error) {
+		return checklist.Remove(itemID)
+	})
+}
+
+func (s *Service) SetChecklistAutoComplete(todoID int, autoComplete bool) (*entity.Todo, error) {
+	return s.updateChecklist(todoID, func(checklist entity.Checklist) (entity.Checklist, error) {
+		checklist.AutoComplete = autoComplete
+		return checklist, nil
+	})
+}
+
+// updateChecklist changes the checklist of a todo through UpdateFunc, so
+// the change is authorized and tracked like any other update, and a
+// checklist that auto-completes its todo also creates the next occurrence
+// of a recurring one.
+func (s *Service) updateChecklist(todoID int, fn func(entity.Checklist) (entity.Checklist, error)) (*entity.Todo, error) {
+	err := s.UpdateFunc(todoID, func(todo *entity.Todo) error {
+		checklist, err := fn(todo.Checklist)
+		if err != nil {
+			return err
+		}
+		todo.SetChecklist(checklist)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(todoID)
+}
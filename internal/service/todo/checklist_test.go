@@ -0,0 +1,229 @@
+package todo
+
+import (
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+// storeTodo backs UpdateFunc and Get of the mock repo with todo, so checklist
+// changes can be followed across calls.
+func (s *todoSuite) storeTodo(todo *entity.Todo) {
+	s.mockRepo.EXPECT().UpdateFunc(todo.ID, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+		updated := *todo
+		if err := fn(&updated); err != nil {
+			return err
+		}
+		*todo = updated
+		return nil
+	}).AnyTimes()
+	s.mockRepo.EXPECT().Get(todo.ID).DoAndReturn(func(int) (*entity.Todo, error) {
+		got := *todo
+		return &got, nil
+	}).AnyTimes()
+}
+
+func checklistTexts(todo *entity.Todo) []string {
+	return lo.Map(todo.Checklist.Items, func(item entity.ChecklistItem, _ int) string { return item.Text })
+}
+
+func (s *todoSuite) TestAddChecklistItem() {
+	s.Run("success", func() {
+		s.storeTodo(&entity.Todo{ID: 1})
+
+		_, err := s.srv.AddChecklistItem(1, " buy milk ")
+		s.Require().NoError(err)
+		got, err := s.srv.AddChecklistItem(1, "buy eggs")
+		s.Require().NoError(err)
+		s.Equal([]entity.ChecklistItem{{ID: 1, Text: "buy milk"}, {ID: 2, Text: "buy eggs"}}, got.Checklist.Items)
+		s.Equal("0/2", got.Checklist.String())
+	})
+	s.Run("invalid", func() {
+		s.storeTodo(&entity.Todo{ID: 1})
+
+		_, err := s.srv.AddChecklistItem(1, "  ")
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrEmptyChecklistItem)
+		_, err = s.srv.AddChecklistItem(1, strings.Repeat("x", entity.MaxChecklistItemLength+1))
+		s.ErrorIs(err, entity.ErrChecklistItemTooLong)
+	})
+	s.Run("full", func() {
+		todo := &entity.Todo{ID: 1}
+		s.storeTodo(todo)
+		for range entity.MaxChecklistItems {
+			_, err := s.srv.AddChecklistItem(1, "step")
+			s.Require().NoError(err)
+		}
+
+		_, err := s.srv.AddChecklistItem(1, "step")
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrChecklistFull)
+	})
+	s.Run("removed IDs are not reused", func() {
+		s.storeTodo(&entity.Todo{ID: 1})
+		_, err := s.srv.AddChecklistItem(1, "a")
+		s.Require().NoError(err)
+		_, err = s.srv.RemoveChecklistItem(1, 1)
+		s.Require().NoError(err)
+
+		got, err := s.srv.AddChecklistItem(1, "b")
+		s.Require().NoError(err)
+		s.Equal([]entity.ChecklistItem{{ID: 2, Text: "b"}}, got.Checklist.Items)
+	})
+	s.Run("viewer", func() {
+		s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+			return fn(&entity.Todo{ID: 1, OwnerID: 2})
+		}).Times(1)
+
+		_, err := s.srv.As(entity.Actor{UserID: 2, Role: entity.RoleViewer}).AddChecklistItem(1, "a")
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+}
+
+func (s *todoSuite) TestUpdateChecklistItem() {
+	checklist := entity.Checklist{
+		Items:  []entity.ChecklistItem{{ID: 1, Text: "a"}, {ID: 2, Text: "b"}},
+		NextID: 3,
+	}
+
+	s.Run("check", func() {
+		todo := &entity.Todo{ID: 1, Checklist: checklist}
+		s.storeTodo(todo)
+
+		got, err := s.srv.UpdateChecklistItem(1, 2, entity.UpdateChecklistItemInput{Checked: lo.ToPtr(true)})
+		s.Require().NoError(err)
+		s.Equal("1/2", got.Checklist.String())
+		s.False(got.IsCompleted)
+		s.False(checklist.Items[1].Checked, "the stored checklist is copied, not changed in place")
+	})
+	s.Run("edit text", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		got, err := s.srv.UpdateChecklistItem(1, 1, entity.UpdateChecklistItemInput{Text: lo.ToPtr("A")})
+		s.Require().NoError(err)
+		s.Equal([]string{"A", "b"}, checklistTexts(got))
+	})
+	s.Run("not found", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		_, err := s.srv.UpdateChecklistItem(1, 3, entity.UpdateChecklistItemInput{Checked: lo.ToPtr(true)})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestChecklistAutoComplete() {
+	checklist := entity.Checklist{
+		Items:        []entity.ChecklistItem{{ID: 1, Text: "a", Checked: true}, {ID: 2, Text: "b"}},
+		AutoComplete: true,
+		NextID:       3,
+	}
+	check := func(id int, checked bool) (*entity.Todo, error) {
+		return s.srv.UpdateChecklistItem(1, id, entity.UpdateChecklistItemInput{Checked: &checked})
+	}
+
+	s.Run("completes once every item is checked", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		got, err := check(2, true)
+		s.Require().NoError(err)
+		s.True(got.IsCompleted)
+	})
+	s.Run("reopens when an item is unchecked", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+		_, err := check(2, true)
+		s.Require().NoError(err)
+
+		got, err := check(1, false)
+		s.Require().NoError(err)
+		s.False(got.IsCompleted)
+	})
+	s.Run("reopens when an item is added", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+		_, err := check(2, true)
+		s.Require().NoError(err)
+
+		got, err := s.srv.AddChecklistItem(1, "c")
+		s.Require().NoError(err)
+		s.False(got.IsCompleted)
+	})
+	s.Run("blocked todos stay open", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Blocked: true, Checklist: checklist})
+
+		got, err := check(2, true)
+		s.Require().NoError(err)
+		s.False(got.IsCompleted)
+	})
+	s.Run("turning it on completes a done checklist", func() {
+		done := checklist
+		done.AutoComplete = false
+		done.Items = []entity.ChecklistItem{{ID: 1, Text: "a", Checked: true}}
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: done})
+
+		got, err := s.srv.SetChecklistAutoComplete(1, true)
+		s.Require().NoError(err)
+		s.True(got.Checklist.AutoComplete)
+		s.True(got.IsCompleted)
+	})
+	s.Run("off leaves the todo alone", func() {
+		manual := checklist
+		manual.AutoComplete = false
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: manual})
+
+		got, err := check(2, true)
+		s.Require().NoError(err)
+		s.False(got.IsCompleted)
+	})
+}
+
+func (s *todoSuite) TestReorderChecklist() {
+	checklist := entity.Checklist{
+		Items:  []entity.ChecklistItem{{ID: 1, Text: "a"}, {ID: 2, Text: "b"}, {ID: 3, Text: "c"}},
+		NextID: 4,
+	}
+
+	s.Run("success", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		got, err := s.srv.ReorderChecklist(1, []int{3, 1, 2})
+		s.Require().NoError(err)
+		s.Equal([]string{"c", "a", "b"}, checklistTexts(got))
+	})
+	for desc, ids := range map[string][]int{
+		"missing item":   {3, 1},
+		"repeated item":  {3, 1, 1},
+		"unknown item":   {3, 1, 4},
+		"too many items": {3, 1, 2, 2},
+	} {
+		s.Run(desc, func() {
+			s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+			_, err := s.srv.ReorderChecklist(1, ids)
+			s.ErrorIs(err, service.ErrInvalidInput)
+			s.ErrorIs(err, entity.ErrChecklistOrder)
+		})
+	}
+}
+
+func (s *todoSuite) TestRemoveChecklistItem() {
+	checklist := entity.Checklist{
+		Items:  []entity.ChecklistItem{{ID: 1, Text: "a"}, {ID: 2, Text: "b"}},
+		NextID: 3,
+	}
+
+	s.Run("success", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		got, err := s.srv.RemoveChecklistItem(1, 1)
+		s.Require().NoError(err)
+		s.Equal([]string{"b"}, checklistTexts(got))
+	})
+	s.Run("not found", func() {
+		s.storeTodo(&entity.Todo{ID: 1, Checklist: checklist})
+
+		_, err := s.srv.RemoveChecklistItem(1, 3)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"slices"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+func (s *Service) AddDependency(todoID, blockerID int) (*entity.Dependency, error) {
+	if err := s.authorize(todoID, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(blockerID, entity.ActionRead, entity.ErrBlockerNotFound); err != nil {
+		return nil, err
+	}
+	dep, err := s.repo.AddDependency(todoID, blockerID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return dep, nil
+}
+
+func (s *Service) RemoveDependency(todoID, blockerID int) error {
+	if err := s.authorize(todoID, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveDependency(todoID, blockerID); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *Service) Dependencies(todoID int) ([]entity.Dependency, error) {
+	if _, err := s.Get(todoID); err != nil {
+		return nil, err
+	}
+	deps, err := s.repo.ListDependencies()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(deps, func(dep entity.Dependency) bool {
+		return dep.TodoID != todoID
+	}), nil
+}
+
+// CriticalPath only looks at open todos, so the path is the work that is
+// left.
+func (s *Service) CriticalPath(projectID int) ([]entity.Todo, error) {
+	todos, err := s.List(entity.TodoQuery{Project: &projectID})
+	if err != nil {
+		return nil, err
+	}
+	todos = slices.DeleteFunc(todos, func(todo entity.Todo) bool {
+		return todo.IsCompleted
+	})
+	deps, err := s.repo.ListDependencies()
+	if err != nil {
+		return nil, err
+	}
+	return entity.CriticalPath(todos, deps), nil
+}
@@ -0,0 +1,132 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+)
+
+func (s *todoSuite) TestAddDependency() {
+	tests := []struct {
+		desc    string
+		err     error
+		wantErr error
+	}{
+		{desc: "success"},
+		{desc: "self", err: entity.ErrDependencySelf, wantErr: service.ErrInvalidInput},
+		{desc: "cycle", err: entity.ErrDependencyCycle, wantErr: service.ErrInvalidInput},
+		{desc: "blocker not found", err: entity.ErrBlockerNotFound, wantErr: service.ErrInvalidInput},
+		{desc: "exists", err: entity.ErrDependencyExists, wantErr: service.ErrConflict},
+		{desc: "not found", err: repo.ErrNotFound, wantErr: service.ErrNotFound},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			var dep *entity.Dependency
+			if tt.err == nil {
+				dep = &entity.Dependency{TodoID: 2, BlockerID: 1}
+			}
+			s.mockRepo.EXPECT().AddDependency(2, 1).Return(dep, tt.err).Times(1)
+
+			got, err := s.srv.AddDependency(2, 1)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(dep, got)
+		})
+	}
+}
+
+func (s *todoSuite) TestRemoveDependency() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().RemoveDependency(2, 1).Return(nil).Times(1)
+
+		s.NoError(s.srv.RemoveDependency(2, 1))
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().RemoveDependency(2, 1).Return(repo.ErrNotFound).Times(1)
+
+		s.ErrorIs(s.srv.RemoveDependency(2, 1), service.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestDependencies() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2}, nil).Times(1)
+		s.mockRepo.EXPECT().ListDependencies().Return([]entity.Dependency{
+			{TodoID: 2, BlockerID: 1},
+			{TodoID: 3, BlockerID: 2},
+			{TodoID: 2, BlockerID: 4},
+		}, nil).Times(1)
+
+		got, err := s.srv.Dependencies(2)
+		s.Require().NoError(err)
+		s.Equal([]entity.Dependency{{TodoID: 2, BlockerID: 1}, {TodoID: 2, BlockerID: 4}}, got)
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Get(2).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Dependencies(2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestCompleteBlocked() {
+	s.Run("conflict", func() {
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2, Blocked: true}, nil).Times(1)
+		s.mockRepo.EXPECT().Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}).Return(entity.ErrBlocked).Times(1)
+
+		err := s.srv.Update(2, entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)})
+		s.ErrorIs(err, service.ErrConflict)
+	})
+}
+
+func (s *todoSuite) TestListTopological() {
+	s.mockRepo.EXPECT().List().Return([]entity.Todo{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Times(1)
+	s.mockRepo.EXPECT().ListDependencies().Return([]entity.Dependency{
+		{TodoID: 1, BlockerID: 3},
+		{TodoID: 3, BlockerID: 2},
+	}, nil).Times(1)
+
+	got, err := s.srv.List(entity.TodoQuery{Order: entity.OrderTopological})
+	s.Require().NoError(err)
+	s.Equal([]int{2, 3, 1}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+}
+
+func (s *todoSuite) TestCriticalPath() {
+	s.Run("longest chain of open todos", func() {
+		s.mockRepo.EXPECT().List().Return([]entity.Todo{
+			{ID: 1, ProjectID: 1},
+			{ID: 2, ProjectID: 1},
+			{ID: 3, ProjectID: 1},
+			{ID: 4, ProjectID: 1},
+			{ID: 5, ProjectID: 1, IsCompleted: true},
+			{ID: 6, ProjectID: 2},
+		}, nil).Times(1)
+		s.mockRepo.EXPECT().ListDependencies().Return([]entity.Dependency{
+			{TodoID: 4, BlockerID: 1},
+			{TodoID: 3, BlockerID: 2},
+			{TodoID: 4, BlockerID: 3},
+			{TodoID: 2, BlockerID: 5},
+			{TodoID: 5, BlockerID: 6},
+		}, nil).Times(1)
+
+		got, err := s.srv.CriticalPath(1)
+		s.Require().NoError(err)
+		s.Equal([]int{2, 3, 4}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("no dependencies", func() {
+		s.mockRepo.EXPECT().List().Return([]entity.Todo{{ID: 1, ProjectID: 1}, {ID: 2, ProjectID: 1}}, nil).Times(1)
+		s.mockRepo.EXPECT().ListDependencies().Return(nil, nil).Times(1)
+
+		got, err := s.srv.CriticalPath(1)
+		s.Require().NoError(err)
+		s.Equal([]int{1}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("empty project", func() {
+		s.mockRepo.EXPECT().List().Return(nil, nil).Times(1)
+		s.mockRepo.EXPECT().ListDependencies().Return(nil, nil).Times(1)
+
+		got, err := s.srv.CriticalPath(1)
+		s.Require().NoError(err)
+		s.Empty(got)
+	})
+}
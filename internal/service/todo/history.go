@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// WithHistory records what every change to a todo changed, for the
+// activity feed.
+func WithHistory(history repo.History) Option {
+	return func(s *Service) {
+		s.history = history
+	}
+}
+
+// track runs fn, which changes the todo with id, and records what it
+// changed. The todo is read before and after fn rather than locked, so a
+// change made by someone else meanwhile can end up in the same record.
+func (s *Service) track(id int, fn func() error) error {
+	return s.trackAll([]int{id}, fn)
+}
+
+// trackAll is track for fn changing several todos.
+func (s *Service) trackAll(ids []int, fn func() error) error {
+	if s.history == nil {
+		return fn()
+	}
+	before := make(map[int]entity.Todo, len(ids))
+	for _, id := range ids {
+		// A todo that does not exist yet has nothing to compare with, and
+		// fn reports todos that are missing.
+		if todo, err := s.repo.Get(id); err == nil {
+			before[id] = *todo
+		}
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+
+	var actorID int
+	if s.actor != nil {
+		actorID = s.actor.UserID
+	}
+	for _, id := range ids {
+		prev, ok := before[id]
+		if !ok {
+			continue
+		}
+		after, err := s.repo.Get(id)
+		if err != nil {
+			return err
+		}
+		fields := entity.DiffTodo(prev, *after)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := s.history.Record(entity.TodoChange{TodoID: id, ActorID: actorID, Fields: fields}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
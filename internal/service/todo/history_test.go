@@ -0,0 +1,68 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *todoSuite) TestHistory() {
+	var mockHistory *mocks.MockHistory
+	newService := func() *Service {
+		mockHistory = mocks.NewMockHistory(gomock.NewController(s.T()))
+		return NewService(s.mockRepo, WithHistory(mockHistory)).(*Service)
+	}
+
+	s.Run("records changed fields", func() {
+		srv := newService()
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 2, Title: "title-1"}, nil).Times(2),
+			s.mockRepo.EXPECT().Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("title-2")}).Return(nil).Times(1),
+			s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, OwnerID: 2, Title: "title-2"}, nil).Times(1),
+		)
+		mockHistory.EXPECT().Record(entity.TodoChange{
+			TodoID:  1,
+			ActorID: 2,
+			Fields:  []entity.FieldChange{{Field: "title", From: "title-1", To: "title-2"}},
+		}).Return(&entity.TodoChange{ID: 1}, nil).Times(1)
+
+		actor := entity.Actor{UserID: 2, Role: entity.RoleEditor}
+		s.NoError(srv.As(actor).Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("title-2")}))
+	})
+	s.Run("nothing changed", func() {
+		srv := newService()
+		todo := &entity.Todo{ID: 1, Title: "title-1"}
+		s.mockRepo.EXPECT().Get(1).Return(todo, nil).Times(2)
+		s.mockRepo.EXPECT().Update(1, gomock.Any()).Return(nil).Times(1)
+
+		s.NoError(srv.Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("title-1")}))
+	})
+	s.Run("failed change is not recorded", func() {
+		srv := newService()
+		s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+		s.mockRepo.EXPECT().Update(1, gomock.Any()).Return(repo.ErrNotFound).Times(1)
+
+		s.Error(srv.Update(1, entity.UpdateTodoInput{Title: lo.ToPtr("title-2")}))
+	})
+	s.Run("created todo has no history", func() {
+		srv := newService()
+		s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+		s.mockRepo.EXPECT().Upsert(1, gomock.Any()).Return(&entity.Todo{ID: 1}, true, nil).Times(1)
+
+		_, created, err := srv.Upsert(1, entity.ReplaceTodoInput{Title: "title-1"})
+		s.NoError(err)
+		s.True(created)
+	})
+	s.Run("scoped to the workspace", func() {
+		srv := newService()
+		scopedRepo := mocks.NewMockTodo(gomock.NewController(s.T()))
+		scopedHistory := mocks.NewMockHistory(gomock.NewController(s.T()))
+		s.mockRepo.EXPECT().ForWorkspace(3).Return(scopedRepo).Times(1)
+		mockHistory.EXPECT().ForWorkspace(3).Return(scopedHistory).Times(1)
+
+		scoped := srv.ForWorkspace(3).(*Service)
+		s.Equal(scopedHistory, scoped.history)
+	})
+}
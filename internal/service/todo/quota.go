@@ -0,0 +1,65 @@
+package todo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+// Quota caps what a single user may store. Zero fields are unlimited. Only
+// the service scoped to an actor enforces it.
+type Quota struct {
+	// MaxTodos is how many todos a user may own across every workspace.
+	MaxTodos int
+	// MaxDescription is the longest description in bytes.
+	MaxDescription int
+}
+
+type Option func(*Service)
+
+func WithQuota(quota Quota) Option {
+	return func(s *Service) {
+		s.quota = quota
+	}
+}
+
+// remaining returns how many more todos the actor may own. The count and
+// the create that follows are not atomic, so concurrent creates can go over
+// by a few.
+func (s *Service) remaining() (int, error) {
+	if s.actor == nil || s.quota.MaxTodos == 0 {
+		return math.MaxInt, nil
+	}
+	owned, err := s.repo.CountOwned(s.actor.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return max(s.quota.MaxTodos-owned, 0), nil
+}
+
+// checkTodos fails when the actor owning n more todos would go over the
+// quota.
+func (s *Service) checkTodos(n int) error {
+	remaining, err := s.remaining()
+	if err != nil {
+		return err
+	}
+	if n > remaining {
+		return s.todoQuotaError()
+	}
+	return nil
+}
+
+func (s *Service) todoQuotaError() error {
+	return fmt.Errorf("%w: a user may own at most %d todos", service.ErrQuotaExceeded, s.quota.MaxTodos)
+}
+
+// checkDescription fails when description is longer than the quota allows.
+// A nil description is left alone.
+func (s *Service) checkDescription(description *string) error {
+	if s.actor == nil || s.quota.MaxDescription == 0 || description == nil || len(*description) <= s.quota.MaxDescription {
+		return nil
+	}
+	return fmt.Errorf("%w: descriptions may be at most %d bytes", service.ErrQuotaExceeded, s.quota.MaxDescription)
+}
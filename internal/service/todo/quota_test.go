@@ -0,0 +1,147 @@
+package todo
+
+import (
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+func (s *todoSuite) TestQuota() {
+	editor := entity.Actor{UserID: 1, WorkspaceID: 1, Role: entity.RoleEditor}
+	quota := Quota{MaxTodos: 3, MaxDescription: 8}
+	long := strings.Repeat("x", 9)
+	mine := &entity.Todo{ID: 1, OwnerID: 1}
+
+	tests := []struct {
+		desc    string
+		call    func(srv service.Todo) error
+		wantErr string
+	}{
+		{
+			desc: "create",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().CountOwned(1).Return(2, nil).Times(1)
+				s.mockRepo.EXPECT().Create(entity.CreateTodoInput{OwnerID: 1, Title: "title-1", Description: "12345678"}).Return(&entity.Todo{ID: 3}, nil).Times(1)
+				_, err := srv.Create(entity.CreateTodoInput{Title: "title-1", Description: "12345678"})
+				return err
+			},
+		},
+		{
+			desc: "create over max todos",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().CountOwned(1).Return(3, nil).Times(1)
+				_, err := srv.Create(entity.CreateTodoInput{Title: "title-1"})
+				return err
+			},
+			wantErr: "forbidden: quota exceeded: a user may own at most 3 todos",
+		},
+		{
+			desc: "create with long description",
+			call: func(srv service.Todo) error {
+				_, err := srv.Create(entity.CreateTodoInput{Title: "title-1", Description: long})
+				return err
+			},
+			wantErr: "forbidden: quota exceeded: descriptions may be at most 8 bytes",
+		},
+		{
+			desc: "count failed",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().CountOwned(1).Return(0, mockErr).Times(1)
+				_, err := srv.Create(entity.CreateTodoInput{Title: "title-1"})
+				return err
+			},
+			wantErr: mockErr.Error(),
+		},
+		{
+			desc: "atomic batch create over max todos",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().CountOwned(1).Return(1, nil).Times(1)
+				_, err := srv.BatchCreate(make([]entity.CreateTodoInput, 3), entity.BatchAtomic)
+				return err
+			},
+			wantErr: "item 2: forbidden: quota exceeded: a user may own at most 3 todos",
+		},
+		{
+			desc: "upsert creating over max todos",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().Get(5).Return(nil, repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().CountOwned(1).Return(3, nil).Times(1)
+				_, _, err := srv.Upsert(5, entity.ReplaceTodoInput{Title: "title-1"})
+				return err
+			},
+			wantErr: "forbidden: quota exceeded: a user may own at most 3 todos",
+		},
+		{
+			desc: "upsert replacing at max todos",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().Get(1).Return(mine, nil).Times(1)
+				s.mockRepo.EXPECT().Upsert(1, gomock.Any()).Return(mine, false, nil).Times(1)
+				_, _, err := srv.Upsert(1, entity.ReplaceTodoInput{Title: "title-1"})
+				return err
+			},
+		},
+		{
+			desc: "update with long description",
+			call: func(srv service.Todo) error {
+				return srv.Update(1, entity.UpdateTodoInput{Description: &long})
+			},
+			wantErr: "forbidden: quota exceeded: descriptions may be at most 8 bytes",
+		},
+		{
+			desc: "update func with long description",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+					return fn(lo.ToPtr(*mine))
+				}).Times(1)
+				return srv.UpdateFunc(1, func(todo *entity.Todo) error {
+					todo.Description = long
+					return nil
+				})
+			},
+			wantErr: "forbidden: quota exceeded: descriptions may be at most 8 bytes",
+		},
+		{
+			desc: "replace with long description",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().Get(1).Return(mine, nil).Times(1)
+				_, err := srv.Replace(1, entity.ReplaceTodoInput{Title: "title-1", Description: long})
+				return err
+			},
+			wantErr: "forbidden: quota exceeded: descriptions may be at most 8 bytes",
+		},
+		{
+			desc: "atomic batch update with long description",
+			call: func(srv service.Todo) error {
+				s.mockRepo.EXPECT().Get(1).Return(mine, nil).Times(1)
+				_, err := srv.BatchUpdate([]entity.BatchUpdateTodoInput{{ID: 1, Input: entity.UpdateTodoInput{Description: &long}}}, entity.BatchAtomic)
+				return err
+			},
+			wantErr: "item 0: forbidden: quota exceeded: descriptions may be at most 8 bytes",
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			srv := NewService(s.mockRepo, WithQuota(quota)).As(editor)
+
+			err := tt.call(srv)
+			if tt.wantErr == "" {
+				s.NoError(err)
+				return
+			}
+			s.EqualError(err, tt.wantErr)
+		})
+	}
+	s.Run("unscoped service is not limited", func() {
+		s.mockRepo.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Description: long}).Return(&entity.Todo{ID: 4}, nil).Times(1)
+
+		_, err := NewService(s.mockRepo, WithQuota(quota)).Create(entity.CreateTodoInput{Title: "title-1", Description: long})
+		s.NoError(err)
+	})
+	s.Run("quota is forbidden", func() {
+		s.ErrorIs(service.ErrQuotaExceeded, service.ErrForbidden)
+	})
+}
@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"errors"
+
+	"github.com/cloudingcity/todo/internal/entity"
+)
+
+// errNotRecurring stops recur from writing a todo that has no rule to clear.
+var errNotRecurring = errors.New("todo does not recur")
+
+// recur creates the next occurrence of a todo that was just completed. The
+// rule moves to the new todo, so completing the old one again does not
+// repeat the series. The rule is read and cleared in one update, so when
+// the todo is completed twice at once only one of them creates the next
+// occurrence.
+func (s *Service) recur(id int) error {
+	var todo entity.Todo
+	err := s.repo.UpdateFunc(id, func(t *entity.Todo) error {
+		if t.Recurrence == nil {
+			return errNotRecurring
+		}
+		todo = *t
+		t.Recurrence = nil
+		return nil
+	})
+	if errors.Is(err, errNotRecurring) {
+		return nil
+	} else if err != nil {
+		return mapError(err)
+	}
+
+	input, ok := todo.NextOccurrence()
+	if !ok {
+		return nil
+	}
+	next, err := s.repo.Create(input)
+	if err != nil {
+		return mapError(err)
+	}
+	if len(todo.Tags) > 0 {
+		if _, err := s.repo.AttachTags(next.ID, todo.Tags); err != nil {
+			return mapError(err)
+		}
+	}
+	return nil
+}
+
+// recurReplaced is recur for Replace and Upsert, which return the todo they
+// wrote: when it recurs, it is returned again without its rule.
+func (s *Service) recurReplaced(todo *entity.Todo) (*entity.Todo, error) {
+	if todo.Recurrence == nil {
+		return todo, nil
+	}
+	if err := s.recur(todo.ID); err != nil {
+		return nil, err
+	}
+	todo, err := s.repo.Get(todo.ID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
+}
+
+// Occurrences previews the next n occurrences of a todo. It is empty for
+// todos that do not recur.
+func (s *Service) Occurrences(id, n int) ([]entity.Occurrence, error) {
+	todo, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return todo.Occurrences(n), nil
+}
@@ -0,0 +1,384 @@
+package todo
+
+import (
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+	"go.uber.org/mock/gomock"
+)
+
+func mustParseRRule(s string) *entity.Recurrence {
+	rule, err := entity.ParseRRule(s)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+func formatDateTime(d *entity.DateTime) string {
+	switch {
+	case d == nil:
+		return ""
+	case d.AllDay:
+		return d.Time.Format(time.DateOnly)
+	default:
+		return d.Time.Format(time.RFC3339)
+	}
+}
+
+func (s *todoSuite) TestParseRRule() {
+	tests := []struct {
+		desc    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{desc: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{desc: "prefix and case", rule: "RRULE:freq=weekly;byday=mo,th;interval=2", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{desc: "numbered weekday", rule: "FREQ=MONTHLY;BYDAY=-1FR", want: "FREQ=MONTHLY;BYDAY=-1FR"},
+		{desc: "month days", rule: "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=3", want: "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=3"},
+		{desc: "until date", rule: "FREQ=DAILY;UNTIL=20261231", want: "FREQ=DAILY;UNTIL=20261231"},
+		{desc: "until date-time", rule: "FREQ=DAILY;UNTIL=20261231T120000Z", want: "FREQ=DAILY;UNTIL=20261231T120000Z"},
+		{desc: "missing frequency", rule: "INTERVAL=2", wantErr: true},
+		{desc: "unknown frequency", rule: "FREQ=HOURLY", wantErr: true},
+		{desc: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{desc: "duplicate part", rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{desc: "count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: true},
+		{desc: "zero interval", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{desc: "unknown weekday", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{desc: "numbered weekday weekly", rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{desc: "month day weekly", rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{desc: "month day out of range", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{desc: "yearly with weekday", rule: "FREQ=YEARLY;BYDAY=MO", wantErr: true},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			got, err := entity.ParseRRule(tt.rule)
+			if tt.wantErr {
+				s.ErrorIs(err, entity.ErrInvalidRRule)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.want, got.String())
+		})
+	}
+}
+
+func (s *todoSuite) TestOccurrences() {
+	newYork, err := time.LoadLocation("America/New_York")
+	s.Require().NoError(err)
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	date := func(year int, month time.Month, day int) *entity.DateTime {
+		return lo.ToPtr(entity.NewDate(year, month, day))
+	}
+
+	tests := []struct {
+		desc      string
+		todo      entity.Todo
+		n         int
+		wantStart []string
+		wantDue   []string
+	}{
+		{
+			desc:    "daily interval",
+			todo:    entity.Todo{Due: date(2026, 1, 30), Recurrence: mustParseRRule("FREQ=DAILY;INTERVAL=2")},
+			n:       3,
+			wantDue: []string{"2026-02-01", "2026-02-03", "2026-02-05"},
+		},
+		{
+			desc:    "weekly on weekdays",
+			todo:    entity.Todo{Due: date(2026, 10, 19), Recurrence: mustParseRRule("FREQ=WEEKLY;BYDAY=MO,TH")},
+			n:       3,
+			wantDue: []string{"2026-10-22", "2026-10-26", "2026-10-29"},
+		},
+		{
+			desc:    "every other week",
+			todo:    entity.Todo{Due: date(2026, 10, 22), Recurrence: mustParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH")},
+			n:       3,
+			wantDue: []string{"2026-11-02", "2026-11-05", "2026-11-16"},
+		},
+		{
+			desc:    "last friday of the month",
+			todo:    entity.Todo{Due: date(2026, 1, 30), Recurrence: mustParseRRule("FREQ=MONTHLY;BYDAY=-1FR")},
+			n:       3,
+			wantDue: []string{"2026-02-27", "2026-03-27", "2026-04-24"},
+		},
+		{
+			desc:    "last day of the month",
+			todo:    entity.Todo{Due: date(2026, 1, 31), Recurrence: mustParseRRule("FREQ=MONTHLY;BYMONTHDAY=-1")},
+			n:       3,
+			wantDue: []string{"2026-02-28", "2026-03-31", "2026-04-30"},
+		},
+		{
+			desc:    "monthly skips short months",
+			todo:    entity.Todo{Due: date(2026, 1, 31), Recurrence: mustParseRRule("FREQ=MONTHLY")},
+			n:       3,
+			wantDue: []string{"2026-03-31", "2026-05-31", "2026-07-31"},
+		},
+		{
+			desc:    "yearly on leap day",
+			todo:    entity.Todo{Due: date(2024, 2, 29), Recurrence: mustParseRRule("FREQ=YEARLY")},
+			n:       2,
+			wantDue: []string{"2028-02-29", "2032-02-29"},
+		},
+		{
+			desc:    "count includes the todo",
+			todo:    entity.Todo{Due: date(2026, 1, 1), Recurrence: mustParseRRule("FREQ=DAILY;COUNT=3")},
+			n:       5,
+			wantDue: []string{"2026-01-02", "2026-01-03"},
+		},
+		{
+			desc:    "until is inclusive",
+			todo:    entity.Todo{Due: date(2026, 2, 1), Recurrence: mustParseRRule("FREQ=DAILY;UNTIL=20260204")},
+			n:       5,
+			wantDue: []string{"2026-02-02", "2026-02-03", "2026-02-04"},
+		},
+		{
+			desc:      "start only",
+			todo:      entity.Todo{Start: date(2026, 1, 1), Recurrence: mustParseRRule("FREQ=WEEKLY")},
+			n:         2,
+			wantStart: []string{"2026-01-08", "2026-01-15"},
+		},
+		{
+			desc: "start moves with due",
+			todo: entity.Todo{
+				Start:      lo.ToPtr(entity.NewDateTime(time.Date(2026, 10, 19, 9, 0, 0, 0, taipei), "Asia/Taipei")),
+				Due:        date(2026, 10, 21),
+				Recurrence: mustParseRRule("FREQ=WEEKLY"),
+			},
+			n:         2,
+			wantStart: []string{"2026-10-26T09:00:00+08:00", "2026-11-02T09:00:00+08:00"},
+			wantDue:   []string{"2026-10-28", "2026-11-04"},
+		},
+		{
+			desc: "dst gap moves forward",
+			todo: entity.Todo{
+				Due:        lo.ToPtr(entity.NewDateTime(time.Date(2026, 3, 7, 2, 30, 0, 0, newYork), "America/New_York")),
+				Recurrence: mustParseRRule("FREQ=DAILY"),
+			},
+			n:       2,
+			wantDue: []string{"2026-03-08T03:30:00-04:00", "2026-03-09T02:30:00-04:00"},
+		},
+		{
+			desc: "dst overlap keeps the first instance",
+			todo: entity.Todo{
+				Due:        lo.ToPtr(entity.NewDateTime(time.Date(2026, 10, 31, 1, 30, 0, 0, newYork), "America/New_York")),
+				Recurrence: mustParseRRule("FREQ=DAILY"),
+			},
+			n:       2,
+			wantDue: []string{"2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		},
+		{
+			desc: "wall clock kept across dst",
+			todo: entity.Todo{
+				Due:        lo.ToPtr(entity.NewDateTime(time.Date(2026, 10, 26, 9, 0, 0, 0, newYork), "America/New_York")),
+				Recurrence: mustParseRRule("FREQ=WEEKLY"),
+			},
+			n:       2,
+			wantDue: []string{"2026-11-02T09:00:00-05:00", "2026-11-09T09:00:00-05:00"},
+		},
+		{
+			desc: "not recurring",
+			todo: entity.Todo{Due: date(2026, 1, 1)},
+			n:    3,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockRepo.EXPECT().Get(1).Return(&tt.todo, nil).Times(1)
+
+			got, err := s.srv.Occurrences(1, tt.n)
+			s.Require().NoError(err)
+			s.Len(got, max(len(tt.wantStart), len(tt.wantDue)))
+			for i, occurrence := range got {
+				if tt.wantStart != nil {
+					s.Equal(tt.wantStart[i], formatDateTime(occurrence.Start))
+				} else {
+					s.Nil(occurrence.Start)
+				}
+				if tt.wantDue != nil {
+					s.Equal(tt.wantDue[i], formatDateTime(occurrence.Due))
+				} else {
+					s.Nil(occurrence.Due)
+				}
+			}
+		})
+	}
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Occurrences(1, 3)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
+
+func (s *todoSuite) TestCompleteRecurring() {
+	recurring := func(completed bool) *entity.Todo {
+		return &entity.Todo{
+			ID:          1,
+			Title:       "water plants",
+			IsCompleted: completed,
+			Priority:    entity.PriorityLow,
+			ProjectID:   2,
+			Tags:        []string{"home"},
+			Checklist: entity.Checklist{
+				Items:  []entity.ChecklistItem{{ID: 1, Text: "fill can", Checked: true}},
+				NextID: 2,
+			},
+			Due:        lo.ToPtr(entity.NewDate(2026, 10, 19)),
+			Recurrence: mustParseRRule("FREQ=WEEKLY;COUNT=3"),
+		}
+	}
+	next := entity.CreateTodoInput{
+		Title:     "water plants",
+		Priority:  entity.PriorityLow,
+		ProjectID: 2,
+		// The checklist starts over.
+		Checklist: entity.Checklist{
+			Items:  []entity.ChecklistItem{{ID: 1, Text: "fill can"}},
+			NextID: 2,
+		},
+		Due:        lo.ToPtr(entity.NewDate(2026, 10, 26)),
+		Recurrence: mustParseRRule("FREQ=WEEKLY;COUNT=2"),
+	}
+	clearsRule := func(todo *entity.Todo) func(int, func(*entity.Todo) error) error {
+		return func(_ int, fn func(*entity.Todo) error) error {
+			s.Require().NoError(fn(todo))
+			s.Nil(todo.Recurrence)
+			return nil
+		}
+	}
+
+	s.Run("update", func() {
+		input := entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().Update(1, input).Return(nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(recurring(true))),
+			s.mockRepo.EXPECT().Create(next).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().AttachTags(3, []string{"home"}).Return(&entity.Todo{ID: 3}, nil),
+		)
+
+		s.NoError(s.srv.Update(1, input))
+	})
+	s.Run("merge patch", func() {
+		gomock.InOrder(
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+				return fn(recurring(false))
+			}),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(recurring(true))),
+			s.mockRepo.EXPECT().Create(next).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().AttachTags(3, []string{"home"}).Return(&entity.Todo{ID: 3}, nil),
+		)
+
+		s.NoError(s.srv.UpdateFunc(1, func(todo *entity.Todo) error {
+			todo.IsCompleted = true
+			return nil
+		}))
+	})
+	s.Run("atomic batch update", func() {
+		inputs := []entity.BatchUpdateTodoInput{{ID: 1, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}}}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().BatchUpdate(inputs).Return(nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(recurring(true))),
+			s.mockRepo.EXPECT().Create(next).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().AttachTags(3, []string{"home"}).Return(&entity.Todo{ID: 3}, nil),
+		)
+
+		_, err := s.srv.BatchUpdate(inputs, entity.BatchAtomic)
+		s.NoError(err)
+	})
+	s.Run("already completed", func() {
+		input := entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}
+		s.mockRepo.EXPECT().Get(1).Return(recurring(true), nil).Times(1)
+		s.mockRepo.EXPECT().Update(1, input).Return(nil).Times(1)
+
+		s.NoError(s.srv.Update(1, input))
+	})
+	s.Run("last occurrence", func() {
+		last := recurring(true)
+		last.Recurrence.Count = 1
+		input := entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().Update(1, input).Return(nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(last)),
+		)
+
+		s.NoError(s.srv.Update(1, input))
+	})
+	s.Run("completed twice at once", func() {
+		// The other completion moved the rule to the next occurrence
+		// between the update and recur.
+		input := entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}
+		moved := recurring(true)
+		moved.Recurrence = nil
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().Update(1, input).Return(nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+				return fn(moved)
+			}),
+		)
+
+		s.NoError(s.srv.Update(1, input))
+	})
+	s.Run("replace", func() {
+		input := entity.ReplaceTodoInput{Title: "water plants", IsCompleted: true, Recurrence: mustParseRRule("FREQ=WEEKLY;COUNT=3")}
+		replaced := recurring(true)
+		replaced.Recurrence = nil
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().Replace(1, input).Return(recurring(true), nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(recurring(true))),
+			s.mockRepo.EXPECT().Create(next).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().AttachTags(3, []string{"home"}).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().Get(1).Return(replaced, nil),
+		)
+
+		got, err := s.srv.Replace(1, input)
+		s.Require().NoError(err)
+		s.Nil(got.Recurrence)
+	})
+	s.Run("upsert", func() {
+		input := entity.ReplaceTodoInput{Title: "water plants", IsCompleted: true, Recurrence: mustParseRRule("FREQ=WEEKLY;COUNT=3")}
+		replaced := recurring(true)
+		replaced.Recurrence = nil
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(recurring(false), nil),
+			s.mockRepo.EXPECT().Upsert(1, input).Return(recurring(true), false, nil),
+			s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(clearsRule(recurring(true))),
+			s.mockRepo.EXPECT().Create(next).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().AttachTags(3, []string{"home"}).Return(&entity.Todo{ID: 3}, nil),
+			s.mockRepo.EXPECT().Get(1).Return(replaced, nil),
+		)
+
+		got, created, err := s.srv.Upsert(1, input)
+		s.Require().NoError(err)
+		s.False(created)
+		s.Nil(got.Recurrence)
+	})
+	s.Run("upsert creating a completed todo", func() {
+		input := entity.ReplaceTodoInput{Title: "water plants", IsCompleted: true, Recurrence: mustParseRRule("FREQ=WEEKLY;COUNT=3")}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound),
+			s.mockRepo.EXPECT().Upsert(1, input).Return(recurring(true), true, nil),
+		)
+
+		_, created, err := s.srv.Upsert(1, input)
+		s.Require().NoError(err)
+		s.True(created)
+	})
+	s.Run("missing anchor", func() {
+		input := entity.UpdateTodoInput{Recurrence: mustParseRRule("FREQ=DAILY")}
+		s.mockRepo.EXPECT().Update(1, input).Return(entity.ErrRecurrenceAnchor).Times(1)
+
+		err := s.srv.Update(1, input)
+		s.ErrorIs(err, service.ErrInvalidInput)
+	})
+}
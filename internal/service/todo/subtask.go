@@ -0,0 +1,39 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+func (s *Service) SetParent(id, parentID int) (*entity.Todo, error) {
+	if err := s.authorize(id, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	if parentID != 0 && parentID != id {
+		if err := s.authorize(parentID, entity.ActionRead, entity.ErrParentNotFound); err != nil {
+			return nil, err
+		}
+	}
+	var todo *entity.Todo
+	err := s.track(id, func() (err error) {
+		todo, err = s.repo.SetParent(id, parentID)
+		return err
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
+}
+
+// Subtree only checks the root, since subtasks always stay in the project of
+// their parent.
+func (s *Service) Subtree(id int) ([]entity.Todo, error) {
+	if err := s.authorize(id, entity.ActionRead, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	todos, err := s.repo.Subtree(id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todos, nil
+}
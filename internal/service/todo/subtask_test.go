@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+func (s *todoSuite) TestSetParent() {
+	tests := []struct {
+		desc    string
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				s.mockRepo.EXPECT().SetParent(2, 1).Return(&entity.Todo{ID: 2, ParentID: 1}, nil).Times(1)
+			},
+			want: &entity.Todo{ID: 2, ParentID: 1},
+		},
+		{
+			desc: "cycle",
+			setup: func() {
+				s.mockRepo.EXPECT().SetParent(2, 1).Return(nil, entity.ErrParentCycle).Times(1)
+			},
+			wantErr: service.ErrInvalidInput,
+		},
+		{
+			desc: "parent not found",
+			setup: func() {
+				s.mockRepo.EXPECT().SetParent(2, 1).Return(nil, entity.ErrParentNotFound).Times(1)
+			},
+			wantErr: service.ErrInvalidInput,
+		},
+		{
+			desc: "not found",
+			setup: func() {
+				s.mockRepo.EXPECT().SetParent(2, 1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.SetParent(2, 1)
+			s.ErrorIs(err, tt.wantErr)
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *todoSuite) TestSubtree() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Subtree(1).Return([]entity.Todo{{ID: 1}, {ID: 2, ParentID: 1}}, nil).Times(1)
+
+		got, err := s.srv.Subtree(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.Todo{{ID: 1}, {ID: 2, ParentID: 1}}, got)
+	})
+	s.Run("not found", func() {
+		s.mockRepo.EXPECT().Subtree(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Subtree(1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}
@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+)
+
+// Tags belong to the workspace as a whole, so changing them takes a
+// workspace role, while attaching them only takes changing the todo.
+func (s *Service) CreateTag(name string) (*entity.Tag, error) {
+	if err := s.checkWorkspace(entity.ActionCreate); err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.CreateTag(name)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return tag, nil
+}
+
+// ListTags is empty for actors who may not read the workspace.
+func (s *Service) ListTags() ([]entity.Tag, error) {
+	if err := s.checkWorkspace(entity.ActionRead); err != nil {
+		return []entity.Tag{}, nil
+	}
+	return s.repo.ListTags()
+}
+
+func (s *Service) GetTag(id int) (*entity.Tag, error) {
+	if err := s.checkWorkspace(entity.ActionRead); err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.GetTag(id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return tag, nil
+}
+
+func (s *Service) RenameTag(id int, name string) (*entity.Tag, error) {
+	if err := s.checkWorkspace(entity.ActionUpdate); err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.RenameTag(id, name)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return tag, nil
+}
+
+func (s *Service) DeleteTag(id int) error {
+	if err := s.checkWorkspace(entity.ActionDelete); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(id); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// MergeTags deletes the source tag, so it takes deleting tags.
+func (s *Service) MergeTags(sourceID, targetID int) (*entity.Tag, error) {
+	if err := s.checkWorkspace(entity.ActionDelete); err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.MergeTags(sourceID, targetID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return tag, nil
+}
+
+func (s *Service) AttachTags(todoID int, names []string) (*entity.Todo, error) {
+	if err := s.authorize(todoID, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	var todo *entity.Todo
+	err := s.track(todoID, func() (err error) {
+		todo, err = s.repo.AttachTags(todoID, names)
+		return err
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
+}
+
+func (s *Service) DetachTags(todoID int, names []string) (*entity.Todo, error) {
+	if err := s.authorize(todoID, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	var todo *entity.Todo
+	err := s.track(todoID, func() (err error) {
+		todo, err = s.repo.DetachTags(todoID, names)
+		return err
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
+}
@@ -0,0 +1,130 @@
+package todo
+
+import (
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
+)
+
+func (s *todoSuite) TestCreateTag() {
+	tests := []struct {
+		desc    string
+		setup   func()
+		want    *entity.Tag
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				s.mockRepo.EXPECT().CreateTag("work").Return(&entity.Tag{ID: 1, Name: "work"}, nil).Times(1)
+			},
+			want: &entity.Tag{ID: 1, Name: "work"},
+		},
+		{
+			desc: "invalid name",
+			setup: func() {
+				s.mockRepo.EXPECT().CreateTag("work").Return(nil, entity.ErrInvalidTagName).Times(1)
+			},
+			wantErr: service.ErrInvalidInput,
+		},
+		{
+			desc: "exists",
+			setup: func() {
+				s.mockRepo.EXPECT().CreateTag("work").Return(nil, entity.ErrTagExists).Times(1)
+			},
+			wantErr: service.ErrConflict,
+		},
+		{
+			desc: "create failed",
+			setup: func() {
+				s.mockRepo.EXPECT().CreateTag("work").Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.CreateTag("work")
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestTagErrors() {
+	s.Run("get not found", func() {
+		s.mockRepo.EXPECT().GetTag(1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.GetTag(1)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("rename taken", func() {
+		s.mockRepo.EXPECT().RenameTag(1, "home").Return(nil, entity.ErrTagExists).Times(1)
+
+		_, err := s.srv.RenameTag(1, "home")
+		s.ErrorIs(err, service.ErrConflict)
+	})
+	s.Run("delete not found", func() {
+		s.mockRepo.EXPECT().DeleteTag(1).Return(repo.ErrNotFound).Times(1)
+
+		s.ErrorIs(s.srv.DeleteTag(1), service.ErrNotFound)
+	})
+	s.Run("merge into itself", func() {
+		s.mockRepo.EXPECT().MergeTags(1, 1).Return(nil, entity.ErrMergeSelf).Times(1)
+
+		_, err := s.srv.MergeTags(1, 1)
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrMergeSelf)
+	})
+	s.Run("attach to missing todo", func() {
+		s.mockRepo.EXPECT().AttachTags(1, []string{"work"}).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.AttachTags(1, []string{"work"})
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+	s.Run("detach invalid name", func() {
+		s.mockRepo.EXPECT().DetachTags(1, []string{"a|b"}).Return(nil, entity.ErrInvalidTagName).Times(1)
+
+		_, err := s.srv.DetachTags(1, []string{"a|b"})
+		s.ErrorIs(err, service.ErrInvalidInput)
+	})
+}
+
+func (s *todoSuite) TestListTagged() {
+	expr := entity.TagAnd{Left: entity.TagName("work"), Right: entity.TagNot{Expr: entity.TagName("home")}}
+
+	s.Run("uses the tag index", func() {
+		s.mockRepo.EXPECT().ListTagged(expr).Return([]entity.Todo{
+			{ID: 2, CreatedAt: time.Unix(2, 0), Tags: []string{"work"}},
+			{ID: 1, CreatedAt: time.Unix(1, 0), Tags: []string{"work"}},
+		}, nil).Times(1)
+
+		got, err := s.srv.List(entity.TodoQuery{Tags: expr})
+		s.Require().NoError(err)
+		s.Equal([]int{1, 2}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("combines with due views", func() {
+		timeNow = func() time.Time {
+			return time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)
+		}
+		defer func() { timeNow = time.Now }()
+		s.mockRepo.EXPECT().ListTagged(expr).Return([]entity.Todo{
+			{ID: 1, Tags: []string{"work"}, Due: lo.ToPtr(entity.NewDate(2024, 3, 10))},
+			{ID: 2, Tags: []string{"work"}},
+		}, nil).Times(1)
+
+		got, err := s.srv.List(entity.TodoQuery{Tags: expr, View: entity.DueToday})
+		s.Require().NoError(err)
+		s.Equal([]int{1}, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+	})
+	s.Run("list failed", func() {
+		s.mockRepo.EXPECT().ListTagged(expr).Return(nil, mockErr).Times(1)
+
+		_, err := s.srv.List(entity.TodoQuery{Tags: expr})
+		s.ErrorIs(err, mockErr)
+	})
+}
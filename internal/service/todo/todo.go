@@ -2,33 +2,83 @@ package todo
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/cloudingcity/todo/internal/entity"
 	"github.com/cloudingcity/todo/internal/repo"
 	"github.com/cloudingcity/todo/internal/service"
+	"github.com/samber/lo"
 )
 
+var timeNow = time.Now
+
 type Service struct {
 	repo repo.Todo
+	// actor is who the service is scoped to, or nil for the unscoped
+	// service.
+	actor   *entity.Actor
+	quota   Quota
+	history repo.History
 }
 
-func NewService(repo repo.Todo) service.Todo {
-	return &Service{
+func NewService(repo repo.Todo, opts ...Option) service.Todo {
+	s := &Service{
 		repo: repo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Service) Create(title, description string) (*entity.Todo, error) {
-	return s.repo.Create(title, description)
+func (s *Service) Create(input entity.CreateTodoInput) (*entity.Todo, error) {
+	if err := s.checkCreate(&input); err != nil {
+		return nil, err
+	}
+	if err := s.checkTodos(1); err != nil {
+		return nil, err
+	}
+	todo, err := s.repo.Create(input)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
 }
 
-func (s *Service) List() ([]entity.Todo, error) {
-	return s.repo.List()
+func (s *Service) List(query entity.TodoQuery) ([]entity.Todo, error) {
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if query.Tags != nil {
+		todos, err = s.repo.ListTagged(query.Tags)
+	} else {
+		todos, err = s.repo.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	todos = slices.DeleteFunc(todos, func(todo entity.Todo) bool {
+		return !s.visible(todo) || !query.Match(todo, now)
+	})
+	slices.SortStableFunc(todos, query.Compare)
+	if query.Order == entity.OrderTopological {
+		deps, err := s.repo.ListDependencies()
+		if err != nil {
+			return nil, err
+		}
+		todos = entity.TopologicalOrder(todos, deps)
+	}
+	return todos, nil
 }
 
 func (s *Service) Get(id int) (*entity.Todo, error) {
 	todo, err := s.repo.Get(id)
-	if errors.Is(err, repo.ErrNotFound) {
+	if errors.Is(err, repo.ErrNotFound) || err == nil && !s.visible(*todo) {
 		return nil, service.ErrNotFound
 	} else if err != nil {
 		return nil, err
@@ -36,20 +86,323 @@ func (s *Service) Get(id int) (*entity.Todo, error) {
 	return todo, nil
 }
 
+// Update creates the next occurrence when the update completes a recurring
+// todo.
 func (s *Service) Update(id int, input entity.UpdateTodoInput) error {
-	if err := s.repo.Update(id, input); errors.Is(err, repo.ErrNotFound) {
-		return service.ErrNotFound
-	} else if err != nil {
+	if err := s.checkDescription(input.Description); err != nil {
+		return err
+	}
+	var prev *entity.Todo
+	if input.IsCompleted != nil && *input.IsCompleted {
+		var err error
+		if prev, err = s.Get(id); err != nil {
+			return err
+		}
+		if err := s.check(*prev, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+			return err
+		}
+	} else if err := s.authorize(id, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return err
+	}
+	if err := s.checkProject(input.ProjectID); err != nil {
 		return err
 	}
+	err := s.track(id, func() error {
+		return s.repo.Update(id, input)
+	})
+	if err != nil {
+		return mapError(err)
+	}
+	if prev != nil && !prev.IsCompleted {
+		return s.recur(id)
+	}
 	return nil
 }
 
-func (s *Service) Delete(id int) error {
-	if err := s.repo.Delete(id); errors.Is(err, repo.ErrNotFound) {
-		return service.ErrNotFound
-	} else if err != nil {
+// UpdateFunc checks the todo before fn sees it, and its project again
+// after fn when fn moves it.
+func (s *Service) UpdateFunc(id int, fn func(todo *entity.Todo) error) error {
+	var completed bool
+	update := func(todo *entity.Todo) error {
+		if err := s.check(*todo, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+			return err
+		}
+		prev := *todo
+		if err := fn(todo); err != nil {
+			return err
+		}
+		if todo.ProjectID != prev.ProjectID {
+			if err := s.checkProject(&todo.ProjectID); err != nil {
+				return err
+			}
+		}
+		if todo.Description != prev.Description {
+			if err := s.checkDescription(&todo.Description); err != nil {
+				return err
+			}
+		}
+		completed = todo.IsCompleted && !prev.IsCompleted
+		return nil
+	}
+	err := s.track(id, func() error {
+		return s.repo.UpdateFunc(id, update)
+	})
+	if err != nil {
+		return mapError(err)
+	}
+	if completed {
+		return s.recur(id)
+	}
+	return nil
+}
+
+// Replace creates the next occurrence when the replacement completes a
+// recurring todo.
+func (s *Service) Replace(id int, input entity.ReplaceTodoInput) (*entity.Todo, error) {
+	var prev *entity.Todo
+	if input.IsCompleted {
+		var err error
+		if prev, err = s.Get(id); err != nil {
+			return nil, err
+		}
+		if err := s.check(*prev, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+			return nil, err
+		}
+	} else if err := s.authorize(id, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	if err := s.checkDescription(&input.Description); err != nil {
+		return nil, err
+	}
+	var todo *entity.Todo
+	err := s.track(id, func() (err error) {
+		todo, err = s.repo.Replace(id, input)
 		return err
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if prev != nil && !prev.IsCompleted {
+		return s.recurReplaced(todo)
+	}
+	return todo, nil
+}
+
+// Upsert on a todo the actor may not read fails as not found rather than
+// creating a todo with a taken ID. New todos go to the inbox. Like Replace,
+// it creates the next occurrence when it completes a recurring todo.
+func (s *Service) Upsert(id int, input entity.ReplaceTodoInput) (*entity.Todo, bool, error) {
+	var prev *entity.Todo
+	if s.actor != nil || input.IsCompleted {
+		todo, err := s.repo.Get(id)
+		if err == nil {
+			prev = todo
+		} else if !errors.Is(err, repo.ErrNotFound) {
+			return nil, false, err
+		}
+	}
+	if s.actor != nil {
+		var err error
+		if prev != nil {
+			err = s.check(*prev, entity.ActionUpdate, repo.ErrNotFound)
+		} else {
+			err = s.checkWorkspace(entity.ActionCreate)
+			if err == nil {
+				err = s.checkTodos(1)
+			}
+		}
+		if err == nil {
+			err = s.checkDescription(&input.Description)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		input.OwnerID = s.actor.UserID
+	}
+	var (
+		todo    *entity.Todo
+		created bool
+	)
+	err := s.track(id, func() (err error) {
+		todo, created, err = s.repo.Upsert(id, input)
+		return err
+	})
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	if !created && prev != nil && !prev.IsCompleted && todo.IsCompleted {
+		todo, err = s.recurReplaced(todo)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return todo, created, nil
+}
+
+func (s *Service) Delete(id int, mode entity.SubtaskDeleteMode) error {
+	if err := s.authorize(id, entity.ActionDelete, repo.ErrNotFound); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id, mode); err != nil {
+		return mapError(err)
 	}
 	return nil
 }
+
+func (s *Service) Move(id int, input entity.MoveTodoInput) (*entity.Todo, error) {
+	if err := s.authorize(id, entity.ActionUpdate, repo.ErrNotFound); err != nil {
+		return nil, err
+	}
+	target := input.After
+	if input.Before != nil {
+		target = input.Before
+	}
+	if target != nil && *target != id {
+		if err := s.authorize(*target, entity.ActionRead, entity.ErrMoveTarget); err != nil {
+			return nil, err
+		}
+	}
+	todo, err := s.repo.Move(id, input)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return todo, nil
+}
+
+func (s *Service) BatchCreate(inputs []entity.CreateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	results := make([]entity.BatchResult, len(inputs))
+	if mode == entity.BatchAtomic {
+		inputs = slices.Clone(inputs)
+		for i := range inputs {
+			if err := s.checkCreate(&inputs[i]); err != nil {
+				return nil, &service.BatchError{Index: i, Err: err}
+			}
+		}
+		remaining, err := s.remaining()
+		if err != nil {
+			return nil, err
+		}
+		if len(inputs) > remaining {
+			return nil, &service.BatchError{Index: remaining, Err: s.todoQuotaError()}
+		}
+		todos, err := s.repo.BatchCreate(inputs)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		for i := range todos {
+			results[i].Todo = &todos[i]
+		}
+		return results, nil
+	}
+
+	for i, input := range inputs {
+		results[i].Todo, results[i].Err = s.Create(input)
+	}
+	return results, nil
+}
+
+func (s *Service) BatchUpdate(inputs []entity.BatchUpdateTodoInput, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	results := make([]entity.BatchResult, len(inputs))
+	if mode == entity.BatchAtomic {
+		// Todos the batch completes get their next occurrence afterwards, as
+		// they would through Update.
+		var completing []int
+		for i, input := range inputs {
+			var err error
+			if input.Input.IsCompleted != nil && *input.Input.IsCompleted {
+				var prev *entity.Todo
+				if prev, err = s.Get(input.ID); err == nil {
+					err = s.check(*prev, entity.ActionUpdate, repo.ErrNotFound)
+				}
+				if err == nil && !prev.IsCompleted {
+					completing = append(completing, input.ID)
+				}
+			} else {
+				err = s.authorize(input.ID, entity.ActionUpdate, repo.ErrNotFound)
+			}
+			if err == nil {
+				err = s.checkProject(input.Input.ProjectID)
+			}
+			if err == nil {
+				err = s.checkDescription(input.Input.Description)
+			}
+			if err != nil {
+				return nil, &service.BatchError{Index: i, Err: err}
+			}
+		}
+		ids := lo.Map(inputs, func(input entity.BatchUpdateTodoInput, _ int) int { return input.ID })
+		err := s.trackAll(ids, func() error {
+			return s.repo.BatchUpdate(inputs)
+		})
+		if err != nil {
+			return nil, mapError(err)
+		}
+		for _, id := range lo.Uniq(completing) {
+			if err := s.recur(id); err != nil {
+				return nil, err
+			}
+		}
+		return results, nil
+	}
+
+	for i, input := range inputs {
+		results[i].Err = s.Update(input.ID, input.Input)
+	}
+	return results, nil
+}
+
+func (s *Service) BatchDelete(ids []int, mode entity.BatchMode) ([]entity.BatchResult, error) {
+	results := make([]entity.BatchResult, len(ids))
+	if mode == entity.BatchAtomic {
+		if err := s.authorizeAll(ids, entity.ActionDelete); err != nil {
+			return nil, err
+		}
+		if err := s.repo.BatchDelete(ids); err != nil {
+			return nil, mapError(err)
+		}
+		return results, nil
+	}
+
+	for i, id := range ids {
+		results[i].Err = s.Delete(id, entity.SubtaskDeleteCascade)
+	}
+	return results, nil
+}
+
+// mapError translates repo and entity errors into their service
+// counterparts, keeping the item index of batch errors.
+func mapError(err error) error {
+	var batchErr *repo.BatchError
+	if errors.As(err, &batchErr) {
+		return &service.BatchError{Index: batchErr.Index, Err: mapError(batchErr.Err)}
+	}
+	switch {
+	case errors.Is(err, repo.ErrNotFound),
+		errors.Is(err, entity.ErrChecklistItemNotFound):
+		return service.ErrNotFound
+	case errors.Is(err, entity.ErrStartAfterDue),
+		errors.Is(err, entity.ErrTodoIDTooLarge),
+		errors.Is(err, entity.ErrMoveSelf),
+		errors.Is(err, entity.ErrMoveTarget),
+		errors.Is(err, entity.ErrInvalidTagName),
+		errors.Is(err, entity.ErrMergeSelf),
+		errors.Is(err, entity.ErrParentNotFound),
+		errors.Is(err, entity.ErrParentCycle),
+		errors.Is(err, entity.ErrBlockerNotFound),
+		errors.Is(err, entity.ErrDependencySelf),
+		errors.Is(err, entity.ErrDependencyCycle),
+		errors.Is(err, entity.ErrRecurrenceAnchor),
+		errors.Is(err, entity.ErrEmptyChecklistItem),
+		errors.Is(err, entity.ErrChecklistItemTooLong),
+		errors.Is(err, entity.ErrChecklistFull),
+		errors.Is(err, entity.ErrChecklistOrder),
+		errors.Is(err, entity.ErrNegativeEstimate):
+		return fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	case errors.Is(err, entity.ErrTagExists),
+		errors.Is(err, entity.ErrHasSubtasks),
+		errors.Is(err, entity.ErrDependencyExists),
+		errors.Is(err, entity.ErrBlocked):
+		return fmt.Errorf("%w: %w", service.ErrConflict, err)
+	}
+	return err
+}
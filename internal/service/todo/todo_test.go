@@ -2,6 +2,7 @@ package todo
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -48,7 +49,7 @@ func (s *todoSuite) TestCreate() {
 			title:       "title-1",
 			description: "desc-1",
 			setup: func() {
-				s.mockRepo.EXPECT().Create("title-1", "desc-1").Return(&entity.Todo{
+				s.mockRepo.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"}).Return(&entity.Todo{
 					ID:          1,
 					Title:       "title-1",
 					Description: "desc-1",
@@ -72,7 +73,7 @@ func (s *todoSuite) TestCreate() {
 			title:       "title-1",
 			description: "desc-1",
 			setup: func() {
-				s.mockRepo.EXPECT().Create("title-1", "desc-1").Return(nil, mockErr).Times(1)
+				s.mockRepo.EXPECT().Create(entity.CreateTodoInput{Title: "title-1", Description: "desc-1"}).Return(nil, mockErr).Times(1)
 			},
 			want:    nil,
 			wantErr: mockErr,
@@ -81,7 +82,7 @@ func (s *todoSuite) TestCreate() {
 	for _, tt := range tests {
 		s.Run(tt.desc, func() {
 			tt.setup()
-			got, err := s.srv.Create(tt.title, tt.description)
+			got, err := s.srv.Create(entity.CreateTodoInput{Title: tt.title, Description: tt.description})
 			s.Equal(tt.want, got)
 			s.ErrorIs(tt.wantErr, err)
 		})
@@ -165,7 +166,7 @@ func (s *todoSuite) TestList() {
 	for _, tt := range tests {
 		s.Run(tt.desc, func() {
 			tt.setup()
-			got, err := s.srv.List()
+			got, err := s.srv.List(entity.TodoQuery{})
 			s.Equal(tt.want, got)
 			s.ErrorIs(tt.wantErr, err)
 		})
@@ -241,11 +242,15 @@ func (s *todoSuite) TestUpdate() {
 				IsCompleted: lo.ToPtr(true),
 			},
 			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
 				s.mockRepo.EXPECT().Update(1, entity.UpdateTodoInput{
 					Title:       lo.ToPtr("title-update"),
 					Description: lo.ToPtr("desc-update"),
 					IsCompleted: lo.ToPtr(true),
 				}).Return(nil).Times(1)
+				s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).DoAndReturn(func(_ int, fn func(*entity.Todo) error) error {
+					return fn(&entity.Todo{ID: 1, IsCompleted: true})
+				}).Times(1)
 			},
 			wantErr: nil,
 		},
@@ -258,11 +263,7 @@ func (s *todoSuite) TestUpdate() {
 				IsCompleted: lo.ToPtr(true),
 			},
 			setup: func() {
-				s.mockRepo.EXPECT().Update(1, entity.UpdateTodoInput{
-					Title:       lo.ToPtr("title-update"),
-					Description: lo.ToPtr("desc-update"),
-					IsCompleted: lo.ToPtr(true),
-				}).Return(repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
 			},
 			wantErr: service.ErrNotFound,
 		},
@@ -287,7 +288,7 @@ func (s *todoSuite) TestDelete() {
 			desc: "success",
 			id:   1,
 			setup: func() {
-				s.mockRepo.EXPECT().Delete(1).Return(nil).Times(1)
+				s.mockRepo.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(nil).Times(1)
 			},
 			wantErr: nil,
 		},
@@ -295,16 +296,516 @@ func (s *todoSuite) TestDelete() {
 			desc: "not found",
 			id:   1,
 			setup: func() {
-				s.mockRepo.EXPECT().Delete(1).Return(repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(repo.ErrNotFound).Times(1)
 			},
 			wantErr: service.ErrNotFound,
 		},
+		{
+			desc: "has subtasks",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(entity.ErrHasSubtasks).Times(1)
+			},
+			wantErr: service.ErrConflict,
+		},
 	}
 	for _, tt := range tests {
 		s.Run(tt.desc, func() {
 			tt.setup()
-			err := s.srv.Delete(tt.id)
-			s.ErrorIs(tt.wantErr, err)
+			err := s.srv.Delete(tt.id, entity.SubtaskDeleteCascade)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestUpdateFunc() {
+	tests := []struct {
+		desc    string
+		id      int
+		setup   func()
+		wantErr error
+	}{
+		{
+			desc: "success",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			desc: "not found",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).Return(repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "fn failed",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().UpdateFunc(1, gomock.Any()).Return(mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			err := s.srv.UpdateFunc(tt.id, func(todo *entity.Todo) error { return nil })
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestReplace() {
+	input := entity.ReplaceTodoInput{
+		Title:       "title-1",
+		Description: "desc-1",
+		IsCompleted: true,
+	}
+	tests := []struct {
+		desc    string
+		id      int
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+				s.mockRepo.EXPECT().Replace(1, input).Return(&entity.Todo{ID: 1, Title: "title-1"}, nil).Times(1)
+			},
+			want:    &entity.Todo{ID: 1, Title: "title-1"},
+			wantErr: nil,
+		},
+		{
+			desc: "not found",
+			id:   1,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			want:    nil,
+			wantErr: service.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.Replace(tt.id, input)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestUpsert() {
+	input := entity.ReplaceTodoInput{
+		Title: "title-1",
+	}
+	tests := []struct {
+		desc        string
+		setup       func()
+		want        *entity.Todo
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			desc: "created",
+			setup: func() {
+				s.mockRepo.EXPECT().Upsert(5, input).Return(&entity.Todo{ID: 5, Title: "title-1"}, true, nil).Times(1)
+			},
+			want:        &entity.Todo{ID: 5, Title: "title-1"},
+			wantCreated: true,
+		},
+		{
+			desc: "upsert failed",
+			setup: func() {
+				s.mockRepo.EXPECT().Upsert(5, input).Return(nil, false, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, created, err := s.srv.Upsert(5, input)
+			s.Equal(tt.want, got)
+			s.Equal(tt.wantCreated, created)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchCreate() {
+	inputs := []entity.CreateTodoInput{
+		{Title: "title-1"},
+		{Title: "title-2"},
+	}
+	tests := []struct {
+		desc    string
+		mode    entity.BatchMode
+		setup   func()
+		want    []entity.BatchResult
+		wantErr error
+	}{
+		{
+			desc: "atomic",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().BatchCreate(inputs).Return([]entity.Todo{
+					{ID: 1, Title: "title-1"},
+					{ID: 2, Title: "title-2"},
+				}, nil).Times(1)
+			},
+			want: []entity.BatchResult{
+				{Todo: &entity.Todo{ID: 1, Title: "title-1"}},
+				{Todo: &entity.Todo{ID: 2, Title: "title-2"}},
+			},
+		},
+		{
+			desc: "atomic failed",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().BatchCreate(inputs).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+		{
+			desc: "best effort",
+			mode: entity.BatchBestEffort,
+			setup: func() {
+				s.mockRepo.EXPECT().Create(entity.CreateTodoInput{Title: "title-1"}).Return(&entity.Todo{ID: 1, Title: "title-1"}, nil).Times(1)
+				s.mockRepo.EXPECT().Create(entity.CreateTodoInput{Title: "title-2"}).Return(nil, mockErr).Times(1)
+			},
+			want: []entity.BatchResult{
+				{Todo: &entity.Todo{ID: 1, Title: "title-1"}},
+				{Err: mockErr},
+			},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.BatchCreate(inputs, tt.mode)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchUpdate() {
+	inputs := []entity.BatchUpdateTodoInput{
+		{ID: 1, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+		{ID: 2, Input: entity.UpdateTodoInput{IsCompleted: lo.ToPtr(true)}},
+	}
+	tests := []struct {
+		desc    string
+		mode    entity.BatchMode
+		setup   func()
+		want    []entity.BatchResult
+		wantErr error
+	}{
+		{
+			desc: "atomic",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, IsCompleted: true}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2, IsCompleted: true}, nil).Times(1)
+				s.mockRepo.EXPECT().BatchUpdate(inputs).Return(nil).Times(1)
+			},
+			want: make([]entity.BatchResult, 2),
+		},
+		{
+			desc: "atomic not found",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, IsCompleted: true}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: &service.BatchError{Index: 1, Err: service.ErrNotFound},
+		},
+		{
+			desc: "atomic write failed",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Todo{ID: 2}, nil).Times(1)
+				s.mockRepo.EXPECT().BatchUpdate(inputs).Return(&repo.BatchError{Index: 1, Err: repo.ErrNotFound}).Times(1)
+			},
+			wantErr: &service.BatchError{Index: 1, Err: service.ErrNotFound},
+		},
+		{
+			desc: "best effort",
+			mode: entity.BatchBestEffort,
+			setup: func() {
+				s.mockRepo.EXPECT().Get(1).Return(&entity.Todo{ID: 1, IsCompleted: true}, nil).Times(1)
+				s.mockRepo.EXPECT().Update(1, inputs[0].Input).Return(nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			want: []entity.BatchResult{
+				{},
+				{Err: service.ErrNotFound},
+			},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.BatchUpdate(inputs, tt.mode)
+			s.Equal(tt.want, got)
+			s.Equal(tt.wantErr, err)
+		})
+	}
+}
+
+func (s *todoSuite) TestBatchDelete() {
+	tests := []struct {
+		desc    string
+		mode    entity.BatchMode
+		setup   func()
+		want    []entity.BatchResult
+		wantErr error
+	}{
+		{
+			desc: "atomic",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().BatchDelete([]int{1, 2}).Return(nil).Times(1)
+			},
+			want: make([]entity.BatchResult, 2),
+		},
+		{
+			desc: "atomic not found",
+			mode: entity.BatchAtomic,
+			setup: func() {
+				s.mockRepo.EXPECT().BatchDelete([]int{1, 2}).Return(&repo.BatchError{Index: 0, Err: repo.ErrNotFound}).Times(1)
+			},
+			wantErr: &service.BatchError{Index: 0, Err: service.ErrNotFound},
+		},
+		{
+			desc: "best effort",
+			mode: entity.BatchBestEffort,
+			setup: func() {
+				s.mockRepo.EXPECT().Delete(1, entity.SubtaskDeleteCascade).Return(repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().Delete(2, entity.SubtaskDeleteCascade).Return(nil).Times(1)
+			},
+			want: []entity.BatchResult{
+				{Err: service.ErrNotFound},
+				{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.BatchDelete([]int{1, 2}, tt.mode)
+			s.Equal(tt.want, got)
+			s.Equal(tt.wantErr, err)
+		})
+	}
+}
+
+func (s *todoSuite) TestListDue() {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	losAngeles, _ := time.LoadLocation("America/Los_Angeles")
+	timeNow = func() time.Time {
+		return time.Date(2024, 3, 10, 10, 0, 0, 0, taipei)
+	}
+	defer func() { timeNow = time.Now }()
+
+	todos := []entity.Todo{
+		{ID: 1, Due: lo.ToPtr(entity.NewDate(2024, 3, 9))},
+		{ID: 2, Due: lo.ToPtr(entity.NewDateTime(time.Date(2024, 3, 10, 9, 0, 0, 0, taipei), "Asia/Taipei"))},
+		{ID: 3, Due: lo.ToPtr(entity.NewDate(2024, 3, 10))},
+		{ID: 4, Due: lo.ToPtr(entity.NewDateTime(time.Date(2024, 3, 12, 23, 30, 0, 0, time.UTC), ""))},
+		{ID: 5, Due: lo.ToPtr(entity.NewDate(2024, 3, 20))},
+		{ID: 6, IsCompleted: true, Due: lo.ToPtr(entity.NewDate(2024, 3, 9))},
+		{ID: 7},
+	}
+	ids := func(todos []entity.Todo) []int {
+		return lo.Map(todos, func(todo entity.Todo, _ int) int { return todo.ID })
+	}
+
+	tests := []struct {
+		desc    string
+		filter  entity.TodoQuery
+		setup   func()
+		want    []int
+		wantErr error
+	}{
+		{
+			desc:   "overdue",
+			filter: entity.TodoQuery{View: entity.DueOverdue, Location: taipei},
+			want:   []int{1, 2},
+		},
+		{
+			desc:   "today",
+			filter: entity.TodoQuery{View: entity.DueToday, Location: taipei},
+			want:   []int{2, 3},
+		},
+		{
+			desc:   "today in another time zone",
+			filter: entity.TodoQuery{View: entity.DueToday, Location: losAngeles},
+			want:   []int{2, 1},
+		},
+		{
+			desc:   "upcoming",
+			filter: entity.TodoQuery{View: entity.DueUpcoming, Days: 3, Location: taipei},
+			want:   []int{4},
+		},
+		{
+			desc:   "upcoming further ahead",
+			filter: entity.TodoQuery{View: entity.DueUpcoming, Days: 14, Location: taipei},
+			want:   []int{4, 5},
+		},
+		{
+			desc:   "list failed",
+			filter: entity.TodoQuery{View: entity.DueOverdue, Location: taipei},
+			setup: func() {
+				s.mockRepo.EXPECT().List().Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			} else {
+				s.mockRepo.EXPECT().List().Return(slices.Clone(todos), nil).Times(1)
+			}
+			got, err := s.srv.List(tt.filter)
+			s.ErrorIs(err, tt.wantErr)
+			if tt.wantErr == nil {
+				s.Equal(tt.want, ids(got))
+			}
+		})
+	}
+}
+
+func (s *todoSuite) TestInvalidSchedule() {
+	s.Run("start after due", func() {
+		input := entity.CreateTodoInput{Title: "title-1"}
+		s.mockRepo.EXPECT().Create(input).Return(nil, entity.ErrStartAfterDue).Times(1)
+
+		_, err := s.srv.Create(input)
+		s.ErrorIs(err, service.ErrInvalidInput)
+		s.ErrorIs(err, entity.ErrStartAfterDue)
+	})
+	s.Run("batch item start after due", func() {
+		s.mockRepo.EXPECT().BatchUpdate(gomock.Any()).Return(&repo.BatchError{Index: 1, Err: entity.ErrStartAfterDue}).Times(1)
+
+		_, err := s.srv.BatchUpdate(make([]entity.BatchUpdateTodoInput, 2), entity.BatchAtomic)
+		var batchErr *service.BatchError
+		s.Require().ErrorAs(err, &batchErr)
+		s.Equal(1, batchErr.Index)
+		s.ErrorIs(err, service.ErrInvalidInput)
+	})
+}
+
+func (s *todoSuite) TestListOrder() {
+	todos := []entity.Todo{
+		{ID: 1, Priority: entity.PriorityLow, Rank: "r", CreatedAt: time.Unix(1, 0)},
+		{ID: 2, Priority: entity.PriorityUrgent, Rank: "w", CreatedAt: time.Unix(2, 0), Due: lo.ToPtr(entity.NewDate(2024, 3, 12))},
+		{ID: 3, Priority: entity.PriorityNone, Rank: "i", CreatedAt: time.Unix(3, 0), Due: lo.ToPtr(entity.NewDate(2024, 3, 10))},
+		{ID: 4, Priority: entity.PriorityLow, Rank: "9", CreatedAt: time.Unix(4, 0)},
+	}
+
+	tests := []struct {
+		desc  string
+		order entity.TodoOrder
+		want  []int
+	}{
+		{desc: "created by default", want: []int{1, 2, 3, 4}},
+		{desc: "manual", order: entity.OrderManual, want: []int{4, 3, 1, 2}},
+		{desc: "priority then rank", order: entity.OrderPriority, want: []int{2, 4, 1, 3}},
+		{desc: "due with undated last", order: entity.OrderDue, want: []int{3, 2, 4, 1}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockRepo.EXPECT().List().Return(slices.Clone(todos), nil).Times(1)
+
+			got, err := s.srv.List(entity.TodoQuery{Order: tt.order})
+			s.Require().NoError(err)
+			s.Equal(tt.want, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
+		})
+	}
+}
+
+func (s *todoSuite) TestMove() {
+	input := entity.MoveTodoInput{After: lo.ToPtr(2)}
+	tests := []struct {
+		desc    string
+		setup   func()
+		want    *entity.Todo
+		wantErr error
+	}{
+		{
+			desc: "success",
+			setup: func() {
+				s.mockRepo.EXPECT().Move(1, input).Return(&entity.Todo{ID: 1, Rank: "w"}, nil).Times(1)
+			},
+			want: &entity.Todo{ID: 1, Rank: "w"},
+		},
+		{
+			desc: "not found",
+			setup: func() {
+				s.mockRepo.EXPECT().Move(1, input).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc: "target not found",
+			setup: func() {
+				s.mockRepo.EXPECT().Move(1, input).Return(nil, entity.ErrMoveTarget).Times(1)
+			},
+			wantErr: service.ErrInvalidInput,
+		},
+		{
+			desc: "move failed",
+			setup: func() {
+				s.mockRepo.EXPECT().Move(1, input).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+			got, err := s.srv.Move(1, input)
+			s.Equal(tt.want, got)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *todoSuite) TestListProject() {
+	todos := []entity.Todo{
+		{ID: 1, ProjectID: 2},
+		{ID: 2},
+		{ID: 3, ProjectID: 2},
+	}
+
+	tests := []struct {
+		desc    string
+		project *int
+		want    []int
+	}{
+		{desc: "every project", want: []int{1, 2, 3}},
+		{desc: "one project", project: lo.ToPtr(2), want: []int{1, 3}},
+		{desc: "inbox", project: lo.ToPtr(entity.Inbox), want: []int{2}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			s.mockRepo.EXPECT().List().Return(slices.Clone(todos), nil).Times(1)
+
+			got, err := s.srv.List(entity.TodoQuery{Project: tt.project})
+			s.Require().NoError(err)
+			s.Equal(tt.want, lo.Map(got, func(todo entity.Todo, _ int) int { return todo.ID }))
 		})
 	}
 }
@@ -0,0 +1,269 @@
+package user
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/cloudingcity/todo/internal/password"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+var (
+	timeNow = time.Now
+	// newTokenID returns the jti of an access token, which logout revokes.
+	newTokenID = rand.Text
+)
+
+const (
+	DefaultTokenTTL = 24 * time.Hour
+	// Issuer is the iss claim of the access tokens.
+	Issuer = "todo"
+)
+
+type Option func(*Service)
+
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// WithPasswordParams sets the KDF parameters of new password hashes.
+func WithPasswordParams(params password.Params) Option {
+	return func(s *Service) {
+		s.params = params
+	}
+}
+
+// Service registers users and issues signed access tokens. Tokens are JWTs
+// verified locally with the same method, so the only session state kept is
+// the IDs of the tokens revoked at logout.
+type Service struct {
+	repo   repo.User
+	method jwt.Method
+	ttl    time.Duration
+	params password.Params
+	// dummyHash is verified against when the email is unknown, so a failed
+	// login takes as long whether or not the account exists.
+	dummyHash string
+
+	mu sync.Mutex
+	// revoked maps the IDs of revoked tokens to when they expire, after
+	// which they are rejected anyway and forgotten.
+	revoked map[string]time.Time
+}
+
+func NewService(repo repo.User, method jwt.Method, opts ...Option) (service.User, error) {
+	s := &Service{
+		repo:    repo,
+		method:  method,
+		ttl:     DefaultTokenTTL,
+		params:  password.DefaultParams,
+		revoked: make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var err error
+	if s.dummyHash, err = password.HashWith(s.params, "dummy password"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Service) Register(input entity.RegisterInput) (*entity.User, error) {
+	email, err := entity.NormalizeEmail(input.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	if len(input.Password) < entity.MinPasswordLength {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrPasswordTooShort)
+	}
+	hash, err := password.HashWith(s.params, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.Create(entity.CreateUserInput{
+		Email:        email,
+		Name:         strings.TrimSpace(input.Name),
+		PasswordHash: hash,
+	})
+	if errors.Is(err, entity.ErrEmailTaken) {
+		return nil, fmt.Errorf("%w: %w", service.ErrConflict, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login does not say whether the email or the password was wrong, or
+// whether the user only logs in with SSO.
+func (s *Service) Login(email, pass string) (*entity.User, *entity.AccessToken, error) {
+	user, err := s.findByEmail(email)
+	if errors.Is(err, repo.ErrNotFound) || err == nil && user.PasswordHash == "" {
+		_, _ = password.Verify(pass, s.dummyHash)
+		return nil, nil, service.ErrUnauthorized
+	} else if err != nil {
+		return nil, nil, err
+	}
+	ok, err := password.Verify(pass, user.PasswordHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, service.ErrUnauthorized
+	}
+
+	token, err := s.issue(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+// LoginExternal logs in the user an identity provider vouches for. The
+// first time, the subject is linked to the user with the same email, or to
+// a new user without a password, as long as the provider verified the email.
+func (s *Service) LoginExternal(ext entity.ExternalUser) (*entity.User, *entity.AccessToken, error) {
+	user, err := s.repo.GetByIdentity(ext.Issuer, ext.Subject)
+	if errors.Is(err, repo.ErrNotFound) {
+		user, err = s.link(ext)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := s.issue(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+func (s *Service) link(ext entity.ExternalUser) (*entity.User, error) {
+	email, err := entity.NormalizeEmail(ext.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrUnauthorized, err)
+	}
+	if !ext.EmailVerified {
+		return nil, fmt.Errorf("%w: the provider has not verified %s", service.ErrUnauthorized, email)
+	}
+
+	user, err := s.repo.GetByEmail(email)
+	if errors.Is(err, repo.ErrNotFound) {
+		user, err = s.repo.Create(entity.CreateUserInput{
+			Email: email,
+			Name:  strings.TrimSpace(ext.Name),
+		})
+	}
+	if errors.Is(err, entity.ErrEmailTaken) {
+		return nil, fmt.Errorf("%w: %w", service.ErrConflict, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.LinkIdentity(user.ID, ext.Issuer, ext.Subject)
+	if errors.Is(err, entity.ErrIdentityLinked) {
+		return nil, fmt.Errorf("%w: %w", service.ErrConflict, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *Service) findByEmail(email string) (*entity.User, error) {
+	email, err := entity.NormalizeEmail(email)
+	if err != nil {
+		return nil, repo.ErrNotFound
+	}
+	return s.repo.GetByEmail(email)
+}
+
+func (s *Service) issue(user *entity.User) (*entity.AccessToken, error) {
+	now := timeNow()
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+	value, err := jwt.Sign(s.method, "", jwt.Claims{
+		Issuer:    Issuer,
+		Subject:   strconv.Itoa(user.ID),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+		ID:        newTokenID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.AccessToken{Value: value, ExpiresAt: expiresAt}, nil
+}
+
+func (s *Service) Authenticate(token string) (*entity.User, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad subject", service.ErrUnauthorized)
+	}
+
+	user, err := s.repo.Get(id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown user", service.ErrUnauthorized)
+	} else if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Logout revokes an access token, which is rejected from then on.
+func (s *Service) Logout(token string) error {
+	claims, err := s.verify(token)
+	if err != nil {
+		return err
+	}
+	if claims.ID == "" {
+		return fmt.Errorf("%w: token cannot be revoked", service.ErrUnauthorized)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timeNow()
+	for id, expiresAt := range s.revoked {
+		if !now.Before(expiresAt) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[claims.ID] = time.Unix(claims.ExpiresAt, 0)
+	return nil
+}
+
+// verify checks the signature and claims of an access token and that it
+// was not revoked.
+func (s *Service) verify(token string) (*jwt.Claims, error) {
+	var claims jwt.Claims
+	if err := jwt.Parse(token, s.method, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrUnauthorized, err)
+	}
+	if err := claims.Validate(timeNow(), jwt.Expected{Issuer: Issuer}); err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrUnauthorized, err)
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("%w: token does not expire", service.ErrUnauthorized)
+	}
+
+	s.mu.Lock()
+	_, revoked := s.revoked[claims.ID]
+	s.mu.Unlock()
+	if revoked {
+		return nil, fmt.Errorf("%w: token was revoked", service.ErrUnauthorized)
+	}
+	return &claims, nil
+}
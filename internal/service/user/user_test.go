@@ -0,0 +1,447 @@
+package user
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/jwt"
+	"github.com/cloudingcity/todo/internal/password"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockErr = errors.New("something wrong")
+	// fastParams keep hashing cheap in tests.
+	fastParams = password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	secret     = []byte("0123456789abcdef0123456789abcdef")
+)
+
+type userSuite struct {
+	suite.Suite
+	srv      service.User
+	mockRepo *mocks.MockUser
+	now      time.Time
+}
+
+func (s *userSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = mocks.NewMockUser(ctrl)
+	var err error
+	s.srv, err = NewService(s.mockRepo, jwt.HS256(secret), WithPasswordParams(fastParams), WithTokenTTL(time.Hour))
+	s.Require().NoError(err)
+	s.now = time.Unix(123456789, 0)
+	timeNow = func() time.Time {
+		return s.now
+	}
+	newTokenID = func() string {
+		return "token-id"
+	}
+}
+
+func (s *userSuite) TearDownSubTest() {
+	timeNow = time.Now
+	newTokenID = rand.Text
+}
+
+func TestUserSuite(t *testing.T) {
+	suite.Run(t, new(userSuite))
+}
+
+func (s *userSuite) hash(pass string) string {
+	hash, err := password.HashWith(fastParams, pass)
+	s.Require().NoError(err)
+	return hash
+}
+
+func (s *userSuite) TestRegister() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(input entity.CreateUserInput) (*entity.User, error) {
+			s.Equal("a@example.com", input.Email)
+			s.Equal("A", input.Name)
+			ok, err := password.Verify("correct horse", input.PasswordHash)
+			s.Require().NoError(err)
+			s.True(ok)
+			return &entity.User{ID: 1, Email: input.Email, Name: input.Name, PasswordHash: input.PasswordHash}, nil
+		}).Times(1)
+
+		got, err := s.srv.Register(entity.RegisterInput{Email: " A@Example.com ", Name: " A ", Password: "correct horse"})
+		s.Require().NoError(err)
+		s.Equal(1, got.ID)
+	})
+
+	tests := []struct {
+		desc    string
+		input   entity.RegisterInput
+		setup   func()
+		wantErr []error
+	}{
+		{
+			desc:    "invalid email",
+			input:   entity.RegisterInput{Email: "A <a@example.com>", Password: "correct horse"},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrInvalidEmail},
+		},
+		{
+			desc:    "short password",
+			input:   entity.RegisterInput{Email: "a@example.com", Password: "short"},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrPasswordTooShort},
+		},
+		{
+			desc:  "email taken",
+			input: entity.RegisterInput{Email: "a@example.com", Password: "correct horse"},
+			setup: func() {
+				s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, entity.ErrEmailTaken).Times(1)
+			},
+			wantErr: []error{service.ErrConflict, entity.ErrEmailTaken},
+		},
+		{
+			desc:  "repo error",
+			input: entity.RegisterInput{Email: "a@example.com", Password: "correct horse"},
+			setup: func() {
+				s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, mockErr).Times(1)
+			},
+			wantErr: []error{mockErr},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, err := s.srv.Register(tt.input)
+			for _, want := range tt.wantErr {
+				s.ErrorIs(err, want)
+			}
+		})
+	}
+}
+
+func (s *userSuite) TestLogin() {
+	s.Run("success", func() {
+		user := &entity.User{ID: 7, Email: "a@example.com", PasswordHash: s.hash("correct horse")}
+		s.mockRepo.EXPECT().GetByEmail("a@example.com").Return(user, nil).Times(1)
+
+		got, token, err := s.srv.Login("A@example.com", "correct horse")
+		s.Require().NoError(err)
+		s.Equal(user, got)
+		s.Equal(s.now.Add(time.Hour), token.ExpiresAt)
+
+		var claims jwt.Claims
+		s.Require().NoError(jwt.Parse(token.Value, jwt.HS256(secret), &claims))
+		s.Equal(jwt.Claims{Issuer: "todo", Subject: "7", IssuedAt: 123456789, ExpiresAt: 123456789 + 3600, ID: "token-id"}, claims)
+	})
+
+	tests := []struct {
+		desc     string
+		email    string
+		password string
+		setup    func()
+		wantErr  error
+	}{
+		{
+			desc:     "wrong password",
+			email:    "a@example.com",
+			password: "battery staple",
+			setup: func() {
+				s.mockRepo.EXPECT().GetByEmail("a@example.com").Return(&entity.User{ID: 7, PasswordHash: s.hash("correct horse")}, nil).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:     "user without password",
+			email:    "a@example.com",
+			password: "",
+			setup: func() {
+				s.mockRepo.EXPECT().GetByEmail("a@example.com").Return(&entity.User{ID: 7}, nil).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:     "unknown email",
+			email:    "b@example.com",
+			password: "correct horse",
+			setup: func() {
+				s.mockRepo.EXPECT().GetByEmail("b@example.com").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:     "invalid email",
+			email:    "not an email",
+			password: "correct horse",
+			wantErr:  service.ErrUnauthorized,
+		},
+		{
+			desc:     "repo error",
+			email:    "a@example.com",
+			password: "correct horse",
+			setup: func() {
+				s.mockRepo.EXPECT().GetByEmail("a@example.com").Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, _, err := s.srv.Login(tt.email, tt.password)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *userSuite) TestAuthenticate() {
+	sign := func(method jwt.Method, claims jwt.Claims) string {
+		token, err := jwt.Sign(method, "", claims)
+		s.Require().NoError(err)
+		return token
+	}
+	valid := jwt.Claims{Issuer: "todo", Subject: "7", ExpiresAt: 123456789 + 60}
+
+	s.Run("success", func() {
+		user := &entity.User{ID: 7}
+		s.mockRepo.EXPECT().Get(7).Return(user, nil).Times(1)
+
+		got, err := s.srv.Authenticate(sign(jwt.HS256(secret), valid))
+		s.Require().NoError(err)
+		s.Equal(user, got)
+	})
+
+	tests := []struct {
+		desc    string
+		token   func() string
+		setup   func()
+		wantErr error
+	}{
+		{
+			desc:    "garbage",
+			token:   func() string { return "garbage" },
+			wantErr: jwt.ErrMalformed,
+		},
+		{
+			desc:    "other secret",
+			token:   func() string { return sign(jwt.HS256([]byte("other")), valid) },
+			wantErr: jwt.ErrSignature,
+		},
+		{
+			desc: "expired",
+			token: func() string {
+				s.now = s.now.Add(time.Minute)
+				return sign(jwt.HS256(secret), valid)
+			},
+			wantErr: jwt.ErrExpired,
+		},
+		{
+			desc: "other issuer",
+			token: func() string {
+				claims := valid
+				claims.Issuer = "someone"
+				return sign(jwt.HS256(secret), claims)
+			},
+			wantErr: jwt.ErrIssuer,
+		},
+		{
+			desc: "no expiry",
+			token: func() string {
+				claims := valid
+				claims.ExpiresAt = 0
+				return sign(jwt.HS256(secret), claims)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "bad subject",
+			token: func() string {
+				claims := valid
+				claims.Subject = "me"
+				return sign(jwt.HS256(secret), claims)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc:  "deleted user",
+			token: func() string { return sign(jwt.HS256(secret), valid) },
+			setup: func() {
+				s.mockRepo.EXPECT().Get(7).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			_, err := s.srv.Authenticate(tt.token())
+			s.ErrorIs(err, service.ErrUnauthorized)
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *userSuite) TestLoginExternal() {
+	const issuer = "https://idp.example.com"
+	ext := entity.ExternalUser{Issuer: issuer, Subject: "sub-1", Email: " Jane@Example.com", EmailVerified: true, Name: " Jane "}
+
+	s.Run("linked user", func() {
+		user := &entity.User{ID: 7}
+		s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(user, nil).Times(1)
+
+		got, token, err := s.srv.LoginExternal(ext)
+		s.Require().NoError(err)
+		s.Equal(user, got)
+
+		var claims jwt.Claims
+		s.Require().NoError(jwt.Parse(token.Value, jwt.HS256(secret), &claims))
+		s.Equal("7", claims.Subject)
+	})
+	s.Run("links the user with the email", func() {
+		user := &entity.User{ID: 7, Email: "jane@example.com", PasswordHash: "hash"}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1),
+			s.mockRepo.EXPECT().GetByEmail("jane@example.com").Return(user, nil).Times(1),
+			s.mockRepo.EXPECT().LinkIdentity(7, issuer, "sub-1").Return(nil).Times(1),
+		)
+
+		got, _, err := s.srv.LoginExternal(ext)
+		s.Require().NoError(err)
+		s.Equal(user, got)
+	})
+	s.Run("creates a user without password", func() {
+		user := &entity.User{ID: 8, Email: "jane@example.com", Name: "Jane"}
+		gomock.InOrder(
+			s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1),
+			s.mockRepo.EXPECT().GetByEmail("jane@example.com").Return(nil, repo.ErrNotFound).Times(1),
+			s.mockRepo.EXPECT().Create(entity.CreateUserInput{Email: "jane@example.com", Name: "Jane"}).Return(user, nil).Times(1),
+			s.mockRepo.EXPECT().LinkIdentity(8, issuer, "sub-1").Return(nil).Times(1),
+		)
+
+		got, _, err := s.srv.LoginExternal(ext)
+		s.Require().NoError(err)
+		s.Equal(user, got)
+	})
+
+	tests := []struct {
+		desc    string
+		ext     func() entity.ExternalUser
+		setup   func()
+		wantErr error
+	}{
+		{
+			desc: "email not verified",
+			ext: func() entity.ExternalUser {
+				ext := ext
+				ext.EmailVerified = false
+				return ext
+			},
+			setup: func() {
+				s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrUnauthorized,
+		},
+		{
+			desc: "no email",
+			ext: func() entity.ExternalUser {
+				ext := ext
+				ext.Email = ""
+				return ext
+			},
+			setup: func() {
+				s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: entity.ErrInvalidEmail,
+		},
+		{
+			desc: "email registered meanwhile",
+			ext:  func() entity.ExternalUser { return ext },
+			setup: func() {
+				s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().GetByEmail("jane@example.com").Return(nil, repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().Create(gomock.Any()).Return(nil, entity.ErrEmailTaken).Times(1)
+			},
+			wantErr: service.ErrConflict,
+		},
+		{
+			desc: "linked meanwhile",
+			ext:  func() entity.ExternalUser { return ext },
+			setup: func() {
+				s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, repo.ErrNotFound).Times(1)
+				s.mockRepo.EXPECT().GetByEmail("jane@example.com").Return(&entity.User{ID: 7}, nil).Times(1)
+				s.mockRepo.EXPECT().LinkIdentity(7, issuer, "sub-1").Return(entity.ErrIdentityLinked).Times(1)
+			},
+			wantErr: service.ErrConflict,
+		},
+		{
+			desc: "repo error",
+			ext:  func() entity.ExternalUser { return ext },
+			setup: func() {
+				s.mockRepo.EXPECT().GetByIdentity(issuer, "sub-1").Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			_, _, err := s.srv.LoginExternal(tt.ext())
+			s.ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *userSuite) TestLogout() {
+	s.Run("token is rejected after", func() {
+		token, err := jwt.Sign(jwt.HS256(secret), "", jwt.Claims{Issuer: "todo", Subject: "7", ExpiresAt: 123456789 + 60, ID: "token-id"})
+		s.Require().NoError(err)
+		other, err := jwt.Sign(jwt.HS256(secret), "", jwt.Claims{Issuer: "todo", Subject: "7", ExpiresAt: 123456789 + 60, ID: "other-id"})
+		s.Require().NoError(err)
+		s.mockRepo.EXPECT().Get(7).Return(&entity.User{ID: 7}, nil).Times(1)
+
+		s.Require().NoError(s.srv.Logout(token))
+		_, err = s.srv.Authenticate(token)
+		s.ErrorIs(err, service.ErrUnauthorized)
+		s.ErrorIs(s.srv.Logout(token), service.ErrUnauthorized)
+		_, err = s.srv.Authenticate(other)
+		s.NoError(err, "other tokens of the user still work")
+	})
+	s.Run("revocations are forgotten once the token expires", func() {
+		_, token, err := s.issueFor(7)
+		s.Require().NoError(err)
+		s.Require().NoError(s.srv.Logout(token.Value))
+		s.Len(s.srv.(*Service).revoked, 1)
+
+		s.now = s.now.Add(time.Hour)
+		newTokenID = func() string { return "next-id" }
+		_, next, err := s.issueFor(7)
+		s.Require().NoError(err)
+		s.Require().NoError(s.srv.Logout(next.Value))
+		s.Equal(map[string]time.Time{"next-id": s.now.Add(time.Hour)}, s.srv.(*Service).revoked)
+	})
+	s.Run("token without ID", func() {
+		token, err := jwt.Sign(jwt.HS256(secret), "", jwt.Claims{Issuer: "todo", Subject: "7", ExpiresAt: 123456789 + 60})
+		s.Require().NoError(err)
+
+		s.ErrorIs(s.srv.Logout(token), service.ErrUnauthorized)
+	})
+	s.Run("invalid token", func() {
+		s.ErrorIs(s.srv.Logout("garbage"), jwt.ErrMalformed)
+	})
+}
+
+// issueFor logs in the user with the given ID through their linked identity.
+func (s *userSuite) issueFor(id int) (*entity.User, *entity.AccessToken, error) {
+	s.mockRepo.EXPECT().GetByIdentity("https://idp.example.com", "sub-1").Return(&entity.User{ID: id}, nil).Times(1)
+	return s.srv.LoginExternal(entity.ExternalUser{Issuer: "https://idp.example.com", Subject: "sub-1"})
+}
@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/service"
+)
+
+// Service manages workspaces and who belongs to them. A workspace a user is
+// not a member of is reported as not found.
+type Service struct {
+	repo     repo.Workspace
+	users    repo.User
+	projects repo.Project
+}
+
+func NewService(repo repo.Workspace, users repo.User, projects repo.Project) service.Workspace {
+	return &Service{
+		repo:     repo,
+		users:    users,
+		projects: projects,
+	}
+}
+
+func (s *Service) Create(userID int, name string) (*entity.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", service.ErrInvalidInput)
+	}
+	return s.repo.Create(entity.CreateWorkspaceInput{Name: name, OwnerID: userID})
+}
+
+func (s *Service) List(userID int) ([]entity.Workspace, error) {
+	if _, err := s.repo.Personal(userID); err != nil {
+		return nil, err
+	}
+	workspaces, err := s.repo.ListByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(workspaces, func(a, b entity.Workspace) int {
+		return compareBool(b.Personal, a.Personal)
+	})
+	return workspaces, nil
+}
+
+func (s *Service) Resolve(userID, workspaceID int) (*entity.Actor, error) {
+	workspace, membership, err := s.membership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := s.projects.ForWorkspace(workspace.ID).SharesOf(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := &entity.Actor{
+		UserID:      userID,
+		WorkspaceID: workspace.ID,
+		Role:        membership.Role,
+	}
+	if len(shares) > 0 {
+		actor.ProjectRoles = make(map[int]entity.Role, len(shares))
+		for _, share := range shares {
+			actor.ProjectRoles[share.ProjectID] = share.Role
+		}
+	}
+	return actor, nil
+}
+
+func (s *Service) AddMember(userID, workspaceID int, email string, role entity.Role) (*entity.Member, error) {
+	workspace, membership, err := s.membership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.Role.AtLeast(entity.RoleOwner) {
+		return nil, service.ErrForbidden
+	}
+	if workspace.Personal {
+		return nil, fmt.Errorf("%w: %w", service.ErrConflict, entity.ErrPersonalWorkspace)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, entity.ErrInvalidRole)
+	}
+	email, err = entity.NormalizeEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", service.ErrInvalidInput, err)
+	}
+	user, err := s.users.GetByEmail(email)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, fmt.Errorf("%w: no user has the email", service.ErrNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.AddMember(workspace.ID, user.ID, role)
+	if errors.Is(err, entity.ErrAlreadyMember) {
+		return nil, fmt.Errorf("%w: %w", service.ErrConflict, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return &entity.Member{User: *user, Role: role}, nil
+}
+
+func (s *Service) Members(userID, workspaceID int) ([]entity.Member, error) {
+	_, membership, err := s.membership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.Role.AtLeast(entity.RoleViewer) {
+		return nil, service.ErrForbidden
+	}
+	memberships, err := s.repo.Members(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]entity.Member, 0, len(memberships))
+	for _, membership := range memberships {
+		user, err := s.users.Get(membership.UserID)
+		if errors.Is(err, repo.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		members = append(members, entity.Member{User: *user, Role: membership.Role})
+	}
+	return members, nil
+}
+
+// membership returns the workspace with workspaceID, or the personal
+// workspace of the user when it is zero, along with the membership of the
+// user. It fails with service.ErrNotFound unless the user is a member.
+func (s *Service) membership(userID, workspaceID int) (*entity.Workspace, *entity.Membership, error) {
+	if workspaceID == 0 {
+		workspace, err := s.repo.Personal(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		workspaceID = workspace.ID
+	}
+	membership, err := s.repo.Member(workspaceID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil, service.ErrNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+	workspace, err := s.repo.Get(workspaceID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil, service.ErrNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return workspace, membership, nil
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,265 @@
+package workspace
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudingcity/todo/internal/entity"
+	"github.com/cloudingcity/todo/internal/repo"
+	"github.com/cloudingcity/todo/internal/repo/mocks"
+	"github.com/cloudingcity/todo/internal/service"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+var mockErr = errors.New("something wrong")
+
+type workspaceSuite struct {
+	suite.Suite
+	srv       service.Workspace
+	mockRepo  *mocks.MockWorkspace
+	mockUsers *mocks.MockUser
+	mockProj  *mocks.MockProject
+}
+
+func (s *workspaceSuite) SetupSubTest() {
+	ctrl := gomock.NewController(s.T())
+	s.mockRepo = mocks.NewMockWorkspace(ctrl)
+	s.mockUsers = mocks.NewMockUser(ctrl)
+	s.mockProj = mocks.NewMockProject(ctrl)
+	s.mockProj.EXPECT().ForWorkspace(gomock.Any()).Return(s.mockProj).AnyTimes()
+	s.srv = NewService(s.mockRepo, s.mockUsers, s.mockProj)
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(workspaceSuite))
+}
+
+func (s *workspaceSuite) TestCreate() {
+	s.Run("success", func() {
+		s.mockRepo.EXPECT().Create(entity.CreateWorkspaceInput{Name: "team", OwnerID: 1}).Return(&entity.Workspace{ID: 2}, nil).Times(1)
+
+		got, err := s.srv.Create(1, " team ")
+		s.Require().NoError(err)
+		s.Equal(2, got.ID)
+	})
+	s.Run("blank name", func() {
+		_, err := s.srv.Create(1, " ")
+		s.ErrorIs(err, service.ErrInvalidInput)
+	})
+}
+
+func (s *workspaceSuite) TestList() {
+	s.Run("personal first", func() {
+		s.mockRepo.EXPECT().Personal(1).Return(&entity.Workspace{ID: 3, Personal: true}, nil).Times(1)
+		s.mockRepo.EXPECT().ListByMember(1).Return([]entity.Workspace{{ID: 1}, {ID: 2}, {ID: 3, Personal: true}}, nil).Times(1)
+
+		got, err := s.srv.List(1)
+		s.Require().NoError(err)
+		s.Equal([]entity.Workspace{{ID: 3, Personal: true}, {ID: 1}, {ID: 2}}, got)
+	})
+}
+
+func (s *workspaceSuite) TestResolve() {
+	tests := []struct {
+		desc        string
+		workspaceID int
+		setup       func()
+		want        *entity.Actor
+		wantErr     error
+	}{
+		{
+			desc:        "personal",
+			workspaceID: 0,
+			setup: func() {
+				s.mockRepo.EXPECT().Personal(1).Return(&entity.Workspace{ID: 3, Personal: true}, nil).Times(1)
+				s.mockRepo.EXPECT().Member(3, 1).Return(&entity.Membership{WorkspaceID: 3, UserID: 1, Role: entity.RoleOwner}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(3).Return(&entity.Workspace{ID: 3, Personal: true}, nil).Times(1)
+				s.mockProj.EXPECT().SharesOf(1).Return(nil, nil).Times(1)
+			},
+			want: &entity.Actor{UserID: 1, WorkspaceID: 3, Role: entity.RoleOwner},
+		},
+		{
+			desc:        "member with shares",
+			workspaceID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Member(2, 1).Return(&entity.Membership{WorkspaceID: 2, UserID: 1, Role: entity.RoleGuest}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Workspace{ID: 2}, nil).Times(1)
+				s.mockProj.EXPECT().SharesOf(1).Return([]entity.ProjectShare{
+					{ProjectID: 4, UserID: 1, Role: entity.RoleViewer},
+					{ProjectID: 6, UserID: 1, Role: entity.RoleEditor},
+				}, nil).Times(1)
+			},
+			want: &entity.Actor{UserID: 1, WorkspaceID: 2, Role: entity.RoleGuest, ProjectRoles: map[int]entity.Role{4: entity.RoleViewer, 6: entity.RoleEditor}},
+		},
+		{
+			desc:        "not a member",
+			workspaceID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Member(2, 1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: service.ErrNotFound,
+		},
+		{
+			desc:        "repo error",
+			workspaceID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Member(2, 1).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+		{
+			desc:        "shares error",
+			workspaceID: 2,
+			setup: func() {
+				s.mockRepo.EXPECT().Member(2, 1).Return(&entity.Membership{WorkspaceID: 2, UserID: 1, Role: entity.RoleEditor}, nil).Times(1)
+				s.mockRepo.EXPECT().Get(2).Return(&entity.Workspace{ID: 2}, nil).Times(1)
+				s.mockProj.EXPECT().SharesOf(1).Return(nil, mockErr).Times(1)
+			},
+			wantErr: mockErr,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			got, err := s.srv.Resolve(1, tt.workspaceID)
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+func (s *workspaceSuite) TestAddMember() {
+	member := func(workspace *entity.Workspace, role entity.Role) {
+		s.mockRepo.EXPECT().Member(workspace.ID, 1).Return(&entity.Membership{WorkspaceID: workspace.ID, UserID: 1, Role: role}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(workspace.ID).Return(workspace, nil).Times(1)
+	}
+
+	tests := []struct {
+		desc    string
+		email   string
+		role    entity.Role
+		setup   func()
+		wantErr []error
+	}{
+		{
+			desc:  "success",
+			email: "B@example.com",
+			role:  entity.RoleViewer,
+			setup: func() {
+				member(&entity.Workspace{ID: 2}, entity.RoleOwner)
+				s.mockUsers.EXPECT().GetByEmail("b@example.com").Return(&entity.User{ID: 5}, nil).Times(1)
+				s.mockRepo.EXPECT().AddMember(2, 5, entity.RoleViewer).Return(nil).Times(1)
+			},
+		},
+		{
+			desc:  "editor cannot add members",
+			email: "b@example.com",
+			role:  entity.RoleViewer,
+			setup: func() {
+				member(&entity.Workspace{ID: 2}, entity.RoleEditor)
+			},
+			wantErr: []error{service.ErrForbidden},
+		},
+		{
+			desc:  "personal workspace",
+			email: "b@example.com",
+			role:  entity.RoleEditor,
+			setup: func() {
+				member(&entity.Workspace{ID: 2, Personal: true}, entity.RoleOwner)
+			},
+			wantErr: []error{service.ErrConflict, entity.ErrPersonalWorkspace},
+		},
+		{
+			desc:  "invalid role",
+			email: "b@example.com",
+			role:  "admin",
+			setup: func() {
+				member(&entity.Workspace{ID: 2}, entity.RoleOwner)
+			},
+			wantErr: []error{service.ErrInvalidInput, entity.ErrInvalidRole},
+		},
+		{
+			desc:  "unknown email",
+			email: "b@example.com",
+			role:  entity.RoleEditor,
+			setup: func() {
+				member(&entity.Workspace{ID: 2}, entity.RoleOwner)
+				s.mockUsers.EXPECT().GetByEmail("b@example.com").Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: []error{service.ErrNotFound},
+		},
+		{
+			desc:  "already a member",
+			email: "b@example.com",
+			role:  entity.RoleEditor,
+			setup: func() {
+				member(&entity.Workspace{ID: 2}, entity.RoleOwner)
+				s.mockUsers.EXPECT().GetByEmail("b@example.com").Return(&entity.User{ID: 5}, nil).Times(1)
+				s.mockRepo.EXPECT().AddMember(2, 5, entity.RoleEditor).Return(entity.ErrAlreadyMember).Times(1)
+			},
+			wantErr: []error{service.ErrConflict, entity.ErrAlreadyMember},
+		},
+		{
+			desc:  "not a member",
+			email: "b@example.com",
+			role:  entity.RoleEditor,
+			setup: func() {
+				s.mockRepo.EXPECT().Member(2, 1).Return(nil, repo.ErrNotFound).Times(1)
+			},
+			wantErr: []error{service.ErrNotFound},
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.desc, func() {
+			tt.setup()
+
+			got, err := s.srv.AddMember(1, 2, tt.email, tt.role)
+			if tt.wantErr == nil {
+				s.Require().NoError(err)
+				s.Equal(&entity.Member{User: entity.User{ID: 5}, Role: tt.role}, got)
+			}
+			for _, want := range tt.wantErr {
+				s.ErrorIs(err, want)
+			}
+		})
+	}
+}
+
+func (s *workspaceSuite) TestMembers() {
+	member := func(role entity.Role) {
+		s.mockRepo.EXPECT().Member(2, 1).Return(&entity.Membership{WorkspaceID: 2, UserID: 1, Role: role}, nil).Times(1)
+		s.mockRepo.EXPECT().Get(2).Return(&entity.Workspace{ID: 2}, nil).Times(1)
+	}
+
+	s.Run("success", func() {
+		member(entity.RoleViewer)
+		s.mockRepo.EXPECT().Members(2).Return([]entity.Membership{
+			{WorkspaceID: 2, UserID: 1, Role: entity.RoleViewer},
+			{WorkspaceID: 2, UserID: 5, Role: entity.RoleOwner},
+		}, nil).Times(1)
+		s.mockUsers.EXPECT().Get(1).Return(&entity.User{ID: 1}, nil).Times(1)
+		s.mockUsers.EXPECT().Get(5).Return(&entity.User{ID: 5}, nil).Times(1)
+
+		got, err := s.srv.Members(1, 2)
+		s.Require().NoError(err)
+		s.Equal([]entity.Member{{User: entity.User{ID: 1}, Role: entity.RoleViewer}, {User: entity.User{ID: 5}, Role: entity.RoleOwner}}, got)
+	})
+	s.Run("guest", func() {
+		member(entity.RoleGuest)
+
+		_, err := s.srv.Members(1, 2)
+		s.ErrorIs(err, service.ErrForbidden)
+	})
+	s.Run("not a member", func() {
+		s.mockRepo.EXPECT().Member(2, 1).Return(nil, repo.ErrNotFound).Times(1)
+
+		_, err := s.srv.Members(1, 2)
+		s.ErrorIs(err, service.ErrNotFound)
+	})
+}